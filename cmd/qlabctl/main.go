@@ -0,0 +1,1060 @@
+// Command qlabctl is a small reference client for the qlab package.
+//
+// It exercises the public API only: connecting to a workspace, planning and
+// syncing a JSON cue file, receiving the current workspace, verifying cue
+// configuration, and tailing QLab update messages.
+//
+// Usage:
+//
+//	qlabctl [global flags] <command> [command flags]
+//
+// Commands:
+//
+//	connect              Connect to QLab and print the workspace ID
+//	plan <file>          Show what sync would change without sending anything
+//	sync <file>          Transmit a cue file to QLab
+//	apply <plan>         Apply a plan saved with plan -o, recording what's done in it
+//	resume <journal>     Finish an apply cut short, from the journal apply -journal kept
+//	receive              Print the current QLab cues as JSON
+//	verify [file]        Check cues and targets, and validate the file and that QLab matches it if given
+//	tail                 Print QLab update messages until interrupted
+//	patches              List the workspace's audio patches, network patches, and video stages
+//	clear                Delete the cues earlier syncs created, or a cue list with -list
+//	resolutions <file>   List the conflict choices remembered for a cue file, or forget them
+//	renumber <list>      Renumber the cues of a cue list, given by uniqueID or name, in order
+//	mock [scenario]      Serve a mock QLab workspace, from a scenario file if given, until interrupted
+//	version              Print the qlab package version
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/zenibako/qlab-golang/qlab"
+)
+
+// globalOptions are flags shared by every command
+type globalOptions struct {
+	host     string
+	port     int
+	passcode string
+	timeout  int
+	retries  int
+	tcp      bool
+	readOnly bool
+	verbose  bool
+	profile  string
+	profiles string
+
+	replyPort     int
+	replyStrategy string
+}
+
+// errOutOfSync is returned by verify when QLab differs from the source file
+var errOutOfSync = errors.New("QLab workspace is out of sync with the source file")
+
+// errBadTargets is returned by verify when cue targets are broken, missing, or circular
+var errBadTargets = errors.New("cue targets failed validation")
+
+// errInvalidSource is returned by verify when the source file fails validation
+var errInvalidSource = errors.New("source file failed validation")
+
+func main() {
+	opts := globalOptions{}
+	flag.StringVar(&opts.host, "host", "localhost", "QLab host")
+	flag.IntVar(&opts.port, "port", 53000, "QLab OSC port")
+	flag.StringVar(&opts.passcode, "passcode", os.Getenv("QLAB_PASSCODE"), "workspace passcode (default $QLAB_PASSCODE)")
+	flag.IntVar(&opts.timeout, "timeout", 10, "reply timeout in seconds")
+	flag.IntVar(&opts.retries, "retries", 0, "retries for timed-out commands")
+	flag.BoolVar(&opts.tcp, "tcp", false, "send OSC over TCP instead of UDP")
+	flag.BoolVar(&opts.readOnly, "read-only", false, "refuse every command that would change the workspace")
+	flag.IntVar(&opts.replyPort, "reply-port", 0, "UDP port to receive replies on (default port+1, or a free port)")
+	flag.StringVar(&opts.replyStrategy, "reply-strategy", "negotiate", "how replies find us: negotiate (/udpReplyPort) or source")
+	flag.BoolVar(&opts.verbose, "v", false, "enable debug logging")
+	flag.StringVar(&opts.profile, "profile", "", "connection profile to use (default: the profile file's default)")
+	flag.StringVar(&opts.profiles, "profiles", "", "connection profile file (default ~/.config/cuejitsu/profiles.json)")
+	flag.Usage = usage
+	flag.Parse()
+
+	if err := applyProfile(&opts); err != nil {
+		fmt.Fprintf(os.Stderr, "qlabctl: %v\n", err)
+		os.Exit(2)
+	}
+
+	if opts.verbose {
+		log.SetLevel(log.DebugLevel)
+	} else {
+		log.SetLevel(log.WarnLevel)
+	}
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	command, args := flag.Arg(0), flag.Args()[1:]
+	var err error
+	switch command {
+	case "connect":
+		err = runConnect(opts, args)
+	case "plan":
+		err = runPlan(opts, args)
+	case "sync":
+		err = runSync(opts, args)
+	case "apply":
+		err = runApply(opts, args)
+	case "resume":
+		err = runResume(opts, args)
+	case "receive":
+		err = runReceive(opts, args)
+	case "verify":
+		err = runVerify(opts, args)
+	case "tail":
+		err = runTail(opts, args)
+	case "patches":
+		err = runPatches(opts, args)
+	case "renumber":
+		err = runRenumber(opts, args)
+	case "clear":
+		err = runClear(opts, args)
+	case "resolutions":
+		err = runResolutions(opts, args)
+	case "mock":
+		err = runMock(opts, args)
+	case "version":
+		fmt.Println(qlab.Version())
+	default:
+		fmt.Fprintf(os.Stderr, "qlabctl: unknown command %q\n", command)
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "qlabctl %s: %v\n", command, err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `Usage: qlabctl [global flags] <command> [command flags]
+
+Commands:
+  connect              Connect to QLab and print the workspace ID
+  plan <file>          Show what sync would change without sending anything
+  sync <file>          Transmit a cue file to QLab
+  apply <plan>         Apply a plan saved with plan -o, recording what's done in it
+  resume <journal>     Finish an apply cut short, from the journal apply -journal kept
+  receive              Print the current QLab cues as JSON
+  verify [file]        Check cues and targets, and validate the file and that QLab matches it if given
+  tail                 Print QLab update messages until interrupted
+  patches              List the workspace's audio patches, network patches, and video stages
+  clear                Delete the cues earlier syncs created, or a cue list with -list
+  resolutions <file>   List the conflict choices remembered for a cue file, or forget them
+  renumber <list>      Renumber the cues of a cue list, given by uniqueID or name, in order
+  mock [scenario]      Serve a mock QLab workspace, from a scenario file if given, until interrupted
+  version              Print the qlab package version
+
+Global flags:
+`)
+	flag.PrintDefaults()
+}
+
+// applyProfile fills in the connection options that weren't given as flags from the
+// selected profile, if there is one. The passcode may come from the macOS keychain.
+func applyProfile(opts *globalOptions) error {
+	path := opts.profiles
+	if path == "" {
+		defaultPath, err := qlab.DefaultProfilePath()
+		if err != nil {
+			return err
+		}
+		path = defaultPath
+	}
+	config, err := qlab.LoadProfileConfig(path)
+	if err != nil {
+		return err
+	}
+	if opts.profile == "" && config.Default == "" {
+		return nil
+	}
+	profile, err := config.Profile(opts.profile)
+	if err != nil {
+		return err
+	}
+
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if !set["host"] && profile.Host != "" {
+		opts.host = profile.Host
+	}
+	if !set["port"] && profile.Port != 0 {
+		opts.port = profile.Port
+	}
+	if !set["timeout"] && profile.Timeout != 0 {
+		opts.timeout = profile.Timeout
+	}
+	if !set["retries"] {
+		opts.retries = profile.Retries
+	}
+	if !set["tcp"] {
+		opts.tcp = profile.Transport == qlab.TransportTCP
+	}
+	if !set["read-only"] {
+		opts.readOnly = profile.ReadOnly
+	}
+	if !set["reply-port"] {
+		opts.replyPort = profile.ReplyPort
+	}
+	if !set["reply-strategy"] && profile.ReplyStrategy != "" {
+		opts.replyStrategy = string(profile.ReplyStrategy)
+	}
+	if opts.passcode == "" {
+		var store qlab.PasscodeStore
+		if runtime.GOOS == "darwin" {
+			store = qlab.NewKeychainPasscodeStore("cuejitsu")
+		}
+		if opts.passcode, err = profile.ResolvePasscode(store); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// connect creates a workspace from the global options and initializes it
+func connect(opts globalOptions) (*qlab.Workspace, error) {
+	return connectOpening(opts, "")
+}
+
+// connectOpening is connect, first having QLab open the workspace file at open, a path on
+// the QLab machine, unless it's empty
+func connectOpening(opts globalOptions, open string) (*qlab.Workspace, error) {
+	transport := qlab.TransportUDP
+	if opts.tcp {
+		transport = qlab.TransportTCP
+	}
+	strategy := qlab.ReplyStrategy(opts.replyStrategy)
+	if strategy != qlab.ReplyNegotiate && strategy != qlab.ReplySourcePort {
+		return nil, fmt.Errorf("unknown reply strategy %q (want negotiate or source)", opts.replyStrategy)
+	}
+	profile := qlab.ConnectionProfile{
+		Host:          opts.host,
+		Port:          opts.port,
+		Passcode:      opts.passcode,
+		Timeout:       opts.timeout,
+		Retries:       opts.retries,
+		Transport:     transport,
+		ReadOnly:      opts.readOnly,
+		ReplyPort:     opts.replyPort,
+		ReplyStrategy: strategy,
+	}
+	if open == "" {
+		return qlab.ConnectProfile(profile, nil)
+	}
+	workspace := qlab.NewWorkspaceFromProfile(profile)
+	if _, err := workspace.OpenWorkspaceFile(open); err != nil {
+		workspace.Close()
+		return nil, err
+	}
+	if _, err := workspace.Init(opts.passcode); err != nil {
+		workspace.Close()
+		return nil, err
+	}
+	return workspace, nil
+}
+
+// loadWorkspaceData reads a JSON cue file with a top-level "cues" array
+func loadWorkspaceData(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var workspaceData map[string]any
+	if err := json.Unmarshal(data, &workspaceData); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return qlab.UnwrapEnvelope(workspaceData), nil
+}
+
+// fileArg parses command flags and returns the single required file argument
+func fileArg(fs *flag.FlagSet, args []string) (string, error) {
+	if err := fs.Parse(args); err != nil {
+		return "", err
+	}
+	if fs.NArg() != 1 {
+		return "", fmt.Errorf("expected exactly one cue file argument")
+	}
+	return fs.Arg(0), nil
+}
+
+func runConnect(opts globalOptions, args []string) error {
+	fs := flag.NewFlagSet("connect", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	workspace, err := connect(opts)
+	if err != nil {
+		return err
+	}
+	defer workspace.Close()
+
+	fmt.Printf("Connected to %s:%d, workspace %s\n", opts.host, opts.port, workspace.WorkspaceID())
+	if version := workspace.QLabVersion(); version != "" {
+		fmt.Printf("QLab %s\n", version)
+	}
+	if permissions, ok := workspace.Permissions(); ok {
+		fmt.Printf("Permissions: %s\n", permissions)
+	}
+	return nil
+}
+
+func runPlan(opts globalOptions, args []string) error {
+	fs := flag.NewFlagSet("plan", flag.ExitOnError)
+	syncDeletions := fs.Bool("delete", false, "include cues removed from the source since the last sync")
+	syncState := fs.Bool("sync-state", false, "compare the armed and flagged states in the source")
+	externalID := fs.String("external-id", "", "match cues by the stable ID in this source property")
+	externalIDProperty := fs.String("external-id-property", "", "QLab property external IDs are stamped in (default the notes)")
+	nameTemplate := fs.String("name-template", "", "render cue names through this Go template, e.g. '[SQ {{.Number}}] {{.Name}}'")
+	notesTemplate := fs.String("notes-template", "", "render cue notes through this Go template")
+	suffixDuplicates := fs.Bool("suffix-duplicates", false, "renumber duplicate cue numbers with a letter suffix instead of refusing")
+	scopes := fs.Bool("scopes", false, "print the scope comparison tree as JSON instead of the plan")
+	numbering := fs.String("numbering", "preserve", "number cues by preserve, increment or fill")
+	numbers := fs.Bool("numbers", false, "print the number each cue would be given instead of the plan")
+	absoluteNumbers := fs.Bool("absolute-numbers", false, "take cue numbers in groups as written instead of prefixing the group's")
+	output := fs.String("o", "", "save the plan to this file for apply, resolving conflicts now")
+	resolve := fs.String("resolve", "prompt", "with -o, resolve conflicts by prompt, source, qlab or skip")
+	path, err := fileArg(fs, args)
+	if err != nil {
+		return err
+	}
+	strategy, err := numberingStrategy(*numbering)
+	if err != nil {
+		return err
+	}
+	resolver, err := conflictResolver(*resolve, "")
+	if err != nil {
+		return err
+	}
+
+	workspaceData, err := loadWorkspaceData(path)
+	if err != nil {
+		return err
+	}
+	var suffixed []qlab.DuplicateCue
+	if *suffixDuplicates {
+		workspaceData, suffixed = qlab.SuffixDuplicateCues(workspaceData)
+	}
+	if duplicates := qlab.FindDuplicateCues(workspaceData); len(duplicates) > 0 {
+		return &qlab.DuplicateCueError{Duplicates: duplicates}
+	}
+
+	workspace, err := connect(opts)
+	if err != nil {
+		return err
+	}
+	defer workspace.Close()
+	workspace.SetSyncDeletions(*syncDeletions)
+	workspace.SetSyncOperationalState(*syncState)
+	workspace.SetExternalIDs(*externalID, *externalIDProperty)
+	workspace.SetAbsoluteCueNumbers(*absoluteNumbers)
+	if err := setCueTemplates(workspace, *nameTemplate, *notesTemplate); err != nil {
+		return err
+	}
+	if *output != "" {
+		workspace.SetNumberingStrategy(strategy)
+		plan, err := workspace.PlanTransmit(path, workspaceData, qlab.WithConflictResolver(resolver))
+		if err != nil {
+			return err
+		}
+		printOperations(plan)
+		return savePlan(*output, plan)
+	}
+	if *numbers || strategy != nil {
+		allocator := qlab.NewCueNumberAllocator(strategy)
+		allocator.Absolute = *absoluteNumbers
+		if err := workspace.ReserveCueNumbers(allocator); err != nil {
+			return err
+		}
+		var allocations []qlab.CueNumberAllocation
+		workspaceData, allocations = allocator.Apply(workspaceData)
+		if *numbers {
+			printNumbers(allocations)
+			return nil
+		}
+	}
+	if workspaceData, err = workspace.RenderCueTemplates(workspaceData); err != nil {
+		return err
+	}
+
+	comparison, err := workspace.PerformThreeWayComparison(path, workspaceData)
+	if err != nil {
+		return err
+	}
+	comparison.Duplicates = suffixed
+	if *scopes {
+		if comparison.WorkspaceScope == nil {
+			return errors.New("no scope comparison without a cache of an earlier sync")
+		}
+		data, err := json.MarshalIndent(comparison.WorkspaceScope.Nested(), "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	printPlan(comparison)
+	return nil
+}
+
+// setCueTemplates sets the name and notes templates given on the command line
+func setCueTemplates(workspace *qlab.Workspace, name, notes string) error {
+	if err := workspace.SetNameTemplate(name); err != nil {
+		return err
+	}
+	return workspace.SetNotesTemplate(notes)
+}
+
+func runSync(opts globalOptions, args []string) error {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "log the OSC messages instead of sending them")
+	batch := fs.Int("batch", 0, "pipeline property sets with this many messages in flight (0 disables)")
+	warnDuplicates := fs.Bool("warn-duplicates", false, "warn about duplicate cue identifiers instead of refusing")
+	suffixDuplicates := fs.Bool("suffix-duplicates", false, "renumber duplicate cue numbers with a letter suffix instead of refusing")
+	preserveSelection := fs.Bool("preserve-selection", false, "restore the selection and playheads after syncing")
+	resolve := fs.String("resolve", "prompt", "resolve conflicts by prompt, source, qlab or skip")
+	keepFields := fs.String("keep-qlab-fields", "", "keep these comma-separated fields from QLab in conflicts, taking the rest from source")
+	remember := fs.Bool("remember", false, "remember conflict choices for later syncs of this file, and apply the ones remembered")
+	syncDeletions := fs.Bool("delete", false, "delete cues removed from the source since the last sync")
+	syncState := fs.Bool("sync-state", false, "send the armed and flagged states in the source")
+	externalID := fs.String("external-id", "", "stamp cues with the stable ID in this source property and match them by it")
+	externalIDProperty := fs.String("external-id-property", "", "QLab property external IDs are stamped in (default the notes)")
+	lists := fs.String("lists", "", "only sync the cues in these comma-separated cue lists")
+	from := fs.String("from", "", "only sync cues numbered from this cue on")
+	to := fs.String("to", "", "only sync cues numbered up to this cue")
+	checkMedia := fs.Bool("check-media", false, "refuse to sync if any file target is missing or unplayable")
+	collectMedia := fs.String("collect-media", "", "copy media outside the workspace folder into this folder inside it")
+	linkMedia := fs.Bool("link-media", false, "hard-link collected media instead of copying it")
+	inbox := fs.String("inbox", "Cuejitsu Inbox", "staging cue list created by the first sync that creates cues (empty for none)")
+	rate := fs.Float64("rate", 0, "send at most this many OSC messages per second (0 is unlimited)")
+	maxInFlight := fs.Int("max-in-flight", 0, "keep at most this many requests awaiting a reply (0 is unlimited)")
+	audit := fs.String("audit", "", "append every change sent to QLab to this file as JSON lines")
+	nameTemplate := fs.String("name-template", "", "render cue names through this Go template, e.g. '[SQ {{.Number}}] {{.Name}}'")
+	notesTemplate := fs.String("notes-template", "", "render cue notes through this Go template")
+	numbering := fs.String("numbering", "preserve", "number cues by preserve, increment or fill")
+	absoluteNumbers := fs.Bool("absolute-numbers", false, "take cue numbers in groups as written instead of prefixing the group's")
+	open := fs.String("open", "", "have QLab open this workspace file, a path on the QLab machine, and sync to it")
+	save := fs.Bool("save", false, "save the workspace after syncing")
+	path, err := fileArg(fs, args)
+	if err != nil {
+		return err
+	}
+
+	resolver, err := conflictResolver(*resolve, *keepFields)
+	if err != nil {
+		return err
+	}
+	strategy, err := numberingStrategy(*numbering)
+	if err != nil {
+		return err
+	}
+
+	workspaceData, err := loadWorkspaceData(path)
+	if err != nil {
+		return err
+	}
+
+	workspace, err := connectOpening(opts, *open)
+	if err != nil {
+		return err
+	}
+	defer workspace.Close()
+
+	workspace.SetDryRun(*dryRun)
+	workspace.SetBatchWindow(*batch)
+	workspace.SetPreserveSelection(*preserveSelection)
+	workspace.SetSyncDeletions(*syncDeletions)
+	workspace.SetSyncOperationalState(*syncState)
+	workspace.SetRememberResolutions(*remember)
+	workspace.SetExternalIDs(*externalID, *externalIDProperty)
+	workspace.SetMediaPreflight(*checkMedia)
+	workspace.SetNumberingStrategy(strategy)
+	if *collectMedia != "" {
+		manager := &qlab.MediaManager{Folder: *collectMedia}
+		if *linkMedia {
+			manager.Mode = qlab.MediaHardLink
+		}
+		workspace.SetMediaManager(manager)
+	}
+	if err := setCueTemplates(workspace, *nameTemplate, *notesTemplate); err != nil {
+		return err
+	}
+	workspace.SetSendRate(*rate)
+	workspace.SetMaxInFlight(*maxInFlight)
+	if *audit != "" {
+		auditLog, err := qlab.OpenAuditLog(*audit)
+		if err != nil {
+			return err
+		}
+		defer auditLog.Close()
+		workspace.SetAuditSink(auditLog)
+	}
+	if *inbox == "" {
+		workspace.SetSkipInbox(true)
+	} else {
+		workspace.SetInboxName(*inbox)
+	}
+	if *warnDuplicates {
+		workspace.SetDuplicatePolicy(qlab.DuplicatePolicyWarn)
+	}
+	if *suffixDuplicates {
+		workspace.SetDuplicatePolicy(qlab.DuplicatePolicySuffix)
+	}
+	workspace.SetProgressCallback(func(step, message string) {
+		fmt.Fprintf(os.Stderr, "[%s] %s\n", step, message)
+	})
+
+	options := []qlab.TransmitOption{qlab.WithConflictResolver(resolver)}
+	if *lists != "" {
+		options = append(options, qlab.WithCueLists(strings.Split(*lists, ",")...))
+	}
+	if *from != "" || *to != "" {
+		options = append(options, qlab.WithCueRange(*from, *to))
+	}
+	if *absoluteNumbers {
+		options = append(options, qlab.WithAbsoluteNumbers())
+	}
+
+	comparison, err := workspace.TransmitWorkspaceData(path, workspaceData, options...)
+	if err != nil {
+		return err
+	}
+	if comparison != nil {
+		printPlan(comparison)
+	}
+	if *save {
+		return workspace.SaveWorkspace()
+	}
+	return nil
+}
+
+func runApply(opts globalOptions, args []string) error {
+	fs := flag.NewFlagSet("apply", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "log the OSC messages instead of sending them")
+	skip := fs.String("skip", "", "leave out the operations on these comma-separated cues")
+	journal := fs.String("journal", "", "journal each operation to this file as it completes, for resume")
+	inbox := fs.String("inbox", "Cuejitsu Inbox", "staging cue list created by the first sync that creates cues (empty for none)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected exactly one plan file argument")
+	}
+	planPath := fs.Arg(0)
+
+	data, err := os.ReadFile(planPath)
+	if err != nil {
+		return err
+	}
+	var plan qlab.TransmitPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", planPath, err)
+	}
+	if *skip != "" {
+		skipped := make(map[string]bool)
+		for _, cue := range strings.Split(*skip, ",") {
+			skipped[strings.TrimSpace(cue)] = true
+		}
+		plan.Filter(func(op qlab.PlanOperation) bool { return !skipped[op.Cue] })
+	}
+
+	workspace, err := connect(opts)
+	if err != nil {
+		return err
+	}
+	defer workspace.Close()
+	workspace.SetDryRun(*dryRun)
+	workspace.SetJournal(*journal)
+	if *inbox == "" {
+		workspace.SetSkipInbox(true)
+	} else {
+		workspace.SetInboxName(*inbox)
+	}
+	workspace.SetProgressCallback(func(step, message string) {
+		fmt.Fprintf(os.Stderr, "[%s] %s\n", step, message)
+	})
+
+	// The plan is saved even when the apply fails, so running apply again resumes it
+	applyErr := workspace.ApplyPlan(&plan)
+	if !*dryRun {
+		if err := savePlan(planPath, &plan); err != nil {
+			return err
+		}
+	}
+	if applyErr != nil {
+		return applyErr
+	}
+	printOperations(&plan)
+	return nil
+}
+
+func runResume(opts globalOptions, args []string) error {
+	fs := flag.NewFlagSet("resume", flag.ExitOnError)
+	inbox := fs.String("inbox", "Cuejitsu Inbox", "staging cue list created by the first sync that creates cues (empty for none)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected exactly one journal file argument")
+	}
+
+	workspace, err := connect(opts)
+	if err != nil {
+		return err
+	}
+	defer workspace.Close()
+	if *inbox == "" {
+		workspace.SetSkipInbox(true)
+	} else {
+		workspace.SetInboxName(*inbox)
+	}
+	workspace.SetProgressCallback(func(step, message string) {
+		fmt.Fprintf(os.Stderr, "[%s] %s\n", step, message)
+	})
+	return workspace.ResumeTransmit(fs.Arg(0))
+}
+
+// savePlan writes a plan as indented JSON
+func savePlan(path string, plan *qlab.TransmitPlan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// printOperations prints the operations of a plan in order, with what's skipped or done
+func printOperations(plan *qlab.TransmitPlan) {
+	for _, op := range plan.Operations {
+		status := ""
+		switch {
+		case op.Skip:
+			status = "  (skipped)"
+		case op.Done:
+			status = "  (done)"
+		}
+		fmt.Printf("%-10s %-24s %s%s\n", op.Phase, op.Cue, op.Name, status)
+	}
+}
+
+// conflictResolver returns the resolver named by the -resolve flag, or one keeping the
+// -keep-qlab-fields fields from QLab
+func conflictResolver(name, keepFields string) (qlab.ConflictResolver, error) {
+	if keepFields != "" {
+		if name != "prompt" && name != "source" {
+			return nil, fmt.Errorf("-keep-qlab-fields takes the other fields from source, so it can't be used with -resolve %s", name)
+		}
+		resolver := qlab.FieldPolicyResolver{Fields: make(map[string]qlab.ConflictResolutionChoice), Default: qlab.ChoiceUseSource}
+		for _, field := range strings.Split(keepFields, ",") {
+			resolver.Fields[strings.TrimSpace(field)] = qlab.ChoiceKeepQLab
+		}
+		return resolver, nil
+	}
+	switch name {
+	case "prompt":
+		return qlab.PromptResolver{}, nil
+	case "source":
+		return qlab.AlwaysSource, nil
+	case "qlab":
+		return qlab.AlwaysQLab, nil
+	case "skip":
+		return qlab.AlwaysSkip, nil
+	}
+	return nil, fmt.Errorf("unknown -resolve value %q (want prompt, source, qlab or skip)", name)
+}
+
+// numberingStrategy returns the strategy named by the -numbering flag, nil for the numbers
+// the source gives
+func numberingStrategy(name string) (qlab.NumberingStrategy, error) {
+	switch name {
+	case "preserve":
+		return nil, nil
+	case "increment":
+		return qlab.AutoIncrementNumbers(1, 1), nil
+	case "fill":
+		return qlab.FillNumberGaps, nil
+	}
+	return nil, fmt.Errorf("unknown -numbering value %q (want preserve, increment or fill)", name)
+}
+
+// printNumbers prints the number each source cue is given, with conflicts
+func printNumbers(allocations []qlab.CueNumberAllocation) {
+	for _, a := range allocations {
+		number := a.Number
+		if number == "" {
+			number = "(unnumbered)"
+		}
+		line := fmt.Sprintf("%-20s %-10s -> %s", a.Path, a.Written, number)
+		if a.Name != "" {
+			line += "  " + a.Name
+		}
+		if a.Conflict != "" {
+			line += "  (conflicts with " + a.Conflict + ")"
+		}
+		fmt.Println(line)
+	}
+}
+
+func runReceive(opts globalOptions, args []string) error {
+	fs := flag.NewFlagSet("receive", flag.ExitOnError)
+	output := fs.String("o", "", "write JSON to this file instead of stdout")
+	relative := fs.Bool("relative", false, "write file targets relative to the workspace folder")
+	relativeTo := fs.String("relative-to", "", "write file targets relative to this folder")
+	envelope := fs.Bool("envelope", false, "include the workspace name, basePath and cue lists")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	workspace, err := connect(opts)
+	if err != nil {
+		return err
+	}
+	defer workspace.Close()
+
+	var options []qlab.ReceiveOption
+	if *relative || *relativeTo != "" {
+		options = append(options, qlab.WithRelativePaths(*relativeTo))
+	}
+	var received any
+	if *envelope {
+		received, err = workspace.ReceiveWorkspace(options...)
+	} else {
+		var cues []any
+		cues, err = workspace.ReceiveWorkspaceData(options...)
+		received = map[string]any{"cues": cues}
+	}
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(received, "", "  ")
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		fmt.Println(string(data))
+		return nil
+	}
+	return os.WriteFile(*output, append(data, '\n'), 0o644)
+}
+
+func runPatches(opts globalOptions, args []string) error {
+	fs := flag.NewFlagSet("patches", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	workspace, err := connect(opts)
+	if err != nil {
+		return err
+	}
+	defer workspace.Close()
+
+	settings := workspace.Settings()
+	for _, kind := range []qlab.PatchKind{qlab.PatchAudio, qlab.PatchNetwork, qlab.PatchStage} {
+		patches, err := settings.Patches(kind)
+		if err != nil {
+			fmt.Printf("%s: %v\n", kind, err)
+			continue
+		}
+		fmt.Printf("%s (%d)\n", kind, len(patches))
+		for _, patch := range patches {
+			fmt.Printf("  %-24s %s\n", patch.Name, patch.UniqueID)
+		}
+	}
+	return nil
+}
+
+func runRenumber(opts globalOptions, args []string) error {
+	fs := flag.NewFlagSet("renumber", flag.ExitOnError)
+	start := fs.Float64("start", 1, "number of the first cue")
+	increment := fs.Float64("increment", 1, "step between cue numbers")
+	force := fs.Bool("force", false, "take numbers held by cues in other cue lists")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected one cue list")
+	}
+
+	workspace, err := connect(opts)
+	if err != nil {
+		return err
+	}
+	defer workspace.Close()
+
+	workspace.SetForceCueNumbers(*force)
+	renumbered, err := workspace.RenumberCues(fs.Arg(0), *start, *increment)
+	for _, cue := range renumbered {
+		fmt.Printf("%s -> %s\n", cue.OldNumber, cue.NewNumber)
+	}
+	return err
+}
+
+func runClear(opts globalOptions, args []string) error {
+	fs := flag.NewFlagSet("clear", flag.ExitOnError)
+	list := fs.String("list", "", "delete this cue list and every cue in it instead")
+	externalID := fs.String("external-id", "", "also delete cues stamped with a stable ID from this source property")
+	externalIDProperty := fs.String("external-id-property", "", "QLab property external IDs are stamped in (default the notes)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	workspace, err := connect(opts)
+	if err != nil {
+		return err
+	}
+	defer workspace.Close()
+
+	if *list != "" {
+		return workspace.DeleteCueList(*list)
+	}
+	workspace.SetExternalIDs(*externalID, *externalIDProperty)
+	deleted, err := workspace.ClearManagedCues()
+	fmt.Printf("Deleted %d cues\n", deleted)
+	return err
+}
+
+func runResolutions(opts globalOptions, args []string) error {
+	fs := flag.NewFlagSet("resolutions", flag.ExitOnError)
+	forget := fs.String("forget", "", "forget the choices for these comma-separated cues")
+	forgetAll := fs.Bool("forget-all", false, "forget every remembered choice")
+	path, err := fileArg(fs, args)
+	if err != nil {
+		return err
+	}
+
+	workspace, err := connect(opts)
+	if err != nil {
+		return err
+	}
+	defer workspace.Close()
+
+	if *forget != "" || *forgetAll {
+		var cues []string
+		if !*forgetAll {
+			cues = strings.Split(*forget, ",")
+		}
+		forgotten, err := workspace.ForgetResolutions(path, cues...)
+		fmt.Printf("Forgot %d choices\n", forgotten)
+		return err
+	}
+	remembered, err := workspace.RememberedResolutions(path)
+	if err != nil {
+		return err
+	}
+	for _, resolution := range remembered {
+		fmt.Printf("%s (%s)\n", resolution, resolution.DecidedAt.Format("2006-01-02 15:04"))
+	}
+	return nil
+}
+
+func runVerify(opts globalOptions, args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("expected at most one cue file argument")
+	}
+
+	workspace, err := connect(opts)
+	if err != nil {
+		return err
+	}
+	defer workspace.Close()
+
+	warnings, err := workspace.ValidateAllCues()
+	if err != nil {
+		return err
+	}
+	for _, warning := range warnings {
+		fmt.Printf("warning: %s\n", warning)
+	}
+
+	if fs.NArg() == 0 {
+		fmt.Printf("%d cue warnings\n", len(warnings))
+		return printTargetReport(workspace, nil)
+	}
+
+	path := fs.Arg(0)
+	workspaceData, err := loadWorkspaceData(path)
+	if err != nil {
+		return err
+	}
+	report := workspace.Validate(workspaceData)
+	for _, problem := range report.Problems {
+		fmt.Printf("%s: %s\n", problem.Severity, problem)
+	}
+	if !report.OK() {
+		return errInvalidSource
+	}
+	if err := printTargetReport(workspace, workspaceData); err != nil {
+		return err
+	}
+	comparison, err := workspace.PerformThreeWayComparison(path, workspaceData)
+	if err != nil {
+		return err
+	}
+	if changes := printPlan(comparison); changes > 0 {
+		return errOutOfSync
+	}
+	fmt.Println("QLab matches the source file")
+	return nil
+}
+
+// printTargetReport validates the cue targets of a cue file, or of QLab when workspaceData
+// is nil, and prints any problems
+func printTargetReport(workspace *qlab.Workspace, workspaceData map[string]any) error {
+	report, err := workspace.ValidateTargets(workspaceData)
+	if err != nil {
+		return err
+	}
+	for _, problem := range report.Problems {
+		fmt.Printf("target: %s\n", problem)
+	}
+	if !report.OK() {
+		return errBadTargets
+	}
+	return nil
+}
+
+func runTail(opts globalOptions, args []string) error {
+	fs := flag.NewFlagSet("tail", flag.ExitOnError)
+	reconnect := fs.Bool("reconnect", false, "reconnect automatically when QLab goes away")
+	heartbeat := fs.Duration("heartbeat", 0, "check QLab's health at this interval and print changes (0 disables)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	workspace, err := connect(opts)
+	if err != nil {
+		return err
+	}
+	defer workspace.Close()
+
+	workspace.SetAutoReconnect(*reconnect)
+	workspace.OnReconnect(func(event qlab.ReconnectEvent) {
+		fmt.Fprintf(os.Stderr, "reconnect %s (attempt %d)\n", event.State, event.Attempt)
+	})
+	if *heartbeat > 0 {
+		if err := workspace.StartHeartbeat(*heartbeat); err != nil {
+			return err
+		}
+		go func() {
+			for change := range workspace.HealthChanges() {
+				fmt.Fprintf(os.Stderr, "healthy %t\n", change.Healthy)
+			}
+		}()
+	}
+
+	err = workspace.StartUpdateListener(func(address string, args []any) {
+		fmt.Printf("%s %v\n", address, args)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(os.Stderr, "Listening for QLab updates, press Ctrl-C to stop")
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	<-signals
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return workspace.Shutdown(ctx)
+}
+
+func runMock(opts globalOptions, args []string) error {
+	fs := flag.NewFlagSet("mock", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	server := qlab.NewMockOSCServer(opts.host, opts.port)
+	if fs.NArg() > 0 {
+		scenario, err := qlab.LoadMockScenario(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		if err := server.LoadScenario(scenario); err != nil {
+			return err
+		}
+	}
+	if err := server.Start(); err != nil {
+		return err
+	}
+	defer func() { _ = server.Stop() }()
+
+	fmt.Fprintf(os.Stderr, "Mock QLab workspace %s on %s:%d, press Ctrl-C to stop\n", server.GetWorkspaceID(), opts.host, opts.port)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	<-signals
+	return nil
+}
+
+// printPlan prints the cues that differ between source and QLab and returns how many there are
+func printPlan(comparison *qlab.ThreeWayComparison) int {
+	numbers := make([]string, 0, len(comparison.CueResults))
+	for number := range comparison.CueResults {
+		numbers = append(numbers, number)
+	}
+	sort.Strings(numbers)
+
+	for _, dup := range comparison.Duplicates {
+		if len(dup.Suffixed) > 0 {
+			fmt.Printf("duplicate %s at %s renumbered %s\n", dup.Identifier, strings.Join(dup.Paths[1:], ", "), strings.Join(dup.Suffixed, ", "))
+		} else {
+			fmt.Printf("duplicate %s at %s\n", dup.Identifier, strings.Join(dup.Paths, ", "))
+		}
+	}
+
+	if comparison.Media != nil {
+		for _, media := range comparison.Media.Copied() {
+			fmt.Printf("%-8s %-24s %s -> %s\n", media.Mode, media.Cue, media.Source, media.Target)
+		}
+	}
+
+	changes := 0
+	for _, number := range numbers {
+		result := comparison.CueResults[number]
+		if !result.HasChanged {
+			continue
+		}
+		changes++
+		fmt.Printf("%-8s %-24s %s\n", result.Action, number, result.Reason)
+		fields := make([]string, 0, len(result.ModifiedFields))
+		for field := range result.ModifiedFields {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			fmt.Printf("         %s: %s\n", field, result.ModifiedFields[field])
+		}
+		if result.ColorMapping != "" {
+			fmt.Printf("         color mapped: %s\n", result.ColorMapping)
+		}
+	}
+	fmt.Printf("%d of %d cues need changes\n", changes, len(comparison.CueResults))
+	return changes
+}
@@ -1,6 +1,7 @@
 package integration
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"testing"
@@ -113,7 +114,7 @@ func TestRealQLabEmptyPasscode(t *testing.T) {
 	// This test is designed to work with workspaces that have no passcode set
 	if err != nil {
 		// Check if it's a passcode error - if so, that's expected for protected workspaces
-		if err.Error() == "QLab authentication failed - incorrect passcode. Check your passcode in the CUE file, config file, or --passcode flag" {
+		if errors.Is(err, qlab.ErrAuthFailed) {
 			t.Skipf("QLab workspace requires a passcode - skipping empty passcode test. Error: %v", err)
 		}
 		t.Fatalf("Failed to initialize connection to QLab with empty passcode: %v", err)
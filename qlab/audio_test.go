@@ -0,0 +1,133 @@
+package qlab
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseLevelMatrix tests reading main, output, crosspoint and gang levels from a
+// "levels" object
+func TestParseLevelMatrix(t *testing.T) {
+	cueData := map[string]any{
+		"levels": map[string]any{
+			"main":        -3.0,
+			"outputs":     []any{-6.0, -6.0},
+			"crosspoints": []any{[]any{1.0, 2.0, -60.0}, map[string]any{"row": 2.0, "column": 1.0, "db": -60.0}},
+			"gangs":       []any{[]any{0.0, 1.0, "A"}, map[string]any{"row": 0.0, "column": 2.0, "gang": "A"}, []any{1.0, 1.0}},
+		},
+	}
+
+	expected := []FadeLevel{{0, 0, -3}, {0, 1, -6}, {0, 2, -6}, {1, 2, -60}, {2, 1, -60}}
+	levels := parseFadeLevels(cueData)
+	if len(levels) != len(expected) {
+		t.Fatalf("Expected %d levels, got %+v", len(expected), levels)
+	}
+	for i, level := range levels {
+		if level != expected[i] {
+			t.Errorf("Level %d: expected %+v, got %+v", i, expected[i], level)
+		}
+	}
+
+	gangs := parseLevelGangs(cueData)
+	if len(gangs) != 2 || gangs[0] != (LevelGang{0, 1, "A"}) || gangs[1] != (LevelGang{0, 2, "A"}) {
+		t.Errorf("Expected two output gangs, skipping the malformed one, got %+v", gangs)
+	}
+
+	outputs := parseFadeLevels(map[string]any{"levels": map[string]any{"outputs": map[string]any{"4": -10.0, "main": -1.0}}})
+	if len(outputs) != 1 || outputs[0] != (FadeLevel{0, 4, -10}) {
+		t.Errorf("Expected output 4 from an outputs object, got %+v", outputs)
+	}
+}
+
+// TestAudioCueLevels tests that audio cue levels and gangs are sent after the file and that
+// level changes in the source are detected
+func TestAudioCueLevels(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+
+	cueData := map[string]any{
+		"type":       "audio",
+		"name":       "Storm",
+		"fileTarget": "/sounds/storm.wav",
+		"levels": map[string]any{
+			"main":        -3.0,
+			"outputs":     []any{-6.0, -6.0},
+			"crosspoints": []any{[]any{1.0, 2.0, -60.0}},
+			"gangs":       []any{[]any{0.0, 1.0, "A"}, []any{0.0, 2.0, "A"}},
+		},
+	}
+	mockServer.ClearReceivedMessages()
+	cueID, err := workspace.createCueWithoutTarget(cueData, "7")
+	if err != nil {
+		t.Fatalf("createCueWithoutTarget failed: %v", err)
+	}
+
+	cue := mockServer.GetCue(cueID)
+	if cue == nil {
+		t.Fatalf("Expected cue %s to exist", cueID)
+	}
+	expected := map[string]string{
+		"level/0/0": "-3",
+		"level/0/1": "-6",
+		"level/0/2": "-6",
+		"level/1/2": "-60",
+		"gang/0/1":  "A",
+		"gang/0/2":  "A",
+	}
+	for property, value := range expected {
+		if got := cue.Properties[property]; got != value {
+			t.Errorf("Expected %s=%q, got %q", property, value, got)
+		}
+	}
+
+	fileSet, levelSet := -1, -1
+	for i, msg := range mockServer.GetReceivedMessages() {
+		if fileSet < 0 && strings.HasSuffix(msg.Address, "/cue_id/"+cueID+"/fileTarget") {
+			fileSet = i
+		}
+		if levelSet < 0 && strings.HasSuffix(msg.Address, "/cue_id/"+cueID+"/level") {
+			levelSet = i
+		}
+	}
+	if fileSet < 0 || levelSet < fileSet {
+		t.Errorf("Expected levels set after the file, got file at %d and first level at %d", fileSet, levelSet)
+	}
+
+	// QLab reports the master level only; the other cells are carried from the source
+	current := map[string]any{"type": "Audio", "name": "Storm", "levels": []any{[]any{0.0, 0.0, -3.0}}}
+	if diffs := workspace.compareCuePropertiesDetailed(cueData, current); len(diffs) != 0 {
+		t.Errorf("Expected cells QLab doesn't report to be ignored, got %v", diffs)
+	}
+	source := map[string]any{"cues": []any{map[string]any{"number": "7", "type": "audio", "levels": cueData["levels"]}}}
+	snapshot := map[string]any{"cues": []any{map[string]any{"number": "7", "type": "Audio", "name": "Storm", "levels": []any{[]any{0.0, 0.0, -3.0}}}}}
+	workspace.carryLevels(source, snapshot)
+	cached := snapshot["cues"].([]any)[0].(map[string]any)
+
+	changed := map[string]any{
+		"type": "audio",
+		"name": "Storm",
+		"levels": map[string]any{
+			"main":        -3.0,
+			"outputs":     []any{-6.0, -6.0},
+			"crosspoints": []any{[]any{1.0, 2.0, -12.0}},
+			"gangs":       []any{[]any{0.0, 1.0, "A"}, []any{0.0, 2.0, "A"}},
+		},
+	}
+	if diffs := workspace.compareCuePropertiesDetailed(cueData, cached); len(diffs) != 0 {
+		t.Errorf("Expected the source to match its carried cache, got %v", diffs)
+	}
+	if diffs := workspace.compareCuePropertiesDetailed(changed, cached); diffs["levels"] == "" {
+		t.Errorf("Expected a changed crosspoint to be detected, got %v", diffs)
+	}
+	regang := map[string]any{"type": "audio", "name": "Storm", "levels": map[string]any{"gangs": []any{[]any{0.0, 1.0, "B"}}}}
+	if diffs := workspace.compareCuePropertiesDetailed(regang, cached); diffs["levels"] == "" {
+		t.Errorf("Expected a changed gang to be detected, got %v", diffs)
+	}
+
+	if err := workspace.updateCueProperties(cueID, changed); err != nil {
+		t.Fatalf("updateCueProperties failed: %v", err)
+	}
+	if got := mockServer.GetCue(cueID).Properties["level/1/2"]; got != "-12" {
+		t.Errorf("Expected the updated crosspoint -12, got %q", got)
+	}
+}
@@ -0,0 +1,143 @@
+package qlab
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// AuditEntry records a change sent to QLab: a property set, a cue created, moved or
+// deleted, and the like. Queries and playback commands aren't audited.
+type AuditEntry struct {
+	Time        time.Time     `json:"time"`                  // When the change was sent, by the local clock
+	WorkspaceID string        `json:"workspaceID,omitempty"` // Workspace the change was sent to
+	Address     string        `json:"address"`
+	Args        []any         `json:"args,omitempty"`
+	Status      string        `json:"status"`          // "ok", "error" or "denied" as QLab replied, "timeout", "sent" without a reply, or "failed"
+	Error       string        `json:"error,omitempty"` // Why the change failed, when it did
+	Duration    time.Duration `json:"duration"`        // Until QLab replied or the send gave up
+}
+
+// AuditSink receives an AuditEntry for every change sent to QLab. Audit is called from any
+// goroutine and must not block.
+type AuditSink interface {
+	Audit(entry AuditEntry)
+}
+
+// AuditFunc adapts a function to an AuditSink
+type AuditFunc func(entry AuditEntry)
+
+// Audit calls f(entry)
+func (f AuditFunc) Audit(entry AuditEntry) {
+	f(entry)
+}
+
+// SetAuditSink sets where changes sent to QLab are recorded, or nil to stop recording them
+func (q *Workspace) SetAuditSink(sink AuditSink) {
+	q.auditSink = sink
+}
+
+// AuditLog is an AuditSink that writes each entry as a line of JSON
+type AuditLog struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+	err    error
+}
+
+// NewAuditLog creates an audit log that writes to w
+func NewAuditLog(w io.Writer) *AuditLog {
+	return &AuditLog{w: w}
+}
+
+// OpenAuditLog creates an audit log that appends to a file, created if needed
+func OpenAuditLog(path string) (*AuditLog, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return &AuditLog{w: file, closer: file}, nil
+}
+
+// Audit writes an entry. A write error is kept and returned by Err and Close.
+func (l *AuditLog) Audit(entry AuditEntry) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		// Arguments are strings, numbers and booleans, so this only happens for odd values
+		entry.Args = []any{fmt.Sprint(entry.Args...)}
+		line, _ = json.Marshal(entry)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.w.Write(append(line, '\n')); err != nil && l.err == nil {
+		l.err = err
+	}
+}
+
+// Err returns the first error writing the log
+func (l *AuditLog) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// Close closes the file of a log opened with OpenAuditLog and returns the first error
+// writing it
+func (l *AuditLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closer != nil {
+		if err := l.closer.Close(); err != nil && l.err == nil {
+			l.err = err
+		}
+		l.closer = nil
+	}
+	return l.err
+}
+
+// audit records a change and how QLab answered it, if an audit sink is set
+func (q *Workspace) audit(address, input string, args []any, sentAt time.Time, reply []any, err error) {
+	if q.auditSink == nil || !isChange(address, input, args) {
+		return
+	}
+	if input != "" {
+		args = append([]any{input}, args...)
+	}
+	entry := AuditEntry{
+		Time:        sentAt,
+		WorkspaceID: q.workspace_id,
+		Address:     address,
+		Args:        args,
+		Duration:    time.Since(sentAt),
+	}
+	switch {
+	case errors.Is(err, errNoReply):
+		entry.Status = "sent"
+	case err != nil:
+		entry.Status = "failed"
+		entry.Error = err.Error()
+	case isTimeoutReply(reply):
+		entry.Status = "timeout"
+	default:
+		entry.Status = "ok"
+		if parsed, err := parseReply(address, reply); parsed != nil {
+			if parsed.Status != "" {
+				entry.Status = parsed.Status
+			}
+			if err != nil {
+				entry.Error = err.Error()
+			}
+		} else if err != nil {
+			entry.Status = "failed"
+			entry.Error = err.Error()
+		}
+	}
+	q.auditSink.Audit(entry)
+}
+
+// errNoReply marks a change audited as sent, for messages no reply is waited for
+var errNoReply = errors.New("no reply expected")
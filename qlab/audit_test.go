@@ -0,0 +1,83 @@
+package qlab
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestAuditLog tests that changes sent to QLab are audited with their reply status and
+// queries are not
+func TestAuditLog(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+
+	var mu sync.Mutex
+	var entries []AuditEntry
+	workspace.SetAuditSink(AuditFunc(func(entry AuditEntry) {
+		mu.Lock()
+		defer mu.Unlock()
+		entries = append(entries, entry)
+	}))
+
+	cueID, err := workspace.createCueWithoutTarget(map[string]any{"type": "memo", "number": "1"}, "1")
+	if err != nil {
+		t.Fatalf("Failed to create cue: %v", err)
+	}
+	if err := workspace.setCueProperty(cueID, "name", "Preshow"); err != nil {
+		t.Fatalf("Failed to set name: %v", err)
+	}
+	mockServer.SetFault("/notes", MockFault{Error: "busy"})
+	if err := workspace.setCueProperty(cueID, "notes", "Check levels"); err == nil {
+		t.Error("Expected the faulted property set to fail")
+	}
+	if _, err := workspace.GetCueValues(cueID, []string{"name"}); err != nil {
+		t.Fatalf("GetCueValues failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	var name, notes *AuditEntry
+	for i, entry := range entries {
+		if entry.Status == "" || entry.WorkspaceID != workspace.WorkspaceID() {
+			t.Errorf("Expected a status and workspace ID, got %+v", entry)
+		}
+		if strings.HasSuffix(entry.Address, "/valuesForKeys") || strings.HasSuffix(entry.Address, "/cueLists") {
+			t.Errorf("Expected queries not to be audited, got %s", entry.Address)
+		}
+		switch {
+		case strings.HasSuffix(entry.Address, cueID+"/name"):
+			name = &entries[i]
+		case strings.HasSuffix(entry.Address, cueID+"/notes"):
+			notes = &entries[i]
+		}
+	}
+	if !strings.HasSuffix(entries[0].Address, "/new") {
+		t.Errorf("Expected the cue creation audited first, got %s", entries[0].Address)
+	}
+	if name == nil || name.Status != "ok" || len(name.Args) != 1 || name.Args[0] != "Preshow" {
+		t.Errorf("Expected the name set audited as ok, got %+v", name)
+	}
+	if notes == nil || notes.Status != "error" {
+		t.Errorf("Expected the faulted notes set audited as an error, got %+v", notes)
+	}
+
+	var buf bytes.Buffer
+	auditLog := NewAuditLog(&buf)
+	for _, entry := range entries {
+		auditLog.Audit(entry)
+	}
+	if err := auditLog.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(entries) {
+		t.Fatalf("Expected one line per entry, got %d lines for %d entries", len(lines), len(entries))
+	}
+	var decoded AuditEntry
+	if err := json.Unmarshal([]byte(lines[0]), &decoded); err != nil || decoded.Address != entries[0].Address {
+		t.Errorf("Expected the first line to decode to the first entry, got %+v (%v)", decoded, err)
+	}
+}
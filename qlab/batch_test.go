@@ -0,0 +1,135 @@
+package qlab
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestCreateCuesBatch tests that batched creation sets every property and reconciles cleanly
+func TestCreateCuesBatch(t *testing.T) {
+	for _, bundles := range []bool{false, true} {
+		workspace, mockServer := setupWorkspaceWithCleanup(t)
+		workspace.SetBatchWindow(8)
+		workspace.SetBatchBundles(bundles)
+
+		cues := []map[string]any{
+			{"type": "memo", "number": "1", "name": "First"},
+			{"type": "memo", "number": "2", "name": "Second"},
+			{"type": "memo", "number": "3", "name": "Third"},
+		}
+
+		result, err := workspace.CreateCuesBatch(cues)
+		if err != nil {
+			t.Fatalf("CreateCuesBatch (bundles=%v) failed: %v", bundles, err)
+		}
+		if !result.OK() {
+			t.Fatalf("Expected OK batch result, got %v", result.Err())
+		}
+		if len(result.CreatedCueIDs) != 3 {
+			t.Fatalf("Expected 3 created cues, got %d", len(result.CreatedCueIDs))
+		}
+
+		for i, cueID := range result.CreatedCueIDs {
+			cue := mockServer.GetCue(cueID)
+			if cue == nil {
+				t.Fatalf("Cue %s not found in mock server", cueID)
+			}
+			if cue.Name != cues[i]["name"] || cue.Number != cues[i]["number"] {
+				t.Errorf("Cue %s = [%s] %s, want [%s] %s", cueID, cue.Number, cue.Name, cues[i]["number"], cues[i]["name"])
+			}
+		}
+	}
+}
+
+// TestSetBatchWindow tests that negative windows disable batching
+func TestSetBatchWindow(t *testing.T) {
+	workspace := &Workspace{}
+	workspace.SetBatchWindow(-1)
+	if workspace.batchWindow != 0 {
+		t.Errorf("Expected batch window 0, got %d", workspace.batchWindow)
+	}
+	workspace.SetBatchWindow(16)
+	if workspace.batchWindow != 16 {
+		t.Errorf("Expected batch window 16, got %d", workspace.batchWindow)
+	}
+}
+
+// TestNextSendGroup tests that bundles group consecutive messages for the same cue up to the window
+func TestNextSendGroup(t *testing.T) {
+	queue := []queuedMessage{
+		{cueID: "a", property: "name"},
+		{cueID: "a", property: "number"},
+		{cueID: "a", property: "notes"},
+		{cueID: "b", property: "name"},
+	}
+
+	workspace := &Workspace{}
+	if got := len(workspace.nextSendGroup(queue, 8)); got != 1 {
+		t.Errorf("Without bundles expected group of 1, got %d", got)
+	}
+
+	workspace.SetBatchBundles(true)
+	if got := len(workspace.nextSendGroup(queue, 8)); got != 3 {
+		t.Errorf("With bundles expected group of 3, got %d", got)
+	}
+	if got := len(workspace.nextSendGroup(queue, 2)); got != 2 {
+		t.Errorf("With window 2 expected group of 2, got %d", got)
+	}
+}
+
+// TestBatchResultErr tests that batch problems are summarized in a single error
+func TestBatchResultErr(t *testing.T) {
+	result := &BatchResult{}
+	if result.Err() != nil {
+		t.Errorf("Expected nil error for empty result, got %v", result.Err())
+	}
+
+	result.Failures = []BatchFailure{{CueID: "a", Property: "name", Err: errors.New("boom")}}
+	result.MissingCueIDs = []string{"b"}
+	if result.OK() {
+		t.Error("Expected result with failures to not be OK")
+	}
+	if result.Err() == nil {
+		t.Error("Expected error for result with failures")
+	}
+}
+
+// TestPipelineRespectsMaxInFlight tests that pipelined messages each take an in-flight slot
+func TestPipelineRespectsMaxInFlight(t *testing.T) {
+	for _, bundles := range []bool{false, true} {
+		workspace, _ := setupWorkspaceWithCleanup(t)
+		workspace.SetBatchWindow(8)
+		workspace.SetBatchBundles(bundles)
+		workspace.SetMaxInFlight(2)
+
+		cueID, err := workspace.createCueWithoutTarget(map[string]any{"type": "memo", "name": "Queried"}, "")
+		if err != nil {
+			t.Fatalf("Failed to create cue: %v", err)
+		}
+		var queue []queuedMessage
+		for range 6 {
+			queue = append(queue, queuedMessage{cueID: cueID, property: "name", address: workspace.addressBuilder.BuildCuePropertyAddress(cueID, "name")})
+		}
+
+		handled, peak := 0, 0
+		workspace.pipelineMessages(queue, func(m queuedMessage, reply []any, err error) {
+			if err == nil {
+				err = checkReplyStatus(reply)
+			}
+			if err != nil {
+				t.Errorf("Query of %s failed: %v", m.address, err)
+			}
+			handled++
+			peak = max(peak, workspace.SendStats().InFlight)
+		})
+		if handled != len(queue) {
+			t.Errorf("Expected %d messages handled (bundles=%v), got %d", len(queue), bundles, handled)
+		}
+		if peak == 0 || peak > 2 {
+			t.Errorf("Expected pipelined messages, at most 2 in flight (bundles=%v), got %d", bundles, peak)
+		}
+		if stats := workspace.SendStats(); stats.InFlight != 0 {
+			t.Errorf("Expected every in-flight slot freed (bundles=%v), got %+v", bundles, stats)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package qlab
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// CueEdit is one transformation applied by BulkEdit. Apply receives a cue's data, with
+// the properties in Reads read from QLab, and returns the properties to change and their
+// new values; returning none leaves the cue alone. Edits given together see the changes
+// of the edits before them.
+type CueEdit struct {
+	Reads []string // Properties Apply needs that /cueLists doesn't report
+	Apply func(cue map[string]any) map[string]string
+}
+
+// BulkEditChange is a property change made, or previewed, by BulkEdit
+type BulkEditChange struct {
+	UniqueID string
+	Number   string
+	Property string
+	OldValue string
+	NewValue string
+}
+
+func (c BulkEditChange) String() string {
+	cue := c.Number
+	if cue == "" {
+		cue = c.UniqueID
+	}
+	return fmt.Sprintf("cue %s %s: %q -> %q", cue, c.Property, c.OldValue, c.NewValue)
+}
+
+// BulkEditResult is what BulkEdit changed
+type BulkEditResult struct {
+	Changes  []BulkEditChange // Every property set sent, including failed ones
+	Failures []BatchFailure   // Property sets QLab rejected or never answered
+}
+
+// BulkEdit applies edits to every cue, in every cue list, for which match returns true
+// (nil matches every cue), sending the changes as batched property sets. With SetDryRun
+// the changes are logged instead of sent; PreviewBulkEdit returns them without logging.
+func (q *Workspace) BulkEdit(match func(*Cue) bool, edits ...CueEdit) (*BulkEditResult, error) {
+	if err := q.checkWritable("bulk edit"); err != nil {
+		return nil, err
+	}
+	q.opMu.Lock()
+	defer q.opMu.Unlock()
+	defer q.InvalidateCueCache()
+
+	changes, err := q.planBulkEdit(match, edits)
+	if err != nil {
+		return nil, err
+	}
+	result := &BulkEditResult{Changes: changes}
+	if len(changes) == 0 {
+		return result, nil
+	}
+
+	batch, err := q.runBatched(func() error {
+		for _, change := range changes {
+			property := change.Property
+			if property == "fileTarget" {
+				property = "file" // File targets are set through /file, as in updateCueProperties
+			}
+			if err := q.setCueProperty(change.UniqueID, property, change.NewValue); err != nil {
+				return fmt.Errorf("failed to set %s of cue %s: %w", change.Property, change.UniqueID, err)
+			}
+		}
+		return nil
+	})
+	if batch != nil {
+		result.Failures = batch.Failures
+	}
+	if err != nil {
+		return result, fmt.Errorf("bulk edit incomplete: %w", err)
+	}
+	q.log().Infof("Bulk edit changed %d properties", len(changes))
+	return result, nil
+}
+
+// PreviewBulkEdit returns the changes BulkEdit would make without sending anything
+func (q *Workspace) PreviewBulkEdit(match func(*Cue) bool, edits ...CueEdit) ([]BulkEditChange, error) {
+	return q.planBulkEdit(match, edits)
+}
+
+// planBulkEdit applies edits to copies of the matching cues and returns the changes
+func (q *Workspace) planBulkEdit(match func(*Cue) bool, edits []CueEdit) ([]BulkEditChange, error) {
+	var reads []string
+	for _, edit := range edits {
+		if edit.Apply == nil {
+			return nil, fmt.Errorf("bulk edit has no Apply function")
+		}
+		for _, property := range edit.Reads {
+			if !slices.Contains(cueListsProperties, property) && !slices.Contains(reads, property) {
+				reads = append(reads, property)
+			}
+		}
+	}
+
+	var matched []map[string]any
+	var walkErr error
+	if _, err := q.findCueData(func(cueData map[string]any) bool {
+		cue, err := cueFromMap(cueData, q.log())
+		if err != nil {
+			walkErr = err
+			return true
+		}
+		if match == nil || match(cue) {
+			matched = append(matched, cueData)
+		}
+		return false
+	}); err != nil {
+		return nil, err
+	}
+	if walkErr != nil {
+		return nil, walkErr
+	}
+
+	var changes []BulkEditChange
+	for _, cueData := range matched {
+		uniqueID, _ := cueData["uniqueID"].(string)
+		if uniqueID == "" {
+			continue
+		}
+		cue := maps.Clone(cueData)
+		if len(reads) > 0 {
+			values, err := q.GetCueValues(uniqueID, reads)
+			if err != nil {
+				return nil, err
+			}
+			for _, property := range reads {
+				if value, ok := cueValue(values, property); ok {
+					cue[property] = value
+				}
+			}
+		}
+
+		original := maps.Clone(cue)
+		changed := make(map[string]bool)
+		for _, edit := range edits {
+			for property, value := range edit.Apply(maps.Clone(cue)) {
+				cue[property] = value
+				changed[property] = true
+			}
+		}
+
+		number := q.normalizeProperty(cue["number"])
+		for _, property := range slices.Sorted(maps.Keys(changed)) {
+			oldValue, newValue := q.normalizeProperty(original[property]), q.normalizeProperty(cue[property])
+			if oldValue == newValue {
+				continue
+			}
+			changes = append(changes, BulkEditChange{UniqueID: uniqueID, Number: number, Property: property, OldValue: oldValue, NewValue: newValue})
+		}
+	}
+	return changes, nil
+}
+
+// ReplaceInProperty replaces matches of pattern in a text property such as "name" or
+// "notes", expanding $1-style references in replacement as regexp.ReplaceAllString does
+func ReplaceInProperty(property string, pattern *regexp.Regexp, replacement string) CueEdit {
+	return CueEdit{
+		Reads: []string{property},
+		Apply: func(cue map[string]any) map[string]string {
+			value, _ := cue[property].(string)
+			if replaced := pattern.ReplaceAllString(value, replacement); replaced != value {
+				return map[string]string{property: replaced}
+			}
+			return nil
+		},
+	}
+}
+
+// PrefixFileTargets puts prefix in front of every file target that doesn't start with it
+// already, such as a new media folder
+func PrefixFileTargets(prefix string) CueEdit {
+	return CueEdit{
+		Reads: []string{"fileTarget"},
+		Apply: func(cue map[string]any) map[string]string {
+			fileTarget, _ := cue["fileTarget"].(string)
+			if fileTarget == "" || strings.HasPrefix(fileTarget, prefix) {
+				return nil
+			}
+			return map[string]string{"fileTarget": prefix + fileTarget}
+		},
+	}
+}
+
+// RecolorByType sets the color of cues by type, e.g. {"audio": "blue"}; cue types without
+// a color keep theirs
+func RecolorByType(colors map[string]string) CueEdit {
+	return CueEdit{
+		Apply: func(cue map[string]any) map[string]string {
+			cueType, _ := cue["type"].(string)
+			if color, ok := colors[strings.ToLower(cueType)]; ok {
+				return map[string]string{"colorName": color}
+			}
+			return nil
+		},
+	}
+}
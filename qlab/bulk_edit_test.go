@@ -0,0 +1,73 @@
+package qlab
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestBulkEdit tests that bulk edits are previewed without sending anything and then
+// applied to the matching cues only
+func TestBulkEdit(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+
+	var ids []string
+	for _, cueData := range []map[string]any{
+		{"type": "audio", "name": "Intro music", "fileTarget": "music/intro.mp3"},
+		{"type": "audio", "name": "Storm", "fileTarget": "sfx/storm.wav"},
+		{"type": "memo", "name": "Intro note"},
+	} {
+		cueID, err := workspace.createCueWithoutTarget(cueData, "")
+		if err != nil {
+			t.Fatalf("createCueWithoutTarget failed: %v", err)
+		}
+		ids = append(ids, cueID)
+	}
+
+	rename := ReplaceInProperty("name", regexp.MustCompile(`^Intro (\w+)`), "Opening $1")
+	sent := len(mockServer.GetReceivedMessages())
+	changes, err := workspace.PreviewBulkEdit(nil, rename)
+	if err != nil {
+		t.Fatalf("PreviewBulkEdit failed: %v", err)
+	}
+	if len(changes) != 2 || changes[0].NewValue != "Opening music" || changes[1].NewValue != "Opening note" {
+		t.Errorf("Expected both intro cues renamed, got %v", changes)
+	}
+	for _, msg := range mockServer.GetReceivedMessages()[sent:] {
+		if strings.HasSuffix(msg.Address, "/name") {
+			t.Errorf("Expected the preview to send nothing, got %s", msg.Address)
+		}
+	}
+
+	isAudio := func(cue *Cue) bool { return cue.Type == CueTypeAudio }
+	result, err := workspace.BulkEdit(isAudio, PrefixFileTargets("/Volumes/Show/"), RecolorByType(map[string]string{CueTypeAudio: "blue"}))
+	if err != nil {
+		t.Fatalf("BulkEdit failed: %v", err)
+	}
+	if len(result.Changes) != 4 || len(result.Failures) != 0 {
+		t.Errorf("Expected a file target and color per audio cue, got %v", result.Changes)
+	}
+	if got := mockServer.GetCue(ids[0]).FileTarget; got != "/Volumes/Show/music/intro.mp3" {
+		t.Errorf("Expected the file target prefixed, got %q", got)
+	}
+	if got := mockServer.GetCue(ids[1]).Properties["colorName"]; got != "blue" {
+		t.Errorf("Expected the audio cue recolored, got %q", got)
+	}
+	if got := mockServer.GetCue(ids[2]).Properties["colorName"]; got != "" {
+		t.Errorf("Expected the memo left alone, got %q", got)
+	}
+
+	// Prefixed file targets aren't prefixed again
+	changes, err = workspace.PreviewBulkEdit(isAudio, PrefixFileTargets("/Volumes/Show/"))
+	if err != nil || len(changes) != 0 {
+		t.Errorf("Expected nothing left to prefix, got %v, %v", changes, err)
+	}
+
+	workspace.SetDryRun(true)
+	if result, err = workspace.BulkEdit(nil, rename); err != nil || len(result.Changes) != 2 {
+		t.Fatalf("Expected a dry run to report the changes, got %v, %v", result, err)
+	}
+	if got := mockServer.GetCue(ids[0]).Name; got != "Intro music" {
+		t.Errorf("Expected a dry run to leave the name, got %q", got)
+	}
+}
@@ -0,0 +1,493 @@
+package qlab
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrCacheDisabled is returned by cache operations when caching is turned off
+var ErrCacheDisabled = errors.New("caching is disabled")
+
+// CacheStore persists the workspace snapshots written after each transmit. Snapshots are
+// grouped by name, made from the base name of the source file they were written for and
+// the QLab host and workspace it was sent to.
+type CacheStore interface {
+	// Save stores a snapshot and returns the key it was stored under
+	Save(name string, data []byte) (string, error)
+	// Load returns the snapshot stored under key
+	Load(key string) ([]byte, error)
+	// List returns the snapshots stored for name, newest first; an empty name lists every snapshot
+	List(name string) ([]CacheEntry, error)
+	// Delete removes the snapshot stored under key
+	Delete(key string) error
+}
+
+// CacheEntry describes a stored snapshot
+type CacheEntry struct {
+	Key     string    // Store-specific key (the file path for FileCacheStore)
+	Name    string    // Source file base name and target, e.g. show@10.0.1.20@<workspace ID>
+	SavedAt time.Time // When the snapshot was stored, by the local clock
+}
+
+// CacheRetention limits how many snapshots are kept. Zero values mean no limit.
+type CacheRetention struct {
+	MaxFiles int           // Snapshots kept per source file and workspace
+	MaxAge   time.Duration // Snapshots older than this are removed
+}
+
+// cacheTimestampLayout is the timestamp in snapshot file names
+const cacheTimestampLayout = "2006-01-02T15-04-05"
+
+// DefaultCacheDirectory returns the directory FileCacheStore uses unless configured otherwise
+func DefaultCacheDirectory() (string, error) {
+	usr, err := user.Current()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current user: %v", err)
+	}
+	return filepath.Join(usr.HomeDir, ".cache", "cuejitsu"), nil
+}
+
+// FileCacheStore keeps snapshots as <name>_<timestamp>.json files in a directory
+type FileCacheStore struct {
+	Dir string
+}
+
+// NewFileCacheStore creates a store that keeps snapshots in dir
+func NewFileCacheStore(dir string) *FileCacheStore {
+	return &FileCacheStore{Dir: dir}
+}
+
+// Save writes a snapshot file, created along with the directory if needed
+func (s *FileCacheStore) Save(name string, data []byte) (string, error) {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %v", err)
+	}
+
+	// Don't overwrite a snapshot saved earlier in the same second
+	base := fmt.Sprintf("%s_%s", name, time.Now().Format(cacheTimestampLayout))
+	path := filepath.Join(s.Dir, base+".json")
+	for i := 1; ; i++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			break
+		}
+		path = filepath.Join(s.Dir, fmt.Sprintf("%s-%d.json", base, i))
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write cache file: %v", err)
+	}
+	return path, nil
+}
+
+// Load reads a snapshot file
+func (s *FileCacheStore) Load(key string) ([]byte, error) {
+	data, err := os.ReadFile(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache file: %v", err)
+	}
+	return data, nil
+}
+
+// List returns snapshot files ordered by modification time, newest first
+func (s *FileCacheStore) List(name string) ([]CacheEntry, error) {
+	pattern := "*_*.json"
+	if name != "" {
+		pattern = name + "_*.json"
+	}
+	matches, err := filepath.Glob(filepath.Join(s.Dir, pattern))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search for cache files: %v", err)
+	}
+
+	var entries []CacheEntry
+	for _, match := range matches {
+		// The timestamp holds no underscores, so the name is everything before the last one
+		fileName := strings.TrimSuffix(filepath.Base(match), ".json")
+		split := strings.LastIndex(fileName, "_")
+		if name != "" && fileName[:split] != name {
+			continue // Another file's name that starts with this one, e.g. show_extra for show
+		}
+		info, err := os.Stat(match)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, CacheEntry{Key: match, Name: fileName[:split], SavedAt: info.ModTime()})
+	}
+	sortCacheEntries(entries)
+	return entries, nil
+}
+
+// Delete removes a snapshot file
+func (s *FileCacheStore) Delete(key string) error {
+	if err := os.Remove(key); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove cache file: %v", err)
+	}
+	return nil
+}
+
+// MemoryCacheStore keeps snapshots in memory, for tests and short-lived tools
+type MemoryCacheStore struct {
+	mu        sync.Mutex
+	snapshots map[string]memorySnapshot
+	saved     int
+}
+
+type memorySnapshot struct {
+	entry CacheEntry
+	data  []byte
+}
+
+// NewMemoryCacheStore creates an empty in-memory store
+func NewMemoryCacheStore() *MemoryCacheStore {
+	return &MemoryCacheStore{snapshots: make(map[string]memorySnapshot)}
+}
+
+// Save stores a copy of the snapshot
+func (s *MemoryCacheStore) Save(name string, data []byte) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.saved++
+	key := fmt.Sprintf("%s#%d", name, s.saved)
+	s.snapshots[key] = memorySnapshot{
+		entry: CacheEntry{Key: key, Name: name, SavedAt: time.Now()},
+		data:  append([]byte(nil), data...),
+	}
+	return key, nil
+}
+
+// Load returns the snapshot stored under key
+func (s *MemoryCacheStore) Load(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snapshot, ok := s.snapshots[key]
+	if !ok {
+		return nil, fmt.Errorf("no cached snapshot %q", key)
+	}
+	return snapshot.data, nil
+}
+
+// List returns the stored snapshots, newest first
+func (s *MemoryCacheStore) List(name string) ([]CacheEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var entries []CacheEntry
+	for _, snapshot := range s.snapshots {
+		if name == "" || snapshot.entry.Name == name {
+			entries = append(entries, snapshot.entry)
+		}
+	}
+	sortCacheEntries(entries)
+	return entries, nil
+}
+
+// Delete removes the snapshot stored under key
+func (s *MemoryCacheStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.snapshots, key)
+	return nil
+}
+
+// SetSavedAt backdates a snapshot, for exercising age-based retention
+func (s *MemoryCacheStore) SetSavedAt(key string, savedAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if snapshot, ok := s.snapshots[key]; ok {
+		snapshot.entry.SavedAt = savedAt
+		s.snapshots[key] = snapshot
+	}
+}
+
+// sortCacheEntries orders entries newest first; keys break ties so the order is stable
+func sortCacheEntries(entries []CacheEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].SavedAt.Equal(entries[j].SavedAt) {
+			return entries[i].SavedAt.After(entries[j].SavedAt)
+		}
+		return cacheKeyAfter(entries[i].Key, entries[j].Key)
+	})
+}
+
+// cacheKeyAfter compares keys that end in increasing counters or timestamps
+func cacheKeyAfter(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) > len(b)
+	}
+	return a > b
+}
+
+// SetCacheStore sets where cache snapshots are kept (nil restores the default directory)
+func (q *Workspace) SetCacheStore(store CacheStore) {
+	q.cacheStore = store
+}
+
+// SetCacheDirectory keeps cache snapshots as files in dir instead of ~/.cache/cuejitsu
+func (q *Workspace) SetCacheDirectory(dir string) {
+	q.cacheStore = NewFileCacheStore(dir)
+}
+
+// SetCacheRetention sets how many snapshots are kept; it is applied after each snapshot
+// is written and by PruneCache
+func (q *Workspace) SetCacheRetention(retention CacheRetention) {
+	q.cacheRetention = retention
+}
+
+// SetCacheEnabled turns caching on or off. With caching off nothing is written after a
+// transmit and comparisons run without a cache.
+func (q *Workspace) SetCacheEnabled(enabled bool) {
+	q.cacheDisabled = !enabled
+}
+
+// cache returns the configured store
+func (q *Workspace) cache() (CacheStore, error) {
+	if q.cacheDisabled {
+		return nil, ErrCacheDisabled
+	}
+	if q.cacheStore != nil {
+		return q.cacheStore, nil
+	}
+	dir, err := DefaultCacheDirectory()
+	if err != nil {
+		return nil, err
+	}
+	return NewFileCacheStore(dir), nil
+}
+
+// PruneCache removes the snapshots outside the retention policy and returns how many
+// were removed
+func (q *Workspace) PruneCache() (int, error) {
+	store, err := q.cache()
+	if err != nil {
+		return 0, err
+	}
+	return pruneCacheStore(store, q.cacheRetention, time.Now(), q.log())
+}
+
+// CacheSnapshotInfo describes a snapshot written after a transmit, as listed by
+// ListCacheSnapshots
+type CacheSnapshotInfo struct {
+	CacheEntry
+	Metadata *CacheMetadata // Clocks and QLab version when the snapshot was written
+	CueCount int            // Cues in the snapshot, counting those inside groups
+}
+
+// ListCacheSnapshots returns the snapshots written for a source file to this workspace,
+// newest first, so a tool can show the sync history; an empty filePath lists every
+// snapshot. The newest is the baseline the next comparison uses.
+func (q *Workspace) ListCacheSnapshots(filePath string) ([]CacheSnapshotInfo, error) {
+	store, err := q.cache()
+	if err != nil {
+		return nil, err
+	}
+	name := ""
+	if filePath != "" {
+		name = q.cacheName(filePath)
+	}
+	entries, err := store.List(name)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]CacheSnapshotInfo, 0, len(entries))
+	for _, entry := range entries {
+		info := CacheSnapshotInfo{CacheEntry: entry}
+		if data, err := store.Load(entry.Key); err != nil {
+			q.log().Warnf("Failed to read cache snapshot %s: %v", entry.Key, err)
+		} else if workspace, meta, err := decodeCacheSnapshot(data, entry.SavedAt); err != nil {
+			q.log().Warnf("Failed to decode cache snapshot %s: %v", entry.Key, err)
+		} else {
+			info.Metadata = meta
+			info.CueCount = countSnapshotCues(workspace)
+		}
+		snapshots = append(snapshots, info)
+	}
+	return snapshots, nil
+}
+
+// LoadCacheSnapshot returns the workspace data of a snapshot, by the key ListCacheSnapshots
+// gives it, and the metadata it was written with
+func (q *Workspace) LoadCacheSnapshot(key string) (map[string]any, *CacheMetadata, error) {
+	store, err := q.cache()
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := store.Load(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return decodeCacheSnapshot(data, time.Time{})
+}
+
+// DeleteCacheSnapshot removes a snapshot. Deleting the newest makes the one before it the
+// baseline for the next comparison.
+func (q *Workspace) DeleteCacheSnapshot(key string) error {
+	store, err := q.cache()
+	if err != nil {
+		return err
+	}
+	if err := store.Delete(key); err != nil {
+		return err
+	}
+	q.log().Infof("Deleted cache snapshot %s", key)
+	return nil
+}
+
+// RestoreCacheSnapshot rolls the baseline back to an earlier snapshot by saving a copy of
+// it as the newest snapshot for its source file, and returns the copy's key. The snapshots
+// in between are kept.
+func (q *Workspace) RestoreCacheSnapshot(key string) (string, error) {
+	store, err := q.cache()
+	if err != nil {
+		return "", err
+	}
+	entries, err := store.List("")
+	if err != nil {
+		return "", err
+	}
+	index := slices.IndexFunc(entries, func(entry CacheEntry) bool { return entry.Key == key })
+	if index < 0 {
+		return "", fmt.Errorf("no cache snapshot %q", key)
+	}
+	data, err := store.Load(key)
+	if err != nil {
+		return "", err
+	}
+	restored, err := store.Save(entries[index].Name, data)
+	if err != nil {
+		return "", err
+	}
+	q.log().Infof("Restored cache snapshot %s as %s", key, restored)
+	return restored, nil
+}
+
+// countSnapshotCues counts the cues in snapshot workspace data
+func countSnapshotCues(workspace map[string]any) int {
+	data, _ := workspace["data"].([]any)
+	count := 0
+	for _, cueListData := range data {
+		if cueList, ok := cueListData.(map[string]any); ok {
+			if cues, ok := cueList["cues"].([]any); ok {
+				count += len(extractCueIDs(cues))
+			}
+		}
+	}
+	return count
+}
+
+// pruneCacheStore applies a retention policy to every snapshot in a store
+func pruneCacheStore(store CacheStore, retention CacheRetention, now time.Time, logger Logger) (int, error) {
+	if retention.MaxFiles <= 0 && retention.MaxAge <= 0 {
+		return 0, nil
+	}
+	entries, err := store.List("")
+	if err != nil {
+		return 0, err
+	}
+
+	kept := make(map[string]int)
+	removed := 0
+	for _, entry := range entries {
+		expired := retention.MaxAge > 0 && now.Sub(entry.SavedAt) > retention.MaxAge
+		if !expired && (retention.MaxFiles <= 0 || kept[entry.Name] < retention.MaxFiles) {
+			kept[entry.Name]++
+			continue
+		}
+		if err := store.Delete(entry.Key); err != nil {
+			return removed, err
+		}
+		logger.Debugf("Pruned cache snapshot %s", entry.Key)
+		removed++
+	}
+	return removed, nil
+}
+
+// cacheName returns the name snapshots for a source file are stored under. Each QLab host
+// and workspace the file is sent to keeps its own baseline, so syncing one file to a show
+// machine and a backup doesn't mix their states.
+func (q *Workspace) cacheName(filePath string) string {
+	return legacyCacheName(filePath) + q.cacheTarget()
+}
+
+// cacheTarget returns the end of cache names that names the QLab host and workspace
+func (q *Workspace) cacheTarget() string {
+	var target string
+	for _, part := range []string{q.host, q.workspace_id} {
+		if part != "" {
+			target += "@" + cacheNameUnsafe.ReplaceAllString(part, "-")
+		}
+	}
+	return target
+}
+
+// cacheNameUnsafe matches characters kept out of cache names, which become file names
+var cacheNameUnsafe = regexp.MustCompile(`[^A-Za-z0-9.-]`)
+
+// legacyCacheName returns the name snapshots were stored under before they were kept per
+// workspace
+func legacyCacheName(filePath string) string {
+	return strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+}
+
+// loadLatestCache loads the newest snapshot for a source file
+func (q *Workspace) loadLatestCache(filePath string) (CacheEntry, map[string]any, *CacheMetadata, error) {
+	store, err := q.cache()
+	if err != nil {
+		return CacheEntry{}, nil, nil, err
+	}
+	name := q.cacheName(filePath)
+	entries, err := store.List(name)
+	if err != nil {
+		return CacheEntry{}, nil, nil, err
+	}
+	// Fall back to a snapshot written before snapshots were kept per workspace; the next
+	// transmit writes one under the new name
+	if legacy := legacyCacheName(filePath); len(entries) == 0 && legacy != name {
+		if entries, err = store.List(legacy); err != nil {
+			return CacheEntry{}, nil, nil, err
+		}
+		if len(entries) > 0 {
+			q.log().Infof("Using cache snapshot %s, written before snapshots were kept per workspace", entries[0].Key)
+		}
+	}
+	if len(entries) == 0 {
+		return CacheEntry{}, nil, nil, fmt.Errorf("no cache files found for %s", name)
+	}
+
+	entry := entries[0]
+	data, err := store.Load(entry.Key)
+	if err != nil {
+		return entry, nil, nil, err
+	}
+	workspace, meta, err := decodeCacheSnapshot(data, entry.SavedAt)
+	return entry, workspace, meta, err
+}
+
+// decodeCacheSnapshot splits a snapshot into workspace data and its metadata; snapshots
+// written before metadata was recorded fall back to the time they were saved
+func decodeCacheSnapshot(data []byte, savedAt time.Time) (map[string]any, *CacheMetadata, error) {
+	var workspace map[string]any
+	if err := json.Unmarshal(data, &workspace); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal cache data: %v", err)
+	}
+
+	meta := &CacheMetadata{WrittenAt: savedAt}
+	if raw, ok := workspace[cacheMetadataKey]; ok {
+		delete(workspace, cacheMetadataKey)
+		metaJSON, _ := json.Marshal(raw)
+		recorded := &CacheMetadata{}
+		if err := json.Unmarshal(metaJSON, recorded); err == nil {
+			meta = recorded
+		}
+	}
+	return workspace, meta, nil
+}
@@ -0,0 +1,203 @@
+package qlab
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestFileCacheStore tests saving, listing, loading and deleting snapshot files
+func TestFileCacheStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	store := NewFileCacheStore(dir)
+
+	first, err := store.Save("show", []byte(`{"n": 1}`))
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	second, err := store.Save("show", []byte(`{"n": 2}`))
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if first == second {
+		t.Fatal("Expected snapshots saved in the same second to get distinct keys")
+	}
+	if _, err := store.Save("show_extra", []byte(`{}`)); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	// Make the first snapshot unambiguously older
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(first, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := store.List("show")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Key != second || entries[1].Key != first {
+		t.Fatalf("Expected show's two snapshots newest first, got %+v", entries)
+	}
+	if all, _ := store.List(""); len(all) != 3 {
+		t.Errorf("Expected 3 snapshots in total, got %+v", all)
+	}
+
+	data, err := store.Load(entries[0].Key)
+	if err != nil || string(data) != `{"n": 2}` {
+		t.Errorf("Expected newest snapshot contents, got %q, %v", data, err)
+	}
+	if err := store.Delete(second); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if entries, _ := store.List("show"); len(entries) != 1 {
+		t.Errorf("Expected one snapshot after delete, got %+v", entries)
+	}
+}
+
+// TestPruneCache tests that retention limits snapshots per source file and by age
+func TestPruneCache(t *testing.T) {
+	store := NewMemoryCacheStore()
+	workspace := &Workspace{}
+	workspace.SetCacheStore(store)
+
+	var showKeys []string
+	for range 4 {
+		key, _ := store.Save("show", []byte(`{}`))
+		showKeys = append(showKeys, key)
+	}
+	other, _ := store.Save("other", []byte(`{}`))
+	store.SetSavedAt(other, time.Now().Add(-48*time.Hour))
+
+	if removed, err := workspace.PruneCache(); err != nil || removed != 0 {
+		t.Fatalf("Expected nothing pruned without a retention policy, got %d, %v", removed, err)
+	}
+
+	workspace.SetCacheRetention(CacheRetention{MaxFiles: 2, MaxAge: 24 * time.Hour})
+	removed, err := workspace.PruneCache()
+	if err != nil {
+		t.Fatalf("PruneCache failed: %v", err)
+	}
+	if removed != 3 {
+		t.Errorf("Expected 3 snapshots pruned, got %d", removed)
+	}
+
+	entries, _ := store.List("")
+	if len(entries) != 2 || entries[0].Key != showKeys[3] || entries[1].Key != showKeys[2] {
+		t.Errorf("Expected the two newest show snapshots to remain, got %+v", entries)
+	}
+}
+
+// TestCacheDisabled tests that nothing is cached or loaded when caching is turned off
+func TestCacheDisabled(t *testing.T) {
+	workspace, _ := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1) // The mock doesn't answer every query made while snapshotting
+	store := NewMemoryCacheStore()
+	workspace.SetCacheStore(store)
+	workspace.SetCacheEnabled(false)
+
+	if err := workspace.writeCueFileToCache("/shows/show.cue", map[string]any{}, nil, nil); err != nil {
+		t.Fatalf("Expected writing a disabled cache to be a no-op, got %v", err)
+	}
+	if entries, _ := store.List(""); len(entries) != 0 {
+		t.Errorf("Expected no snapshots, got %+v", entries)
+	}
+	if _, err := workspace.PruneCache(); !errors.Is(err, ErrCacheDisabled) {
+		t.Errorf("Expected ErrCacheDisabled, got %v", err)
+	}
+
+	workspace.SetCacheEnabled(true)
+	if err := workspace.writeCueFileToCache("/shows/show.cue", map[string]any{}, nil, nil); err != nil {
+		t.Fatalf("writeCueFileToCache failed: %v", err)
+	}
+	if entries, _ := store.List(workspace.cacheName("/shows/show.cue")); len(entries) != 1 {
+		t.Errorf("Expected one snapshot once caching is enabled, got %+v", entries)
+	}
+}
+
+// TestCacheSnapshots tests listing, loading, deleting and restoring snapshots
+func TestCacheSnapshots(t *testing.T) {
+	store := NewMemoryCacheStore()
+	workspace := &Workspace{}
+	workspace.SetCacheStore(store)
+
+	older, _ := store.Save("show", []byte(`{"data": [{"cues": [{"uniqueID": "A"}, {"uniqueID": "B", "cues": [{"uniqueID": "C"}]}]}], "cacheMetadata": {"writtenAt": "2026-01-02T10:00:00Z"}}`))
+	newer, _ := store.Save("show", []byte(`{"data": [{"cues": [{"uniqueID": "A"}]}]}`))
+	if _, err := store.Save("other", []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshots, err := workspace.ListCacheSnapshots("/shows/show.json")
+	if err != nil {
+		t.Fatalf("ListCacheSnapshots failed: %v", err)
+	}
+	if len(snapshots) != 2 || snapshots[0].Key != newer || snapshots[1].Key != older {
+		t.Fatalf("Expected show's snapshots newest first, got %+v", snapshots)
+	}
+	if snapshots[1].CueCount != 3 || snapshots[0].CueCount != 1 {
+		t.Errorf("Expected 3 and 1 cues, got %d and %d", snapshots[1].CueCount, snapshots[0].CueCount)
+	}
+	if want := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC); !snapshots[1].Metadata.WrittenAt.Equal(want) {
+		t.Errorf("Expected the recorded write time, got %v", snapshots[1].Metadata.WrittenAt)
+	}
+	if all, _ := workspace.ListCacheSnapshots(""); len(all) != 3 {
+		t.Errorf("Expected every snapshot without a file path, got %d", len(all))
+	}
+
+	data, _, err := workspace.LoadCacheSnapshot(older)
+	if err != nil || countSnapshotCues(data) != 3 {
+		t.Errorf("Expected the older snapshot's data, got %v (%v)", data, err)
+	}
+
+	// Restoring the older snapshot makes it the baseline again
+	restored, err := workspace.RestoreCacheSnapshot(older)
+	if err != nil {
+		t.Fatalf("RestoreCacheSnapshot failed: %v", err)
+	}
+	entry, baseline, _, err := workspace.loadLatestCache("/shows/show.json")
+	if err != nil || entry.Key != restored || countSnapshotCues(baseline) != 3 {
+		t.Errorf("Expected the restored snapshot as the baseline, got %s (%v)", entry.Key, err)
+	}
+	if _, err := workspace.RestoreCacheSnapshot("missing"); err == nil {
+		t.Error("Expected restoring a missing snapshot to fail")
+	}
+
+	if err := workspace.DeleteCacheSnapshot(restored); err != nil {
+		t.Fatalf("DeleteCacheSnapshot failed: %v", err)
+	}
+	if entry, _, _, _ := workspace.loadLatestCache("/shows/show.json"); entry.Key != newer {
+		t.Errorf("Expected the newer snapshot as the baseline after deleting the restored one, got %s", entry.Key)
+	}
+}
+
+// TestCacheTargets tests that each QLab host and workspace a file is sent to keeps its own
+// baseline, and that snapshots written before that are still used
+func TestCacheTargets(t *testing.T) {
+	store := NewMemoryCacheStore()
+	show := &Workspace{host: "10.0.1.20", workspace_id: "SHOW"}
+	backup := &Workspace{host: "10.0.1.21", workspace_id: "SHOW"}
+	show.SetCacheStore(store)
+	backup.SetCacheStore(store)
+
+	if name := show.cacheName("/shows/show.json"); name != "show@10.0.1.20@SHOW" {
+		t.Errorf("Expected the cache name to include host and workspace, got %s", name)
+	}
+
+	legacy, _ := store.Save("show", []byte(`{"data": []}`))
+	if entry, _, _, err := backup.loadLatestCache("/shows/show.json"); err != nil || entry.Key != legacy {
+		t.Errorf("Expected the unkeyed snapshot as a fallback, got %s (%v)", entry.Key, err)
+	}
+
+	showKey, _ := store.Save(show.cacheName("/shows/show.json"), []byte(`{"data": [{"cues": [{"uniqueID": "A"}]}]}`))
+	backupKey, _ := store.Save(backup.cacheName("/shows/show.json"), []byte(`{"data": []}`))
+	if entry, _, _, err := show.loadLatestCache("/shows/show.json"); err != nil || entry.Key != showKey {
+		t.Errorf("Expected the show machine's own baseline, got %s (%v)", entry.Key, err)
+	}
+	if entry, _, _, err := backup.loadLatestCache("/shows/show.json"); err != nil || entry.Key != backupKey {
+		t.Errorf("Expected the backup machine's own baseline, got %s (%v)", entry.Key, err)
+	}
+	if snapshots, _ := show.ListCacheSnapshots("/shows/show.json"); len(snapshots) != 1 || snapshots[0].Key != showKey {
+		t.Errorf("Expected only the show machine's snapshot listed, got %+v", snapshots)
+	}
+}
@@ -0,0 +1,64 @@
+package qlab
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// TestValidateCartGrid tests positions outside the grid, shared cells, and overfull carts
+func TestValidateCartGrid(t *testing.T) {
+	cart := map[string]any{
+		"type": "cart", "name": "Sampler", "cartRows": 2.0, "cartColumns": 2.0,
+		"cues": []any{
+			map[string]any{"type": "audio", "number": "1", "cartPosition": map[string]any{"row": 1.0, "column": 2.0}},
+			map[string]any{"type": "audio", "number": "2", "cartPosition": []any{2.0, 1.0}},
+			map[string]any{"type": "audio", "number": "3"},
+		},
+	}
+	if err := ValidateCartGrid(cart); err != nil {
+		t.Fatalf("Expected the cart to fit its grid, got %v", err)
+	}
+
+	cart["cues"] = append(cart["cues"].([]any),
+		map[string]any{"type": "audio", "number": "4", "cartPosition": []any{3.0, 1.0}},
+		map[string]any{"type": "audio", "number": "5", "cartPosition": []any{1.0, 2.0}},
+		map[string]any{"type": "audio", "number": "6", "cartPosition": []any{0.0, 1.0}},
+	)
+	var gridErr *CartGridError
+	if err := ValidateCartGrid(cart); !errors.As(err, &gridErr) || gridErr.Cart != `"Sampler"` || len(gridErr.Problems) != 4 {
+		t.Fatalf("Expected 4 grid problems, got %v", err)
+	}
+}
+
+// TestTransmitCartGrid tests that carts are sized and their children placed in their cells
+func TestTransmitCartGrid(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspaceData := map[string]any{
+		"cues": []any{
+			map[string]any{
+				"type": "cart", "name": "Sampler", "cartRows": 2.0, "cartColumns": 3.0,
+				"cues": []any{
+					map[string]any{"type": "memo", "number": "S1", "name": "Doorbell", "cartPosition": map[string]any{"row": 2.0, "column": 3.0}},
+					map[string]any{"type": "memo", "number": "S2", "name": "Phone"},
+				},
+			},
+		},
+	}
+
+	if _, err := workspace.TransmitWorkspaceData(filepath.Join(t.TempDir(), "show.json"), workspaceData); err != nil {
+		t.Fatalf("TransmitWorkspaceData failed: %v", err)
+	}
+
+	// The mock numbers its cues in creation order
+	cart, doorbell, phone := mockServer.GetCue("MOCK-CUE-1"), mockServer.GetCue("MOCK-CUE-2"), mockServer.GetCue("MOCK-CUE-3")
+	if cart == nil || cart.Properties["cartRows"] != "2" || cart.Properties["cartColumns"] != "3" {
+		t.Fatalf("Expected a 2x3 cart, got %+v", cart)
+	}
+	if doorbell == nil || doorbell.Properties["cartPosition"] != "2 3" {
+		t.Errorf("Expected the doorbell at row 2, column 3, got %+v", doorbell)
+	}
+	if phone == nil || phone.Properties["cartPosition"] != "" {
+		t.Errorf("Expected the phone left in the first empty cell, got %+v", phone)
+	}
+}
@@ -0,0 +1,237 @@
+package qlab
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hypebeast/go-osc/osc"
+	"github.com/zenibako/qlab-golang/messages"
+)
+
+// Client manages connections to several workspaces on one QLab host. Its workspaces share
+// a single listener for replies and updates instead of each binding ports of its own;
+// messages are routed to a workspace by the workspace ID in their address.
+type Client struct {
+	host       string
+	port       int
+	mu         sync.Mutex
+	server     *osc.Server           // Listener shared by every workspace, nil until first needed
+	conn       net.PacketConn        // Socket of the shared listener
+	workspaces map[string]*Workspace // Workspaces by ID
+	logger     Logger                // Receives log output, nil for the global charmbracelet logger
+}
+
+// NewClient creates a client for the QLab instance at host:port
+func NewClient(host string, port int) *Client {
+	return &Client{
+		host:       host,
+		port:       port,
+		workspaces: make(map[string]*Workspace),
+	}
+}
+
+// SetLogger routes the client's logging, and that of workspaces it creates afterward, to
+// logger, or back to the global charmbracelet logger when nil
+func (c *Client) SetLogger(logger Logger) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.logger = logger
+}
+
+// log returns the logger the client logs to
+func (c *Client) log() Logger {
+	return orDefaultLogger(c.logger)
+}
+
+// Workspace returns the client's workspace with the given ID, creating it on first use.
+// A new workspace isn't connected yet: call Init on it, or use Connect. Set its update
+// handler with StartUpdateListener as usual; the shared listener is used either way.
+func (c *Client) Workspace(workspaceID string) (*Workspace, error) {
+	if workspaceID == "" {
+		return nil, fmt.Errorf("%w - a workspace ID is required", ErrWorkspaceNotFound)
+	}
+	if err := c.listen(); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if w, ok := c.workspaces[workspaceID]; ok {
+		return w, nil
+	}
+	w := &Workspace{
+		host:           c.host,
+		port:           c.port,
+		client:         osc.NewClient(c.host, c.port),
+		workspace_id:   workspaceID,
+		addressBuilder: messages.NewOSCAddressBuilder(workspaceID),
+		cueNumbers:     make(map[string]string),
+		cueListNames:   make(map[string]string),
+		replyHandlers:  make(map[string][]pendingReply),
+		timeout:        10,
+		updateServer:   c.server,
+		replyConn:      c.conn,
+		pool:           c,
+		logger:         c.logger,
+	}
+	c.workspaces[workspaceID] = w
+	c.log().Debugf("Added workspace %s to client for %s:%d", workspaceID, c.host, c.port)
+	return w, nil
+}
+
+// Connect returns the client's workspace with the given ID, connected with passcode and
+// subscribed to updates. A workspace that is already connected is returned as is.
+func (c *Client) Connect(workspaceID, passcode string) (*Workspace, error) {
+	w, err := c.Workspace(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if w.IsConnected() {
+		return w, nil
+	}
+	if _, err := w.Init(passcode); err != nil {
+		w.Close()
+		return nil, err
+	}
+	if err := w.SendNoReply(w.addressBuilder.GetWorkspacePrefix()+"/updates", int32(1)); err != nil {
+		c.log().Error("Failed to subscribe to updates", "workspace_id", workspaceID, "error", err)
+	}
+	return w, nil
+}
+
+// Workspaces returns the client's workspaces ordered by ID
+func (c *Client) Workspaces() []*Workspace {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	workspaces := make([]*Workspace, 0, len(c.workspaces))
+	for _, w := range c.workspaces {
+		workspaces = append(workspaces, w)
+	}
+	sort.Slice(workspaces, func(i, j int) bool {
+		return workspaces[i].workspace_id < workspaces[j].workspace_id
+	})
+	return workspaces
+}
+
+// ListenAddress returns the address of the shared listener, or an empty string before
+// the first workspace is added
+func (c *Client) ListenAddress() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return ""
+	}
+	return c.conn.LocalAddr().String()
+}
+
+// Close closes every workspace and then the shared listener
+func (c *Client) Close() {
+	for _, w := range c.Workspaces() {
+		w.Close()
+	}
+
+	c.mu.Lock()
+	conn := c.conn
+	c.server = nil
+	c.conn = nil
+	c.mu.Unlock()
+
+	if conn != nil {
+		c.log().Debugf("Closing shared listener")
+		if err := conn.Close(); err != nil {
+			c.log().Warnf("Failed to close shared listener: %v", err)
+		}
+	}
+}
+
+// listen starts the shared listener if it isn't running, on port+1 as a workspace's own
+// listener is, or a free port if that's taken. Each workspace tells QLab the port when it
+// connects.
+func (c *Client) listen() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.server != nil {
+		return nil
+	}
+
+	conn, err := listenForReplies(0, c.port+1)
+	if err != nil {
+		return fmt.Errorf("failed to start shared listener: %w", err)
+	}
+	d := osc.NewStandardDispatcher()
+	_ = d.AddMsgHandler("*", c.route)
+	server := &osc.Server{Dispatcher: &timetagDispatcher{dispatcher: d, clock: c}}
+	go func() {
+		if err := servePackets(server, conn); err != nil && !errors.Is(err, net.ErrClosed) {
+			c.log().Errorf("Shared listener exited with error: %v", err)
+		}
+	}()
+	c.server = server
+	c.conn = conn
+	c.log().Infof("Shared OSC listener started on %s", conn.LocalAddr())
+	return nil
+}
+
+// route passes a message to the workspace named in its address. Replies without a
+// workspace go to whichever workspace is waiting for them; updates without one go to all.
+func (c *Client) route(msg *osc.Message) {
+	c.mu.Lock()
+	target, ok := c.workspaces[addressWorkspaceID(msg.Address)]
+	workspaces := make([]*Workspace, 0, len(c.workspaces))
+	for _, w := range c.workspaces {
+		workspaces = append(workspaces, w)
+	}
+	c.mu.Unlock()
+
+	if ok {
+		target.handleIncomingMessage(msg)
+		return
+	}
+	if strings.HasPrefix(msg.Address, "/reply") {
+		for _, w := range workspaces {
+			if w.deliverReply(msg) {
+				return
+			}
+		}
+		c.log().Debugf("No workspace waiting for reply: %s", msg.Address)
+		return
+	}
+	for _, w := range workspaces {
+		w.handleIncomingMessage(msg)
+	}
+}
+
+// observe passes a reading of QLab's clock to every workspace
+func (c *Client) observe(qlabTime time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, w := range c.workspaces {
+		w.qlabClock.observe(qlabTime)
+	}
+}
+
+// release removes a closed workspace from the client
+func (c *Client) release(w *Workspace) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.workspaces[w.workspace_id] == w {
+		delete(c.workspaces, w.workspace_id)
+	}
+}
+
+// addressWorkspaceID returns the workspace ID in a reply or update address such as
+// /reply/workspace/{id}/cueLists, or an empty string if there isn't one
+func addressWorkspaceID(address string) string {
+	for _, prefix := range []string{"/reply/workspace/", "/update/workspace/"} {
+		if rest, ok := strings.CutPrefix(address, prefix); ok {
+			id, _, _ := strings.Cut(rest, "/")
+			return id
+		}
+	}
+	return ""
+}
@@ -0,0 +1,128 @@
+package qlab
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestClientSharesListener tests that a client's workspaces share one listener and get
+// the replies and updates addressed to them
+func TestClientSharesListener(t *testing.T) {
+	port, err := getFreePort()
+	if err != nil {
+		t.Fatalf("Failed to get free port: %v", err)
+	}
+	mockServer := NewMockOSCServer("localhost", port)
+	if err := mockServer.Start(); err != nil {
+		t.Fatalf("Failed to start mock server: %v", err)
+	}
+	client := NewClient("localhost", port)
+	t.Cleanup(func() {
+		client.Close()
+		_ = mockServer.Stop()
+		time.Sleep(150 * time.Millisecond)
+	})
+
+	show, err := client.Connect(mockServer.GetWorkspaceID(), "")
+	if err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	backup, err := client.Workspace("BACKUP-WORKSPACE-ID")
+	if err != nil {
+		t.Fatalf("Workspace failed: %v", err)
+	}
+	if show.updateServer == nil || show.updateServer != backup.updateServer {
+		t.Fatal("Expected both workspaces to use the client's listener")
+	}
+	if addr := client.ListenAddress(); !strings.HasSuffix(addr, fmt.Sprintf(":%d", port+1)) {
+		t.Errorf("Expected the listener on port+1, got %q", addr)
+	}
+	if again, _ := client.Workspace("BACKUP-WORKSPACE-ID"); again != backup {
+		t.Error("Expected the same workspace for the same ID")
+	}
+
+	// Replies without a workspace in their address reach the workspace waiting for them
+	if version, err := show.queryVersion(); err != nil || version != "5.4.1" {
+		t.Errorf("Expected the version reply to reach the show workspace, got %q, %v", version, err)
+	}
+
+	var mu sync.Mutex
+	updates := make(map[string][]string)
+	for _, w := range []*Workspace{show, backup} {
+		id := w.WorkspaceID()
+		_ = w.StartUpdateListener(func(address string, args []any) {
+			mu.Lock()
+			defer mu.Unlock()
+			updates[id] = append(updates[id], address)
+		})
+	}
+	received := func(id string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(updates[id])
+	}
+
+	if err := mockServer.SendUpdate("/update/workspace/BACKUP-WORKSPACE-ID/cue_id/abc"); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return received("BACKUP-WORKSPACE-ID") == 1 })
+	if err := mockServer.SendUpdate("/update/preferences"); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return received(show.WorkspaceID()) == 1 && received("BACKUP-WORKSPACE-ID") == 2 })
+
+	// Closing one workspace leaves the listener running for the other
+	backup.Close()
+	if workspaces := client.Workspaces(); len(workspaces) != 1 || workspaces[0] != show {
+		t.Fatalf("Expected only the show workspace to remain, got %d", len(workspaces))
+	}
+	if _, err := show.queryVersion(); err != nil {
+		t.Errorf("Expected replies after closing the other workspace, got %v", err)
+	}
+}
+
+// TestClientConnectUnknownWorkspace tests that connecting to a workspace that isn't open fails
+func TestClientConnectUnknownWorkspace(t *testing.T) {
+	port, err := getFreePort()
+	if err != nil {
+		t.Fatalf("Failed to get free port: %v", err)
+	}
+	mockServer := NewMockOSCServer("localhost", port)
+	if err := mockServer.Start(); err != nil {
+		t.Fatalf("Failed to start mock server: %v", err)
+	}
+	client := NewClient("localhost", port)
+	t.Cleanup(func() {
+		client.Close()
+		_ = mockServer.Stop()
+		time.Sleep(150 * time.Millisecond)
+	})
+
+	if _, err := client.Connect("NOT-OPEN", ""); !errors.Is(err, ErrWorkspaceNotFound) {
+		t.Errorf("Expected ErrWorkspaceNotFound, got %v", err)
+	}
+	if workspaces := client.Workspaces(); len(workspaces) != 0 {
+		t.Errorf("Expected the failed workspace to be removed, got %d", len(workspaces))
+	}
+}
+
+// TestAddressWorkspaceID tests reading the workspace ID from reply and update addresses
+func TestAddressWorkspaceID(t *testing.T) {
+	cases := map[string]string{
+		"/reply/workspace/ABC/cueLists":      "ABC",
+		"/update/workspace/ABC/cue_id/1":     "ABC",
+		"/update/workspace/ABC":              "ABC",
+		"/reply/version":                     "",
+		"/update/preferences":                "",
+		"/reply/cue_id/1/workspace/ABC/name": "",
+	}
+	for address, want := range cases {
+		if got := addressWorkspaceID(address); got != want {
+			t.Errorf("addressWorkspaceID(%q) = %q, want %q", address, got, want)
+		}
+	}
+}
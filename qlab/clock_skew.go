@@ -0,0 +1,204 @@
+package qlab
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hypebeast/go-osc/osc"
+)
+
+// Cache age is judged from timestamps taken on the controlling machine, which mislead when
+// its clock has been changed (e.g. a laptop moving between time zones on tour). Each cache
+// file therefore records QLab's clock alongside the local one whenever QLab reports it, and
+// comparisons flag skew so that "cache is stale" judgments aren't silently wrong.
+
+// ClockSkewThreshold is the clock difference beyond which cache timestamps are flagged
+const ClockSkewThreshold = 2 * time.Minute
+
+// cacheMetadataKey is the top-level key cache metadata is stored under in cache files
+const cacheMetadataKey = "cacheMetadata"
+
+// CacheMetadata records when, and against which QLab, a cache file was written
+type CacheMetadata struct {
+	WrittenAt   time.Time  `json:"writtenAt"`             // Local clock when the cache was written
+	QLabTime    *time.Time `json:"qlabTime,omitempty"`    // QLab's clock at the same moment, when known
+	QLabVersion string     `json:"qlabVersion,omitempty"` // Version reported by /version
+	Host        string     `json:"host,omitempty"`        // QLab host the snapshot was read from
+	WorkspaceID string     `json:"workspaceID,omitempty"` // Workspace the snapshot was read from
+
+	// Identities maps each source cue's change detection key to the uniqueID of the QLab
+	// cue it was transmitted as
+	Identities map[string]string `json:"identities,omitempty"`
+
+	// Resolutions are the conflict choices remembered for later syncs
+	Resolutions []RememberedResolution `json:"resolutions,omitempty"`
+}
+
+// Skew returns how far the local clock was ahead of QLab's when the cache was written,
+// and whether QLab's clock was known
+func (m *CacheMetadata) Skew() (time.Duration, bool) {
+	if m == nil || m.QLabTime == nil {
+		return 0, false
+	}
+	return m.WrittenAt.Sub(*m.QLabTime), true
+}
+
+// ClockSkewReport describes clock problems found while comparing against a cache
+type ClockSkewReport struct {
+	CacheWrittenAt time.Time     // Local clock when the cache was written
+	CacheAge       time.Duration // Age of the cache, measured on QLab's clock when possible
+	AgeFromQLab    bool          // Whether CacheAge was measured on QLab's clock
+	CurrentSkew    time.Duration // Local clock minus QLab's clock now
+	HasCurrentSkew bool          // Whether QLab's clock is currently known
+	Warnings       []string      // Human-readable descriptions of detected skew
+}
+
+// Significant reports whether any skew was detected
+func (r *ClockSkewReport) Significant() bool {
+	return r != nil && len(r.Warnings) > 0
+}
+
+// remoteClock holds the most recent QLab clock reading taken from reply bundle timetags
+type remoteClock struct {
+	mu      sync.Mutex
+	reading time.Time // QLab's clock when the reading was taken
+	at      time.Time // Local clock when the reading was taken
+}
+
+// observe records a reading of QLab's clock
+func (c *remoteClock) observe(qlabTime time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reading = qlabTime
+	c.at = time.Now()
+}
+
+// now estimates QLab's current clock from the most recent reading
+func (c *remoteClock) now() (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.at.IsZero() {
+		return time.Time{}, false
+	}
+	return c.reading.Add(time.Since(c.at)), true
+}
+
+// QLabClock returns QLab's current clock as estimated from the timetags of bundled replies.
+// The second result is false if QLab hasn't sent a timetagged reply yet.
+func (q *Workspace) QLabClock() (time.Time, bool) {
+	return q.qlabClock.now()
+}
+
+// ClockSkew returns how far the local clock is ahead of QLab's (negative when behind),
+// and whether QLab's clock is known
+func (q *Workspace) ClockSkew() (time.Duration, bool) {
+	qlabNow, ok := q.QLabClock()
+	if !ok {
+		return 0, false
+	}
+	return time.Since(qlabNow), true
+}
+
+// timetagDispatcher reads QLab's clock from bundle timetags and dispatches the bundled
+// messages immediately; the standard dispatcher would hold them until the timetag, which
+// is indefinitely when QLab's clock runs ahead of ours
+type timetagDispatcher struct {
+	dispatcher osc.Dispatcher
+	clock      clockObserver
+}
+
+// clockObserver receives readings of QLab's clock
+type clockObserver interface {
+	observe(qlabTime time.Time)
+}
+
+func (d *timetagDispatcher) Dispatch(packet osc.Packet) {
+	bundle, ok := packet.(*osc.Bundle)
+	if !ok {
+		d.dispatcher.Dispatch(packet)
+		return
+	}
+	// A timetag of 1 means "immediately" and carries no clock
+	if bundle.Timetag.TimeTag() > 1 {
+		d.clock.observe(bundle.Timetag.Time())
+	}
+	for _, msg := range bundle.Messages {
+		d.dispatcher.Dispatch(msg)
+	}
+	for _, nested := range bundle.Bundles {
+		d.Dispatch(nested)
+	}
+}
+
+// currentCacheMetadata collects metadata for a cache file written now
+func (q *Workspace) currentCacheMetadata() CacheMetadata {
+	// Querying the version also gives QLab a chance to report its clock
+	version, err := q.queryVersion()
+	if err != nil {
+		q.log().Debugf("Could not query QLab version for cache metadata: %v", err)
+	}
+	meta := CacheMetadata{WrittenAt: time.Now(), QLabVersion: version, Host: q.host, WorkspaceID: q.workspace_id}
+	if qlabNow, ok := q.QLabClock(); ok {
+		meta.QLabTime = &qlabNow
+	}
+	return meta
+}
+
+// queryVersion asks QLab for its version
+func (q *Workspace) queryVersion() (string, error) {
+	reply := q.Send("/version", "")
+	if err := checkReplyStatus(reply); err != nil {
+		return "", err
+	}
+	data, _ := replyDataValue(reply)
+	version, _ := data.(string)
+	return version, nil
+}
+
+// detectClockSkew compares cache metadata with the clocks now and reports skew that
+// makes the cache's age unreliable
+func detectClockSkew(meta *CacheMetadata, now time.Time, qlabNow time.Time, qlabKnown bool) *ClockSkewReport {
+	report := &ClockSkewReport{CacheWrittenAt: meta.WrittenAt, CacheAge: now.Sub(meta.WrittenAt)}
+	if qlabKnown {
+		report.CurrentSkew = now.Sub(qlabNow)
+		report.HasCurrentSkew = true
+		if meta.QLabTime != nil {
+			report.CacheAge = qlabNow.Sub(*meta.QLabTime)
+			report.AgeFromQLab = true
+		}
+	}
+
+	if meta.WrittenAt.After(now.Add(ClockSkewThreshold)) {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("cache was written %s in the future by the local clock; the clock has been set back since", formatSkew(meta.WrittenAt.Sub(now))))
+	}
+	if report.HasCurrentSkew && absDuration(report.CurrentSkew) > ClockSkewThreshold {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("local clock is %s QLab's", describeSkew(report.CurrentSkew)))
+	}
+	if cacheSkew, ok := meta.Skew(); ok && report.HasCurrentSkew {
+		if drift := report.CurrentSkew - cacheSkew; absDuration(drift) > ClockSkewThreshold {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("local clock has moved %s relative to QLab since the cache was written", formatSkew(drift)))
+		}
+	}
+	return report
+}
+
+// describeSkew phrases a local-minus-QLab duration
+func describeSkew(skew time.Duration) string {
+	if skew < 0 {
+		return formatSkew(-skew) + " behind"
+	}
+	return formatSkew(skew) + " ahead of"
+}
+
+// formatSkew rounds a duration for display
+func formatSkew(d time.Duration) string {
+	return absDuration(d).Round(time.Second).String()
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,99 @@
+package qlab
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestDetectClockSkew tests that skew is reported when the local clock moved or disagrees with QLab
+func TestDetectClockSkew(t *testing.T) {
+	now := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+	qlabAtWrite := now.Add(-1 * time.Hour)
+
+	// Both clocks agreed then and agree now: no warnings, age measured on QLab's clock
+	meta := &CacheMetadata{WrittenAt: qlabAtWrite, QLabTime: &qlabAtWrite}
+	report := detectClockSkew(meta, now, now, true)
+	if report.Significant() {
+		t.Errorf("Expected no skew, got %v", report.Warnings)
+	}
+	if !report.AgeFromQLab || report.CacheAge != time.Hour {
+		t.Errorf("Expected 1h age from QLab's clock, got %v (fromQLab=%t)", report.CacheAge, report.AgeFromQLab)
+	}
+
+	// The laptop moved three time zones west since the cache was written
+	localNow := now.Add(-3 * time.Hour)
+	report = detectClockSkew(meta, localNow, now, true)
+	if len(report.Warnings) != 3 {
+		t.Fatalf("Expected future-cache, current-skew and drift warnings, got %v", report.Warnings)
+	}
+	if !strings.Contains(report.Warnings[1], "3h0m0s behind") {
+		t.Errorf("Expected current skew warning, got %q", report.Warnings[1])
+	}
+	if report.CacheAge != time.Hour {
+		t.Errorf("Expected QLab's clock to give the real 1h age, got %v", report.CacheAge)
+	}
+
+	// Without QLab's clock only the local clock can be judged
+	report = detectClockSkew(&CacheMetadata{WrittenAt: now.Add(time.Hour)}, now, time.Time{}, false)
+	if len(report.Warnings) != 1 || report.AgeFromQLab {
+		t.Errorf("Expected a single future-cache warning, got %v", report.Warnings)
+	}
+}
+
+// TestQLabClockFromTimetaggedReplies tests that QLab's clock is read from bundled replies
+func TestQLabClockFromTimetaggedReplies(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+
+	if _, ok := workspace.ClockSkew(); ok {
+		t.Fatal("Expected QLab's clock to be unknown before any timetagged reply")
+	}
+
+	mockServer.SetClockOffset(-2 * time.Hour)
+	meta := workspace.currentCacheMetadata()
+	if meta.QLabVersion != "5.4.1" {
+		t.Errorf("Expected QLab version in metadata, got %q", meta.QLabVersion)
+	}
+	skew, ok := meta.Skew()
+	if !ok {
+		t.Fatal("Expected QLab's clock in metadata after a timetagged reply")
+	}
+	if skew < 2*time.Hour-time.Minute || skew > 2*time.Hour+time.Minute {
+		t.Errorf("Expected about 2h of skew, got %v", skew)
+	}
+}
+
+// TestCacheMetadataStorage tests that metadata is read from snapshots and kept out of cached workspace data
+func TestCacheMetadataStorage(t *testing.T) {
+	store := NewMemoryCacheStore()
+	workspace := &Workspace{}
+	workspace.SetCacheStore(store)
+
+	qlabTime := time.Date(2026, 3, 1, 18, 0, 0, 0, time.UTC)
+	data, _ := json.Marshal(map[string]any{
+		"data":           []any{},
+		cacheMetadataKey: CacheMetadata{WrittenAt: qlabTime.Add(time.Hour), QLabTime: &qlabTime},
+	})
+	if _, err := store.Save("show", data); err != nil {
+		t.Fatal(err)
+	}
+
+	_, cached, meta, err := workspace.loadLatestCache("/shows/show.cue")
+	if err != nil {
+		t.Fatalf("loadLatestCache failed: %v", err)
+	}
+	if skew, ok := meta.Skew(); !ok || skew != time.Hour {
+		t.Errorf("Expected 1h recorded skew, got %v (%t)", skew, ok)
+	}
+	if _, ok := cached[cacheMetadataKey]; ok {
+		t.Error("Expected metadata to be removed from cached workspace data")
+	}
+
+	// Snapshots written before metadata existed fall back to the time they were saved
+	savedAt := time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+	cached, meta, err = decodeCacheSnapshot([]byte(`{"data": []}`), savedAt)
+	if err != nil || cached == nil || !meta.WrittenAt.Equal(savedAt) || meta.QLabTime != nil {
+		t.Errorf("Expected saved time fallback, got %+v, %v", meta, err)
+	}
+}
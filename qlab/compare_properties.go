@@ -0,0 +1,127 @@
+package qlab
+
+import (
+	"slices"
+	"strings"
+)
+
+// comparedProperties are compared for every cue when detecting changes
+var comparedProperties = slices.Concat([]string{
+	"name", "type", "fileTarget", "duration", "cueTargetNumber",
+	"armed", "colorName", "colorCondition", "flagged", "notes",
+}, followValueKeys, triggerValueKeys)
+
+// typeComparedProperties are compared in addition for cues of a type
+var typeComparedProperties = map[string][]string{
+	CueTypeText:   {"text"},
+	CueTypeGroup:  {"mode"},
+	CueTypeFade:   {"levels"},
+	CueTypeAudio:  {"levels"},
+	CueTypeScript: {"scriptSource"},
+}
+
+// sparseComparedProperties may be left out of QLab data or left unset in source data
+// without meaning anything, so they are only compared when both cues have them
+var sparseComparedProperties = slices.Concat([]string{"fileTarget", "cueTargetNumber", "colorCondition", "text", "mode", "levels", "scriptSource"}, triggerValueKeys)
+
+// AddComparedProperties adds properties to those compared when detecting whether a cue
+// changed, for cues of cueType or for every cue when cueType is empty. Added properties
+// are read from QLab along with the others and, like text or group modes, only compared
+// when both cues have them.
+func (q *Workspace) AddComparedProperties(cueType string, properties ...string) {
+	if q.comparedExtra == nil {
+		q.comparedExtra = make(map[string][]string)
+	}
+	cueType = strings.ToLower(cueType)
+	q.comparedExtra[cueType] = append(q.comparedExtra[cueType], properties...)
+}
+
+// ComparedProperties returns the properties compared when detecting whether a cue of
+// cueType changed
+func (q *Workspace) ComparedProperties(cueType string) []string {
+	cueType = strings.ToLower(cueType)
+	properties := slices.Concat(comparedProperties, typeComparedProperties[cueType], q.comparedExtra[""])
+	if cueType != "" {
+		properties = append(properties, q.comparedExtra[cueType]...)
+	}
+	seen := make(map[string]bool, len(properties))
+	return slices.DeleteFunc(properties, func(property string) bool {
+		duplicate := seen[property]
+		seen[property] = true
+		return duplicate
+	})
+}
+
+// isSparseComparedProperty reports whether a property is only compared when both cues have it
+func (q *Workspace) isSparseComparedProperty(property string) bool {
+	if slices.Contains(sparseComparedProperties, property) {
+		return true
+	}
+	for _, properties := range q.comparedExtra {
+		if slices.Contains(properties, property) {
+			return !slices.Contains(comparedProperties, property)
+		}
+	}
+	return false
+}
+
+// comparedValueKeys returns the type-specific and added compared properties of a cue type
+// that enrichment doesn't read already. Levels are read by the fade and audio enrichment,
+// which needs query arguments.
+func (q *Workspace) comparedValueKeys(cueType string, read []string) []string {
+	cueType = strings.ToLower(cueType)
+	var keys []string
+	for _, property := range slices.Concat(typeComparedProperties[cueType], q.comparedExtra[""], q.comparedExtra[cueType]) {
+		if property != "levels" && !slices.Contains(read, property) && !slices.Contains(keys, property) {
+			keys = append(keys, property)
+		}
+	}
+	return keys
+}
+
+// enrichCompared adds the compared properties read with comparedValueKeys that the cue
+// doesn't have yet
+func enrichCompared(cue map[string]any, values map[string]any, keys []string) {
+	for _, key := range keys {
+		if _, ok := cue[key]; ok {
+			continue
+		}
+		if value, ok := cueValue(values, key); ok {
+			cue[key] = value
+		}
+	}
+}
+
+// comparedValue returns a property of a cue normalized for comparison
+func (q *Workspace) comparedValue(cue map[string]any, property string) string {
+	if property == "levels" {
+		return formatLevelCells(levelCells(cue), nil)
+	}
+	if property == "scriptSource" {
+		source, _ := cue[property].(string)
+		return scriptHash(source)
+	}
+	if property == "colorName" {
+		if name, _, err := q.normalizeColor(cue[property]); err == nil {
+			return name
+		}
+	}
+	if property == "notes" {
+		notes, _ := cue[property].(string)
+		return stripExternalID(notes)
+	}
+	return q.normalizeProperty(cue[property])
+}
+
+// hasComparedValue reports whether a cue sets a property, counting the level shorthand
+// as setting levels
+func hasComparedValue(cue map[string]any, property string) bool {
+	if _, ok := cue[property]; ok {
+		return true
+	}
+	if property == "levels" {
+		_, ok := cue["level"]
+		return ok
+	}
+	return false
+}
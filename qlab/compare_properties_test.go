@@ -0,0 +1,89 @@
+package qlab
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestComparedProperties tests that timing, text, group mode, and level edits are
+// detected without reporting values QLab or the source leave out as changes
+func TestComparedProperties(t *testing.T) {
+	workspace, _ := setupWorkspaceWithCleanup(t)
+
+	tests := []struct {
+		name      string
+		source    map[string]any
+		current   map[string]any
+		different string // Property expected to differ, "" for none
+	}{
+		{"pre-wait changed", map[string]any{"type": "memo", "preWait": 2.0}, map[string]any{"type": "Memo", "preWait": 1.0}, "preWait"},
+		{"pre-wait set", map[string]any{"type": "memo", "preWait": "2"}, map[string]any{"type": "Memo"}, "preWait"},
+		{"zero pre-wait", map[string]any{"type": "memo", "preWait": 0.0}, map[string]any{"type": "Memo"}, ""},
+		{"text changed", map[string]any{"type": "text", "text": "Act 2"}, map[string]any{"type": "Text", "text": "Act 1"}, "text"},
+		{"text not read", map[string]any{"type": "text", "text": "Act 2"}, map[string]any{"type": "Text"}, ""},
+		{"text on other types", map[string]any{"type": "memo", "text": "Act 2"}, map[string]any{"type": "Memo", "text": "Act 1"}, ""},
+		{"group mode by name", map[string]any{"type": "group", "mode": "timeline"}, map[string]any{"type": "Group", "mode": float64(GroupModeTimeline)}, ""},
+		{"group mode changed", map[string]any{"type": "group", "mode": "fire first"}, map[string]any{"type": "Group", "mode": float64(GroupModeTimeline)}, "mode"},
+		{"level shorthand", map[string]any{"type": "fade", "level": -6.0}, map[string]any{"type": "Fade", "levels": []any{[]any{0.0, 0.0, -6.0}}}, ""},
+		{"level changed", map[string]any{"type": "fade", "level": -3.0}, map[string]any{"type": "Fade", "levels": []any{[]any{0.0, 0.0, -6.0}}}, "levels"},
+		{"levels unset", map[string]any{"type": "fade"}, map[string]any{"type": "Fade", "levels": []any{[]any{0.0, 0.0, -6.0}}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			differences := workspace.compareCuePropertiesDetailed(tt.source, tt.current)
+			if tt.different == "" && len(differences) != 0 {
+				t.Errorf("Expected no differences, got %v", differences)
+			}
+			if tt.different != "" && (differences[tt.different] == "" || len(differences) != 1) {
+				t.Errorf("Expected %s to differ, got %v", tt.different, differences)
+			}
+		})
+	}
+
+	workspace.AddComparedProperties("Audio", "rate")
+	if properties := workspace.ComparedProperties("audio"); !slices.Contains(properties, "rate") {
+		t.Errorf("Expected rate compared for audio cues, got %v", properties)
+	}
+	if slices.Contains(workspace.ComparedProperties("memo"), "rate") {
+		t.Error("Expected rate compared for audio cues only")
+	}
+	audio := map[string]any{"type": "audio", "rate": 1.0}
+	if differences := workspace.compareCuePropertiesDetailed(audio, map[string]any{"type": "Audio", "rate": 0.5}); differences["rate"] == "" {
+		t.Errorf("Expected the added property compared, got %v", differences)
+	}
+	if differences := workspace.compareCuePropertiesDetailed(audio, map[string]any{"type": "Audio"}); len(differences) != 0 {
+		t.Errorf("Expected an added property QLab didn't report skipped, got %v", differences)
+	}
+}
+
+// TestComparedPropertiesReadBack tests that the compared properties are set on update and
+// read back from QLab
+func TestComparedPropertiesReadBack(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+
+	source := map[string]any{"type": "group", "name": "Scene", "mode": "timeline", "preWait": 1.5}
+	cueID, err := workspace.createCueWithoutTarget(source, "")
+	if err != nil {
+		t.Fatalf("createCueWithoutTarget failed: %v", err)
+	}
+	if got := mockServer.GetCue(cueID).Properties["preWait"]; got != "1.5" {
+		t.Errorf("Expected the pre-wait set, got %q", got)
+	}
+
+	enriched := map[string]any{"type": "Group", "uniqueID": cueID, "name": "Scene"}
+	workspace.enrichCueProperties(enriched, cueID)
+	if differences := workspace.compareCuePropertiesDetailed(source, enriched); len(differences) != 0 {
+		t.Errorf("Expected the cue read back unchanged, got %v from %v", differences, enriched)
+	}
+
+	changed := map[string]any{"type": "group", "name": "Scene", "mode": "timeline", "preWait": 3.0}
+	if differences := workspace.compareCuePropertiesDetailed(changed, enriched); differences["preWait"] == "" {
+		t.Fatalf("Expected the pre-wait change detected, got %v", differences)
+	}
+	if err := workspace.updateCueProperties(cueID, changed); err != nil {
+		t.Fatalf("updateCueProperties failed: %v", err)
+	}
+	if got := mockServer.GetCue(cueID).Properties["preWait"]; got != "3" {
+		t.Errorf("Expected the pre-wait updated, got %q", got)
+	}
+}
@@ -0,0 +1,115 @@
+package qlab
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestConcurrentTransmitAndQueries tests that queries made while a transmit runs in the
+// background are safe. Run with -race to check for data races.
+func TestConcurrentTransmitAndQueries(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetCacheStore(NewMemoryCacheStore())
+	workspace.SetTransactional(true)
+
+	cues := make([]any, 0, 5)
+	for i := 1; i <= 5; i++ {
+		cues = append(cues, map[string]any{"type": "memo", "number": fmt.Sprint(i), "name": fmt.Sprintf("Cue %d", i)})
+	}
+	workspaceData := map[string]any{"cues": cues}
+
+	// Concurrent transmits of the same show take turns, so the cues are created once
+	path := filepath.Join(t.TempDir(), "show.json")
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := workspace.TransmitWorkspaceData(path, workspaceData, WithConflictResolver(AlwaysSource)); err != nil {
+				t.Errorf("TransmitWorkspaceData failed: %v", err)
+			}
+		}()
+	}
+
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 2 {
+				_, _ = workspace.FindCues(func(*Cue) bool { return true })
+				_, _ = workspace.GetCueByNumber("1")
+				_ = workspace.IsConnected()
+				_ = workspace.SendStats()
+				workspace.InvalidateCueCache()
+				_, _ = workspace.ValidateIndexes()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, err := workspace.GetCueByNumber("5"); err != nil {
+		t.Errorf("Expected every cue transmitted, got %v", err)
+	}
+	if count := mockServer.GetCueCount(); count != len(cues) {
+		t.Errorf("Expected the cues created once, got %d cues", count)
+	}
+}
+
+// TestMutatorsWaitForTransmit tests that the public mutators wait for a running transmit
+// instead of changing the workspace underneath it
+func TestMutatorsWaitForTransmit(t *testing.T) {
+	workspace, _ := setupWorkspaceWithCleanup(t)
+
+	cueID, err := workspace.createCueWithoutTarget(map[string]any{"type": "memo", "name": "Memo"}, "")
+	if err != nil {
+		t.Fatalf("createCueWithoutTarget failed: %v", err)
+	}
+	tx, err := workspace.BeginTransaction()
+	if err != nil {
+		t.Fatalf("BeginTransaction failed: %v", err)
+	}
+	generator := NewCueGenerator(workspace)
+
+	mutators := map[string]func(){
+		"DeleteCue":           func() { _ = workspace.DeleteCue(cueID) },
+		"RollbackCreatedCues": func() { _ = workspace.RollbackCreatedCues() },
+		"ClearManagedCues":    func() { _, _ = workspace.ClearManagedCues() },
+		"DeleteCueList":       func() { _ = workspace.DeleteCueList("Missing") },
+		"MoveCueList":         func() { _ = workspace.MoveCueList("Missing", 0) },
+		"ReorderCueLists":     func() { _ = workspace.ReorderCueLists("Missing") },
+		"CreateCueListAt":     func() { _, _ = workspace.CreateCueListAt("Extra", 0) },
+		"Dim":                 func() { _, _ = workspace.Dim(10) },
+		"Undim":               func() { _ = workspace.Undim() },
+		"Rollback":            func() { _ = tx.Rollback() },
+		"GenerateBatch":       func() { generator.GenerateBatch(nil) },
+	}
+
+	// Stand in for a transmit running on another goroutine
+	workspace.opMu.Lock()
+	done := make(chan string, len(mutators))
+	for name, mutate := range mutators {
+		go func() {
+			mutate()
+			done <- name
+		}()
+	}
+	pending := len(mutators)
+	select {
+	case name := <-done:
+		t.Errorf("%s returned while a transmit was running", name)
+		pending--
+	case <-time.After(50 * time.Millisecond):
+	}
+	workspace.opMu.Unlock()
+
+	for range pending {
+		select {
+		case <-done:
+		case <-time.After(10 * time.Second):
+			t.Fatal("Mutators did not finish after the transmit")
+		}
+	}
+}
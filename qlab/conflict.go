@@ -7,9 +7,12 @@ const (
 	ConflictThreeWayDivergence ConflictType = "three_way_divergence" // Source ≠ Cache ≠ QLab
 	ConflictCacheStale         ConflictType = "cache_stale"          // Cache ≠ QLab but Source = Cache
 	ConflictSourceModified     ConflictType = "source_modified"      // Source ≠ Cache but Cache = QLab
+	ConflictDeletedInSource    ConflictType = "deleted_in_source"    // Removed from source but Cache ≠ QLab
 )
 
 // ConflictScope represents the level at which a conflict occurs
+//
+// This API is stable.
 type ConflictScope string
 
 const (
@@ -20,13 +23,15 @@ const (
 )
 
 // FieldConflict represents a conflict at the field level
+//
+// This API is stable.
 type FieldConflict struct {
-	FieldName    string // Name of the conflicting field
-	SourceValue  any    // Value in source
-	CacheValue   any    // Value in cache
-	QLabValue    any    // Value in QLab
-	ChosenValue  any    // Value chosen after resolution (nil if not resolved)
-	ChosenSource string // Which source was chosen: "source", "qlab", "cache", or "custom"
+	FieldName    string `json:"fieldName"`              // Name of the conflicting field
+	SourceValue  any    `json:"sourceValue"`            // Value in source
+	CacheValue   any    `json:"cacheValue"`             // Value in cache
+	QLabValue    any    `json:"qlabValue"`              // Value in QLab
+	ChosenValue  any    `json:"chosenValue,omitempty"`  // Value chosen after resolution (nil if not resolved)
+	ChosenSource string `json:"chosenSource,omitempty"` // Which source was chosen: "source", "qlab", "cache", or "custom"
 }
 
 // CueConflict represents a conflict that needs user resolution
@@ -42,18 +47,24 @@ type CueConflict struct {
 	FieldConflicts map[string]*FieldConflict // Detailed field-level conflicts
 	Description    string                    // Human-readable description
 	Resolved       bool                      // Whether conflict has been resolved
+	Ancestors      []string                  // Identifiers of the enclosing groups and cue lists, innermost first
+	CueList        string                    // Name of the cue list containing the cue, if known
 }
 
 // ScopeComparison represents changes detected within a specific scope
+//
+// This API is stable.
 type ScopeComparison struct {
-	Scope          ConflictScope             // The scope being compared
-	Identifier     string                    // Identifier for this scope (cue number, cue list name, etc.)
-	HasChanges     bool                      // Whether changes were detected
-	ChangeType     string                    // Type of change: "create", "update", "delete", "none"
-	FieldChanges   map[string]*FieldConflict // Field-level changes
-	ChildScopes    []*ScopeComparison        // Nested scopes (e.g., cues within a cue list)
-	ConflictExists bool                      // Whether unresolved conflicts exist
-	Resolved       bool                      // Whether all conflicts resolved
+	Scope          ConflictScope             `json:"scope"`                  // The scope being compared
+	Identifier     string                    `json:"identifier"`             // Identifier for this scope (cue number, cue list name, etc.)
+	HasChanges     bool                      `json:"hasChanges"`             // Whether changes were detected
+	ChangeType     string                    `json:"changeType,omitempty"`   // Type of change: "create", "update", "delete", "none"
+	FieldChanges   map[string]*FieldConflict `json:"fieldChanges,omitempty"` // Field-level changes
+	ChildScopes    []*ScopeComparison        `json:"childScopes,omitempty"`  // Nested scopes (e.g., cues within a cue list)
+	ConflictExists bool                      `json:"conflictExists"`         // Whether unresolved conflicts exist
+	Resolved       bool                      `json:"resolved"`               // Whether all conflicts resolved
+	Ancestors      []string                  `json:"ancestors,omitempty"`    // Identifiers of the enclosing groups and cue lists, innermost first
+	CueList        string                    `json:"cueList,omitempty"`      // Name of the cue list containing this scope, if known
 }
 
 // MergedScope represents the final merged state after conflict resolution
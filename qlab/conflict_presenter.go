@@ -0,0 +1,160 @@
+package qlab
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// ConflictField is one field of a conflict as a conflict UI shows it: its value at the last
+// sync and its values in the source and in QLab now
+type ConflictField struct {
+	Field         string `json:"field"`
+	Cached        any    `json:"cached"` // Value at the last sync, nil when unknown
+	Source        any    `json:"source"`
+	QLab          any    `json:"qlab"`
+	SourceChanged bool   `json:"source_changed"` // Whether the source changed it since the last sync
+	QLabChanged   bool   `json:"qlab_changed"`   // Whether QLab changed it since the last sync
+}
+
+// ConflictView is what a conflict UI needs to present a conflict, with a ConflictField for
+// each field that changed
+type ConflictView struct {
+	CueNumber    string          `json:"cue_number"`
+	Name         string          `json:"name,omitempty"`
+	ConflictType ConflictType    `json:"conflict_type"`
+	Description  string          `json:"description"`
+	Fields       []ConflictField `json:"fields,omitempty"`
+}
+
+// NewConflictView returns the view of a conflict, its fields in name order
+func NewConflictView(conflict CueConflict) ConflictView {
+	view := ConflictView{CueNumber: conflict.CueNumber, ConflictType: conflict.ConflictType, Description: conflict.Description}
+	for _, data := range []map[string]any{conflict.SourceData, conflict.QLabData} {
+		if name, ok := data["name"].(string); ok && view.Name == "" {
+			view.Name = name
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(conflict.FieldConflicts)) {
+		change := conflict.FieldConflicts[name]
+		if change == nil {
+			continue
+		}
+		cached := diffValue(change.CacheValue)
+		view.Fields = append(view.Fields, ConflictField{
+			Field: name, Cached: change.CacheValue, Source: change.SourceValue, QLab: change.QLabValue,
+			SourceChanged: conflict.ConflictType != ConflictDeletedInSource && diffValue(change.SourceValue) != cached,
+			QLabChanged:   diffValue(change.QLabValue) != cached,
+		})
+		if name == "name" && view.Name == "" {
+			view.Name = diffValue(change.SourceValue)
+		}
+	}
+	return view
+}
+
+// ConflictPresenter renders conflicts for the terminal prompt. Set one with
+// SetConflictPresenter or PromptResolver.Presenter to change how conflicts are shown; UIs of
+// their own can build the same ConflictViews with NewConflictView, and InteractiveResolver
+// sends them with each request.
+type ConflictPresenter interface {
+	RenderConflict(view ConflictView) string
+}
+
+// TerminalPresenter is the default ConflictPresenter. It renders a conflict's description and
+// a table of its fields with their values at the last sync, in the source and in QLab side by
+// side, marking the values changed since the last sync with *.
+type TerminalPresenter struct {
+	MaxWidth int // Widest a value is shown before it's cut short, 0 for 30
+}
+
+// RenderConflict renders the view as text
+func (p TerminalPresenter) RenderConflict(view ConflictView) string {
+	var out strings.Builder
+	out.WriteString(view.Description)
+	if len(view.Fields) == 0 {
+		return out.String()
+	}
+
+	out.WriteString("\n\n")
+	table := tabwriter.NewWriter(&out, 0, 0, 2, ' ', 0)
+	source := "SOURCE"
+	if view.ConflictType == ConflictDeletedInSource {
+		source = "SOURCE (removed)"
+	}
+	fmt.Fprintf(table, "FIELD\tLAST SYNC\t%s\tQLAB\n", source)
+	for _, field := range view.Fields {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", field.Field, p.cell(field.Cached, false),
+			p.cell(field.Source, field.SourceChanged), p.cell(field.QLab, field.QLabChanged))
+	}
+	table.Flush()
+	out.WriteString("* changed since the last sync")
+	return out.String()
+}
+
+// cell formats a value for the table, cut short at MaxWidth
+func (p TerminalPresenter) cell(value any, changed bool) string {
+	maxWidth := p.MaxWidth
+	if maxWidth <= 0 {
+		maxWidth = 30
+	}
+	text := strings.Join(strings.Fields(diffValue(value)), " ")
+	if text == "" {
+		text = "-"
+	}
+	if runes := []rune(text); len(runes) > maxWidth {
+		text = string(runes[:maxWidth-1]) + "…"
+	}
+	if changed {
+		text += " *"
+	}
+	return text
+}
+
+// fieldConflictsFromModified builds field conflicts from a result's ModifiedFields, for
+// conflicts found without a scope comparison. Fields only one side changed keep the cached
+// value on the other.
+func fieldConflictsFromModified(result *CueChangeResult, qlabSide bool) map[string]*FieldConflict {
+	fields := make(map[string]*FieldConflict)
+	for key, diff := range result.ModifiedFields {
+		name, fromQLab := key, qlabSide
+		if field, ok := strings.CutPrefix(key, "source_vs_cache_"); ok {
+			name, fromQLab = field, false
+		} else if field, ok := strings.CutPrefix(key, "cache_vs_current_"); ok {
+			name, fromQLab = field, true
+		}
+		before, after, ok := parseModifiedField(diff)
+		if !ok {
+			continue
+		}
+		change := fields[name]
+		if change == nil {
+			change = &FieldConflict{FieldName: name, SourceValue: before, CacheValue: before, QLabValue: before}
+			fields[name] = change
+		}
+		if fromQLab {
+			change.QLabValue = after
+		} else {
+			change.SourceValue = after
+		}
+	}
+	return fields
+}
+
+// parseModifiedField splits a ModifiedFields entry, "'old' -> 'new'"
+func parseModifiedField(diff string) (before, after string, ok bool) {
+	before, after, ok = strings.Cut(diff, "' -> '")
+	if !ok || !strings.HasPrefix(before, "'") || !strings.HasSuffix(after, "'") {
+		return "", "", false
+	}
+	return before[1:], after[:len(after)-1], true
+}
+
+// SetConflictPresenter sets how the terminal prompt renders conflicts (nil for
+// TerminalPresenter)
+func (q *Workspace) SetConflictPresenter(presenter ConflictPresenter) {
+	q.conflictPresenter = presenter
+}
@@ -0,0 +1,75 @@
+package qlab
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestTerminalPresenter tests that a conflict is rendered with its field values side by side,
+// marking the sides changed since the last sync
+func TestTerminalPresenter(t *testing.T) {
+	conflict := CueConflict{
+		CueNumber:    "12",
+		ConflictType: ConflictThreeWayDivergence,
+		Description:  "cue '12' has conflicting changes in source and QLab (fields: [duration name])",
+		FieldConflicts: map[string]*FieldConflict{
+			"name":     {SourceValue: "Thunder crack", CacheValue: "Thunder", QLabValue: "Thunder"},
+			"duration": {SourceValue: 10.0, CacheValue: 10.0, QLabValue: 8.5},
+			"notes":    {SourceValue: "A very long note about the storm", CacheValue: nil, QLabValue: nil},
+		},
+	}
+	view := NewConflictView(conflict)
+	if view.Name != "Thunder crack" || len(view.Fields) != 3 || view.Fields[0].Field != "duration" || !view.Fields[0].QLabChanged || view.Fields[0].SourceChanged {
+		t.Fatalf("Unexpected view %+v", view)
+	}
+
+	expected := conflict.Description + `
+
+FIELD     LAST SYNC  SOURCE             QLAB
+duration  10         10                 8.5 *
+name      Thunder    Thunder crack *    Thunder
+notes     -          A very long no… *  -
+* changed since the last sync`
+	if rendered := (TerminalPresenter{MaxWidth: 15}).RenderConflict(view); rendered != expected {
+		t.Errorf("Unexpected rendering:\n%s\nwant:\n%s", rendered, expected)
+	}
+}
+
+// TestConflictViewsFromModifiedFields tests that conflicts found without a scope comparison
+// and deletion conflicts carry their field values from the modified fields
+func TestConflictViewsFromModifiedFields(t *testing.T) {
+	workspace := &Workspace{}
+	comparison := &ThreeWayComparison{HasCache: true, HasQLabData: true, CueResults: map[string]*CueChangeResult{
+		"1": {Action: "update", Reason: "both source and QLab modified", ModifiedFields: map[string]string{
+			"source_vs_cache_name":      "'House open' -> 'Doors'",
+			"cache_vs_current_duration": "'5' -> '4.5'",
+		}},
+		"2": {Action: "delete", Reason: "removed from source but modified in QLab", ModifiedFields: map[string]string{
+			"notes": "'' -> 'Keep this'",
+		}},
+	}}
+	conflicts, err := workspace.IdentifyConflicts(comparison)
+	if err != nil || len(conflicts) != 2 {
+		t.Fatalf("Expected two conflicts, got %v (%v)", conflicts, err)
+	}
+
+	view := NewConflictView(conflicts[0])
+	if len(view.Fields) != 2 {
+		t.Fatalf("Expected two fields, got %+v", view.Fields)
+	}
+	if duration := view.Fields[0]; duration.Source != "5" || duration.QLab != "4.5" || duration.SourceChanged || !duration.QLabChanged {
+		t.Errorf("Unexpected duration %+v", duration)
+	}
+	if name := view.Fields[1]; name.Source != "Doors" || name.QLab != "House open" || !name.SourceChanged {
+		t.Errorf("Unexpected name %+v", name)
+	}
+
+	deleted := conflicts[1]
+	if deleted.ConflictType != ConflictDeletedInSource || deleted.Fields() != nil {
+		t.Errorf("Expected a deletion conflict without per-field choices, got %+v", deleted)
+	}
+	rendered := TerminalPresenter{}.RenderConflict(NewConflictView(deleted))
+	if !strings.Contains(rendered, "SOURCE (removed)") || !strings.Contains(rendered, "Keep this *") {
+		t.Errorf("Unexpected rendering of the deletion:\n%s", rendered)
+	}
+}
@@ -2,6 +2,9 @@ package qlab
 
 import (
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 )
 
 type ConflictResolutionChoice string
@@ -10,25 +13,221 @@ const (
 	ChoiceUseSource ConflictResolutionChoice = "use_source"
 	ChoiceKeepQLab  ConflictResolutionChoice = "keep_qlab"
 	ChoiceSkip      ConflictResolutionChoice = "skip"
+	ChoicePerField  ConflictResolutionChoice = "per_field" // Source or QLab is chosen for each field, see FieldConflictResolver
 )
 
+// ResolutionScope is how far a conflict resolution choice reaches
+type ResolutionScope string
+
+const (
+	ResolveThisCue     ResolutionScope = "cue"       // Only the conflict the choice was made for
+	ResolveThisGroup   ResolutionScope = "group"     // Every conflict in the cue's group, including nested groups
+	ResolveThisCueList ResolutionScope = "cue_list"  // Every conflict in the cue's cue list
+	ResolveRemaining   ResolutionScope = "remaining" // This conflict and every conflict after it
+)
+
+// ScopedResolution applies one choice to every conflict within a scope around an anchor conflict
+type ScopedResolution struct {
+	CueNumber string                   `json:"cue_number"` // Conflict the choice was made for
+	Choice    ConflictResolutionChoice `json:"choice"`
+	Scope     ResolutionScope          `json:"scope"`
+}
+
 type ConflictResolutionRequest struct {
-	Conflicts []CueConflict `json:"conflicts"`
-	RequestID string        `json:"request_id"`
+	Conflicts []CueConflict  `json:"conflicts"`
+	Views     []ConflictView `json:"views"` // Each conflict's field values side by side, in conflict order
+	RequestID string         `json:"request_id"`
 }
 
 type ConflictResolutionResponse struct {
-	RequestID   string                              `json:"request_id"`
-	Resolutions map[string]ConflictResolutionChoice `json:"resolutions"`
+	RequestID         string                              `json:"request_id"`
+	Resolutions       map[string]ConflictResolutionChoice `json:"resolutions"`
+	ScopedResolutions []ScopedResolution                  `json:"scoped_resolutions,omitempty"` // Applied to conflicts without an entry in Resolutions
+	FieldResolutions  FieldResolutions                    `json:"field_resolutions,omitempty"`  // Resolves a conflict without an entry in Resolutions per field
 }
 
+// FieldResolutions are the per-field choices for conflicts resolved with ChoicePerField: cue
+// number -> field -> ChoiceUseSource or ChoiceKeepQLab
+type FieldResolutions map[string]map[string]ConflictResolutionChoice
+
+// ConflictResolver decides how conflicts found during a transmit are resolved, returning a
+// choice for each conflict keyed by cue number. Pass one to TransmitWorkspaceData with
+// WithConflictResolver to sync without the terminal prompt.
 type ConflictResolver interface {
 	ResolveConflicts(conflicts []CueConflict) (map[string]ConflictResolutionChoice, error)
 }
 
+// FieldConflictResolver is a ConflictResolver that can also choose per field, e.g. keeping
+// QLab's trimmed duration but taking the new name from source. ResolveFieldConflicts is called
+// with the conflicts ResolveConflicts answered ChoicePerField and returns a choice for each of
+// their Fields.
+type FieldConflictResolver interface {
+	ConflictResolver
+	ResolveFieldConflicts(conflicts []CueConflict) (FieldResolutions, error)
+}
+
+// Fields returns the names of the fields a per-field resolution of the conflict chooses
+// between, sorted. A cue removed from the source has none: it's deleted or kept whole.
+func (c CueConflict) Fields() []string {
+	if c.ConflictType == ConflictDeletedInSource {
+		return nil
+	}
+	fields := slices.Collect(maps.Keys(c.FieldConflicts))
+	if len(fields) == 0 {
+		fields = slices.Clone(c.Properties)
+	}
+	slices.Sort(fields)
+	return slices.Compact(fields)
+}
+
+// PolicyResolver resolves every conflict with the same choice
+type PolicyResolver struct {
+	Choice ConflictResolutionChoice
+}
+
+// ResolveConflicts returns the policy's choice for every conflict
+func (r PolicyResolver) ResolveConflicts(conflicts []CueConflict) (map[string]ConflictResolutionChoice, error) {
+	resolutions := make(map[string]ConflictResolutionChoice, len(conflicts))
+	for _, conflict := range conflicts {
+		resolutions[conflict.CueNumber] = r.Choice
+	}
+	return resolutions, nil
+}
+
+// Resolvers for headless syncs that treat one side as authoritative
+var (
+	AlwaysSource ConflictResolver = PolicyResolver{Choice: ChoiceUseSource}
+	AlwaysQLab   ConflictResolver = PolicyResolver{Choice: ChoiceKeepQLab}
+	AlwaysSkip   ConflictResolver = PolicyResolver{Choice: ChoiceSkip}
+)
+
+// FieldPolicyResolver resolves the named fields of every conflict with their choice and the
+// other fields with Default, so conflicts touching none of the named fields get Default
+type FieldPolicyResolver struct {
+	Fields  map[string]ConflictResolutionChoice // e.g. {"duration": ChoiceKeepQLab}
+	Default ConflictResolutionChoice
+}
+
+// ResolveConflicts returns ChoicePerField for the conflicts with a named field and Default
+// for the others
+func (r FieldPolicyResolver) ResolveConflicts(conflicts []CueConflict) (map[string]ConflictResolutionChoice, error) {
+	resolutions := make(map[string]ConflictResolutionChoice, len(conflicts))
+	for _, conflict := range conflicts {
+		resolutions[conflict.CueNumber] = r.Default
+		for _, field := range conflict.Fields() {
+			if _, named := r.Fields[field]; named {
+				resolutions[conflict.CueNumber] = ChoicePerField
+				break
+			}
+		}
+	}
+	return resolutions, nil
+}
+
+// ResolveFieldConflicts returns the named fields' choices, and Default for the other fields
+func (r FieldPolicyResolver) ResolveFieldConflicts(conflicts []CueConflict) (FieldResolutions, error) {
+	resolutions := make(FieldResolutions, len(conflicts))
+	for _, conflict := range conflicts {
+		choices := make(map[string]ConflictResolutionChoice)
+		for _, field := range conflict.Fields() {
+			choice, named := r.Fields[field]
+			if !named {
+				choice = r.Default
+			}
+			choices[field] = choice
+		}
+		resolutions[conflict.CueNumber] = choices
+	}
+	return resolutions, nil
+}
+
+// CallbackResolver resolves each conflict by calling a function, in conflict order
+type CallbackResolver func(conflict CueConflict) (ConflictResolutionChoice, error)
+
+// ResolveConflicts calls the function for every conflict, stopping at the first error
+func (f CallbackResolver) ResolveConflicts(conflicts []CueConflict) (map[string]ConflictResolutionChoice, error) {
+	resolutions := make(map[string]ConflictResolutionChoice, len(conflicts))
+	for _, conflict := range conflicts {
+		choice, err := f(conflict)
+		if err != nil {
+			return nil, fmt.Errorf("cue %s: %w", conflict.CueNumber, err)
+		}
+		resolutions[conflict.CueNumber] = choice
+	}
+	return resolutions, nil
+}
+
+// PromptResolver asks the user in the terminal, the way TransmitWorkspaceData does by default
+type PromptResolver struct {
+	Logger    Logger            // Receives the conflict summaries shown around the prompts, nil for the default logger
+	Presenter ConflictPresenter // Renders each conflict above its prompt, nil for TerminalPresenter
+}
+
+// ResolveConflicts prompts for each conflict, offering to apply each answer to a wider scope
+func (r PromptResolver) ResolveConflicts(conflicts []CueConflict) (map[string]ConflictResolutionChoice, error) {
+	presenter := r.Presenter
+	if presenter == nil {
+		presenter = TerminalPresenter{}
+	}
+	return promptForResolutions(conflicts, orDefaultLogger(r.Logger), presenter)
+}
+
+// ResolveFieldConflicts prompts for each field of the conflicts the user chose to resolve per field
+func (r PromptResolver) ResolveFieldConflicts(conflicts []CueConflict) (FieldResolutions, error) {
+	return promptForFieldResolutions(conflicts, orDefaultLogger(r.Logger))
+}
+
+// applyResolver resolves conflicts with a resolver and applies its choices, refusing to
+// continue with a conflict or field left unresolved or an unknown choice
+func applyResolver(resolver ConflictResolver, conflicts []CueConflict, comparison *ThreeWayComparison) error {
+	resolutions, err := resolver.ResolveConflicts(conflicts)
+	if err != nil {
+		return err
+	}
+	var perField []CueConflict
+	for _, conflict := range conflicts {
+		switch choice, ok := resolutions[conflict.CueNumber]; {
+		case !ok:
+			return fmt.Errorf("conflict for cue %s was left unresolved", conflict.CueNumber)
+		case choice == ChoicePerField:
+			if len(conflict.Fields()) == 0 {
+				return fmt.Errorf("conflict for cue %s has no fields to choose between", conflict.CueNumber)
+			}
+			perField = append(perField, conflict)
+		case choice != ChoiceUseSource && choice != ChoiceKeepQLab && choice != ChoiceSkip:
+			return fmt.Errorf("invalid resolution %q for cue %s", choice, conflict.CueNumber)
+		}
+	}
+
+	var fieldResolutions FieldResolutions
+	if len(perField) > 0 {
+		fieldResolver, ok := resolver.(FieldConflictResolver)
+		if !ok {
+			return fmt.Errorf("resolver %T can't resolve cue %s per field", resolver, perField[0].CueNumber)
+		}
+		if fieldResolutions, err = fieldResolver.ResolveFieldConflicts(perField); err != nil {
+			return err
+		}
+		for _, conflict := range perField {
+			for _, field := range conflict.Fields() {
+				switch choice, ok := fieldResolutions[conflict.CueNumber][field]; {
+				case !ok:
+					return fmt.Errorf("field %s of cue %s was left unresolved", field, conflict.CueNumber)
+				case choice != ChoiceUseSource && choice != ChoiceKeepQLab:
+					return fmt.Errorf("invalid resolution %q for field %s of cue %s", choice, field, conflict.CueNumber)
+				}
+			}
+		}
+	}
+	ApplyResolutions(comparison, resolutions)
+	ApplyFieldResolutions(comparison, fieldResolutions)
+	return nil
+}
+
 type InteractiveResolver struct {
-	responseChannel chan ConflictResolutionResponse
-	requestSender   func(ConflictResolutionRequest) error
+	responseChannel  chan ConflictResolutionResponse
+	requestSender    func(ConflictResolutionRequest) error
+	fieldResolutions FieldResolutions // From the last response, for ResolveFieldConflicts
 }
 
 func NewInteractiveResolver(requestSender func(ConflictResolutionRequest) error) *InteractiveResolver {
@@ -45,6 +244,9 @@ func (r *InteractiveResolver) ResolveConflicts(conflicts []CueConflict) (map[str
 		Conflicts: conflicts,
 		RequestID: requestID,
 	}
+	for _, conflict := range conflicts {
+		request.Views = append(request.Views, NewConflictView(conflict))
+	}
 
 	err := r.requestSender(request)
 	if err != nil {
@@ -57,7 +259,29 @@ func (r *InteractiveResolver) ResolveConflicts(conflicts []CueConflict) (map[str
 		return nil, fmt.Errorf("request ID mismatch: expected %s, got %s", requestID, response.RequestID)
 	}
 
-	return response.Resolutions, nil
+	// A conflict answered field by field is resolved per field unless Resolutions says otherwise
+	r.fieldResolutions = response.FieldResolutions
+	resolutions := maps.Clone(response.Resolutions)
+	if resolutions == nil {
+		resolutions = make(map[string]ConflictResolutionChoice)
+	}
+	for cueNumber := range response.FieldResolutions {
+		if _, resolved := resolutions[cueNumber]; !resolved {
+			resolutions[cueNumber] = ChoicePerField
+		}
+	}
+	return ExpandScopedResolutions(conflicts, resolutions, response.ScopedResolutions), nil
+}
+
+// ResolveFieldConflicts returns the field choices sent with the response to the last request
+func (r *InteractiveResolver) ResolveFieldConflicts(conflicts []CueConflict) (FieldResolutions, error) {
+	resolutions := make(FieldResolutions, len(conflicts))
+	for _, conflict := range conflicts {
+		if choices, ok := r.fieldResolutions[conflict.CueNumber]; ok {
+			resolutions[conflict.CueNumber] = choices
+		}
+	}
+	return resolutions, nil
 }
 
 func (r *InteractiveResolver) SubmitResolution(response ConflictResolutionResponse) {
@@ -70,10 +294,17 @@ func ApplyResolutions(comparison *ThreeWayComparison, resolutions map[string]Con
 		if !exists {
 			continue
 		}
+		if comparison.Resolutions == nil {
+			comparison.Resolutions = make(map[string]ConflictResolutionChoice)
+		}
+		comparison.Resolutions[cueNumber] = choice
 
 		switch choice {
 		case ChoiceUseSource:
-			result.Action = "update"
+			// For a cue removed from the source, the source's version is the deletion
+			if result.Action != "delete" {
+				result.Action = "update"
+			}
 			result.Reason = "User chose to use source file version"
 
 		case ChoiceKeepQLab:
@@ -92,3 +323,131 @@ func ApplyResolutions(comparison *ThreeWayComparison, resolutions map[string]Con
 		comparison.CueResults[cueNumber] = result
 	}
 }
+
+// ApplyFieldResolutions applies per-field choices. Fields kept from QLab are recorded in
+// QLabChosenFields for merging back to source and left out of the cue's update, so only the
+// fields taken from source are sent; a cue with every field kept from QLab is skipped.
+func ApplyFieldResolutions(comparison *ThreeWayComparison, resolutions FieldResolutions) {
+	for cueNumber, choices := range resolutions {
+		result, exists := comparison.CueResults[cueNumber]
+		if !exists {
+			continue
+		}
+		if comparison.FieldResolutions == nil {
+			comparison.FieldResolutions = make(FieldResolutions)
+		}
+		comparison.FieldResolutions[cueNumber] = choices
+
+		var fromSource, fromQLab []string
+		for field, choice := range choices {
+			switch choice {
+			case ChoiceUseSource:
+				fromSource = append(fromSource, field)
+			case ChoiceKeepQLab:
+				fromQLab = append(fromQLab, field)
+			}
+		}
+		slices.Sort(fromSource)
+		slices.Sort(fromQLab)
+
+		if len(fromQLab) > 0 {
+			if comparison.QLabChosenFields == nil {
+				comparison.QLabChosenFields = make(map[string]map[string]bool)
+			}
+			kept := make(map[string]bool, len(fromQLab))
+			for _, field := range fromQLab {
+				kept[field] = true
+			}
+			comparison.QLabChosenFields[cueNumber] = kept
+		}
+		result.KeptFields = fromQLab
+
+		if len(fromSource) == 0 {
+			result.Action = "skip"
+			result.Reason = "User chose to keep QLab version of every field"
+		} else {
+			result.Action = "update"
+			result.Reason = fmt.Sprintf("User chose per field: %s from source", strings.Join(fromSource, ", "))
+			if len(fromQLab) > 0 {
+				result.Reason += fmt.Sprintf(", %s kept from QLab", strings.Join(fromQLab, ", "))
+			}
+		}
+		comparison.CueResults[cueNumber] = result
+	}
+}
+
+// ExpandScopedResolutions returns a per-cue resolution for every conflict covered by resolutions
+// or scoped. Per-cue resolutions always win; otherwise the first scoped resolution covering a
+// conflict applies, so answers given earlier in a prompt sequence are not overridden later.
+func ExpandScopedResolutions(conflicts []CueConflict, resolutions map[string]ConflictResolutionChoice, scoped []ScopedResolution) map[string]ConflictResolutionChoice {
+	expanded := make(map[string]ConflictResolutionChoice, len(conflicts))
+	for cueNumber, choice := range resolutions {
+		expanded[cueNumber] = choice
+	}
+
+	for _, resolution := range scoped {
+		for _, cueNumber := range ConflictsInScope(conflicts, resolution.CueNumber, resolution.Scope) {
+			if _, resolved := expanded[cueNumber]; !resolved {
+				expanded[cueNumber] = resolution.Choice
+			}
+		}
+	}
+	return expanded
+}
+
+// ConflictsInScope returns the cue numbers of the conflicts that a resolution for the anchor
+// conflict covers at the given scope, in conflict order. The group of a group cue's own conflict
+// is the group itself; for any other cue it is the innermost enclosing group or cue list.
+func ConflictsInScope(conflicts []CueConflict, anchorCueNumber string, scope ResolutionScope) []string {
+	anchorIndex := -1
+	for i, conflict := range conflicts {
+		if conflict.CueNumber == anchorCueNumber {
+			anchorIndex = i
+			break
+		}
+	}
+	if anchorIndex < 0 {
+		return nil
+	}
+	anchor := conflicts[anchorIndex]
+
+	var covered []string
+	switch scope {
+	case ResolveThisGroup:
+		group := conflictGroup(conflicts, anchor)
+		for _, conflict := range conflicts {
+			if conflict.CueIdentifier == group || slices.Contains(conflict.Ancestors, group) {
+				covered = append(covered, conflict.CueNumber)
+			}
+		}
+	case ResolveThisCueList:
+		if anchor.CueList == "" {
+			return []string{anchor.CueNumber}
+		}
+		for _, conflict := range conflicts {
+			if conflict.CueList == anchor.CueList {
+				covered = append(covered, conflict.CueNumber)
+			}
+		}
+	case ResolveRemaining:
+		for _, conflict := range conflicts[anchorIndex:] {
+			covered = append(covered, conflict.CueNumber)
+		}
+	default:
+		covered = []string{anchor.CueNumber}
+	}
+	return covered
+}
+
+// conflictGroup returns the identifier of the group a group-scoped resolution of anchor applies to
+func conflictGroup(conflicts []CueConflict, anchor CueConflict) string {
+	for _, conflict := range conflicts {
+		if slices.Contains(conflict.Ancestors, anchor.CueIdentifier) {
+			return anchor.CueIdentifier // The anchor is itself a group with conflicting children
+		}
+	}
+	if len(anchor.Ancestors) > 0 {
+		return anchor.Ancestors[0]
+	}
+	return anchor.CueIdentifier
+}
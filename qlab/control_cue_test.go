@@ -0,0 +1,63 @@
+package qlab
+
+import (
+	"testing"
+)
+
+// TestControlCueTypes tests creating and updating wait, devamp, arm, disarm and memo cues,
+// with targets resolved in the second pass
+func TestControlCueTypes(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+
+	mapping := &CueMapping{NumberToID: make(map[string]string)}
+	for _, cueData := range []map[string]any{
+		{"type": "audio", "number": "1", "name": "Vamp"},
+		{"type": "wait", "number": "2", "duration": 4.5},
+		{"type": "devamp", "number": "3", "cueTargetNumber": "1", "startNextCueWhenSliceEnds": true, "stopTargetWhenSliceEnds": false},
+		{"type": "arm", "number": "4", "cueTargetName": "Vamp"},
+		{"type": "disarm", "number": "5", "cueTargetNumber": "1"},
+		{"type": "memo", "number": "6", "name": "Check mics", "notes": "Before house opens", "colorName": "red"},
+	} {
+		if _, err := workspace.processCueListWithParentMappingAndChangeDetection(cueData, "", "", mapping, nil); err != nil {
+			t.Fatalf("processing cue %v failed: %v", cueData["number"], err)
+		}
+	}
+	if len(mapping.CuesWithTargets) != 3 {
+		t.Fatalf("Expected devamp, arm and disarm targets queued for the second pass, got %+v", mapping.CuesWithTargets)
+	}
+	if err := workspace.setCueTargets(mapping); err != nil {
+		t.Fatalf("setCueTargets failed: %v", err)
+	}
+
+	for _, number := range []string{"3", "4", "5"} {
+		if cue := mockServer.GetCue(mapping.NumberToID[number]); cue == nil || cue.CueTargetNumber != "1" {
+			t.Errorf("Expected cue %s to target cue 1, got %+v", number, cue)
+		}
+	}
+	if got := mockServer.GetCue(mapping.NumberToID["2"]).Properties["duration"]; got != "4.5" {
+		t.Errorf("Expected the wait duration 4.5, got %q", got)
+	}
+	devamp := mockServer.GetCue(mapping.NumberToID["3"])
+	if devamp.Properties["startNextCueWhenSliceEnds"] != "1" || devamp.Properties["stopTargetWhenSliceEnds"] != "0" {
+		t.Errorf("Expected devamp settings sent, got %v", devamp.Properties)
+	}
+	memo := mockServer.GetCue(mapping.NumberToID["6"])
+	if memo.Properties["notes"] != "Before house opens" || memo.Properties["colorName"] != "red" {
+		t.Errorf("Expected memo notes and color, got %v", memo.Properties)
+	}
+
+	memoID, waitID := mapping.NumberToID["6"], mapping.NumberToID["2"]
+	if err := workspace.updateCueProperties(memoID, map[string]any{"type": "memo", "notes": "After house opens", "colorName": "blue"}); err != nil {
+		t.Fatalf("Updating memo failed: %v", err)
+	}
+	if err := workspace.updateCueProperties(waitID, map[string]any{"type": "wait", "duration": "10"}); err != nil {
+		t.Fatalf("Updating wait failed: %v", err)
+	}
+	if memo := mockServer.GetCue(memoID); memo.Properties["notes"] != "After house opens" || memo.Properties["colorName"] != "blue" {
+		t.Errorf("Expected memo notes and color updated, got %v", memo.Properties)
+	}
+	if got := mockServer.GetCue(waitID).Properties["duration"]; got != "10" {
+		t.Errorf("Expected the wait duration updated to 10, got %q", got)
+	}
+}
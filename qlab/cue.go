@@ -2,6 +2,8 @@ package qlab
 
 // Cue represents a generic QLab cue with all possible properties.
 // Different cue types will use different subsets of these fields.
+//
+// This API is stable.
 type Cue struct {
 	// Common properties (all cue types)
 	Type          string `json:"type"`
@@ -24,12 +26,13 @@ type Cue struct {
 	// Target properties (for Start, Stop, Fade cues, etc.)
 	CueTargetNumber string `json:"cueTargetNumber,omitempty"`
 	CueTargetID     string `json:"cueTargetID,omitempty"`
+	CueTargetName   string `json:"cueTargetName,omitempty"` // Target by cue name, resolved when transmitting
 	FileTarget      string `json:"fileTarget,omitempty"`
 
 	// Group/List properties
-	Mode         int   `json:"mode,omitempty"` // 0=list, 1=start first+enter, 2=start first, 3=timeline, 4=start random, 5=cart, 6=playlist
-	InfiniteLoop bool  `json:"infiniteLoop,omitempty"`
-	Cues         []Cue `json:"cues,omitempty"` // Child cues for Group/List cues
+	Mode         GroupMode `json:"mode,omitempty"` // 0=list, 1=start first+enter, 2=start first, 3=timeline, 4=start random, 5=cart, 6=playlist
+	InfiniteLoop bool      `json:"infiniteLoop,omitempty"`
+	Cues         []Cue     `json:"cues,omitempty"` // Child cues for Group/List cues
 
 	// Text cue properties
 	Text          string    `json:"text,omitempty"`                        // Text content
@@ -38,6 +41,9 @@ type Cue struct {
 	TextFontSize  float64   `json:"text/format/fontSize,omitempty"`        // Font size in points
 	TextAlignment string    `json:"text/format/alignment,omitempty"`       // "left", "center", "right", "justify"
 
+	// Script cue properties
+	ScriptSource string `json:"scriptSource,omitempty"` // AppleScript source, kept exactly as written
+
 	// Video/Text cue stage properties
 	StageID   string `json:"stageID,omitempty"`   // Video stage unique ID
 	StageName string `json:"stageName,omitempty"` // Video stage name
@@ -50,14 +56,31 @@ type Cue struct {
 	Quaternion   []float64 `json:"quaternion,omitempty"`   // [a, b, c, d] for 3D rotation
 	Opacity      float64   `json:"opacity,omitempty"`      // 0.0 to 1.0
 
+	// Video cue properties
+	Layer               int     `json:"layer,omitempty"`               // Layer on the stage; higher layers are drawn on top
+	FillStage           bool    `json:"fillStage,omitempty"`           // Scale the video to fill the stage
+	PreserveAspectRatio bool    `json:"preserveAspectRatio,omitempty"` // Keep the aspect ratio when filling the stage
+	HoldAtEnd           bool    `json:"holdAtEnd,omitempty"`           // Hold the last frame when playback ends
+	Rate                float64 `json:"rate,omitempty"`                // Playback rate, 1.0 is normal speed
+
 	// Fade cue geometry parameter enables (checkboxes)
 	DoOpacity     bool `json:"doOpacity,omitempty"`     // Enable opacity fading
 	DoTranslation bool `json:"doTranslation,omitempty"` // Enable translation fading
 	DoScale       bool `json:"doScale,omitempty"`       // Enable scale fading
 	DoRotation    bool `json:"doRotation,omitempty"`    // Enable rotation fading
+
+	// Fade cue targets
+	Levels             [][]float64 `json:"levels,omitempty"`             // [row, column, dB] level targets; [0, 0, dB] is the master
+	StopTargetWhenDone bool        `json:"stopTargetWhenDone,omitempty"` // Stop the target cue when the fade completes
+
+	// Properties the library doesn't recognize, e.g. metadata from other tools.
+	// They are carried through JSON and CUE round trips unchanged and never sent to QLab.
+	Extra map[string]any `json:"-"`
 }
 
 // WorkspaceData represents the parsed workspace structure
+//
+// This API is stable.
 type WorkspaceData struct {
 	Name string `json:"name"`
 	Cues []Cue  `json:"cues"`
@@ -68,12 +91,17 @@ const (
 	CueTypeAudio      = "audio"
 	CueTypeVideo      = "video"
 	CueTypeText       = "text"
+	CueTypeLight      = "light"
 	CueTypeFade       = "fade"
 	CueTypeStart      = "start"
 	CueTypeStop       = "stop"
 	CueTypePause      = "pause"
 	CueTypeReset      = "reset"
 	CueTypeDevamp     = "devamp"
+	CueTypeArm        = "arm"
+	CueTypeDisarm     = "disarm"
+	CueTypeWait       = "wait"
+	CueTypeLoad       = "load"
 	CueTypeGoto       = "goto"
 	CueTypeTarget     = "target"
 	CueTypeGroup      = "group"
@@ -83,6 +111,7 @@ const (
 	CueTypeMIDIFile   = "midi file"
 	CueTypeTimecode   = "timecode"
 	CueTypeNetwork    = "network"
+	CueTypeOSC        = "osc" // QLab 4 name for Network cues
 	CueTypeMSC        = "msc"
 	CueTypeCamera     = "camera"
 	CueTypeMicrophone = "microphone"
@@ -115,11 +144,11 @@ const (
 
 // GroupMode constants
 const (
-	GroupModeList               = 0 // Cue list
-	GroupModeStartFirstAndEnter = 1 // Start first and enter
-	GroupModeStartFirst         = 2 // Start first
-	GroupModeTimeline           = 3 // Timeline
-	GroupModeStartRandom        = 4 // Start random
-	GroupModeCart               = 5 // Cart
-	GroupModePlaylist           = 6 // Playlist
+	GroupModeList               GroupMode = 0 // Cue list
+	GroupModeStartFirstAndEnter GroupMode = 1 // Start first and enter
+	GroupModeStartFirst         GroupMode = 2 // Start first
+	GroupModeTimeline           GroupMode = 3 // Timeline
+	GroupModeStartRandom        GroupMode = 4 // Start random
+	GroupModeCart               GroupMode = 5 // Cart
+	GroupModePlaylist           GroupMode = 6 // Playlist
 )
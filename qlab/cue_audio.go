@@ -0,0 +1,179 @@
+package qlab
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// LevelGang puts a cell of an audio cue's level matrix in a gang, so that cells in the same
+// gang move together. Row 0 is the main row and Column 0 the main (input) column.
+type LevelGang struct {
+	Row    int
+	Column int
+	Gang   string
+}
+
+// parseLevelMatrix reads the levels of a "levels" object: "main" is the master level,
+// "outputs" the main row's output levels, either a list starting at output 1 or an object
+// keyed by output number, and "crosspoints" a list of [row, column, dB] entries
+func parseLevelMatrix(matrix map[string]any) []FadeLevel {
+	var levels []FadeLevel
+
+	if main, ok := toFloat(matrix["main"]); ok {
+		levels = append(levels, FadeLevel{Row: 0, Column: 0, Decibel: main})
+	}
+
+	switch outputs := matrix["outputs"].(type) {
+	case []any:
+		for i, value := range outputs {
+			if db, ok := toFloat(value); ok {
+				levels = append(levels, FadeLevel{Row: 0, Column: i + 1, Decibel: db})
+			}
+		}
+	case map[string]any:
+		for _, output := range slices.Sorted(maps.Keys(outputs)) {
+			column, err := strconv.Atoi(output)
+			db, ok := toFloat(outputs[output])
+			if err == nil && column > 0 && ok {
+				levels = append(levels, FadeLevel{Row: 0, Column: column, Decibel: db})
+			}
+		}
+	}
+
+	if crosspoints, ok := matrix["crosspoints"].([]any); ok {
+		levels = append(levels, parseLevelEntries(crosspoints)...)
+	}
+
+	return levels
+}
+
+// parseLevelGangs reads the "gangs" of a "levels" object, a list of [row, column, gang]
+// triples or {"row", "column", "gang"} objects
+func parseLevelGangs(cueData map[string]any) []LevelGang {
+	matrix, _ := cueData["levels"].(map[string]any)
+	entries, _ := matrix["gangs"].([]any)
+
+	var gangs []LevelGang
+	for _, entry := range entries {
+		var row, column, gang any
+		switch v := entry.(type) {
+		case []any:
+			if len(v) != 3 {
+				continue
+			}
+			row, column, gang = v[0], v[1], v[2]
+		case map[string]any:
+			row, column, gang = v["row"], v["column"], v["gang"]
+		}
+		r, rowOK := toFloat(row)
+		c, columnOK := toFloat(column)
+		name, nameOK := gang.(string)
+		if rowOK && columnOK && nameOK {
+			gangs = append(gangs, LevelGang{Row: int(r), Column: int(c), Gang: name})
+		}
+	}
+	return gangs
+}
+
+// setAudioLevels sets the level matrix and gangs of an audio cue from its "levels" cue
+// data. It runs after the file target is set, since the matrix rows follow the file's
+// channels.
+func (q *Workspace) setAudioLevels(uniqueID string, cueData map[string]any) error {
+	// Levels: /level {row} {column} {decibels}
+	for _, level := range parseFadeLevels(cueData) {
+		if err := q.setCuePropertyWithArgs(uniqueID, "level", int32(level.Row), int32(level.Column), float32(level.Decibel)); err != nil {
+			return fmt.Errorf("failed to set level %d/%d: %w", level.Row, level.Column, err)
+		}
+	}
+
+	// Gangs: /gang {row} {column} {name}
+	for _, gang := range parseLevelGangs(cueData) {
+		if err := q.setCuePropertyWithArgs(uniqueID, "gang", int32(gang.Row), int32(gang.Column), gang.Gang); err != nil {
+			return fmt.Errorf("failed to set gang %d/%d: %w", gang.Row, gang.Column, err)
+		}
+	}
+	return nil
+}
+
+// levelCells returns the level targets and gangs of a cue keyed by matrix cell
+func levelCells(cue map[string]any) map[string]string {
+	cells := make(map[string]string)
+	for _, level := range parseFadeLevels(cue) {
+		cells[fmt.Sprintf("%d/%d", level.Row, level.Column)] = strconv.FormatFloat(level.Decibel, 'g', -1, 64)
+	}
+	for _, gang := range parseLevelGangs(cue) {
+		cells[fmt.Sprintf("gang %d/%d", gang.Row, gang.Column)] = gang.Gang
+	}
+	return cells
+}
+
+// formatLevelCells normalizes level cells for comparison, keeping only the cells in keep
+// unless it is nil
+func formatLevelCells(cells, keep map[string]string) string {
+	var parts []string
+	for _, cell := range slices.Sorted(maps.Keys(cells)) {
+		if _, ok := keep[cell]; keep == nil || ok {
+			parts = append(parts, cell+"="+cells[cell])
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// sharedLevels returns the levels of two cues normalized for comparison, keeping only the
+// cells both cues set. QLab is only asked for the master level, so a cell set on one side
+// alone isn't a change.
+func sharedLevels(cue1, cue2 map[string]any) (string, string) {
+	cells1, cells2 := levelCells(cue1), levelCells(cue2)
+	return formatLevelCells(cells1, cells2), formatLevelCells(cells2, cells1)
+}
+
+// carryLevels copies the level cells and gangs that source cues set onto the matching cues
+// of a QLab snapshot, which only holds the master level, so that later level changes in the
+// source are detected against the cache
+func (q *Workspace) carryLevels(source, snapshot map[string]any) {
+	snapshotCues := q.indexCuesFromWorkspace(snapshot)
+	for key, sourceCue := range q.indexCuesFromWorkspace(source) {
+		snapshotCue, ok := snapshotCues[key]
+		if !ok {
+			continue
+		}
+		sourceLevels, sourceGangs := parseFadeLevels(sourceCue), parseLevelGangs(sourceCue)
+		if len(sourceLevels) == 0 && len(sourceGangs) == 0 {
+			continue
+		}
+
+		levels, gangs := parseFadeLevels(snapshotCue), parseLevelGangs(snapshotCue)
+		known := len(levels) + len(gangs)
+		for _, level := range sourceLevels {
+			if !slices.ContainsFunc(levels, func(l FadeLevel) bool { return l.Row == level.Row && l.Column == level.Column }) {
+				levels = append(levels, level)
+			}
+		}
+		for _, gang := range sourceGangs {
+			if !slices.ContainsFunc(gangs, func(g LevelGang) bool { return g.Row == gang.Row && g.Column == gang.Column }) {
+				gangs = append(gangs, gang)
+			}
+		}
+		if len(levels)+len(gangs) == known {
+			continue
+		}
+
+		crosspoints := make([]any, 0, len(levels))
+		for _, level := range levels {
+			crosspoints = append(crosspoints, []any{float64(level.Row), float64(level.Column), level.Decibel})
+		}
+		matrix := map[string]any{"crosspoints": crosspoints}
+		if len(gangs) > 0 {
+			entries := make([]any, 0, len(gangs))
+			for _, gang := range gangs {
+				entries = append(entries, []any{float64(gang.Row), float64(gang.Column), gang.Gang})
+			}
+			matrix["gangs"] = entries
+		}
+		delete(snapshotCue, "level")
+		snapshotCue["levels"] = matrix
+	}
+}
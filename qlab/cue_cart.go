@@ -0,0 +1,147 @@
+package qlab
+
+import (
+	"fmt"
+	"strings"
+)
+
+// cartCueProperties are the grid dimensions of a cart cue
+var cartCueProperties = []cuePropertySpec{
+	{key: "cartRows", kind: cuePropertyInt},
+	{key: "cartColumns", kind: cuePropertyInt},
+}
+
+// CartPosition is a cell of a cart's grid; rows and columns are numbered from 1
+type CartPosition struct {
+	Row    int `json:"row"`
+	Column int `json:"column"`
+}
+
+func (p CartPosition) String() string {
+	return fmt.Sprintf("row %d, column %d", p.Row, p.Column)
+}
+
+// CartGridError reports cart children that don't fit the cart's grid
+type CartGridError struct {
+	Cart     string   // Name or number of the cart
+	Problems []string // One description per child out of place
+}
+
+func (e *CartGridError) Error() string {
+	return fmt.Sprintf("cart %s: %s", e.Cart, strings.Join(e.Problems, "; "))
+}
+
+// parseCartPosition reads a cartPosition given as {"row", "column"} or [row, column]
+func parseCartPosition(value any) (CartPosition, error) {
+	var row, column any
+	switch v := value.(type) {
+	case map[string]any:
+		row, column = v["row"], v["column"]
+	case []any:
+		if len(v) != 2 {
+			return CartPosition{}, fmt.Errorf("cartPosition must have a row and a column, got %v", value)
+		}
+		row, column = v[0], v[1]
+	default:
+		return CartPosition{}, fmt.Errorf("cartPosition must be {row, column} or [row, column], got %T", value)
+	}
+	r, rowOK := toFloat(row)
+	c, columnOK := toFloat(column)
+	if !rowOK || !columnOK || r != float64(int(r)) || c != float64(int(c)) || r < 1 || c < 1 {
+		return CartPosition{}, fmt.Errorf("cartPosition row and column must be whole numbers from 1, got %v", value)
+	}
+	return CartPosition{Row: int(r), Column: int(c)}, nil
+}
+
+// cartChildPosition returns a child's cartPosition, if it has one
+func cartChildPosition(cueData map[string]any) (CartPosition, bool, error) {
+	value, ok := cueData["cartPosition"]
+	if !ok || value == nil {
+		return CartPosition{}, false, nil
+	}
+	position, err := parseCartPosition(value)
+	return position, err == nil, err
+}
+
+// ValidateCartGrid checks that a cart's children fit its grid: every cartPosition must lie
+// within cartRows and cartColumns, no two children may share a cell, and there must be a
+// cell for every child. Dimensions the cart doesn't give are not checked.
+func ValidateCartGrid(cartData map[string]any) error {
+	rows, _ := toFloat(cartData["cartRows"])
+	columns, _ := toFloat(cartData["cartColumns"])
+	children, _ := cartData["cues"].([]any)
+
+	var problems []string
+	occupied := make(map[CartPosition]string)
+	for i, childData := range children {
+		child, ok := childData.(map[string]any)
+		if !ok {
+			continue
+		}
+		label := sourceCueLabel(child, i)
+		position, ok, err := cartChildPosition(child)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", label, err))
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if (rows > 0 && position.Row > int(rows)) || (columns > 0 && position.Column > int(columns)) {
+			problems = append(problems, fmt.Sprintf("%s is at %s, outside the %vx%v grid", label, position, rows, columns))
+			continue
+		}
+		if other, taken := occupied[position]; taken {
+			problems = append(problems, fmt.Sprintf("%s and %s are both at %s", other, label, position))
+			continue
+		}
+		occupied[position] = label
+	}
+	if rows > 0 && columns > 0 && len(children) > int(rows*columns) {
+		problems = append(problems, fmt.Sprintf("%d cues don't fit the %vx%v grid", len(children), rows, columns))
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return &CartGridError{Cart: sourceCueLabel(cartData, -1), Problems: problems}
+}
+
+// sourceCueLabel names a source cue in validation errors by number, name, or index
+func sourceCueLabel(cueData map[string]any, index int) string {
+	if number := formatCueNumber(cueData["number"]); number != "" {
+		return number
+	}
+	if name, _ := cueData["name"].(string); name != "" {
+		return fmt.Sprintf("%q", name)
+	}
+	if index >= 0 {
+		return fmt.Sprintf("cue at index %d", index)
+	}
+	return "(unnamed)"
+}
+
+// placeCartChildren puts the children of a cart into their cells. QLab ignores the index
+// of a move into a cart and drops the cue into the first empty cell, so cells are assigned
+// with cartPosition once every child is in the cart.
+func (q *Workspace) placeCartChildren(cartID string, children []any, childIDs map[int]string) error {
+	for i, childData := range children {
+		child, ok := childData.(map[string]any)
+		if !ok {
+			continue
+		}
+		childID := childIDs[i]
+		position, ok, err := cartChildPosition(child)
+		if err != nil {
+			return fmt.Errorf("cue %s in cart %s: %w", sourceCueLabel(child, i), cartID, err)
+		}
+		if !ok || childID == "" {
+			continue
+		}
+		if err := q.setCuePropertyWithArgs(childID, "cartPosition", int32(position.Row), int32(position.Column)); err != nil {
+			return fmt.Errorf("failed to place cue %s at %s in cart %s: %w", sourceCueLabel(child, i), position, cartID, err)
+		}
+		q.log().Debug("Placed cue in cart", "cue", childID, "cart", cartID, "row", position.Row, "column", position.Column)
+	}
+	return nil
+}
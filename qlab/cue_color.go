@@ -0,0 +1,197 @@
+package qlab
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// CueColor is a color QLab can give a cue, with an approximate swatch that hex and RGB
+// colors in source data are matched against
+type CueColor struct {
+	Name     string
+	R, G, B  uint8
+	Extended bool // Only in QLab 5's extended palette
+}
+
+// colorValueKeys are the color properties enrichment reads that /cueLists doesn't include
+var colorValueKeys = []string{"colorCondition"}
+
+// cueColorPalette is every color QLab can give a cue
+var cueColorPalette = []CueColor{
+	{Name: "red", R: 224, G: 51, B: 46},
+	{Name: "orange", R: 240, G: 138, B: 36},
+	{Name: "green", R: 66, G: 181, B: 73},
+	{Name: "blue", R: 47, G: 111, B: 222},
+	{Name: "purple", R: 142, G: 68, B: 173},
+	{Name: "berry", R: 163, G: 36, B: 94, Extended: true},
+	{Name: "crimson", R: 176, G: 16, B: 44, Extended: true},
+	{Name: "cyan", R: 34, G: 184, B: 207, Extended: true},
+	{Name: "forest", R: 46, G: 107, B: 48, Extended: true},
+	{Name: "indigo", R: 75, G: 59, B: 175, Extended: true},
+	{Name: "lavender", R: 179, G: 157, B: 219, Extended: true},
+	{Name: "midnight", R: 27, G: 42, B: 85, Extended: true},
+	{Name: "olive", R: 128, G: 128, B: 52, Extended: true},
+	{Name: "peach", R: 245, G: 169, B: 138, Extended: true},
+	{Name: "plum", R: 122, G: 59, B: 105, Extended: true},
+	{Name: "sky", R: 110, G: 195, B: 244, Extended: true},
+	{Name: "yellow", R: 242, G: 208, B: 36, Extended: true},
+}
+
+// CueColors returns the names of the colors QLab can give a cue, "none" first, including
+// QLab 5's extended palette when extended is true
+func CueColors(extended bool) []string {
+	names := []string{"none"}
+	for _, color := range cueColorPalette {
+		if extended || !color.Extended {
+			names = append(names, color.Name)
+		}
+	}
+	return names
+}
+
+// NormalizeColor returns the QLab color name for a colorName in source data: a color name
+// in any case, "" or "none" for no color, a hex color such as "#3070e0" or "#37e", an
+// "rgb(48, 112, 224)" string, or a list or map of red, green and blue values from 0 to 255.
+// Hex and RGB colors, and extended colors when extended is false, are mapped to the nearest
+// color in the palette; exact reports whether the color was used as given.
+func NormalizeColor(value any, extended bool) (name string, exact bool, err error) {
+	if value == nil {
+		return "", true, nil
+	}
+	if text, ok := value.(string); ok {
+		text = strings.ToLower(strings.TrimSpace(text))
+		if text == "" || text == "none" {
+			return text, true, nil
+		}
+		for _, color := range cueColorPalette {
+			if color.Name != text {
+				continue
+			}
+			if extended || !color.Extended {
+				return color.Name, true, nil
+			}
+			return nearestCueColor(float64(color.R), float64(color.G), float64(color.B), false), false, nil
+		}
+	}
+
+	rgb, err := parseRGB(value)
+	if err != nil {
+		return "", false, err
+	}
+	return nearestCueColor(rgb[0], rgb[1], rgb[2], extended), false, nil
+}
+
+// parseRGB reads a hex color, an "rgb(r, g, b)" string, or a list or map of red, green and
+// blue values
+func parseRGB(value any) ([3]float64, error) {
+	var rgb [3]float64
+	switch v := value.(type) {
+	case string:
+		text := strings.ToLower(strings.TrimSpace(v))
+		if hex, ok := strings.CutPrefix(text, "#"); ok {
+			if len(hex) == 3 {
+				hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+			}
+			n, err := strconv.ParseUint(hex, 16, 32)
+			if len(hex) != 6 || err != nil {
+				return rgb, fmt.Errorf("invalid hex color %q", v)
+			}
+			return [3]float64{float64(n >> 16), float64(n >> 8 & 0xff), float64(n & 0xff)}, nil
+		}
+		inner, ok := strings.CutPrefix(text, "rgb(")
+		if inner, found := strings.CutSuffix(inner, ")"); ok && found {
+			parts := strings.Split(inner, ",")
+			values := make([]any, len(parts))
+			for i, part := range parts {
+				values[i] = strings.TrimSpace(part)
+			}
+			return rgbComponents(v, values)
+		}
+		return rgb, fmt.Errorf("unknown color %q, expected one of %s or a hex or RGB color", v, strings.Join(CueColors(true), ", "))
+	case []any:
+		return rgbComponents(v, v)
+	case map[string]any:
+		components := []any{v["r"], v["g"], v["b"]}
+		if v["r"] == nil {
+			components = []any{v["red"], v["green"], v["blue"]}
+		}
+		return rgbComponents(v, components)
+	}
+	return rgb, fmt.Errorf("unknown color %v", value)
+}
+
+// rgbComponents reads three values from 0 to 255
+func rgbComponents(value any, components []any) ([3]float64, error) {
+	var rgb [3]float64
+	if len(components) != 3 {
+		return rgb, fmt.Errorf("invalid RGB color %v: expected red, green and blue", value)
+	}
+	for i, component := range components {
+		n, ok := toFloat(component)
+		if text, isText := component.(string); isText {
+			parsed, err := strconv.ParseFloat(text, 64)
+			n, ok = parsed, err == nil
+		}
+		if !ok || n < 0 || n > 255 {
+			return rgb, fmt.Errorf("invalid RGB color %v: components must be 0 to 255", value)
+		}
+		rgb[i] = n
+	}
+	return rgb, nil
+}
+
+// nearestCueColor returns the palette color closest to an RGB color
+func nearestCueColor(r, g, b float64, extended bool) string {
+	nearest, best := "", math.Inf(1)
+	for _, color := range cueColorPalette {
+		if color.Extended && !extended {
+			continue
+		}
+		dr, dg, db := r-float64(color.R), g-float64(color.G), b-float64(color.B)
+		if distance := dr*dr + dg*dg + db*db; distance < best {
+			nearest, best = color.Name, distance
+		}
+	}
+	return nearest
+}
+
+// normalizeColor maps a source colorName to a color the connected QLab has; QLab 4 only has
+// the basic palette
+func (q *Workspace) normalizeColor(value any) (string, bool, error) {
+	return NormalizeColor(value, q.Supports(FeatureExtendedColors))
+}
+
+// colorMapping describes how a source cue's colorName was mapped to a QLab color, e.g.
+// "#3070e0 -> blue", or returns "" when it's used as given
+func (q *Workspace) colorMapping(cue map[string]any) string {
+	value, ok := cue["colorName"]
+	if !ok {
+		return ""
+	}
+	name, exact, err := q.normalizeColor(value)
+	if err != nil || exact {
+		return ""
+	}
+	return fmt.Sprintf("%v -> %s", value, name)
+}
+
+// setCueColor sets a cue's color, normalized for the connected QLab, and its colorCondition.
+// New cues are left without a color when the source has none.
+func (q *Workspace) setCueColor(uniqueID string, cueData map[string]any, created, strict bool) error {
+	name, _, err := q.normalizeColor(cueData["colorName"])
+	if err == nil && name != "" && !(created && name == "none") {
+		err = q.setCueProperty(uniqueID, "colorName", name)
+	}
+	if condition, ok := cueData["colorCondition"].(string); ok && condition != "" && err == nil {
+		err = q.setCueProperty(uniqueID, "colorCondition", condition)
+	}
+	if err != nil {
+		if strict {
+			return fmt.Errorf("failed to set color: %w", err)
+		}
+		q.log().Warnf("Failed to set color for cue %s: %v", uniqueID, err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package qlab
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestNormalizeColor tests color names, hex and RGB colors, and the QLab 4 palette
+func TestNormalizeColor(t *testing.T) {
+	tests := []struct {
+		value    any
+		extended bool
+		expected string
+		exact    bool
+	}{
+		{"Red", true, "red", true},
+		{" none ", true, "none", true},
+		{"", true, "", true},
+		{"berry", true, "berry", true},
+		{"berry", false, "red", false},
+		{"#2f6fde", true, "blue", false},
+		{"#ff0", true, "yellow", false},
+		{"#ff0", false, "orange", false},
+		{"rgb(40, 180, 210)", true, "cyan", false},
+		{[]any{70.0, 180.0, 70.0}, true, "green", false},
+		{map[string]any{"r": 140.0, "g": 70.0, "b": 170.0}, false, "purple", false},
+	}
+	for _, tt := range tests {
+		name, exact, err := NormalizeColor(tt.value, tt.extended)
+		if err != nil || name != tt.expected || exact != tt.exact {
+			t.Errorf("NormalizeColor(%v, %t) = %q, %t, %v; want %q, %t", tt.value, tt.extended, name, exact, err, tt.expected, tt.exact)
+		}
+	}
+
+	for _, value := range []any{"teal", "#12345", "rgb(300, 0, 0)", []any{1.0, 2.0}} {
+		if _, _, err := NormalizeColor(value, true); err == nil {
+			t.Errorf("Expected an error for %v", value)
+		}
+	}
+}
+
+// TestTransmitMapsColors tests that a hex color is sent as the nearest QLab color, shown in
+// the comparison, and compared by the color it maps to
+func TestTransmitMapsColors(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+	workspace.SetCacheEnabled(false)
+	workspace.SetSkipInbox(true)
+	path := filepath.Join(t.TempDir(), "show.json")
+
+	source := func(color string) map[string]any {
+		return map[string]any{"cues": []any{
+			map[string]any{"type": "audio", "number": "1", "name": "Preshow", "colorName": color},
+		}}
+	}
+
+	comparison, err := workspace.TransmitWorkspaceData(path, source("#2f6fde"))
+	if err != nil {
+		t.Fatalf("TransmitWorkspaceData failed: %v", err)
+	}
+	result := comparison.CueResults["1"]
+	if result.ColorMapping != "#2f6fde -> blue" {
+		t.Errorf("Expected the color mapping in the comparison, got %q", result.ColorMapping)
+	}
+	uniqueID, _ := workspace.lookupCueNumber("1")
+	if cue := mockServer.GetCue(uniqueID); cue == nil || cue.Properties["colorName"] != "blue" {
+		t.Fatalf("Expected the cue colored blue, got %+v", cue)
+	}
+
+	comparison, err = workspace.PerformThreeWayComparison(path, source("Blue"))
+	if err != nil {
+		t.Fatalf("PerformThreeWayComparison failed: %v", err)
+	}
+	if result := comparison.CueResults["1"]; result.Action != "skip" {
+		t.Errorf("Expected Blue to match blue, got %+v", result)
+	}
+}
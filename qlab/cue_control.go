@@ -0,0 +1,73 @@
+package qlab
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// devampCueProperties are the Devamp cue settings for what happens when the target's
+// current slice ends
+var devampCueProperties = []cuePropertySpec{
+	{key: "startNextCueWhenSliceEnds", kind: cuePropertyBool},
+	{key: "stopTargetWhenSliceEnds", kind: cuePropertyBool},
+}
+
+// setCueTarget sets the target of a cue from its cueTargetNumber, falling back to
+// cueTargetID when the number can't be set. When strict, a number that can't be set and
+// has no fallback is an error rather than a warning.
+func (q *Workspace) setCueTarget(uniqueID string, cueData map[string]any, strict bool) error {
+	if targetNumber, ok := cueData["cueTargetNumber"].(string); ok && targetNumber != "" {
+		if err := q.setCueProperty(uniqueID, "cueTargetNumber", targetNumber); err != nil {
+			// Fallback to cueTargetID if we have it
+			targetID, _ := cueData["cueTargetID"].(string)
+			if targetID == "" && strict {
+				return err
+			}
+			q.log().Warnf("Failed to set cueTargetNumber %s, trying cueTargetID fallback: %v", targetNumber, err)
+			if targetID != "" {
+				if err := q.setCueProperty(uniqueID, "cueTargetID", targetID); err != nil {
+					return fmt.Errorf("failed to set cue target: %v", err)
+				}
+			}
+		}
+	} else if targetID, ok := cueData["cueTargetID"].(string); ok && targetID != "" {
+		// Only cueTargetID is available
+		if err := q.setCueProperty(uniqueID, "cueTargetID", targetID); err != nil {
+			return fmt.Errorf("failed to set cue target: %v", err)
+		}
+	}
+	return nil
+}
+
+// setWaitCueProperties sets the duration of a wait cue, given as a number or a string
+func (q *Workspace) setWaitCueProperties(uniqueID string, cueData map[string]any, strict bool) error {
+	duration, ok := toFloat(cueData["duration"])
+	if !ok || duration <= 0 {
+		return nil
+	}
+	if err := q.setCueProperty(uniqueID, "duration", strconv.FormatFloat(duration, 'g', -1, 64)); err != nil {
+		if strict {
+			return fmt.Errorf("failed to set wait duration: %w", err)
+		}
+		q.log().Warnf("Failed to set duration for wait cue %s: %v", uniqueID, err)
+	}
+	return nil
+}
+
+// setMemoCueProperties sets the notes of a memo cue, which has nothing else to set; its
+// color is set with every other cue's
+func (q *Workspace) setMemoCueProperties(uniqueID string, cueData map[string]any, strict bool) error {
+	for _, property := range []string{"notes"} {
+		value, ok := cueData[property].(string)
+		if !ok || value == "" {
+			continue
+		}
+		if err := q.setCueProperty(uniqueID, property, value); err != nil {
+			if strict {
+				return fmt.Errorf("failed to set %s: %w", property, err)
+			}
+			q.log().Warnf("Failed to set %s for memo cue %s: %v", property, uniqueID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package qlab
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// extraCueProperties are cue data keys the library reads that have no Cue field
+var extraCueProperties = []string{"level", "fillMode", "cartPosition", "audioOutputPatchName", "audioOutputPatchID", "lightCommands", "prune"}
+
+var (
+	knownCuePropertiesOnce sync.Once
+	knownCueProperties     map[string]bool
+)
+
+// isKnownCueProperty reports whether the library reads or writes a cue data key.
+// Known keys are the Cue JSON fields, the type-specific property tables, and extraCueProperties.
+func isKnownCueProperty(key string) bool {
+	knownCuePropertiesOnce.Do(func() {
+		knownCueProperties = make(map[string]bool)
+		cueType := reflect.TypeOf(Cue{})
+		for i := 0; i < cueType.NumField(); i++ {
+			name, _, _ := strings.Cut(cueType.Field(i).Tag.Get("json"), ",")
+			if name != "" && name != "-" {
+				knownCueProperties[name] = true
+			}
+		}
+		for _, specs := range [][]cuePropertySpec{midiCueProperties, networkCueProperties, midiFileCueProperties, videoCueProperties, cartCueProperties, lightCueProperties, triggerCueProperties, devampCueProperties} {
+			for _, spec := range specs {
+				knownCueProperties[spec.key] = true
+			}
+		}
+		for _, key := range extraCueProperties {
+			knownCueProperties[key] = true
+		}
+	})
+	return knownCueProperties[key]
+}
+
+// UnknownCueProperties returns the properties of cue data that the library doesn't
+// recognize, or nil if there are none. Child cues are not included.
+func UnknownCueProperties(cueData map[string]any) map[string]any {
+	var unknown map[string]any
+	for key, value := range cueData {
+		if isKnownCueProperty(key) {
+			continue
+		}
+		if unknown == nil {
+			unknown = make(map[string]any)
+		}
+		unknown[key] = value
+	}
+	return unknown
+}
+
+// MarshalJSON encodes the cue with its Extra properties alongside the known fields.
+// Extra keys never override a known field.
+func (c Cue) MarshalJSON() ([]byte, error) {
+	type plainCue Cue
+	data, err := json.Marshal(plainCue(c))
+	if err != nil || len(c.Extra) == 0 {
+		return data, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for key, value := range c.Extra {
+		if isKnownCueProperty(key) {
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		fields[key] = encoded
+	}
+	return json.Marshal(fields)
+}
+
+// UnmarshalJSON decodes the known fields and collects every other key into Extra
+func (c *Cue) UnmarshalJSON(data []byte) error {
+	type plainCue Cue
+	var plain plainCue
+	decodeErr := json.Unmarshal(data, &plain)
+	var typeErr *json.UnmarshalTypeError
+	if decodeErr != nil && !errors.As(decodeErr, &typeErr) {
+		return decodeErr
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*c = Cue(plain)
+	c.Extra = UnknownCueProperties(raw)
+
+	// Type errors leave the offending field zero; report them like encoding/json does
+	return decodeErr
+}
+
+// SetCompareUnknownProperties sets whether scope comparisons report differences in
+// properties the library doesn't recognize. They are ignored by default, since QLab
+// never stores them and every such property would otherwise show as a change.
+func (q *Workspace) SetCompareUnknownProperties(compare bool) {
+	q.compareUnknown = compare
+}
+
+// carryUnknownProperties copies the unknown properties of source cues onto the matching
+// cues of a QLab snapshot, which QLab itself never reports
+func (q *Workspace) carryUnknownProperties(source, snapshot map[string]any) {
+	snapshotCues := q.indexCuesFromWorkspace(snapshot)
+	for key, sourceCue := range q.indexCuesFromWorkspace(source) {
+		snapshotCue, ok := snapshotCues[key]
+		if !ok {
+			continue
+		}
+		for property, value := range UnknownCueProperties(sourceCue) {
+			if _, exists := snapshotCue[property]; !exists {
+				snapshotCue[property] = value
+			}
+		}
+	}
+}
+
+// cueLabelPattern matches CUE labels that need no quoting
+var cueLabelPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// writeExtraProperties writes unknown properties in key order. JSON values are valid CUE.
+func writeExtraProperties(builder *strings.Builder, extra map[string]any, indentStr string) {
+	for _, key := range slices.Sorted(maps.Keys(extra)) {
+		if isKnownCueProperty(key) {
+			continue
+		}
+		value, err := json.Marshal(extra[key])
+		if err != nil {
+			continue
+		}
+		label := key
+		if !cueLabelPattern.MatchString(key) {
+			label = strconv.Quote(key)
+		}
+		builder.WriteString(indentStr + "\t" + label + ": " + string(value) + "\n")
+	}
+}
@@ -0,0 +1,253 @@
+package qlab
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// fadeEnableProperties are the fade cue checkboxes that select which geometry parameters fade
+var fadeEnableProperties = []string{"doOpacity", "doTranslation", "doScale", "doRotation"}
+
+// FadeLevel is one audio level target in a fade cue's level matrix.
+// Row 0 is the main (input) row and column 0 is the main output; Row 0, Column 0 is the master level.
+type FadeLevel struct {
+	Row     int
+	Column  int
+	Decibel float64
+}
+
+// parseFadeLevels reads level targets from cue data. "level" is shorthand for the master
+// level; "levels" is a list of [row, column, dB] triples or {"row", "column", "db"} objects,
+// or a level matrix object as read by parseLevelMatrix.
+func parseFadeLevels(cueData map[string]any) []FadeLevel {
+	var levels []FadeLevel
+
+	if level, ok := toFloat(cueData["level"]); ok {
+		levels = append(levels, FadeLevel{Row: 0, Column: 0, Decibel: level})
+	}
+
+	switch v := cueData["levels"].(type) {
+	case []any:
+		levels = append(levels, parseLevelEntries(v)...)
+	case map[string]any:
+		levels = append(levels, parseLevelMatrix(v)...)
+	}
+
+	return levels
+}
+
+// parseLevelEntries reads a list of [row, column, dB] triples or {"row", "column", "db"}
+// objects, skipping malformed entries
+func parseLevelEntries(entries []any) []FadeLevel {
+	var levels []FadeLevel
+	for _, entry := range entries {
+		switch v := entry.(type) {
+		case []any:
+			if len(v) != 3 {
+				continue
+			}
+			row, rowOK := toFloat(v[0])
+			column, columnOK := toFloat(v[1])
+			db, dbOK := toFloat(v[2])
+			if rowOK && columnOK && dbOK {
+				levels = append(levels, FadeLevel{Row: int(row), Column: int(column), Decibel: db})
+			}
+		case map[string]any:
+			row, rowOK := toFloat(v["row"])
+			column, columnOK := toFloat(v["column"])
+			db, dbOK := toFloat(v["db"])
+			if rowOK && columnOK && dbOK {
+				levels = append(levels, FadeLevel{Row: int(row), Column: int(column), Decibel: db})
+			}
+		}
+	}
+	return levels
+}
+
+// toFloat converts a numeric cue data value (JSON number, int, or numeric string) to float64
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// setFadeCueProperties sets the fade-specific properties of a fade cue: geometry enables and
+// targets, audio level targets, duration, and stopTargetWhenDone. Enable checkboxes only log
+// warnings on failure; other failures are returned when strict and logged otherwise.
+func (q *Workspace) setFadeCueProperties(uniqueID string, cueData map[string]any, strict bool) error {
+	fail := func(what string, err error) error {
+		if strict {
+			return fmt.Errorf("failed to set %s: %v", what, err)
+		}
+		q.log().Warnf("Failed to set %s for fade cue %s: %v", what, uniqueID, err)
+		return nil
+	}
+
+	// Set fade geometry parameter enables
+	for _, property := range fadeEnableProperties {
+		if enabled, ok := cueData[property].(bool); ok && enabled {
+			if err := q.setCueProperty(uniqueID, property, "1"); err != nil {
+				q.log().Warnf("Failed to set %s for fade cue %s: %v", property, uniqueID, err)
+			}
+		}
+	}
+
+	// Duration may be a number or a string, as written by WriteCueFile and returned by ReceiveWorkspaceData
+	if duration, ok := toFloat(cueData["duration"]); ok && duration > 0 {
+		if err := q.setCueProperty(uniqueID, "duration", strconv.FormatFloat(duration, 'g', -1, 64)); err != nil {
+			if err := fail("duration", err); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Set geometry targets. A fade to zero opacity is only meaningful when opacity fading is enabled.
+	doOpacity, _ := cueData["doOpacity"].(bool)
+	if opacity, ok := cueData["opacity"].(float64); ok && (opacity > 0 || doOpacity) {
+		if err := q.setCueProperty(uniqueID, "opacity", fmt.Sprintf("%g", opacity)); err != nil {
+			if err := fail("opacity", err); err != nil {
+				return err
+			}
+		}
+	}
+	if translation, ok := cueData["translation"].([]any); ok && len(translation) == 2 {
+		x, _ := translation[0].(float64)
+		y, _ := translation[1].(float64)
+		if err := q.setCuePropertyWithArgs(uniqueID, "translation", float32(x), float32(y)); err != nil {
+			if err := fail("translation", err); err != nil {
+				return err
+			}
+		}
+	}
+	if scale, ok := cueData["scale"].([]any); ok && len(scale) == 2 {
+		x, _ := scale[0].(float64)
+		y, _ := scale[1].(float64)
+		if err := q.setCuePropertyWithArgs(uniqueID, "scale", float32(x), float32(y)); err != nil {
+			if err := fail("scale", err); err != nil {
+				return err
+			}
+		}
+	}
+	if rotation, ok := cueData["rotation"].(float64); ok && rotation != 0 {
+		if err := q.setCueProperty(uniqueID, "rotation", fmt.Sprintf("%g", rotation)); err != nil {
+			if err := fail("rotation", err); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Set audio level targets: /level {row} {column} {decibels}
+	for _, level := range parseFadeLevels(cueData) {
+		if err := q.setCuePropertyWithArgs(uniqueID, "level", int32(level.Row), int32(level.Column), float32(level.Decibel)); err != nil {
+			if err := fail(fmt.Sprintf("level %d/%d", level.Row, level.Column), err); err != nil {
+				return err
+			}
+		}
+	}
+
+	if stopTarget, ok := cueData["stopTargetWhenDone"].(bool); ok {
+		value := "0"
+		if stopTarget {
+			value = "1"
+		}
+		if err := q.setCueProperty(uniqueID, "stopTargetWhenDone", value); err != nil {
+			if err := fail("stopTargetWhenDone", err); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// fadeValueKeys are the fade properties read with valuesForKeys
+var fadeValueKeys = slices.Concat([]string{"duration", "stopTargetWhenDone"}, fadeEnableProperties, geometryValueKeys)
+
+// enrichFadeCue adds the fade-specific properties that /cueLists does not include from
+// values read with fadeValueKeys, then queries the master level, which needs arguments
+func (q *Workspace) enrichFadeCue(cue map[string]any, uniqueID string, values map[string]any) {
+	if duration, ok := cueValue(values, "duration"); ok {
+		if d, ok := toFloat(duration); ok {
+			cue["duration"] = strconv.FormatFloat(d, 'g', -1, 64)
+		}
+	}
+
+	for _, property := range append(fadeEnableProperties, "stopTargetWhenDone") {
+		if value, ok := cueValue(values, property); ok {
+			cue[property] = toBool(value)
+		}
+	}
+
+	enrichGeometry(cue, values)
+	q.enrichMasterLevel(cue, uniqueID)
+}
+
+// enrichMasterLevel queries a cue's master level, /level 0 0, and records it as its levels
+func (q *Workspace) enrichMasterLevel(cue map[string]any, uniqueID string) {
+	if level, ok := q.queryCueValue(uniqueID, "level", int32(0), int32(0)); ok {
+		if db, ok := toFloat(level); ok {
+			cue["levels"] = []any{[]any{float64(0), float64(0), db}}
+		}
+	}
+}
+
+// queryCueValue reads a cue property, passing any query arguments, and returns the reply data
+func (q *Workspace) queryCueValue(uniqueID, property string, args ...any) (any, bool) {
+	address := q.addressBuilder.BuildCuePropertyAddress(uniqueID, property)
+	reply := q.sendWithRetry(address, "", args)
+	if checkReplyStatus(reply) != nil || len(reply) == 0 {
+		return nil, false
+	}
+	value, ok := replyDataValue(reply)
+	if !ok || value == nil || value == "" {
+		return nil, false
+	}
+	return value, true
+}
+
+// toBool converts a QLab boolean reply (bool, number, or "0"/"1" string) to bool
+func toBool(value any) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "1" || v == "true"
+	default:
+		f, ok := toFloat(v)
+		return ok && f != 0
+	}
+}
+
+// toPair converts a QLab [x, y] array or {"x", "y"} object reply to a two-element slice
+func toPair(value any) []any {
+	switch v := value.(type) {
+	case []any:
+		if len(v) == 2 {
+			x, xOK := toFloat(v[0])
+			y, yOK := toFloat(v[1])
+			if xOK && yOK {
+				return []any{x, y}
+			}
+		}
+	case map[string]any:
+		x, xOK := toFloat(v["x"])
+		y, yOK := toFloat(v["y"])
+		if xOK && yOK {
+			return []any{x, y}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package qlab
+
+import (
+	"strconv"
+	"strings"
+)
+
+// continueModes are the names accepted for continueMode
+var continueModes = map[string]int{
+	"none":            ContinueModeNone,
+	"do not continue": ContinueModeNone,
+	"auto-continue":   ContinueModeAutoContinue,
+	"auto continue":   ContinueModeAutoContinue,
+	"autocontinue":    ContinueModeAutoContinue,
+	"auto-follow":     ContinueModeAutoFollow,
+	"auto follow":     ContinueModeAutoFollow,
+	"autofollow":      ContinueModeAutoFollow,
+}
+
+// followCueProperties are the waits around a cue and how it continues to the next one:
+// the pre-wait before it starts, the continue mode, and the post-wait before an
+// auto-continue fires
+var followCueProperties = []cuePropertySpec{
+	{key: "preWait", kind: cuePropertyFloat},
+	{key: "postWait", kind: cuePropertyFloat},
+	{key: "continueMode", kind: cuePropertyInt, names: continueModes},
+}
+
+// followValueKeys are the follow properties read with valuesForKeys
+var followValueKeys = cuePropertyKeys(followCueProperties)
+
+// setFollowProperties sets the waits and continue mode given in cue data. The
+// post-wait goes before the continue mode so an auto-continue never fires on the old one. Failures are
+// returned when strict and logged otherwise.
+func (q *Workspace) setFollowProperties(uniqueID string, cueData map[string]any, strict bool) error {
+	return q.setMappedCueProperties(uniqueID, cueData, followCueProperties, strict)
+}
+
+// enrichFollow adds a continue mode other than do not continue and nonzero waits from
+// values read with followValueKeys
+func enrichFollow(cue map[string]any, values map[string]any) {
+	for _, key := range followValueKeys {
+		if value, ok := cueValue(values, key); ok {
+			if f, ok := toFloat(value); ok && f != 0 {
+				cue[key] = f
+			}
+		}
+	}
+}
+
+// normalizeContinueMode returns a continue mode given by number or name as its number,
+// or the value unchanged if it is neither
+func normalizeContinueMode(value string) string {
+	if mode, ok := continueModes[strings.ToLower(strings.TrimSpace(value))]; ok {
+		return strconv.Itoa(mode)
+	}
+	return value
+}
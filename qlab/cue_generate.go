@@ -5,53 +5,86 @@ import (
 	"fmt"
 
 	"github.com/zenibako/qlab-golang/templates"
-
-	"github.com/charmbracelet/log"
 )
 
 // CueGenerator handles the generation of QLab cues via OSC
 type CueGenerator struct {
 	workspace *Workspace
+	library   *templates.TemplateLibrary
+	generated map[string]string // idempotency key -> uniqueID of the cue generated for it
 }
 
 // NewCueGenerator creates a new cue generator
 func NewCueGenerator(workspace *Workspace) *CueGenerator {
 	return &CueGenerator{
 		workspace: workspace,
+		generated: make(map[string]string),
 	}
 }
 
-// GenerateCues creates cues in QLab based on a template
+// GenerateCues creates cues in QLab based on a template. Cues targeting others by
+// cueTargetNumber or cueTargetName get their targets once all of them exist.
 func (cg *CueGenerator) GenerateCues(request templates.CueGenerationRequest) templates.CueGenerationResult {
-	result := templates.CueGenerationResult{
-		Success:     true,
-		CuesCreated: []templates.CreatedCue{},
-		Errors:      []string{},
-	}
+	cg.workspace.opMu.Lock()
+	defer cg.workspace.opMu.Unlock()
+	return cg.generateBatch([]templates.CueGenerationRequest{request}).Results[0]
+}
+
+// SetTemplateLibrary sets the library GenerateFromLibrary takes templates from
+func (cg *CueGenerator) SetTemplateLibrary(library *templates.TemplateLibrary) {
+	cg.library = library
+}
 
-	// Create the cue(s) from the template
-	cuesCreated, err := cg.createCueFromTemplate(request.Template, request.CueNumber, request.ParentID)
+// GenerateFromLibrary creates cues from a named template in the generator's library,
+// substituting params into it. The cue number and parent are the templates.ParamCueNumber
+// and templates.ParamParentID parameters.
+func (cg *CueGenerator) GenerateFromLibrary(name string, params map[string]string) templates.CueGenerationResult {
+	if cg.library == nil {
+		return templates.CueGenerationResult{Errors: []string{"no template library set"}}
+	}
+	request, err := cg.library.Request(name, params)
 	if err != nil {
-		result.Success = false
-		result.Errors = append(result.Errors, err.Error())
-		return result
+		return templates.CueGenerationResult{Errors: []string{err.Error()}}
 	}
-
-	result.CuesCreated = cuesCreated
-	return result
+	return cg.GenerateCues(request)
 }
 
-// createCueFromTemplate creates a cue and its children from a template
-func (cg *CueGenerator) createCueFromTemplate(template templates.CueTemplate, cueNumber string, parentID string) ([]templates.CreatedCue, error) {
+// createCueFromTemplate creates a cue and its children from a template, recording their
+// numbers, names, and targets in mapping
+func (cg *CueGenerator) createCueFromTemplate(template templates.CueTemplate, cueNumber string, parentID string, mapping *CueMapping) ([]templates.CreatedCue, error) {
 	var allCreated []templates.CreatedCue
 
 	// Create the main cue
+	created, err := cg.createTemplateCue(template, cueNumber, parentID, mapping)
+	if err != nil {
+		return nil, err
+	}
+	allCreated = append(allCreated, created)
+
+	// Create child cues if this is a group
+	if len(template.Children) > 0 {
+		for i, childTemplate := range template.Children {
+			childCueNumber := childCueNumber(cueNumber, i)
+			childCues, err := cg.createCueFromTemplate(childTemplate, childCueNumber, created.UniqueID, mapping)
+			if err != nil {
+				return allCreated, fmt.Errorf("failed to create child cue %d: %w", i, err)
+			}
+			allCreated = append(allCreated, childCues...)
+		}
+	}
+
+	return allCreated, nil
+}
+
+// createTemplateCue creates the cue of a template, without its children, recording its
+// number, name, and target in mapping
+func (cg *CueGenerator) createTemplateCue(template templates.CueTemplate, cueNumber string, parentID string, mapping *CueMapping) (templates.CreatedCue, error) {
 	uniqueID, err := cg.createCue(template.Type, cueNumber, parentID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create %s cue: %w", template.Type, err)
+		return templates.CreatedCue{}, fmt.Errorf("failed to create %s cue: %w", template.Type, err)
 	}
 
-	log.Info("Created cue", "type", template.Type, "uniqueID", uniqueID, "cueNumber", cueNumber)
+	cg.workspace.log().Info("Created cue", "type", template.Type, "uniqueID", uniqueID, "cueNumber", cueNumber)
 
 	created := templates.CreatedCue{
 		UniqueID:  uniqueID,
@@ -63,24 +96,28 @@ func (cg *CueGenerator) createCueFromTemplate(template templates.CueTemplate, cu
 
 	// Set cue properties
 	if err := cg.setCueProperties(uniqueID, template.Name, template.Properties); err != nil {
-		return nil, fmt.Errorf("failed to set properties for cue %s: %w", uniqueID, err)
+		return templates.CreatedCue{}, fmt.Errorf("failed to set properties for cue %s: %w", uniqueID, err)
 	}
 
-	allCreated = append(allCreated, created)
+	mapping.recordCue(cueNumber, template.Name, uniqueID)
+	mapping.recordTarget(uniqueID, templateCueData(template))
+	return created, nil
+}
 
-	// Create child cues if this is a group
-	if len(template.Children) > 0 {
-		for i, childTemplate := range template.Children {
-			childCueNumber := fmt.Sprintf("%s.%d", cueNumber, i+1)
-			childCues, err := cg.createCueFromTemplate(childTemplate, childCueNumber, uniqueID)
-			if err != nil {
-				return allCreated, fmt.Errorf("failed to create child cue %d: %w", i, err)
-			}
-			allCreated = append(allCreated, childCues...)
-		}
-	}
+// childCueNumber returns the number of a template's i-th child cue
+func childCueNumber(parentNumber string, i int) string {
+	return fmt.Sprintf("%s.%d", parentNumber, i+1)
+}
 
-	return allCreated, nil
+// templateCueData returns a template's type and properties in the form cue data is
+// recorded from
+func templateCueData(template templates.CueTemplate) map[string]any {
+	cueData := make(map[string]any, len(template.Properties)+1)
+	for key, value := range template.Properties {
+		cueData[key] = value
+	}
+	cueData["type"] = template.Type
+	return cueData
 }
 
 // createCue creates a single cue in QLab and returns its unique ID
@@ -89,6 +126,7 @@ func (cg *CueGenerator) createCue(cueType string, cueNumber string, parentID str
 	address := cg.workspace.GetAddress("/new")
 
 	// Build the input string - parent ID if provided
+	cueType = cg.workspace.newCueType(cueType)
 	input := cueType
 	if parentID != "" {
 		input = fmt.Sprintf("%s %s", cueType, parentID)
@@ -103,12 +141,12 @@ func (cg *CueGenerator) createCue(cueType string, cueNumber string, parentID str
 		return "", fmt.Errorf("failed to extract unique ID from result: %v", result)
 	}
 
-	log.Info("Created cue via OSC", "type", cueType, "uniqueID", uniqueID)
+	cg.workspace.log().Info("Created cue via OSC", "type", cueType, "uniqueID", uniqueID)
 
 	// Set the cue number if provided and different from default
 	if cueNumber != "" {
 		if err := cg.setCueNumber(uniqueID, cueNumber); err != nil {
-			log.Warn("Failed to set cue number", "uniqueID", uniqueID, "cueNumber", cueNumber, "error", err)
+			cg.workspace.log().Warn("Failed to set cue number", "uniqueID", uniqueID, "cueNumber", cueNumber, "error", err)
 			// Don't fail completely if we can't set the number
 		}
 	}
@@ -128,14 +166,19 @@ func (cg *CueGenerator) setCueProperties(uniqueID string, name string, propertie
 	// Set other properties
 	for key, value := range properties {
 		if key == "mode" && value != nil {
-			// Special handling for group mode
-			if err := cg.setCueProperty(uniqueID, "mode", value); err != nil {
-				log.Warn("Failed to set property", "property", key, "error", err)
+			// Special handling for group mode, which may be given by name
+			mode, err := ParseGroupMode(value)
+			if err != nil {
+				cg.workspace.log().Warn("Failed to set property", "property", key, "error", err)
+				continue
+			}
+			if err := cg.setCueProperty(uniqueID, "mode", int(mode)); err != nil {
+				cg.workspace.log().Warn("Failed to set property", "property", key, "error", err)
 			}
 		} else if key == "duration" && value != nil {
 			// Set duration for fade cues, etc.
 			if err := cg.setCueProperty(uniqueID, "duration", value); err != nil {
-				log.Warn("Failed to set duration", "error", err)
+				cg.workspace.log().Warn("Failed to set duration", "error", err)
 			}
 		}
 		// Add more property handlers as needed
@@ -0,0 +1,163 @@
+package qlab
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/zenibako/qlab-golang/templates"
+)
+
+// GenerateBatch creates the cues of several requests together. A request whose cues target,
+// by cueTargetNumber or cueTargetName, cues another request creates is created after that
+// request; requests that target each other in a cycle are created in the order given. As in
+// a transmit, targets are set in a second pass once every request's cues exist. A request
+// targeting one that failed isn't created. A request with an idempotency key updates the
+// cues an earlier generation with that key created, if any. Results holds each request's
+// status in the order the requests were given, and Success is false if any failed.
+// Generation waits for a running transmit or batch on the workspace to finish.
+func (cg *CueGenerator) GenerateBatch(requests []templates.CueGenerationRequest) templates.BatchGenerationResult {
+	cg.workspace.opMu.Lock()
+	defer cg.workspace.opMu.Unlock()
+	return cg.generateBatch(requests)
+}
+
+// generateBatch creates the cues of several requests; the workspace's opMu must be held
+func (cg *CueGenerator) generateBatch(requests []templates.CueGenerationRequest) templates.BatchGenerationResult {
+	batch := templates.BatchGenerationResult{
+		Success:     true,
+		CuesCreated: []templates.CreatedCue{},
+		Results:     make([]templates.CueGenerationResult, len(requests)),
+	}
+	for i := range batch.Results {
+		batch.Results[i] = templates.CueGenerationResult{
+			Success:     true,
+			CuesCreated: []templates.CreatedCue{},
+			Errors:      []string{},
+		}
+	}
+	fail := func(i int, err error) {
+		batch.Success = false
+		batch.Results[i].Success = false
+		batch.Results[i].Errors = append(batch.Results[i].Errors, err.Error())
+	}
+
+	order, dependencies := generationOrder(requests)
+
+	// First pass: create every cue, queueing targets per request
+	mapping := &CueMapping{NumberToID: make(map[string]string)}
+	targets := make([][]CueTarget, len(requests))
+	for _, i := range order {
+		request := requests[i]
+		if strings.TrimSpace(request.Template.Type) == "" {
+			fail(i, fmt.Errorf("request %d: template has no type", i))
+			continue
+		}
+		if failed := slices.IndexFunc(dependencies[i], func(j int) bool { return !batch.Results[j].Success }); failed >= 0 {
+			fail(i, fmt.Errorf("request %d: targets cues of request %d, which failed", i, dependencies[i][failed]))
+			continue
+		}
+
+		queued := len(mapping.CuesWithTargets)
+		var created []templates.CreatedCue
+		var err error
+		if strings.TrimSpace(request.IdempotencyKey) != "" {
+			created, err = cg.generateIdempotent(request.Template, strings.TrimSpace(request.IdempotencyKey), request.CueNumber, request.ParentID, mapping)
+		} else {
+			created, err = cg.createCueFromTemplate(request.Template, request.CueNumber, request.ParentID, mapping)
+		}
+		batch.Results[i].CuesCreated = append(batch.Results[i].CuesCreated, created...)
+		batch.CuesCreated = append(batch.CuesCreated, created...)
+		targets[i] = mapping.CuesWithTargets[queued:]
+		if err != nil {
+			fail(i, err)
+		}
+	}
+
+	// Second pass: set targets now that every cue they could reference exists
+	for _, i := range order {
+		if !batch.Results[i].Success || len(targets[i]) == 0 {
+			continue
+		}
+		requestTargets := &CueMapping{NumberToID: mapping.NumberToID, NameToCues: mapping.NameToCues, CuesWithTargets: targets[i]}
+		if err := cg.workspace.setCueTargets(requestTargets); err != nil {
+			fail(i, fmt.Errorf("request %d: %w", i, err))
+		}
+	}
+
+	// The cached cue lists no longer show every cue
+	if len(batch.CuesCreated) > 0 {
+		cg.workspace.InvalidateCueCache()
+	}
+	cg.workspace.log().Info("Generated cue batch", "requests", len(requests), "cues", len(batch.CuesCreated), "success", batch.Success)
+	return batch
+}
+
+// generationOrder orders requests so each comes after the requests creating the cues it
+// targets, keeping the given order otherwise, and returns each request's dependencies.
+// Requests left in a cycle follow in the order given.
+func generationOrder(requests []templates.CueGenerationRequest) ([]int, [][]int) {
+	numbers := make(map[string][]int)
+	names := make(map[string][]int)
+	for i, request := range requests {
+		walkTemplate(request.Template, request.CueNumber, func(cue templates.CueTemplate, number string) {
+			if number != "" {
+				numbers[number] = append(numbers[number], i)
+			}
+			if cue.Name != "" {
+				names[cue.Name] = append(names[cue.Name], i)
+			}
+		})
+	}
+
+	dependencies := make([][]int, len(requests))
+	for i, request := range requests {
+		walkTemplate(request.Template, request.CueNumber, func(cue templates.CueTemplate, _ string) {
+			var creators []int
+			if number, ok := cue.Properties["cueTargetNumber"].(string); ok && number != "" {
+				creators = numbers[number]
+			} else if name, ok := cue.Properties["cueTargetName"].(string); ok && name != "" {
+				creators = names[name]
+			}
+			for _, j := range creators {
+				if j != i && !slices.Contains(dependencies[i], j) {
+					dependencies[i] = append(dependencies[i], j)
+				}
+			}
+		})
+	}
+
+	order := make([]int, 0, len(requests))
+	placed := make([]bool, len(requests))
+	for len(order) < len(requests) {
+		progressed := false
+		for i := range requests {
+			if placed[i] || slices.ContainsFunc(dependencies[i], func(j int) bool { return !placed[j] }) {
+				continue
+			}
+			order = append(order, i)
+			placed[i] = true
+			progressed = true
+			break
+		}
+		if !progressed {
+			// Only cycles are left
+			for i := range requests {
+				if !placed[i] {
+					order = append(order, i)
+					placed[i] = true
+				}
+			}
+		}
+	}
+	return order, dependencies
+}
+
+// walkTemplate calls visit with a template and each of its descendants, and the number
+// each is created with
+func walkTemplate(template templates.CueTemplate, cueNumber string, visit func(templates.CueTemplate, string)) {
+	visit(template, cueNumber)
+	for i, child := range template.Children {
+		walkTemplate(child, childCueNumber(cueNumber, i), visit)
+	}
+}
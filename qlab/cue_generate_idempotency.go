@@ -0,0 +1,206 @@
+package qlab
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zenibako/qlab-golang/templates"
+)
+
+// generateIdempotent creates the cues of a template whose cue is generated under an
+// idempotency key, or updates the cues an earlier generation with the same key created.
+// Each child is generated under the key with its position appended, as in "scene.1".
+// Children an earlier generation created past the template's last child are deleted.
+func (cg *CueGenerator) generateIdempotent(template templates.CueTemplate, key, cueNumber, parentID string, mapping *CueMapping) ([]templates.CreatedCue, error) {
+	return cg.generateKeyed(cg.indexGeneratedCues(), template, key, cueNumber, parentID, mapping)
+}
+
+// generateKeyed is generateIdempotent with the workspace's cues read once for the whole tree
+func (cg *CueGenerator) generateKeyed(existing generationIndex, template templates.CueTemplate, key, cueNumber, parentID string, mapping *CueMapping) ([]templates.CreatedCue, error) {
+	var cue templates.CreatedCue
+	var err error
+	if uniqueID := cg.findGenerated(existing, key, cueNumber, template.Name); uniqueID != "" {
+		cg.workspace.log().Info("Updating generated cue", "key", key, "uniqueID", uniqueID)
+		cue, err = cg.updateTemplateCue(template, uniqueID, cueNumber, parentID, mapping)
+	} else if cue, err = cg.createTemplateCue(template, cueNumber, parentID, mapping); err == nil {
+		if err = cg.stampIdempotencyKey(cue.UniqueID, key); err != nil {
+			err = fmt.Errorf("failed to stamp idempotency key %q on cue %s: %w", key, cue.UniqueID, err)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	cg.generated[key] = cue.UniqueID
+
+	allGenerated := []templates.CreatedCue{cue}
+	for i, childTemplate := range template.Children {
+		childCues, err := cg.generateKeyed(existing, childTemplate, fmt.Sprintf("%s.%d", key, i+1), childCueNumber(cueNumber, i), cue.UniqueID, mapping)
+		allGenerated = append(allGenerated, childCues...)
+		if err != nil {
+			return allGenerated, fmt.Errorf("failed to generate child cue %d: %w", i, err)
+		}
+	}
+	if cue.Updated {
+		if err := cg.deleteStaleChildren(existing, key, cueNumber, len(template.Children)); err != nil {
+			return allGenerated, err
+		}
+	}
+	return allGenerated, nil
+}
+
+// deleteStaleChildren deletes the children an earlier generation under a key created past
+// the template's last child, stopping at the first position with none
+func (cg *CueGenerator) deleteStaleChildren(existing generationIndex, key, cueNumber string, children int) error {
+	for i := children; ; i++ {
+		childKey := fmt.Sprintf("%s.%d", key, i+1)
+		uniqueID := cg.findGenerated(existing, childKey, childCueNumber(cueNumber, i), "")
+		if uniqueID == "" {
+			return nil
+		}
+		if err := cg.workspace.deleteCue(uniqueID); err != nil {
+			return fmt.Errorf("failed to delete stale generated cue %s: %w", uniqueID, err)
+		}
+		cg.workspace.log().Info("Deleted stale generated cue", "key", childKey, "uniqueID", uniqueID)
+		delete(cg.generated, childKey)
+	}
+}
+
+// generationIndex indexes the workspace's cues by number and name, for finding the
+// cues an earlier generation created
+type generationIndex struct {
+	byNumber map[string][]string
+	byName   map[string][]string
+}
+
+// findGenerated returns the uniqueID of the cue stamped with an idempotency key, or "" if
+// there's none. Cues this generator made are remembered; otherwise the cues with the
+// number or name the cue is generated with are checked for the stamp, so a cue renumbered
+// and renamed in QLab isn't found.
+func (cg *CueGenerator) findGenerated(existing generationIndex, key, cueNumber, name string) string {
+	var candidates []string
+	if uniqueID, ok := cg.generated[key]; ok {
+		candidates = append(candidates, uniqueID)
+	}
+	if cueNumber != "" {
+		if uniqueID, ok := cg.workspace.lookupCueNumber(cueNumber); ok {
+			candidates = append(candidates, uniqueID)
+		}
+		candidates = append(candidates, existing.byNumber[cueNumber]...)
+	}
+	if name != "" {
+		candidates = append(candidates, existing.byName[name]...)
+	}
+	for _, uniqueID := range candidates {
+		if cg.idempotencyKeyOf(uniqueID) == key {
+			return uniqueID
+		}
+	}
+	return ""
+}
+
+// indexGeneratedCues reads the workspace's cues once and indexes them by number and name
+func (cg *CueGenerator) indexGeneratedCues() generationIndex {
+	existing := generationIndex{byNumber: make(map[string][]string), byName: make(map[string][]string)}
+	data, err := cg.workspace.getCueLists()
+	if err != nil {
+		cg.workspace.log().Debug("Failed to list cues for idempotency check", "error", err)
+		return existing
+	}
+	var walk func(cues []any)
+	walk = func(cues []any) {
+		for _, cueData := range cues {
+			cue, ok := cueData.(map[string]any)
+			if !ok {
+				continue
+			}
+			if uniqueID, _ := cue["uniqueID"].(string); uniqueID != "" {
+				if cueNumber, _ := cue["number"].(string); cueNumber != "" {
+					existing.byNumber[cueNumber] = append(existing.byNumber[cueNumber], uniqueID)
+				}
+				if cueName, _ := cue["name"].(string); cueName != "" {
+					existing.byName[cueName] = append(existing.byName[cueName], uniqueID)
+				}
+			}
+			if children, ok := cue["cues"].([]any); ok {
+				walk(children)
+			}
+		}
+	}
+	for _, cueListData := range data {
+		if cueList, ok := cueListData.(map[string]any); ok {
+			if cues, ok := cueList["cues"].([]any); ok {
+				walk(cues)
+			}
+		}
+	}
+	return existing
+}
+
+// idempotencyKeyProperty returns the QLab property idempotency keys are stamped in: the
+// external ID property if one is set, notes otherwise
+func (cg *CueGenerator) idempotencyKeyProperty() string {
+	if cg.workspace.externalIDProperty != "" {
+		return cg.workspace.externalIDProperty
+	}
+	return "notes"
+}
+
+// idempotencyKeyOf returns the idempotency key stamped on a cue, "" if there's none or the
+// cue doesn't exist
+func (cg *CueGenerator) idempotencyKeyOf(uniqueID string) string {
+	value, ok := cg.workspace.queryCueValue(uniqueID, cg.idempotencyKeyProperty())
+	if !ok {
+		return ""
+	}
+	stamp, _ := value.(string)
+	if cg.workspace.externalIDProperty != "" {
+		return strings.TrimSpace(stamp)
+	}
+	if match := externalIDPattern.FindStringSubmatch(stamp); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// stampIdempotencyKey stamps a generated cue with its key, the way external IDs are stamped,
+// so transmits matching external IDs recognise the cue too
+func (cg *CueGenerator) stampIdempotencyKey(uniqueID, key string) error {
+	if cg.workspace.externalIDProperty != "" {
+		return cg.workspace.setCueProperty(uniqueID, cg.workspace.externalIDProperty, key)
+	}
+	return cg.workspace.setCueProperty(uniqueID, "notes", withExternalID("", key))
+}
+
+// updateTemplateCue updates a generated cue, without its children, from a template
+func (cg *CueGenerator) updateTemplateCue(template templates.CueTemplate, uniqueID, cueNumber, parentID string, mapping *CueMapping) (templates.CreatedCue, error) {
+	reply, err := cg.workspace.Query(cg.workspace.CueAddress(uniqueID, "type"))
+	if err != nil {
+		return templates.CreatedCue{}, fmt.Errorf("failed to read the type of generated cue %s: %w", uniqueID, err)
+	}
+	var cueType string
+	if err := reply.Decode(&cueType); err != nil {
+		return templates.CreatedCue{}, fmt.Errorf("failed to read the type of generated cue %s: %w", uniqueID, err)
+	}
+	if !strings.EqualFold(cueType, template.Type) {
+		return templates.CreatedCue{}, fmt.Errorf("generated cue %s is a %s cue, not %s", uniqueID, cueType, template.Type)
+	}
+	if cueNumber != "" {
+		if err := cg.setCueNumber(uniqueID, cueNumber); err != nil {
+			return templates.CreatedCue{}, fmt.Errorf("failed to renumber cue %s: %w", uniqueID, err)
+		}
+	}
+	if err := cg.setCueProperties(uniqueID, template.Name, template.Properties); err != nil {
+		return templates.CreatedCue{}, fmt.Errorf("failed to set properties for cue %s: %w", uniqueID, err)
+	}
+
+	mapping.recordCue(cueNumber, template.Name, uniqueID)
+	mapping.recordTarget(uniqueID, templateCueData(template))
+	return templates.CreatedCue{
+		UniqueID:  uniqueID,
+		CueNumber: cueNumber,
+		Name:      template.Name,
+		Type:      template.Type,
+		ParentID:  parentID,
+		Updated:   true,
+	}, nil
+}
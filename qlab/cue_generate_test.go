@@ -1,6 +1,7 @@
 package qlab
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/zenibako/qlab-golang/templates"
@@ -413,3 +414,155 @@ func TestExtractUniqueIDFromResult(t *testing.T) {
 		})
 	}
 }
+
+// TestGenerateFromLibrary tests generating cues from a parameterized library template
+func TestGenerateFromLibrary(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	generator := NewCueGenerator(workspace)
+
+	library := templates.NewTemplateLibrary("Scenes")
+	err := library.Add("scene", templates.LibraryTemplate{
+		Parameters: []templates.TemplateParameter{{Name: "SceneName", Required: true}},
+		Template: templates.CueTemplate{
+			Type: "group",
+			Name: "{{.SceneName}}",
+			Children: []templates.CueTemplate{
+				{Type: "memo", Name: "{{.SceneName}} standby"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to add template: %v", err)
+	}
+
+	if result := generator.GenerateFromLibrary("scene", nil); result.Success {
+		t.Fatal("Expected an error without a template library")
+	}
+	generator.SetTemplateLibrary(library)
+
+	result := generator.GenerateFromLibrary("scene", map[string]string{"SceneName": "Storm", templates.ParamCueNumber: "20"})
+	if !result.Success {
+		t.Fatalf("Expected successful generation, got errors: %v", result.Errors)
+	}
+	if len(result.CuesCreated) != 2 || mockServer.GetCueCount() != 2 {
+		t.Fatalf("Expected 2 cues, got %+v", result.CuesCreated)
+	}
+	if result.CuesCreated[0].Name != "Storm" || result.CuesCreated[0].CueNumber != "20" {
+		t.Errorf("Expected group 20 named Storm, got %+v", result.CuesCreated[0])
+	}
+	if result.CuesCreated[1].Name != "Storm standby" || result.CuesCreated[1].CueNumber != "20.1" {
+		t.Errorf("Expected memo 20.1 named 'Storm standby', got %+v", result.CuesCreated[1])
+	}
+
+	result = generator.GenerateFromLibrary("scene", nil)
+	if result.Success || len(result.Errors) == 0 || mockServer.GetCueCount() != 2 {
+		t.Errorf("Expected a missing parameter to fail before any cue is created, got %+v", result)
+	}
+}
+
+// TestGenerateBatch tests that a batch creates targeted cues first, sets targets across
+// requests, and skips requests targeting one that failed
+func TestGenerateBatch(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	generator := NewCueGenerator(workspace)
+
+	requests := []templates.CueGenerationRequest{
+		{CueNumber: "20", Template: templates.CueTemplate{Type: "start", Name: "Go scene", Properties: map[string]any{"cueTargetNumber": "10"}}},
+		{CueNumber: "10", Template: templates.CueTemplate{Type: "group", Name: "Scene", Children: []templates.CueTemplate{
+			{Type: "stop", Name: "Stop standby", Properties: map[string]any{"cueTargetName": "Standby"}},
+		}}},
+		{CueNumber: "30", Template: templates.CueTemplate{Type: "memo", Name: "Standby"}},
+		{CueNumber: "40", Template: templates.CueTemplate{Name: "Untyped"}},
+		{CueNumber: "50", Template: templates.CueTemplate{Type: "start", Name: "Go untyped", Properties: map[string]any{"cueTargetNumber": "40"}}},
+	}
+	result := generator.GenerateBatch(requests)
+
+	if result.Success || len(result.Results) != len(requests) {
+		t.Fatalf("Expected the batch to report the failed requests, got %+v", result)
+	}
+	for i, want := range []bool{true, true, true, false, false} {
+		if result.Results[i].Success != want {
+			t.Errorf("Request %d: expected success %t, got %+v", i, want, result.Results[i])
+		}
+	}
+
+	var created []string
+	for _, cue := range result.CuesCreated {
+		created = append(created, cue.CueNumber)
+	}
+	if got, want := strings.Join(created, " "), "30 10 10.1 20"; got != want {
+		t.Errorf("Expected cues created in the order %q, got %q", want, got)
+	}
+	if mockServer.GetCueCount() != 4 {
+		t.Errorf("Expected the failed requests to create no cues, got %d cues", mockServer.GetCueCount())
+	}
+
+	goScene := mockServer.GetCue(result.Results[0].CuesCreated[0].UniqueID)
+	if goScene == nil || goScene.CueTargetNumber != "10" {
+		t.Errorf("Expected cue 20 to target cue 10, got %+v", goScene)
+	}
+	standby := result.Results[2].CuesCreated[0].UniqueID
+	if stop := mockServer.GetCue(result.Results[1].CuesCreated[1].UniqueID); stop == nil || stop.CueTargetNumber != "30" {
+		t.Errorf("Expected cue 10.1 to target Standby (%s) by its number, got %+v", standby, stop)
+	}
+}
+
+// TestGenerateIdempotent tests that generating a request with an idempotency key again
+// updates its cues, including from a new generator, instead of duplicating them
+func TestGenerateIdempotent(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+
+	request := templates.CueGenerationRequest{
+		CueNumber:      "60",
+		IdempotencyKey: "scene-storm",
+		Template: templates.CueTemplate{Type: "group", Name: "Storm", Children: []templates.CueTemplate{
+			{Type: "memo", Name: "Storm standby"},
+		}},
+	}
+	first := NewCueGenerator(workspace).GenerateCues(request)
+	if !first.Success || len(first.CuesCreated) != 2 || first.CuesCreated[0].Updated {
+		t.Fatalf("Expected the group and its child created, got %+v", first)
+	}
+	groupID := first.CuesCreated[0].UniqueID
+
+	request.Template.Name = "Thunderstorm"
+	request.Template.Children = append(request.Template.Children, templates.CueTemplate{Type: "memo", Name: "Thunder"})
+	var thunderID string
+	for _, generator := range []*CueGenerator{NewCueGenerator(workspace), NewCueGenerator(workspace)} {
+		again := generator.GenerateCues(request)
+		if !again.Success || len(again.CuesCreated) != 3 {
+			t.Fatalf("Expected the group updated with one new child, got %+v", again)
+		}
+		thunderID = again.CuesCreated[2].UniqueID
+		if again.CuesCreated[0].UniqueID != groupID || !again.CuesCreated[0].Updated || !again.CuesCreated[1].Updated {
+			t.Errorf("Expected the generated group and child updated, got %+v", again.CuesCreated)
+		}
+		request.Template.Children = request.Template.Children[:2]
+	}
+	if got := mockServer.GetCueCount(); got != 3 {
+		t.Errorf("Expected 3 cues after regenerating, got %d", got)
+	}
+	if group := mockServer.GetCue(groupID); group == nil || group.Name != "Thunderstorm" {
+		t.Errorf("Expected the group renamed, got %+v", group)
+	}
+
+	// Children dropped from the template are deleted from the regenerated group
+	request.Template.Children = request.Template.Children[:1]
+	if fewer := NewCueGenerator(workspace).GenerateCues(request); !fewer.Success || len(fewer.CuesCreated) != 2 {
+		t.Fatalf("Expected the group regenerated with one child, got %+v", fewer)
+	}
+	if mockServer.GetCue(thunderID) != nil || mockServer.GetCueCount() != 2 {
+		t.Errorf("Expected the dropped child deleted, got %d cues", mockServer.GetCueCount())
+	}
+
+	request.Template.Type = "memo"
+	if mismatched := NewCueGenerator(workspace).GenerateCues(request); mismatched.Success {
+		t.Error("Expected regenerating the group as a memo cue to fail")
+	}
+	request.Template.Type = "group"
+
+	request.IdempotencyKey = "scene-storm-2"
+	if other := NewCueGenerator(workspace).GenerateCues(request); !other.Success || other.CuesCreated[0].Updated {
+		t.Errorf("Expected a new key to create new cues, got %+v", other)
+	}
+}
@@ -0,0 +1,147 @@
+package qlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GroupMode is the mode of a group cue, as QLab 5 numbers it
+type GroupMode int
+
+// groupModeNames are the canonical names of the group modes
+var groupModeNames = map[GroupMode]string{
+	GroupModeList:               "list",
+	GroupModeStartFirstAndEnter: "start first and enter",
+	GroupModeStartFirst:         "start first",
+	GroupModeTimeline:           "timeline",
+	GroupModeStartRandom:        "start random",
+	GroupModeCart:               "cart",
+	GroupModePlaylist:           "playlist",
+}
+
+// groupModeAliases are the names accepted for each mode, with spaces, dashes, and
+// underscores removed and matched case-insensitively
+var groupModeAliases = map[string]GroupMode{
+	"list":               GroupModeList,
+	"cuelist":            GroupModeList,
+	"startfirstandenter": GroupModeStartFirstAndEnter,
+	"firstandenter":      GroupModeStartFirstAndEnter,
+	"firefirstandenter":  GroupModeStartFirstAndEnter,
+	"startfirst":         GroupModeStartFirst,
+	"firefirst":          GroupModeStartFirst,
+	"timeline":           GroupModeTimeline,
+	"startall":           GroupModeTimeline,
+	"fireall":            GroupModeTimeline,
+	"startrandom":        GroupModeStartRandom,
+	"firerandom":         GroupModeStartRandom,
+	"random":             GroupModeStartRandom,
+	"cart":               GroupModeCart,
+	"playlist":           GroupModePlaylist,
+}
+
+// String returns the mode's name, or its number if it isn't a known mode
+func (m GroupMode) String() string {
+	if name, ok := groupModeNames[m]; ok {
+		return name
+	}
+	return strconv.Itoa(int(m))
+}
+
+// Valid reports whether m is one of QLab's group modes
+func (m GroupMode) Valid() bool {
+	_, ok := groupModeNames[m]
+	return ok
+}
+
+// UnmarshalJSON accepts a mode as a number or a name
+func (m *GroupMode) UnmarshalJSON(data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	mode, err := ParseGroupMode(value)
+	if err != nil {
+		return err
+	}
+	*m = mode
+	return nil
+}
+
+// ParseGroupMode converts a mode given as a number, a numeric string, or a name such as
+// "timeline" or "fire first" to a GroupMode
+func ParseGroupMode(value any) (GroupMode, error) {
+	switch v := value.(type) {
+	case GroupMode:
+		if !v.Valid() {
+			return 0, fmt.Errorf("unknown group mode %d", int(v))
+		}
+		return v, nil
+	case string:
+		key := strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(strings.TrimSpace(v)))
+		if mode, ok := groupModeAliases[key]; ok {
+			return mode, nil
+		}
+		if _, err := strconv.Atoi(key); err != nil {
+			return 0, fmt.Errorf("unknown group mode %q", v)
+		}
+	}
+	f, ok := toFloat(value)
+	if !ok || f != float64(int(f)) || !GroupMode(f).Valid() {
+		return 0, fmt.Errorf("unknown group mode %v", value)
+	}
+	return GroupMode(f), nil
+}
+
+// ValidateGroupMode checks that a mode is possible for a cue type. Cue lists are always in
+// list mode and carts in cart mode; a group can take any mode but cart.
+func ValidateGroupMode(cueType string, mode GroupMode) error {
+	if !mode.Valid() {
+		return fmt.Errorf("unknown group mode %d", int(mode))
+	}
+	switch strings.ToLower(cueType) {
+	case "list", CueTypeList:
+		if mode != GroupModeList {
+			return fmt.Errorf("a cue list can't be in %s mode", mode)
+		}
+	case CueTypeCart:
+		if mode != GroupModeCart {
+			return fmt.Errorf("a cart can't be in %s mode", mode)
+		}
+	case CueTypeGroup:
+		if mode == GroupModeCart {
+			return fmt.Errorf("a group can't be in cart mode; use a cart cue")
+		}
+	}
+	return nil
+}
+
+// groupModeOf reads and validates the mode of cue data, reporting whether it has one
+func groupModeOf(cueData map[string]any) (GroupMode, bool, error) {
+	value, ok := cueData["mode"]
+	if !ok || value == nil {
+		return 0, false, nil
+	}
+	cueType, _ := cueData["type"].(string)
+	mode, err := ParseGroupMode(value)
+	if err == nil {
+		err = ValidateGroupMode(cueType, mode)
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("cue %s: %w", sourceCueLabel(cueData, -1), err)
+	}
+	return mode, true, nil
+}
+
+// setGroupMode sets the mode of a group cue from its cue data, if it has one
+func (q *Workspace) setGroupMode(uniqueID string, cueData map[string]any) error {
+	mode, ok, err := groupModeOf(cueData)
+	if err != nil || !ok {
+		return err
+	}
+	if err := q.setCueProperty(uniqueID, "mode", strconv.Itoa(int(mode))); err != nil {
+		return fmt.Errorf("failed to set group mode: %v", err)
+	}
+	return nil
+}
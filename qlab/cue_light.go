@@ -0,0 +1,111 @@
+package qlab
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// lightCueProperties are the light cue properties: the command text, as typed in the
+// cue's light commands editor, and whether to always collate
+var lightCueProperties = []cuePropertySpec{
+	{key: "lightCommandText", kind: cuePropertyString},
+	{key: "alwaysCollate", kind: cuePropertyBool},
+}
+
+// LightCommandText builds light command text from instrument or group levels, one
+// "name = level" command per line, sorted by name. Names may address a parameter, as in
+// "Front Wash.red".
+func LightCommandText(levels map[string]float64) string {
+	names := make([]string, 0, len(levels))
+	for name := range levels {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var builder strings.Builder
+	for _, name := range names {
+		if builder.Len() > 0 {
+			builder.WriteByte('\n')
+		}
+		fmt.Fprintf(&builder, "%s = %s", name, strconv.FormatFloat(levels[name], 'f', -1, 64))
+	}
+	return builder.String()
+}
+
+// ParseLightCommandText reads "name = level" light commands back into levels. Blank lines
+// are skipped; a command without a numeric level is an error, since it can't round-trip.
+func ParseLightCommandText(text string) (map[string]float64, error) {
+	levels := make(map[string]float64)
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("light command %d %q is not name = level", i+1, line)
+		}
+		level, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("light command %d %q has no numeric level", i+1, line)
+		}
+		levels[name] = level
+	}
+	return levels, nil
+}
+
+// lightCommandLevels reads the lightCommands map of cue data as levels
+func lightCommandLevels(value any) (map[string]float64, error) {
+	commands, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("lightCommands must map instruments to levels, got %T", value)
+	}
+	levels := make(map[string]float64, len(commands))
+	for name, level := range commands {
+		f, ok := toFloat(level)
+		if !ok {
+			return nil, fmt.Errorf("light level for %s must be a number, got %v", name, level)
+		}
+		levels[name] = f
+	}
+	return levels, nil
+}
+
+// setLightCueProperties sets the commands and collate setting of a light cue, then prunes
+// it if asked. lightCommandText takes precedence over a lightCommands map. Failures are
+// returned when strict and logged otherwise.
+func (q *Workspace) setLightCueProperties(uniqueID string, cueData map[string]any, strict bool) error {
+	properties := cueData
+	if _, hasText := cueData["lightCommandText"]; !hasText && cueData["lightCommands"] != nil {
+		levels, err := lightCommandLevels(cueData["lightCommands"])
+		if err != nil {
+			if strict {
+				return err
+			}
+			q.log().Warnf("Failed to set light commands for cue %s: %v", uniqueID, err)
+		} else {
+			properties = make(map[string]any, len(cueData)+1)
+			for k, v := range cueData {
+				properties[k] = v
+			}
+			properties["lightCommandText"] = LightCommandText(levels)
+		}
+	}
+	if err := q.setMappedCueProperties(uniqueID, properties, lightCueProperties, strict); err != nil {
+		return err
+	}
+
+	// Pruning drops commands that don't change anything; it is an action, not a property
+	if toBool(cueData["prune"]) {
+		if err := q.setCuePropertyWithArgs(uniqueID, "prune"); err != nil {
+			if strict {
+				return fmt.Errorf("failed to prune light commands: %w", err)
+			}
+			q.log().Warnf("Failed to prune light commands for cue %s: %v", uniqueID, err)
+		}
+	}
+	return nil
+}
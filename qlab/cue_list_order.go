@@ -0,0 +1,109 @@
+package qlab
+
+import (
+	"fmt"
+	"slices"
+)
+
+// CueListInfo is a cue list of the workspace, as CueListOrder reports it
+type CueListInfo struct {
+	Name     string `json:"name"`
+	UniqueID string `json:"uniqueID"`
+}
+
+// CueListOrder returns the workspace's cue lists in the order QLab shows them
+func (q *Workspace) CueListOrder() ([]CueListInfo, error) {
+	reply, err := q.Query("/cueLists/shallow")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cue lists: %w", err)
+	}
+	var lists []CueListInfo
+	if err := reply.Decode(&lists); err != nil {
+		return nil, fmt.Errorf("failed to parse cue lists: %w", err)
+	}
+	return lists, nil
+}
+
+// MoveCueList moves a cue list, given by name or uniqueID, to an index among the
+// workspace's cue lists, 0 for the first. Indexes past the last cue list move it to the end.
+func (q *Workspace) MoveCueList(cueList string, index int) error {
+	q.opMu.Lock()
+	defer q.opMu.Unlock()
+	return q.moveCueListLocked(cueList, index)
+}
+
+// moveCueListLocked moves a cue list to an index; q.opMu must be held
+func (q *Workspace) moveCueListLocked(cueList string, index int) error {
+	if index < 0 {
+		return fmt.Errorf("invalid cue list index %d", index)
+	}
+	lists, err := q.CueListOrder()
+	if err != nil {
+		return err
+	}
+	current := slices.IndexFunc(lists, func(list CueListInfo) bool {
+		return list.Name == cueList || list.UniqueID == cueList
+	})
+	if current < 0 {
+		return fmt.Errorf("cue list %q: %w", cueList, ErrCueNotFound)
+	}
+	index = min(index, len(lists)-1)
+	if current == index {
+		return nil
+	}
+	if err := q.moveCueList(lists[current].UniqueID, index); err != nil {
+		return fmt.Errorf("failed to move cue list %q: %w", cueList, err)
+	}
+	q.log().Infof("Moved cue list %q from index %d to %d", lists[current].Name, current, index)
+	return nil
+}
+
+// ReorderCueLists puts the cue lists with the given names or uniqueIDs first, in the order
+// given, followed by the others in their current order. Moves stop at the first that fails.
+func (q *Workspace) ReorderCueLists(cueLists ...string) error {
+	q.opMu.Lock()
+	defer q.opMu.Unlock()
+
+	for i, cueList := range cueLists {
+		if err := q.moveCueListLocked(cueList, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateCueListAt creates a cue list with a name at an index among the workspace's cue
+// lists and returns its uniqueID. QLab puts new cue lists last, so an index past the last
+// leaves it there.
+func (q *Workspace) CreateCueListAt(name string, index int) (string, error) {
+	if index < 0 {
+		return "", fmt.Errorf("invalid cue list index %d", index)
+	}
+	q.opMu.Lock()
+	defer q.opMu.Unlock()
+
+	listID, err := q.createCueList(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to create cue list %q: %w", name, err)
+	}
+	q.recordCueList(name, listID)
+	q.InvalidateCueCache()
+	if q.dryRun {
+		q.log().Infof("[DRY RUN] Would move cue list %q to index %d", name, index)
+		return listID, nil
+	}
+	if err := q.moveCueListLocked(listID, index); err != nil {
+		return listID, err
+	}
+	return listID, nil
+}
+
+// moveCueList sends the /move for a cue list, without a parent since cue lists only move
+// among themselves
+func (q *Workspace) moveCueList(listID string, index int) error {
+	if _, err := q.Query(fmt.Sprintf("/move/%s", listID), int32(index)); err != nil {
+		return err
+	}
+	q.InvalidateCueCache()
+	return nil
+}
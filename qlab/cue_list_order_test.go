@@ -0,0 +1,61 @@
+package qlab
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// cueListNames returns the names of the workspace's cue lists in order
+func cueListNames(t *testing.T, workspace *Workspace) []string {
+	t.Helper()
+	lists, err := workspace.CueListOrder()
+	if err != nil {
+		t.Fatalf("CueListOrder failed: %v", err)
+	}
+	names := make([]string, len(lists))
+	for i, list := range lists {
+		names[i] = list.Name
+	}
+	return names
+}
+
+// TestCueListOrder tests reading, creating at an index, moving, and reordering cue lists
+func TestCueListOrder(t *testing.T) {
+	workspace, _ := setupWorkspaceWithCleanup(t)
+
+	for _, name := range []string{"Act 2", "Act 3"} {
+		if _, err := workspace.ensureCueList(name); err != nil {
+			t.Fatalf("Failed to create cue list %q: %v", name, err)
+		}
+	}
+	if got := cueListNames(t, workspace); !slices.Equal(got, []string{"Main Cue List", "Act 2", "Act 3"}) {
+		t.Fatalf("Expected new cue lists last, got %v", got)
+	}
+
+	act1, err := workspace.CreateCueListAt("Act 1", 1)
+	if err != nil {
+		t.Fatalf("CreateCueListAt failed: %v", err)
+	}
+	if got := cueListNames(t, workspace); !slices.Equal(got, []string{"Main Cue List", "Act 1", "Act 2", "Act 3"}) {
+		t.Errorf("Expected Act 1 created at index 1, got %v", got)
+	}
+
+	if err := workspace.MoveCueList("Main Cue List", 10); err != nil {
+		t.Fatalf("MoveCueList failed: %v", err)
+	}
+	if got := cueListNames(t, workspace); !slices.Equal(got, []string{"Act 1", "Act 2", "Act 3", "Main Cue List"}) {
+		t.Errorf("Expected the main cue list moved last, got %v", got)
+	}
+
+	if err := workspace.ReorderCueLists("Act 3", act1); err != nil {
+		t.Fatalf("ReorderCueLists failed: %v", err)
+	}
+	if got := cueListNames(t, workspace); !slices.Equal(got, []string{"Act 3", "Act 1", "Act 2", "Main Cue List"}) {
+		t.Errorf("Expected Act 3 and Act 1 first, got %v", got)
+	}
+
+	if err := workspace.MoveCueList("Intermission", 0); !errors.Is(err, ErrCueNotFound) {
+		t.Errorf("Expected ErrCueNotFound for a missing cue list, got %v", err)
+	}
+}
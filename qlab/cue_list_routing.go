@@ -0,0 +1,90 @@
+package qlab
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// createCueList creates a new cue list with a name and returns its uniqueID
+func (q *Workspace) createCueList(name string) (string, error) {
+	// Create a new cue list using /new list
+	address := fmt.Sprintf("/workspace/%s/new", q.workspace_id)
+	reply := q.Send(address, "list")
+
+	if len(reply) == 0 {
+		return "", fmt.Errorf("no reply received when creating cue list")
+	}
+
+	replyStr, ok := reply[0].(string)
+	if !ok {
+		return "", fmt.Errorf("invalid reply format from cue list creation")
+	}
+
+	var replyData map[string]any
+	err := json.Unmarshal([]byte(replyStr), &replyData)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse cue list creation reply: %v", err)
+	}
+
+	// Check for error status
+	if status, ok := replyData["status"].(string); ok && status == "error" {
+		return "", newQLabError("QLab error creating cue list", replyStr)
+	}
+
+	// Extract the new cue list ID
+	cueListID, ok := replyData["data"].(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected cue list creation reply format")
+	}
+
+	q.log().Debug("Created new cue list", "cue_list_id", cueListID)
+
+	err = q.setCueListProperty(cueListID, "name", name)
+	if err != nil {
+		return "", fmt.Errorf("failed to set cue list name: %v", err)
+	}
+
+	q.log().Debug("Set cue list name", "cue_list_id", cueListID, "name", name)
+	return cueListID, nil
+}
+
+// ensureCueList returns the uniqueID of the cue list with a name, creating it if QLab
+// doesn't have one
+func (q *Workspace) ensureCueList(name string) (string, error) {
+	if listID := q.lookupCueList(name); listID != "" {
+		return listID, nil
+	}
+	listID, err := q.createCueList(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to create cue list %q: %w", name, err)
+	}
+	q.recordCueList(name, listID)
+	q.log().Infof("Created cue list %q: %s", name, listID)
+	return listID, nil
+}
+
+// routeCue makes the cue list named by a top-level cue's listName the current one, so
+// QLab creates the cue in it, creating the list first if needed. selected holds the list
+// made current last, so a run of cues in one list selects it once.
+func (q *Workspace) routeCue(cueData map[string]any, selected *string) error {
+	listName, _ := cueData["listName"].(string)
+	if listName == "" || cueData["type"] == "list" {
+		return nil
+	}
+	listID, err := q.ensureCueList(listName)
+	if err != nil {
+		return err
+	}
+	if listID == *selected {
+		return nil
+	}
+
+	// New cues go into the cue list of the selection
+	address := fmt.Sprintf("/workspace/%s/select_id/%s", q.workspace_id, listID)
+	if err := checkReplyStatus(q.Send(address, "")); err != nil {
+		return fmt.Errorf("failed to select cue list %q: %w", listName, err)
+	}
+	*selected = listID
+	q.log().Debug("Routing cues to cue list", "name", listName, "cue_list_id", listID)
+	return nil
+}
@@ -0,0 +1,66 @@
+package qlab
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestCueListRouting tests that top-level cues are created in the cue list their listName
+// names, and that lists are created once and reused afterwards
+func TestCueListRouting(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetSkipInbox(true)
+
+	cues := []any{
+		map[string]any{"type": "memo", "number": "1", "name": "House open", "listName": "Preshow"},
+		map[string]any{"type": "memo", "number": "2", "name": "Opening", "listName": "Show"},
+		map[string]any{"type": "memo", "number": "3", "name": "Blackout", "listName": "Show"},
+		map[string]any{"type": "memo", "number": "4", "name": "Walk-in music", "listName": "Preshow"},
+		map[string]any{"type": "memo", "number": "5", "name": "Main list note"},
+	}
+	// Index the existing cue lists, as Init does
+	if _, err := workspace.ValidateIndexes(); err != nil {
+		t.Fatalf("ValidateIndexes failed: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "show.json")
+	if _, err := workspace.TransmitWorkspaceData(path, map[string]any{"cues": cues}); err != nil {
+		t.Fatalf("TransmitWorkspaceData failed: %v", err)
+	}
+
+	// Adding a cue to an existing list reuses it
+	cues = append(cues, map[string]any{"type": "memo", "number": "6", "name": "Curtain call", "listName": "Show"})
+	if _, err := workspace.TransmitWorkspaceData(path, map[string]any{"cues": cues}, WithConflictResolver(AlwaysSource)); err != nil {
+		t.Fatalf("Second TransmitWorkspaceData failed: %v", err)
+	}
+
+	report, err := workspace.ValidateIndexes()
+	if err != nil {
+		t.Fatalf("ValidateIndexes failed: %v", err)
+	}
+	if !report.OK() {
+		t.Errorf("Expected the created lists indexed, got divergences %v", report.Divergences)
+	}
+	if report.CueLists != 3 {
+		t.Errorf("Expected the main, Preshow and Show cue lists, got %d cue lists", report.CueLists)
+	}
+
+	found, err := workspace.FindCues(func(*Cue) bool { return true })
+	if err != nil {
+		t.Fatalf("FindCues failed: %v", err)
+	}
+	listNames := make(map[string]string)
+	for _, cue := range found {
+		listNames[cue.Number] = cue.ListName
+	}
+	// Cues without a listName go into the list current when they are created
+	expected := map[string]string{"1": "Preshow", "2": "Show", "3": "Show", "4": "Preshow", "5": "Preshow", "6": "Show"}
+	for number, listName := range expected {
+		if listNames[number] != listName {
+			t.Errorf("Expected cue %s in %q, got %q", number, listName, listNames[number])
+		}
+	}
+	if mockServer.GetCueCount() != len(cues) {
+		t.Errorf("Expected %d cues, got %d", len(cues), mockServer.GetCueCount())
+	}
+}
@@ -1,13 +1,211 @@
 package qlab
 
+import (
+	"fmt"
+	"strings"
+)
+
 // CueMapping tracks the relationship between cue numbers and unique IDs
 type CueMapping struct {
-	NumberToID      map[string]string // cue number -> unique ID
-	CuesWithTargets []CueTarget       // cues that need target setting after creation
+	NumberToID      map[string]string     // cue number -> unique ID
+	NameToCues      map[string][]NamedCue // cue name -> cues with that name, for cueTargetName resolution
+	CuesWithTargets []CueTarget           // cues that need target setting after creation
 }
 
 // CueTarget represents a cue that needs its target set after creation
 type CueTarget struct {
 	UniqueID     string
 	TargetNumber string
+	TargetName   string // Target referenced by cue name (cueTargetName) instead of number
+	TargetID     string // Target's uniqueID (cueTargetID), set when no number or name is given or the number can't be set
+}
+
+// NamedCue identifies a cue found by name
+type NamedCue struct {
+	UniqueID string
+	Number   string
+}
+
+// TargetNameError is returned when a cueTargetName matches no cue or more than one cue
+type TargetNameError struct {
+	Name       string
+	Candidates []NamedCue // Every cue with the name; empty when none was found
+}
+
+func (e *TargetNameError) Error() string {
+	if len(e.Candidates) == 0 {
+		return fmt.Sprintf("no cue named %q", e.Name)
+	}
+	var candidates []string
+	for _, c := range e.Candidates {
+		if c.Number != "" {
+			candidates = append(candidates, c.Number)
+		} else {
+			candidates = append(candidates, c.UniqueID)
+		}
+	}
+	return fmt.Sprintf("cue name %q is ambiguous, matches %d cues: %s", e.Name, len(e.Candidates), strings.Join(candidates, ", "))
+}
+
+// recordTarget queues the target of a targeting cue to be set once every cue it could
+// reference exists. Numbers take precedence over names; cueTargetID is kept as the fallback.
+func (m *CueMapping) recordTarget(uniqueID string, cueData map[string]any) {
+	cueType, _ := cueData["type"].(string)
+	if uniqueID == "" || !isTargetingCueType(cueType) {
+		return
+	}
+	target := CueTarget{UniqueID: uniqueID}
+	target.TargetID, _ = cueData["cueTargetID"].(string)
+	if number, ok := cueData["cueTargetNumber"].(string); ok && number != "" {
+		target.TargetNumber = number
+	} else if name, ok := cueData["cueTargetName"].(string); ok && name != "" {
+		target.TargetName = name
+	} else if target.TargetID == "" {
+		return
+	}
+	m.CuesWithTargets = append(m.CuesWithTargets, target)
+}
+
+// recordCue adds a processed cue to the number and name indexes
+func (m *CueMapping) recordCue(number, name, uniqueID string) {
+	if uniqueID == "" {
+		return
+	}
+	if number != "" {
+		m.NumberToID[number] = uniqueID
+	}
+	if name != "" {
+		if m.NameToCues == nil {
+			m.NameToCues = make(map[string][]NamedCue)
+		}
+		for _, existing := range m.NameToCues[name] {
+			if existing.UniqueID == uniqueID {
+				return
+			}
+		}
+		m.NameToCues[name] = append(m.NameToCues[name], NamedCue{UniqueID: uniqueID, Number: number})
+	}
+}
+
+// numberOf returns the number recorded for a cue, or an empty string if it has none
+func (m *CueMapping) numberOf(uniqueID string) string {
+	for number, id := range m.NumberToID {
+		if id == uniqueID {
+			return number
+		}
+	}
+	return ""
+}
+
+// ResolveTargetName returns the single cue with the given name, or a *TargetNameError
+// if no cue or more than one cue has that name
+func (m *CueMapping) ResolveTargetName(name string) (NamedCue, error) {
+	return resolveNamedCue(name, m.NameToCues[name])
+}
+
+// resolveNamedCue applies ambiguity detection to the cues found for a name
+func resolveNamedCue(name string, candidates []NamedCue) (NamedCue, error) {
+	if len(candidates) != 1 {
+		return NamedCue{}, &TargetNameError{Name: name, Candidates: candidates}
+	}
+	return candidates[0], nil
+}
+
+// resolveTargetName resolves a cueTargetName against the cues processed from source data,
+// falling back to the cues already in QLab when the source has no cue with that name
+func (q *Workspace) resolveTargetName(mapping *CueMapping, name string) (NamedCue, error) {
+	if candidates := mapping.NameToCues[name]; len(candidates) > 0 {
+		return resolveNamedCue(name, candidates)
+	}
+	return resolveNamedCue(name, q.findCuesByName(name))
+}
+
+// findCuesByName returns every cue in QLab with the given name
+func (q *Workspace) findCuesByName(name string) []NamedCue {
+	data, err := q.getCueLists()
+	if err != nil {
+		return nil
+	}
+
+	var found []NamedCue
+	var walk func(cues []any)
+	walk = func(cues []any) {
+		for _, cueData := range cues {
+			cue, ok := cueData.(map[string]any)
+			if !ok {
+				continue
+			}
+			if cueName, _ := cue["name"].(string); cueName == name {
+				uniqueID, _ := cue["uniqueID"].(string)
+				number, _ := cue["number"].(string)
+				if uniqueID != "" {
+					found = append(found, NamedCue{UniqueID: uniqueID, Number: number})
+				}
+			}
+			if children, ok := cue["cues"].([]any); ok {
+				walk(children)
+			}
+		}
+	}
+	for _, cueListData := range data {
+		if cueList, ok := cueListData.(map[string]any); ok {
+			if cues, ok := cueList["cues"].([]any); ok {
+				walk(cues)
+			}
+		}
+	}
+	return found
+}
+
+// ValidateTargetNames checks every cueTargetName in source data and returns a warning for
+// each name that matches no cue, or more than one cue, in the source data or QLab
+func (q *Workspace) ValidateTargetNames(workspaceData map[string]any) []string {
+	var cuesData []any
+	if cues, ok := workspaceData["cues"].([]any); ok {
+		cuesData = cues
+	} else if workspace, ok := workspaceData["workspace"].(map[string]any); ok {
+		if cues, ok := workspace["cues"].([]any); ok {
+			cuesData = cues
+		}
+	}
+
+	// Index source cue names the same way the transmit mapping does
+	mapping := &CueMapping{NumberToID: make(map[string]string)}
+	type namedTarget struct{ source, target string }
+	var targets []namedTarget
+	var walk func(cues []any, parentNumber, path string)
+	walk = func(cues []any, parentNumber, path string) {
+		for i, cueData := range cues {
+			cue, ok := cueData.(map[string]any)
+			if !ok {
+				continue
+			}
+			cuePath := fmt.Sprintf("%s[%d]", path, i)
+			_, fullNumber := q.sourceCueKey(cue, parentNumber, i)
+			name, _ := cue["name"].(string)
+			mapping.recordCue(fullNumber, name, cuePath)
+
+			if targetName, ok := cue["cueTargetName"].(string); ok && targetName != "" {
+				if number, _ := cue["cueTargetNumber"].(string); number == "" {
+					source := cuePath
+					if fullNumber != "" {
+						source = "cue " + fullNumber
+					}
+					targets = append(targets, namedTarget{source: source, target: targetName})
+				}
+			}
+			if children, ok := cue["cues"].([]any); ok {
+				walk(children, fullNumber, cuePath+".cues")
+			}
+		}
+	}
+	walk(cuesData, "", "cues")
+
+	var warnings []string
+	for _, t := range targets {
+		if _, err := q.resolveTargetName(mapping, t.target); err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: cueTargetName: %v", t.source, err))
+		}
+	}
+	return warnings
 }
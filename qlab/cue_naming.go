@@ -0,0 +1,165 @@
+package qlab
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"strings"
+	"text/template"
+)
+
+// CueTemplateData is what the name and notes templates are executed with for each source cue
+type CueTemplateData struct {
+	Number     string         // Cue number as written in the source, "" when unnumbered
+	FullNumber string         // Cue number including the parent group's, e.g. "12.1"
+	Type       string         // Lowercase cue type
+	Name       string         // Name in the source
+	Notes      string         // Notes in the source
+	Index      int            // Position among the parent's cues, from 0
+	Parent     string         // Full number of the group holding the cue, "" at the top level
+	ParentName string         // Name of the group or cue list holding the cue
+	ParentType string         // Type of the group or cue list holding the cue
+	Cue        map[string]any // Every property of the source cue, for {{index .Cue "department"}}
+	Source     map[string]any // Top-level source data other than the cues, e.g. show metadata
+}
+
+// cueTemplateFuncs are the functions name and notes templates may call besides the built-in ones
+var cueTemplateFuncs = template.FuncMap{
+	"upper":     strings.ToUpper,
+	"lower":     strings.ToLower,
+	"trim":      strings.TrimSpace,
+	"hasPrefix": strings.HasPrefix,
+	"default": func(fallback string, value any) string {
+		if value == nil || value == "" {
+			return fallback
+		}
+		return fmt.Sprint(value)
+	},
+}
+
+// SetNameTemplate sets a text/template every source cue's name is rendered through while
+// transmitting, e.g. "[SQ {{.Number}}] {{.Name}}". It's executed with a CueTemplateData and
+// can call upper, lower, trim, hasPrefix and default. An empty template turns it off.
+func (q *Workspace) SetNameTemplate(text string) error {
+	tmpl, err := parseCueTemplate("name", text)
+	if err != nil {
+		return err
+	}
+	q.nameTemplate = tmpl
+	return nil
+}
+
+// SetNotesTemplate sets a text/template every source cue's notes are rendered through while
+// transmitting, like SetNameTemplate
+func (q *Workspace) SetNotesTemplate(text string) error {
+	tmpl, err := parseCueTemplate("notes", text)
+	if err != nil {
+		return err
+	}
+	q.notesTemplate = tmpl
+	return nil
+}
+
+// parseCueTemplate parses a name or notes template, returning nil for an empty one
+func parseCueTemplate(kind, text string) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New(kind).Funcs(cueTemplateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template: %w", kind, err)
+	}
+	return tmpl, nil
+}
+
+// RenderCueTemplates returns a copy of source data with every cue's name and notes rendered
+// through the templates set with SetNameTemplate and SetNotesTemplate; the source data itself
+// is left alone. TransmitWorkspaceData renders them itself, so this is for comparing or
+// previewing. Cue lists keep their names.
+func (q *Workspace) RenderCueTemplates(workspaceData map[string]any) (map[string]any, error) {
+	if q.nameTemplate == nil && q.notesTemplate == nil {
+		return workspaceData, nil
+	}
+
+	source := maps.Clone(workspaceData)
+	cues, ok := source["cues"].([]any)
+	nested, _ := source["workspace"].(map[string]any)
+	if !ok && nested != nil {
+		nested = maps.Clone(nested)
+		source["workspace"] = nested
+		cues, _ = nested["cues"].([]any)
+	}
+	metadata := make(map[string]any)
+	for _, data := range []map[string]any{workspaceData, nested} {
+		for key, value := range data {
+			if key != "cues" && key != "workspace" {
+				metadata[key] = value
+			}
+		}
+	}
+
+	var render func(cues []any, parent map[string]any, parentNumber string) ([]any, error)
+	render = func(cues []any, parent map[string]any, parentNumber string) ([]any, error) {
+		rendered := make([]any, len(cues))
+		for i, cueData := range cues {
+			original, ok := cueData.(map[string]any)
+			if !ok {
+				rendered[i] = cueData
+				continue
+			}
+			cue := maps.Clone(original)
+			rendered[i] = cue
+			_, fullNumber := q.sourceCueKey(cue, parentNumber, i)
+
+			cueType, _ := cue["type"].(string)
+			switch strings.ToLower(cueType) {
+			case "list", "cuelist", "cue_list", CueTypeList:
+			default:
+				data := CueTemplateData{
+					Number: formatCueNumber(cue["number"]), FullNumber: fullNumber, Type: strings.ToLower(cueType),
+					Index: i, Parent: parentNumber, Cue: original, Source: metadata,
+				}
+				data.Name, _ = cue["name"].(string)
+				data.Notes, _ = cue["notes"].(string)
+				if parent != nil {
+					data.ParentName, _ = parent["name"].(string)
+					data.ParentType, _ = parent["type"].(string)
+				}
+				templates := []struct {
+					property string
+					tmpl     *template.Template
+				}{{"name", q.nameTemplate}, {"notes", q.notesTemplate}}
+				for _, t := range templates {
+					if t.tmpl == nil {
+						continue
+					}
+					var out bytes.Buffer
+					if err := t.tmpl.Execute(&out, data); err != nil {
+						return nil, fmt.Errorf("cue %s: %w", sourceCueLabel(original, i), err)
+					}
+					cue[t.property] = out.String()
+				}
+			}
+
+			if children, ok := cue["cues"].([]any); ok {
+				renderedChildren, err := render(children, original, fullNumber)
+				if err != nil {
+					return nil, err
+				}
+				cue["cues"] = renderedChildren
+			}
+		}
+		return rendered, nil
+	}
+
+	rendered, err := render(cues, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := source["cues"].([]any); ok {
+		source["cues"] = rendered
+	} else if nested != nil && cues != nil {
+		nested["cues"] = rendered
+	}
+	return source, nil
+}
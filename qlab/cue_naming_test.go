@@ -0,0 +1,80 @@
+package qlab
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestRenderCueTemplates tests rendering names and notes with per-cue context, leaving cue
+// lists and the source data alone
+func TestRenderCueTemplates(t *testing.T) {
+	workspace := &Workspace{}
+	if err := workspace.SetNameTemplate("[SQ {{.FullNumber}}] {{.Name}}"); err != nil {
+		t.Fatalf("SetNameTemplate failed: %v", err)
+	}
+	if err := workspace.SetNotesTemplate(`{{.Source.show}}: {{default "no department" (index .Cue "department")}}, in {{.ParentName}}`); err != nil {
+		t.Fatalf("SetNotesTemplate failed: %v", err)
+	}
+	if err := workspace.SetNameTemplate("{{.Name"); err == nil {
+		t.Error("Expected an invalid template to be refused")
+	}
+
+	thunder := map[string]any{"type": "audio", "number": "1", "name": "Thunder", "department": "Sound"}
+	source := map[string]any{"show": "Hamlet", "cues": []any{
+		map[string]any{"type": "list", "name": "Main", "cues": []any{
+			map[string]any{"type": "group", "number": "12", "name": "Storm", "cues": []any{thunder}},
+		}},
+	}}
+
+	rendered, err := workspace.RenderCueTemplates(source)
+	if err != nil {
+		t.Fatalf("RenderCueTemplates failed: %v", err)
+	}
+	list := rendered["cues"].([]any)[0].(map[string]any)
+	group := list["cues"].([]any)[0].(map[string]any)
+	cue := group["cues"].([]any)[0].(map[string]any)
+	if list["name"] != "Main" {
+		t.Errorf("Expected the cue list name kept, got %v", list["name"])
+	}
+	if group["name"] != "[SQ 12] Storm" || group["notes"] != "Hamlet: no department, in Main" {
+		t.Errorf("Unexpected group name %q and notes %q", group["name"], group["notes"])
+	}
+	if cue["name"] != "[SQ 12.1] Thunder" || cue["notes"] != "Hamlet: Sound, in Storm" {
+		t.Errorf("Unexpected cue name %q and notes %q", cue["name"], cue["notes"])
+	}
+	if thunder["name"] != "Thunder" {
+		t.Errorf("Expected the source data left alone, got %v", thunder["name"])
+	}
+}
+
+// TestTransmitRendersNames tests that rendered names are sent and that the next transmit of
+// the same source finds nothing to change
+func TestTransmitRendersNames(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+	workspace.SetCacheEnabled(false)
+	workspace.SetSkipInbox(true)
+	if err := workspace.SetNameTemplate("[{{upper .Type}} {{.Number}}] {{.Name}}"); err != nil {
+		t.Fatalf("SetNameTemplate failed: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "show.json")
+	source := map[string]any{"cues": []any{
+		map[string]any{"type": "memo", "number": "1", "name": "House open"},
+	}}
+
+	if _, err := workspace.TransmitWorkspaceData(path, source); err != nil {
+		t.Fatalf("First transmit failed: %v", err)
+	}
+	uniqueID, _ := workspace.lookupCueNumber("1")
+	if cue := mockServer.GetCue(uniqueID); cue == nil || cue.Name != "[MEMO 1] House open" {
+		t.Fatalf("Expected the rendered name sent, got %+v", cue)
+	}
+
+	comparison, err := workspace.TransmitWorkspaceData(path, source)
+	if err != nil {
+		t.Fatalf("Second transmit failed: %v", err)
+	}
+	if result := comparison.CueResults["1"]; result.Action != "skip" {
+		t.Errorf("Expected the rendered name to match, got %+v", result)
+	}
+}
@@ -0,0 +1,208 @@
+package qlab
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// cuePropertyKind is the value type of a mapped cue property
+type cuePropertyKind int
+
+const (
+	cuePropertyString cuePropertyKind = iota
+	cuePropertyInt
+	cuePropertyFloat
+	cuePropertyBool
+)
+
+// cuePropertySpec maps a cue data key to the QLab property of the same name
+type cuePropertySpec struct {
+	key   string
+	kind  cuePropertyKind
+	names map[string]int // Symbolic names accepted for integer properties, matched case-insensitively
+}
+
+// MIDI message types (messageType)
+var midiMessageTypes = map[string]int{"voice": 1, "msc": 2, "sysex": 3}
+
+// MIDI voice message status bytes (status)
+var midiStatuses = map[string]int{
+	"noteoff":         128,
+	"noteon":          144,
+	"keypressure":     160,
+	"controlchange":   176,
+	"programchange":   192,
+	"channelpressure": 208,
+	"pitchbend":       224,
+}
+
+// MIDI Show Control commands (command)
+var mscCommands = map[string]int{
+	"go":       1,
+	"stop":     2,
+	"resume":   3,
+	"timed_go": 4,
+	"load":     5,
+	"set":      6,
+	"fire":     7,
+	"all_off":  8,
+	"restore":  9,
+	"reset":    10,
+	"go_off":   11,
+}
+
+// midiCueProperties are the MIDI cue properties: patch, voice messages, MSC, and SysEx
+var midiCueProperties = []cuePropertySpec{
+	{key: "midiPatchName", kind: cuePropertyString},
+	{key: "midiPatchID", kind: cuePropertyString},
+	{key: "messageType", kind: cuePropertyInt, names: midiMessageTypes},
+	// Voice messages
+	{key: "status", kind: cuePropertyInt, names: midiStatuses},
+	{key: "channel", kind: cuePropertyInt},
+	{key: "byteOne", kind: cuePropertyInt},
+	{key: "byteTwo", kind: cuePropertyInt},
+	{key: "byteCombo", kind: cuePropertyInt},
+	{key: "doFade", kind: cuePropertyBool},
+	{key: "endValue", kind: cuePropertyInt},
+	// MIDI Show Control
+	{key: "command", kind: cuePropertyInt, names: mscCommands},
+	{key: "commandFormat", kind: cuePropertyInt},
+	{key: "deviceID", kind: cuePropertyInt},
+	{key: "qNumber", kind: cuePropertyString},
+	{key: "qList", kind: cuePropertyString},
+	{key: "qPath", kind: cuePropertyString},
+	{key: "macro", kind: cuePropertyInt},
+	{key: "controlNumber", kind: cuePropertyInt},
+	{key: "controlValue", kind: cuePropertyInt},
+	// SysEx
+	{key: "sysexMessage", kind: cuePropertyString},
+}
+
+// networkCueProperties are the Network (QLab 5) and OSC (QLab 4) cue properties
+var networkCueProperties = []cuePropertySpec{
+	{key: "networkPatchName", kind: cuePropertyString},
+	{key: "networkPatchID", kind: cuePropertyString},
+	{key: "patch", kind: cuePropertyInt},
+	{key: "messageType", kind: cuePropertyInt},
+	{key: "customString", kind: cuePropertyString},
+	{key: "udpString", kind: cuePropertyString},
+	{key: "qlabCommand", kind: cuePropertyInt},
+	{key: "qlabCueNumber", kind: cuePropertyString},
+}
+
+// midiFileCueProperties are the MIDI File cue properties; the file itself is set from fileTarget
+var midiFileCueProperties = []cuePropertySpec{
+	{key: "midiPatchName", kind: cuePropertyString},
+	{key: "midiPatchID", kind: cuePropertyString},
+	{key: "rate", kind: cuePropertyFloat},
+}
+
+// mappedCueProperties returns the property mapping for a cue type, or nil if it has none
+func mappedCueProperties(cueType string) []cuePropertySpec {
+	switch strings.ToLower(cueType) {
+	case CueTypeMIDI:
+		return midiCueProperties
+	case CueTypeNetwork, CueTypeOSC:
+		return networkCueProperties
+	case CueTypeMIDIFile:
+		return midiFileCueProperties
+	case CueTypeLight:
+		return lightCueProperties
+	case CueTypeScript:
+		return scriptCueProperties
+	case CueTypeDevamp:
+		return devampCueProperties
+	default:
+		return nil
+	}
+}
+
+// format converts a cue data value to the string sent to QLab
+func (spec cuePropertySpec) format(value any) (string, error) {
+	switch spec.kind {
+	case cuePropertyString:
+		s, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("%s must be a string, got %T", spec.key, value)
+		}
+		return s, nil
+
+	case cuePropertyBool:
+		if toBool(value) {
+			return "1", nil
+		}
+		return "0", nil
+
+	case cuePropertyInt:
+		if name, ok := value.(string); ok && spec.names != nil {
+			if n, ok := spec.names[strings.ToLower(name)]; ok {
+				return strconv.Itoa(n), nil
+			}
+		}
+		f, ok := toFloat(value)
+		if !ok || f != float64(int(f)) {
+			return "", fmt.Errorf("%s must be an integer, got %v", spec.key, value)
+		}
+		return strconv.Itoa(int(f)), nil
+
+	default:
+		f, ok := toFloat(value)
+		if !ok {
+			return "", fmt.Errorf("%s must be a number, got %v", spec.key, value)
+		}
+		return strconv.FormatFloat(f, 'g', -1, 64), nil
+	}
+}
+
+// setMappedCueProperties sets every mapped property present in cue data. Failures are
+// returned when strict and logged otherwise, so a bad value doesn't abandon a created cue.
+func (q *Workspace) setMappedCueProperties(uniqueID string, cueData map[string]any, specs []cuePropertySpec, strict bool) error {
+	for _, spec := range specs {
+		value, ok := cueData[spec.key]
+		if !ok || value == nil {
+			continue
+		}
+
+		formatted, err := spec.format(value)
+		if err == nil {
+			err = q.setCueProperty(uniqueID, spec.key, formatted)
+		}
+		if err != nil {
+			if strict {
+				return fmt.Errorf("failed to set %s: %w", spec.key, err)
+			}
+			q.log().Warnf("Failed to set %s for cue %s: %v", spec.key, uniqueID, err)
+		}
+	}
+	return nil
+}
+
+// cuePropertyKeys returns the keys of the mapped properties
+func cuePropertyKeys(specs []cuePropertySpec) []string {
+	keys := make([]string, len(specs))
+	for i, spec := range specs {
+		keys[i] = spec.key
+	}
+	return keys
+}
+
+// enrichMappedCue adds the mapped properties that /cueLists does not include from values
+func enrichMappedCue(cue map[string]any, values map[string]any, specs []cuePropertySpec) {
+	for _, spec := range specs {
+		value, ok := cueValue(values, spec.key)
+		if !ok {
+			continue
+		}
+		switch spec.kind {
+		case cuePropertyString:
+			cue[spec.key] = fmt.Sprintf("%v", value)
+		case cuePropertyBool:
+			cue[spec.key] = toBool(value)
+		default:
+			if f, ok := toFloat(value); ok {
+				cue[spec.key] = f
+			}
+		}
+	}
+}
@@ -0,0 +1,350 @@
+package qlab
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// NumberSlot is a source cue as a NumberingStrategy sees it
+type NumberSlot struct {
+	Cue          map[string]any // The source cue, not to be changed
+	Written      string         // Number as the source writes it, "" when unnumbered
+	ParentNumber string         // Number the enclosing group was given, "" at the top level
+	Index        int            // Position among its siblings
+	Previous     string         // Number given to the closest earlier numbered sibling
+	Next         string         // Full source number of the closest later numbered sibling
+	Absolute     bool           // Whether numbers are taken as written, without the group's prefix
+	Taken        func(number string) bool
+}
+
+// NumberingStrategy returns the full number a CueNumberAllocator gives a cue, "" to leave it
+// unnumbered. Unless numbers are absolute, a number without a decimal point given to a cue in
+// a numbered group is taken relative to the group, as in source data.
+type NumberingStrategy func(slot NumberSlot) string
+
+// PreserveSourceNumbers keeps the numbers the source gives, prefixing relative numbers with
+// their group's, which is how transmit numbers cues when no strategy is set
+func PreserveSourceNumbers(slot NumberSlot) string {
+	return slot.qualify(slot.Written)
+}
+
+// qualify returns a number as the slot's group qualifies it
+func (slot NumberSlot) qualify(number string) string {
+	if slot.Absolute {
+		return number
+	}
+	return qualifyCueNumber(slot.ParentNumber, number)
+}
+
+// AutoIncrementNumbers numbers the cues of each group in order as start, start+step and so
+// on under the group's number, whatever the source gives. With start 1 and step 1, top-level
+// cues are 1, 2, 3 and the cues in group 2 are 2.1, 2.2.
+func AutoIncrementNumbers(start, step float64) NumberingStrategy {
+	return func(slot NumberSlot) string {
+		number := renumberedNumber(start, step, slot.Index)
+		if slot.ParentNumber == "" {
+			return number
+		}
+		return slot.ParentNumber + "." + number
+	}
+}
+
+// FillNumberGaps keeps the numbers the source gives where they're free, and gives cues
+// without one, or whose number is taken, the first free number between the cues around
+// them: the next whole number after the previous cue's, or failing that the next tenth, then
+// hundredth. A cue between 3 and 5 becomes 4, one between 4 and 5 becomes 4.1.
+func FillNumberGaps(slot NumberSlot) string {
+	if number := slot.qualify(slot.Written); number != "" && !slot.Taken(number) {
+		return number
+	}
+
+	// Work in numbers relative to the group, so group 10's first gap is 10.1
+	relative := func(number string) (float64, bool) {
+		if slot.ParentNumber != "" {
+			number = strings.TrimPrefix(number, slot.ParentNumber+".")
+		}
+		value, err := strconv.ParseFloat(number, 64)
+		return value, err == nil
+	}
+	lower, _ := relative(slot.Previous)
+	upper, ok := relative(slot.Next)
+	if !ok || upper <= lower {
+		upper = math.Inf(1)
+	}
+	for _, step := range []float64{1, 0.1, 0.01, 0.001} {
+		for k := math.Floor(lower/step+1e-9) + 1; k*step < upper-1e-9; k++ {
+			number := renumberedNumber(0, step, int(k))
+			if slot.ParentNumber != "" {
+				number = slot.ParentNumber + "." + number
+			}
+			if !slot.Taken(number) {
+				return number
+			}
+		}
+	}
+	return ""
+}
+
+// CueNumberAllocation is the number a CueNumberAllocator gives a source cue
+type CueNumberAllocation struct {
+	Path     string // Location in the source, e.g. cues[2].cues[0]
+	Name     string
+	Written  string // Number as the source writes it
+	Number   string // Full number given, "" when the cue stays unnumbered
+	Conflict string // Path of an earlier cue given the same number
+	Reserved string // Holder of Number when it was reserved, e.g. the QLab cue the cue will match
+}
+
+// CueNumberAllocator numbers source cues with a NumberingStrategy, keeping track of the
+// numbers cues outside the source hold so strategies can avoid them and tools can preview
+// the numbers a transmit would give
+type CueNumberAllocator struct {
+	Strategy NumberingStrategy // nil for PreserveSourceNumbers
+	Absolute bool              // Numbers are full as written, as under SetAbsoluteCueNumbers
+	reserved map[string]string // Holder of each reserved number
+}
+
+// NewCueNumberAllocator returns an allocator numbering cues with a strategy
+func NewCueNumberAllocator(strategy NumberingStrategy) *CueNumberAllocator {
+	return &CueNumberAllocator{Strategy: strategy, reserved: make(map[string]string)}
+}
+
+// Reserve marks a number as held by something outside the source data, such as a QLab cue.
+// A source cue that writes the number itself is taken to be its holder and may keep it.
+func (a *CueNumberAllocator) Reserve(number, holder string) {
+	if a.reserved == nil {
+		a.reserved = make(map[string]string)
+	}
+	a.reserved[number] = holder
+}
+
+// Allocate returns the number each cue of source data would be given, in workspace order,
+// parents before their children. The data is left alone.
+func (a *CueNumberAllocator) Allocate(workspaceData map[string]any) []CueNumberAllocation {
+	return a.allocate(workspaceData, false)
+}
+
+// Apply returns a copy of source data with the numbers the strategy gives written in place
+// of the source's, and the allocations. Cues whose number doesn't change keep the form the
+// source writes it in.
+func (a *CueNumberAllocator) Apply(workspaceData map[string]any) (map[string]any, []CueNumberAllocation) {
+	applied, _ := cloneMergeValue(workspaceData).(map[string]any)
+	return applied, a.allocate(applied, true)
+}
+
+// allocate walks source data numbering its cues, writing the numbers into the cues if asked
+func (a *CueNumberAllocator) allocate(workspaceData map[string]any, write bool) []CueNumberAllocation {
+	strategy := a.Strategy
+	if strategy == nil {
+		strategy = PreserveSourceNumbers
+	}
+	qualify := qualifyCueNumber
+	if a.Absolute {
+		qualify = func(_, number string) string { return number }
+	}
+	var allocations []CueNumberAllocation
+	given := make(map[string]string) // Path of the cue given each number
+
+	var walk func(cues []any, parentNumber, path string)
+	walk = func(cues []any, parentNumber, path string) {
+		previous := ""
+		for i, cueData := range cues {
+			cue, ok := cueData.(map[string]any)
+			if !ok {
+				continue
+			}
+			allocation := CueNumberAllocation{Path: fmt.Sprintf("%s[%d]", path, i), Written: formatCueNumber(cue["number"])}
+			allocation.Name, _ = cue["name"].(string)
+			own := qualify(parentNumber, allocation.Written)
+
+			cueType, _ := cue["type"].(string)
+			switch strings.ToLower(cueType) {
+			case "list", "cuelist", "cue_list", CueTypeList:
+				// Cue lists keep whatever number they have; strategies number cues
+				allocation.Number = own
+			default:
+				slot := NumberSlot{Cue: cue, Written: allocation.Written, ParentNumber: parentNumber, Index: i, Previous: previous, Absolute: a.Absolute}
+				for _, nextData := range cues[i+1:] {
+					if next, ok := nextData.(map[string]any); ok {
+						if slot.Next = qualify(parentNumber, formatCueNumber(next["number"])); slot.Next != "" {
+							break
+						}
+					}
+				}
+				slot.Taken = func(number string) bool {
+					if _, ok := given[number]; ok {
+						return true
+					}
+					_, ok := a.reserved[number]
+					return ok && number != own
+				}
+				allocation.Number = qualify(parentNumber, strategy(slot))
+			}
+
+			if allocation.Number != "" {
+				if holder, ok := given[allocation.Number]; ok {
+					allocation.Conflict = holder
+				} else {
+					given[allocation.Number] = allocation.Path
+				}
+				allocation.Reserved = a.reserved[allocation.Number]
+				previous = allocation.Number
+			}
+			if write && allocation.Number != own {
+				if allocation.Number == "" {
+					delete(cue, "number")
+				} else {
+					cue["number"] = allocation.Number
+				}
+			}
+			allocations = append(allocations, allocation)
+
+			if children, ok := cue["cues"].([]any); ok {
+				walk(children, allocation.Number, allocation.Path+".cues")
+			}
+		}
+	}
+
+	if cues, ok := workspaceData["cues"].([]any); ok {
+		walk(cues, "", "cues")
+	} else if nested, ok := workspaceData["workspace"].(map[string]any); ok {
+		if cues, ok := nested["cues"].([]any); ok {
+			walk(cues, "", "workspace.cues")
+		}
+	}
+	return allocations
+}
+
+// SetNumberingStrategy sets the strategy TransmitWorkspaceData numbers source cues with
+// before comparing them, against the numbers QLab's cues hold. nil transmits the numbers
+// the source gives.
+func (q *Workspace) SetNumberingStrategy(strategy NumberingStrategy) {
+	q.numberingStrategy = strategy
+}
+
+// ReserveCueNumbers reserves every number the cues of the current QLab workspace hold in an
+// allocator, with the holding cue's uniqueID
+func (q *Workspace) ReserveCueNumbers(allocator *CueNumberAllocator) error {
+	data, err := q.getCueLists()
+	if err != nil {
+		return fmt.Errorf("failed to query cue lists: %w", err)
+	}
+	var reserve func(cues []any)
+	reserve = func(cues []any) {
+		for _, cueData := range cues {
+			cue, ok := cueData.(map[string]any)
+			if !ok {
+				continue
+			}
+			uniqueID, _ := cue["uniqueID"].(string)
+			if number := formatCueNumber(cue["number"]); number != "" {
+				allocator.Reserve(number, uniqueID)
+			}
+			if children, ok := cue["cues"].([]any); ok {
+				reserve(children)
+			}
+		}
+	}
+	for _, cueListData := range data {
+		if cueList, ok := cueListData.(map[string]any); ok {
+			children, _ := cueList["cues"].([]any)
+			reserve(children)
+		}
+	}
+	return nil
+}
+
+// PreviewCueNumbers returns the numbers a transmit of source data would give its cues with
+// a strategy, nil for the one set with SetNumberingStrategy, against the numbers QLab's cues
+// hold. Nothing is sent.
+func (q *Workspace) PreviewCueNumbers(workspaceData map[string]any, strategy NumberingStrategy) ([]CueNumberAllocation, error) {
+	if strategy == nil {
+		strategy = q.numberingStrategy
+	}
+	allocator := NewCueNumberAllocator(strategy)
+	allocator.Absolute = q.absoluteNumbers.Load()
+	if err := q.ReserveCueNumbers(allocator); err != nil {
+		return nil, err
+	}
+	return allocator.Allocate(UnwrapEnvelope(workspaceData)), nil
+}
+
+// SetAbsoluteCueNumbers sets whether cue numbers are taken as written. Normally a number
+// without a decimal point in a numbered group is relative to it, so 2 in group 1 is cue
+// 1.2; with absolute numbers it's cue 2, for sources that already give every cue its full
+// number. This applies to QLab's cues as well as the source's. WithAbsoluteNumbers turns it
+// on for a single transmit.
+func (q *Workspace) SetAbsoluteCueNumbers(absolute bool) {
+	q.absoluteNumbers.Store(absolute)
+}
+
+// WithAbsoluteNumbers takes the cue numbers of this transmit as written, without prefixing
+// relative numbers with their group's, as SetAbsoluteCueNumbers does for every transmit
+func WithAbsoluteNumbers() TransmitOption {
+	return func(o *transmitOptions) {
+		o.absoluteNumbers = true
+	}
+}
+
+// WithNumbering numbers the source cues of this transmit with a strategy, in place of the one
+// set with SetNumberingStrategy
+func WithNumbering(strategy NumberingStrategy) TransmitOption {
+	return func(o *transmitOptions) {
+		o.numbering = strategy
+	}
+}
+
+// cueKeyFunc identifies a source cue at a position as sourceCueKey does
+type cueKeyFunc func(cue map[string]any, parentNumber string, position int) (key string, fullNumber string)
+
+// sourceCueKey is the package's sourceCueKey, taking numbers as written under
+// SetAbsoluteCueNumbers
+func (q *Workspace) sourceCueKey(cue map[string]any, parentNumber string, position int) (string, string) {
+	if q.absoluteNumbers.Load() {
+		if number := formatCueNumber(cue["number"]); number != "" {
+			return number, number
+		}
+	}
+	return sourceCueKey(cue, parentNumber, position)
+}
+
+// fullCueNumber qualifies a cue number with its parent's, unless numbers are absolute
+func (q *Workspace) fullCueNumber(parentNumber, cueNumber string) string {
+	if q.absoluteNumbers.Load() {
+		return cueNumber
+	}
+	return qualifyCueNumber(parentNumber, cueNumber)
+}
+
+// formatCueNumber renders a cue number from CUE data, keeping the decimal of whole
+// numbers such as 1.0 that were decoded as floats
+func formatCueNumber(num any) string {
+	switch v := num.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		if v == float64(int64(v)) && v >= 0 && v <= 999 {
+			return fmt.Sprintf("%.1f", v)
+		}
+		return fmt.Sprintf("%g", v)
+	case int64:
+		return fmt.Sprintf("%d", v)
+	case int:
+		return fmt.Sprintf("%d", v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// qualifyCueNumber prefixes a relative cue number with its parent's; numbers with a
+// decimal point are already absolute
+func qualifyCueNumber(parentNumber, cueNumber string) string {
+	if parentNumber == "" || cueNumber == "" || strings.Contains(cueNumber, ".") {
+		return cueNumber
+	}
+	return parentNumber + "." + cueNumber
+}
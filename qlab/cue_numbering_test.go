@@ -0,0 +1,162 @@
+package qlab
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+// TestCueNumberAllocator tests the numbers each strategy gives top-level cues and the cues
+// of a group, around a reserved number
+func TestCueNumberAllocator(t *testing.T) {
+	source := map[string]any{"cues": []any{
+		map[string]any{"type": "memo", "number": "1", "name": "A"},
+		map[string]any{"type": "memo", "name": "B"},
+		map[string]any{"type": "memo", "number": "3", "name": "C"},
+		map[string]any{"type": "group", "number": "10", "name": "Group", "cues": []any{
+			map[string]any{"type": "memo", "number": "1", "name": "D"},
+			map[string]any{"type": "memo", "name": "E"},
+			map[string]any{"type": "memo", "number": "3", "name": "F"},
+		}},
+	}}
+
+	tests := []struct {
+		name     string
+		strategy NumberingStrategy
+		want     []string
+	}{
+		{"preserve", nil, []string{"1", "", "3", "10", "10.1", "", "10.3"}},
+		{"auto-increment", AutoIncrementNumbers(1, 1), []string{"1", "2", "3", "4", "4.1", "4.2", "4.3"}},
+		{"fill gaps", FillNumberGaps, []string{"1", "1.1", "3", "10", "10.1", "10.2", "10.3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allocator := NewCueNumberAllocator(tt.strategy)
+			allocator.Reserve("2", "QLAB-2")
+			allocations := allocator.Allocate(source)
+			if len(allocations) != len(tt.want) {
+				t.Fatalf("Expected %d allocations, got %+v", len(tt.want), allocations)
+			}
+			for i, want := range tt.want {
+				if allocations[i].Number != want || allocations[i].Conflict != "" {
+					t.Errorf("%s: expected %q, got %+v", allocations[i].Path, want, allocations[i])
+				}
+			}
+		})
+	}
+
+	applied, allocations := NewCueNumberAllocator(AutoIncrementNumbers(1, 1)).Apply(source)
+	if allocations[1].Reserved != "" {
+		t.Errorf("Expected no reservation without Reserve, got %q", allocations[1].Reserved)
+	}
+	group := applied["cues"].([]any)[3].(map[string]any)
+	if group["number"] != "4" || group["cues"].([]any)[1].(map[string]any)["number"] != "4.2" {
+		t.Errorf("Expected the group renumbered 4 with its second cue 4.2, got %v", group)
+	}
+	if _, ok := source["cues"].([]any)[1].(map[string]any)["number"]; ok {
+		t.Error("Expected the source data left alone")
+	}
+
+	duplicated := map[string]any{"cues": []any{
+		map[string]any{"type": "memo", "number": "5"},
+		map[string]any{"type": "memo", "number": "5"},
+	}}
+	allocations = NewCueNumberAllocator(nil).Allocate(duplicated)
+	if allocations[1].Conflict != "cues[0]" {
+		t.Errorf("Expected the second cue 5 to conflict with cues[0], got %+v", allocations[1])
+	}
+}
+
+// TestTransmitNumberingStrategy tests that a transmit numbers unnumbered cues with the
+// strategy set, and that a preview reports the QLab cues the numbers will match
+func TestTransmitNumberingStrategy(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+	workspace.SetCacheStore(NewMemoryCacheStore())
+	workspace.SetSkipInbox(true)
+	workspace.SetNumberingStrategy(FillNumberGaps)
+
+	source := map[string]any{"cues": []any{
+		map[string]any{"type": "memo", "number": "1", "name": "Places"},
+		map[string]any{"type": "memo", "name": "Standby"},
+		map[string]any{"type": "memo", "number": "2", "name": "Go"},
+	}}
+	if _, err := workspace.TransmitWorkspaceData(filepath.Join(t.TempDir(), "show.json"), source); err != nil {
+		t.Fatalf("Transmit failed: %v", err)
+	}
+	uniqueID, ok := workspace.lookupCueNumber("1.1")
+	if !ok {
+		t.Fatal("Expected the unnumbered cue created as 1.1")
+	}
+	if cue := mockServer.GetCue(uniqueID); cue == nil || cue.Name != "Standby" {
+		t.Errorf("Expected cue 1.1 to be Standby, got %+v", cue)
+	}
+
+	allocations, err := workspace.PreviewCueNumbers(source, AutoIncrementNumbers(1, 1))
+	if err != nil {
+		t.Fatalf("PreviewCueNumbers failed: %v", err)
+	}
+	firstID, _ := workspace.lookupCueNumber("1")
+	if len(allocations) != 3 || allocations[2].Number != "3" || allocations[0].Reserved != firstID || allocations[2].Reserved != "" {
+		t.Errorf("Expected 1, 2 and 3 with 1 matching QLab's cue %s, got %+v", firstID, allocations)
+	}
+}
+
+// TestTransmitAbsoluteNumbers tests that a transmit with absolute numbers creates the cues
+// of a group under the numbers they're written with, finds them again by those numbers, and
+// that a custom numbering function applies to a single transmit
+func TestTransmitAbsoluteNumbers(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+	workspace.SetCacheStore(NewMemoryCacheStore())
+	workspace.SetSkipInbox(true)
+	path := filepath.Join(t.TempDir(), "show.json")
+
+	source := map[string]any{"cues": []any{
+		map[string]any{"type": "group", "number": "1", "name": "Act 1", "cues": []any{
+			map[string]any{"type": "memo", "number": "2", "name": "Scene 1"},
+			map[string]any{"type": "memo", "number": "3", "name": "Scene 2"},
+		}},
+	}}
+	if _, err := workspace.TransmitWorkspaceData(path, source, WithAbsoluteNumbers()); err != nil {
+		t.Fatalf("Transmit failed: %v", err)
+	}
+	uniqueID, ok := workspace.lookupCueNumber("2")
+	if !ok {
+		t.Fatal("Expected the group's first cue created as 2")
+	}
+	if cue := mockServer.GetCue(uniqueID); cue == nil || cue.Name != "Scene 1" {
+		t.Errorf("Expected cue 2 to be Scene 1, got %+v", cue)
+	}
+	if _, ok := workspace.lookupCueNumber("1.2"); ok {
+		t.Error("Expected no cue 1.2")
+	}
+
+	comparison, err := workspace.TransmitWorkspaceData(path, source, WithAbsoluteNumbers())
+	if err != nil {
+		t.Fatalf("Second transmit failed: %v", err)
+	}
+	for key, result := range comparison.CueResults {
+		if result.Action == "create" {
+			t.Errorf("Expected cue %s found, got %s: %s", key, result.Action, result.Reason)
+		}
+	}
+
+	// Numbered in tens for this transmit only
+	tens := func(slot NumberSlot) string { return fmt.Sprintf("%d", (slot.Index+1)*10) }
+	memos := map[string]any{"cues": []any{
+		map[string]any{"type": "memo", "name": "Preset"},
+		map[string]any{"type": "memo", "name": "Warning"},
+	}}
+	if _, err := workspace.TransmitWorkspaceData(filepath.Join(t.TempDir(), "memos.json"), memos, WithNumbering(tens)); err != nil {
+		t.Fatalf("Numbered transmit failed: %v", err)
+	}
+	for _, number := range []string{"10", "20"} {
+		if _, ok := workspace.lookupCueNumber(number); !ok {
+			t.Errorf("Expected cue %s created", number)
+		}
+	}
+	if workspace.numberingStrategy != nil || workspace.absoluteNumbers.Load() {
+		t.Error("Expected the transmit options to leave the workspace's numbering alone")
+	}
+}
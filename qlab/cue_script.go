@@ -0,0 +1,33 @@
+package qlab
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// scriptCueProperties are the Script cue properties. The AppleScript source is sent and
+// read back exactly as given, line breaks and indentation included.
+var scriptCueProperties = []cuePropertySpec{
+	{key: "scriptSource", kind: cuePropertyString},
+}
+
+// scriptHash returns a short content hash of a script for comparison, so that a changed
+// script shows as two hashes rather than two copies of the whole source
+func scriptHash(source string) string {
+	if source == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(source))
+	return "sha256:" + hex.EncodeToString(sum[:])[:16] + " (" + lineCount(source) + ")"
+}
+
+// lineCount describes how many lines a script has
+func lineCount(source string) string {
+	lines := strings.Count(strings.TrimSuffix(source, "\n"), "\n") + 1
+	if lines == 1 {
+		return "1 line"
+	}
+	return strconv.Itoa(lines) + " lines"
+}
@@ -0,0 +1,63 @@
+package qlab
+
+// cueTrigger is one of a cue's triggers: the flag that arms it and the properties that
+// configure it, which only matter while it's armed
+type cueTrigger struct {
+	enable     string
+	properties []cuePropertySpec
+}
+
+// cueTriggers are the MIDI, hotkey, wall-clock, and timecode triggers every cue type has
+var cueTriggers = []cueTrigger{
+	{enable: "midiTrigger", properties: []cuePropertySpec{
+		{key: "midiTriggerStatus", kind: cuePropertyInt, names: midiStatuses},
+		{key: "midiTriggerChannel", kind: cuePropertyInt},
+		{key: "midiTriggerByteOne", kind: cuePropertyInt},
+		{key: "midiTriggerByteTwo", kind: cuePropertyInt},
+	}},
+	{enable: "hotkeyTrigger", properties: []cuePropertySpec{
+		{key: "hotkeyTriggerString", kind: cuePropertyString},
+	}},
+	{enable: "wallClockTrigger", properties: []cuePropertySpec{
+		{key: "wallClockHours", kind: cuePropertyInt},
+		{key: "wallClockMinutes", kind: cuePropertyInt},
+		{key: "wallClockSeconds", kind: cuePropertyInt},
+	}},
+	{enable: "timecodeTrigger", properties: []cuePropertySpec{
+		{key: "timecodeTriggerText", kind: cuePropertyString},
+	}},
+}
+
+// triggerCueProperties are all the trigger properties, each trigger's settings before the
+// flag that arms it so a trigger is never armed half configured
+var triggerCueProperties = func() []cuePropertySpec {
+	var specs []cuePropertySpec
+	for _, trigger := range cueTriggers {
+		specs = append(specs, trigger.properties...)
+		specs = append(specs, cuePropertySpec{key: trigger.enable, kind: cuePropertyBool})
+	}
+	return specs
+}()
+
+// triggerValueKeys are the trigger properties read with valuesForKeys
+var triggerValueKeys = cuePropertyKeys(triggerCueProperties)
+
+// setTriggerProperties sets the trigger properties given in cue data. Failures are
+// returned when strict and logged otherwise.
+func (q *Workspace) setTriggerProperties(uniqueID string, cueData map[string]any, strict bool) error {
+	return q.setMappedCueProperties(uniqueID, cueData, triggerCueProperties, strict)
+}
+
+// enrichTriggers adds the armed triggers and their settings from values read with
+// triggerValueKeys. Disarmed triggers are left out, so cues without triggers stay as
+// /cueLists reports them.
+func enrichTriggers(cue map[string]any, values map[string]any) {
+	for _, trigger := range cueTriggers {
+		value, ok := cueValue(values, trigger.enable)
+		if !ok || !toBool(value) {
+			continue
+		}
+		cue[trigger.enable] = true
+		enrichMappedCue(cue, values, trigger.properties)
+	}
+}
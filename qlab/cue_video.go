@@ -0,0 +1,143 @@
+package qlab
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// videoCueProperties are the scalar video cue properties, set by name
+var videoCueProperties = []cuePropertySpec{
+	{key: "layer", kind: cuePropertyInt},
+	{key: "fillStage", kind: cuePropertyBool},
+	{key: "preserveAspectRatio", kind: cuePropertyBool},
+	{key: "holdAtEnd", kind: cuePropertyBool},
+	{key: "rate", kind: cuePropertyFloat},
+	{key: "rotationType", kind: cuePropertyInt},
+}
+
+// videoFillModes maps the fillMode shorthand to fillStage and preserveAspectRatio
+var videoFillModes = map[string][2]bool{
+	"none":    {false, false}, // Native size
+	"stretch": {true, false},  // Fill the stage, ignoring aspect ratio
+	"fit":     {true, true},   // Fill the stage, preserving aspect ratio
+}
+
+// setVideoCueProperties sets the stage, geometry, fill, layer, hold, and rate properties of a
+// video cue. Failures are returned when strict and logged otherwise.
+func (q *Workspace) setVideoCueProperties(uniqueID string, cueData map[string]any, strict bool) error {
+	fail := func(what string, err error) error {
+		if strict {
+			return fmt.Errorf("failed to set %s: %v", what, err)
+		}
+		q.log().Warnf("Failed to set %s for video cue %s: %v", what, uniqueID, err)
+		return nil
+	}
+
+	// Stage assignment comes first; geometry is relative to the stage
+	if stageName, ok := cueData["stageName"].(string); ok && stageName != "" {
+		if err := q.setCueProperty(uniqueID, "stageName", stageName); err != nil {
+			if err := fail("stage name", err); err != nil {
+				return err
+			}
+		}
+	} else if stageID, ok := cueData["stageID"].(string); ok && stageID != "" {
+		if err := q.setCueProperty(uniqueID, "stageID", stageID); err != nil {
+			if err := fail("stage ID", err); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Expand the fillMode shorthand unless the flags are given explicitly
+	properties := cueData
+	if mode, ok := cueData["fillMode"].(string); ok && mode != "" {
+		flags, known := videoFillModes[strings.ToLower(mode)]
+		if !known {
+			if err := fail("fill mode", fmt.Errorf("unknown fill mode %q", mode)); err != nil {
+				return err
+			}
+		} else {
+			properties = make(map[string]any, len(cueData)+2)
+			for k, v := range cueData {
+				properties[k] = v
+			}
+			if _, ok := properties["fillStage"]; !ok {
+				properties["fillStage"] = flags[0]
+			}
+			if _, ok := properties["preserveAspectRatio"]; !ok {
+				properties["preserveAspectRatio"] = flags[1]
+			}
+		}
+	}
+	if err := q.setMappedCueProperties(uniqueID, properties, videoCueProperties, strict); err != nil {
+		return err
+	}
+
+	// Geometry
+	if opacity, ok := cueData["opacity"].(float64); ok && opacity >= 0 {
+		if err := q.setCueProperty(uniqueID, "opacity", strconv.FormatFloat(opacity, 'g', -1, 64)); err != nil {
+			if err := fail("opacity", err); err != nil {
+				return err
+			}
+		}
+	}
+	for _, property := range []string{"translation", "scale"} {
+		if pair, ok := cueData[property].([]any); ok && len(pair) == 2 {
+			x, _ := toFloat(pair[0])
+			y, _ := toFloat(pair[1])
+			if err := q.setCuePropertyWithArgs(uniqueID, property, float32(x), float32(y)); err != nil {
+				if err := fail(property, err); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if rotation, ok := toFloat(cueData["rotation"]); ok {
+		if err := q.setCueProperty(uniqueID, "rotation", strconv.FormatFloat(rotation, 'g', -1, 64)); err != nil {
+			if err := fail("rotation", err); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// geometryValueKeys are the geometry properties read with valuesForKeys
+var geometryValueKeys = []string{"opacity", "rotation", "translation", "scale"}
+
+// videoValueKeys are the video properties read with valuesForKeys
+var videoValueKeys = slices.Concat([]string{"stageName"}, cuePropertyKeys(videoCueProperties), geometryValueKeys)
+
+// enrichVideoCue adds the video properties that /cueLists does not include from values
+// read with videoValueKeys
+func enrichVideoCue(cue map[string]any, values map[string]any) {
+	if stageName, ok := cueValue(values, "stageName"); ok {
+		cue["stageName"] = fmt.Sprintf("%v", stageName)
+	}
+	enrichMappedCue(cue, values, videoCueProperties)
+	enrichGeometry(cue, values)
+}
+
+// enrichGeometry adds opacity, rotation, translation, and scale from values
+func enrichGeometry(cue map[string]any, values map[string]any) {
+	if opacity, ok := cueValue(values, "opacity"); ok {
+		if f, ok := toFloat(opacity); ok {
+			cue["opacity"] = f
+		}
+	}
+	if rotation, ok := cueValue(values, "rotation"); ok {
+		if f, ok := toFloat(rotation); ok && f != 0 {
+			cue["rotation"] = f
+		}
+	}
+	for _, property := range []string{"translation", "scale"} {
+		if value, ok := cueValue(values, property); ok {
+			if pair := toPair(value); pair != nil {
+				cue[property] = pair
+			}
+		}
+	}
+}
@@ -7,6 +7,8 @@ import (
 
 // WriteCueFile generates a CUE file string from a workspace name and cues
 // It ensures all cues conform to the schema defined in lib/cj/qlab_workspace.cue
+//
+// This API is stable.
 func WriteCueFile(workspaceName string, cues []Cue, comment string) string {
 	var builder strings.Builder
 	builder.WriteString("package qlab\n\n")
@@ -68,6 +70,11 @@ func writeCue(builder *strings.Builder, c Cue, indent int) {
 		fmt.Fprintf(builder, "%s\ttext: %q\n", indentStr, c.Text)
 	}
 
+	// Script source (optional); quoting keeps its line breaks and indentation
+	if c.ScriptSource != "" {
+		fmt.Fprintf(builder, "%s\tscriptSource: %q\n", indentStr, c.ScriptSource)
+	}
+
 	// Text formatting colors (optional)
 	if len(c.TextColor) == 4 {
 		fmt.Fprintf(builder, "%s\t\"text/format/color\": [%.1f, %.1f, %.1f, %.1f]\n",
@@ -98,6 +105,29 @@ func writeCue(builder *strings.Builder, c Cue, indent int) {
 	if c.Opacity > 0 && c.Opacity <= 1.0 {
 		fmt.Fprintf(builder, "%s\topacity: %.1f\n", indentStr, c.Opacity)
 	}
+	if len(c.Scale) == 2 {
+		fmt.Fprintf(builder, "%s\tscale: [%g, %g]\n", indentStr, c.Scale[0], c.Scale[1])
+	}
+	if c.Rotation != 0 {
+		fmt.Fprintf(builder, "%s\trotation: %g\n", indentStr, c.Rotation)
+	}
+
+	// Video properties (optional)
+	if c.Layer != 0 {
+		fmt.Fprintf(builder, "%s\tlayer: %d\n", indentStr, c.Layer)
+	}
+	if c.FillStage {
+		fmt.Fprintf(builder, "%s\tfillStage: true\n", indentStr)
+	}
+	if c.PreserveAspectRatio {
+		fmt.Fprintf(builder, "%s\tpreserveAspectRatio: true\n", indentStr)
+	}
+	if c.HoldAtEnd {
+		fmt.Fprintf(builder, "%s\tholdAtEnd: true\n", indentStr)
+	}
+	if c.Rate > 0 {
+		fmt.Fprintf(builder, "%s\trate: %g\n", indentStr, c.Rate)
+	}
 
 	// FileTarget (optional, defaults to "")
 	if c.FileTarget != "" {
@@ -133,6 +163,9 @@ func writeCue(builder *strings.Builder, c Cue, indent int) {
 	if c.CueTargetNumber != "" {
 		fmt.Fprintf(builder, "%s\tcueTargetNumber: %q\n", indentStr, c.CueTargetNumber)
 	}
+	if c.CueTargetName != "" {
+		fmt.Fprintf(builder, "%s\tcueTargetName: %q\n", indentStr, c.CueTargetName)
+	}
 
 	// Geometry parameter enables (for fade cues)
 	if c.DoOpacity {
@@ -148,6 +181,23 @@ func writeCue(builder *strings.Builder, c Cue, indent int) {
 		fmt.Fprintf(builder, "%s\tdoRotation: true\n", indentStr)
 	}
 
+	// Fade targets
+	if len(c.Levels) > 0 {
+		levels := make([]string, 0, len(c.Levels))
+		for _, level := range c.Levels {
+			if len(level) == 3 {
+				levels = append(levels, fmt.Sprintf("[%g, %g, %g]", level[0], level[1], level[2]))
+			}
+		}
+		fmt.Fprintf(builder, "%s\tlevels: [%s]\n", indentStr, strings.Join(levels, ", "))
+	}
+	if c.StopTargetWhenDone {
+		fmt.Fprintf(builder, "%s\tstopTargetWhenDone: true\n", indentStr)
+	}
+
+	// Unrecognized properties are written back unchanged
+	writeExtraProperties(builder, c.Extra, indentStr)
+
 	// Write nested cues if present
 	if len(c.Cues) > 0 {
 		builder.WriteString(indentStr + "\tcues: [\n")
@@ -162,6 +212,8 @@ func writeCue(builder *strings.Builder, c Cue, indent int) {
 
 // NormalizeCue ensures a cue has all required fields with proper defaults
 // This ensures compatibility with the CUE schema
+//
+// This API is stable.
 func NormalizeCue(c *Cue) {
 	// Type is required - ensure it's not empty
 	if c.Type == "" {
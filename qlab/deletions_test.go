@@ -0,0 +1,106 @@
+package qlab
+
+import "testing"
+
+// deletionTestWorkspace builds QLab-format workspace data with the given cues in each cue list
+func deletionTestWorkspace(lists map[string][]any) map[string]any {
+	var data []any
+	for _, name := range []string{"Main", "Other", cuejitsuInboxName} {
+		data = append(data, map[string]any{"name": name, "type": "cue_list", "cues": lists[name]})
+	}
+	return map[string]any{"data": data}
+}
+
+// TestDetectDeletions tests which cues removed from the source are scheduled for deletion
+func TestDetectDeletions(t *testing.T) {
+	memo := func(number, name string) map[string]any {
+		return map[string]any{"type": "memo", "number": number, "name": name, "uniqueID": "id-" + number}
+	}
+	group := map[string]any{"type": "group", "number": "3", "name": "Scene", "uniqueID": "id-3", "cues": []any{memo("3.1", "Inside")}}
+	// Group 4 was removed from the source after a cue was added to it in QLab
+	tracked := map[string]any{"type": "group", "number": "4", "name": "Act", "uniqueID": "id-4", "cues": []any{memo("4.1", "Transmitted")}}
+	extended := map[string]any{"type": "group", "number": "4", "name": "Act", "uniqueID": "id-4", "cues": []any{memo("4.1", "Transmitted"), memo("4.2", "Added in QLab")}}
+	// Cue 6 was deleted in QLab and another cue given its number
+	replaced := map[string]any{"type": "memo", "number": "6", "name": "Reused number", "uniqueID": "id-new-6"}
+	cached := deletionTestWorkspace(map[string][]any{
+		"Main":            {memo("1", "Keep"), memo("2", "Removed"), group, tracked, memo("6", "Deleted in QLab")},
+		"Other":           {memo("9", "Unmanaged")},
+		cuejitsuInboxName: {memo("20", "Staged")},
+	})
+	current := deletionTestWorkspace(map[string][]any{
+		"Main":            {memo("1", "Keep"), memo("2", "Renamed in QLab"), group, extended, memo("5", "Added in QLab"), replaced},
+		"Other":           {memo("9", "Unmanaged")},
+		cuejitsuInboxName: {memo("20", "Staged")},
+	})
+	source := map[string]any{"cues": []any{
+		map[string]any{"type": "list", "name": "Main", "cues": []any{memo("1", "Keep")}},
+	}}
+
+	workspace := &Workspace{}
+	comparison := &ThreeWayComparison{
+		CueResults:      make(map[string]*CueChangeResult),
+		HasCache:        true,
+		HasQLabData:     true,
+		CurrentQLabData: current,
+	}
+	workspace.detectDeletions(comparison, source,
+		workspace.indexCuesFromWorkspace(source),
+		workspace.indexCuesFromWorkspace(cached),
+		workspace.indexCuesFromWorkspace(current))
+
+	deletions := sortedResultKeys(comparison.CueResults, "delete")
+	want := []string{"2", "20", "3", "4.1"}
+	if len(deletions) != len(want) {
+		t.Fatalf("Expected deletions %v, got %v", want, deletions)
+	}
+	for i := range want {
+		if deletions[i] != want[i] {
+			t.Fatalf("Expected deletions %v, got %v", want, deletions)
+		}
+	}
+	if comparison.CueResults["3"].ExistingID != "id-3" {
+		t.Errorf("Expected the group's QLab ID, got %q", comparison.CueResults["3"].ExistingID)
+	}
+
+	// Only the cue modified in QLab needs a decision
+	conflicts := deletionConflicts(comparison)
+	if len(conflicts) != 1 || conflicts[0].CueNumber != "2" || conflicts[0].ConflictType != ConflictDeletedInSource {
+		t.Fatalf("Expected one deletion conflict for cue 2, got %+v", conflicts)
+	}
+
+	ApplyResolutions(comparison, map[string]ConflictResolutionChoice{"2": ChoiceUseSource, "20": ChoiceKeepQLab})
+	if comparison.CueResults["2"].Action != "delete" {
+		t.Errorf("Expected using the source to keep the deletion, got %s", comparison.CueResults["2"].Action)
+	}
+	if comparison.CueResults["20"].Action != "skip" {
+		t.Errorf("Expected keeping QLab to cancel the deletion, got %s", comparison.CueResults["20"].Action)
+	}
+}
+
+// TestApplyDeletions tests that scheduled deletions are sent to QLab
+func TestApplyDeletions(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+
+	removed, err := workspace.createCueWithoutTarget(map[string]any{"type": "memo", "name": "Removed"}, "2")
+	if err != nil {
+		t.Fatalf("Failed to create cue: %v", err)
+	}
+	kept, err := workspace.createCueWithoutTarget(map[string]any{"type": "memo", "name": "Kept"}, "1")
+	if err != nil {
+		t.Fatalf("Failed to create cue: %v", err)
+	}
+
+	results := map[string]*CueChangeResult{
+		"1": {Action: "skip", ExistingID: kept},
+		"2": {Action: "delete", ExistingID: removed},
+	}
+	if err := workspace.applyDeletions(results); err != nil {
+		t.Fatalf("applyDeletions failed: %v", err)
+	}
+	if mockServer.GetCue(removed) != nil {
+		t.Error("Expected the removed cue to be deleted")
+	}
+	if mockServer.GetCue(kept) == nil {
+		t.Error("Expected the skipped cue to remain")
+	}
+}
@@ -0,0 +1,113 @@
+package qlab
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestDiffWorkspaces tests diffing QLab data against a snapshot of another machine
+func TestDiffWorkspaces(t *testing.T) {
+	showMachine := map[string]any{
+		"data": []any{
+			map[string]any{
+				"name": "Main Cue List",
+				"type": "Cue List",
+				"cues": []any{
+					map[string]any{"uniqueID": "A-1", "number": "1", "name": "Preshow", "type": "Audio", "fileTarget": "/show/preshow.wav", "preWait": 0.0},
+					map[string]any{"uniqueID": "A-2", "number": "2", "name": "House Out", "type": "Fade", "duration": 5.0, "armed": true},
+					map[string]any{"uniqueID": "A-3", "number": "3", "name": "Act One", "type": "Group", "cues": []any{
+						map[string]any{"uniqueID": "A-4", "number": "3.1", "name": "Sting", "type": "Audio"},
+					}},
+					map[string]any{"uniqueID": "A-5", "number": "4", "name": "Blackout", "type": "Audio"},
+				},
+			},
+		},
+	}
+	backup := map[string]any{
+		"version": 1.0,
+		"cueLists": []any{
+			map[string]any{
+				"name": "Main Cue List",
+				"type": "list",
+				"cues": []any{
+					map[string]any{"uniqueID": "B-1", "number": "1", "name": "Preshow", "type": "audio", "fileTarget": "/backup/preshow.wav"},
+					map[string]any{"uniqueID": "B-2", "number": "2", "name": "House Out", "type": "fade", "duration": "8"},
+					map[string]any{"uniqueID": "B-3", "number": "3", "name": "Act One", "type": "group", "cues": []any{}},
+					map[string]any{"uniqueID": "B-4", "number": "3.1", "name": "Sting", "type": "audio"},
+					map[string]any{"uniqueID": "B-6", "number": "5", "name": "Curtain Call", "type": "audio"},
+				},
+			},
+		},
+	}
+
+	diff, err := DiffWorkspaces(showMachine, backup)
+	if err != nil {
+		t.Fatalf("DiffWorkspaces failed: %v", err)
+	}
+
+	if len(diff.Added) != 1 || diff.Added[0].Key != "5" || diff.Added[0].CueList != "Main Cue List" {
+		t.Errorf("Expected cue 5 added to Main Cue List, got %+v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Key != "4" {
+		t.Errorf("Expected cue 4 removed, got %+v", diff.Removed)
+	}
+
+	// Unique IDs, armed, zero timings, and the directory of media files aren't differences
+	if len(diff.Changed) != 2 {
+		t.Fatalf("Expected cues 2 and 3.1 changed, got %+v", diff.Changed)
+	}
+	houseOut := diff.Changed[0]
+	if houseOut.Key != "2" || len(houseOut.Fields) != 1 || houseOut.Fields[0].Field != "duration" {
+		t.Errorf("Expected only the duration of cue 2 to change, got %+v", houseOut)
+	}
+	sting := diff.Changed[1]
+	if sting.Key != "3.1" || len(sting.Fields) != 1 || sting.Fields[0].Field != "parent" {
+		t.Fatalf("Expected cue 3.1 to have moved, got %+v", sting)
+	}
+	if sting.Fields[0].A != "Main Cue List > 3" || sting.Fields[0].B != "Main Cue List" {
+		t.Errorf("Unexpected locations for cue 3.1: %+v", sting.Fields[0])
+	}
+
+	report := diff.String()
+	for _, line := range []string{
+		"1 added, 1 removed, 2 changed",
+		`+ [5] audio "Curtain Call" in Main Cue List`,
+		`- [4] audio "Blackout" in Main Cue List`,
+		`    duration: "5" -> "8"`,
+	} {
+		if !strings.Contains(report, line) {
+			t.Errorf("Report is missing %q:\n%s", line, report)
+		}
+	}
+}
+
+// TestDiffWorkspacesSourceData tests diffing CUE source data, whose cue lists are list cues
+func TestDiffWorkspacesSourceData(t *testing.T) {
+	source := map[string]any{
+		"cues": []any{
+			map[string]any{"type": "list", "name": "Main Cue List", "cues": []any{
+				map[string]any{"number": "1", "name": "Preshow", "type": "audio"},
+			}},
+		},
+	}
+	qlabData := map[string]any{
+		"data": []any{
+			map[string]any{"name": "Main Cue List", "cues": []any{
+				map[string]any{"uniqueID": "A-1", "number": "1", "name": "Preshow", "type": "Audio"},
+			}},
+		},
+	}
+
+	diff, err := DiffWorkspaces(source, qlabData)
+	if err != nil {
+		t.Fatalf("DiffWorkspaces failed: %v", err)
+	}
+	if !diff.Empty() {
+		t.Errorf("Expected no differences, got:\n%s", diff)
+	}
+
+	if _, err := DiffWorkspaces(source, map[string]any{"status": "ok"}); !errors.Is(err, ErrNoCueData) {
+		t.Errorf("Expected ErrNoCueData, got %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package qlab
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DisconnectReason says why the workspace decided QLab was disconnected
+type DisconnectReason string
+
+const (
+	DisconnectTimeoutStorm       DisconnectReason = "timeout_storm"       // Consecutive messages went unanswered
+	DisconnectBadPasscode        DisconnectReason = "badpass"             // QLab stopped accepting the passcode
+	DisconnectWorkspaceClosed    DisconnectReason = "workspace_closed"    // QLab reported that the workspace closed
+	DisconnectNetworkUnreachable DisconnectReason = "network_unreachable" // Messages couldn't be sent at all
+)
+
+// Default debounce for disconnect notifications; the interval doubles with each
+// notification during an outage, up to the maximum
+const (
+	DefaultDisconnectBackoff    = time.Second
+	DefaultMaxDisconnectBackoff = time.Minute
+)
+
+// DisconnectEvent describes a detected disconnect
+type DisconnectEvent struct {
+	Reason            DisconnectReason
+	Address           string // Address of the message that failed, empty for workspace-closed updates
+	ConsecutiveErrors int    // Failed messages in a row when the disconnect was detected
+	Notifications     int    // Notifications so far in this outage, including this one
+	Suppressed        int    // Disconnects debounced since the previous notification
+	Err               error  // Underlying error, when there is one
+}
+
+// disconnectState debounces disconnect notifications with exponential backoff
+type disconnectState struct {
+	mu            sync.Mutex
+	initial       time.Duration
+	max           time.Duration
+	backoff       time.Duration // Quiet period required before the next notification
+	lastNotified  time.Time
+	notifications int
+	suppressed    int
+}
+
+// OnDisconnectEvent sets a callback that receives the reason and details of each
+// detected disconnect. Repeated disconnects are debounced: after each notification the
+// next is held back for an interval that doubles up to SetDisconnectBackoff's maximum.
+// A callback set with OnDisconnect is called for the same notifications.
+func (q *Workspace) OnDisconnectEvent(callback func(DisconnectEvent)) {
+	q.disconnects.mu.Lock()
+	defer q.disconnects.mu.Unlock()
+	q.onDisconnectEvent = callback
+}
+
+// SetDisconnectBackoff sets the initial and maximum debounce interval between disconnect
+// notifications (defaults DefaultDisconnectBackoff and DefaultMaxDisconnectBackoff)
+func (q *Workspace) SetDisconnectBackoff(initial, limit time.Duration) {
+	q.disconnects.mu.Lock()
+	defer q.disconnects.mu.Unlock()
+	q.disconnects.initial = initial
+	q.disconnects.max = limit
+	q.disconnects.backoff = 0
+}
+
+// notifyDisconnect starts reconnecting if that's enabled, and reports a disconnect to the
+// callbacks unless it falls within the backoff interval of the previous notification
+func (q *Workspace) notifyDisconnect(reason DisconnectReason, address string, err error) {
+	q.startReconnect(reason)
+	q.disconnects.mu.Lock()
+	onDisconnect, onDisconnectEvent := q.onDisconnect, q.onDisconnectEvent
+	q.disconnects.mu.Unlock()
+	if onDisconnect == nil && onDisconnectEvent == nil {
+		return
+	}
+	event, ok := q.disconnects.next(time.Now())
+	if !ok {
+		q.log().Debugf("Debounced disconnect notification (%s on %s)", reason, address)
+		return
+	}
+	event.Reason = reason
+	event.Address = address
+	event.ConsecutiveErrors = int(q.consecutiveErrors.Load())
+	event.Err = err
+
+	q.log().Warnf("QLab appears to be disconnected: %s", reason)
+	if onDisconnect != nil {
+		onDisconnect()
+	}
+	if onDisconnectEvent != nil {
+		onDisconnectEvent(event)
+	}
+}
+
+// next decides whether a disconnect detected at now is notified, advancing the backoff
+func (s *disconnectState) next(now time.Time) (DisconnectEvent, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	initial, limit := s.initial, s.max
+	if initial <= 0 {
+		initial = DefaultDisconnectBackoff
+	}
+	if limit < initial {
+		limit = max(DefaultMaxDisconnectBackoff, initial)
+	}
+
+	// A quiet spell longer than the maximum backoff ends the outage
+	if s.lastNotified.IsZero() || now.Sub(s.lastNotified) > limit+s.backoff {
+		s.backoff = 0
+		s.notifications = 0
+	}
+	if s.backoff > 0 && now.Sub(s.lastNotified) < s.backoff {
+		s.suppressed++
+		return DisconnectEvent{}, false
+	}
+
+	if s.backoff == 0 {
+		s.backoff = initial
+	} else {
+		s.backoff = min(s.backoff*2, limit)
+	}
+	s.lastNotified = now
+	s.notifications++
+	event := DisconnectEvent{Notifications: s.notifications, Suppressed: s.suppressed}
+	s.suppressed = 0
+	return event, true
+}
+
+// isAuthFailureReply reports whether a reply says the passcode was rejected
+func isAuthFailureReply(reply []any) bool {
+	if len(reply) == 0 {
+		return false
+	}
+	replyStr, ok := reply[0].(string)
+	if !ok || (!strings.Contains(replyStr, "badpass") && !strings.Contains(replyStr, "denied")) {
+		return false
+	}
+	return errors.Is(newQLabError("", replyStr), ErrAuthFailed)
+}
+
+// isWorkspaceClosedUpdate reports whether an update says this workspace was closed
+func (q *Workspace) isWorkspaceClosedUpdate(address string) bool {
+	return q.workspace_id != "" && address == "/update/workspace/"+q.workspace_id+"/disconnect"
+}
@@ -0,0 +1,116 @@
+package qlab
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestDisconnectBackoff tests that repeated disconnects are debounced with a doubling interval
+func TestDisconnectBackoff(t *testing.T) {
+	state := &disconnectState{initial: time.Second, max: 4 * time.Second}
+	start := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		offset   time.Duration
+		notified bool
+	}{
+		{0, true},                       // First disconnect, backoff becomes 1s
+		{500 * time.Millisecond, false}, // Within 1s
+		{1500 * time.Millisecond, true}, // Backoff becomes 2s
+		{3 * time.Second, false},        // Within 2s
+		{3600 * time.Millisecond, true}, // Backoff becomes 4s
+		{7 * time.Second, false},        // Within 4s
+		{7700 * time.Millisecond, true}, // Backoff stays at the 4s maximum
+		{20 * time.Second, true},        // Quiet for longer than the maximum: a new outage
+	}
+	var events []DisconnectEvent
+	for i, step := range steps {
+		event, ok := state.next(start.Add(step.offset))
+		if ok != step.notified {
+			t.Fatalf("step %d: expected notified=%t", i, step.notified)
+		}
+		if ok {
+			events = append(events, event)
+		}
+	}
+
+	if events[1].Suppressed != 1 || events[1].Notifications != 2 {
+		t.Errorf("Expected second notification to count one suppressed disconnect, got %+v", events[1])
+	}
+	if last := events[len(events)-1]; last.Notifications != 1 || last.Suppressed != 0 {
+		t.Errorf("Expected a new outage to restart the count, got %+v", last)
+	}
+}
+
+// TestDisconnectEventReasons tests the reasons reported for unanswered messages and closed workspaces
+func TestDisconnectEventReasons(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+	workspace.SetDisconnectBackoff(time.Millisecond, time.Millisecond)
+
+	var mu sync.Mutex
+	var events []DisconnectEvent
+	legacyCalls := 0
+	workspace.OnDisconnect(func() { legacyCalls++ })
+	workspace.OnDisconnectEvent(func(event DisconnectEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	})
+
+	// Connect, then send two messages the mock never answers
+	if _, err := workspace.queryVersion(); err != nil {
+		t.Fatalf("queryVersion failed: %v", err)
+	}
+	unanswered := workspace.GetAddress("/noSuchCommand")
+	workspace.Send(unanswered, "")
+	workspace.Send(unanswered, "")
+
+	if len(events) != 1 || legacyCalls != 1 {
+		t.Fatalf("Expected one disconnect notification, got %+v (legacy %d)", events, legacyCalls)
+	}
+	event := events[0]
+	if event.Reason != DisconnectTimeoutStorm || event.Address != unanswered || event.ConsecutiveErrors != 2 || !errors.Is(event.Err, ErrTimeout) {
+		t.Errorf("Expected timeout storm on %s after 2 errors, got %+v", unanswered, event)
+	}
+
+	// Reconnect, then QLab reports that the workspace closed
+	time.Sleep(5 * time.Millisecond)
+	if _, err := workspace.queryVersion(); err != nil {
+		t.Fatalf("queryVersion failed: %v", err)
+	}
+	if err := mockServer.SendUpdate("/update/workspace/" + mockServer.GetWorkspaceID() + "/disconnect"); err != nil {
+		t.Fatalf("SendUpdate failed: %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		n := len(events)
+		mu.Unlock()
+		if n == 2 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 2 || events[1].Reason != DisconnectWorkspaceClosed {
+		t.Errorf("Expected workspace closed notification, got %+v", events)
+	}
+}
+
+// TestIsAuthFailureReply tests detection of rejected passcodes in replies
+func TestIsAuthFailureReply(t *testing.T) {
+	if !isAuthFailureReply([]any{`{"status": "ok", "data": "badpass"}`}) {
+		t.Error("Expected badpass data to be an auth failure")
+	}
+	if !isAuthFailureReply([]any{`{"status": "denied"}`}) {
+		t.Error("Expected denied status to be an auth failure")
+	}
+	if isAuthFailureReply([]any{`{"status": "ok", "data": "badpass cue"}`}) || isAuthFailureReply(nil) {
+		t.Error("Expected ordinary replies not to be auth failures")
+	}
+}
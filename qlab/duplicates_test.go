@@ -0,0 +1,165 @@
+package qlab
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// TestFindDuplicateCues tests that duplicate numbers and position keys are reported with their paths
+func TestFindDuplicateCues(t *testing.T) {
+	workspaceData := map[string]any{
+		"cues": []any{
+			map[string]any{"type": "audio", "number": "1", "name": "First"},
+			map[string]any{"type": "group", "number": "2", "name": "Group", "cues": []any{
+				map[string]any{"type": "audio", "number": "1", "name": "Child"},
+			}},
+			map[string]any{"type": "audio", "number": "1", "name": "Copy of First"},
+			map[string]any{"type": "group", "name": "Unnumbered", "cues": []any{
+				map[string]any{"type": "memo", "name": "Note"},
+			}},
+		},
+	}
+
+	duplicates := FindDuplicateCues(workspaceData)
+
+	// "1" appears twice at the top level; the child is "2.1"; "@0[memo:Note]" collides with nothing
+	if len(duplicates) != 1 {
+		t.Fatalf("Expected 1 duplicate, got %d: %+v", len(duplicates), duplicates)
+	}
+	dup := duplicates[0]
+	if dup.Identifier != "1" {
+		t.Errorf("Expected duplicate identifier '1', got %q", dup.Identifier)
+	}
+	if len(dup.Paths) != 2 || dup.Paths[0] != "cues[0]" || dup.Paths[1] != "cues[2]" {
+		t.Errorf("Unexpected paths: %v", dup.Paths)
+	}
+}
+
+// TestFindDuplicateCuesPositionKeys tests that unnumbered cues colliding on position keys are reported
+func TestFindDuplicateCuesPositionKeys(t *testing.T) {
+	workspaceData := map[string]any{
+		"workspace": map[string]any{
+			"cues": []any{
+				map[string]any{"type": "memo", "name": "Note"},
+				map[string]any{"type": "group", "name": "Unnumbered", "cues": []any{
+					map[string]any{"type": "memo", "name": "Note"},
+				}},
+			},
+		},
+	}
+
+	duplicates := FindDuplicateCues(workspaceData)
+	if len(duplicates) != 1 {
+		t.Fatalf("Expected 1 duplicate, got %d: %+v", len(duplicates), duplicates)
+	}
+	if duplicates[0].Identifier != "@0[memo:Note]" {
+		t.Errorf("Expected position key duplicate, got %q", duplicates[0].Identifier)
+	}
+	if duplicates[0].Paths[1] != "cues[1].cues[0]" {
+		t.Errorf("Expected nested path, got %v", duplicates[0].Paths)
+	}
+}
+
+// TestTransmitRefusesDuplicates tests that the default policy refuses before any OSC is sent
+func TestTransmitRefusesDuplicates(t *testing.T) {
+	workspaceData := map[string]any{
+		"cues": []any{
+			map[string]any{"type": "audio", "number": "5"},
+			map[string]any{"type": "audio", "number": "5"},
+		},
+	}
+
+	// No client is configured, so any attempt to send would panic
+	workspace := &Workspace{}
+	_, err := workspace.TransmitWorkspaceData("show.cue", workspaceData)
+
+	var dupErr *DuplicateCueError
+	if !errors.As(err, &dupErr) {
+		t.Fatalf("Expected DuplicateCueError, got %v", err)
+	}
+	if len(dupErr.Duplicates) != 1 {
+		t.Errorf("Expected 1 duplicate, got %d", len(dupErr.Duplicates))
+	}
+}
+
+// TestCheckDuplicateCuesWarnPolicy tests that the warn policy lets the transmit continue
+func TestCheckDuplicateCuesWarnPolicy(t *testing.T) {
+	workspaceData := map[string]any{
+		"cues": []any{
+			map[string]any{"type": "audio", "number": "5"},
+			map[string]any{"type": "audio", "number": "5"},
+		},
+	}
+
+	workspace := &Workspace{}
+	workspace.SetDuplicatePolicy(DuplicatePolicyWarn)
+	if err := workspace.checkDuplicateCues(workspaceData); err != nil {
+		t.Errorf("Expected no error with warn policy, got %v", err)
+	}
+}
+
+// TestSuffixDuplicateCues tests that later duplicates get the first free suffix, keeping
+// relative numbers relative and leaving the source alone
+func TestSuffixDuplicateCues(t *testing.T) {
+	workspaceData := map[string]any{
+		"cues": []any{
+			map[string]any{"type": "audio", "number": "5"},
+			map[string]any{"type": "audio", "number": "5a"},
+			map[string]any{"type": "audio", "number": "5"},
+			map[string]any{"type": "group", "number": "7", "cues": []any{
+				map[string]any{"type": "memo", "number": "1"},
+				map[string]any{"type": "memo", "number": "1"},
+			}},
+		},
+	}
+
+	suffixed, duplicates := SuffixDuplicateCues(workspaceData)
+	if len(duplicates) != 2 {
+		t.Fatalf("Expected 2 duplicates, got %+v", duplicates)
+	}
+	if got := duplicates[0].Suffixed; len(got) != 1 || got[0] != "5b" {
+		t.Errorf("Expected the second cue 5 renumbered 5b, got %v", got)
+	}
+	if got := duplicates[1].Suffixed; len(got) != 1 || got[0] != "7.1a" {
+		t.Errorf("Expected the second cue 7.1 renumbered 7.1a, got %v", got)
+	}
+	group := suffixed["cues"].([]any)[3].(map[string]any)
+	if number := group["cues"].([]any)[1].(map[string]any)["number"]; number != "1a" {
+		t.Errorf("Expected the relative number suffixed, got %v", number)
+	}
+	if remaining := FindDuplicateCues(suffixed); len(remaining) != 0 {
+		t.Errorf("Expected no duplicates left, got %+v", remaining)
+	}
+	if number := workspaceData["cues"].([]any)[2].(map[string]any)["number"]; number != "5" {
+		t.Errorf("Expected the source data left alone, got %v", number)
+	}
+}
+
+// TestTransmitSuffixesDuplicates tests that the suffix policy transmits both cues and reports
+// the duplicate in the comparison
+func TestTransmitSuffixesDuplicates(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+	workspace.SetCacheEnabled(false)
+	workspace.SetSkipInbox(true)
+	workspace.SetDuplicatePolicy(DuplicatePolicySuffix)
+
+	workspaceData := map[string]any{
+		"cues": []any{
+			map[string]any{"type": "memo", "number": "5", "name": "First"},
+			map[string]any{"type": "memo", "number": "5", "name": "Second"},
+		},
+	}
+	comparison, err := workspace.TransmitWorkspaceData(filepath.Join(t.TempDir(), "show.json"), workspaceData)
+	if err != nil {
+		t.Fatalf("TransmitWorkspaceData failed: %v", err)
+	}
+	if len(comparison.Duplicates) != 1 || comparison.Duplicates[0].Suffixed[0] != "5a" {
+		t.Errorf("Expected the duplicate reported as renumbered 5a, got %+v", comparison.Duplicates)
+	}
+	uniqueID, _ := workspace.lookupCueNumber("5a")
+	if cue := mockServer.GetCue(uniqueID); cue == nil || cue.Name != "Second" {
+		t.Errorf("Expected the second cue numbered 5a, got %+v", cue)
+	}
+}
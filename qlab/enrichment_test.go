@@ -1,6 +1,7 @@
 package qlab
 
 import (
+	"fmt"
 	"testing"
 )
 
@@ -131,3 +132,85 @@ func TestPropertyEnrichment(t *testing.T) {
 		}
 	})
 }
+
+// TestParallelPropertyEnrichment tests that replies to parallel property queries reach the right cues
+func TestParallelPropertyEnrichment(t *testing.T) {
+	workspace, _ := setupWorkspaceWithCleanup(t)
+	workspace.SetEnrichmentConcurrency(4)
+
+	for i := 1; i <= 6; i++ {
+		number := fmt.Sprintf("%d.0", i)
+		cueData := map[string]any{"type": "audio", "number": number, "name": "Audio " + number, "fileTarget": "music/" + number + ".mp3"}
+		if _, err := workspace.createCue(cueData, number); err != nil {
+			t.Fatalf("Failed to create cue %s: %v", number, err)
+		}
+	}
+
+	snapshot := func() map[string]map[string]any {
+		t.Helper()
+		currentWorkspace, err := workspace.queryCurrentWorkspaceState()
+		if err != nil {
+			t.Fatalf("Failed to query workspace state: %v", err)
+		}
+		return workspace.indexCuesFromWorkspace(currentWorkspace)
+	}
+
+	cues := snapshot()
+	for i := 1; i <= 6; i++ {
+		number := fmt.Sprintf("%d.0", i)
+		if got := cues[number]["fileTarget"]; got != "music/"+number+".mp3" {
+			t.Errorf("Expected cue %s to have its own fileTarget, got %v", number, got)
+		}
+	}
+
+	workspace.SetEnrichedProperties("cueTargetNumber")
+	for number, cue := range snapshot() {
+		if _, ok := cue["fileTarget"]; ok {
+			t.Errorf("Expected fileTarget not to be queried for cue %s", number)
+		}
+	}
+}
+
+// TestEnrichmentConcurrency tests that queries only overlap with a shared listener
+func TestEnrichmentConcurrency(t *testing.T) {
+	workspace := NewWorkspace("localhost", 53000)
+	workspace.SetEnrichmentConcurrency(16)
+	if got := workspace.enrichmentConcurrency(); got != 1 {
+		t.Errorf("Expected serial queries without a listener, got %d workers", got)
+	}
+
+	tcp := NewWorkspaceTCP("localhost", 53000)
+	if got := tcp.enrichmentConcurrency(); got != DefaultEnrichmentConcurrency {
+		t.Errorf("Expected %d workers by default, got %d", DefaultEnrichmentConcurrency, got)
+	}
+	tcp.SetEnrichmentConcurrency(16)
+	if got := tcp.enrichmentConcurrency(); got != 16 {
+		t.Errorf("Expected 16 workers, got %d", got)
+	}
+}
+
+// TestGetCueValues tests reading several properties in one valuesForKeys query
+func TestGetCueValues(t *testing.T) {
+	workspace, _ := setupWorkspaceWithCleanup(t)
+
+	uniqueID, err := workspace.createCue(map[string]any{"type": "audio", "number": "1.0", "name": "Intro", "fileTarget": "music/intro.mp3"}, "1.0")
+	if err != nil {
+		t.Fatalf("Failed to create cue: %v", err)
+	}
+
+	values, err := workspace.GetCueValues(uniqueID, []string{"name", "number", "fileTarget"})
+	if err != nil {
+		t.Fatalf("GetCueValues failed: %v", err)
+	}
+	want := map[string]any{"name": "Intro", "number": "1.0", "fileTarget": "music/intro.mp3"}
+	for key, value := range want {
+		if values[key] != value {
+			t.Errorf("Expected %s to be %v, got %v", key, value, values[key])
+		}
+	}
+
+	workspace.SetTimeout(1)
+	if _, err := workspace.GetCueValues("MISSING", []string{"name"}); err == nil {
+		t.Error("Expected an error for a cue QLab doesn't have")
+	}
+}
@@ -0,0 +1,63 @@
+package qlab
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+// TestWorkspaceEnvelopeRoundTrip tests that an envelope creates its cue lists with their
+// cues, is received back with the workspace's metadata, and transmits back unchanged
+func TestWorkspaceEnvelopeRoundTrip(t *testing.T) {
+	workspace, _ := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+	workspace.SetCacheStore(NewMemoryCacheStore())
+	workspace.SetSkipInbox(true)
+	path := filepath.Join(t.TempDir(), "show.json")
+
+	source := map[string]any{"envelope": 1, "name": "Tour", "cueLists": []any{
+		map[string]any{"name": "Preshow", "cues": []any{map[string]any{"type": "memo", "number": "1", "name": "Walk-in"}}},
+		map[string]any{"name": "Show", "cues": []any{map[string]any{"type": "memo", "number": "10", "name": "Act 1"}}},
+	}}
+	if _, err := workspace.TransmitWorkspaceData(path, source); err != nil {
+		t.Fatalf("Transmit failed: %v", err)
+	}
+
+	envelope, err := workspace.ReceiveWorkspace()
+	if err != nil {
+		t.Fatalf("ReceiveWorkspace failed: %v", err)
+	}
+	if envelope.Version != EnvelopeVersion || envelope.Name != "Mock Workspace" || envelope.WorkspaceID != workspace.WorkspaceID() || envelope.BasePath == "" {
+		t.Errorf("Expected the workspace's metadata, got %+v", envelope)
+	}
+	lists := make(map[string][]string)
+	var order []string
+	for _, list := range envelope.CueLists {
+		order = append(order, list.Name)
+		for _, cueData := range list.Cues {
+			lists[list.Name] = append(lists[list.Name], formatCueNumber(cueData.(map[string]any)["number"]))
+		}
+	}
+	if len(lists["Preshow"]) != 1 || lists["Preshow"][0] != "1" || len(lists["Show"]) != 1 || lists["Show"][0] != "10" {
+		t.Fatalf("Expected cue 1 in Preshow and 10 in Show, got %v (lists %v)", lists, order)
+	}
+
+	// Through JSON and back, nothing needs changing
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var received map[string]any
+	if err := json.Unmarshal(data, &received); err != nil {
+		t.Fatal(err)
+	}
+	comparison, err := workspace.TransmitWorkspaceData(path, received)
+	if err != nil {
+		t.Fatalf("Second transmit failed: %v", err)
+	}
+	for key, result := range comparison.CueResults {
+		if result.Action == "create" {
+			t.Errorf("Expected cue %s found, got %s: %s", key, result.Action, result.Reason)
+		}
+	}
+}
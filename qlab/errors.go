@@ -0,0 +1,86 @@
+package qlab
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors for OSC failures, for use with errors.Is. Timeouts are transient and
+// worth retrying; authentication and workspace failures need the caller to change something.
+var (
+	ErrTimeout           = errors.New("timeout waiting for reply from QLab")
+	ErrAuthFailed        = errors.New("QLab authentication failed")
+	ErrWorkspaceNotFound = errors.New("QLab workspace not found")
+	ErrClosed            = errors.New("workspace is shut down") // Messages sent once Shutdown has started
+
+	// Workspace messages after QLab stopped accepting the passcode mid-session, until
+	// Reauthenticate succeeds. It matches ErrAuthFailed too.
+	ErrReauthenticationRequired = fmt.Errorf("%w: passcode no longer accepted, reauthenticate", ErrAuthFailed)
+
+	// Changes a read-only workspace, or one whose passcode doesn't allow them, refuses to send
+	ErrReadOnly                = errors.New("workspace is read-only")
+	ErrInsufficientPermissions = errors.New("passcode does not allow this operation")
+)
+
+// timeoutReply is the reply returned in place of QLab's when none arrives in time
+const timeoutReply = `{"status": "error", "error": "timeout waiting for reply from QLab"}`
+
+// QLabError is returned when QLab rejects a message, or a message gets no reply in time.
+// It unwraps to ErrTimeout, ErrAuthFailed or ErrReauthenticationRequired when the reply says so, so callers can check
+// errors.Is(err, ErrTimeout) without inspecting the reply themselves.
+type QLabError struct {
+	Message string // What was being attempted
+	Status  string // Reply status, e.g. "error" or "denied"
+	Address string // Address the reply was for, when QLab included it
+	RawJSON string // The reply as received
+}
+
+// newQLabError creates a QLabError from a reply, filling in its status and address
+func newQLabError(message, replyJSON string) *QLabError {
+	e := &QLabError{Message: message, RawJSON: replyJSON}
+	var reply map[string]any
+	if err := json.Unmarshal([]byte(replyJSON), &reply); err == nil {
+		e.Status, _ = reply["status"].(string)
+		e.Address, _ = reply["address"].(string)
+	}
+	return e
+}
+
+// Error formats the message with the reply pretty-printed
+func (e *QLabError) Error() string {
+	var jsonData any
+	if err := json.Unmarshal([]byte(e.RawJSON), &jsonData); err != nil {
+		// Fallback to raw string if JSON parsing fails
+		return fmt.Sprintf("%s: %s", e.Message, e.RawJSON)
+	}
+
+	prettyBytes, err := json.MarshalIndent(jsonData, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, jsonData)
+	}
+
+	return fmt.Sprintf("%s:\n%s", e.Message, string(prettyBytes))
+}
+
+// Unwrap returns the sentinel error the reply corresponds to, if any
+func (e *QLabError) Unwrap() error {
+	var reply map[string]any
+	if err := json.Unmarshal([]byte(e.RawJSON), &reply); err != nil {
+		return nil
+	}
+	switch message, _ := reply["error"].(string); message {
+	case ErrTimeout.Error():
+		return ErrTimeout
+	case ErrReadOnly.Error():
+		return ErrReadOnly
+	case ErrInsufficientPermissions.Error():
+		return ErrInsufficientPermissions
+	case ErrReauthenticationRequired.Error():
+		return ErrReauthenticationRequired
+	}
+	if data, _ := reply["data"].(string); data == "badpass" || e.Status == "denied" {
+		return ErrAuthFailed
+	}
+	return nil
+}
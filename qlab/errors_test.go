@@ -0,0 +1,48 @@
+package qlab
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestQLabErrorClassification tests that error replies unwrap to the matching sentinel errors
+func TestQLabErrorClassification(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply string
+		want  error
+	}{
+		{"timeout", timeoutReply, ErrTimeout},
+		{"badpass", `{"status": "ok", "data": "badpass"}`, ErrAuthFailed},
+		{"denied", `{"status": "denied", "address": "/workspace/W/new"}`, ErrAuthFailed},
+		{"rejected", `{"status": "error", "address": "/workspace/W/new"}`, nil},
+	}
+
+	for _, tt := range tests {
+		err := newQLabError("test", tt.reply)
+		for _, sentinel := range []error{ErrTimeout, ErrAuthFailed, ErrWorkspaceNotFound} {
+			if got := errors.Is(err, sentinel); got != (sentinel == tt.want) {
+				t.Errorf("%s: errors.Is(err, %v) = %v", tt.name, sentinel, got)
+			}
+		}
+	}
+
+	var qlabErr *QLabError
+	if err := checkReplyStatus([]any{`{"status": "error", "address": "/workspace/W/go"}`}); !errors.As(err, &qlabErr) {
+		t.Fatalf("Expected *QLabError, got %v", err)
+	}
+	if qlabErr.Status != "error" || qlabErr.Address != "/workspace/W/go" || !strings.Contains(qlabErr.RawJSON, "/go") {
+		t.Errorf("Unexpected QLabError fields: %+v", qlabErr)
+	}
+}
+
+// TestInitReportsAuthFailure tests that a rejected passcode is reported as ErrAuthFailed
+func TestInitReportsAuthFailure(t *testing.T) {
+	workspace, _ := setupWorkspaceWithCleanup(t)
+
+	// The mock rejects the passcode "test" with "badpass"
+	if _, err := workspace.Init("test"); !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("Expected ErrAuthFailed, got %v", err)
+	}
+}
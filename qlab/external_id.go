@@ -0,0 +1,109 @@
+package qlab
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// externalIDPattern matches the line external IDs are stamped in cue notes with
+var externalIDPattern = regexp.MustCompile(`(?m)\n?^\[cuejitsu-id: ([^\]\n]+)\]$`)
+
+// SetExternalIDs stamps each managed cue with the stable ID its source cue gives in the key
+// property (for example "externalID") and matches cues by that ID before anything else, so a
+// cue keeps its identity even when both its number and position change. The ID is stamped
+// in the QLab property named by property, or as a "[cuejitsu-id: …]" line at the end of the
+// cue's notes when property is empty. An empty key turns external IDs off (the default).
+func (q *Workspace) SetExternalIDs(key, property string) {
+	q.externalIDKey = key
+	q.externalIDProperty = property
+}
+
+// externalIDReadKey returns the QLab property external IDs are read from, or "" when they
+// are off
+func (q *Workspace) externalIDReadKey() string {
+	if q.externalIDKey == "" {
+		return ""
+	}
+	if q.externalIDProperty == "" {
+		return "notes"
+	}
+	return q.externalIDProperty
+}
+
+// sourceExternalID returns the external ID a source cue gives
+func (q *Workspace) sourceExternalID(cueData map[string]any) string {
+	if q.externalIDKey == "" {
+		return ""
+	}
+	id, _ := cueData[q.externalIDKey].(string)
+	return strings.TrimSpace(id)
+}
+
+// enrichExternalID records the external ID stamped on a QLab cue under the source's key,
+// from values read with externalIDReadKey
+func (q *Workspace) enrichExternalID(cue map[string]any, values map[string]any) {
+	key := q.externalIDReadKey()
+	if key == "" {
+		return
+	}
+	value, _ := values[key].(string)
+	if q.externalIDProperty == "" {
+		match := externalIDPattern.FindStringSubmatch(value)
+		if match == nil {
+			return
+		}
+		value = match[1]
+	}
+	if value != "" {
+		cue[q.externalIDKey] = value
+	}
+}
+
+// withExternalID returns notes with an external ID line at the end, replacing any already there
+func withExternalID(notes, id string) string {
+	notes = stripExternalID(notes)
+	line := fmt.Sprintf("[cuejitsu-id: %s]", id)
+	if notes == "" {
+		return line
+	}
+	return notes + "\n" + line
+}
+
+// stripExternalID removes the external ID line from notes
+func stripExternalID(notes string) string {
+	return externalIDPattern.ReplaceAllString(notes, "")
+}
+
+// stampExternalID stamps a cue with its source cue's external ID. New cues are always
+// stamped; when updating, notes are only rewritten if the source sets them, so notes
+// written in QLab aren't replaced by the ID alone.
+func (q *Workspace) stampExternalID(uniqueID string, cueData map[string]any, created bool) error {
+	id := q.sourceExternalID(cueData)
+	if id == "" {
+		return nil
+	}
+	if q.externalIDProperty != "" {
+		return q.setCueProperty(uniqueID, q.externalIDProperty, id)
+	}
+	notes, hasNotes := cueData["notes"].(string)
+	if !created && !hasNotes {
+		return nil
+	}
+	return q.setCueProperty(uniqueID, "notes", withExternalID(notes, id))
+}
+
+// externalIDIndex maps each external ID stamped on indexed QLab cues to the cue's uniqueID
+func (q *Workspace) externalIDIndex(cues map[string]map[string]any) map[string]string {
+	index := make(map[string]string)
+	if q.externalIDKey == "" {
+		return index
+	}
+	for _, cue := range cues {
+		uniqueID, _ := cue["uniqueID"].(string)
+		if id, _ := cue[q.externalIDKey].(string); id != "" && uniqueID != "" {
+			index[id] = uniqueID
+		}
+	}
+	return index
+}
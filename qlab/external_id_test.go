@@ -0,0 +1,83 @@
+package qlab
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestWithExternalID tests stamping, replacing and stripping the external ID line in notes
+func TestWithExternalID(t *testing.T) {
+	tests := []struct {
+		notes    string
+		id       string
+		expected string
+	}{
+		{"", "sfx-1", "[cuejitsu-id: sfx-1]"},
+		{"Check levels", "sfx-1", "Check levels\n[cuejitsu-id: sfx-1]"},
+		{"Check levels\n[cuejitsu-id: old]", "sfx-2", "Check levels\n[cuejitsu-id: sfx-2]"},
+	}
+	for _, tt := range tests {
+		if got := withExternalID(tt.notes, tt.id); got != tt.expected {
+			t.Errorf("withExternalID(%q, %q) = %q; want %q", tt.notes, tt.id, got, tt.expected)
+		}
+	}
+	if got := stripExternalID("Check levels\n[cuejitsu-id: sfx-1]"); got != "Check levels" {
+		t.Errorf("Expected the ID line stripped, got %q", got)
+	}
+
+	workspace := &Workspace{}
+	workspace.SetExternalIDs("externalID", "")
+	cue := map[string]any{}
+	workspace.enrichExternalID(cue, map[string]any{"notes": "Check levels\n[cuejitsu-id: sfx-1]"})
+	if cue["externalID"] != "sfx-1" {
+		t.Errorf("Expected the stamped ID read from the notes, got %v", cue["externalID"])
+	}
+}
+
+// TestExternalIDMatching tests that a cue stamped with an external ID is found by it when
+// its number changes, even without a cache
+func TestExternalIDMatching(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+	workspace.SetCacheEnabled(false)
+	workspace.SetSkipInbox(true)
+	workspace.SetExternalIDs("externalID", "")
+	path := filepath.Join(t.TempDir(), "show.json")
+
+	source := func(number, name string) map[string]any {
+		return map[string]any{"cues": []any{
+			map[string]any{"type": "memo", "number": number, "name": name, "notes": "Lights out", "externalID": "blackout"},
+		}}
+	}
+
+	comparison, err := workspace.TransmitWorkspaceData(path, source("5", "Blackout"))
+	if err != nil {
+		t.Fatalf("First transmit failed: %v", err)
+	}
+	if comparison.CueResults["5"].Action != "create" {
+		t.Fatalf("Expected cue 5 to be created, got %+v", comparison.CueResults["5"])
+	}
+	count := mockServer.GetCueCount()
+
+	comparison, err = workspace.TransmitWorkspaceData(path, source("50", "Final blackout"))
+	if err != nil {
+		t.Fatalf("Second transmit failed: %v", err)
+	}
+	result := comparison.CueResults["50"]
+	if result == nil || result.Action != "update" || result.PreviousKey != "5" {
+		t.Fatalf("Expected cue 50 to update cue 5 in place, got %+v", result)
+	}
+	if _, ok := result.ModifiedFields["notes"]; ok {
+		t.Errorf("Expected the ID line to be ignored when comparing notes, got %v", result.ModifiedFields)
+	}
+	if got := mockServer.GetCueCount(); got != count {
+		t.Errorf("Expected %d cues, got %d", count, got)
+	}
+	cue := mockServer.GetCue(result.ExistingID)
+	if cue == nil || cue.Number != "50" || cue.Name != "Final blackout" {
+		t.Fatalf("Expected the cue renumbered and renamed, got %+v", cue)
+	}
+	if notes := cue.Properties["notes"]; notes != "Lights out\n[cuejitsu-id: blackout]" {
+		t.Errorf("Expected the notes to keep the ID line, got %q", notes)
+	}
+}
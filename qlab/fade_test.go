@@ -0,0 +1,100 @@
+package qlab
+
+import (
+	"testing"
+)
+
+// TestParseFadeLevels tests the level shorthand and both levels list formats
+func TestParseFadeLevels(t *testing.T) {
+	levels := parseFadeLevels(map[string]any{
+		"level": -6.0,
+		"levels": []any{
+			[]any{0.0, 1.0, -12.0},
+			map[string]any{"row": 1.0, "column": 2.0, "db": "-3.5"},
+			[]any{0.0, 1.0},
+		},
+	})
+
+	expected := []FadeLevel{
+		{Row: 0, Column: 0, Decibel: -6},
+		{Row: 0, Column: 1, Decibel: -12},
+		{Row: 1, Column: 2, Decibel: -3.5},
+	}
+	if len(levels) != len(expected) {
+		t.Fatalf("Expected %d levels, got %+v", len(expected), levels)
+	}
+	for i, level := range levels {
+		if level != expected[i] {
+			t.Errorf("Level %d: expected %+v, got %+v", i, expected[i], level)
+		}
+	}
+}
+
+// TestFadeCueRoundTrip tests that fade properties are sent on create and read back by ReceiveWorkspaceData
+func TestFadeCueRoundTrip(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+
+	cueID, err := workspace.createCueWithoutTarget(map[string]any{
+		"type":               "fade",
+		"name":               "Fade out",
+		"duration":           "2.5",
+		"doOpacity":          true,
+		"opacity":            0.0,
+		"levels":             []any{[]any{0.0, 0.0, -60.0}},
+		"stopTargetWhenDone": true,
+	}, "5")
+	if err != nil {
+		t.Fatalf("createCueWithoutTarget failed: %v", err)
+	}
+
+	mockCue := mockServer.GetCue(cueID)
+	if mockCue == nil {
+		t.Fatalf("Expected cue %s to exist", cueID)
+	}
+	expected := map[string]string{
+		"duration":           "2.5",
+		"doOpacity":          "1",
+		"opacity":            "0",
+		"level/0/0":          "-60",
+		"stopTargetWhenDone": "1",
+	}
+	for property, value := range expected {
+		if got := mockCue.Properties[property]; got != value {
+			t.Errorf("Expected %s=%q, got %q", property, value, got)
+		}
+	}
+
+	cues, err := workspace.ReceiveWorkspaceData()
+	if err != nil {
+		t.Fatalf("ReceiveWorkspaceData failed: %v", err)
+	}
+
+	var received map[string]any
+	var find func(cues []any)
+	find = func(cues []any) {
+		for _, c := range cues {
+			cue, _ := c.(map[string]any)
+			if id, _ := cue["uniqueID"].(string); id == cueID {
+				received = cue
+				return
+			}
+			if children, ok := cue["cues"].([]any); ok {
+				find(children)
+			}
+		}
+	}
+	find(cues)
+	if received == nil {
+		t.Fatalf("Fade cue %s not found in received data", cueID)
+	}
+
+	if received["duration"] != "2.5" {
+		t.Errorf("Expected duration 2.5, got %v", received["duration"])
+	}
+	if received["doOpacity"] != true || received["stopTargetWhenDone"] != true {
+		t.Errorf("Expected doOpacity and stopTargetWhenDone to be true, got %v and %v", received["doOpacity"], received["stopTargetWhenDone"])
+	}
+	if levels := parseFadeLevels(received); len(levels) != 1 || levels[0].Decibel != -60 {
+		t.Errorf("Expected master level -60 dB, got %+v", received["levels"])
+	}
+}
@@ -0,0 +1,106 @@
+package qlab
+
+import "testing"
+
+// filterTestSource builds source data with two cue lists, one of them holding a scene group
+func filterTestSource() map[string]any {
+	memo := func(number, name string) map[string]any {
+		return map[string]any{"type": "memo", "number": number, "name": name}
+	}
+	return map[string]any{"cues": []any{
+		map[string]any{"type": "list", "name": "Main", "cues": []any{
+			memo("1", "Preshow"),
+			map[string]any{"type": "group", "number": "10", "name": "Scene", "cues": []any{
+				memo("10.1", "Lights"),
+				memo("10.2", "Sound"),
+				map[string]any{"type": "memo", "name": "Unnumbered"},
+			}},
+			memo("25", "Bows"),
+		}},
+		map[string]any{"type": "list", "name": "Effects", "cues": []any{
+			memo("100", "Thunder"),
+		}},
+	}}
+}
+
+// filterTestComparison has an update for every cue of filterTestSource, all in QLab
+func filterTestComparison() *ThreeWayComparison {
+	comparison := &ThreeWayComparison{CueResults: make(map[string]*CueChangeResult)}
+	for _, key := range []string{"1", "10", "10.1", "10.2", "10@2[memo:Unnumbered]", "25", "100"} {
+		comparison.CueResults[key] = &CueChangeResult{HasChanged: true, Action: "update", ExistingID: "id-" + key}
+	}
+	comparison.CueResults["7"] = &CueChangeResult{HasChanged: true, Action: "delete", ExistingID: "id-7"}
+	comparison.WorkspaceScope = &ScopeComparison{Scope: ScopeWorkspace, ChildScopes: []*ScopeComparison{
+		{Scope: ScopeCue, Identifier: "1", ConflictExists: true},
+		{Scope: ScopeCue, Identifier: "10.1", ConflictExists: true},
+	}}
+	return comparison
+}
+
+// TestTransmitFilter tests which cues each kind of selection leaves to be transmitted
+func TestTransmitFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		option TransmitOption
+		want   map[string]string // Key -> action after filtering
+	}{
+		{
+			name:   "cue range",
+			option: WithCueRange("10.0", "20.0"),
+			want:   map[string]string{"1": "skip", "10": "update", "10.1": "update", "10.2": "update", "10@2[memo:Unnumbered]": "update", "25": "skip", "100": "skip"},
+		},
+		{
+			name:   "cue list",
+			option: WithCueLists("Effects"),
+			want:   map[string]string{"1": "skip", "10": "skip", "10.1": "skip", "25": "skip", "100": "update"},
+		},
+		{
+			name: "filter inside a group",
+			option: WithCueFilter(func(key, cueList string, cue map[string]any) bool {
+				return cue["name"] == "Sound"
+			}),
+			want: map[string]string{"1": "skip", "10": "keep", "10.1": "skip", "10.2": "update", "10@2[memo:Unnumbered]": "skip"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var options transmitOptions
+			tt.option(&options)
+			comparison := filterTestComparison()
+			(&Workspace{}).applyTransmitFilter(comparison, filterTestSource(), options.filter)
+
+			for key, want := range tt.want {
+				if got := comparison.CueResults[key].Action; got != want {
+					t.Errorf("Cue %s: expected %s, got %s", key, want, got)
+				}
+			}
+			if _, ok := comparison.CueResults["7"]; ok {
+				t.Error("Expected deletions to be dropped from a selective transmit")
+			}
+			if comparison.WorkspaceScope.ChildScopes[0].ConflictExists {
+				t.Error("Expected the conflict of unselected cue 1 to be dropped")
+			}
+		})
+	}
+}
+
+// TestCompareCueNumbers tests the ordering used by WithCueRange
+func TestCompareCueNumbers(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"10.2", "10.10", -1},
+		{"9", "10.0", -1},
+		{"10", "10.0", 0},
+		{"10.1", "10.0", 1},
+		{"1A", "1B", -1},
+		{"20.5", "20.0", 1},
+	}
+	for _, tt := range tests {
+		if got := compareCueNumbers(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareCueNumbers(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package qlab
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCueFollow tests that continue modes and post-waits are set, read back, and compared
+func TestCueFollow(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+
+	cueID, err := workspace.createCueWithoutTarget(map[string]any{
+		"type":         "memo",
+		"name":         "Blackout",
+		"continueMode": "auto-follow",
+		"postWait":     2.5,
+	}, "")
+	if err != nil {
+		t.Fatalf("createCueWithoutTarget failed: %v", err)
+	}
+
+	cue := mockServer.GetCue(cueID)
+	if cue.Properties["continueMode"] != "2" || cue.Properties["postWait"] != "2.5" {
+		t.Errorf("Expected an auto-follow after 2.5s, got %v", cue.Properties)
+	}
+
+	// The post-wait goes before the continue mode
+	var order []string
+	for _, msg := range mockServer.GetReceivedMessages() {
+		if strings.HasSuffix(msg.Address, "/continueMode") || strings.HasSuffix(msg.Address, "/postWait") {
+			order = append(order, msg.Address[strings.LastIndex(msg.Address, "/")+1:])
+		}
+	}
+	if len(order) < 2 || order[0] != "postWait" {
+		t.Errorf("Expected the post-wait set before the continue mode, got %v", order)
+	}
+
+	enriched := map[string]any{"type": "Memo", "uniqueID": cueID, "name": "Blackout"}
+	workspace.enrichCueProperties(enriched, cueID)
+	if enriched["continueMode"] != 2.0 || enriched["postWait"] != 2.5 {
+		t.Errorf("Expected the follow read back, got %v", enriched)
+	}
+
+	same := map[string]any{"type": "memo", "name": "Blackout", "continueMode": "autofollow", "postWait": 2.5}
+	if differences := workspace.compareCuePropertiesDetailed(same, enriched); len(differences) != 0 {
+		t.Errorf("Expected a named continue mode to match, got %v", differences)
+	}
+	changed := map[string]any{"type": "memo", "name": "Blackout", "continueMode": "auto-continue", "postWait": 2.5}
+	if differences := workspace.compareCuePropertiesDetailed(changed, enriched); differences["continueMode"] == "" {
+		t.Errorf("Expected the continue mode change detected, got %v", differences)
+	}
+
+	if err := workspace.updateCueProperties(cueID, map[string]any{"type": "memo", "continueMode": "sometimes"}); err == nil {
+		t.Error("Expected an unknown continue mode to fail the update")
+	}
+	if err := workspace.updateCueProperties(cueID, map[string]any{"type": "memo", "continueMode": 0.0}); err != nil {
+		t.Fatalf("updateCueProperties failed: %v", err)
+	}
+	if got := mockServer.GetCue(cueID).Properties["continueMode"]; got != "0" {
+		t.Errorf("Expected the follow cleared, got %q", got)
+	}
+}
@@ -0,0 +1,77 @@
+package qlab
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestParseGroupMode tests modes given by number and by name
+func TestParseGroupMode(t *testing.T) {
+	tests := []struct {
+		value any
+		want  GroupMode
+	}{
+		{3.0, GroupModeTimeline},
+		{6, GroupModePlaylist},
+		{"4", GroupModeStartRandom},
+		{"timeline", GroupModeTimeline},
+		{"Fire First", GroupModeStartFirst},
+		{"start_first_and_enter", GroupModeStartFirstAndEnter},
+		{"cue-list", GroupModeList},
+	}
+	for _, tt := range tests {
+		if got, err := ParseGroupMode(tt.value); err != nil || got != tt.want {
+			t.Errorf("ParseGroupMode(%v) = %v, %v; want %v", tt.value, got, err, tt.want)
+		}
+	}
+
+	for _, value := range []any{7.0, -1, 2.5, "sideways", true} {
+		if mode, err := ParseGroupMode(value); err == nil {
+			t.Errorf("Expected ParseGroupMode(%v) to fail, got %v", value, mode)
+		}
+	}
+
+	var cue Cue
+	if err := json.Unmarshal([]byte(`{"type": "group", "mode": "playlist"}`), &cue); err != nil || cue.Mode != GroupModePlaylist {
+		t.Errorf("Expected a named mode to unmarshal, got %v (%v)", cue.Mode, err)
+	}
+	if GroupModeTimeline.String() != "timeline" || GroupMode(9).String() != "9" {
+		t.Errorf("Unexpected mode names %q, %q", GroupModeTimeline, GroupMode(9))
+	}
+}
+
+// TestValidateGroupMode tests the modes cue lists, carts, and groups accept
+func TestValidateGroupMode(t *testing.T) {
+	tests := []struct {
+		cueType string
+		mode    GroupMode
+		valid   bool
+	}{
+		{"list", GroupModeList, true},
+		{"cue list", GroupModeTimeline, false},
+		{"cart", GroupModeCart, true},
+		{"cart", GroupModeList, false},
+		{"group", GroupModeTimeline, true},
+		{"group", GroupModeCart, false},
+		{"group", GroupMode(8), false},
+	}
+	for _, tt := range tests {
+		if err := ValidateGroupMode(tt.cueType, tt.mode); (err == nil) != tt.valid {
+			t.Errorf("ValidateGroupMode(%q, %v) = %v; want valid=%t", tt.cueType, tt.mode, err, tt.valid)
+		}
+	}
+}
+
+// TestTransmitRefusesImpossibleGroupMode tests that a bad mode is refused before any OSC is sent
+func TestTransmitRefusesImpossibleGroupMode(t *testing.T) {
+	workspaceData := map[string]any{
+		"cues": []any{
+			map[string]any{"type": "list", "name": "Main", "mode": "timeline", "cues": []any{}},
+		},
+	}
+
+	// No client is configured, so any attempt to send would panic
+	if _, err := (&Workspace{}).TransmitWorkspaceData("show.cue", workspaceData); err == nil {
+		t.Fatal("Expected a cue list in timeline mode to be refused")
+	}
+}
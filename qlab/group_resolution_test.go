@@ -0,0 +1,91 @@
+package qlab
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestConflictHierarchyFromScope tests that conflicts carry their enclosing groups and cue list, in cue list order
+func TestConflictHierarchyFromScope(t *testing.T) {
+	workspace := &Workspace{}
+
+	cues := func(name string) map[string]any {
+		return map[string]any{"cues": []any{
+			map[string]any{"type": "list", "name": "Main", "cues": []any{
+				map[string]any{"type": "group", "number": "10", "name": "Scene", "cues": []any{
+					map[string]any{"type": "audio", "number": "10.1", "name": name},
+					map[string]any{"type": "audio", "number": "10.2", "name": name},
+				}},
+				map[string]any{"type": "memo", "number": "20", "name": name},
+			}},
+		}}
+	}
+
+	scope, err := workspace.PerformScopeBasedComparison(cues("Source"), cues("Cache"), cues("QLab"))
+	if err != nil {
+		t.Fatalf("PerformScopeBasedComparison failed: %v", err)
+	}
+	conflicts := workspace.identifyConflictsFromScope(scope)
+
+	var numbers []string
+	for _, c := range conflicts {
+		numbers = append(numbers, c.CueNumber)
+	}
+	// Groups conflict on their children too
+	if !slices.Equal(numbers, []string{"@0[list:Main]", "10", "10.1", "10.2", "20"}) {
+		t.Fatalf("Expected conflicts in cue list order, got %v", numbers)
+	}
+	if !slices.Equal(conflicts[2].Ancestors, []string{"10", "@0[list:Main]"}) || conflicts[2].CueList != "Main" {
+		t.Errorf("Expected cue 10.1 inside group 10 in Main, got %v in %q", conflicts[2].Ancestors, conflicts[2].CueList)
+	}
+	if slices.Contains(conflicts[4].Ancestors, "10") {
+		t.Errorf("Expected cue 20 outside group 10, got %v", conflicts[4].Ancestors)
+	}
+	if got := ConflictsInScope(conflicts, "10", ResolveThisGroup); !slices.Equal(got, []string{"10", "10.1", "10.2"}) {
+		t.Errorf("Expected group 10 to cover its children, got %v", got)
+	}
+}
+
+// TestExpandScopedResolutions tests that group, cue list, and remaining choices cover the right conflicts
+func TestExpandScopedResolutions(t *testing.T) {
+	conflicts := []CueConflict{
+		{CueNumber: "10", CueIdentifier: "10", CueList: "Main"},
+		{CueNumber: "10.1", CueIdentifier: "10.1", Ancestors: []string{"10"}, CueList: "Main"},
+		{CueNumber: "10.1.1", CueIdentifier: "10.1.1", Ancestors: []string{"10.1", "10"}, CueList: "Main"},
+		{CueNumber: "20", CueIdentifier: "20", CueList: "Main"},
+		{CueNumber: "30", CueIdentifier: "30", CueList: "Backstage"},
+	}
+
+	// A group cue's own conflict covers the group; a child's covers its innermost group
+	if got := ConflictsInScope(conflicts, "10", ResolveThisGroup); !slices.Equal(got, []string{"10", "10.1", "10.1.1"}) {
+		t.Errorf("Group 10: got %v", got)
+	}
+	if got := ConflictsInScope(conflicts, "10.1.1", ResolveThisGroup); !slices.Equal(got, []string{"10.1", "10.1.1"}) {
+		t.Errorf("Group of 10.1.1: got %v", got)
+	}
+	if got := ConflictsInScope(conflicts, "10.1", ResolveThisCueList); !slices.Equal(got, []string{"10", "10.1", "10.1.1", "20"}) {
+		t.Errorf("Cue list Main: got %v", got)
+	}
+	if got := ConflictsInScope(conflicts, "20", ResolveRemaining); !slices.Equal(got, []string{"20", "30"}) {
+		t.Errorf("Remaining from 20: got %v", got)
+	}
+
+	expanded := ExpandScopedResolutions(conflicts,
+		map[string]ConflictResolutionChoice{"10.1": ChoiceSkip},
+		[]ScopedResolution{
+			{CueNumber: "10", Choice: ChoiceUseSource, Scope: ResolveThisGroup},
+			{CueNumber: "10", Choice: ChoiceKeepQLab, Scope: ResolveRemaining},
+		})
+	want := map[string]ConflictResolutionChoice{
+		"10":     ChoiceUseSource,
+		"10.1":   ChoiceSkip, // Per-cue resolutions win
+		"10.1.1": ChoiceUseSource,
+		"20":     ChoiceKeepQLab,
+		"30":     ChoiceKeepQLab,
+	}
+	for cueNumber, choice := range want {
+		if expanded[cueNumber] != choice {
+			t.Errorf("Cue %s: expected %s, got %s", cueNumber, choice, expanded[cueNumber])
+		}
+	}
+}
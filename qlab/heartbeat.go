@@ -0,0 +1,193 @@
+package qlab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// HeartbeatMisses is how many heartbeats in a row must go unanswered before the
+// connection is considered unhealthy
+const HeartbeatMisses = 2
+
+// HealthStatus is a snapshot of connection health as measured by the heartbeat
+type HealthStatus struct {
+	Healthy           bool
+	LastSeen          time.Time     // When QLab last answered a heartbeat, zero if never
+	LastLatency       time.Duration // Round trip of the last answered heartbeat
+	MinLatency        time.Duration
+	MaxLatency        time.Duration
+	AvgLatency        time.Duration
+	Beats             int // Heartbeats answered
+	Missed            int // Heartbeats unanswered
+	ConsecutiveMisses int
+}
+
+// HealthEvent reports a change between healthy and unhealthy
+type HealthEvent struct {
+	Healthy bool
+	At      time.Time
+	Latency time.Duration // Round trip of the heartbeat that restored health
+	Err     error         // Why the last heartbeat failed, when health was lost
+}
+
+// heartbeatState runs the heartbeat and keeps the health it measures
+type heartbeatState struct {
+	mu           sync.Mutex
+	status       HealthStatus
+	totalLatency time.Duration
+	changes      chan HealthEvent
+	cancel       context.CancelFunc
+	done         chan struct{}
+}
+
+// StartHeartbeat sends /thump to QLab every interval and tracks the replies. After
+// HeartbeatMisses unanswered beats the connection is unhealthy and, if it was connected,
+// a DisconnectTimeoutStorm is reported. A heartbeat already running is restarted.
+func (q *Workspace) StartHeartbeat(interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("heartbeat interval must be positive, got %v", interval)
+	}
+	q.StopHeartbeat()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	q.heartbeat.mu.Lock()
+	if q.heartbeat.changes == nil {
+		q.heartbeat.changes = make(chan HealthEvent, 16)
+	}
+	q.heartbeat.cancel = cancel
+	q.heartbeat.done = done
+	q.heartbeat.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			q.beat(ctx, interval)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// StopHeartbeat stops the heartbeat, waiting for a beat in flight to finish
+func (q *Workspace) StopHeartbeat() {
+	q.heartbeat.mu.Lock()
+	cancel, done := q.heartbeat.cancel, q.heartbeat.done
+	q.heartbeat.cancel, q.heartbeat.done = nil, nil
+	q.heartbeat.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// Healthy reports whether the last heartbeats were answered. It is false until the
+// first heartbeat is.
+func (q *Workspace) Healthy() bool {
+	q.heartbeat.mu.Lock()
+	defer q.heartbeat.mu.Unlock()
+	return q.heartbeat.status.Healthy
+}
+
+// Health returns the connection health measured by the heartbeat
+func (q *Workspace) Health() HealthStatus {
+	q.heartbeat.mu.Lock()
+	defer q.heartbeat.mu.Unlock()
+	return q.heartbeat.status
+}
+
+// HealthChanges returns a channel receiving each change between healthy and unhealthy.
+// Changes are dropped if the channel's buffer is full.
+func (q *Workspace) HealthChanges() <-chan HealthEvent {
+	q.heartbeat.mu.Lock()
+	defer q.heartbeat.mu.Unlock()
+	if q.heartbeat.changes == nil {
+		q.heartbeat.changes = make(chan HealthEvent, 16)
+	}
+	return q.heartbeat.changes
+}
+
+// beat sends one heartbeat, waiting at most the interval or the workspace timeout for
+// the reply, and records the result
+func (q *Workspace) beat(ctx context.Context, interval time.Duration) {
+	wait := interval
+	if timeout := time.Duration(q.timeout) * time.Second; timeout > 0 && timeout < wait {
+		wait = timeout
+	}
+	beatCtx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+
+	start := time.Now()
+	reply, err := q.sendWithRetryCtx(beatCtx, "/thump", "", nil)
+	if ctx.Err() != nil {
+		return // Stopped, not missed
+	}
+	if errors.Is(err, context.DeadlineExceeded) || (err == nil && isTimeoutReply(reply)) {
+		err = ErrTimeout
+	}
+	if err == nil {
+		err = checkReplyStatus(reply)
+	}
+	q.recordBeat(time.Since(start), err)
+}
+
+// recordBeat updates the health with a heartbeat's result, announcing any change
+func (q *Workspace) recordBeat(latency time.Duration, err error) {
+	now := time.Now()
+	q.heartbeat.mu.Lock()
+	status := &q.heartbeat.status
+	wasHealthy := status.Healthy
+	var event *HealthEvent
+	if err == nil {
+		status.Beats++
+		status.ConsecutiveMisses = 0
+		status.LastSeen = now
+		status.LastLatency = latency
+		if status.Beats == 1 || latency < status.MinLatency {
+			status.MinLatency = latency
+		}
+		status.MaxLatency = max(status.MaxLatency, latency)
+		q.heartbeat.totalLatency += latency
+		status.AvgLatency = q.heartbeat.totalLatency / time.Duration(status.Beats)
+		status.Healthy = true
+		if !wasHealthy {
+			event = &HealthEvent{Healthy: true, At: now, Latency: latency}
+		}
+	} else {
+		status.Missed++
+		status.ConsecutiveMisses++
+		if wasHealthy && status.ConsecutiveMisses >= HeartbeatMisses {
+			status.Healthy = false
+			event = &HealthEvent{Healthy: false, At: now, Err: err}
+		}
+	}
+	changes := q.heartbeat.changes
+	q.heartbeat.mu.Unlock()
+
+	if event == nil {
+		return
+	}
+	if event.Healthy {
+		q.log().Infof("QLab heartbeat restored (%v)", latency)
+	} else {
+		q.log().Warnf("QLab missed %d heartbeats: %v", HeartbeatMisses, err)
+		if q.wasConnected.Load() {
+			q.notifyDisconnect(DisconnectTimeoutStorm, "/thump", err)
+			q.wasConnected.Store(false)
+		}
+	}
+	select {
+	case changes <- *event:
+	default:
+		q.log().Debug("Dropped health change, channel full")
+	}
+}
@@ -0,0 +1,67 @@
+package qlab
+
+import (
+	"testing"
+	"time"
+)
+
+// TestHeartbeat tests health transitions as QLab stops and resumes answering heartbeats
+func TestHeartbeat(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	var disconnects []DisconnectEvent
+	workspace.OnDisconnectEvent(func(event DisconnectEvent) { disconnects = append(disconnects, event) })
+
+	if workspace.Healthy() {
+		t.Error("Expected no health before the first heartbeat")
+	}
+	if err := workspace.StartHeartbeat(0); err == nil {
+		t.Error("Expected a zero interval to be refused")
+	}
+	if err := workspace.StartHeartbeat(300 * time.Millisecond); err != nil {
+		t.Fatalf("StartHeartbeat failed: %v", err)
+	}
+	changes := workspace.HealthChanges()
+
+	expectChange := func(healthy bool) HealthEvent {
+		t.Helper()
+		select {
+		case event := <-changes:
+			if event.Healthy != healthy {
+				t.Fatalf("Expected healthy=%t, got %+v", healthy, event)
+			}
+			return event
+		case <-time.After(3 * time.Second):
+			t.Fatalf("Expected a change to healthy=%t", healthy)
+		}
+		return HealthEvent{}
+	}
+
+	if event := expectChange(true); event.Latency <= 0 {
+		t.Errorf("Expected the latency of the first heartbeat, got %+v", event)
+	}
+	health := workspace.Health()
+	if !health.Healthy || health.LastSeen.IsZero() || health.MinLatency > health.MaxLatency {
+		t.Errorf("Unexpected health: %+v", health)
+	}
+
+	mockServer.SetThumpEnabled(false)
+	if event := expectChange(false); event.Err == nil {
+		t.Errorf("Expected the heartbeat error, got %+v", event)
+	}
+	if health := workspace.Health(); health.ConsecutiveMisses < HeartbeatMisses || health.Missed < HeartbeatMisses {
+		t.Errorf("Expected the missed heartbeats counted, got %+v", health)
+	}
+	if len(disconnects) != 1 || disconnects[0].Address != "/thump" {
+		t.Errorf("Expected one disconnect for the missed heartbeats, got %+v", disconnects)
+	}
+
+	mockServer.SetThumpEnabled(true)
+	expectChange(true)
+
+	workspace.StopHeartbeat()
+	beats := workspace.Health().Beats
+	time.Sleep(700 * time.Millisecond)
+	if got := workspace.Health().Beats; got != beats {
+		t.Errorf("Expected no heartbeats after StopHeartbeat, went from %d to %d", beats, got)
+	}
+}
@@ -0,0 +1,98 @@
+package qlab
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestMatchCueIdentities tests that source cues keep the uniqueID of the QLab cue they were
+// transmitted as when their number or name changes
+func TestMatchCueIdentities(t *testing.T) {
+	workspace := &Workspace{}
+	cachedCues := map[string]map[string]any{
+		"1":                 {"type": "memo", "number": "1", "name": "House open", "uniqueID": "A"},
+		"2":                 {"type": "audio", "number": "2", "name": "Thunder", "fileTarget": "thunder.wav", "uniqueID": "B"},
+		"@0[memo:Preshow]":  {"type": "memo", "name": "Preshow", "uniqueID": "C"},
+		"5":                 {"type": "memo", "number": "5", "name": "Blackout", "uniqueID": "D"},
+		"6":                 {"type": "memo", "number": "6", "name": "Blackout", "uniqueID": "E"},
+		"@1[memo:Dropped]":  {"type": "memo", "name": "Dropped", "uniqueID": "F"},
+		"@2[memo:Declared]": {"type": "memo", "name": "Declared", "uniqueID": "G"},
+	}
+	sourceCues := map[string]map[string]any{
+		"1":                 {"type": "memo", "number": "1", "name": "House open"},
+		"10":                {"type": "audio", "number": "10", "name": "Storm", "fileTarget": "thunder.wav"},
+		"@0[memo:Walk-in]":  {"type": "memo", "name": "Walk-in"},
+		"7":                 {"type": "memo", "number": "7", "name": "Blackout"},
+		"@1[memo:Renamed]":  {"type": "memo", "name": "Renamed", "uniqueID": "G"},
+		"@2[memo:Recorded]": {"type": "memo", "name": "Recorded"},
+	}
+	meta := &CacheMetadata{Identities: map[string]string{"@0[memo:Walk-in]": "C"}}
+
+	identities := workspace.matchCueIdentities(sourceCues, cachedCues, nil, meta)
+	expected := map[string]string{
+		"10":               "B", // Renumbered and renamed, same file target
+		"@0[memo:Walk-in]": "C", // Recorded by the cache
+		"@1[memo:Renamed]": "G", // Declared in the source
+	}
+	if len(identities) != len(expected) {
+		t.Fatalf("Expected identities %v, got %v", expected, identities)
+	}
+	for key, id := range expected {
+		if identities[key] != id {
+			t.Errorf("Expected %s to be %s, got %q", key, id, identities[key])
+		}
+	}
+	// Two cached cues named Blackout left the source, so cue 7 can't be told apart and is new
+	if id, ok := identities["7"]; ok {
+		t.Errorf("Expected an ambiguous match to be left alone, got %s", id)
+	}
+}
+
+// TestRenumberKeepsIdentity tests that renumbering a cue in the source updates the QLab cue
+// in place instead of deleting it and creating another
+func TestRenumberKeepsIdentity(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+	workspace.SetCacheStore(NewMemoryCacheStore())
+	workspace.SetSyncDeletions(true)
+	workspace.SetSkipInbox(true)
+	path := filepath.Join(t.TempDir(), "show.json")
+
+	source := func(number string) map[string]any {
+		return map[string]any{"cues": []any{
+			map[string]any{"type": "memo", "number": "1", "name": "House open"},
+			map[string]any{"type": "memo", "number": number, "name": "Blackout"},
+		}}
+	}
+
+	if _, err := workspace.TransmitWorkspaceData(path, source("2")); err != nil {
+		t.Fatalf("First transmit failed: %v", err)
+	}
+	count := mockServer.GetCueCount()
+	comparison, err := workspace.PerformThreeWayComparison(path, source("2"))
+	if err != nil {
+		t.Fatalf("PerformThreeWayComparison failed: %v", err)
+	}
+	blackoutID := comparison.CueResults["2"].ExistingID
+	if blackoutID == "" {
+		t.Fatal("Expected cue 2 to be in QLab after the first transmit")
+	}
+
+	comparison, err = workspace.TransmitWorkspaceData(path, source("20"))
+	if err != nil {
+		t.Fatalf("Second transmit failed: %v", err)
+	}
+	result := comparison.CueResults["20"]
+	if result == nil || result.Action != "update" || result.ExistingID != blackoutID || result.PreviousKey != "2" {
+		t.Fatalf("Expected cue 20 to update cue 2 in place, got %+v", result)
+	}
+	if _, deleted := comparison.CueResults["2"]; deleted {
+		t.Errorf("Expected the old number not to be deleted, got %+v", comparison.CueResults["2"])
+	}
+	if got := mockServer.GetCueCount(); got != count {
+		t.Errorf("Expected %d cues after renumbering, got %d", count, got)
+	}
+	if cue := mockServer.GetCue(blackoutID); cue == nil || cue.Number != "20" {
+		t.Errorf("Expected the cue renumbered to 20, got %+v", cue)
+	}
+}
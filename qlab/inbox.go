@@ -0,0 +1,58 @@
+package qlab
+
+// SetInboxName sets the name of the cue list imported content is staged in, "Cuejitsu
+// Inbox" by default. An existing list with the name is used; otherwise it is created by
+// the first transmit that creates cues. Set it before Init to pick up an existing list.
+func (q *Workspace) SetInboxName(name string) {
+	q.inboxName = name
+	q.setInboxID("")
+}
+
+// SetSkipInbox sets whether the staging cue list is never looked for or created, for
+// read-only connections and workspaces that shouldn't gain an extra cue list. Deletion
+// sync then only deletes from the cue lists the source defines.
+func (q *Workspace) SetSkipInbox(skip bool) {
+	q.skipInbox = skip
+	if skip {
+		q.setInboxID("")
+	}
+}
+
+// inboxListName returns the name of the staging cue list
+func (q *Workspace) inboxListName() string {
+	if q.inboxName != "" {
+		return q.inboxName
+	}
+	return cuejitsuInboxName
+}
+
+// managedInboxName returns the name of the staging cue list deletion sync manages, or ""
+// when the inbox is skipped
+func (q *Workspace) managedInboxName() string {
+	if q.skipInbox {
+		return ""
+	}
+	return q.inboxListName()
+}
+
+// ensureInbox creates the staging cue list before a transmit creates cues, unless it is
+// skipped, already known or this is a dry run. Failing to create it doesn't stop the
+// transmit.
+func (q *Workspace) ensureInbox() {
+	if q.skipInbox || q.dryRun || q.getInboxID() != "" {
+		return
+	}
+	if _, err := q.ensureCuejitsuInbox(); err != nil {
+		q.log().Warnf("Failed to ensure %s exists: %v", q.inboxListName(), err)
+	}
+}
+
+// createsCues reports whether any change result creates a cue
+func createsCues(results map[string]*CueChangeResult) bool {
+	for _, result := range results {
+		if result.Action == "create" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package qlab
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestInboxCreatedOnImport tests that the staging cue list is created by the first transmit
+// that creates cues, under a custom name, and not at all when it is skipped
+func TestInboxCreatedOnImport(t *testing.T) {
+	workspaceData := map[string]any{"cues": []any{
+		map[string]any{"type": "memo", "number": "1", "name": "Top of show"},
+	}}
+
+	t.Run("custom name", func(t *testing.T) {
+		workspace, _ := setupWorkspaceWithCleanup(t)
+		workspace.SetInboxName("Staging")
+
+		if _, err := workspace.TransmitWorkspaceData(filepath.Join(t.TempDir(), "show.json"), workspaceData); err != nil {
+			t.Fatalf("TransmitWorkspaceData failed: %v", err)
+		}
+		inboxID := workspace.getInboxID()
+		if inboxID == "" {
+			t.Fatal("Expected the inbox created by the transmit")
+		}
+		if listID := workspace.lookupCueList("Staging"); listID != inboxID {
+			t.Errorf("Expected the inbox named Staging, got %q indexed for %s", listID, inboxID)
+		}
+		if listID := workspace.lookupCueList(cuejitsuInboxName); listID != "" {
+			t.Errorf("Expected no %s cue list, got %s", cuejitsuInboxName, listID)
+		}
+	})
+
+	t.Run("skipped", func(t *testing.T) {
+		workspace, mockServer := setupWorkspaceWithCleanup(t)
+		workspace.SetSkipInbox(true)
+
+		if _, err := workspace.TransmitWorkspaceData(filepath.Join(t.TempDir(), "show.json"), workspaceData); err != nil {
+			t.Fatalf("TransmitWorkspaceData failed: %v", err)
+		}
+		if inboxID := workspace.getInboxID(); inboxID != "" {
+			t.Errorf("Expected no inbox, got %s", inboxID)
+		}
+		report, err := workspace.ValidateIndexes()
+		if err != nil {
+			t.Fatalf("ValidateIndexes failed: %v", err)
+		}
+		if report.CueLists != 1 {
+			t.Errorf("Expected only the main cue list, got %d cue lists", report.CueLists)
+		}
+		if mockServer.GetCueCount() != 1 {
+			t.Errorf("Expected the cue created, got %d cues", mockServer.GetCueCount())
+		}
+	})
+}
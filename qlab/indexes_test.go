@@ -0,0 +1,64 @@
+package qlab
+
+import (
+	"testing"
+)
+
+// TestValidateIndexesRepairsDivergences tests that stale, missing, and reassigned entries are reported and repaired
+func TestValidateIndexesRepairsDivergences(t *testing.T) {
+	workspace, _ := setupWorkspaceWithCleanup(t)
+
+	first, err := workspace.createCueWithoutTarget(map[string]any{"type": "memo", "name": "First"}, "1")
+	if err != nil {
+		t.Fatalf("createCueWithoutTarget failed: %v", err)
+	}
+	second, err := workspace.createCueWithoutTarget(map[string]any{"type": "memo", "name": "Second"}, "2")
+	if err != nil {
+		t.Fatalf("createCueWithoutTarget failed: %v", err)
+	}
+
+	// Simulate drift left behind by an aborted sync
+	workspace.cueNumbers = map[string]string{
+		"1":  second,       // Reassigned
+		"99": "deleted-id", // Stale
+	}
+
+	report, err := workspace.ValidateIndexes()
+	if err != nil {
+		t.Fatalf("ValidateIndexes failed: %v", err)
+	}
+	if report.OK() {
+		t.Fatal("Expected divergences to be reported")
+	}
+
+	found := make(map[string]IndexDivergence)
+	for _, d := range report.Divergences {
+		if d.Index == "cueNumbers" {
+			found[d.Key] = d
+		}
+	}
+	if d := found["1"]; d.IndexedID != second || d.QLabID != first {
+		t.Errorf("Expected cue 1 reassigned from %s to %s, got %+v", second, first, d)
+	}
+	if d := found["2"]; d.IndexedID != "" || d.QLabID != second {
+		t.Errorf("Expected cue 2 missing from index, got %+v", d)
+	}
+	if d := found["99"]; d.IndexedID != "deleted-id" || d.QLabID != "" {
+		t.Errorf("Expected stale cue 99, got %+v", d)
+	}
+
+	if workspace.cueNumbers["1"] != first || workspace.cueNumbers["2"] != second {
+		t.Errorf("Expected index repaired, got %v", workspace.cueNumbers)
+	}
+	if _, ok := workspace.cueNumbers["99"]; ok {
+		t.Error("Expected stale entry removed")
+	}
+
+	report, err = workspace.ValidateIndexes()
+	if err != nil {
+		t.Fatalf("ValidateIndexes failed: %v", err)
+	}
+	if !report.OK() {
+		t.Errorf("Expected no divergences after repair, got %v", report.Divergences)
+	}
+}
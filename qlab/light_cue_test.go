@@ -0,0 +1,66 @@
+package qlab
+
+import (
+	"maps"
+	"strings"
+	"testing"
+)
+
+// TestLightCommandText tests building light command text and reading it back
+func TestLightCommandText(t *testing.T) {
+	levels := map[string]float64{"Front Wash": 75, "Back": 30, "Cyc.blue": 12.5}
+	text := LightCommandText(levels)
+	if want := "Back = 30\nCyc.blue = 12.5\nFront Wash = 75"; text != want {
+		t.Errorf("Expected %q, got %q", want, text)
+	}
+
+	parsed, err := ParseLightCommandText("\n" + text + "\n")
+	if err != nil || !maps.Equal(parsed, levels) {
+		t.Errorf("Expected the levels back, got %v (%v)", parsed, err)
+	}
+	if _, err := ParseLightCommandText("Front Wash = full"); err == nil {
+		t.Error("Expected a non-numeric level to fail")
+	}
+}
+
+// TestCreateLightCue tests that light commands, collate, and prune are sent and read back
+func TestCreateLightCue(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+
+	cueID, err := workspace.createCueWithoutTarget(map[string]any{
+		"type":          "light",
+		"name":          "Warm",
+		"lightCommands": map[string]any{"Front Wash": 75.0, "Back": 30.0},
+		"alwaysCollate": true,
+		"prune":         true,
+	}, "")
+	if err != nil {
+		t.Fatalf("createCueWithoutTarget failed: %v", err)
+	}
+
+	cue := mockServer.GetCue(cueID)
+	if got := cue.Properties["lightCommandText"]; got != "Back = 30\nFront Wash = 75" {
+		t.Errorf("Expected the built command text, got %q", got)
+	}
+	if cue.Properties["alwaysCollate"] != "1" {
+		t.Errorf("Expected alwaysCollate set, got %q", cue.Properties["alwaysCollate"])
+	}
+	pruned := false
+	for _, msg := range mockServer.GetReceivedMessages() {
+		pruned = pruned || strings.HasSuffix(msg.Address, "/cue_id/"+cueID+"/prune")
+	}
+	if !pruned {
+		t.Error("Expected the cue to be pruned")
+	}
+
+	enriched := map[string]any{"type": "Light", "uniqueID": cueID}
+	workspace.enrichCueProperties(enriched, cueID)
+	levels, err := ParseLightCommandText(enriched["lightCommandText"].(string))
+	if err != nil || levels["Front Wash"] != 75 || enriched["alwaysCollate"] != true {
+		t.Errorf("Expected the light commands read back, got %v (%v)", enriched, err)
+	}
+
+	if err := workspace.updateCueProperties(cueID, map[string]any{"type": "light", "lightCommands": map[string]any{"Back": "full"}}); err == nil {
+		t.Error("Expected a non-numeric level to fail the update")
+	}
+}
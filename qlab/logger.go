@@ -0,0 +1,54 @@
+package qlab
+
+import "github.com/charmbracelet/log"
+
+// Logger receives the package's log output. Messages take optional structured fields as
+// key-value pairs; the f variants format like fmt.Sprintf. A *log.Logger from
+// charmbracelet/log satisfies it, as does NopLogger.
+type Logger interface {
+	Debug(msg any, keyvals ...any)
+	Info(msg any, keyvals ...any)
+	Warn(msg any, keyvals ...any)
+	Error(msg any, keyvals ...any)
+	Debugf(format string, args ...any)
+	Infof(format string, args ...any)
+	Warnf(format string, args ...any)
+	Errorf(format string, args ...any)
+}
+
+// NopLogger discards everything logged to it, for embedders that want the library silent
+type NopLogger struct{}
+
+func (NopLogger) Debug(any, ...any)     {}
+func (NopLogger) Info(any, ...any)      {}
+func (NopLogger) Warn(any, ...any)      {}
+func (NopLogger) Error(any, ...any)     {}
+func (NopLogger) Debugf(string, ...any) {}
+func (NopLogger) Infof(string, ...any)  {}
+func (NopLogger) Warnf(string, ...any)  {}
+func (NopLogger) Errorf(string, ...any) {}
+
+// defaultLogger is used when no logger is set: the global charmbracelet logger, looked up
+// on every call so log.SetDefault and log.SetLevel keep working
+func defaultLogger() Logger {
+	return log.Default()
+}
+
+// orDefaultLogger returns logger, or the default logger if it is nil
+func orDefaultLogger(logger Logger) Logger {
+	if logger == nil {
+		return defaultLogger()
+	}
+	return logger
+}
+
+// SetLogger routes the workspace's logging to logger, or back to the global charmbracelet
+// logger when nil
+func (q *Workspace) SetLogger(logger Logger) {
+	q.logger = logger
+}
+
+// log returns the logger the workspace logs to
+func (q *Workspace) log() Logger {
+	return orDefaultLogger(q.logger)
+}
@@ -0,0 +1,66 @@
+package qlab
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// TestSetLogger tests that workspace logging goes to the logger set instead of the global one
+func TestSetLogger(t *testing.T) {
+	workspace, _ := setupWorkspaceWithCleanup(t)
+
+	var global bytes.Buffer
+	defaultLog := log.Default()
+	log.SetDefault(log.NewWithOptions(&global, log.Options{Level: log.DebugLevel}))
+	t.Cleanup(func() { log.SetDefault(defaultLog) })
+
+	var routed bytes.Buffer
+	workspace.SetLogger(log.NewWithOptions(&routed, log.Options{Level: log.DebugLevel}))
+	workspace.SetDryRun(true)
+	workspace.SendWithArgs("/workspace/"+workspace.WorkspaceID()+"/new", "memo")
+
+	if !strings.Contains(routed.String(), "[DRY RUN]") {
+		t.Errorf("Expected the dry run logged to the workspace logger, got %q", routed.String())
+	}
+	if global.Len() != 0 {
+		t.Errorf("Expected nothing logged globally, got %q", global.String())
+	}
+
+	workspace.SetLogger(NopLogger{})
+	workspace.SendWithArgs("/workspace/"+workspace.WorkspaceID()+"/new", "memo")
+	if global.Len() != 0 {
+		t.Errorf("Expected NopLogger to silence the workspace, got %q", global.String())
+	}
+
+	workspace.SetLogger(nil)
+	workspace.SendWithArgs("/workspace/"+workspace.WorkspaceID()+"/new", "memo")
+	if !strings.Contains(global.String(), "[DRY RUN]") {
+		t.Errorf("Expected a nil logger to restore the global logger, got %q", global.String())
+	}
+}
+
+// TestClientLogger tests that workspaces created by a client inherit its logger
+func TestClientLogger(t *testing.T) {
+	port, err := getFreePort()
+	if err != nil {
+		t.Fatalf("Failed to get free port: %v", err)
+	}
+	client := NewClient("localhost", port)
+	client.SetLogger(NopLogger{})
+	t.Cleanup(func() {
+		client.Close()
+		time.Sleep(150 * time.Millisecond)
+	})
+
+	workspace, err := client.Workspace("LOGGED-WORKSPACE-ID")
+	if err != nil {
+		t.Fatalf("Workspace failed: %v", err)
+	}
+	if _, ok := workspace.log().(NopLogger); !ok {
+		t.Errorf("Expected the workspace to log to the client's logger, got %T", workspace.log())
+	}
+}
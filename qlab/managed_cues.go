@@ -0,0 +1,164 @@
+package qlab
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ClearManagedCues deletes the cues this package transmitted to the workspace: those the
+// newest cache snapshot of each cue file sent to it records, and with external IDs turned
+// on, every cue stamped with one. Cues added in QLab are never touched, and cue lists are
+// kept; DeleteCueList removes those. A group takes the cues inside it along. It returns how
+// many cues were deleted.
+func (q *Workspace) ClearManagedCues() (int, error) {
+	q.opMu.Lock()
+	defer q.opMu.Unlock()
+
+	managed, err := q.managedCueIDs()
+	if err != nil {
+		return 0, err
+	}
+
+	var cueLists []any
+	if q.externalIDKey != "" {
+		// Stamped IDs are only read when enriching the snapshot
+		state, err := q.queryCurrentWorkspaceState()
+		if err != nil {
+			return 0, fmt.Errorf("failed to read the workspace: %w", err)
+		}
+		cueLists, _ = state["data"].([]any)
+	} else {
+		q.InvalidateCueCache()
+		if cueLists, err = q.getCueLists(); err != nil {
+			return 0, fmt.Errorf("failed to read the workspace: %w", err)
+		}
+	}
+
+	// Depth-first, leaving out the cues inside a group that is deleted itself
+	var doomed []map[string]any
+	var walk func(cues []any)
+	walk = func(cues []any) {
+		for _, cueData := range cues {
+			cue, ok := cueData.(map[string]any)
+			if !ok {
+				continue
+			}
+			uniqueID, _ := cue["uniqueID"].(string)
+			if managed[uniqueID] || (q.externalIDKey != "" && cue[q.externalIDKey] != nil) {
+				doomed = append(doomed, cue)
+				continue
+			}
+			if children, ok := cue["cues"].([]any); ok {
+				walk(children)
+			}
+		}
+	}
+	for _, cueListData := range cueLists {
+		if cueList, ok := cueListData.(map[string]any); ok {
+			children, _ := cueList["cues"].([]any)
+			walk(children)
+		}
+	}
+
+	deleted := 0
+	defer q.InvalidateCueCache()
+	for _, cue := range doomed {
+		uniqueID, _ := cue["uniqueID"].(string)
+		if err := q.deleteCue(uniqueID); err != nil {
+			return deleted, fmt.Errorf("failed to delete cue %s: %w", uniqueID, err)
+		}
+		q.forgetCueNumbers(cue)
+		deleted++
+	}
+	q.log().Infof("Cleared %d managed cues", deleted)
+	return deleted, nil
+}
+
+// forgetCueNumbers removes a deleted cue's number, and those of the cues inside it, from
+// the number index
+func (q *Workspace) forgetCueNumbers(cue map[string]any) {
+	if number := formatCueNumber(cue["number"]); number != "" {
+		q.forgetCueNumber(number)
+	}
+	children, _ := cue["cues"].([]any)
+	for _, child := range children {
+		if childCue, ok := child.(map[string]any); ok {
+			q.forgetCueNumbers(childCue)
+		}
+	}
+}
+
+// managedCueIDs returns the uniqueIDs the newest cache snapshot of each cue file sent to
+// this workspace records for its source cues. Snapshots written before identities were
+// recorded hold QLab's whole workspace, so they are left out.
+func (q *Workspace) managedCueIDs() (map[string]bool, error) {
+	managed := make(map[string]bool)
+	store, err := q.cache()
+	if errors.Is(err, ErrCacheDisabled) {
+		return managed, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	entries, err := store.List("")
+	if err != nil {
+		return nil, err
+	}
+
+	target := q.cacheTarget()
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if seen[entry.Name] || !strings.HasSuffix(entry.Name, target) {
+			continue
+		}
+		seen[entry.Name] = true // Entries are newest first
+		data, err := store.Load(entry.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load cache snapshot %s: %w", entry.Key, err)
+		}
+		_, meta, err := decodeCacheSnapshot(data, entry.SavedAt)
+		if err != nil {
+			q.log().Warnf("Skipping unreadable cache snapshot %s: %v", entry.Key, err)
+			continue
+		}
+		for _, uniqueID := range meta.Identities {
+			managed[uniqueID] = true
+		}
+	}
+	return managed, nil
+}
+
+// DeleteCueList deletes the cue list with a name and every cue in it
+func (q *Workspace) DeleteCueList(name string) error {
+	q.opMu.Lock()
+	defer q.opMu.Unlock()
+
+	q.InvalidateCueCache()
+	cueLists, err := q.getCueLists()
+	if err != nil {
+		return fmt.Errorf("failed to read cue lists: %w", err)
+	}
+
+	var listID string
+	for _, cueListData := range cueLists {
+		if cueList, ok := cueListData.(map[string]any); ok && cueList["name"] == name {
+			listID, _ = cueList["uniqueID"].(string)
+			break
+		}
+	}
+	if listID == "" {
+		return fmt.Errorf("cue list %q: %w", name, ErrCueNotFound)
+	}
+
+	if err := q.deleteCue(listID); err != nil {
+		return fmt.Errorf("failed to delete cue list %q: %w", name, err)
+	}
+	q.forgetCueList(name)
+	if q.getInboxID() == listID {
+		q.setInboxID("")
+	}
+	q.InvalidateCueCache()
+	q.log().Infof("Deleted cue list %q", name)
+	return nil
+}
@@ -0,0 +1,67 @@
+package qlab
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// TestClearManagedCues tests that only the cues earlier transmits created are deleted
+func TestClearManagedCues(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+	workspace.SetCacheStore(NewMemoryCacheStore())
+	workspace.SetSkipInbox(true)
+
+	manual, err := workspace.createCueWithoutTarget(map[string]any{"type": "memo", "name": "Added by the designer"}, "100")
+	if err != nil {
+		t.Fatalf("Failed to create cue: %v", err)
+	}
+
+	source := map[string]any{"cues": []any{
+		map[string]any{"type": "memo", "number": "1", "name": "House open"},
+		map[string]any{"type": "group", "number": "2", "name": "Scene", "cues": []any{
+			map[string]any{"type": "memo", "number": "2.1", "name": "Inside"},
+		}},
+	}}
+	comparison, err := workspace.TransmitWorkspaceData(filepath.Join(t.TempDir(), "show.json"), source)
+	if err != nil {
+		t.Fatalf("TransmitWorkspaceData failed: %v", err)
+	}
+	houseOpen := comparison.CueResults["1"].ExistingID
+
+	deleted, err := workspace.ClearManagedCues()
+	if err != nil {
+		t.Fatalf("ClearManagedCues failed: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("Expected cue 1 and the group deleted, got %d deletions", deleted)
+	}
+	if mockServer.GetCue(manual) == nil {
+		t.Error("Expected the designer's cue to be kept")
+	}
+	if houseOpen != "" && mockServer.GetCue(houseOpen) != nil {
+		t.Error("Expected the transmitted cue to be deleted")
+	}
+	if got := mockServer.GetCueCount(); got != 1 {
+		t.Errorf("Expected only the designer's cue left, got %d cues", got)
+	}
+}
+
+// TestDeleteCueList tests deleting a cue list by name
+func TestDeleteCueList(t *testing.T) {
+	workspace, _ := setupWorkspaceWithCleanup(t)
+
+	if _, err := workspace.ensureCueList("Scratch"); err != nil {
+		t.Fatalf("Failed to create cue list: %v", err)
+	}
+	if err := workspace.DeleteCueList("Scratch"); err != nil {
+		t.Fatalf("DeleteCueList failed: %v", err)
+	}
+	if listID := workspace.lookupCueList("Scratch"); listID != "" {
+		t.Errorf("Expected the cue list forgotten, got %s", listID)
+	}
+	if err := workspace.DeleteCueList("Scratch"); !errors.Is(err, ErrCueNotFound) {
+		t.Errorf("Expected ErrCueNotFound for a deleted cue list, got %v", err)
+	}
+}
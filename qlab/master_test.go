@@ -0,0 +1,110 @@
+package qlab
+
+import (
+	"testing"
+	"time"
+)
+
+// TestAlwaysAudition tests toggling and querying the workspace always-audition mode
+func TestAlwaysAudition(t *testing.T) {
+	workspace, _ := setupWorkspaceWithCleanup(t)
+
+	if err := workspace.SetAlwaysAudition(true); err != nil {
+		t.Fatalf("SetAlwaysAudition failed: %v", err)
+	}
+	enabled, err := workspace.AlwaysAudition()
+	if err != nil {
+		t.Fatalf("AlwaysAudition failed: %v", err)
+	}
+	if !enabled {
+		t.Error("Expected always-audition to be on")
+	}
+
+	if err := workspace.SetAlwaysAudition(false); err != nil {
+		t.Fatalf("SetAlwaysAudition failed: %v", err)
+	}
+	if enabled, _ := workspace.AlwaysAudition(); enabled {
+		t.Error("Expected always-audition to be off")
+	}
+}
+
+// TestDimAndUndim tests that Dim lowers running cues only and Undim restores them
+func TestDimAndUndim(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+
+	running, err := workspace.createCueWithoutTarget(map[string]any{"type": "audio", "name": "Music"}, "")
+	if err != nil {
+		t.Fatalf("createCueWithoutTarget failed: %v", err)
+	}
+	stopped, err := workspace.createCueWithoutTarget(map[string]any{"type": "audio", "name": "Effect"}, "")
+	if err != nil {
+		t.Fatalf("createCueWithoutTarget failed: %v", err)
+	}
+	for _, id := range []string{running, stopped} {
+		if err := workspace.SetCueMasterLevel(id, -6); err != nil {
+			t.Fatalf("SetCueMasterLevel failed: %v", err)
+		}
+	}
+	mockServer.SetCueRunning(running, true)
+
+	dimmed, err := workspace.Dim(20)
+	if err != nil {
+		t.Fatalf("Dim failed: %v", err)
+	}
+	if dimmed != 1 || !workspace.IsDimmed() {
+		t.Fatalf("Expected one dimmed cue, got %d", dimmed)
+	}
+	if level, _ := workspace.CueMasterLevel(running); level != -26 {
+		t.Errorf("Expected running cue at -26 dB, got %g", level)
+	}
+	if level, _ := workspace.CueMasterLevel(stopped); level != -6 {
+		t.Errorf("Expected stopped cue to stay at -6 dB, got %g", level)
+	}
+
+	if err := workspace.Undim(); err != nil {
+		t.Fatalf("Undim failed: %v", err)
+	}
+	if workspace.IsDimmed() {
+		t.Error("Expected workspace not to be dimmed after Undim")
+	}
+	if level, _ := workspace.CueMasterLevel(running); level != -6 {
+		t.Errorf("Expected running cue restored to -6 dB, got %g", level)
+	}
+}
+
+// TestSetCueMasterLevelWaitsForBatch tests that a level set made while another goroutine is
+// batching waits for it and is sent on its own rather than queued into that batch
+func TestSetCueMasterLevelWaitsForBatch(t *testing.T) {
+	workspace, _ := setupWorkspaceWithCleanup(t)
+
+	cueID, err := workspace.createCueWithoutTarget(map[string]any{"type": "audio", "name": "Music"}, "")
+	if err != nil {
+		t.Fatalf("createCueWithoutTarget failed: %v", err)
+	}
+
+	// Stand in for a batched transmit running on another goroutine
+	workspace.opMu.Lock()
+	batch := &batchState{expectedNumbers: make(map[string]string)}
+	workspace.batch = batch
+
+	done := make(chan error, 1)
+	go func() { done <- workspace.SetCueMasterLevel(cueID, -12) }()
+
+	select {
+	case err := <-done:
+		t.Fatalf("SetCueMasterLevel returned during the batch: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	workspace.batch = nil
+	workspace.opMu.Unlock()
+
+	if err := <-done; err != nil {
+		t.Fatalf("SetCueMasterLevel failed: %v", err)
+	}
+	if len(batch.queue) != 0 {
+		t.Errorf("Expected nothing queued into the batch, got %d messages", len(batch.queue))
+	}
+	if level, _ := workspace.CueMasterLevel(cueID); level != -12 {
+		t.Errorf("Expected cue at -12 dB, got %g", level)
+	}
+}
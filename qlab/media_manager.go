@@ -0,0 +1,331 @@
+package qlab
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MediaCollectMode is how a MediaManager brings a file into the media folder
+type MediaCollectMode string
+
+const (
+	MediaCopy     MediaCollectMode = "copy"
+	MediaHardLink MediaCollectMode = "link" // Falls back to a copy when the file is on another volume
+)
+
+// MediaManager collects the media of cues whose file target lies outside the workspace
+// folder into a media folder inside it, like QLab's own collect, and points the cues at the
+// collected files
+type MediaManager struct {
+	Folder string           // Media folder, relative to Root; "" for "media"
+	Mode   MediaCollectMode // "" for MediaCopy
+	Root   string           // Workspace folder; "" for QLab's basePath
+}
+
+// CollectedMedia is a file target a MediaManager collected
+type CollectedMedia struct {
+	Cue        string // Full cue number, or position key for unnumbered cues
+	Name       string
+	FileTarget string           // As written in the source
+	Source     string           // Absolute path the file was collected from
+	Target     string           // Absolute path in the media folder, now the cue's file target
+	Mode       MediaCollectMode // How the file was brought in, "" when an identical file was already there
+	Size       int64
+}
+
+// MediaCollectReport is the result of CollectMedia
+type MediaCollectReport struct {
+	Collected []CollectedMedia // In workspace order
+	Inside    int              // File targets already inside the workspace folder, left as they are
+}
+
+// Copied returns the files copied or linked into the media folder, leaving out the ones
+// collected before
+func (r *MediaCollectReport) Copied() []CollectedMedia {
+	var copied []CollectedMedia
+	for _, media := range r.Collected {
+		if media.Mode != "" {
+			copied = append(copied, media)
+		}
+	}
+	return copied
+}
+
+// SetMediaManager sets the media manager TransmitWorkspaceData collects media with before
+// comparing, nil for none
+func (q *Workspace) SetMediaManager(manager *MediaManager) {
+	q.mediaManager = manager
+}
+
+// CollectMedia collects the file targets of source data outside the workspace folder with
+// the media manager, returning a copy of the data with those cues pointed at the collected
+// files. A file whose name is taken in the media folder by a different file is collected
+// with a number added, e.g. thunder-2.wav; one already there with the same content is used
+// as it is, so syncing again doesn't copy it again. Relative paths resolve as in CheckMedia.
+// In a dry run nothing is copied, but the report says what would be.
+func (q *Workspace) CollectMedia(filePath string, workspaceData map[string]any) (map[string]any, *MediaCollectReport, error) {
+	return q.collectMedia(filePath, workspaceData, q.dryRun)
+}
+
+// collectMedia is CollectMedia, copying nothing in a dry run
+func (q *Workspace) collectMedia(filePath string, workspaceData map[string]any, dryRun bool) (map[string]any, *MediaCollectReport, error) {
+	manager := q.mediaManager
+	if manager == nil {
+		return nil, nil, fmt.Errorf("no media manager set")
+	}
+	root := manager.Root
+	if root == "" {
+		basePath, err := q.getWorkspaceBasePath()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to find the workspace folder: %w", err)
+		}
+		root = basePath
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get absolute path: %v", err)
+	}
+	folder := manager.Folder
+	if folder == "" {
+		folder = "media"
+	}
+	folder = filepath.Join(root, folder)
+	dir := ""
+	if filePath != "" {
+		absFilePath, err := filepath.Abs(filePath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get absolute path: %v", err)
+		}
+		dir = filepath.Dir(absFilePath)
+	}
+
+	collected, _ := cloneMergeValue(workspaceData).(map[string]any)
+	report := &MediaCollectReport{}
+	targets := make(map[string]string) // Collected path of each source file
+	claimed := make(map[string]bool)   // Paths in the media folder taken by this collection
+	var collectErr error
+	eachSourceCue(collected, q.sourceCueKey, func(cue map[string]any, key string) {
+		fileTarget, _ := cue["fileTarget"].(string)
+		if fileTarget == "" || collectErr != nil {
+			return
+		}
+		source := fileTarget
+		if !filepath.IsAbs(source) {
+			if dir != "" {
+				source = filepath.Join(dir, source)
+			} else if source, collectErr = q.resolveFilePath(source); collectErr != nil {
+				return
+			}
+		}
+		source = filepath.Clean(source)
+		if _, inside := pathInside(root, source); inside {
+			report.Inside++
+			return
+		}
+
+		media := CollectedMedia{Cue: key, FileTarget: fileTarget, Source: source}
+		media.Name, _ = cue["name"].(string)
+		info, err := os.Stat(source)
+		if err == nil && !info.Mode().IsRegular() {
+			err = errors.New("not a file")
+		}
+		if err != nil {
+			collectErr = fmt.Errorf("failed to collect media for cue %s: %w", key, err)
+			return
+		}
+		media.Size = info.Size()
+
+		if target, ok := targets[source]; ok {
+			media.Target = target
+		} else {
+			target, exists, err := mediaDestination(source, folder, claimed)
+			if err != nil {
+				collectErr = fmt.Errorf("failed to collect media for cue %s: %w", key, err)
+				return
+			}
+			media.Target = target
+			targets[source], claimed[target] = target, true
+			if !exists {
+				if media.Mode, err = q.placeMedia(source, target, manager.Mode, dryRun); err != nil {
+					collectErr = fmt.Errorf("failed to collect media for cue %s: %w", key, err)
+					return
+				}
+				q.log().Infof("Collected %s for cue %s into %s (%s)", source, key, target, media.Mode)
+			}
+		}
+		cue["fileTarget"] = media.Target
+		report.Collected = append(report.Collected, media)
+	})
+	if collectErr != nil {
+		return nil, nil, collectErr
+	}
+	return collected, report, nil
+}
+
+// eachSourceCue calls visit with every cue of source data and its key, parents before their
+// children
+func eachSourceCue(workspaceData map[string]any, keyOf cueKeyFunc, visit func(cue map[string]any, key string)) {
+	var walk func(cues []any, parentNumber string)
+	walk = func(cues []any, parentNumber string) {
+		for i, cueData := range cues {
+			cue, ok := cueData.(map[string]any)
+			if !ok {
+				continue
+			}
+			key, fullNumber := keyOf(cue, parentNumber, i)
+			visit(cue, key)
+			if children, ok := cue["cues"].([]any); ok {
+				walk(children, fullNumber)
+			}
+		}
+	}
+
+	if cues, ok := workspaceData["cues"].([]any); ok {
+		walk(cues, "")
+	} else if nested, ok := workspaceData["workspace"].(map[string]any); ok {
+		if cues, ok := nested["cues"].([]any); ok {
+			walk(cues, "")
+		}
+	} else if cueLists, ok := workspaceData["data"].([]any); ok {
+		for _, cueListData := range cueLists {
+			if cueList, ok := cueListData.(map[string]any); ok {
+				if cues, ok := cueList["cues"].([]any); ok {
+					walk(cues, "")
+				}
+			}
+		}
+	}
+}
+
+// pathInside returns path relative to root, and whether it's inside root
+func pathInside(root, path string) (string, bool) {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+// mediaDestination returns where in folder a source file is collected, and whether a file
+// with the same content is there already. Names taken by other files, or by other sources
+// in this collection, get -2, -3 and so on before the extension.
+func mediaDestination(source, folder string, claimed map[string]bool) (string, bool, error) {
+	base := filepath.Base(source)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	for n := 1; ; n++ {
+		name := base
+		if n > 1 {
+			name = fmt.Sprintf("%s-%d%s", stem, n, ext)
+		}
+		target := filepath.Join(folder, name)
+		if claimed[target] {
+			continue
+		}
+		same, err := sameMediaFile(source, target)
+		if errors.Is(err, os.ErrNotExist) {
+			return target, false, nil
+		}
+		if err != nil {
+			return "", false, err
+		}
+		if same {
+			return target, true, nil
+		}
+	}
+}
+
+// sameMediaFile reports whether two files are the same file or have the same content
+func sameMediaFile(a, b string) (bool, error) {
+	infoA, err := os.Stat(a)
+	if err != nil {
+		return false, err
+	}
+	infoB, err := os.Stat(b)
+	if err != nil {
+		return false, err
+	}
+	if os.SameFile(infoA, infoB) {
+		return true, nil
+	}
+	if !infoB.Mode().IsRegular() || infoA.Size() != infoB.Size() {
+		return false, nil
+	}
+	digestA, err := fileDigest(a)
+	if err != nil {
+		return false, err
+	}
+	digestB, err := fileDigest(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(digestA, digestB), nil
+}
+
+// fileDigest returns the SHA-256 of a file's content
+func fileDigest(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return nil, err
+	}
+	return hash.Sum(nil), nil
+}
+
+// placeMedia hard-links or copies source to target, returning how it was placed. Nothing is
+// written in a dry run.
+func (q *Workspace) placeMedia(source, target string, mode MediaCollectMode, dryRun bool) (MediaCollectMode, error) {
+	if mode == "" {
+		mode = MediaCopy
+	}
+	if dryRun {
+		return mode, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", err
+	}
+	if mode == MediaHardLink {
+		err := os.Link(source, target)
+		if err == nil {
+			return MediaHardLink, nil
+		}
+		q.log().Debug("Hard link failed, copying instead", "source", source, "error", err)
+	}
+	return MediaCopy, copyMediaFile(source, target)
+}
+
+// copyMediaFile copies source to target through a temporary file, so an interrupted copy
+// never leaves a partial file under the target's name
+func copyMediaFile(source, target string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.CreateTemp(filepath.Dir(target), ".collect-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(out.Name())
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(out.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(out.Name(), target)
+}
@@ -0,0 +1,106 @@
+package qlab
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestCollectMedia tests that media outside the workspace folder is copied into the media
+// folder with clashing names numbered, and that collecting again copies nothing
+func TestCollectMedia(t *testing.T) {
+	root, showDir, otherDir := t.TempDir(), t.TempDir(), t.TempDir()
+	files := map[string]string{
+		filepath.Join(showDir, "audio", "thunder.wav"): "RIFF thunder",
+		filepath.Join(otherDir, "thunder.wav"):         "RIFF other thunder",
+		filepath.Join(root, "sound", "rain.wav"):       "RIFF rain",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	workspaceData := map[string]any{"cues": []any{
+		map[string]any{"type": "audio", "number": "1", "fileTarget": "audio/thunder.wav"},
+		map[string]any{"type": "audio", "number": "2", "fileTarget": filepath.Join(otherDir, "thunder.wav")},
+		map[string]any{"type": "audio", "number": "3", "fileTarget": "audio/thunder.wav"},
+		map[string]any{"type": "audio", "number": "4", "fileTarget": filepath.Join(root, "sound", "rain.wav")},
+	}}
+
+	workspace := &Workspace{}
+	workspace.SetMediaManager(&MediaManager{Root: root})
+	filePath := filepath.Join(showDir, "show.json")
+	collected, report, err := workspace.CollectMedia(filePath, workspaceData)
+	if err != nil {
+		t.Fatalf("CollectMedia failed: %v", err)
+	}
+
+	want := []string{"thunder.wav", "thunder-2.wav", "thunder.wav", ""}
+	for i, name := range want {
+		cue := collected["cues"].([]any)[i].(map[string]any)
+		expected := filepath.Join(root, "media", name)
+		if name == "" {
+			expected = filepath.Join(root, "sound", "rain.wav")
+		}
+		if cue["fileTarget"] != expected {
+			t.Errorf("Cue %d: expected file target %s, got %v", i+1, expected, cue["fileTarget"])
+		}
+	}
+	if content, err := os.ReadFile(filepath.Join(root, "media", "thunder-2.wav")); err != nil || string(content) != "RIFF other thunder" {
+		t.Errorf("Expected the other thunder copied as thunder-2.wav, got %q (%v)", content, err)
+	}
+	if len(report.Collected) != 3 || len(report.Copied()) != 2 || report.Inside != 1 {
+		t.Errorf("Expected 3 cues collected from 2 copies and 1 inside, got %+v", report)
+	}
+	if source := workspaceData["cues"].([]any)[0].(map[string]any)["fileTarget"]; source != "audio/thunder.wav" {
+		t.Errorf("Expected the source data left alone, got %v", source)
+	}
+
+	_, report, err = workspace.CollectMedia(filePath, workspaceData)
+	if err != nil {
+		t.Fatalf("Second CollectMedia failed: %v", err)
+	}
+	if len(report.Collected) != 3 || len(report.Copied()) != 0 {
+		t.Errorf("Expected nothing copied again, got %+v", report.Copied())
+	}
+}
+
+// TestTransmitCollectsMedia tests that a transmit with a media manager hard-links media into
+// the workspace folder and creates the cue with the collected file
+func TestTransmitCollectsMedia(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+	workspace.SetCacheStore(NewMemoryCacheStore())
+	workspace.SetSkipInbox(true)
+	root, showDir := t.TempDir(), t.TempDir()
+	source := filepath.Join(showDir, "intro.wav")
+	if err := os.WriteFile(source, []byte("RIFF intro"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	workspace.SetMediaManager(&MediaManager{Root: root, Folder: "Audio", Mode: MediaHardLink})
+
+	workspaceData := map[string]any{"cues": []any{
+		map[string]any{"type": "audio", "number": "1", "name": "Intro", "fileTarget": "intro.wav"},
+	}}
+	comparison, err := workspace.TransmitWorkspaceData(filepath.Join(showDir, "show.json"), workspaceData)
+	if err != nil {
+		t.Fatalf("Transmit failed: %v", err)
+	}
+	target := filepath.Join(root, "Audio", "intro.wav")
+	if comparison.Media == nil || len(comparison.Media.Collected) != 1 || comparison.Media.Collected[0].Target != target {
+		t.Fatalf("Expected intro.wav collected into %s, got %+v", target, comparison.Media)
+	}
+	sourceInfo, _ := os.Stat(source)
+	targetInfo, err := os.Stat(target)
+	if err != nil || !os.SameFile(sourceInfo, targetInfo) {
+		t.Errorf("Expected %s hard-linked to the source (%v)", target, err)
+	}
+
+	uniqueID, _ := workspace.lookupCueNumber("1")
+	if cue := mockServer.GetCue(uniqueID); cue == nil || filepath.Base(cue.FileTarget) != "intro.wav" {
+		t.Errorf("Expected the cue created with the collected file, got %+v", cue)
+	}
+}
@@ -0,0 +1,101 @@
+package qlab
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// mediaTestSource writes a cue file directory with some media and returns source data
+// with one cue for each kind of problem
+func mediaTestSource(t *testing.T) (string, map[string]any) {
+	dir := t.TempDir()
+	files := map[string]string{"audio/intro.wav": "RIFF", "audio/empty.wav": "", "notes.txt": "text"}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return filepath.Join(dir, "show.json"), map[string]any{"cues": []any{
+		map[string]any{"type": "audio", "number": "1", "fileTarget": "audio/intro.wav"},
+		map[string]any{"type": "audio", "number": "2", "fileTarget": "audio/missing.wav"},
+		map[string]any{"type": "audio", "number": "3", "fileTarget": "audio/empty.wav"},
+		map[string]any{"type": "audio", "number": "4", "fileTarget": "audio"},
+		map[string]any{"type": "video", "number": "5", "fileTarget": "notes.txt"},
+		map[string]any{"type": "memo", "number": "6"},
+	}}
+}
+
+// TestCheckMedia tests the problems CheckMedia reports for each file target
+func TestCheckMedia(t *testing.T) {
+	filePath, workspaceData := mediaTestSource(t)
+
+	report, err := (&Workspace{}).CheckMedia(filePath, workspaceData)
+	if err != nil {
+		t.Fatalf("CheckMedia failed: %v", err)
+	}
+	if len(report.Files) != 1 || report.Files[0].Cue != "1" || report.Files[0].Extension != ".wav" || report.Files[0].Size != 4 {
+		t.Errorf("Expected cue 1's file to check out, got %+v", report.Files)
+	}
+
+	want := []MediaProblemKind{MediaMissing, MediaEmpty, MediaNotFile, MediaUnsupported}
+	if report.OK() || len(report.Problems) != len(want) {
+		t.Fatalf("Expected %d problems, got %v", len(want), report.Problems)
+	}
+	for i, kind := range want {
+		if got := report.Problems[i].Kind; got != kind {
+			t.Errorf("Problem %d: expected %s, got %s", i, kind, report.Problems[i])
+		}
+	}
+	if !errors.Is(report.Problems[0].Err, os.ErrNotExist) {
+		t.Errorf("Expected the missing file's error, got %v", report.Problems[0].Err)
+	}
+}
+
+// TestMediaPreflight tests that a transmit with bad media creates no cues
+func TestMediaPreflight(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetMediaPreflight(true)
+	filePath, workspaceData := mediaTestSource(t)
+
+	_, err := workspace.TransmitWorkspaceData(filePath, workspaceData)
+	var mediaErr *MediaError
+	if !errors.As(err, &mediaErr) || len(mediaErr.Problems) != 4 {
+		t.Fatalf("Expected a MediaError with 4 problems, got %v", err)
+	}
+	if got := mockServer.GetCueCount(); got != 0 {
+		t.Errorf("Expected no cues created, got %d", got)
+	}
+}
+
+// TestReceiveRelativePaths tests that received file targets inside the root are made
+// relative to it and those outside stay absolute
+func TestReceiveRelativePaths(t *testing.T) {
+	workspace, _ := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+	for number, fileTarget := range map[string]string{"1": "/shows/tour/audio/intro.wav", "2": "/library/thunder.wav"} {
+		if _, err := workspace.createCueWithoutTarget(map[string]any{"type": "audio", "fileTarget": fileTarget}, number); err != nil {
+			t.Fatalf("Failed to create cue %s: %v", number, err)
+		}
+	}
+
+	cues, err := workspace.ReceiveWorkspaceData(WithRelativePaths("/shows/tour"))
+	if err != nil {
+		t.Fatalf("ReceiveWorkspaceData failed: %v", err)
+	}
+	fileTargets := make(map[string]any)
+	for _, cueData := range cues {
+		if cue, ok := cueData.(map[string]any); ok {
+			fileTargets[formatCueNumber(cue["number"])] = cue["fileTarget"]
+		}
+	}
+	if fileTargets["1"] != "audio/intro.wav" || fileTargets["2"] != "/library/thunder.wav" {
+		t.Errorf("Expected cue 1 relative and cue 2 absolute, got %v", fileTargets)
+	}
+}
@@ -0,0 +1,78 @@
+package qlab
+
+import (
+	"testing"
+)
+
+// TestMergeToWorkspaceData tests that resolutions are applied to a copy of the source data
+// with the origin of every field recorded
+func TestMergeToWorkspaceData(t *testing.T) {
+	workspace := &Workspace{}
+	source := map[string]any{
+		"cues": []any{
+			map[string]any{"number": "1", "type": "audio", "name": "Thunder", "notes": "Loud"},
+			map[string]any{"number": "2", "type": "group", "name": "Storm", "cues": []any{
+				map[string]any{"number": "2.1", "type": "memo", "name": "Rain"},
+			}},
+			map[string]any{"number": "3", "type": "memo", "name": "Calm"},
+		},
+	}
+	qlabData := map[string]any{
+		"data": []any{
+			map[string]any{"name": "Main Cue List", "type": "Cue List", "cues": []any{
+				map[string]any{"uniqueID": "A", "number": "1", "type": "Audio", "name": "Thunder Clap", "notes": "Quiet"},
+				map[string]any{"uniqueID": "B", "number": "2", "type": "Group", "name": "Storm", "cues": []any{
+					map[string]any{"uniqueID": "C", "number": "2.1", "type": "Memo", "name": "Drizzle", "notes": "Soft"},
+				}},
+				map[string]any{"uniqueID": "D", "number": "3", "type": "Memo", "name": "Quiet"},
+			}},
+		},
+	}
+	comparison := &ThreeWayComparison{
+		SourceData:       source,
+		CurrentQLabData:  qlabData,
+		QLabChosenCues:   map[string]bool{"1": true},
+		QLabChosenFields: map[string]map[string]bool{"2.1": {"notes": true}},
+	}
+
+	merged, provenance, err := workspace.MergeToWorkspaceDataWithProvenance(comparison)
+	if err != nil {
+		t.Fatalf("MergeToWorkspaceDataWithProvenance failed: %v", err)
+	}
+
+	cues := merged["cues"].([]any)
+	first := cues[0].(map[string]any)
+	if first["name"] != "Thunder Clap" || first["notes"] != "Quiet" || first["type"] != "audio" {
+		t.Errorf("Expected cue 1 kept from QLab with the source type, got %v", first)
+	}
+	if _, ok := first["uniqueID"]; ok {
+		t.Errorf("Expected QLab's uniqueID left out, got %v", first)
+	}
+	child := cues[1].(map[string]any)["cues"].([]any)[0].(map[string]any)
+	if child["name"] != "Rain" || child["notes"] != "Soft" {
+		t.Errorf("Expected only the notes of cue 2.1 kept from QLab, got %v", child)
+	}
+	if cues[2].(map[string]any)["name"] != "Calm" {
+		t.Errorf("Expected cue 3 from the source, got %v", cues[2])
+	}
+
+	want := map[string]map[string]string{
+		"1":   {"number": "source", "type": "source", "name": "qlab", "notes": "qlab"},
+		"2.1": {"number": "source", "type": "source", "name": "source", "notes": "qlab"},
+		"3":   {"number": "source", "type": "source", "name": "source"},
+	}
+	for key, fields := range want {
+		for field, origin := range fields {
+			if got := provenance[key][field]; got != origin {
+				t.Errorf("Expected %s.%s from %s, got %q", key, field, origin, got)
+			}
+		}
+	}
+
+	if source["cues"].([]any)[0].(map[string]any)["name"] != "Thunder" {
+		t.Error("Expected the source data left unmodified")
+	}
+	if _, err := workspace.MergeToWorkspaceData(&ThreeWayComparison{}); err == nil {
+		t.Error("Expected a comparison without source data to fail")
+	}
+}
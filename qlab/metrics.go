@@ -0,0 +1,219 @@
+package qlab
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hypebeast/go-osc/osc"
+)
+
+// Metrics receives what the OSC layer does, for long-running processes that need
+// visibility into OSC health. Methods are called from any goroutine and must not block.
+type Metrics interface {
+	MessageSent(address string)                          // A message was handed to the transport
+	ReplyReceived(address string, latency time.Duration) // QLab answered a message after latency
+	Timeout(address string)                              // A message went unanswered within the timeout
+	Retry(address string)                                // A message is being sent again after a timeout
+}
+
+// SetMetrics sets where OSC metrics are reported, or nil to stop reporting them
+func (q *Workspace) SetMetrics(metrics Metrics) {
+	q.metrics = metrics
+}
+
+// reportSent reports every message of a packet as sent
+func (q *Workspace) reportSent(packet osc.Packet) {
+	if q.metrics == nil {
+		return
+	}
+	switch p := packet.(type) {
+	case *osc.Message:
+		q.metrics.MessageSent(p.Address)
+	case *osc.Bundle:
+		for _, msg := range p.Messages {
+			q.metrics.MessageSent(msg.Address)
+		}
+	}
+}
+
+// reportReply reports a reply to a message sent at sentAt
+func (q *Workspace) reportReply(address string, sentAt time.Time) {
+	if q.metrics != nil {
+		q.metrics.ReplyReceived(address, time.Since(sentAt))
+	}
+}
+
+// reportTimeout reports a message that went unanswered
+func (q *Workspace) reportTimeout(address string) {
+	if q.metrics != nil {
+		q.metrics.Timeout(address)
+	}
+}
+
+// reportRetry reports a message being sent again
+func (q *Workspace) reportRetry(address string) {
+	if q.metrics != nil {
+		q.metrics.Retry(address)
+	}
+}
+
+// AddressPrefix returns the part of an OSC address that names the kind of message, with the
+// workspace and cue IDs dropped, e.g. "/cue_id" for /workspace/W/cue_id/C/name. Latency is
+// grouped by it so per-cue addresses don't each get their own histogram.
+func AddressPrefix(address string) string {
+	parts := strings.Split(strings.TrimPrefix(address, "/"), "/")
+	if len(parts) >= 2 && parts[0] == "workspace" {
+		parts = parts[2:]
+	}
+	if len(parts) == 0 || parts[0] == "" {
+		return "/"
+	}
+	return "/" + parts[0]
+}
+
+// DefaultLatencyBuckets are the upper bounds of the reply latency histograms kept by a
+// MetricsCollector
+var DefaultLatencyBuckets = []time.Duration{
+	time.Millisecond, 5 * time.Millisecond, 10 * time.Millisecond, 25 * time.Millisecond,
+	50 * time.Millisecond, 100 * time.Millisecond, 250 * time.Millisecond, 500 * time.Millisecond,
+	time.Second, 2500 * time.Millisecond, 5 * time.Second, 10 * time.Second,
+}
+
+// LatencyHistogram counts reply latencies for one address prefix. Counts[i] is how many
+// replies took at most Buckets[i], cumulatively; Count includes replies over every bucket.
+type LatencyHistogram struct {
+	Buckets []time.Duration
+	Counts  []int64
+	Count   int64
+	Sum     time.Duration
+}
+
+// MetricsSnapshot is a copy of the counters and histograms of a MetricsCollector
+type MetricsSnapshot struct {
+	MessagesSent    int64
+	RepliesReceived int64
+	Timeouts        int64
+	Retries         int64
+	Latency         map[string]LatencyHistogram // Keyed by AddressPrefix
+}
+
+// MetricsCollector is a ready-made Metrics that keeps counters and latency histograms per
+// address prefix, and exposes them in the Prometheus text format. Package qlabexpvar
+// publishes them through expvar.
+type MetricsCollector struct {
+	mu       sync.Mutex
+	buckets  []time.Duration
+	snapshot MetricsSnapshot
+}
+
+// NewMetricsCollector creates a collector with DefaultLatencyBuckets
+func NewMetricsCollector() *MetricsCollector {
+	return &MetricsCollector{
+		buckets:  DefaultLatencyBuckets,
+		snapshot: MetricsSnapshot{Latency: make(map[string]LatencyHistogram)},
+	}
+}
+
+// MessageSent implements Metrics
+func (c *MetricsCollector) MessageSent(string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.snapshot.MessagesSent++
+}
+
+// ReplyReceived implements Metrics
+func (c *MetricsCollector) ReplyReceived(address string, latency time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.snapshot.RepliesReceived++
+
+	prefix := AddressPrefix(address)
+	histogram, ok := c.snapshot.Latency[prefix]
+	if !ok {
+		histogram = LatencyHistogram{Buckets: c.buckets, Counts: make([]int64, len(c.buckets))}
+	}
+	for i, bound := range c.buckets {
+		if latency <= bound {
+			histogram.Counts[i]++
+		}
+	}
+	histogram.Count++
+	histogram.Sum += latency
+	c.snapshot.Latency[prefix] = histogram
+}
+
+// Timeout implements Metrics
+func (c *MetricsCollector) Timeout(string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.snapshot.Timeouts++
+}
+
+// Retry implements Metrics
+func (c *MetricsCollector) Retry(string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.snapshot.Retries++
+}
+
+// Snapshot returns a copy of the collected metrics
+func (c *MetricsCollector) Snapshot() MetricsSnapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	snapshot := c.snapshot
+	snapshot.Latency = make(map[string]LatencyHistogram, len(c.snapshot.Latency))
+	for prefix, histogram := range c.snapshot.Latency {
+		histogram.Counts = slices.Clone(histogram.Counts)
+		snapshot.Latency[prefix] = histogram
+	}
+	return snapshot
+}
+
+// WritePrometheus writes the collected metrics in the Prometheus text exposition format
+func (c *MetricsCollector) WritePrometheus(w io.Writer) error {
+	snapshot := c.Snapshot()
+	var b strings.Builder
+	counters := []struct {
+		name, help string
+		value      int64
+	}{
+		{"qlab_osc_messages_sent_total", "OSC messages sent to QLab.", snapshot.MessagesSent},
+		{"qlab_osc_replies_received_total", "Replies received from QLab.", snapshot.RepliesReceived},
+		{"qlab_osc_timeouts_total", "OSC messages QLab did not answer in time.", snapshot.Timeouts},
+		{"qlab_osc_retries_total", "OSC messages sent again after a timeout.", snapshot.Retries},
+	}
+	for _, counter := range counters {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", counter.name, counter.help, counter.name, counter.name, counter.value)
+	}
+
+	const latency = "qlab_osc_reply_latency_seconds"
+	fmt.Fprintf(&b, "# HELP %s Round trip from sending an OSC message to QLab's reply.\n# TYPE %s histogram\n", latency, latency)
+	prefixes := make([]string, 0, len(snapshot.Latency))
+	for prefix := range snapshot.Latency {
+		prefixes = append(prefixes, prefix)
+	}
+	slices.Sort(prefixes)
+	for _, prefix := range prefixes {
+		histogram := snapshot.Latency[prefix]
+		for i, bound := range histogram.Buckets {
+			fmt.Fprintf(&b, "%s_bucket{prefix=%q,le=\"%g\"} %d\n", latency, prefix, bound.Seconds(), histogram.Counts[i])
+		}
+		fmt.Fprintf(&b, "%s_bucket{prefix=%q,le=\"+Inf\"} %d\n", latency, prefix, histogram.Count)
+		fmt.Fprintf(&b, "%s_sum{prefix=%q} %g\n", latency, prefix, histogram.Sum.Seconds())
+		fmt.Fprintf(&b, "%s_count{prefix=%q} %d\n", latency, prefix, histogram.Count)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ServeHTTP serves the collected metrics for a Prometheus scrape
+func (c *MetricsCollector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = c.WritePrometheus(w)
+}
@@ -0,0 +1,70 @@
+package qlab
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestAddressPrefix tests that workspace and cue IDs are dropped from metric prefixes
+func TestAddressPrefix(t *testing.T) {
+	tests := map[string]string{
+		"/workspace/W1/cue_id/C1/name": "/cue_id",
+		"/workspace/W1/cueLists":       "/cueLists",
+		"/thump":                       "/thump",
+		"/workspace/W1":                "/",
+		"":                             "/",
+	}
+	for address, want := range tests {
+		if got := AddressPrefix(address); got != want {
+			t.Errorf("AddressPrefix(%q) = %q, want %q", address, got, want)
+		}
+	}
+}
+
+// TestMetricsCollector tests that the OSC layer reports into a collector and that it is
+// exposed in the Prometheus text format
+func TestMetricsCollector(t *testing.T) {
+	workspace, _ := setupWorkspaceWithCleanup(t)
+	collector := NewMetricsCollector()
+	workspace.SetMetrics(collector)
+
+	reply := workspace.Send("/workspace/"+workspace.WorkspaceID()+"/cueLists", "")
+	if isTimeoutReply(reply) {
+		t.Fatal("Expected /cueLists answered")
+	}
+	collector.Timeout("/workspace/W1/cue_id/C1/name")
+	collector.Retry("/workspace/W1/cue_id/C1/name")
+
+	snapshot := collector.Snapshot()
+	if snapshot.MessagesSent != 1 || snapshot.RepliesReceived != 1 || snapshot.Timeouts != 1 || snapshot.Retries != 1 {
+		t.Errorf("Expected one of each, got %+v", snapshot)
+	}
+	histogram := snapshot.Latency["/cueLists"]
+	if histogram.Count != 1 || histogram.Counts[len(histogram.Counts)-1] != 1 {
+		t.Errorf("Expected one /cueLists latency, got %+v", histogram)
+	}
+
+	collector.ReplyReceived("/thump", 30*time.Second)
+	var b strings.Builder
+	if err := collector.WritePrometheus(&b); err != nil {
+		t.Fatalf("WritePrometheus failed: %v", err)
+	}
+	for _, line := range []string{
+		"qlab_osc_messages_sent_total 1",
+		"qlab_osc_replies_received_total 2",
+		`qlab_osc_reply_latency_seconds_bucket{prefix="/thump",le="10"} 0`,
+		`qlab_osc_reply_latency_seconds_bucket{prefix="/thump",le="+Inf"} 1`,
+		`qlab_osc_reply_latency_seconds_sum{prefix="/thump"} 30`,
+	} {
+		if !strings.Contains(b.String(), line+"\n") {
+			t.Errorf("Expected %q in:\n%s", line, b.String())
+		}
+	}
+
+	workspace.SetMetrics(nil)
+	workspace.Send("/workspace/"+workspace.WorkspaceID()+"/cueLists", "")
+	if collector.Snapshot().MessagesSent != 1 {
+		t.Error("Expected no metrics after SetMetrics(nil)")
+	}
+}
@@ -0,0 +1,103 @@
+package qlab
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestMockHierarchy tests that the mock server nests cues moved into groups and reports
+// them through /children, /cueLists, /cueLists/uniqueIDs and /parent
+func TestMockHierarchy(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+
+	create := func(cueType, number string) string {
+		t.Helper()
+		id, err := workspace.createCueWithoutTarget(map[string]any{"type": cueType, "number": number}, number)
+		if err != nil {
+			t.Fatalf("Failed to create cue %s: %v", number, err)
+		}
+		return id
+	}
+	group := create("group", "1")
+	first := create("memo", "1.1")
+	second := create("memo", "1.2")
+	after := create("memo", "2")
+
+	if err := workspace.moveCueToParentWithIndex(second, group, 0); err != nil {
+		t.Fatalf("Failed to move cue into group: %v", err)
+	}
+	if err := workspace.moveCueToParentWithIndex(first, group, 0); err != nil {
+		t.Fatalf("Failed to move cue into group: %v", err)
+	}
+	if err := workspace.moveCueToParentWithIndex(group, group, 0); err == nil {
+		t.Error("Expected moving a group into itself to fail")
+	}
+
+	childIDs := func(parentID string) []string {
+		t.Helper()
+		children, err := workspace.getCueChildren(parentID)
+		if err != nil {
+			t.Fatalf("Failed to query children of %s: %v", parentID, err)
+		}
+		var ids []string
+		for _, child := range children {
+			id, _ := child["uniqueID"].(string)
+			ids = append(ids, id)
+		}
+		return ids
+	}
+	if ids := childIDs(group); !slices.Equal(ids, []string{first, second}) {
+		t.Errorf("Expected group children [%s %s], got %v", first, second, ids)
+	}
+	if ids := childIDs("main-cue-list"); !slices.Equal(ids, []string{group, after}) {
+		t.Errorf("Expected main cue list children [%s %s], got %v", group, after, ids)
+	}
+
+	if parentID, index := workspace.queryCueLocation(second); parentID != group || index != 1 {
+		t.Errorf("Expected cue at index 1 of the group, got %s at %d", parentID, index)
+	}
+	if parentID, index := workspace.queryCueLocation(after); parentID != "main-cue-list" || index != 1 {
+		t.Errorf("Expected cue at index 1 of the main cue list, got %s at %d", parentID, index)
+	}
+
+	data, err := workspace.getCueLists()
+	if err != nil {
+		t.Fatalf("Failed to query cue lists: %v", err)
+	}
+	mainList, _ := data[0].(map[string]any)
+	cues, _ := mainList["cues"].([]any)
+	if len(cues) != 2 {
+		t.Fatalf("Expected 2 top-level cues, got %d", len(cues))
+	}
+	groupData, _ := cues[0].(map[string]any)
+	if nested, _ := groupData["cues"].([]any); len(nested) != 2 {
+		t.Errorf("Expected the group to hold 2 cues in /cueLists, got %v", groupData["cues"])
+	}
+
+	ids, err := workspace.getAllCueIDs()
+	if err != nil {
+		t.Fatalf("Failed to query unique IDs: %v", err)
+	}
+	if !slices.Equal(ids, []string{group, first, second, after}) {
+		t.Errorf("Expected every cue in order from /cueLists/uniqueIDs, got %v", ids)
+	}
+
+	// Deleting a group deletes the cues inside it
+	if err := workspace.deleteCue(group); err != nil {
+		t.Fatalf("Failed to delete group: %v", err)
+	}
+	if count := mockServer.GetCueCount(); count != 1 {
+		t.Errorf("Expected only the cue outside the group left, got %d cues", count)
+	}
+	nested := create("memo", "3")
+	if err := workspace.moveCueToParentWithIndex(nested, create("group", "4"), 0); err != nil {
+		t.Fatalf("Failed to move cue into group: %v", err)
+	}
+	if err := workspace.clearAllCues(); err != nil {
+		t.Fatalf("Failed to clear cues: %v", err)
+	}
+	if count := mockServer.GetCueCount(); count != 0 {
+		t.Errorf("Expected no cues left, got %d", count)
+	}
+}
@@ -0,0 +1,113 @@
+package qlab
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// MockFault makes MockOSCServer misbehave when replying to some messages, to exercise
+// retries, timeouts and disconnect handling
+type MockFault struct {
+	Delay       time.Duration // Extra time to wait before replying
+	DropReplies int           // Replies to drop before answering again; negative drops every reply
+	Error       string        // Replies with an error status and this message instead, when set
+}
+
+// SetFault makes the mock misbehave when replying to messages whose address ends with
+// address, such as "/cueLists" or "/name" for every cue's name; "" matches every message.
+// The longest matching address wins. A zero MockFault removes the fault.
+func (m *MockOSCServer) SetFault(address string, fault MockFault) {
+	m.faultMu.Lock()
+	defer m.faultMu.Unlock()
+	if fault == (MockFault{}) {
+		delete(m.faults, address)
+		return
+	}
+	if m.faults == nil {
+		m.faults = make(map[string]*MockFault)
+	}
+	m.faults[address] = &fault
+}
+
+// ClearFaults removes every fault set with SetFault and stops rejecting the passcode
+func (m *MockOSCServer) ClearFaults() {
+	m.faultMu.Lock()
+	defer m.faultMu.Unlock()
+	m.faults = nil
+	m.badPasscode = false
+	m.passcode = ""
+	m.passcodeChanged = false
+}
+
+// SetBadPasscode makes the mock reject every passcode, the way QLab does after the
+// workspace's passcode changes: connecting replies "badpass" and other workspace messages
+// are answered with a "denied" status
+func (m *MockOSCServer) SetBadPasscode(enabled bool) {
+	m.faultMu.Lock()
+	defer m.faultMu.Unlock()
+	m.badPasscode = enabled
+}
+
+// SetPasscode changes the workspace's passcode mid-session, as an operator can: connecting
+// with any other passcode replies "badpass", and workspace messages are answered with a
+// "denied" status until a connect with the new one. "" accepts any passcode again.
+func (m *MockOSCServer) SetPasscode(passcode string) {
+	m.faultMu.Lock()
+	defer m.faultMu.Unlock()
+	m.passcode = passcode
+	m.passcodeChanged = passcode != ""
+}
+
+// acceptsPasscode reports whether connecting with a passcode succeeds, which ends the
+// denial SetPasscode starts
+func (m *MockOSCServer) acceptsPasscode(passcode string) bool {
+	m.faultMu.Lock()
+	defer m.faultMu.Unlock()
+	if m.badPasscode || (m.passcode != "" && passcode != m.passcode) {
+		return false
+	}
+	m.passcodeChanged = false
+	return true
+}
+
+// applyFault returns the reply to send for a message after any fault set for its address,
+// and false when the reply is to be dropped. It waits out the fault's delay.
+func (m *MockOSCServer) applyFault(address string, data any) (any, bool) {
+	m.faultMu.Lock()
+	if (m.badPasscode || m.passcodeChanged) && strings.HasPrefix(address, "/workspace/") && !strings.HasSuffix(address, "/connect") {
+		m.faultMu.Unlock()
+		return map[string]any{"address": address, "status": "denied"}, true
+	}
+
+	var fault *MockFault
+	match := ""
+	for key, f := range m.faults {
+		if strings.HasSuffix(address, key) && (fault == nil || len(key) > len(match)) {
+			fault, match = f, key
+		}
+	}
+	if fault == nil {
+		m.faultMu.Unlock()
+		return data, true
+	}
+	drop := fault.DropReplies != 0
+	if fault.DropReplies > 0 {
+		fault.DropReplies--
+	}
+	delay, errorMsg := fault.Delay, fault.Error
+	m.faultMu.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	if drop {
+		log.Infof("Mock server dropping reply to %s", address)
+		return nil, false
+	}
+	if errorMsg != "" {
+		return map[string]any{"address": address, "status": "error", "error": errorMsg}, true
+	}
+	return data, true
+}
@@ -0,0 +1,107 @@
+package qlab
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestMockFaults tests that injected faults drive the retry, timeout, fallback and
+// disconnect paths
+func TestMockFaults(t *testing.T) {
+	t.Run("dropped reply is retried", func(t *testing.T) {
+		workspace, mockServer := setupWorkspaceWithCleanup(t)
+		workspace.SetTimeout(1)
+		workspace.SetMaxRetries(2)
+		counter := &retryCounter{}
+		workspace.SetMetrics(counter)
+		mockServer.SetFault("/version", MockFault{DropReplies: 1})
+
+		if version, err := workspace.queryVersion(); err != nil || version != "5.4.1" {
+			t.Fatalf("Expected the retry to get the version, got %q (%v)", version, err)
+		}
+		if retries := counter.retries.Load(); retries != 1 {
+			t.Errorf("Expected 1 retry, got %d", retries)
+		}
+	})
+
+	t.Run("slow reply times out", func(t *testing.T) {
+		workspace, mockServer := setupWorkspaceWithCleanup(t)
+		workspace.SetTimeout(1)
+		workspace.SetMaxRetries(0)
+		mockServer.SetFault("/version", MockFault{Delay: 1500 * time.Millisecond})
+
+		if _, err := workspace.Query("/version"); !errors.Is(err, ErrTimeout) {
+			t.Errorf("Expected a delayed reply to time out, got %v", err)
+		}
+	})
+
+	t.Run("forced error", func(t *testing.T) {
+		workspace, mockServer := setupWorkspaceWithCleanup(t)
+		workspace.SetTimeout(1)
+		mockServer.SetFault("", MockFault{Error: "busy"})
+		mockServer.SetFault("/version", MockFault{Error: "no version"})
+
+		_, err := workspace.Query("/version")
+		var qlabErr *QLabError
+		if !errors.As(err, &qlabErr) || qlabErr.Status != "error" {
+			t.Errorf("Expected an error status, got %v", err)
+		}
+
+		mockServer.ClearFaults()
+		if _, err := workspace.Query("/version"); err != nil {
+			t.Errorf("Expected no fault after ClearFaults, got %v", err)
+		}
+	})
+
+	t.Run("timed out cue lists fall back to the lightweight query", func(t *testing.T) {
+		workspace, mockServer := setupWorkspaceWithCleanup(t)
+		workspace.SetTimeout(1)
+		if _, err := workspace.queryVersion(); err != nil {
+			t.Fatalf("queryVersion failed: %v", err)
+		}
+		mockServer.SetFault("/cueLists", MockFault{Error: ErrTimeout.Error()})
+
+		path := filepath.Join(t.TempDir(), "show.json")
+		comparison, err := workspace.PerformThreeWayComparison(path, map[string]any{"cues": []any{}})
+		if err != nil {
+			t.Fatalf("PerformThreeWayComparison failed: %v", err)
+		}
+		if !comparison.HasQLabData {
+			t.Fatal("Expected the lightweight query to provide QLab data")
+		}
+		lists, _ := comparison.CurrentQLabData["data"].([]any)
+		if len(lists) != 1 {
+			t.Errorf("Expected the main cue list from /cueLists/shallow, got %v", comparison.CurrentQLabData)
+		}
+	})
+
+	t.Run("rejected passcode", func(t *testing.T) {
+		workspace, mockServer := setupWorkspaceWithCleanup(t)
+		workspace.SetTimeout(1)
+		var mu sync.Mutex
+		var events []DisconnectEvent
+		workspace.OnDisconnectEvent(func(event DisconnectEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, event)
+		})
+		if _, err := workspace.queryVersion(); err != nil {
+			t.Fatalf("queryVersion failed: %v", err)
+		}
+
+		mockServer.SetBadPasscode(true)
+		workspace.Send(workspace.GetAddress("/cueLists"), "")
+		mu.Lock()
+		if len(events) != 1 || events[0].Reason != DisconnectBadPasscode {
+			t.Errorf("Expected a bad passcode disconnect, got %+v", events)
+		}
+		mu.Unlock()
+
+		if _, err := workspace.Init(""); !errors.Is(err, ErrAuthFailed) {
+			t.Errorf("Expected connecting to fail authentication, got %v", err)
+		}
+	})
+}
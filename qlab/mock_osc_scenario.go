@@ -0,0 +1,203 @@
+package qlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/hypebeast/go-osc/osc"
+)
+
+// MockScenario describes a workspace for MockOSCServer to start with, and replies it gives
+// in place of its own. Scenarios are usually read from JSON files with LoadMockScenario.
+type MockScenario struct {
+	WorkspaceID string             `json:"workspaceID,omitempty"` // Replaces the mock's workspace ID
+	CueLists    []MockScenarioList `json:"cueLists,omitempty"`    // The first is the main cue list, "main-cue-list"
+	Responses   []MockResponse     `json:"responses,omitempty"`
+}
+
+// MockScenarioList is a cue list of a MockScenario. Each cue has a "type" and may have a
+// "uniqueID", "cues" inside it if it's a group, and any other property as QLab reports it,
+// such as "number", "name" or "fileTarget".
+type MockScenarioList struct {
+	Name string           `json:"name"`
+	Cues []map[string]any `json:"cues,omitempty"`
+}
+
+// MockResponse is a canned reply to messages whose address ends with Address, such as
+// "/cue_id/A1/duration"; the longest matching address wins
+type MockResponse struct {
+	Address string `json:"address"`
+	Status  string `json:"status,omitempty"` // "ok" unless given
+	Data    any    `json:"data,omitempty"`
+}
+
+// LoadMockScenario reads a scenario file
+func LoadMockScenario(path string) (*MockScenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario: %w", err)
+	}
+	scenario := &MockScenario{}
+	if err := json.Unmarshal(data, scenario); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return scenario, nil
+}
+
+// LoadScenario replaces the mock's cues and cue lists with a scenario's and adds its
+// responses. A scenario with a workspace ID must be loaded before Start.
+func (m *MockOSCServer) LoadScenario(scenario *MockScenario) error {
+	m.mu.Lock()
+	if scenario.WorkspaceID != "" && scenario.WorkspaceID != m.workspaceID {
+		if m.isRunning {
+			m.mu.Unlock()
+			return fmt.Errorf("scenario workspace ID must be loaded before the mock server starts")
+		}
+		m.workspaceID = scenario.WorkspaceID
+	}
+
+	m.cues = make(map[string]*MockCue)
+	m.cueLists = make(map[string]*MockCueList)
+	m.cuesByNumber = make(map[string]string)
+	m.listCues = make(map[string][]string)
+	m.listOrder = []string{"main-cue-list"}
+	m.currentList = ""
+	m.mainListName = ""
+	var err error
+	for i, list := range scenario.CueLists {
+		listID := ""
+		if i == 0 {
+			m.mainListName = list.Name
+		} else {
+			listID = fmt.Sprintf("MOCK-CUELIST-%d", m.nextCueListNumber)
+			m.nextCueListNumber++
+			m.cueLists[listID] = &MockCueList{UniqueID: listID, Name: list.Name, Type: "cue_list", Properties: make(map[string]string)}
+			m.listOrder = append(m.listOrder, listID)
+		}
+		if err = m.loadScenarioCues(list.Cues, listID, ""); err != nil {
+			break
+		}
+	}
+	m.responses = append(m.responses, scenario.Responses...)
+	running := m.isRunning
+	cueIDs := make([]string, 0, len(m.cues))
+	for cueID := range m.cues {
+		cueIDs = append(cueIDs, cueID)
+	}
+	listIDs := make([]string, 0, len(m.cueLists))
+	for listID := range m.cueLists {
+		listIDs = append(listIDs, listID)
+	}
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// A running server needs handlers for the new cues; Start adds them otherwise
+	if running {
+		for _, cueID := range cueIDs {
+			m.registerCueHandlers(cueID)
+		}
+		for _, listID := range listIDs {
+			m.registerCueListHandlers(listID)
+		}
+	}
+	log.Infof("Mock server loaded scenario with %d cue lists and %d cues", len(scenario.CueLists), len(cueIDs))
+	return nil
+}
+
+// loadScenarioCues adds scenario cues and the cues inside them to a cue list, inside a
+// group cue if parentID is set; m.mu must be held
+func (m *MockOSCServer) loadScenarioCues(cues []map[string]any, listID, parentID string) error {
+	for _, cueData := range cues {
+		cueType, _ := cueData["type"].(string)
+		if cueType == "" {
+			return fmt.Errorf("scenario cue %v has no type", cueData)
+		}
+		uniqueID, _ := cueData["uniqueID"].(string)
+		if uniqueID == "" {
+			uniqueID = fmt.Sprintf("MOCK-CUE-%d", m.nextCueNumber)
+			m.nextCueNumber++
+		}
+		if m.cues[uniqueID] != nil {
+			return fmt.Errorf("scenario has two cues with uniqueID %s", uniqueID)
+		}
+
+		cue := &MockCue{
+			UniqueID:   uniqueID,
+			Type:       cueType,
+			Properties: make(map[string]string),
+			Children:   make([]string, 0),
+			ListID:     listID,
+			ParentID:   parentID,
+		}
+		m.cues[uniqueID] = cue
+		if parentID != "" {
+			m.cues[parentID].Children = append(m.cues[parentID].Children, uniqueID)
+		} else {
+			m.listCues[listID] = append(m.listCues[listID], uniqueID)
+		}
+
+		for property, value := range cueData {
+			switch property {
+			case "type", "uniqueID", "cues", "listName":
+				continue
+			}
+			m.setCueValue(cue, property, fmt.Sprintf("%v", value))
+		}
+
+		if children, ok := cueData["cues"].([]any); ok {
+			nested := make([]map[string]any, 0, len(children))
+			for _, child := range children {
+				if childData, ok := child.(map[string]any); ok {
+					nested = append(nested, childData)
+				}
+			}
+			if err := m.loadScenarioCues(nested, listID, uniqueID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// AddResponse adds a canned reply, answered in place of the mock's own
+func (m *MockOSCServer) AddResponse(response MockResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.responses = append(m.responses, response)
+}
+
+// cannedReply answers a message with the canned reply for its address, reporting whether
+// there was one
+func (m *MockOSCServer) cannedReply(msg *osc.Message) bool {
+	m.mu.RLock()
+	var response *MockResponse
+	for i, candidate := range m.responses {
+		if strings.HasSuffix(msg.Address, candidate.Address) && (response == nil || len(candidate.Address) > len(response.Address)) {
+			response = &m.responses[i]
+		}
+	}
+	var replyData map[string]any
+	if response != nil {
+		status := response.Status
+		if status == "" {
+			status = "ok"
+		}
+		replyData = map[string]any{"address": msg.Address, "status": status}
+		if response.Data != nil {
+			replyData["data"] = response.Data
+		}
+	}
+	m.mu.RUnlock()
+
+	if replyData == nil {
+		return false
+	}
+	m.captureMessage(msg)
+	m.sendReply(msg.Address, replyData)
+	return true
+}
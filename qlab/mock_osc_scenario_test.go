@@ -0,0 +1,104 @@
+package qlab
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testScenario = `{
+  "workspaceID": "SCENARIO-WORKSPACE",
+  "cueLists": [
+    {
+      "name": "Main Cue List",
+      "cues": [
+        {"type": "memo", "uniqueID": "PRESHOW", "number": "1", "name": "Preshow"},
+        {"type": "group", "number": "2", "name": "Act One", "mode": 1, "cues": [
+          {"type": "audio", "number": "2.1", "name": "Overture", "fileTarget": "overture.wav"}
+        ]}
+      ]
+    },
+    {"name": "Sound Effects", "cues": [{"type": "audio", "number": "SFX1", "name": "Thunder"}]}
+  ],
+  "responses": [
+    {"address": "/cue_id/PRESHOW/notes", "data": "House opens at 7:30"}
+  ]
+}`
+
+// TestMockScenario tests that a mock started from a scenario file serves its workspace and
+// canned replies, and that syncing against it updates the cues it already has
+func TestMockScenario(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scenario.json")
+	if err := os.WriteFile(path, []byte(testScenario), 0o644); err != nil {
+		t.Fatalf("Failed to write scenario: %v", err)
+	}
+	scenario, err := LoadMockScenario(path)
+	if err != nil {
+		t.Fatalf("LoadMockScenario failed: %v", err)
+	}
+
+	port, err := getFreePort()
+	if err != nil {
+		t.Fatalf("Failed to get free port: %v", err)
+	}
+	mockServer := NewMockOSCServer("localhost", port)
+	if err := mockServer.LoadScenario(scenario); err != nil {
+		t.Fatalf("LoadScenario failed: %v", err)
+	}
+	if err := mockServer.Start(); err != nil {
+		t.Fatalf("Failed to start mock server: %v", err)
+	}
+	workspace := NewWorkspace("localhost", port)
+	workspace.SetTimeout(1)
+	t.Cleanup(func() {
+		workspace.Close()
+		if err := mockServer.Stop(); err != nil {
+			t.Logf("Failed to stop mock server: %v", err)
+		}
+	})
+
+	if err := mockServer.LoadScenario(&MockScenario{WorkspaceID: "OTHER"}); err == nil {
+		t.Error("Expected loading a workspace ID into a running mock to fail")
+	}
+	if _, err := workspace.Init(""); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	if workspace.WorkspaceID() != "SCENARIO-WORKSPACE" {
+		t.Errorf("Expected the scenario's workspace ID, got %s", workspace.WorkspaceID())
+	}
+
+	overture, err := workspace.GetCueByNumber("2.1")
+	if err != nil {
+		t.Fatalf("Expected the nested scenario cue, got %v", err)
+	}
+	if overture.Name != "Overture" {
+		t.Errorf("Expected cue 2.1 named Overture, got %q", overture.Name)
+	}
+	thunder, err := workspace.GetCueByNumber("SFX1")
+	if err != nil || thunder.ListName != "Sound Effects" {
+		t.Errorf("Expected SFX1 in Sound Effects, got %+v (%v)", thunder, err)
+	}
+	if values, err := workspace.GetCueValues(overture.UniqueID, []string{"fileTarget"}); err != nil || values["fileTarget"] != "overture.wav" {
+		t.Errorf("Expected the scenario's file target, got %v (%v)", values, err)
+	}
+
+	reply, err := workspace.Query("/cue_id/PRESHOW/notes")
+	var notes string
+	if err != nil || reply.Decode(&notes) != nil || notes != "House opens at 7:30" {
+		t.Errorf("Expected the canned notes, got %q (%v)", notes, err)
+	}
+
+	// Syncing a source that renames the preshow cue updates it instead of adding a cue
+	source := map[string]any{"cues": []any{
+		map[string]any{"type": "memo", "number": "1", "name": "Walk-in"},
+	}}
+	if _, err := workspace.TransmitWorkspaceData(filepath.Join(t.TempDir(), "show.json"), source, WithConflictResolver(AlwaysSource)); err != nil {
+		t.Fatalf("TransmitWorkspaceData failed: %v", err)
+	}
+	if cue := mockServer.GetCue("PRESHOW"); cue == nil || cue.Name != "Walk-in" {
+		t.Errorf("Expected the scenario cue renamed, got %+v", cue)
+	}
+	if count := mockServer.GetCueCount(); count != 4 {
+		t.Errorf("Expected no cues added, got %d cues", count)
+	}
+}
@@ -1,8 +1,13 @@
 package qlab
 
 import (
+	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -23,9 +28,13 @@ type ReceivedMessage struct {
 type safeDispatcher struct {
 	dispatcher osc.Dispatcher
 	mu         *sync.RWMutex
+	respond    func(*osc.Message) bool // Answers a message instead of the dispatcher, if it returns true
 }
 
 func (s *safeDispatcher) Dispatch(packet osc.Packet) {
+	if msg, ok := packet.(*osc.Message); ok && s.respond != nil && s.respond(msg) {
+		return
+	}
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	s.dispatcher.Dispatch(packet)
@@ -36,22 +45,53 @@ type MockOSCServer struct {
 	host              string
 	port              int
 	replyPort         int
-	server            *osc.Server
+	udpConn           net.PacketConn // Socket messages arrive on over UDP; replies to the source port leave from it
 	workspaceID       string
 	cues              map[string]*MockCue     // uniqueID -> cue
 	cueLists          map[string]*MockCueList // uniqueID -> cue list
 	cuesByNumber      map[string]string       // number -> uniqueID
 	nextCueNumber     int
 	nextCueListNumber int
+	currentList       string              // uniqueID of the cue list new cues go into, "" for the main cue list
+	listCues          map[string][]string // uniqueIDs of the cues at the top of each cue list in order, by ListID
+	listOrder         []string            // uniqueIDs of the cue lists in workspace order, "main-cue-list" for the main one
+	mainListName      string              // Name of the main cue list, "" for "Main Cue List"
+	replyToSource     bool                // Whether replies go to where messages came from until /udpReplyPort
+	lastSender        net.Addr            // Where the latest UDP message came from
+	replyMu           sync.Mutex          // Mutex to protect udpConn, replyPort, replyToSource and lastSender
+	displayName       string              // Name /workspaces lists the workspace under, "" for "Mock Workspace"
 	mu                sync.RWMutex
 	dispatcherMu      sync.RWMutex
 	isRunning         bool
 	alwaysReply       bool
-	dispatcher        *osc.StandardDispatcher // Keep reference for dynamic handler registration
-	serverReady       chan struct{}           // Signal that server is ready
-	receivedMessages  []ReceivedMessage       // Capture all received messages for testing
-	registeredCues    map[string]bool         // Track which cues have handlers registered
-	registeredLists   map[string]bool         // Track which lists have handlers registered
+	dispatcher        *osc.StandardDispatcher        // Keep reference for dynamic handler registration
+	serverReady       chan struct{}                  // Signal that server is ready
+	receivedMessages  []ReceivedMessage              // Capture all received messages for testing
+	registeredCues    map[string]bool                // Track which cues have handlers registered
+	registeredLists   map[string]bool                // Track which lists have handlers registered
+	runningCues       []string                       // uniqueIDs reported by /runningCues, in order
+	alwaysAudition    bool                           // Workspace always-audition mode
+	pausedCues        map[string]bool                // uniqueIDs of running cues that are paused
+	clockOffset       *time.Duration                 // When set, replies are bundled with a timetag this far from local time
+	clockMu           sync.Mutex                     // Mutex to protect clockOffset, read while replying
+	tcpListener       net.Listener                   // Accepts OSC over TCP on the same port, nil if it couldn't bind
+	tcpConns          []net.Conn                     // Connected TCP clients; replies go to these instead of UDP
+	tcpMu             sync.Mutex                     // Mutex to protect tcpConns and serialize writes to them
+	tcpMessages       int                            // Messages received over TCP
+	thumpSilent       bool                           // Whether heartbeats go unanswered
+	permissions       string                         // Permissions granted on connect, "" for view|edit|control
+	patches           map[PatchKind][]map[string]any // Patches and video stages listed by /settings, by kind
+	faults            map[string]*MockFault          // Faults set with SetFault, by address suffix
+	badPasscode       bool                           // Whether every passcode is rejected
+	passcode          string                         // Passcode set with SetPasscode, "" to accept any
+	passcodeChanged   bool                           // Whether workspace messages are denied until a connect with passcode
+	faultMu           sync.Mutex                     // Mutex to protect faults and the passcode fields, read while replying
+	responses         []MockResponse                 // Canned replies from a scenario, answered before any handler
+	version           string                         // Version reported by /version, "" for 5.4.1
+	playheads         map[string]string              // uniqueID of the cue at each cue list's playhead, by ListID
+	selection         []string                       // uniqueIDs of the selected cues, in selection order
+	startedAt         map[string]time.Time           // When each running cue started, for actionElapsed
+	loadedAt          map[string]time.Duration       // Cues loaded by load or loadAt, with the time loaded to
 }
 
 // MockCue represents a cue in the mock QLab workspace
@@ -66,6 +106,8 @@ type MockCue struct {
 	CueTargetNumber string            `json:"cueTargetNumber,omitempty"`
 	CueTargetID     string            `json:"cueTargetID,omitempty"`
 	Children        []string          `json:"-"` // uniqueIDs of child cues
+	ListID          string            `json:"-"` // uniqueID of the cue list holding the cue, "" for the main cue list
+	ParentID        string            `json:"-"` // uniqueID of the group cue holding the cue, "" at the top of its cue list
 	Properties      map[string]string `json:"-"` // additional properties
 }
 
@@ -82,11 +124,13 @@ func NewMockOSCServer(host string, port int) *MockOSCServer {
 	return &MockOSCServer{
 		host:              host,
 		port:              port,
-		replyPort:         port + 1, // Match the reply port calculation in main code
+		replyPort:         port + 1, // Where replies go until /udpReplyPort, as the workspace's default
 		workspaceID:       "MOCK-WORKSPACE-ID-1234",
 		cues:              make(map[string]*MockCue),
 		cueLists:          make(map[string]*MockCueList),
 		cuesByNumber:      make(map[string]string),
+		listCues:          make(map[string][]string),
+		listOrder:         []string{"main-cue-list"},
 		nextCueNumber:     1,
 		nextCueListNumber: 1,
 		alwaysReply:       false,
@@ -118,43 +162,71 @@ func (m *MockOSCServer) Start() error {
 	// Handle global working directory
 	_ = d.AddMsgHandler("/workingDirectory", m.handleGetWorkingDirectory)
 
+	// Handle application version
+	_ = d.AddMsgHandler("/version", m.handleVersion)
+
+	// Handle workspaces listing
+	_ = d.AddMsgHandler("/workspaces", m.handleWorkspaces)
+
+	// Handle heartbeats
+	_ = d.AddMsgHandler("/thump", m.handleThump)
+
 	// Handle workspace messages with specific workspace ID
 	workspacePrefix := fmt.Sprintf("/workspace/%s", m.workspaceID)
 	_ = d.AddMsgHandler(workspacePrefix+"/new", m.handleNewCue)
 	// Individual cue handlers will be registered dynamically when cues are created
 	_ = d.AddMsgHandler(workspacePrefix+"/cueLists", m.handleGetCueLists)
-	// Note: /cueLists/uniqueIDs is intentionally not registered as it conflicts with /cueLists matching
+	// /cueLists/uniqueIDs is answered by the default handler: a handler for it would also
+	// catch /cueLists, since the dispatcher matches the incoming address as a pattern
 	_ = d.AddMsgHandler(workspacePrefix+"/basePath", m.handleGetWorkspaceBasePath)
-	_ = d.AddMsgHandler(workspacePrefix+"/cue/*/children", m.handleGetChildrenByNumber)
+	_ = d.AddMsgHandler(workspacePrefix+"/runningCues/shallow", m.handleGetRunningCues)
+	_ = d.AddMsgHandler(workspacePrefix+"/alwaysAudition", m.handleAlwaysAudition)
 	_ = d.AddMsgHandler(workspacePrefix+"/cue/selected/children", m.handleGetSelectedChildren)
-	_ = d.AddMsgHandler(workspacePrefix+"/cue_id/*/children", m.handleGetChildrenByID)
-	_ = d.AddMsgHandler("/cue/*/children", m.handleGetChildrenByNumber)
+	// Wildcard handler addresses are rejected, so children are answered per cue and cue
+	// list, and by number in the default handler; the main cue list needs its own
+	_ = d.AddMsgHandler(workspacePrefix+"/cue_id/main-cue-list/children", m.handleGetChildrenByID)
+	_ = d.AddMsgHandler(workspacePrefix+"/move/main-cue-list", m.handleMoveCue)
 	_ = d.AddMsgHandler("/cue/selected/children", m.handleGetSelectedChildren)
-	_ = d.AddMsgHandler("/cue_id/*/children", m.handleGetChildrenByID)
 
-	// Dynamic handlers registered per cue - no catchall needed
+	// Playback commands are handled by the default handler, which sees every message.
+	// Registering /cue_id/{id}/stop per cue would also catch .../stopTargetWhenDone,
+	// since the dispatcher matches the incoming address as an unanchored pattern.
+	_ = d.AddMsgHandler("*", m.handlePlayback)
+
+	// Cues and cue lists loaded from a scenario before starting get theirs now
+	for cueID := range m.cues {
+		m.registeredCues[cueID] = true
+		m.addCueHandlers(cueID)
+	}
+	for cueListID := range m.cueLists {
+		m.registeredLists[cueListID] = true
+		m.addCueListHandlers(cueListID)
+	}
 
 	// Wrap dispatcher to be thread-safe
 	wrappedDispatcher := &safeDispatcher{
 		dispatcher: d,
 		mu:         &m.dispatcherMu,
+		respond:    m.cannedReply,
 	}
 
 	// Start main server
-	m.server = &osc.Server{
-		Addr:       fmt.Sprintf("%s:%d", m.host, m.port),
-		Dispatcher: wrappedDispatcher,
+	conn, err := net.ListenPacket("udp", fmt.Sprintf("%s:%d", m.host, m.port))
+	if err != nil {
+		return fmt.Errorf("mock server failed to listen: %w", err)
 	}
+	m.replyMu.Lock()
+	m.udpConn = conn
+	m.replyMu.Unlock()
+
+	// Accept OSC over TCP on the same port, as QLab does
+	m.startTCPListener(wrappedDispatcher)
 
 	// Start main server only - no need for separate reply server
 	// The mock server will send replies directly to the workspace's reply server
 	m.serverReady = make(chan struct{})
 	ready := m.serverReady
-	go func() {
-		if err := m.server.ListenAndServe(); err != nil {
-			log.Errorf("Mock OSC server error: %v", err)
-		}
-	}()
+	go m.serveUDP(conn, wrappedDispatcher)
 
 	// Give servers time to start
 	time.Sleep(100 * time.Millisecond)
@@ -174,24 +246,30 @@ func (m *MockOSCServer) Stop() error {
 		return nil
 	}
 
-	// Close the server if it exists
-	// The go-osc library has some race conditions, but we need to close
-	// the connection to free up the port for subsequent tests.
-	// We use a small delay to let the server finish initializing first.
-	if m.server != nil {
-		server := m.server
-		m.server = nil
-
+	// Close the socket if it exists, freeing the port for subsequent tests.
+	// We use a small delay to let replies being sent finish first.
+	m.replyMu.Lock()
+	conn := m.udpConn
+	m.udpConn = nil
+	m.replyMu.Unlock()
+	if conn != nil {
 		// Close in background to avoid blocking
 		go func() {
 			time.Sleep(100 * time.Millisecond)
 			log.Debugf("Closing mock OSC server")
-			if err := server.CloseConnection(); err != nil {
+			if err := conn.Close(); err != nil {
 				log.Warnf("Failed to close mock server: %v", err)
 			}
 		}()
 	}
 
+	// Stop accepting TCP clients and disconnect the current ones
+	if m.tcpListener != nil {
+		_ = m.tcpListener.Close()
+		m.tcpListener = nil
+	}
+	m.DropTCPConnections()
+
 	// Clear ready channel
 	m.serverReady = nil
 
@@ -207,6 +285,11 @@ func (m *MockOSCServer) GetWorkspaceID() string {
 
 // sendReply sends a reply message to the workspace reply server
 func (m *MockOSCServer) sendReply(address string, data any) {
+	data, ok := m.applyFault(address, data)
+	if !ok {
+		return
+	}
+
 	// Build reply address by prepending /reply to the original address
 	replyAddress := "/reply" + address
 
@@ -245,14 +328,22 @@ func (m *MockOSCServer) sendReply(address string, data any) {
 		log.Debugf("Mock server sending converted reply: %s", strValue)
 	}
 
-	// Send reply to the workspace reply port
-	client := osc.NewClient(m.host, m.replyPort)
-
 	// Small delay to simulate QLab processing time and allow reply server to start
-	time.Sleep(150 * time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	var packet osc.Packet = msg
+	if offset, ok := m.replyClockOffset(); ok {
+		bundle := osc.NewBundle(time.Now().Add(offset))
+		_ = bundle.Append(msg)
+		packet = bundle
+	}
+	if m.sendTCP(packet) {
+		log.Infof("Mock server sent reply over TCP with address %s", replyAddress)
+		return
+	}
 
-	log.Infof("Mock server sending reply to %s:%d with address %s", m.host, m.replyPort, replyAddress)
-	if err := client.Send(msg); err != nil {
+	log.Infof("Mock server sending reply with address %s", replyAddress)
+	if err := m.sendUDP(packet); err != nil {
 		log.Errorf("Failed to send mock reply: %v", err)
 	} else {
 		log.Infof("Mock server successfully sent reply")
@@ -262,7 +353,11 @@ func (m *MockOSCServer) sendReply(address string, data any) {
 // handleConnect handles connection requests
 func (m *MockOSCServer) handleConnect(msg *osc.Message) {
 	log.Debug("Mock server received connect request")
+	m.replyConnect(msg, m.workspaceID)
+}
 
+// replyConnect answers a connect request for the given workspace
+func (m *MockOSCServer) replyConnect(msg *osc.Message, workspaceID string) {
 	// Check passcode (simulate authentication)
 	var passcode string
 	if len(msg.Arguments) > 0 {
@@ -271,15 +366,24 @@ func (m *MockOSCServer) handleConnect(msg *osc.Message) {
 		}
 	}
 
+	// Only the mock's own workspace is open
+	if workspaceID != m.workspaceID {
+		m.sendReply(msg.Address, map[string]any{
+			"address": msg.Address,
+			"status":  "error",
+		})
+		return
+	}
+
 	// Simulate authentication failure for "test" passcode (like a real QLab with wrong passcode)
-	if passcode == "test" {
+	if passcode == "test" || !m.acceptsPasscode(passcode) {
 		replyData := map[string]any{
 			"address":      fmt.Sprintf("/workspace/%s/connect", m.workspaceID),
 			"status":       "ok",
 			"data":         "badpass",
 			"workspace_id": m.workspaceID,
 		}
-		m.sendReply("/connect", replyData)
+		m.sendReply(msg.Address, replyData)
 		return
 	}
 
@@ -290,8 +394,13 @@ func (m *MockOSCServer) handleConnect(msg *osc.Message) {
 		"data":         "ok:view|edit|control",
 		"workspace_id": m.workspaceID,
 	}
+	m.mu.RLock()
+	if m.permissions != "" {
+		replyData["data"] = "ok:" + m.permissions
+	}
+	m.mu.RUnlock()
 
-	m.sendReply("/connect", replyData)
+	m.sendReply(msg.Address, replyData)
 }
 
 // handleAlwaysReply handles alwaysReply setting
@@ -310,6 +419,408 @@ func (m *MockOSCServer) handleAlwaysReply(msg *osc.Message) {
 	m.sendReply("/alwaysReply", replyData)
 }
 
+// handleGetRunningCues returns the cues marked as running with SetCueRunning
+func (m *MockOSCServer) handleGetRunningCues(msg *osc.Message) {
+	m.mu.RLock()
+	data := make([]any, 0, len(m.runningCues))
+	for _, id := range m.runningCues {
+		if cue, ok := m.cues[id]; ok {
+			data = append(data, map[string]any{
+				"uniqueID": cue.UniqueID,
+				"number":   cue.Number,
+				"name":     cue.Name,
+				"type":     cue.Type,
+			})
+		}
+	}
+	m.mu.RUnlock()
+
+	m.sendReply(msg.Address, map[string]any{"status": "ok", "data": data})
+}
+
+// handleAlwaysAudition queries or sets the workspace always-audition mode
+func (m *MockOSCServer) handleAlwaysAudition(msg *osc.Message) {
+	m.captureMessage(msg)
+
+	m.mu.Lock()
+	if len(msg.Arguments) > 0 {
+		value := fmt.Sprintf("%v", msg.Arguments[0])
+		m.alwaysAudition = value == "1" || value == "true"
+	}
+	enabled := m.alwaysAudition
+	m.mu.Unlock()
+
+	m.sendReply(msg.Address, map[string]any{"status": "ok", "data": enabled})
+}
+
+// SetCueRunning marks a cue as running or stopped for /runningCues queries
+func (m *MockOSCServer) SetCueRunning(uniqueID string, running bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.setRunningLocked(uniqueID, running)
+}
+
+// setRunningLocked marks a cue as running or stopped; m.mu must be held
+func (m *MockOSCServer) setRunningLocked(uniqueID string, running bool) {
+	for i, id := range m.runningCues {
+		if id == uniqueID {
+			m.runningCues = append(m.runningCues[:i], m.runningCues[i+1:]...)
+			break
+		}
+	}
+	if running {
+		m.runningCues = append(m.runningCues, uniqueID)
+		if m.startedAt == nil {
+			m.startedAt = make(map[string]time.Time)
+		}
+		m.startedAt[uniqueID] = time.Now()
+	} else {
+		delete(m.startedAt, uniqueID)
+	}
+}
+
+// mockWorkspacePlayback and mockCuePlayback are the playback commands the mock understands
+var (
+	mockWorkspacePlayback = map[string]bool{"go": true, "stop": true, "pause": true, "resume": true, "panic": true, "hardStop": true, "reset": true}
+	mockCuePlayback       = map[string]bool{"start": true, "stop": true, "pause": true, "resume": true, "load": true, "preview": true}
+)
+
+// handlePlayback handles workspace and cue playback commands, ignoring every other message
+func (m *MockOSCServer) handlePlayback(msg *osc.Message) {
+	// Connecting to a specific workspace is handled here for the same reason: a handler
+	// for /workspace/{id}/connect would also catch /connect
+	if rest, ok := strings.CutPrefix(msg.Address, "/workspace/"); ok {
+		if workspaceID, ok := strings.CutSuffix(rest, "/connect"); ok && !strings.Contains(workspaceID, "/") {
+			log.Debug("Mock server received workspace connect request")
+			m.replyConnect(msg, workspaceID)
+			return
+		}
+	}
+
+	// A handler for /open would also catch /workspace/{id}/cue_id/{id}/open and the like
+	if msg.Address == "/open" {
+		m.captureMessage(msg)
+		m.handleOpen(msg)
+		return
+	}
+
+	workspacePrefix := fmt.Sprintf("/workspace/%s", m.workspaceID)
+	rest, ok := strings.CutPrefix(msg.Address, workspacePrefix+"/")
+	if !ok {
+		return
+	}
+	parts := strings.Split(rest, "/")
+
+	switch {
+	case len(parts) == 1 && mockWorkspacePlayback[parts[0]]:
+		m.captureMessage(msg)
+		if err := m.workspacePlayback(parts[0], msg.Arguments); err != nil {
+			m.sendErrorReply(msg.Address, err.Error())
+			return
+		}
+	case len(parts) == 1 && parts[0] == "save":
+		m.captureMessage(msg)
+	case len(parts) == 1 && parts[0] == "disconnect":
+		// Captured for tests; QLab doesn't answer it
+		m.captureMessage(msg)
+		return
+	case len(parts) >= 3 && parts[0] == "settings":
+		m.captureMessage(msg)
+		m.handleSettings(msg, parts[1:])
+		return
+	case len(parts) == 2 && parts[0] == "cueLists" && parts[1] == "uniqueIDs":
+		m.handleGetCueListIDs(msg)
+		return
+	case len(parts) == 2 && parts[0] == "cueLists" && parts[1] == "shallow":
+		m.handleGetShallowCueLists(msg)
+		return
+	case len(parts) == 3 && parts[0] == "cue" && parts[1] != "selected" && parts[2] == "children":
+		m.handleGetChildrenByNumber(msg)
+		return
+	case len(parts) == 3 && parts[0] == "cue_id" && (parts[2] == "playheadId" || parts[2] == "playhead"):
+		m.captureMessage(msg)
+		m.handlePlayhead(msg, parts[1], parts[2])
+		return
+	case len(parts) == 2 && parts[0] == "selectedCues" && parts[1] == "shallow":
+		m.handleGetSelectedCues(msg)
+		return
+	case len(parts) == 2 && parts[0] == "select_id":
+		m.captureMessage(msg)
+		if err := m.selectCue(parts[1]); err != nil {
+			m.sendErrorReply(msg.Address, err.Error())
+			return
+		}
+	case len(parts) == 3 && parts[0] == "cue_id" && mockCuePlayback[parts[2]]:
+		m.captureMessage(msg)
+		if err := m.cuePlayback(parts[1], parts[2]); err != nil {
+			m.sendErrorReply(msg.Address, err.Error())
+			return
+		}
+	case len(parts) == 3 && parts[0] == "cue" && (parts[2] == "load" || parts[2] == "loadAt"):
+		m.captureMessage(msg)
+		if err := m.loadCueNumber(parts[1], msg.Arguments); err != nil {
+			m.sendErrorReply(msg.Address, err.Error())
+			return
+		}
+	default:
+		return
+	}
+
+	m.sendReply(msg.Address, map[string]any{"status": "ok"})
+}
+
+// handlePlayhead reads or moves the playhead of a cue list, by uniqueID for playheadId and
+// by number for playhead
+func (m *MockOSCServer) handlePlayhead(msg *osc.Message, cueListID, property string) {
+	m.mu.Lock()
+	listID := cueListID
+	if cueListID == "main-cue-list" {
+		listID = ""
+	} else if m.cueLists[cueListID] == nil {
+		m.mu.Unlock()
+		m.sendErrorReply(msg.Address, fmt.Sprintf("cue list %s not found", cueListID))
+		return
+	}
+
+	if len(msg.Arguments) == 0 {
+		var data any = m.playheadLocked(listID)
+		if property == "playhead" {
+			data = ""
+			if cue := m.cues[m.playheadLocked(listID)]; cue != nil {
+				data = cue.Number
+			}
+		}
+		m.mu.Unlock()
+		m.sendReply(msg.Address, map[string]any{"status": "ok", "data": data})
+		return
+	}
+
+	target := fmt.Sprintf("%v", msg.Arguments[0])
+	if property == "playhead" {
+		target = m.cuesByNumber[target]
+	}
+	cue := m.cues[target]
+	if cue == nil || cue.ListID != listID {
+		m.mu.Unlock()
+		m.sendErrorReply(msg.Address, fmt.Sprintf("cue %v not found in cue list %s", msg.Arguments[0], cueListID))
+		return
+	}
+	m.setPlayheadLocked(listID, target)
+	m.mu.Unlock()
+	m.sendReply(msg.Address, map[string]any{"status": "ok"})
+}
+
+// playheadLocked returns the uniqueID of the cue at a cue list's playhead: the first cue
+// until it's moved, "" once GO has run past the last cue; m.mu must be held
+func (m *MockOSCServer) playheadLocked(listID string) string {
+	if uniqueID, ok := m.playheads[listID]; ok && (uniqueID == "" || m.cues[uniqueID] != nil) {
+		return uniqueID
+	}
+	if ids := m.listCues[listID]; len(ids) > 0 {
+		return ids[0]
+	}
+	return ""
+}
+
+// setPlayheadLocked moves a cue list's playhead; m.mu must be held
+func (m *MockOSCServer) setPlayheadLocked(listID, uniqueID string) {
+	if m.playheads == nil {
+		m.playheads = make(map[string]string)
+	}
+	m.playheads[listID] = uniqueID
+}
+
+// nextCueLocked returns the cue after a cue in its group or cue list, "" if it's the last;
+// m.mu must be held
+func (m *MockOSCServer) nextCueLocked(uniqueID string) string {
+	cue := m.cues[uniqueID]
+	siblings := m.listCues[cue.ListID]
+	if parent := m.cues[cue.ParentID]; parent != nil {
+		siblings = parent.Children
+	}
+	if i := slices.Index(siblings, uniqueID); i >= 0 && i+1 < len(siblings) {
+		return siblings[i+1]
+	}
+	return ""
+}
+
+// loadCueNumber loads the cue with a number, to the time in seconds given as an argument
+// for loadAt
+func (m *MockOSCServer) loadCueNumber(number string, args []any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	uniqueID, ok := m.cuesByNumber[number]
+	if !ok {
+		return fmt.Errorf("cue %s not found", number)
+	}
+	var offset time.Duration
+	if len(args) > 0 {
+		seconds, ok := toFloat(args[0])
+		if !ok || seconds < 0 {
+			return fmt.Errorf("invalid load time %v", args[0])
+		}
+		offset = time.Duration(seconds * float64(time.Second))
+	}
+	m.setLoadedLocked(uniqueID, offset)
+	return nil
+}
+
+// setLoadedLocked marks a cue loaded to a time; m.mu must be held
+func (m *MockOSCServer) setLoadedLocked(uniqueID string, offset time.Duration) {
+	if m.loadedAt == nil {
+		m.loadedAt = make(map[string]time.Duration)
+	}
+	m.loadedAt[uniqueID] = offset
+}
+
+// LoadedAt reports whether a cue was loaded, and the time it was loaded to
+func (m *MockOSCServer) LoadedAt(uniqueID string) (time.Duration, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	offset, ok := m.loadedAt[uniqueID]
+	return offset, ok
+}
+
+// SetPlayhead moves the playhead of a cue list, "" for the main cue list
+func (m *MockOSCServer) SetPlayhead(cueListID, uniqueID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.setPlayheadLocked(cueListID, uniqueID)
+}
+
+// selectCue selects a cue or cue list, making its cue list the one new cues go into
+func (m *MockOSCServer) selectCue(uniqueID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch {
+	case uniqueID == "main-cue-list":
+		m.currentList = ""
+	case m.cueLists[uniqueID] != nil:
+		m.currentList = uniqueID
+	case m.cues[uniqueID] != nil:
+		m.currentList = m.cues[uniqueID].ListID
+	default:
+		return fmt.Errorf("cue not found: %s", uniqueID)
+	}
+	m.selection = []string{uniqueID}
+	return nil
+}
+
+// SetSelection selects several cues at once, as an operator can in QLab
+func (m *MockOSCServer) SetSelection(uniqueIDs ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.selection = slices.Clone(uniqueIDs)
+}
+
+// GetSelection returns the uniqueIDs of the selected cues, in selection order
+func (m *MockOSCServer) GetSelection() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return slices.Clone(m.selection)
+}
+
+// handleGetSelectedCues replies to /selectedCues/shallow with the selected cues that exist
+func (m *MockOSCServer) handleGetSelectedCues(msg *osc.Message) {
+	m.mu.RLock()
+	data := []any{}
+	for _, uniqueID := range m.selection {
+		if cue := m.cues[uniqueID]; cue != nil {
+			data = append(data, map[string]any{"uniqueID": cue.UniqueID, "number": cue.Number, "name": cue.Name, "type": cue.Type})
+		}
+	}
+	m.mu.RUnlock()
+	m.sendReply(msg.Address, map[string]any{"status": "ok", "data": data})
+}
+
+// workspacePlayback applies a workspace-level playback command
+func (m *MockOSCServer) workspacePlayback(command string, args []any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch command {
+	case "go":
+		uniqueID := m.playheadLocked(m.currentList)
+		if len(args) > 0 {
+			number := fmt.Sprintf("%v", args[0])
+			var ok bool
+			if uniqueID, ok = m.cuesByNumber[number]; !ok {
+				return fmt.Errorf("cue %s not found", number)
+			}
+		}
+		if uniqueID == "" {
+			return nil // The playhead is past the last cue
+		}
+		m.setRunningLocked(uniqueID, true)
+		m.setPlayheadLocked(m.cues[uniqueID].ListID, m.nextCueLocked(uniqueID))
+	case "stop", "panic", "hardStop", "reset":
+		m.runningCues = nil
+		m.pausedCues = nil
+		m.loadedAt = nil
+	case "pause":
+		for _, id := range m.runningCues {
+			m.setPausedLocked(id, true)
+		}
+	case "resume":
+		m.pausedCues = nil
+	}
+	return nil
+}
+
+// cuePlayback applies a cue-level playback command
+func (m *MockOSCServer) cuePlayback(uniqueID, command string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.cues[uniqueID]; !ok {
+		return fmt.Errorf("cue %s not found", uniqueID)
+	}
+
+	switch command {
+	case "load":
+		m.setLoadedLocked(uniqueID, 0)
+	case "start", "preview":
+		m.setRunningLocked(uniqueID, true)
+		m.setPausedLocked(uniqueID, false)
+	case "stop":
+		m.setRunningLocked(uniqueID, false)
+		m.setPausedLocked(uniqueID, false)
+	case "pause":
+		m.setPausedLocked(uniqueID, true)
+	case "resume":
+		m.setPausedLocked(uniqueID, false)
+	}
+	return nil
+}
+
+// IsCueRunning reports whether a cue is running
+func (m *MockOSCServer) IsCueRunning(uniqueID string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return slices.Contains(m.runningCues, uniqueID)
+}
+
+// IsCuePaused reports whether a running cue is paused
+func (m *MockOSCServer) IsCuePaused(uniqueID string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.pausedCues[uniqueID]
+}
+
+// setPausedLocked marks a cue as paused or unpaused; m.mu must be held
+func (m *MockOSCServer) setPausedLocked(uniqueID string, paused bool) {
+	if !paused {
+		delete(m.pausedCues, uniqueID)
+		return
+	}
+	if m.pausedCues == nil {
+		m.pausedCues = make(map[string]bool)
+	}
+	m.pausedCues[uniqueID] = true
+}
+
 // handleNewCue handles cue and cue list creation
 func (m *MockOSCServer) handleNewCue(msg *osc.Message) {
 	log.Debug("Mock server received new request:", msg.String())
@@ -341,8 +852,9 @@ func (m *MockOSCServer) handleNewCue(msg *osc.Message) {
 			Properties: make(map[string]string),
 		}
 
-		// Store the cue list
+		// Store the cue list, last in the workspace as QLab puts new ones
 		m.cueLists[uniqueID] = cueList
+		m.listOrder = append(m.listOrder, uniqueID)
 
 		log.Infof("Mock server created cue list: %s (type: %s)", uniqueID, cueList.Type)
 
@@ -364,8 +876,12 @@ func (m *MockOSCServer) handleNewCue(msg *osc.Message) {
 		return
 	}
 
+	// A parent's uniqueID may follow the type, as in "memo {parentID}"
+	cueType, _, _ = strings.Cut(cueType, " ")
+
 	// Generate unique ID for regular cue
-	uniqueID := fmt.Sprintf("MOCK-CUE-%d", len(m.cues)+1)
+	uniqueID := fmt.Sprintf("MOCK-CUE-%d", m.nextCueNumber)
+	m.nextCueNumber++
 
 	// Create new cue
 	cue := &MockCue{
@@ -373,9 +889,11 @@ func (m *MockOSCServer) handleNewCue(msg *osc.Message) {
 		Type:       cueType,
 		Properties: make(map[string]string),
 		Children:   make([]string, 0),
+		ListID:     m.currentList,
 	}
 
 	m.cues[uniqueID] = cue
+	m.listCues[m.currentList] = append(m.listCues[m.currentList], uniqueID)
 
 	log.Infof("Mock server created cue: %s (type: %s)", uniqueID, cueType)
 
@@ -421,6 +939,9 @@ func (m *MockOSCServer) handleSetCueProperty(msg *osc.Message) {
 		m.sendErrorReply(msg.Address, "invalid property address")
 		return
 	}
+	if mockCuePlayback[property] {
+		return // Playback command matched a longer property address; handlePlayback replies
+	}
 
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -431,47 +952,32 @@ func (m *MockOSCServer) handleSetCueProperty(msg *osc.Message) {
 		return
 	}
 
-	// If no arguments, this is a query - return the property value
-	if len(msg.Arguments) == 0 {
-		var data any
-		switch property {
-		case "name":
-			data = cue.Name
-		case "number":
-			data = cue.Number
-		case "fileTarget":
-			if cue.FileTarget != "" {
-				basePath := "/Users/test/Desktop/QLab Workspace"
-				if strings.HasPrefix(cue.FileTarget, basePath+"/") {
-					data = strings.TrimPrefix(cue.FileTarget, basePath+"/")
-				} else {
-					data = cue.FileTarget
-				}
-			} else {
-				data = cue.FileTarget
-			}
-		case "file":
-			data = cue.FileTarget
-		case "infiniteLoop":
-			if cue.InfiniteLoop {
-				data = "1"
-			} else {
-				data = "0"
-			}
-		case "mode":
-			data = fmt.Sprintf("%d", cue.Mode)
-		case "cueTarget", "cueTargetID":
-			data = cue.CueTargetID
-		case "cueTargetNumber":
-			data = cue.CueTargetNumber
-		default:
-			if val, ok := cue.Properties[property]; ok {
-				data = val
-			} else {
-				data = ""
-			}
+	// Levels and gangs are addressed by matrix position: /level {row} {column} queries,
+	// /level {row} {column} {dB} and /gang {row} {column} {name} set
+	if (property == "level" || property == "gang") && len(msg.Arguments) >= 2 {
+		key := fmt.Sprintf("%s/%v/%v", property, msg.Arguments[0], msg.Arguments[1])
+		replyData := map[string]any{"status": "ok"}
+		if len(msg.Arguments) >= 3 {
+			cue.Properties[key] = fmt.Sprintf("%v", msg.Arguments[2])
+		} else if val, ok := cue.Properties[key]; ok {
+			replyData["data"] = val
+		} else {
+			replyData["data"] = ""
 		}
+		m.sendReply(msg.Address, replyData)
+		return
+	}
+
+	// Cart cells are set with two arguments, /cartPosition {row} {column}
+	if property == "cartPosition" && len(msg.Arguments) >= 2 {
+		cue.Properties[property] = fmt.Sprintf("%v %v", msg.Arguments[0], msg.Arguments[1])
+		m.sendReply(msg.Address, map[string]any{"status": "ok"})
+		return
+	}
 
+	// If no arguments, this is a query - return the property value
+	if len(msg.Arguments) == 0 {
+		data := m.cueValue(cue, property)
 		log.Debugf("Mock server query %s.%s = %v", cueID, property, data)
 		replyData := map[string]any{
 			"status": "ok",
@@ -483,7 +989,19 @@ func (m *MockOSCServer) handleSetCueProperty(msg *osc.Message) {
 
 	// Set property based on value
 	value := fmt.Sprintf("%v", msg.Arguments[0])
+	m.setCueValue(cue, property, value)
+
+	log.Debugf("Mock server set %s.%s = %s", cueID, property, value)
+
+	// Send reply in the format expected by the workspace
+	replyData := map[string]any{
+		"status": "ok",
+	}
+	m.sendReply(msg.Address, replyData)
+}
 
+// setCueValue sets a property of a cue as a message setting it does; m.mu must be held
+func (m *MockOSCServer) setCueValue(cue *MockCue, property, value string) {
 	switch property {
 	case "name":
 		cue.Name = value
@@ -495,7 +1013,7 @@ func (m *MockOSCServer) handleSetCueProperty(msg *osc.Message) {
 		cue.Number = value
 		// Only add to mapping if the new value is not empty
 		if value != "" {
-			m.cuesByNumber[value] = cueID
+			m.cuesByNumber[value] = cue.UniqueID
 		}
 	case "fileTarget", "file":
 		cue.FileTarget = value
@@ -514,57 +1032,231 @@ func (m *MockOSCServer) handleSetCueProperty(msg *osc.Message) {
 	default:
 		cue.Properties[property] = value
 	}
+}
 
-	log.Debugf("Mock server set %s.%s = %s", cueID, property, value)
-
-	// Send reply in the format expected by the workspace
-	replyData := map[string]any{
-		"status": "ok",
+// cueValue returns a property of a cue as a query for it reports it; m.mu must be held
+func (m *MockOSCServer) cueValue(cue *MockCue, property string) any {
+	switch property {
+	case "name":
+		return cue.Name
+	case "type":
+		return cue.Type
+	case "number":
+		return cue.Number
+	case "fileTarget":
+		basePath := "/Users/test/Desktop/QLab Workspace"
+		return strings.TrimPrefix(cue.FileTarget, basePath+"/")
+	case "file":
+		return cue.FileTarget
+	case "infiniteLoop":
+		if cue.InfiniteLoop {
+			return "1"
+		}
+		return "0"
+	case "mode":
+		return fmt.Sprintf("%d", cue.Mode)
+	case "cueTarget", "cueTargetID":
+		return cue.CueTargetID
+	case "cueTargetNumber":
+		return cue.CueTargetNumber
+	case "parent":
+		return m.parentID(cue)
+	case "actionElapsed":
+		if startedAt, ok := m.startedAt[cue.UniqueID]; ok && slices.Contains(m.runningCues, cue.UniqueID) {
+			return time.Since(startedAt).Seconds()
+		}
+		return 0.0
+	default:
+		// Unset properties read as empty
+		return cue.Properties[property]
 	}
-	m.sendReply(msg.Address, replyData)
 }
 
-// handleMoveCue handles moving cues
-func (m *MockOSCServer) handleMoveCue(msg *osc.Message) {
-	log.Debug("Mock server received move cue request:", msg.String())
+// handleValuesForKeys replies with several properties of a cue, read by valuesForKeys
+func (m *MockOSCServer) handleValuesForKeys(msg *osc.Message) {
+	log.Debug("Mock server received valuesForKeys request:", msg.String())
 
-	// Extract cue ID from address
 	addressParts := strings.Split(msg.Address, "/")
 	var cueID string
-
 	for i, part := range addressParts {
-		if part == "move" && i+1 < len(addressParts) {
+		if part == "cue_id" && i+1 < len(addressParts) {
 			cueID = addressParts[i+1]
 			break
 		}
 	}
 
-	if cueID == "" {
-		m.sendErrorReply(msg.Address, "invalid move address")
+	var keys []string
+	if len(msg.Arguments) > 0 {
+		if encoded, ok := msg.Arguments[0].(string); ok {
+			_ = json.Unmarshal([]byte(encoded), &keys)
+		}
+	}
+	if len(keys) == 0 {
+		m.sendErrorReply(msg.Address, "valuesForKeys expects a JSON array of keys")
 		return
 	}
 
-	// Check arguments - should be index and parent cue ID
-	if len(msg.Arguments) != 2 {
-		log.Debugf("Mock server received %d arguments for move, expected 2", len(msg.Arguments))
-		m.sendErrorReply(msg.Address, fmt.Sprintf("expected 2 arguments for move, got %d", len(msg.Arguments)))
+	m.mu.Lock()
+	cue, exists := m.cues[cueID]
+	values := make(map[string]any, len(keys))
+	if exists {
+		for _, key := range keys {
+			values[key] = m.cueValue(cue, key)
+		}
+	}
+	m.mu.Unlock()
+
+	if !exists {
+		m.sendErrorReply(msg.Address, fmt.Sprintf("cue %s not found", cueID))
+		return
+	}
+	m.sendReply(msg.Address, map[string]any{"status": "ok", "data": values})
+}
+
+// handleMoveCue handles moving cues
+func (m *MockOSCServer) handleMoveCue(msg *osc.Message) {
+	log.Debug("Mock server received move cue request:", msg.String())
+	m.captureMessage(msg)
+
+	// Extract cue ID from address
+	addressParts := strings.Split(msg.Address, "/")
+	var cueID string
+
+	for i, part := range addressParts {
+		if part == "move" && i+1 < len(addressParts) {
+			cueID = addressParts[i+1]
+			break
+		}
+	}
+
+	if cueID == "" {
+		m.sendErrorReply(msg.Address, "invalid move address")
+		return
+	}
+
+	// Check arguments - should be index and parent cue ID, the parent omitted to move the
+	// cue within its current one
+	if len(msg.Arguments) != 1 && len(msg.Arguments) != 2 {
+		log.Debugf("Mock server received %d arguments for move, expected 1 or 2", len(msg.Arguments))
+		m.sendErrorReply(msg.Address, fmt.Sprintf("expected 1 or 2 arguments for move, got %d", len(msg.Arguments)))
 		return
 	}
 
 	index, indexOk := msg.Arguments[0].(int32)
-	parentID, parentOk := msg.Arguments[1].(string)
+	parentID, parentOk := "", true
+	if len(msg.Arguments) == 2 {
+		parentID, parentOk = msg.Arguments[1].(string)
+	}
 
 	if !indexOk || !parentOk {
-		log.Debugf("Mock server received invalid argument types for move: %T, %T", msg.Arguments[0], msg.Arguments[1])
+		log.Debugf("Mock server received invalid argument types for move: %v", msg.Arguments)
 		m.sendErrorReply(msg.Address, "invalid argument types for move")
 		return
 	}
 
-	log.Debugf("Mock server acknowledging move of cue %s to index %d under parent %s", cueID, index, parentID)
+	m.mu.Lock()
+	var err error
+	switch {
+	case slices.Contains(m.listOrder, cueID):
+		// Cue lists move among the cue lists of the workspace
+		m.moveCueList(cueID, int(index))
+	case parentID == "" && m.cues[cueID] != nil:
+		err = m.moveCue(cueID, m.parentID(m.cues[cueID]), int(index))
+	default:
+		err = m.moveCue(cueID, parentID, int(index))
+	}
+	m.mu.Unlock()
+	if err != nil {
+		m.sendErrorReply(msg.Address, err.Error())
+		return
+	}
+
+	log.Debugf("Mock server moved cue %s to index %d under parent %s", cueID, index, parentID)
 	replyData := map[string]any{"status": "ok"}
 	m.sendReply(msg.Address, replyData)
 }
 
+// moveCue moves a cue to an index among the children of a group cue or cue list, as
+// /move does; m.mu must be held
+func (m *MockOSCServer) moveCue(cueID, parentID string, index int) error {
+	cue, exists := m.cues[cueID]
+	if !exists {
+		return fmt.Errorf("cue %s not found", cueID)
+	}
+
+	var listID, groupID string
+	switch {
+	case parentID == "main-cue-list":
+	case m.cueLists[parentID] != nil:
+		listID = parentID
+	case m.cues[parentID] != nil:
+		for ancestor := parentID; ancestor != ""; ancestor = m.cues[ancestor].ParentID {
+			if ancestor == cueID {
+				return fmt.Errorf("cannot move cue %s into itself", cueID)
+			}
+		}
+		listID, groupID = m.cues[parentID].ListID, parentID
+	default:
+		return fmt.Errorf("parent %s not found", parentID)
+	}
+
+	m.detachCue(cue)
+	siblings := m.listCues[listID]
+	if groupID != "" {
+		siblings = m.cues[groupID].Children
+	}
+	index = max(0, min(index, len(siblings)))
+	siblings = slices.Insert(siblings, index, cueID)
+	if groupID != "" {
+		m.cues[groupID].Children = siblings
+	} else {
+		m.listCues[listID] = siblings
+	}
+	cue.ParentID = groupID
+	m.setCueList(cue, listID)
+	return nil
+}
+
+// moveCueList moves a cue list to an index among the cue lists of the workspace; m.mu
+// must be held
+func (m *MockOSCServer) moveCueList(listID string, index int) {
+	m.listOrder = slices.DeleteFunc(m.listOrder, func(id string) bool { return id == listID })
+	index = max(0, min(index, len(m.listOrder)))
+	m.listOrder = slices.Insert(m.listOrder, index, listID)
+}
+
+// detachCue removes a cue from the children of its parent; m.mu must be held
+func (m *MockOSCServer) detachCue(cue *MockCue) {
+	isCue := func(id string) bool { return id == cue.UniqueID }
+	if parent := m.cues[cue.ParentID]; cue.ParentID != "" && parent != nil {
+		parent.Children = slices.DeleteFunc(parent.Children, isCue)
+		return
+	}
+	m.listCues[cue.ListID] = slices.DeleteFunc(m.listCues[cue.ListID], isCue)
+}
+
+// setCueList puts a cue and its children in a cue list; m.mu must be held
+func (m *MockOSCServer) setCueList(cue *MockCue, listID string) {
+	cue.ListID = listID
+	for _, childID := range cue.Children {
+		if child := m.cues[childID]; child != nil {
+			m.setCueList(child, listID)
+		}
+	}
+}
+
+// parentID returns the uniqueID of the group cue or cue list holding a cue; m.mu must be held
+func (m *MockOSCServer) parentID(cue *MockCue) string {
+	switch {
+	case cue.ParentID != "":
+		return cue.ParentID
+	case cue.ListID != "":
+		return cue.ListID
+	default:
+		return "main-cue-list"
+	}
+}
+
 // handleDeleteCue handles deleting cues
 func (m *MockOSCServer) handleDeleteCue(msg *osc.Message) {
 	log.Debug("Mock server received delete cue request:", msg.String())
@@ -588,76 +1280,153 @@ func (m *MockOSCServer) handleDeleteCue(msg *osc.Message) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	// Deleting a cue list deletes the cues in it
+	if _, isList := m.cueLists[cueID]; isList {
+		for _, cue := range m.cues {
+			if cue.ListID == cueID && cue.ParentID == "" {
+				m.deleteCueTree(cue)
+			}
+		}
+		delete(m.cueLists, cueID)
+		m.listOrder = slices.DeleteFunc(m.listOrder, func(id string) bool { return id == cueID })
+		log.Debugf("Mock server deleted cue list %s", cueID)
+		m.sendReply(msg.Address, map[string]any{"status": "ok"})
+		return
+	}
+
 	cue, exists := m.cues[cueID]
 	if !exists {
 		m.sendErrorReply(msg.Address, fmt.Sprintf("cue %s not found", cueID))
 		return
 	}
 
-	// Remove from number mapping
-	if cue.Number != "" {
-		delete(m.cuesByNumber, cue.Number)
-	}
-
-	// Remove cue
-	delete(m.cues, cueID)
+	// Deleting a group cue deletes the cues inside it
+	m.detachCue(cue)
+	m.deleteCueTree(cue)
 
 	log.Debugf("Mock server deleted cue %s", cueID)
 	replyData := map[string]any{"status": "ok"}
 	m.sendReply(msg.Address, replyData)
 }
 
+// deleteCueTree removes a cue and the cues inside it; m.mu must be held
+func (m *MockOSCServer) deleteCueTree(cue *MockCue) {
+	for _, childID := range cue.Children {
+		if child := m.cues[childID]; child != nil {
+			m.deleteCueTree(child)
+		}
+	}
+	if cue.Number != "" && m.cuesByNumber[cue.Number] == cue.UniqueID {
+		delete(m.cuesByNumber, cue.Number)
+	}
+	delete(m.cues, cue.UniqueID)
+}
+
 // handleGetChildrenByNumber handles getting children by cue number
 func (m *MockOSCServer) handleGetChildrenByNumber(msg *osc.Message) {
 	log.Debug("Mock server received get children by number request:", msg.String())
 
-	// For mock, return empty children list
-	children := make([]any, 0)
-	m.sendReply(msg.Address, children)
+	var number string
+	parts := strings.Split(msg.Address, "/")
+	for i, part := range parts {
+		if part == "cue" && i+1 < len(parts) {
+			number = parts[i+1]
+			break
+		}
+	}
+
+	m.mu.RLock()
+	uniqueID, exists := m.cuesByNumber[number]
+	var children []any
+	if exists {
+		children = m.childrenData(uniqueID)
+	}
+	m.mu.RUnlock()
+
+	if !exists {
+		m.sendErrorReply(msg.Address, fmt.Sprintf("cue %s not found", number))
+		return
+	}
+	m.sendReply(msg.Address, map[string]any{"status": "ok", "data": children})
 }
 
 // handleGetSelectedChildren handles getting selected cue children
 func (m *MockOSCServer) handleGetSelectedChildren(msg *osc.Message) {
 	log.Debug("Mock server received get selected children request:", msg.String())
 
-	// For mock, return empty children list
-	children := make([]any, 0)
-	m.sendReply(msg.Address, children)
+	// For mock, return empty children list in QLab's reply format
+	m.sendReply(msg.Address, map[string]any{"status": "ok", "data": make([]any, 0)})
 }
 
-// handleGetChildrenByID handles getting children by cue ID
+// handleGetChildrenByID handles getting the children of a group cue or cue list by ID
 func (m *MockOSCServer) handleGetChildrenByID(msg *osc.Message) {
 	log.Debug("Mock server received get children by ID request:", msg.String())
 
-	// For mock, return empty children list
-	children := make([]any, 0)
-	m.sendReply(msg.Address, children)
-}
+	var cueID string
+	parts := strings.Split(msg.Address, "/")
+	for i, part := range parts {
+		if part == "cue_id" && i+1 < len(parts) {
+			cueID = parts[i+1]
+			break
+		}
+	}
 
-// handleGetCueLists handles getting full cue lists structure
-func (m *MockOSCServer) handleGetCueLists(msg *osc.Message) {
-	log.Debug("Mock server received cueLists request")
+	m.mu.RLock()
+	exists := cueID == "main-cue-list" || m.cueLists[cueID] != nil || m.cues[cueID] != nil
+	var children []any
+	if exists {
+		children = m.childrenData(cueID)
+	}
+	m.mu.RUnlock()
 
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	if !exists {
+		m.sendErrorReply(msg.Address, fmt.Sprintf("cue %s not found", cueID))
+		return
+	}
+	m.sendReply(msg.Address, map[string]any{"status": "ok", "data": children})
+}
 
-	// Create response containing all cue lists
-	var cueLists []any
+// childrenData returns the children of a group cue or cue list as /children reports them;
+// m.mu must be held
+func (m *MockOSCServer) childrenData(parentID string) []any {
+	ids := m.listCues[parentID]
+	listName := m.listName("")
+	switch {
+	case parentID == "main-cue-list":
+		ids = m.listCues[""]
+	case m.cueLists[parentID] != nil:
+		listName = m.cueLists[parentID].Name
+	case m.cues[parentID] != nil:
+		ids = m.cues[parentID].Children
+		listName = m.listName(m.cues[parentID].ListID)
+	}
+	return m.cuesData(ids, listName, nil)
+}
 
-	// Add the default main cue list with all cues
-	mainCueList := map[string]any{
-		"uniqueID": "main-cue-list",
-		"name":     "Main Cue List",
-		"type":     "cue_list",
-		"cues":     make([]any, 0),
+// listName returns the name of the cue list with a ListID; m.mu must be held
+func (m *MockOSCServer) listName(listID string) string {
+	if cueList := m.cueLists[listID]; cueList != nil {
+		return cueList.Name
+	}
+	if m.mainListName != "" {
+		return m.mainListName
 	}
+	return "Main Cue List"
+}
 
-	// Add all cues to the main cue list
-	var cues []any
-	for _, cue := range m.cues {
+// cuesData returns cues and the cues inside them as /cueLists reports them, with only the
+// given keys when keys isn't nil; m.mu must be held
+func (m *MockOSCServer) cuesData(ids []string, listName string, keys []string) []any {
+	cues := make([]any, 0, len(ids))
+	for _, id := range ids {
+		cue := m.cues[id]
+		if cue == nil {
+			continue
+		}
 		cueData := map[string]any{
 			"uniqueID": cue.UniqueID,
 			"type":     cue.Type,
+			"listName": listName,
 		}
 
 		// Add properties if they exist
@@ -676,25 +1445,88 @@ func (m *MockOSCServer) handleGetCueLists(msg *osc.Message) {
 			cueData[key] = value
 		}
 
+		// Group cues hold their children, in order
+		if len(cue.Children) > 0 || strings.EqualFold(cue.Type, "group") {
+			cueData["cues"] = m.cuesData(cue.Children, listName, keys)
+		}
+
+		if keys != nil {
+			for key := range cueData {
+				if key != "cues" && !slices.Contains(keys, key) {
+					delete(cueData, key)
+				}
+			}
+		}
 		cues = append(cues, cueData)
 	}
+	return cues
+}
 
-	mainCueList["cues"] = cues
-	cueLists = append(cueLists, mainCueList)
+// cueListsData returns every cue list and the cues in it as /cueLists reports them, with
+// only the given keys when keys isn't nil; m.mu must be held
+func (m *MockOSCServer) cueListsData(keys []string) []any {
+	cueList := func(uniqueID, name, listType, listID string) map[string]any {
+		data := map[string]any{
+			"uniqueID": uniqueID,
+			"name":     name,
+			"type":     listType,
+			"cues":     m.cuesData(m.listCues[listID], name, keys),
+		}
+		if keys != nil {
+			delete(data, "name")
+			delete(data, "type")
+		}
+		return data
+	}
 
-	// Add any additional cue lists that were created
-	for _, cueList := range m.cueLists {
-		cueListData := map[string]any{
-			"uniqueID": cueList.UniqueID,
-			"name":     cueList.Name,
-			"type":     cueList.Type,
-			"cues":     make([]any, 0), // Empty for now - could be populated later if needed
+	// Cue lists come in workspace order, the main one first until it's moved
+	cueLists := make([]any, 0, len(m.listOrder))
+	for _, listID := range m.listOrder {
+		if listID == "main-cue-list" {
+			cueLists = append(cueLists, cueList(listID, m.listName(""), "cue_list", ""))
+		} else if extra := m.cueLists[listID]; extra != nil {
+			cueLists = append(cueLists, cueList(extra.UniqueID, extra.Name, extra.Type, extra.UniqueID))
 		}
-		cueLists = append(cueLists, cueListData)
 	}
+	return cueLists
+}
 
-	// Return as array of cue lists (QLab can have multiple cue lists)
+// handleGetShallowCueLists replies to /cueLists/shallow with the cue lists alone
+func (m *MockOSCServer) handleGetShallowCueLists(msg *osc.Message) {
+	log.Debug("Mock server received cueLists/shallow request")
+
+	m.mu.RLock()
+	data := m.cueListsData(nil)
+	m.mu.RUnlock()
+	for _, cueList := range data {
+		delete(cueList.(map[string]any), "cues")
+	}
+
+	m.sendReply(msg.Address, map[string]any{"status": "ok", "data": data})
+}
+
+// handleGetCueListIDs replies to /cueLists/uniqueIDs with the cue lists and the cues in
+// them, nested as /cueLists nests them, reporting only their unique IDs
+func (m *MockOSCServer) handleGetCueListIDs(msg *osc.Message) {
+	log.Debug("Mock server received cueLists/uniqueIDs request")
+
+	m.mu.RLock()
+	data := m.cueListsData([]string{"uniqueID"})
+	m.mu.RUnlock()
+
+	m.sendReply(msg.Address, map[string]any{"status": "ok", "data": data})
+}
+
+// handleGetCueLists handles getting full cue lists structure. Each cue list holds the cues
+// created while it was current and the cues moved into it, group cues holding theirs.
+func (m *MockOSCServer) handleGetCueLists(msg *osc.Message) {
+	log.Debug("Mock server received cueLists request")
+
+	m.mu.RLock()
+	cueLists := m.cueListsData(nil)
+	m.mu.RUnlock()
 
+	// Return as array of cue lists (QLab can have multiple cue lists)
 	replyData := map[string]any{
 		"status": "ok",
 		"data":   cueLists,
@@ -729,6 +1561,344 @@ func (m *MockOSCServer) handleGetWorkingDirectory(msg *osc.Message) {
 	m.sendReply(msg.Address, replyData)
 }
 
+// SendUpdate sends an update message to the workspace's listener the way QLab pushes
+// status changes (e.g. /update/workspace/{id}/disconnect when a workspace closes)
+func (m *MockOSCServer) SendUpdate(address string, args ...any) error {
+	msg := osc.NewMessage(address, args...)
+	if m.sendTCP(msg) {
+		return nil
+	}
+	return m.sendUDP(msg)
+}
+
+// serveUDP dispatches the packets received over UDP until the socket is closed. A
+// /udpReplyPort message is applied before the next packet is read, as the replies to the
+// messages after it go to the new port.
+func (m *MockOSCServer) serveUDP(conn net.PacketConn, dispatcher osc.Dispatcher) {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Errorf("Mock OSC server error: %v", err)
+			}
+			return
+		}
+		packet, err := osc.ParsePacket(string(buf[:n]))
+		if err != nil {
+			log.Warnf("Mock server ignoring malformed UDP packet: %v", err)
+			continue
+		}
+		m.replyMu.Lock()
+		m.lastSender = addr
+		m.replyMu.Unlock()
+		if msg, ok := packet.(*osc.Message); ok && msg.Address == "/udpReplyPort" {
+			m.captureMessage(msg)
+			m.handleUDPReplyPort(msg)
+			continue
+		}
+		go dispatcher.Dispatch(packet)
+	}
+}
+
+// handleUDPReplyPort sends the replies and updates that follow to the given port. Like
+// QLab, it doesn't answer.
+func (m *MockOSCServer) handleUDPReplyPort(msg *osc.Message) {
+	if len(msg.Arguments) == 0 {
+		return
+	}
+	port, ok := msg.Arguments[0].(int32)
+	if !ok {
+		log.Warnf("Mock server ignoring /udpReplyPort %v", msg.Arguments[0])
+		return
+	}
+	m.replyMu.Lock()
+	m.replyPort = int(port)
+	m.replyToSource = false
+	m.replyMu.Unlock()
+}
+
+// SetReplyToSource sets whether replies and updates go to the address the latest message
+// came from, as QLab's do before /udpReplyPort, instead of the reply port (by default the
+// mock's port plus one)
+func (m *MockOSCServer) SetReplyToSource(toSource bool) {
+	m.replyMu.Lock()
+	defer m.replyMu.Unlock()
+	m.replyToSource = toSource
+}
+
+// ReplyPort returns the port replies go to, as last set with /udpReplyPort
+func (m *MockOSCServer) ReplyPort() int {
+	m.replyMu.Lock()
+	defer m.replyMu.Unlock()
+	return m.replyPort
+}
+
+// sendUDP sends a packet to the reply port, or from the mock's socket to where the latest
+// message came from with SetReplyToSource
+func (m *MockOSCServer) sendUDP(packet osc.Packet) error {
+	m.replyMu.Lock()
+	port, toSource, sender, conn := m.replyPort, m.replyToSource, m.lastSender, m.udpConn
+	m.replyMu.Unlock()
+	if toSource && sender != nil && conn != nil {
+		data, err := packet.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		_, err = conn.WriteTo(data, sender)
+		return err
+	}
+	return osc.NewClient(m.host, port).Send(packet)
+}
+
+// startTCPListener accepts SLIP-framed OSC over TCP on the mock's port
+func (m *MockOSCServer) startTCPListener(dispatcher osc.Dispatcher) {
+	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", m.host, m.port))
+	if err != nil {
+		log.Warnf("Mock server not accepting TCP: %v", err)
+		return
+	}
+	m.tcpListener = listener
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			m.tcpMu.Lock()
+			m.tcpConns = append(m.tcpConns, conn)
+			m.tcpMu.Unlock()
+			go m.serveTCP(conn, dispatcher)
+		}
+	}()
+}
+
+// serveTCP dispatches the packets received from a TCP client until it disconnects
+func (m *MockOSCServer) serveTCP(conn net.Conn, dispatcher osc.Dispatcher) {
+	defer func() {
+		m.tcpMu.Lock()
+		m.tcpConns = slices.DeleteFunc(m.tcpConns, func(c net.Conn) bool { return c == conn })
+		m.tcpMu.Unlock()
+		_ = conn.Close()
+	}()
+
+	reader := bufio.NewReader(conn)
+	for {
+		frame, err := readSLIPFrame(reader)
+		if err != nil {
+			return
+		}
+		packet, err := osc.ParsePacket(string(frame))
+		if err != nil {
+			log.Warnf("Mock server ignoring malformed TCP packet: %v", err)
+			continue
+		}
+		m.recordTCPPacket(packet)
+		dispatcher.Dispatch(packet)
+	}
+}
+
+// recordTCPPacket marks that a message arrived over TCP, for TCPMessageCount
+func (m *MockOSCServer) recordTCPPacket(packet osc.Packet) {
+	if _, ok := packet.(*osc.Message); ok {
+		m.tcpMu.Lock()
+		m.tcpMessages++
+		m.tcpMu.Unlock()
+	}
+}
+
+// TCPMessageCount returns how many messages have been received over TCP
+func (m *MockOSCServer) TCPMessageCount() int {
+	m.tcpMu.Lock()
+	defer m.tcpMu.Unlock()
+	return m.tcpMessages
+}
+
+// sendTCP writes a packet to every connected TCP client, reporting whether there were any
+func (m *MockOSCServer) sendTCP(packet osc.Packet) bool {
+	m.tcpMu.Lock()
+	defer m.tcpMu.Unlock()
+	if len(m.tcpConns) == 0 {
+		return false
+	}
+	data, err := packet.MarshalBinary()
+	if err != nil {
+		log.Errorf("Failed to encode mock TCP reply: %v", err)
+		return true
+	}
+	for _, conn := range m.tcpConns {
+		if _, err := conn.Write(slipEncode(data)); err != nil {
+			log.Warnf("Failed to send mock TCP reply: %v", err)
+		}
+	}
+	return true
+}
+
+// DropTCPConnections closes every TCP client connection, as when QLab quits
+func (m *MockOSCServer) DropTCPConnections() {
+	m.tcpMu.Lock()
+	defer m.tcpMu.Unlock()
+	for _, conn := range m.tcpConns {
+		_ = conn.Close()
+	}
+	m.tcpConns = nil
+}
+
+// handleVersion handles application version requests
+func (m *MockOSCServer) handleVersion(msg *osc.Message) {
+	m.mu.RLock()
+	version := m.version
+	m.mu.RUnlock()
+	if version == "" {
+		version = "5.4.1"
+	}
+	m.sendReply(msg.Address, map[string]any{
+		"status": "ok",
+		"data":   version,
+	})
+}
+
+// handleWorkspaces lists the mock's one workspace
+func (m *MockOSCServer) handleWorkspaces(msg *osc.Message) {
+	m.mu.RLock()
+	displayName := m.displayName
+	m.mu.RUnlock()
+	if displayName == "" {
+		displayName = "Mock Workspace"
+	}
+	m.sendReply(msg.Address, map[string]any{
+		"status": "ok",
+		"data": []any{map[string]any{
+			"displayName": displayName, "uniqueID": m.workspaceID, "hasPasscode": false, "version": "5.4.1",
+		}},
+	})
+}
+
+// handleOpen opens a workspace file: the mock's workspace takes the file's name. Paths
+// that aren't QLab workspace files are refused.
+func (m *MockOSCServer) handleOpen(msg *osc.Message) {
+	var path string
+	if len(msg.Arguments) > 0 {
+		path, _ = msg.Arguments[0].(string)
+	}
+	ext := filepath.Ext(path)
+	if !strings.HasPrefix(ext, ".qlab") {
+		m.sendErrorReply(msg.Address, fmt.Sprintf("can't open %q", path))
+		return
+	}
+	m.mu.Lock()
+	m.displayName = strings.TrimSuffix(filepath.Base(path), ext)
+	m.mu.Unlock()
+	m.sendReply(msg.Address, map[string]any{"status": "ok"})
+}
+
+// SetVersion sets the QLab version the mock reports, e.g. "4.6.10"; "" restores 5.4.1
+func (m *MockOSCServer) SetVersion(version string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.version = version
+}
+
+// handleThump answers a heartbeat, unless SetThumpEnabled turned heartbeats off
+func (m *MockOSCServer) handleThump(msg *osc.Message) {
+	m.mu.RLock()
+	silent := m.thumpSilent
+	m.mu.RUnlock()
+	if silent {
+		return
+	}
+	m.sendReply(msg.Address, map[string]any{
+		"status": "ok",
+		"data":   "thump",
+	})
+}
+
+// mockSettingsKinds maps the /settings sections to patch kinds
+var mockSettingsKinds = map[string]PatchKind{"audio": PatchAudio, "network": PatchNetwork, "video": PatchStage}
+
+// handleSettings lists patches and video stages, and renames them:
+// /settings/{audio,network}/patchList, /settings/video/stages, and
+// /settings/{audio,network}/patch/{id}/name or /settings/video/stage/{id}/name
+func (m *MockOSCServer) handleSettings(msg *osc.Message, parts []string) {
+	kind, ok := mockSettingsKinds[parts[0]]
+	if !ok {
+		m.sendErrorReply(msg.Address, fmt.Sprintf("unknown settings %s", parts[0]))
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch {
+	case len(parts) == 2 && (parts[1] == "patchList" || parts[1] == "stages"):
+		patches := m.patches[kind]
+		if patches == nil {
+			patches = []map[string]any{}
+		}
+		m.sendReply(msg.Address, map[string]any{"status": "ok", "data": patches})
+	case len(parts) == 4 && (parts[1] == "patch" || parts[1] == "stage") && parts[3] == "name" && len(msg.Arguments) == 1:
+		for _, patch := range m.patches[kind] {
+			if patch["uniqueID"] == parts[2] {
+				patch["name"] = fmt.Sprintf("%v", msg.Arguments[0])
+				m.sendReply(msg.Address, map[string]any{"status": "ok"})
+				return
+			}
+		}
+		m.sendErrorReply(msg.Address, fmt.Sprintf("%s patch %s not found", kind, parts[2]))
+	default:
+		m.sendErrorReply(msg.Address, "unsupported settings method")
+	}
+}
+
+// SetPatches sets the patches or video stages of a kind, named in order; their unique IDs
+// are MOCK-{KIND}-1, MOCK-{KIND}-2, and so on
+func (m *MockOSCServer) SetPatches(kind PatchKind, names ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.patches == nil {
+		m.patches = make(map[PatchKind][]map[string]any)
+	}
+	patches := make([]map[string]any, len(names))
+	for i, name := range names {
+		patches[i] = map[string]any{"uniqueID": fmt.Sprintf("MOCK-%s-%d", strings.ToUpper(string(kind)), i+1), "name": name}
+	}
+	m.patches[kind] = patches
+}
+
+// SetPermissions sets the permissions reported to connecting clients, e.g. "view" for a
+// view-only passcode; "" restores "view|edit|control"
+func (m *MockOSCServer) SetPermissions(permissions string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.permissions = permissions
+}
+
+// SetThumpEnabled sets whether the mock answers heartbeats, to simulate QLab going quiet
+func (m *MockOSCServer) SetThumpEnabled(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.thumpSilent = !enabled
+}
+
+// SetClockOffset makes the mock send replies as bundles timetagged with its own clock,
+// running the given offset from the local clock, the way a QLab machine with a different
+// clock would
+func (m *MockOSCServer) SetClockOffset(offset time.Duration) {
+	m.clockMu.Lock()
+	defer m.clockMu.Unlock()
+	m.clockOffset = &offset
+}
+
+// replyClockOffset returns the offset set by SetClockOffset, if any
+func (m *MockOSCServer) replyClockOffset() (time.Duration, bool) {
+	m.clockMu.Lock()
+	defer m.clockMu.Unlock()
+	if m.clockOffset == nil {
+		return 0, false
+	}
+	return *m.clockOffset, true
+}
+
 // sendErrorReply sends an error reply
 func (m *MockOSCServer) sendErrorReply(address, errorMsg string) {
 	// For compatibility with QLab error format, send error as JSON
@@ -761,7 +1931,9 @@ func (m *MockOSCServer) Clear() {
 
 	m.cues = make(map[string]*MockCue)
 	m.cuesByNumber = make(map[string]string)
+	m.listCues = make(map[string][]string)
 	m.nextCueNumber = 1
+	m.currentList = ""
 
 	log.Debug("Mock server cleared all cues")
 }
@@ -779,20 +1951,36 @@ func (m *MockOSCServer) registerCueHandlers(cueID string) {
 	m.registeredCues[cueID] = true
 	m.mu.Unlock()
 
-	workspacePrefix := fmt.Sprintf("/workspace/%s", m.workspaceID)
-
 	m.dispatcherMu.Lock()
 	defer m.dispatcherMu.Unlock()
+	m.addCueHandlers(cueID)
+}
+
+// addCueHandlers adds the handlers for a cue to the dispatcher; m.dispatcherMu must be
+// held while the server runs
+func (m *MockOSCServer) addCueHandlers(cueID string) {
+	workspacePrefix := fmt.Sprintf("/workspace/%s", m.workspaceID)
 
 	// Register handlers for all supported properties for this specific cue
-	properties := []string{"name", "number", "fileTarget", "file", "infiniteLoop", "mode", "cueTarget", "cueTargetNumber", "cueTargetID"}
+	properties := []string{"name", "number", "type", "fileTarget", "file", "infiniteLoop", "mode", "cueTarget", "cueTargetNumber", "cueTargetID",
+		"duration", "opacity", "translation", "scale", "rotation", "doOpacity", "doTranslation", "doScale", "doRotation",
+		"stopTargetWhenDone", "level", "gang", "masterLevel", "stageName", "stageID", "cartPosition",
+		"audioOutputPatchName", "audioOutputPatchID", "prune", "text", "colorName", "notes", "parent", "actionElapsed",
+		"armed", "flagged"}
+	for _, specs := range [][]cuePropertySpec{midiCueProperties, networkCueProperties, midiFileCueProperties, videoCueProperties, cartCueProperties, lightCueProperties, triggerCueProperties, followCueProperties, scriptCueProperties, devampCueProperties} {
+		for _, spec := range specs {
+			properties = append(properties, spec.key)
+		}
+	}
 	for _, prop := range properties {
 		address := fmt.Sprintf("%s/cue_id/%s/%s", workspacePrefix, cueID, prop)
 		_ = m.dispatcher.AddMsgHandler(address, m.handleSetCueProperty)
 	}
 
-	// Register move and delete handlers for this cue
+	// Register move, delete and children handlers for this cue
 	_ = m.dispatcher.AddMsgHandler(fmt.Sprintf("%s/move/%s", workspacePrefix, cueID), m.handleMoveCue)
+	_ = m.dispatcher.AddMsgHandler(fmt.Sprintf("%s/cue_id/%s/children", workspacePrefix, cueID), m.handleGetChildrenByID)
+	_ = m.dispatcher.AddMsgHandler(fmt.Sprintf("%s/cue_id/%s/valuesForKeys", workspacePrefix, cueID), m.handleValuesForKeys)
 	_ = m.dispatcher.AddMsgHandler(fmt.Sprintf("%s/delete_id/%s", workspacePrefix, cueID), m.handleDeleteCue)
 }
 
@@ -809,10 +1997,15 @@ func (m *MockOSCServer) registerCueListHandlers(cueListID string) {
 	m.registeredLists[cueListID] = true
 	m.mu.Unlock()
 
-	workspacePrefix := fmt.Sprintf("/workspace/%s", m.workspaceID)
-
 	m.dispatcherMu.Lock()
 	defer m.dispatcherMu.Unlock()
+	m.addCueListHandlers(cueListID)
+}
+
+// addCueListHandlers adds the handlers for a cue list to the dispatcher; m.dispatcherMu
+// must be held while the server runs
+func (m *MockOSCServer) addCueListHandlers(cueListID string) {
+	workspacePrefix := fmt.Sprintf("/workspace/%s", m.workspaceID)
 
 	// Register handlers for cue list properties
 	properties := []string{"name"}
@@ -820,6 +2013,9 @@ func (m *MockOSCServer) registerCueListHandlers(cueListID string) {
 		address := fmt.Sprintf("%s/cue_id/%s/%s", workspacePrefix, cueListID, prop)
 		_ = m.dispatcher.AddMsgHandler(address, m.handleSetCueListProperty)
 	}
+	_ = m.dispatcher.AddMsgHandler(fmt.Sprintf("%s/cue_id/%s/children", workspacePrefix, cueListID), m.handleGetChildrenByID)
+	_ = m.dispatcher.AddMsgHandler(fmt.Sprintf("%s/delete_id/%s", workspacePrefix, cueListID), m.handleDeleteCue)
+	_ = m.dispatcher.AddMsgHandler(fmt.Sprintf("%s/move/%s", workspacePrefix, cueListID), m.handleMoveCue)
 }
 
 // handleSetCueListProperty handles setting properties on cue lists
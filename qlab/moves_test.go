@@ -0,0 +1,126 @@
+package qlab
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestDetectMoves tests that reordered cues are moved rather than recreated
+func TestDetectMoves(t *testing.T) {
+	memo := func(number, name string) map[string]any {
+		return map[string]any{"type": "memo", "number": number, "name": name, "uniqueID": "id-" + number}
+	}
+	group := func(number string, cues ...any) map[string]any {
+		return map[string]any{"type": "group", "number": number, "name": "Scene", "uniqueID": "id-" + number, "cues": cues}
+	}
+	current := map[string]any{"data": []any{map[string]any{
+		"name": "Main", "uniqueID": "list-main", "type": "cue_list",
+		"cues": []any{memo("1", "One"), memo("2", "Two"), memo("3", "Three"), memo("4", "Four"), group("10", memo("10.1", "A"), memo("10.2", "B"))},
+	}}}
+	source := map[string]any{"cues": []any{map[string]any{
+		"type": "list", "name": "Main",
+		"cues": []any{memo("1", "One"), memo("3", "Three"), memo("2", "Two"), memo("4", "Four"), group("10", memo("10.2", "B")), memo("10.1", "A")},
+	}}}
+
+	comparison := &ThreeWayComparison{
+		CueResults:      make(map[string]*CueChangeResult),
+		HasQLabData:     true,
+		CurrentQLabData: current,
+	}
+	for _, key := range []string{"1", "2", "3", "4", "10", "10.1", "10.2"} {
+		comparison.CueResults[key] = &CueChangeResult{Action: "skip", ExistingID: "id-" + key}
+	}
+	comparison.CueResults["4"].Action = "update"
+
+	workspace := &Workspace{}
+	workspace.detectMoves(comparison, source)
+
+	moved := sortedResultKeys(comparison.CueResults, "move")
+	if strings.Join(moved, ",") != "10.1,2" {
+		t.Fatalf("Expected cues 10.1 and 2 to move, got %v", moved)
+	}
+	if move := comparison.CueResults["2"].Move; move.ParentID != "list-main" || move.AfterID != "id-3" {
+		t.Errorf("Expected cue 2 to move after cue 3 in Main, got %+v", move)
+	}
+	if result := comparison.CueResults["10.1"]; result.Move.AfterID != "id-10" || !strings.Contains(result.Reason, "list:Main") {
+		t.Errorf("Expected cue 10.1 to move out of its group after it, got %+v (%s)", result.Move, result.Reason)
+	}
+	for _, key := range []string{"1", "3", "4", "10", "10.2"} {
+		if result := comparison.CueResults[key]; result.Move != nil {
+			t.Errorf("Expected cue %s to stay in place, got %+v", key, result.Move)
+		}
+	}
+	if comparison.CueResults["4"].Action != "update" {
+		t.Errorf("Expected cue 4 to keep its update, got %s", comparison.CueResults["4"].Action)
+	}
+}
+
+// TestLongestIncreasingSubsequence tests choosing the cues that stay in place
+func TestLongestIncreasingSubsequence(t *testing.T) {
+	tests := []struct {
+		values []int
+		want   []int
+	}{
+		{nil, []int{}},
+		{[]int{0, 1, 2}, []int{0, 1, 2}},
+		{[]int{2, 1, 0}, []int{2}},
+		{[]int{0, 2, 1, 3}, []int{0, 2, 3}},
+		{[]int{3, 0, 1, 2}, []int{1, 2, 3}},
+	}
+	for _, test := range tests {
+		got := longestIncreasingSubsequence(test.values)
+		if len(got) != len(test.want) {
+			t.Errorf("longestIncreasingSubsequence(%v) = %v, want %v", test.values, got, test.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("longestIncreasingSubsequence(%v) = %v, want %v", test.values, got, test.want)
+				break
+			}
+		}
+	}
+}
+
+// TestApplyMoves tests that moves are sent to QLab in source order
+func TestApplyMoves(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+
+	ids := make(map[string]string)
+	for _, number := range []string{"1", "2", "3", "4", "10"} {
+		cueType := "memo"
+		if number == "10" {
+			cueType = "group"
+		}
+		id, err := workspace.createCueWithoutTarget(map[string]any{"type": cueType, "name": "Cue " + number}, number)
+		if err != nil {
+			t.Fatalf("Failed to create cue: %v", err)
+		}
+		ids[number] = id
+	}
+
+	results := map[string]*CueChangeResult{
+		"1": {Action: "skip", ExistingID: ids["1"]},
+		"2": {Action: "move", ExistingID: ids["2"], Move: &CueMove{ParentID: ids["10"], Parent: "10", AfterID: ids["1"], sequence: 2}},
+		"3": {Action: "move", ExistingID: ids["3"], Move: &CueMove{ParentID: ids["10"], Parent: "10", sequence: 0}},
+		"4": {Action: "skip", ExistingID: ids["4"], Move: &CueMove{ParentID: ids["10"], Parent: "10", sequence: 1}},
+	}
+	mockServer.ClearReceivedMessages()
+	if err := workspace.applyMoves(results); err != nil {
+		t.Fatalf("applyMoves failed: %v", err)
+	}
+
+	var moved []string
+	for _, msg := range mockServer.GetReceivedMessages() {
+		if _, cueID, ok := strings.Cut(msg.Address, "/move/"); ok {
+			moved = append(moved, cueID)
+			if len(msg.Arguments) != 2 || msg.Arguments[1] != ids["10"] {
+				t.Errorf("Expected a move into the group, got %v", msg.Arguments)
+			}
+		}
+	}
+	// Cue 4 was kept as it is in QLab, so only the two moves are applied
+	if strings.Join(moved, ",") != ids["3"]+","+ids["2"] {
+		t.Errorf("Expected cue 3 then cue 2 to move, got %v", moved)
+	}
+}
@@ -0,0 +1,94 @@
+package qlab
+
+import (
+	"testing"
+)
+
+// TestCuePropertySpecFormat tests value conversion for mapped cue properties
+func TestCuePropertySpecFormat(t *testing.T) {
+	tests := []struct {
+		spec     cuePropertySpec
+		value    any
+		expected string
+		wantErr  bool
+	}{
+		{cuePropertySpec{key: "command", kind: cuePropertyInt, names: mscCommands}, "GO", "1", false},
+		{cuePropertySpec{key: "command", kind: cuePropertyInt, names: mscCommands}, 7.0, "7", false},
+		{cuePropertySpec{key: "status", kind: cuePropertyInt, names: midiStatuses}, "noteOn", "144", false},
+		{cuePropertySpec{key: "channel", kind: cuePropertyInt}, "10", "10", false},
+		{cuePropertySpec{key: "channel", kind: cuePropertyInt}, 1.5, "", true},
+		{cuePropertySpec{key: "command", kind: cuePropertyInt, names: mscCommands}, "jump", "", true},
+		{cuePropertySpec{key: "doFade", kind: cuePropertyBool}, true, "1", false},
+		{cuePropertySpec{key: "rate", kind: cuePropertyFloat}, 0.5, "0.5", false},
+		{cuePropertySpec{key: "qNumber", kind: cuePropertyString}, "12.5", "12.5", false},
+		{cuePropertySpec{key: "qNumber", kind: cuePropertyString}, 12.5, "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.spec.format(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s(%v): expected error=%v, got %v", tt.spec.key, tt.value, tt.wantErr, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("%s(%v): expected %q, got %q", tt.spec.key, tt.value, tt.expected, got)
+		}
+	}
+}
+
+// TestCreateMSCCue tests that MIDI Show Control properties are sent when creating a MIDI cue
+func TestCreateMSCCue(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+
+	cueID, err := workspace.createCueWithoutTarget(map[string]any{
+		"type":          "midi",
+		"name":          "LX 12",
+		"midiPatchName": "Console",
+		"messageType":   "msc",
+		"command":       "go",
+		"commandFormat": 1.0,
+		"deviceID":      0.0,
+		"qNumber":       "12",
+		"qList":         "1",
+	}, "")
+	if err != nil {
+		t.Fatalf("createCueWithoutTarget failed: %v", err)
+	}
+
+	mockCue := mockServer.GetCue(cueID)
+	expected := map[string]string{
+		"midiPatchName": "Console",
+		"messageType":   "2",
+		"command":       "1",
+		"commandFormat": "1",
+		"deviceID":      "0",
+		"qNumber":       "12",
+		"qList":         "1",
+	}
+	for property, value := range expected {
+		if got := mockCue.Properties[property]; got != value {
+			t.Errorf("Expected %s=%q, got %q", property, value, got)
+		}
+	}
+}
+
+// TestUpdateNetworkCueRejectsBadValue tests that updates surface invalid mapped values
+func TestUpdateNetworkCueRejectsBadValue(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+
+	cueID, err := workspace.createCueWithoutTarget(map[string]any{
+		"type":         "network",
+		"customString": "/eos/cue/1/go",
+	}, "")
+	if err != nil {
+		t.Fatalf("createCueWithoutTarget failed: %v", err)
+	}
+	if got := mockServer.GetCue(cueID).Properties["customString"]; got != "/eos/cue/1/go" {
+		t.Errorf("Expected customString to be set, got %q", got)
+	}
+
+	err = workspace.updateCueProperties(cueID, map[string]any{"type": "network", "patch": "first"})
+	if err == nil {
+		t.Error("Expected error for non-numeric patch")
+	}
+}
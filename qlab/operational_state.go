@@ -0,0 +1,48 @@
+package qlab
+
+import "fmt"
+
+// operationalStateProperties are the cue properties operators change during rehearsals and
+// shows. They're neither compared nor sent unless SetSyncOperationalState is on.
+var operationalStateProperties = []string{"armed", "flagged"}
+
+// SetSyncOperationalState sets whether the armed and flagged states in the source are
+// compared and sent like any other property, e.g. to disarm every pyro cue for a rehearsal.
+// Off by default, so operators can arm, disarm and flag cues in QLab without a sync undoing
+// it. Cues whose source doesn't give a state keep QLab's. Running and paused cues are
+// playback, not state a sync sends; see the playback controls.
+func (q *Workspace) SetSyncOperationalState(enabled bool) {
+	q.operationalSync = enabled
+}
+
+// compareOperationalState compares two armed or flagged values, as booleans when syncing
+// operational state and otherwise treating every pair as equal. A value one side doesn't
+// give matches anything.
+func (q *Workspace) compareOperationalState(val1, val2 string) bool {
+	if !q.operationalSync || val1 == "" || val2 == "" {
+		return true
+	}
+	return toBool(val1) == toBool(val2)
+}
+
+// setOperationalState sets the armed and flagged states the source gives a cue, when syncing
+// operational state
+func (q *Workspace) setOperationalState(uniqueID string, cueData map[string]any) error {
+	if !q.operationalSync {
+		return nil
+	}
+	for _, property := range operationalStateProperties {
+		value, ok := cueData[property]
+		if !ok || value == nil || value == "" {
+			continue
+		}
+		state := "0"
+		if toBool(value) {
+			state = "1"
+		}
+		if err := q.setCueProperty(uniqueID, property, state); err != nil {
+			return fmt.Errorf("failed to set %s: %w", property, err)
+		}
+	}
+	return nil
+}
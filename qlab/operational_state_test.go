@@ -0,0 +1,59 @@
+package qlab
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestSyncOperationalState tests that a source's armed state is only sent when syncing
+// operational state, and that cues without one keep QLab's
+func TestSyncOperationalState(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+	workspace.SetCacheStore(NewMemoryCacheStore())
+	workspace.SetSkipInbox(true)
+	path := filepath.Join(t.TempDir(), "show.json")
+
+	source := func(armed any) map[string]any {
+		pyro := map[string]any{"type": "memo", "number": "1", "name": "Pyro"}
+		if armed != nil {
+			pyro["armed"] = armed
+		}
+		return map[string]any{"cues": []any{pyro, map[string]any{"type": "memo", "number": "2", "name": "Haze"}}}
+	}
+	if _, err := workspace.TransmitWorkspaceData(path, source("true")); err != nil {
+		t.Fatalf("First transmit failed: %v", err)
+	}
+	pyroID, _ := workspace.lookupCueNumber("1")
+	hazeID, _ := workspace.lookupCueNumber("2")
+	if err := workspace.setCueProperty(hazeID, "armed", "0"); err != nil {
+		t.Fatalf("setCueProperty failed: %v", err)
+	}
+
+	// Disarming in the source is ignored by default
+	comparison, err := workspace.TransmitWorkspaceData(path, source(false))
+	if err != nil {
+		t.Fatalf("Second transmit failed: %v", err)
+	}
+	if result := comparison.CueResults["1"]; result == nil || result.Action != "skip" {
+		t.Errorf("Expected the armed state ignored, got %+v", result)
+	}
+	if armed := mockServer.GetCue(pyroID).Properties["armed"]; armed != "1" {
+		t.Errorf("Expected cue 1 still armed, got %q", armed)
+	}
+
+	workspace.SetSyncOperationalState(true)
+	comparison, err = workspace.TransmitWorkspaceData(path, source(false))
+	if err != nil {
+		t.Fatalf("Third transmit failed: %v", err)
+	}
+	if result := comparison.CueResults["1"]; result == nil || result.Action != "update" || result.ModifiedFields["armed"] == "" {
+		t.Errorf("Expected cue 1 updated for its armed state, got %+v", result)
+	}
+	if armed := mockServer.GetCue(pyroID).Properties["armed"]; armed != "0" {
+		t.Errorf("Expected cue 1 disarmed, got %q", armed)
+	}
+	if armed := mockServer.GetCue(hazeID).Properties["armed"]; armed != "0" {
+		t.Errorf("Expected cue 2 left disarmed in QLab, got %q", armed)
+	}
+}
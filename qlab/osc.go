@@ -1,56 +1,36 @@
 package qlab
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"strconv"
+	"slices"
 	"strings"
 	"time"
 
-	"github.com/charmbracelet/log"
 	"github.com/hypebeast/go-osc/osc"
 )
 
-// Helper functions for pretty JSON logging
-func logPrettyJSON(logger *log.Logger, level log.Level, message string, jsonStr string) {
+// Helper function for pretty JSON logging
+func logInfoJSON(logger Logger, message string, jsonStr string) {
 	// First try to pretty print the JSON with indentation
 	var jsonData any
 	if err := json.Unmarshal([]byte(jsonStr), &jsonData); err != nil {
 		// Fallback to raw string if JSON parsing fails
-		logger.Log(level, message, "raw", jsonStr)
+		logger.Info(message, "raw", jsonStr)
 		return
 	}
 
 	prettyBytes, err := json.MarshalIndent(jsonData, "", "  ")
 	if err != nil {
 		// Fallback to structured data if pretty printing fails
-		logger.Log(level, message, "data", jsonData)
+		logger.Info(message, "data", jsonData)
 		return
 	}
 
 	// Log with pretty formatted JSON
-	logger.Log(level, message+"\n"+string(prettyBytes))
-}
-
-func logInfoJSON(message string, jsonStr string) {
-	logPrettyJSON(log.Default(), log.InfoLevel, message, jsonStr)
-}
-
-// formatErrorWithJSON creates a pretty-printed error message from a JSON string
-func formatErrorWithJSON(baseMessage string, jsonStr string) error {
-	var jsonData any
-	if err := json.Unmarshal([]byte(jsonStr), &jsonData); err != nil {
-		// Fallback to raw string if JSON parsing fails
-		return fmt.Errorf("%s: %s", baseMessage, jsonStr)
-	}
-
-	prettyBytes, err := json.MarshalIndent(jsonData, "", "  ")
-	if err != nil {
-		// Fallback to structured data if pretty printing fails
-		return fmt.Errorf("%s: %v", baseMessage, jsonData)
-	}
-
-	return fmt.Errorf("%s:\n%s", baseMessage, string(prettyBytes))
+	logger.Info(message + "\n" + string(prettyBytes))
 }
 
 type OscClient interface {
@@ -67,148 +47,274 @@ type InitReplyArg struct {
 	Error       string `json:"error,omitempty"`
 }
 
+// Send sends a message with an optional string argument and returns QLab's reply
+// arguments, or an error reply if it couldn't be sent or went unanswered
+//
+// This API is stable.
 func (q *Workspace) Send(address string, input string) []any {
 	if q.dryRun && q.isWriteOperation(address) {
-		log.Printf("[DRY RUN] Would send OSC message: %s ,s %s", address, input)
+		q.log().Infof("[DRY RUN] Would send OSC message: %s ,s %s", address, input)
 		return q.mockDryRunResponse(address, input)
 	}
+	if tx := q.activeTransaction(); tx != nil {
+		var args []any
+		if input != "" {
+			args = []any{input}
+		}
+		op := tx.beginOp(address, args)
+		reply := q.sendWithRetry(address, input, nil)
+		tx.endOp(op, reply)
+		return reply
+	}
 	return q.sendWithRetry(address, input, nil)
 }
 
+// SendNoReply sends a message without waiting for a reply, for messages QLab doesn't answer
+//
+// This API is stable.
 func (q *Workspace) SendNoReply(address string, args ...any) error {
+	if q.shutdown.isClosing() {
+		return ErrClosed
+	}
+	return q.sendNoReply(address, args...)
+}
+
+// sendNoReply is SendNoReply, even while shutting down
+func (q *Workspace) sendNoReply(address string, args ...any) error {
+	if err := q.checkMessageWritable(address, "", args); err != nil {
+		return err
+	}
 	msg := osc.NewMessage(address)
 	for _, arg := range args {
 		msg.Append(arg)
 	}
-	log.Debugf("Sending message without reply: %s %v", address, args)
-	return q.client.Send(msg)
+	q.log().Debugf("Sending message without reply: %s %v", address, args)
+	sentAt := time.Now()
+	err := q.sendPacket(address, msg)
+	if err == nil {
+		q.audit(address, "", args, sentAt, nil, errNoReply)
+	} else {
+		q.audit(address, "", args, sentAt, nil, err)
+	}
+	return err
 }
 
+// StartUpdateListener subscribes to QLab's /update messages and passes each to updateHandler
+//
+// This API is stable.
 func (q *Workspace) StartUpdateListener(updateHandler func(address string, args []any)) error {
-	if q.updateServer != nil {
-		log.Debugf("Update server already running")
-		q.updateHandler = updateHandler
-		return nil
-	}
-
 	q.updateHandler = updateHandler
-	d := osc.NewStandardDispatcher()
 
-	_ = d.AddMsgHandler("*", func(msg *osc.Message) {
-		log.Infof("Received OSC message: %s %v", msg.Address, msg.Arguments)
+	// A client's shared listener is subscribed by Client.Connect
+	if q.pool != nil {
+		q.log().Debugf("Update listener shared with the client")
+		return nil
+	}
 
-		// Check if it's an update message
-		if strings.HasPrefix(msg.Address, "/update") {
-			log.Infof("Matched update message: %s", msg.Address)
-			if q.updateHandler != nil {
-				q.updateHandler(msg.Address, msg.Arguments)
-			}
-			return
+	// Over TCP, replies and updates arrive on the connection itself
+	if q.useTCP {
+		if _, err := q.connectStream(); err != nil {
+			return err
 		}
-
-		// Check if it's a reply message
-		if strings.HasPrefix(msg.Address, "/reply") {
-			log.Debugf("Matched reply message: %s", msg.Address)
-			// Find the first handler that matches this address (with any request ID)
-			q.replyHandlersMux.Lock()
-			var foundHandler chan []any
-			var foundKey string
-			for handlerKey, handler := range q.replyHandlers {
-				// Check if this handler key matches the base address (before the #requestID)
-				baseAddr := strings.Split(handlerKey, "#")[0]
-				if baseAddr == msg.Address {
-					log.Debugf("Routing reply to handler: %s", handlerKey)
-					foundHandler = handler
-					foundKey = handlerKey
-					break
-				}
-			}
-			if foundHandler != nil {
-				delete(q.replyHandlers, foundKey)
-			}
-			q.replyHandlersMux.Unlock()
-
-			if foundHandler != nil {
-				foundHandler <- msg.Arguments
-			} else {
-				log.Debugf("No handler found for reply: %s", msg.Address)
-			}
-			return
+		if err := q.SendNoReply("/updates", int32(1)); err != nil {
+			return fmt.Errorf("failed to subscribe to updates: %w", err)
 		}
-	})
+		q.log().Info("Subscribed to QLab status updates over TCP")
+		return nil
+	}
 
-	maxRetries := 10
-	baseReplyPort := q.port + 1
+	if err := q.startReplyListener(); err != nil {
+		return err
+	}
+	if err := q.SendNoReply("/updates", int32(1)); err != nil {
+		q.log().Error("Failed to subscribe to updates", "error", err)
+	} else {
+		q.log().Info("Subscribed to QLab status updates")
+	}
+	return nil
+}
 
-	for i := range maxRetries {
-		replyPort := baseReplyPort + i
-		replyHost := fmt.Sprintf("%s:%d", q.host, replyPort)
+// handleIncomingMessage routes a message from QLab to the update handler or to the
+// handler waiting for its reply
+func (q *Workspace) handleIncomingMessage(msg *osc.Message) {
+	q.log().Infof("Received OSC message: %s %v", msg.Address, msg.Arguments)
 
-		log.Infof("Starting persistent OSC listener on %s", replyHost)
+	// Check if it's an update message
+	if strings.HasPrefix(msg.Address, "/update") {
+		if q.isWorkspaceClosedUpdate(msg.Address) {
+			q.notifyDisconnect(DisconnectWorkspaceClosed, "", nil)
+			q.wasConnected.Store(false)
+		}
+		q.log().Infof("Matched update message: %s", msg.Address)
+		if q.updateHandler != nil {
+			q.updateHandler(msg.Address, msg.Arguments)
+		}
+		return
+	}
 
-		q.serverMux.Lock()
-		q.updateServer = &osc.Server{
-			Addr:       replyHost,
-			Dispatcher: d,
+	// Check if it's a reply message
+	if strings.HasPrefix(msg.Address, "/reply") {
+		q.log().Debugf("Matched reply message: %s", msg.Address)
+		if !q.deliverReply(msg) {
+			q.log().Debugf("No handler found for reply: %s", msg.Address)
 		}
-		q.updateServerReady = make(chan struct{})
-		server := q.updateServer
-		ready := q.updateServerReady
-		q.serverMux.Unlock()
-
-		started := make(chan error, 1)
-		go func() {
-			err := server.ListenAndServe()
-			if err != nil && !strings.Contains(err.Error(), "use of closed network connection") {
-				log.Errorf("OSC server exited with error: %v", err)
-			}
-			started <- err
-		}()
+		return
+	}
+}
 
-		// Wait a bit for server to bind
-		time.Sleep(50 * time.Millisecond)
+// pendingReply is a message sent to QLab whose reply hasn't arrived
+type pendingReply struct {
+	requestID int
+	reply     chan []any
+	expires   time.Time // Set once the request stops waiting; until then its late reply is dropped
+	shifted   bool      // Whether a late reply was dropped just ahead of it, which may have been its own
+}
 
-		select {
-		case err := <-started:
-			if err != nil && strings.Contains(err.Error(), "bind: address already in use") {
-				log.Debugf("Port %d in use, trying next port", replyPort)
-				q.serverMux.Lock()
-				close(ready) // Close channel before clearing
-				q.updateServer = nil
-				q.updateServerReady = nil
-				q.serverMux.Unlock()
-				continue
-			} else if err != nil && !strings.Contains(err.Error(), "use of closed network connection") {
-				log.Errorf("OSC listener error on %s: %v", replyHost, err)
-				q.serverMux.Lock()
-				close(ready) // Close channel before clearing
-				q.updateServer = nil
-				q.updateServerReady = nil
-				q.serverMux.Unlock()
-				continue
-			}
-			close(ready)
-			return nil
-		case <-time.After(200 * time.Millisecond):
-			close(ready) // Server started successfully
-			log.Infof("OSC listener started successfully on %s", replyHost)
-
-			if err := q.SendNoReply("/updates", int32(1)); err != nil {
-				log.Error("Failed to subscribe to updates", "error", err)
-			} else {
-				log.Info("Subscribed to QLab status updates")
-			}
+// deliverReply passes a reply to the handler waiting for it, reporting whether there was one.
+// QLab answers the messages it receives in order, and the listener handles them one at a
+// time, so replies to the same address go to the messages sent to it oldest first. The
+// reply to a message whose request stopped waiting is dropped rather than given to the
+// next one; a reply that hasn't come once the request has waited as long again is taken
+// to be lost. Since the reply dropped may have been the next message's own when the late
+// one was lost, the next message doesn't drop a reply of its own if it goes unanswered.
+func (q *Workspace) deliverReply(msg *osc.Message) bool {
+	now := time.Now()
+	q.replyHandlersMux.Lock()
+	pending := q.replyHandlers[msg.Address]
+	for len(pending) > 0 && !pending[0].expires.IsZero() && now.After(pending[0].expires) {
+		pending = pending[1:]
+	}
+	if len(pending) == 0 {
+		delete(q.replyHandlers, msg.Address)
+		q.replyHandlersMux.Unlock()
+		return false
+	}
+	handler := pending[0]
+	if len(pending) == 1 {
+		delete(q.replyHandlers, msg.Address)
+	} else {
+		q.replyHandlers[msg.Address] = pending[1:]
+	}
+	q.replyHandlersMux.Unlock()
 
-			return nil
+	if !handler.expires.IsZero() {
+		q.log().Debugf("Dropping late reply: %s#%d", msg.Address, handler.requestID)
+		q.replyHandlersMux.Lock()
+		for i, p := range q.replyHandlers[msg.Address] {
+			if p.expires.IsZero() {
+				q.replyHandlers[msg.Address][i].shifted = true
+				break
+			}
 		}
+		q.replyHandlersMux.Unlock()
+		return true
 	}
+	q.log().Debugf("Routing reply to handler: %s#%d", msg.Address, handler.requestID)
+	select {
+	case handler.reply <- msg.Arguments:
+	default:
+		q.log().Debugf("Dropping reply nobody is waiting for: %s#%d", msg.Address, handler.requestID)
+	}
+	return true
+}
 
-	return fmt.Errorf("failed to start OSC listener after %d attempts", maxRetries)
+// pendingReplyCount returns the number of messages whose request is waiting for a reply
+func (q *Workspace) pendingReplyCount() int {
+	q.replyHandlersMux.Lock()
+	defer q.replyHandlersMux.Unlock()
+	count := 0
+	for _, pending := range q.replyHandlers {
+		for _, p := range pending {
+			if p.expires.IsZero() {
+				count++
+			}
+		}
+	}
+	return count
 }
 
 func (q *Workspace) sendWithRetry(address string, input string, args []any) []any {
-	maxRetries := q.maxRetries
+	reply, err := q.sendWithRetryCtx(context.Background(), address, input, args)
+	if errors.Is(err, ErrReadOnly) || errors.Is(err, ErrInsufficientPermissions) {
+		q.log().Warnf("Not sending %s: %v", address, err)
+		return refusedReply(address, err)
+	}
+	if errors.Is(err, ErrPayloadTooLarge) {
+		q.log().Warnf("Not sending %s: %v", address, err)
+		return payloadTooLargeReply(err)
+	}
+	if errors.Is(err, ErrClosed) {
+		q.log().Warnf("Not sending %s: %v", address, err)
+		return []any{fmt.Sprintf(`{"status": "error", "error": %q, "address": %q}`, err.Error(), address)}
+	}
+	if errors.Is(err, ErrReauthenticationRequired) {
+		q.log().Warnf("Not sending %s: %v", address, err)
+		return reauthenticationReply(address)
+	}
+	if err != nil {
+		q.log().Debugf("Send to %s ended without reply: %v", address, err)
+	}
+	if isTimeoutReply(reply) {
+		q.recordFailedSend(address, input, args)
+	}
+	return reply
+}
+
+// sendWithRetryCtx sends a message and waits for its reply, retrying on timeout.
+// The context is honored while waiting for a reply and between retries; when it is
+// cancelled or its deadline passes the pending reply handler is removed and ctx.Err()
+// is returned. Timeouts after all retries still return the legacy error reply JSON.
+func (q *Workspace) sendWithRetryCtx(ctx context.Context, address string, input string, args []any) (replyArgs []any, err error) {
+	if err := q.checkMessageWritable(address, input, args); err != nil {
+		return nil, err
+	}
+	if !q.shutdown.begin() {
+		return nil, ErrClosed
+	}
+	defer q.shutdown.end()
+	if q.reauthRequired.Load() && needsPasscode(address) {
+		return nil, ErrReauthenticationRequired
+	}
+	if q.auditSink != nil {
+		sentAt := time.Now()
+		defer func() { q.audit(address, input, args, sentAt, replyArgs, err) }()
+	}
+
+	// Wait in the send queue for an in-flight slot
+	release, err := q.sendLimits.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	policy := q.retryPolicyFor(address, input, args)
+	maxRetries := policy.MaxRetries
+	timeout := q.replyTimeout()
+
+	// Every attempt waits in line for its own reply, on one channel: a late reply to an
+	// earlier attempt answers the message as well as the retry's would. The channel holds
+	// the reply so delivering it never blocks, even when the wait has just ended. Replies
+	// still to come once the request stops waiting are dropped, for as long as it waited.
+	reply := make(chan []any, 1)
+	var requestIDs []int
+	waitStart := time.Now()
+	defer func() { q.settleReplies(address, time.Since(waitStart), requestIDs...) }()
+
+	var sendErr error
 	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if attempt > 0 {
+			q.reportRetry(address)
+			// Back off before retrying to avoid overwhelming QLab
+			select {
+			case <-time.After(policy.delay(attempt)):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
 		msg := osc.NewMessage(address)
 		if input != "" {
 			msg.Append(input)
@@ -217,165 +323,204 @@ func (q *Workspace) sendWithRetry(address string, input string, args []any) []an
 			msg.Append(arg)
 		}
 
-		// Generate unique request ID for this request
-		q.requestCounter++
-		requestID := q.requestCounter
+		// Refuse packets UDP would truncate
+		if err := q.checkPayloadSize(address, msg); err != nil {
+			return nil, err
+		}
 
-		// Start listening for a reply with unique request ID
-		reply := make(chan []any)
+		// Generate unique request ID for this attempt and start listening for its reply
+		requestID := q.nextRequestID()
 		q.ListenForReply(address, reply, requestID)
 
 		// Send the message and wait for reply from listener with timeout
 		startTime := time.Now()
-		if err := q.client.Send(msg); err != nil {
-			log.Warnf("Failed to send OSC message: %v", err)
+		if err := q.sendPacketCtx(ctx, address, msg); err != nil {
+			q.log().Warnf("Failed to send OSC message: %v", err)
+			q.removeReplyHandler(address, requestID)
+			sendErr = err
 			continue
 		}
-		log.Debugf("Message sent to %s:%d - %s (attempt %d/%d, requestID: %d)", q.host, q.port, msg.String(), attempt+1, maxRetries+1, requestID)
-
-		timeout := q.timeout
-		if timeout == 0 {
-			timeout = 10
-		}
+		requestIDs = append(requestIDs, requestID)
+		q.log().Debugf("Message sent to %s:%d - %s (attempt %d/%d, requestID: %d)", q.host, q.port, msg.String(), attempt+1, maxRetries+1, requestID)
 
 		select {
 		case result := <-reply:
 			duration := time.Since(startTime)
-			log.Debugf("Reply received for %s in %v (requestID: %d)", address, duration, requestID)
-			q.consecutiveErrors = 0
-			q.wasConnected = true
-			return result
-		case <-time.After(time.Duration(timeout) * time.Second):
-			// Clean up the handler that timed out
-			replyAddress := q.addressBuilder.BuildReplyAddress(address)
-			uniqueReplyAddress := fmt.Sprintf("%s#%d", replyAddress, requestID)
-			q.replyHandlersMux.Lock()
-			delete(q.replyHandlers, uniqueReplyAddress)
-			q.replyHandlersMux.Unlock()
+			q.log().Debugf("Reply received for %s in %v (requestID: %d)", address, duration, requestID)
+			q.reportReply(address, startTime)
+			q.consecutiveErrors.Store(0)
+			if isAuthFailureReply(result) && q.passcodeRevoked(address) {
+				result = reauthenticationReply(address)
+			}
+			if q.wasConnected.Load() && isAuthFailureReply(result) {
+				q.notifyDisconnect(DisconnectBadPasscode, address, ErrAuthFailed)
+				q.wasConnected.Store(false)
+				return result, nil
+			}
+			q.wasConnected.Store(true)
+			return result, nil
+		case <-ctx.Done():
+			q.log().Debugf("Context done while waiting for reply from %s (requestID: %d): %v", address, requestID, ctx.Err())
+			return nil, ctx.Err()
+		case <-time.After(timeout):
+			q.reportTimeout(address)
 
 			if attempt < maxRetries {
-				if q.wasConnected {
-					log.Warnf("Timeout waiting for reply from QLab for address %s (attempt %d/%d), retrying...", address, attempt+1, maxRetries+1)
+				if q.wasConnected.Load() {
+					q.log().Warnf("Timeout waiting for reply from QLab for address %s (attempt %d/%d), retrying...", address, attempt+1, maxRetries+1)
 				} else {
-					log.Debugf("Timeout waiting for reply from QLab for address %s (attempt %d/%d), retrying...", address, attempt+1, maxRetries+1)
+					q.log().Debugf("Timeout waiting for reply from QLab for address %s (attempt %d/%d), retrying...", address, attempt+1, maxRetries+1)
 				}
-				// Small delay before retry to avoid overwhelming QLab
-				time.Sleep(100 * time.Millisecond)
 			} else {
-				q.consecutiveErrors++
-				if q.wasConnected {
-					log.Warnf("Timeout waiting for reply from QLab for address %s after all retry attempts", address)
+				q.sendLimits.recordUnanswered()
+				q.consecutiveErrors.Add(1)
+				if q.wasConnected.Load() {
+					q.log().Warnf("Timeout waiting for reply from QLab for address %s after all retry attempts", address)
 
 					// Provide helpful guidance for common timeout scenarios
 					if strings.Contains(address, "/cueLists") {
-						log.Warn("The /cueLists query timed out - this usually means:")
-						log.Warn("  1. Your QLab workspace has many cues (100+ cues can slow this query)")
-						log.Warn("  2. QLab is busy processing other operations")
-						log.Warn("  3. Network latency between client and QLab")
-						log.Infof("Recommendation: Increase timeout with SetTimeout(30) or SetTimeout(60)")
-						log.Infof("Current timeout: %d seconds, Current retries: %d", q.timeout, q.maxRetries)
+						q.log().Warn("The /cueLists query timed out - this usually means:")
+						q.log().Warn("  1. Your QLab workspace has many cues (100+ cues can slow this query)")
+						q.log().Warn("  2. QLab is busy processing other operations")
+						q.log().Warn("  3. Network latency between client and QLab")
+						q.log().Infof("Recommendation: Increase timeout with SetTimeout(30) or SetTimeout(60)")
+						q.log().Infof("Current timeout: %d seconds, Current retries: %d", q.timeout, maxRetries)
 					}
 
-					if q.consecutiveErrors >= 2 && q.onDisconnect != nil {
-						q.onDisconnect()
-						q.wasConnected = false
+					if q.consecutiveErrors.Load() >= 2 {
+						q.notifyDisconnect(DisconnectTimeoutStorm, address, ErrTimeout)
+						q.wasConnected.Store(false)
 					}
 				} else {
-					log.Debugf("Timeout waiting for reply from QLab for address %s after all retry attempts", address)
+					q.log().Debugf("Timeout waiting for reply from QLab for address %s after all retry attempts", address)
 				}
-				return []any{`{"status": "error", "error": "timeout waiting for reply from QLab"}`}
+				return []any{timeoutReply}, nil
 			}
 		}
 	}
-	q.consecutiveErrors++
-	if q.wasConnected && q.consecutiveErrors >= 2 && q.onDisconnect != nil {
-		q.onDisconnect()
-		q.wasConnected = false
+	q.consecutiveErrors.Add(1)
+	if q.wasConnected.Load() && q.consecutiveErrors.Load() >= 2 {
+		reason := DisconnectTimeoutStorm
+		if sendErr != nil {
+			reason = DisconnectNetworkUnreachable
+		}
+		q.notifyDisconnect(reason, address, sendErr)
+		q.wasConnected.Store(false)
 	}
-	return []any{`{"status": "error", "error": "timeout waiting for reply from QLab"}`}
+	return []any{timeoutReply}, nil
 }
 
-func (q *Workspace) SendWithArgs(address string, args ...any) []any {
-	if q.dryRun && q.isWriteOperation(address) {
-		log.Printf("[DRY RUN] Would send OSC message: %s %v", address, args)
-		return q.mockDryRunResponse(address, "")
-	}
-	return q.sendWithRetry(address, "", args)
+// nextRequestID returns a request ID no other pending request has, so that replies to
+// concurrent queries are routed to the right caller
+func (q *Workspace) nextRequestID() int {
+	q.replyHandlersMux.Lock()
+	defer q.replyHandlersMux.Unlock()
+	q.requestCounter++
+	return q.requestCounter
 }
 
-func (q *Workspace) ListenForReply(address string, reply chan []any, requestID int) {
-	replyAddress := q.addressBuilder.BuildReplyAddress(address)
-	uniqueReplyAddress := fmt.Sprintf("%s#%d", replyAddress, requestID)
+// replyTimeout returns how long a request waits for each reply
+func (q *Workspace) replyTimeout() time.Duration {
+	if q.timeout == 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(q.timeout) * time.Second
+}
 
-	// If persistent server is running, register handler with it
-	if q.updateServer != nil {
-		log.Debugf("Registering reply handler for: %s (using persistent server, requestID: %d)", replyAddress, requestID)
-		q.replyHandlersMux.Lock()
-		q.replyHandlers[uniqueReplyAddress] = reply
-		q.replyHandlersMux.Unlock()
+// settleReplies marks the messages of a request that stopped waiting, so their replies are
+// dropped if they arrive within grace, and skipped as lost after that. Messages whose reply
+// may already have been dropped as a late one are forgotten instead.
+func (q *Workspace) settleReplies(address string, grace time.Duration, requestIDs ...int) {
+	if len(requestIDs) == 0 {
 		return
 	}
-
-	// Note: We don't try to close existing reply servers here anymore
-	// Each request gets its own server instance that closes itself after receiving a reply
-
-	d := osc.NewStandardDispatcher()
-	log.Debugf("Reply address: %s", replyAddress)
-
-	// Capture server reference for the handler to close
-	var localServer *osc.Server
-
-	_ = d.AddMsgHandler(replyAddress, func(msg *osc.Message) {
-		log.Debugf("Received reply message, closing server")
-		if localServer != nil {
-			_ = localServer.CloseConnection()
+	replyAddress := q.addressBuilder.BuildReplyAddress(address)
+	expires := time.Now().Add(grace)
+	q.replyHandlersMux.Lock()
+	defer q.replyHandlersMux.Unlock()
+	var pending []pendingReply
+	for _, p := range q.replyHandlers[replyAddress] {
+		if slices.Contains(requestIDs, p.requestID) {
+			if p.shifted {
+				continue
+			}
+			p.expires = expires
 		}
-		reply <- msg.Arguments
-	})
-
-	// Try to find an available port starting with port + 1
-	maxRetries := 10
-	baseReplyPort := q.port + 1
+		pending = append(pending, p)
+	}
+	if len(pending) == 0 {
+		delete(q.replyHandlers, replyAddress)
+	} else {
+		q.replyHandlers[replyAddress] = pending
+	}
+}
 
-	for i := range maxRetries {
-		replyPort := baseReplyPort + i
-		reply_host := q.host + ":" + strconv.Itoa(replyPort)
+// removeReplyHandler unregisters the reply handler for a message that was never sent
+func (q *Workspace) removeReplyHandler(address string, requestID int) {
+	replyAddress := q.addressBuilder.BuildReplyAddress(address)
+	q.replyHandlersMux.Lock()
+	defer q.replyHandlersMux.Unlock()
+	pending := slices.DeleteFunc(q.replyHandlers[replyAddress], func(p pendingReply) bool {
+		return p.requestID == requestID
+	})
+	if len(pending) == 0 {
+		delete(q.replyHandlers, replyAddress)
+	} else {
+		q.replyHandlers[replyAddress] = pending
+	}
+}
 
-		log.Debugf("Setting up reply server for address %s", address)
-		log.Debugf("QLab host:port = %s:%d, Reply server attempting to bind to: %s", q.host, q.port, reply_host)
+// SendWithArgs is Send with any number of arguments
+//
+// This API is stable.
+func (q *Workspace) SendWithArgs(address string, args ...any) []any {
+	if q.dryRun && q.isWriteOperation(address) {
+		q.log().Infof("[DRY RUN] Would send OSC message: %s %v", address, args)
+		return q.mockDryRunResponse(address, "")
+	}
+	if tx := q.activeTransaction(); tx != nil {
+		op := tx.beginOp(address, args)
+		reply := q.sendWithRetry(address, "", args)
+		tx.endOp(op, reply)
+		return reply
+	}
+	return q.sendWithRetry(address, "", args)
+}
 
-		server := &osc.Server{
-			Addr:       reply_host,
-			Dispatcher: d,
-		}
-		localServer = server // Assign to captured variable for handler
+// SendCtx is the context-aware counterpart of Send and SendWithArgs.
+// The call returns early with ctx.Err() when the context is cancelled or its
+// deadline passes, so callers can abort in-flight requests, impose per-call
+// deadlines shorter than the workspace timeout, and drain on shutdown.
+func (q *Workspace) SendCtx(ctx context.Context, address string, args ...any) ([]any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if q.dryRun && q.isWriteOperation(address) {
+		q.log().Infof("[DRY RUN] Would send OSC message: %s %v", address, args)
+		return q.mockDryRunResponse(address, ""), nil
+	}
+	if tx := q.activeTransaction(); tx != nil {
+		op := tx.beginOp(address, args)
+		reply, err := q.sendWithRetryCtx(ctx, address, "", args)
+		tx.endOp(op, reply)
+		return reply, err
+	}
+	return q.sendWithRetryCtx(ctx, address, "", args)
+}
 
-		// Try to start the server
-		started := make(chan error, 1)
-		go func() {
-			started <- server.ListenAndServe()
-		}()
+func (q *Workspace) ListenForReply(address string, reply chan []any, requestID int) {
+	replyAddress := q.addressBuilder.BuildReplyAddress(address)
 
-		// Give the server a moment to start and check for port conflicts
-		select {
-		case err := <-started:
-			if err != nil && strings.Contains(err.Error(), "bind: address already in use") {
-				log.Debugf("Port %d in use, trying next port", replyPort)
-				localServer = nil
-				continue // Try next port
-			} else if err != nil && !strings.Contains(err.Error(), "use of closed network connection") {
-				log.Errorf("Reply server error on %s: %v", reply_host, err)
-				localServer = nil
-				continue
-			}
-			// If we get here, server started successfully or closed normally
-			return
-		case <-time.After(100 * time.Millisecond):
-			// Server started without immediate error
-			log.Debugf("Reply server started successfully on %s", reply_host)
+	// Replies arrive on the TCP connection, or on the UDP listener started by the first message
+	if !q.useTCP {
+		if err := q.startReplyListener(); err != nil {
+			q.log().Errorf("Failed to start reply listener: %v", err)
 			return
 		}
 	}
 
-	log.Errorf("Failed to start reply server after %d attempts", maxRetries)
+	q.log().Debugf("Registering reply handler for: %s (requestID: %d)", replyAddress, requestID)
+	q.replyHandlersMux.Lock()
+	q.replyHandlers[replyAddress] = append(q.replyHandlers[replyAddress], pendingReply{requestID: requestID, reply: reply})
+	q.replyHandlersMux.Unlock()
 }
@@ -0,0 +1,119 @@
+package qlab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hypebeast/go-osc/osc"
+)
+
+// DefaultMaxUDPPayload is the largest OSC packet sent over UDP by default.
+// macOS drops or truncates datagrams above net.inet.udp.maxdgram (9216 bytes),
+// which would otherwise silently corrupt long text cue bodies and scripts.
+const DefaultMaxUDPPayload = 9216
+
+// ErrPayloadTooLarge is returned when an OSC packet sent over UDP exceeds the payload limit
+var ErrPayloadTooLarge = errors.New("OSC payload too large for UDP")
+
+// streamTransport carries packets over a connection, e.g. OSC over TCP for workspaces
+// created with NewWorkspaceTCP
+type streamTransport interface {
+	Send(packet osc.Packet) error
+	Close() error
+}
+
+// SetMaxUDPPayload sets the largest OSC packet, in bytes, that is sent over UDP.
+// Larger packets fail with ErrPayloadTooLarge unless SetTCPFallback is on; workspaces
+// created with NewWorkspaceTCP have no limit. Zero restores DefaultMaxUDPPayload.
+func (q *Workspace) SetMaxUDPPayload(bytes int) {
+	if bytes < 0 {
+		bytes = 0
+	}
+	q.maxUDPPayload = bytes
+}
+
+// SetTCPFallback sets whether a UDP workspace sends packets over the UDP limit over TCP
+// instead of failing with ErrPayloadTooLarge. The TCP connection to QLab's OSC port is
+// dialed for the first such packet and kept for later ones; other packets still go over
+// UDP. It is off by default.
+func (q *Workspace) SetTCPFallback(enabled bool) {
+	q.tcpFallback = enabled
+}
+
+// udpPayloadLimit returns the configured UDP payload limit
+func (q *Workspace) udpPayloadLimit() int {
+	if q.maxUDPPayload > 0 {
+		return q.maxUDPPayload
+	}
+	return DefaultMaxUDPPayload
+}
+
+// checkPayloadSize returns an error wrapping ErrPayloadTooLarge if the packet cannot be sent
+func (q *Workspace) checkPayloadSize(address string, packet osc.Packet) error {
+	data, err := packet.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to encode OSC packet for %s: %w", address, err)
+	}
+	if limit := q.udpPayloadLimit(); len(data) > limit && !q.useTCP && !q.tcpFallback {
+		return fmt.Errorf("%w: %s is %d bytes, limit is %d", ErrPayloadTooLarge, address, len(data), limit)
+	}
+	return nil
+}
+
+// sendPacket sends a packet over UDP, or over TCP for workspaces created with NewWorkspaceTCP
+// and oversized packets with SetTCPFallback on
+func (q *Workspace) sendPacket(address string, packet osc.Packet) error {
+	return q.sendPacketCtx(context.Background(), address, packet)
+}
+
+// sendPacketCtx sends a packet once the send rate allows, counting it in SendStats
+func (q *Workspace) sendPacketCtx(ctx context.Context, address string, packet osc.Packet) error {
+	if err := q.sendLimits.pace(ctx); err != nil {
+		return err
+	}
+	err := q.transmitPacket(address, packet)
+	q.sendLimits.record(err)
+	if err == nil {
+		q.reportSent(packet)
+	}
+	return err
+}
+
+// transmitPacket hands a packet to the transport that can carry it
+func (q *Workspace) transmitPacket(address string, packet osc.Packet) error {
+	if q.useTCP {
+		return q.sendStream(packet)
+	}
+
+	data, err := packet.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to encode OSC packet for %s: %w", address, err)
+	}
+
+	if limit := q.udpPayloadLimit(); len(data) > limit {
+		if q.tcpFallback {
+			q.log().Debugf("Sending %s over TCP: %d bytes is over the UDP limit of %d", address, len(data), limit)
+			return q.sendStream(packet)
+		}
+		return fmt.Errorf("%w: %s is %d bytes, limit is %d", ErrPayloadTooLarge, address, len(data), limit)
+	}
+	if conn := q.sourcePortConn(); conn != nil {
+		return q.sendFrom(conn, data)
+	}
+	return q.client.Send(packet)
+}
+
+// sendStream sends a packet over the TCP transport, connecting it first if needed
+func (q *Workspace) sendStream(packet osc.Packet) error {
+	stream, err := q.connectStream()
+	if err != nil {
+		return err
+	}
+	return stream.Send(packet)
+}
+
+// payloadTooLargeReply builds the error reply returned by the legacy Send APIs for oversized packets
+func payloadTooLargeReply(err error) []any {
+	return []any{fmt.Sprintf(`{"status": "error", "error": %q}`, err.Error())}
+}
@@ -0,0 +1,219 @@
+package qlab
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/hypebeast/go-osc/osc"
+	"github.com/zenibako/qlab-golang/messages"
+)
+
+// OSC over TCP. QLab accepts OSC on the same port over TCP, framing each packet with
+// SLIP (OSC 1.1), and replies on the same connection. Large replies such as /cueLists on
+// big workspaces arrive intact instead of being dropped at the UDP datagram limit.
+
+// SLIP framing bytes (RFC 1055)
+const (
+	slipEnd    byte = 0xC0
+	slipEsc    byte = 0xDB
+	slipEscEnd byte = 0xDC
+	slipEscEsc byte = 0xDD
+)
+
+// NewWorkspaceTCP creates a workspace that sends and receives all OSC over a TCP
+// connection to QLab instead of UDP. The connection is made on first use.
+func NewWorkspaceTCP(host string, port int) Workspace {
+	return Workspace{
+		initialized:    false,
+		host:           host,
+		port:           port,
+		client:         osc.NewClient(host, port),
+		addressBuilder: messages.NewOSCAddressBuilder(""),
+		cueNumbers:     make(map[string]string),
+		cueListNames:   make(map[string]string),
+		replyHandlers:  make(map[string][]pendingReply),
+		timeout:        10,
+		useTCP:         true,
+	}
+}
+
+// slipEncode frames a packet, with an END byte on both sides so that any line noise
+// before the packet is discarded as an empty or invalid frame
+func slipEncode(data []byte) []byte {
+	framed := make([]byte, 0, len(data)+2)
+	framed = append(framed, slipEnd)
+	for _, b := range data {
+		switch b {
+		case slipEnd:
+			framed = append(framed, slipEsc, slipEscEnd)
+		case slipEsc:
+			framed = append(framed, slipEsc, slipEscEsc)
+		default:
+			framed = append(framed, b)
+		}
+	}
+	return append(framed, slipEnd)
+}
+
+// readSLIPFrame reads the next non-empty SLIP frame
+func readSLIPFrame(r *bufio.Reader) ([]byte, error) {
+	var frame []byte
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		switch b {
+		case slipEnd:
+			if len(frame) > 0 {
+				return frame, nil
+			}
+		case slipEsc:
+			next, err := r.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			switch next {
+			case slipEscEnd:
+				frame = append(frame, slipEnd)
+			case slipEscEsc:
+				frame = append(frame, slipEsc)
+			default:
+				return nil, fmt.Errorf("invalid SLIP escape 0x%02X", next)
+			}
+		default:
+			frame = append(frame, b)
+		}
+	}
+}
+
+// tcpTransport sends SLIP-framed OSC packets over a TCP connection and dispatches the
+// packets received on it
+type tcpTransport struct {
+	conn    net.Conn
+	writeMu sync.Mutex
+	closed  chan struct{}
+	once    sync.Once
+	logger  Logger
+}
+
+// dialTCPTransport connects to host:port and starts dispatching received packets;
+// onLost is called if the connection ends without Close being called
+func dialTCPTransport(host string, port int, timeout time.Duration, dispatcher osc.Dispatcher, logger Logger, onLost func(error)) (*tcpTransport, error) {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, fmt.Sprint(port)), timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to QLab over TCP: %w", err)
+	}
+	t := &tcpTransport{conn: conn, closed: make(chan struct{}), logger: logger}
+	go t.readLoop(dispatcher, onLost)
+	return t, nil
+}
+
+// Send writes a packet to the connection
+func (t *tcpTransport) Send(packet osc.Packet) error {
+	data, err := packet.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	_, err = t.conn.Write(slipEncode(data))
+	return err
+}
+
+// Close closes the connection
+func (t *tcpTransport) Close() error {
+	var err error
+	t.once.Do(func() {
+		close(t.closed)
+		err = t.conn.Close()
+	})
+	return err
+}
+
+// readLoop decodes and dispatches packets until the connection ends
+func (t *tcpTransport) readLoop(dispatcher osc.Dispatcher, onLost func(error)) {
+	reader := bufio.NewReader(t.conn)
+	for {
+		frame, err := readSLIPFrame(reader)
+		if err != nil {
+			select {
+			case <-t.closed:
+				return
+			default:
+			}
+			if errors.Is(err, io.EOF) {
+				err = errors.New("QLab closed the TCP connection")
+			}
+			t.logger.Warnf("OSC TCP connection lost: %v", err)
+			_ = t.Close()
+			if onLost != nil {
+				onLost(err)
+			}
+			return
+		}
+
+		packet, err := osc.ParsePacket(string(frame))
+		if err != nil {
+			t.logger.Warnf("Ignoring malformed OSC packet received over TCP: %v", err)
+			continue
+		}
+		dispatcher.Dispatch(packet)
+	}
+}
+
+// connectStream returns the TCP transport, connecting it if it isn't connected yet
+func (q *Workspace) connectStream() (streamTransport, error) {
+	q.serverMux.Lock()
+	defer q.serverMux.Unlock()
+	if q.stream != nil {
+		return q.stream, nil
+	}
+
+	d := osc.NewStandardDispatcher()
+	_ = d.AddMsgHandler("*", q.handleIncomingMessage)
+
+	timeout := time.Duration(q.timeout) * time.Second
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	// The loss callback takes serverMux, so it can't observe transport before it's assigned
+	var transport *tcpTransport
+	transport, err := dialTCPTransport(q.host, q.port, timeout, &timetagDispatcher{dispatcher: d, clock: &q.qlabClock}, q.log(), func(err error) {
+		q.serverMux.Lock()
+		if q.stream == transport {
+			q.stream = nil
+		}
+		q.serverMux.Unlock()
+		// A UDP workspace only loses its fallback connection, which is dialed again when needed
+		if q.useTCP && q.wasConnected.Load() {
+			q.notifyDisconnect(DisconnectNetworkUnreachable, "", err)
+			q.wasConnected.Store(false)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	q.stream = transport
+	q.log().Infof("Connected to QLab over TCP at %s:%d", q.host, q.port)
+	return transport, nil
+}
+
+// closeStreamLocked closes the TCP transport if one is connected; q.serverMux must be held,
+// as connectStream holds it while dialing
+func (q *Workspace) closeStreamLocked() error {
+	if q.stream == nil {
+		return nil
+	}
+	err := q.stream.Close()
+	if err != nil {
+		q.log().Debugf("Failed to close OSC TCP connection: %v", err)
+	}
+	q.stream = nil
+	return err
+}
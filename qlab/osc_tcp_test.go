@@ -0,0 +1,143 @@
+package qlab
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestSLIPFraming tests that frames containing the END and ESC bytes survive a round trip
+func TestSLIPFraming(t *testing.T) {
+	first := []byte{0x2F, slipEnd, 0x00, slipEsc, 0x01}
+	second := []byte("/go\x00")
+
+	var stream bytes.Buffer
+	stream.Write(slipEncode(first))
+	stream.Write(slipEncode(second))
+	if bytes.Contains(slipEncode(first)[1:len(slipEncode(first))-1], []byte{slipEnd}) {
+		t.Fatal("Expected END bytes inside a frame to be escaped")
+	}
+
+	reader := bufio.NewReader(&stream)
+	for _, want := range [][]byte{first, second} {
+		got, err := readSLIPFrame(reader)
+		if err != nil {
+			t.Fatalf("readSLIPFrame failed: %v", err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("Expected frame %v, got %v", want, got)
+		}
+	}
+
+	if _, err := readSLIPFrame(bufio.NewReader(bytes.NewReader([]byte{slipEnd, slipEsc, 0x01, slipEnd}))); err == nil {
+		t.Error("Expected error for an invalid escape sequence")
+	}
+}
+
+// TestWorkspaceTCP tests connecting, sending oversized packets and receiving updates over TCP
+func TestWorkspaceTCP(t *testing.T) {
+	port, err := getFreePort()
+	if err != nil {
+		t.Fatalf("Failed to get free port: %v", err)
+	}
+	mockServer := NewMockOSCServer("localhost", port)
+	if err := mockServer.Start(); err != nil {
+		t.Fatalf("Failed to start mock server: %v", err)
+	}
+	w := NewWorkspaceTCP("localhost", port)
+	workspace := &w
+	t.Cleanup(func() {
+		workspace.Close()
+		_ = mockServer.Stop()
+		time.Sleep(150 * time.Millisecond)
+	})
+	workspace.SetTimeout(2)
+
+	if _, err := workspace.Init(""); err != nil {
+		t.Fatalf("Init over TCP failed: %v", err)
+	}
+	if workspace.WorkspaceID() != mockServer.GetWorkspaceID() {
+		t.Fatalf("Expected workspace ID %s, got %s", mockServer.GetWorkspaceID(), workspace.WorkspaceID())
+	}
+
+	// The UDP payload limit doesn't apply over TCP
+	workspace.SetMaxUDPPayload(64)
+	name := strings.Repeat("Long cue name ", 10)
+	cueID, err := workspace.createCueWithoutTarget(map[string]any{"type": "memo", "name": name}, "1")
+	if err != nil {
+		t.Fatalf("createCueWithoutTarget over TCP failed: %v", err)
+	}
+	if cue := mockServer.GetCue(cueID); cue == nil || cue.Name != name {
+		t.Errorf("Expected cue named %q, got %+v", name, cue)
+	}
+	if mockServer.TCPMessageCount() == 0 {
+		t.Error("Expected messages to arrive over TCP")
+	}
+
+	// Batches pipeline over TCP, which routes replies without the UDP listener
+	workspace.SetBatchWindow(4)
+	address := workspace.addressBuilder.BuildCuePropertyAddress(cueID, "name")
+	queue := []queuedMessage{{cueID: cueID, address: address}, {cueID: cueID, address: address}, {cueID: cueID, address: address}}
+	pipelined := 0
+	workspace.pipelineMessages(queue, func(m queuedMessage, reply []any, err error) {
+		if err != nil || checkReplyStatus(reply) != nil {
+			t.Errorf("Batched query over TCP failed: %v %v", err, reply)
+		}
+		if workspace.SendStats().InFlight > 0 {
+			pipelined++
+		}
+	})
+	if pipelined == 0 {
+		t.Error("Expected the batch pipelined over TCP")
+	}
+
+	var mu sync.Mutex
+	var updates []string
+	if err := workspace.StartUpdateListener(func(address string, args []any) {
+		mu.Lock()
+		defer mu.Unlock()
+		updates = append(updates, address)
+	}); err != nil {
+		t.Fatalf("StartUpdateListener failed: %v", err)
+	}
+	update := "/update/workspace/" + mockServer.GetWorkspaceID() + "/cue_id/" + cueID
+	if err := mockServer.SendUpdate(update); err != nil {
+		t.Fatalf("SendUpdate failed: %v", err)
+	}
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(updates) == 1 && updates[0] == update
+	})
+
+	// A dropped connection is reported and re-established by the next message
+	lost := make(chan DisconnectEvent, 1)
+	workspace.OnDisconnectEvent(func(event DisconnectEvent) { lost <- event })
+	mockServer.DropTCPConnections()
+	select {
+	case event := <-lost:
+		if event.Reason != DisconnectNetworkUnreachable {
+			t.Errorf("Expected network unreachable, got %s", event.Reason)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a disconnect notification when the TCP connection dropped")
+	}
+	if _, err := workspace.queryVersion(); err != nil {
+		t.Errorf("Expected the next message to reconnect, got %v", err)
+	}
+}
+
+// waitFor polls until cond is true or a second has passed
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
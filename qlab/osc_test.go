@@ -1013,7 +1013,8 @@ func TestCuejitsuInboxCreation(t *testing.T) {
 	t.Log("Cuejitsu Inbox creation test completed successfully")
 }
 
-// TestWorkspaceInitWithInbox tests that workspace initialization includes inbox creation
+// TestWorkspaceInitWithInbox tests that workspace initialization leaves inbox creation to
+// the first import
 func TestWorkspaceInitWithInbox(t *testing.T) {
 	// Get an available port from the OS
 	port, err := getFreePort()
@@ -1050,12 +1051,16 @@ func TestWorkspaceInitWithInbox(t *testing.T) {
 		t.Error("Workspace should be initialized")
 	}
 
-	// Verify inbox was created during initialization
+	// The inbox is created by the first import, not by initialization
+	if workspace.inboxID != "" {
+		t.Errorf("Expected no inbox created during workspace initialization, got %s", workspace.inboxID)
+	}
+	workspace.ensureInbox()
 	if workspace.inboxID == "" {
-		t.Error("Expected inboxID to be set during workspace initialization")
+		t.Error("Expected inboxID to be set once an import needs the inbox")
 	}
 
-	t.Logf("Workspace initialized with inbox ID: %s", workspace.inboxID)
+	t.Logf("Workspace created inbox ID: %s", workspace.inboxID)
 
 	// Verify the mock server shows the cue list was created
 	t.Logf("Mock server state after initialization: %d cues", mockServer.GetCueCount())
@@ -1133,9 +1138,13 @@ func TestFullWorkspaceInitWithInbox(t *testing.T) {
 		t.Error("workspace_id should be set after initialization")
 	}
 
-	// Most importantly - verify inbox was created during initialization
+	// The inbox is created by the first import, not by initialization
+	if workspace.inboxID != "" {
+		t.Errorf("Expected no inbox created during workspace initialization, got %s", workspace.inboxID)
+	}
+	workspace.ensureInbox()
 	if workspace.inboxID == "" {
-		t.Error("Expected inboxID to be set during workspace initialization")
+		t.Error("Expected inboxID to be set once an import needs the inbox")
 	}
 
 	t.Logf("Workspace fully initialized with inbox ID: %s", workspace.inboxID)
@@ -1373,9 +1382,9 @@ func TestEmptyPasscodeConnection(t *testing.T) {
 		t.Error("workspace_id should be set after initialization")
 	}
 
-	// Verify inbox was created
-	if workspace.inboxID == "" {
-		t.Error("Expected inboxID to be set during workspace initialization")
+	// The inbox is created by the first import, not by initialization
+	if workspace.inboxID != "" {
+		t.Errorf("Expected no inbox created during workspace initialization, got %s", workspace.inboxID)
 	}
 
 	// Test creating a cue to verify workspace is fully functional
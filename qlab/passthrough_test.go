@@ -0,0 +1,91 @@
+package qlab
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// TestCueJSONPreservesUnknownProperties tests that unrecognized keys survive a JSON round trip
+func TestCueJSONPreservesUnknownProperties(t *testing.T) {
+	input := `{"type":"audio","name":"Music","x-stageManager":{"call":"Standby"},"cues":[{"type":"memo","owner":"LX"}]}`
+
+	var cue Cue
+	if err := json.Unmarshal([]byte(input), &cue); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if cue.Name != "Music" || cue.Extra["x-stageManager"] == nil {
+		t.Fatalf("Expected known fields and extras, got %+v", cue)
+	}
+	if _, ok := cue.Extra["name"]; ok {
+		t.Error("Known properties must not be collected into Extra")
+	}
+	if len(cue.Cues) != 1 || cue.Cues[0].Extra["owner"] != "LX" {
+		t.Errorf("Expected child extras, got %+v", cue.Cues)
+	}
+
+	output, err := json.Marshal(cue)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var roundTrip map[string]any
+	if err := json.Unmarshal(output, &roundTrip); err != nil {
+		t.Fatalf("Unmarshal of output failed: %v", err)
+	}
+	if call, _ := roundTrip["x-stageManager"].(map[string]any); call["call"] != "Standby" {
+		t.Errorf("Expected x-stageManager to survive, got %s", output)
+	}
+
+	cueFile := WriteCueFile("Show", []Cue{cue}, "")
+	if !strings.Contains(cueFile, `"x-stageManager": {"call":"Standby"}`) || !strings.Contains(cueFile, `owner: "LX"`) {
+		t.Errorf("Expected extras in CUE output, got:\n%s", cueFile)
+	}
+}
+
+// TestScopeComparisonIgnoresUnknownProperties tests that unknown properties are excluded from diffing unless enabled
+func TestScopeComparisonIgnoresUnknownProperties(t *testing.T) {
+	workspace := &Workspace{}
+	source := map[string]any{"cues": []any{
+		map[string]any{"type": "audio", "number": "1", "name": "Music", "x-owner": "Sound"},
+	}}
+	current := map[string]any{"cues": []any{
+		map[string]any{"type": "audio", "number": "1", "name": "Music"},
+	}}
+
+	scope, err := workspace.PerformScopeBasedComparison(source, nil, current)
+	if err != nil {
+		t.Fatalf("PerformScopeBasedComparison failed: %v", err)
+	}
+	if scope.HasChanges {
+		t.Errorf("Expected no changes with unknown properties ignored, got %+v", scope.ChildScopes[0].FieldChanges)
+	}
+
+	workspace.SetCompareUnknownProperties(true)
+	scope, err = workspace.PerformScopeBasedComparison(source, nil, current)
+	if err != nil {
+		t.Fatalf("PerformScopeBasedComparison failed: %v", err)
+	}
+	if _, ok := scope.ChildScopes[0].FieldChanges["x-owner"]; !ok {
+		t.Error("Expected x-owner to be diffed when enabled")
+	}
+}
+
+// TestCarryUnknownPropertiesIntoSnapshot tests that source metadata is kept in cache snapshots
+func TestCarryUnknownPropertiesIntoSnapshot(t *testing.T) {
+	workspace := &Workspace{}
+	source := map[string]any{"cues": []any{
+		map[string]any{"type": "audio", "number": "1", "name": "Music", "x-owner": "Sound"},
+	}}
+	snapshot := map[string]any{"data": []any{
+		map[string]any{"uniqueID": "list", "cues": []any{
+			map[string]any{"uniqueID": "A", "type": "Audio", "number": "1", "name": "Music"},
+		}},
+	}}
+
+	workspace.carryUnknownProperties(source, snapshot)
+
+	cue := workspace.indexCuesFromWorkspace(snapshot)["1"]
+	if cue["x-owner"] != "Sound" {
+		t.Errorf("Expected x-owner carried into snapshot, got %v", cue)
+	}
+}
@@ -0,0 +1,137 @@
+package qlab
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hypebeast/go-osc/osc"
+	"github.com/zenibako/qlab-golang/messages"
+)
+
+// fakeStream records packets sent over the stream transport
+type fakeStream struct {
+	packets []osc.Packet
+}
+
+func (f *fakeStream) Send(packet osc.Packet) error {
+	f.packets = append(f.packets, packet)
+	return nil
+}
+
+func (f *fakeStream) Close() error {
+	return nil
+}
+
+func newPayloadTestWorkspace() *Workspace {
+	return &Workspace{
+		workspace_id:   "test-workspace",
+		addressBuilder: messages.NewOSCAddressBuilder("test-workspace"),
+		cueNumbers:     make(map[string]string),
+		replyHandlers:  make(map[string][]pendingReply),
+	}
+}
+
+// TestSetCuePropertyPayloadTooLarge tests that oversized property values fail before anything is sent
+func TestSetCuePropertyPayloadTooLarge(t *testing.T) {
+	workspace := newPayloadTestWorkspace()
+	longText := strings.Repeat("x", DefaultMaxUDPPayload)
+
+	err := workspace.setCueProperty("cue-1", "text", longText)
+	if !errors.Is(err, ErrPayloadTooLarge) {
+		t.Fatalf("Expected ErrPayloadTooLarge, got %v", err)
+	}
+
+	err = workspace.setCuePropertyWithArgs("cue-1", "text", longText)
+	if !errors.Is(err, ErrPayloadTooLarge) {
+		t.Fatalf("Expected ErrPayloadTooLarge with args, got %v", err)
+	}
+}
+
+// TestSendPayloadTooLarge tests the typed error from SendCtx and the error reply from Send
+func TestSendPayloadTooLarge(t *testing.T) {
+	workspace := newPayloadTestWorkspace()
+	workspace.SetMaxUDPPayload(64)
+	longText := strings.Repeat("x", 128)
+
+	_, err := workspace.SendCtx(context.Background(), "/workspace/test-workspace/cue_id/cue-1/notes", longText)
+	if !errors.Is(err, ErrPayloadTooLarge) {
+		t.Fatalf("Expected ErrPayloadTooLarge from SendCtx, got %v", err)
+	}
+
+	reply := workspace.Send("/workspace/test-workspace/cue_id/cue-1/notes", longText)
+	if checkReplyStatus(reply) == nil {
+		t.Errorf("Expected error reply from Send, got %v", reply)
+	}
+}
+
+// TestSendPacketUsesStreamTransport tests that TCP workspaces send packets over the UDP
+// limit over the stream transport
+func TestSendPacketUsesStreamTransport(t *testing.T) {
+	workspace := newPayloadTestWorkspace()
+	workspace.SetMaxUDPPayload(64)
+	workspace.useTCP = true
+	stream := &fakeStream{}
+	workspace.stream = stream
+
+	address := "/workspace/test-workspace/cue_id/cue-1/text"
+	msg := osc.NewMessage(address, strings.Repeat("x", 128))
+	if err := workspace.checkPayloadSize(address, msg); err != nil {
+		t.Fatalf("Expected no error over TCP, got %v", err)
+	}
+	if err := workspace.sendPacket(address, msg); err != nil {
+		t.Fatalf("sendPacket failed: %v", err)
+	}
+	if len(stream.packets) != 1 {
+		t.Errorf("Expected 1 packet over stream transport, got %d", len(stream.packets))
+	}
+}
+
+// TestSetMaxUDPPayload tests the payload limit setter and default
+func TestSetMaxUDPPayload(t *testing.T) {
+	workspace := &Workspace{}
+	if got := workspace.udpPayloadLimit(); got != DefaultMaxUDPPayload {
+		t.Errorf("Expected default limit %d, got %d", DefaultMaxUDPPayload, got)
+	}
+	workspace.SetMaxUDPPayload(1024)
+	if got := workspace.udpPayloadLimit(); got != 1024 {
+		t.Errorf("Expected limit 1024, got %d", got)
+	}
+	workspace.SetMaxUDPPayload(-5)
+	if got := workspace.udpPayloadLimit(); got != DefaultMaxUDPPayload {
+		t.Errorf("Expected default limit after negative value, got %d", got)
+	}
+}
+
+// TestTCPFallbackSendsOversizedPackets tests that a UDP workspace with the TCP fallback on
+// sends a packet over the UDP limit over a TCP connection dialed for it
+func TestTCPFallbackSendsOversizedPackets(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(2)
+	workspace.SetMaxUDPPayload(128)
+
+	cueID, err := workspace.createCueWithoutTarget(map[string]any{"type": "memo"}, "")
+	if err != nil {
+		t.Fatalf("createCueWithoutTarget failed: %v", err)
+	}
+	if mockServer.TCPMessageCount() != 0 {
+		t.Fatal("Expected packets within the limit to go over UDP")
+	}
+
+	name := strings.Repeat("Long cue name ", 20)
+	if err := workspace.setCueProperty(cueID, "name", name); !errors.Is(err, ErrPayloadTooLarge) {
+		t.Fatalf("Expected ErrPayloadTooLarge without the fallback, got %v", err)
+	}
+
+	workspace.SetTCPFallback(true)
+	if err := workspace.setCueProperty(cueID, "name", name); err != nil {
+		t.Fatalf("setCueProperty with the TCP fallback failed: %v", err)
+	}
+	if cue := mockServer.GetCue(cueID); cue == nil || cue.Name != name {
+		t.Errorf("Expected cue named %q, got %+v", name, cue)
+	}
+	if mockServer.TCPMessageCount() != 1 {
+		t.Errorf("Expected the oversized packet over TCP, got %d TCP messages", mockServer.TCPMessageCount())
+	}
+}
@@ -0,0 +1,118 @@
+package qlab
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Permissions are what the passcode a workspace connected with allows, as QLab reports
+// them in its /connect reply
+type Permissions struct {
+	View    bool // Query the workspace
+	Edit    bool // Create, change, move and delete cues
+	Control bool // Playback commands, selection and playheads
+}
+
+// String formats permissions the way QLab reports them, e.g. "view|edit|control"
+func (p Permissions) String() string {
+	var granted []string
+	for _, permission := range []struct {
+		name    string
+		granted bool
+	}{{"view", p.View}, {"edit", p.Edit}, {"control", p.Control}} {
+		if permission.granted {
+			granted = append(granted, permission.name)
+		}
+	}
+	return strings.Join(granted, "|")
+}
+
+// ParsePermissions parses the data of a successful /connect reply, e.g.
+// "ok:view|edit|control". A plain "ok", as replied by QLab versions without permissions,
+// grants everything.
+func ParsePermissions(data string) (Permissions, error) {
+	if data == "ok" {
+		return Permissions{View: true, Edit: true, Control: true}, nil
+	}
+	list, ok := strings.CutPrefix(data, "ok:")
+	if !ok {
+		return Permissions{}, fmt.Errorf("unexpected connect reply data %q", data)
+	}
+	var p Permissions
+	for _, permission := range strings.Split(list, "|") {
+		switch permission {
+		case "view":
+			p.View = true
+		case "edit":
+			p.Edit = true
+		case "control":
+			p.Control = true
+		}
+	}
+	return p, nil
+}
+
+// PermissionError is returned when the passcode a workspace connected with doesn't allow
+// an operation. It unwraps to ErrInsufficientPermissions.
+type PermissionError struct {
+	Operation string // The refused operation, or the address of the refused message
+	Required  string // "edit" or "control"
+	Granted   Permissions
+}
+
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("%v: %s needs %s permission, passcode allows %q", ErrInsufficientPermissions, e.Operation, e.Required, e.Granted)
+}
+
+// Unwrap returns ErrInsufficientPermissions
+func (e *PermissionError) Unwrap() error {
+	return ErrInsufficientPermissions
+}
+
+// Permissions returns what the passcode the workspace connected with allows. ok is false
+// before Init, or when QLab's reply didn't say; nothing is refused for lack of permission
+// then.
+func (q *Workspace) Permissions() (permissions Permissions, ok bool) {
+	q.stateMu.Lock()
+	defer q.stateMu.Unlock()
+	if q.permissions == nil {
+		return Permissions{}, false
+	}
+	return *q.permissions, true
+}
+
+// setPermissions records the permissions from a /connect reply's data
+func (q *Workspace) setPermissions(data string) {
+	permissions, err := ParsePermissions(data)
+	q.stateMu.Lock()
+	defer q.stateMu.Unlock()
+	if err != nil {
+		q.log().Debugf("No permissions in connect reply: %v", err)
+		q.permissions = nil
+		return
+	}
+	q.permissions = &permissions
+}
+
+// checkPermission returns a *PermissionError if the workspace's permissions are known and
+// don't include required
+func (q *Workspace) checkPermission(operation, required string) error {
+	granted, ok := q.Permissions()
+	if !ok {
+		return nil
+	}
+	if (required == "edit" && !granted.Edit) || (required == "control" && !granted.Control) {
+		return &PermissionError{Operation: operation, Required: required, Granted: granted}
+	}
+	return nil
+}
+
+// requiredPermission returns the permission a message that changes QLab needs
+func requiredPermission(address string) string {
+	parts := strings.Split(strings.Trim(address, "/"), "/")
+	if slices.Contains(controlCommands, parts[len(parts)-1]) || slices.Contains(parts, "select_id") || slices.Contains([]string{"playhead", "playheadId"}, parts[len(parts)-1]) {
+		return "control"
+	}
+	return "edit"
+}
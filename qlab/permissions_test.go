@@ -0,0 +1,89 @@
+package qlab
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// TestParsePermissions tests parsing the permissions in /connect replies
+func TestParsePermissions(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected Permissions
+		wantErr  bool
+	}{
+		{"ok:view|edit|control", Permissions{View: true, Edit: true, Control: true}, false},
+		{"ok:view", Permissions{View: true}, false},
+		{"ok:view|control", Permissions{View: true, Control: true}, false},
+		{"ok", Permissions{View: true, Edit: true, Control: true}, false},
+		{"badpass", Permissions{}, true},
+	}
+	for _, tt := range tests {
+		permissions, err := ParsePermissions(tt.data)
+		if (err != nil) != tt.wantErr || permissions != tt.expected {
+			t.Errorf("ParsePermissions(%q) = %+v, %v, want %+v", tt.data, permissions, err, tt.expected)
+		}
+	}
+	if s := (Permissions{View: true, Control: true}).String(); s != "view|control" {
+		t.Errorf("Expected view|control, got %q", s)
+	}
+}
+
+// TestPermissionsPreflight tests that changes the passcode doesn't allow are refused before
+// they are sent
+func TestPermissionsPreflight(t *testing.T) {
+	port, err := getFreePort()
+	if err != nil {
+		t.Fatalf("Failed to get free port: %v", err)
+	}
+	mockServer := NewMockOSCServer("localhost", port)
+	if err := mockServer.Start(); err != nil {
+		t.Fatalf("Failed to start mock server: %v", err)
+	}
+	workspace := NewWorkspace("localhost", port)
+	t.Cleanup(func() {
+		workspace.Close()
+		if err := mockServer.Stop(); err != nil {
+			t.Logf("Failed to stop mock server: %v", err)
+		}
+	})
+
+	if _, ok := workspace.Permissions(); ok {
+		t.Error("Expected no permissions before Init")
+	}
+	mockServer.SetPermissions("view|control")
+	if _, err := workspace.Init(""); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	permissions, ok := workspace.Permissions()
+	if !ok || permissions != (Permissions{View: true, Control: true}) {
+		t.Fatalf("Expected view and control permissions, got %+v, %v", permissions, ok)
+	}
+
+	_, err = workspace.TransmitWorkspaceData(filepath.Join(t.TempDir(), "show.json"), map[string]any{"cues": []any{
+		map[string]any{"type": "memo", "number": "1", "name": "Refused"},
+	}})
+	var permissionErr *PermissionError
+	if !errors.As(err, &permissionErr) || permissionErr.Required != "edit" {
+		t.Errorf("Expected the transmit refused for lack of edit permission, got %v", err)
+	}
+	if _, err := workspace.createCue(map[string]any{"type": "memo"}, ""); !errors.Is(err, ErrInsufficientPermissions) {
+		t.Errorf("Expected cue creation refused with ErrInsufficientPermissions, got %v", err)
+	}
+	if count := mockServer.GetCueCount(); count != 0 {
+		t.Errorf("Expected no cues created without edit permission, got %d", count)
+	}
+	if err := workspace.Go(); err != nil {
+		t.Errorf("Expected GO allowed with control permission, got %v", err)
+	}
+
+	// A view-only passcode can't control playback either
+	workspace.setPermissions("ok:view")
+	if err := workspace.Go(); !errors.Is(err, ErrInsufficientPermissions) {
+		t.Errorf("Expected GO refused with ErrInsufficientPermissions, got %v", err)
+	}
+	if _, err := workspace.FindCues(func(*Cue) bool { return true }); err != nil {
+		t.Errorf("Expected queries allowed with view permission, got %v", err)
+	}
+}
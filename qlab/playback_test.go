@@ -0,0 +1,158 @@
+package qlab
+
+import (
+	"testing"
+	"time"
+)
+
+// TestCuePlayback tests that start, pause, resume, and stop are sent to a single cue
+func TestCuePlayback(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+
+	cueID, err := workspace.createCueWithoutTarget(map[string]any{"type": "memo", "name": "Music"}, "1")
+	if err != nil {
+		t.Fatalf("createCueWithoutTarget failed: %v", err)
+	}
+
+	if err := workspace.Start(cueID); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if !mockServer.IsCueRunning(cueID) {
+		t.Error("Expected cue to be running after Start")
+	}
+
+	if err := workspace.Pause(cueID); err != nil {
+		t.Fatalf("Pause failed: %v", err)
+	}
+	if !mockServer.IsCuePaused(cueID) {
+		t.Error("Expected cue to be paused after Pause")
+	}
+
+	if err := workspace.Resume(cueID); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	if mockServer.IsCuePaused(cueID) {
+		t.Error("Expected cue to be resumed after Resume")
+	}
+
+	if err := workspace.Stop(cueID); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if mockServer.IsCueRunning(cueID) {
+		t.Error("Expected cue to be stopped after Stop")
+	}
+	if _, ok := mockServer.GetCue(cueID).Properties["stop"]; ok {
+		t.Error("Expected stop to be handled as a command, not stored as a property")
+	}
+
+	if err := workspace.Stop("no-such-cue"); err == nil {
+		t.Error("Expected error stopping an unknown cue")
+	}
+	if err := workspace.Stop(""); err == nil {
+		t.Error("Expected error stopping without a cue ID")
+	}
+}
+
+// TestWorkspacePlayback tests GO by number and the workspace-wide pause, resume, and panic commands
+func TestWorkspacePlayback(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+
+	first, err := workspace.createCueWithoutTarget(map[string]any{"type": "memo", "name": "First"}, "1")
+	if err != nil {
+		t.Fatalf("createCueWithoutTarget failed: %v", err)
+	}
+	second, err := workspace.createCueWithoutTarget(map[string]any{"type": "memo", "name": "Second"}, "2")
+	if err != nil {
+		t.Fatalf("createCueWithoutTarget failed: %v", err)
+	}
+
+	if err := workspace.GoCue("1"); err != nil {
+		t.Fatalf("GoCue failed: %v", err)
+	}
+	if err := workspace.PreviewCue(second); err != nil {
+		t.Fatalf("PreviewCue failed: %v", err)
+	}
+	if !mockServer.IsCueRunning(first) || !mockServer.IsCueRunning(second) {
+		t.Fatal("Expected both cues to be running")
+	}
+
+	if err := workspace.PauseAll(); err != nil {
+		t.Fatalf("PauseAll failed: %v", err)
+	}
+	if !mockServer.IsCuePaused(first) || !mockServer.IsCuePaused(second) {
+		t.Error("Expected both cues to be paused after PauseAll")
+	}
+	if err := workspace.ResumeAll(); err != nil {
+		t.Fatalf("ResumeAll failed: %v", err)
+	}
+	if mockServer.IsCuePaused(first) || mockServer.IsCuePaused(second) {
+		t.Error("Expected both cues to be resumed after ResumeAll")
+	}
+
+	if err := workspace.Panic(); err != nil {
+		t.Fatalf("Panic failed: %v", err)
+	}
+	if mockServer.IsCueRunning(first) || mockServer.IsCueRunning(second) {
+		t.Error("Expected no cues running after Panic")
+	}
+
+	if err := workspace.GoCue("99"); err == nil {
+		t.Error("Expected error for GO to an unknown cue number")
+	}
+}
+
+// TestRehearsalJumps tests loading cues by number, loading to a time, and going from a cue
+func TestRehearsalJumps(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+
+	first, err := workspace.createCueWithoutTarget(map[string]any{"type": "audio", "name": "Overture"}, "1")
+	if err != nil {
+		t.Fatalf("createCueWithoutTarget failed: %v", err)
+	}
+	second, err := workspace.createCueWithoutTarget(map[string]any{"type": "audio", "name": "Storm"}, "2")
+	if err != nil {
+		t.Fatalf("createCueWithoutTarget failed: %v", err)
+	}
+
+	if err := workspace.LoadCue("1"); err != nil {
+		t.Fatalf("LoadCue failed: %v", err)
+	}
+	if offset, ok := mockServer.LoadedAt(first); !ok || offset != 0 {
+		t.Errorf("Expected cue 1 loaded to its start, got %v (%v)", offset, ok)
+	}
+	if err := workspace.LoadAt("2", 90*time.Second); err != nil {
+		t.Fatalf("LoadAt failed: %v", err)
+	}
+	if offset, ok := mockServer.LoadedAt(second); !ok || offset != 90*time.Second {
+		t.Errorf("Expected cue 2 loaded to 1m30s, got %v (%v)", offset, ok)
+	}
+	if err := workspace.LoadAt("2", -time.Second); err == nil {
+		t.Error("Expected a negative load time to be refused")
+	}
+	if err := workspace.LoadCue("99"); err == nil {
+		t.Error("Expected error loading an unknown cue number")
+	}
+
+	if err := workspace.Start(first); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := workspace.GoFrom("2"); err != nil {
+		t.Fatalf("GoFrom failed: %v", err)
+	}
+	if mockServer.IsCueRunning(first) || !mockServer.IsCueRunning(second) {
+		t.Error("Expected GoFrom to stop cue 1 and start cue 2")
+	}
+
+	if required := requiredPermission(workspace.cueNumberAddress("1", "loadAt")); required != "control" {
+		t.Errorf("Expected loadAt to need the control permission, got %s", required)
+	}
+
+	workspace.SetDryRun(true)
+	mockServer.ClearReceivedMessages()
+	if err := workspace.LoadAt("1", time.Second); err != nil {
+		t.Fatalf("LoadAt failed in dry-run mode: %v", err)
+	}
+	if sent := mockServer.GetMessagesForAddress("/loadAt"); len(sent) != 0 {
+		t.Errorf("Expected nothing sent in dry-run mode, got %d messages", len(sent))
+	}
+}
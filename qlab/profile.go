@@ -0,0 +1,302 @@
+package qlab
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultPort is the port QLab listens on for OSC
+const DefaultPort = 53000
+
+// Transport is how a profile sends OSC to QLab
+type Transport string
+
+const (
+	TransportUDP Transport = "udp" // Default
+	TransportTCP Transport = "tcp"
+)
+
+// ErrPasscodeNotFound is returned by a PasscodeStore that has no passcode for a profile
+var ErrPasscodeNotFound = errors.New("passcode not found")
+
+// ConnectionProfile holds everything needed to connect to a QLab workspace
+type ConnectionProfile struct {
+	Name          string        `json:"name,omitempty"`
+	Host          string        `json:"host"`
+	Port          int           `json:"port,omitempty"`     // 0 uses DefaultPort
+	Passcode      string        `json:"passcode,omitempty"` // Stored in plain text; prefer a PasscodeStore
+	Timeout       int           `json:"timeout,omitempty"`  // Reply timeout in seconds, 0 for the default
+	Retries       int           `json:"retries,omitempty"`
+	Transport     Transport     `json:"transport,omitempty"`     // Empty uses UDP
+	ReadOnly      bool          `json:"readOnly,omitempty"`      // Refuse every change to QLab, see SetReadOnly
+	ReplyPort     int           `json:"replyPort,omitempty"`     // UDP port replies arrive on, see SetReplyPort
+	ReplyStrategy ReplyStrategy `json:"replyStrategy,omitempty"` // Empty negotiates the reply port
+}
+
+// NewWorkspaceFromProfile creates a workspace configured by a profile. Call Init with the
+// profile's passcode to connect, or use ConnectProfile.
+func NewWorkspaceFromProfile(p ConnectionProfile) *Workspace {
+	host := p.Host
+	if host == "" {
+		host = "localhost"
+	}
+	port := p.Port
+	if port == 0 {
+		port = DefaultPort
+	}
+
+	workspace := NewWorkspace(host, port)
+	if p.Transport == TransportTCP {
+		workspace = NewWorkspaceTCP(host, port)
+	}
+	if p.Timeout > 0 {
+		workspace.SetTimeout(p.Timeout)
+	}
+	workspace.SetMaxRetries(p.Retries)
+	workspace.SetReadOnly(p.ReadOnly)
+	workspace.SetReplyPort(p.ReplyPort)
+	workspace.SetReplyStrategy(p.ReplyStrategy)
+	return &workspace
+}
+
+// ConnectProfile creates a workspace from a profile and initializes it. The passcode is the
+// profile's own, or else the one in store under the profile's name; store may be nil.
+func ConnectProfile(p ConnectionProfile, store PasscodeStore) (*Workspace, error) {
+	passcode, err := p.ResolvePasscode(store)
+	if err != nil {
+		return nil, err
+	}
+	workspace := NewWorkspaceFromProfile(p)
+	if _, err := workspace.Init(passcode); err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+
+// ResolvePasscode returns the profile's passcode, looking it up in store when the profile
+// doesn't have one. A passcode missing from the store is empty, as for an unlocked workspace.
+func (p ConnectionProfile) ResolvePasscode(store PasscodeStore) (string, error) {
+	if p.Passcode != "" || store == nil {
+		return p.Passcode, nil
+	}
+	passcode, err := store.Get(p.Name)
+	if errors.Is(err, ErrPasscodeNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read passcode for profile %q: %w", p.Name, err)
+	}
+	return passcode, nil
+}
+
+// Validate checks that the profile can be connected with
+func (p ConnectionProfile) Validate() error {
+	if p.Port < 0 || p.Port > 65535 {
+		return fmt.Errorf("profile %q: invalid port %d", p.Name, p.Port)
+	}
+	if p.Timeout < 0 || p.Retries < 0 {
+		return fmt.Errorf("profile %q: timeout and retries can't be negative", p.Name)
+	}
+	switch p.Transport {
+	case "", TransportUDP, TransportTCP:
+	default:
+		return fmt.Errorf("profile %q: unknown transport %q", p.Name, p.Transport)
+	}
+	return nil
+}
+
+// ProfileConfig is a file of named connection profiles
+type ProfileConfig struct {
+	Default  string                       `json:"default,omitempty"` // Profile used when none is named
+	Profiles map[string]ConnectionProfile `json:"profiles"`
+}
+
+// DefaultProfilePath returns the file LoadProfileConfig reads unless told otherwise
+func DefaultProfilePath() (string, error) {
+	usr, err := user.Current()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current user: %v", err)
+	}
+	return filepath.Join(usr.HomeDir, ".config", "cuejitsu", "profiles.json"), nil
+}
+
+// LoadProfileConfig reads a profile file. A file that doesn't exist is an empty config.
+func LoadProfileConfig(path string) (*ProfileConfig, error) {
+	config := &ProfileConfig{Profiles: make(map[string]ConnectionProfile)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profiles: %w", err)
+	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if config.Profiles == nil {
+		config.Profiles = make(map[string]ConnectionProfile)
+	}
+	for name, profile := range config.Profiles {
+		profile.Name = name
+		if err := profile.Validate(); err != nil {
+			return nil, err
+		}
+		config.Profiles[name] = profile
+	}
+	return config, nil
+}
+
+// Save writes the config, readable only by the current user since it may hold passcodes
+func (c *ProfileConfig) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode profiles: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create profile directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write profiles: %w", err)
+	}
+	return nil
+}
+
+// Profile returns the named profile, or the default profile when name is empty
+func (c *ProfileConfig) Profile(name string) (ConnectionProfile, error) {
+	if name == "" {
+		name = c.Default
+	}
+	if name == "" {
+		return ConnectionProfile{}, errors.New("no profile named and no default profile")
+	}
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return ConnectionProfile{}, fmt.Errorf("no profile named %q", name)
+	}
+	profile.Name = name
+	return profile, nil
+}
+
+// SetProfile adds or replaces a profile under its name
+func (c *ProfileConfig) SetProfile(p ConnectionProfile) error {
+	if p.Name == "" {
+		return errors.New("profile has no name")
+	}
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if c.Profiles == nil {
+		c.Profiles = make(map[string]ConnectionProfile)
+	}
+	c.Profiles[p.Name] = p
+	return nil
+}
+
+// RemoveProfile deletes a profile, and clears the default if it was the default
+func (c *ProfileConfig) RemoveProfile(name string) {
+	delete(c.Profiles, name)
+	if c.Default == name {
+		c.Default = ""
+	}
+}
+
+// Names returns the profile names, sorted
+func (c *ProfileConfig) Names() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PasscodeStore keeps workspace passcodes out of profile files, keyed by profile name
+type PasscodeStore interface {
+	Get(profile string) (string, error) // ErrPasscodeNotFound when there is none
+	Set(profile, passcode string) error
+	Delete(profile string) error
+}
+
+// MemoryPasscodeStore keeps passcodes in memory, for tests and short-lived tools
+type MemoryPasscodeStore struct {
+	mu        sync.Mutex
+	passcodes map[string]string
+}
+
+// NewMemoryPasscodeStore creates an empty in-memory store
+func NewMemoryPasscodeStore() *MemoryPasscodeStore {
+	return &MemoryPasscodeStore{passcodes: make(map[string]string)}
+}
+
+func (s *MemoryPasscodeStore) Get(profile string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	passcode, ok := s.passcodes[profile]
+	if !ok {
+		return "", ErrPasscodeNotFound
+	}
+	return passcode, nil
+}
+
+func (s *MemoryPasscodeStore) Set(profile, passcode string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.passcodes[profile] = passcode
+	return nil
+}
+
+func (s *MemoryPasscodeStore) Delete(profile string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.passcodes, profile)
+	return nil
+}
+
+// KeychainPasscodeStore keeps passcodes in the macOS login keychain as generic passwords
+// under a service name, using the security command
+type KeychainPasscodeStore struct {
+	Service string
+}
+
+// NewKeychainPasscodeStore creates a keychain store for service, e.g. "cuejitsu"
+func NewKeychainPasscodeStore(service string) *KeychainPasscodeStore {
+	return &KeychainPasscodeStore{Service: service}
+}
+
+func (s *KeychainPasscodeStore) Get(profile string) (string, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", s.Service, "-a", profile, "-w").Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", ErrPasscodeNotFound
+		}
+		return "", fmt.Errorf("failed to read keychain: %w", err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func (s *KeychainPasscodeStore) Set(profile, passcode string) error {
+	// -U updates an existing item instead of failing
+	if out, err := exec.Command("security", "add-generic-password", "-U", "-s", s.Service, "-a", profile, "-w", passcode).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to write keychain: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (s *KeychainPasscodeStore) Delete(profile string) error {
+	if _, err := s.Get(profile); errors.Is(err, ErrPasscodeNotFound) {
+		return nil
+	}
+	if out, err := exec.Command("security", "delete-generic-password", "-s", s.Service, "-a", profile).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to delete from keychain: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package qlab
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestProfileConfigRoundTrip tests saving and loading profiles
+func TestProfileConfigRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", "profiles.json")
+
+	config, err := LoadProfileConfig(path)
+	if err != nil || len(config.Profiles) != 0 {
+		t.Fatalf("Expected an empty config for a missing file, got %+v, %v", config, err)
+	}
+	if err := config.SetProfile(ConnectionProfile{Name: "booth", Host: "10.0.1.20", Timeout: 15, Transport: TransportTCP}); err != nil {
+		t.Fatalf("SetProfile failed: %v", err)
+	}
+	if err := config.SetProfile(ConnectionProfile{Name: "bad", Transport: "carrier pigeon"}); err == nil {
+		t.Error("Expected an unknown transport to be refused")
+	}
+	config.Default = "booth"
+	if err := config.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected the profile file to be private, got %v, %v", info.Mode(), err)
+	}
+
+	loaded, err := LoadProfileConfig(path)
+	if err != nil {
+		t.Fatalf("LoadProfileConfig failed: %v", err)
+	}
+	profile, err := loaded.Profile("")
+	if err != nil {
+		t.Fatalf("Profile failed: %v", err)
+	}
+	if profile.Name != "booth" || profile.Host != "10.0.1.20" || profile.Transport != TransportTCP {
+		t.Errorf("Unexpected default profile: %+v", profile)
+	}
+	if _, err := loaded.Profile("missing"); err == nil {
+		t.Error("Expected an error for a missing profile")
+	}
+
+	loaded.RemoveProfile("booth")
+	if _, err := loaded.Profile(""); err == nil {
+		t.Error("Expected removing the default profile to clear the default")
+	}
+}
+
+// TestNewWorkspaceFromProfile tests the settings a profile applies and where its passcode comes from
+func TestNewWorkspaceFromProfile(t *testing.T) {
+	workspace := NewWorkspaceFromProfile(ConnectionProfile{Timeout: 15, Retries: 2, Transport: TransportTCP})
+	if workspace.host != "localhost" || workspace.port != DefaultPort || workspace.timeout != 15 || workspace.maxRetries != 2 || !workspace.useTCP {
+		t.Errorf("Unexpected workspace settings: host %s port %d timeout %d retries %d tcp %v",
+			workspace.host, workspace.port, workspace.timeout, workspace.maxRetries, workspace.useTCP)
+	}
+
+	store := NewMemoryPasscodeStore()
+	store.Set("booth", "1234")
+	tests := []struct {
+		profile ConnectionProfile
+		want    string
+	}{
+		{ConnectionProfile{Name: "booth"}, "1234"},
+		{ConnectionProfile{Name: "booth", Passcode: "9999"}, "9999"},
+		{ConnectionProfile{Name: "stage"}, ""},
+	}
+	for _, tt := range tests {
+		if got, err := tt.profile.ResolvePasscode(store); err != nil || got != tt.want {
+			t.Errorf("ResolvePasscode(%+v) = %q, %v; want %q", tt.profile, got, err, tt.want)
+		}
+	}
+}
+
+// TestConnectProfile tests connecting to the mock server through a profile
+func TestConnectProfile(t *testing.T) {
+	port, err := getFreePort()
+	if err != nil {
+		t.Fatalf("Failed to get free port: %v", err)
+	}
+	mockServer := NewMockOSCServer("localhost", port)
+	if err := mockServer.Start(); err != nil {
+		t.Fatalf("Failed to start mock server: %v", err)
+	}
+	defer func() {
+		if err := mockServer.Stop(); err != nil {
+			t.Logf("Failed to stop mock server: %v", err)
+		}
+	}()
+
+	workspace, err := ConnectProfile(ConnectionProfile{Name: "mock", Host: "localhost", Port: port}, NewMemoryPasscodeStore())
+	if err != nil {
+		t.Fatalf("ConnectProfile failed: %v", err)
+	}
+	defer workspace.Close()
+	if workspace.WorkspaceID() != mockServer.GetWorkspaceID() {
+		t.Errorf("Expected workspace %s, got %q", mockServer.GetWorkspaceID(), workspace.WorkspaceID())
+	}
+}
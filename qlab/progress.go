@@ -0,0 +1,159 @@
+package qlab
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// TransmitPhase identifies a stage of TransmitWorkspaceData
+type TransmitPhase string
+
+const (
+	PhaseCompare    TransmitPhase = "compare"    // Three-way comparison with the cache and QLab
+	PhaseDelete     TransmitPhase = "delete"     // Deleting cues removed from the source (SetSyncDeletions)
+	PhaseCreate     TransmitPhase = "create"     // Creating new cues
+	PhaseProperties TransmitPhase = "properties" // Setting the properties of changed cues
+	PhaseMove       TransmitPhase = "move"       // Moving cues into their groups and into place
+	PhaseTargets    TransmitPhase = "targets"    // Resolving and setting cue targets
+)
+
+// TransmitProgress reports a step of a transmit
+type TransmitProgress struct {
+	Phase     TransmitPhase
+	Current   int    // Position of this step in its phase, starting at 1
+	Total     int    // Steps this phase will take
+	CueNumber string // Cue the step applied to, empty when it has no number
+}
+
+// ProgressReporter receives progress as TransmitWorkspaceData works through each phase
+type ProgressReporter interface {
+	ReportProgress(progress TransmitProgress)
+}
+
+// ProgressFunc adapts a function to ProgressReporter
+type ProgressFunc func(progress TransmitProgress)
+
+// ReportProgress calls f
+func (f ProgressFunc) ReportProgress(progress TransmitProgress) {
+	f(progress)
+}
+
+// SetProgressReporter sets a reporter that receives a TransmitProgress for each step of
+// TransmitWorkspaceData, for drawing progress bars. Phases without work are not reported.
+func (q *Workspace) SetProgressReporter(reporter ProgressReporter) {
+	q.progressReporter = reporter
+}
+
+// progressTracker counts the steps of one transmit
+type progressTracker struct {
+	mu       sync.Mutex
+	reporter ProgressReporter
+	totals   map[TransmitPhase]int
+	current  map[TransmitPhase]int
+}
+
+// startProgress begins tracking a transmit, returning nil when no reporter is set
+func (q *Workspace) startProgress() *progressTracker {
+	if q.progressReporter == nil {
+		return nil
+	}
+	q.progress = &progressTracker{
+		reporter: q.progressReporter,
+		totals:   map[TransmitPhase]int{PhaseCompare: 1},
+		current:  make(map[TransmitPhase]int),
+	}
+	return q.progress
+}
+
+// finishProgress stops tracking the transmit
+func (q *Workspace) finishProgress() {
+	q.progress = nil
+}
+
+// setTotal sets the number of steps a phase will take
+func (p *progressTracker) setTotal(phase TransmitPhase, total int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.totals[phase] = total
+}
+
+// step reports the next step of a phase
+func (p *progressTracker) step(phase TransmitPhase, cueNumber string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.current[phase]++
+	progress := TransmitProgress{
+		Phase:     phase,
+		Current:   p.current[phase],
+		Total:     max(p.totals[phase], p.current[phase]),
+		CueNumber: cueNumber,
+	}
+	p.mu.Unlock()
+	p.reporter.ReportProgress(progress)
+}
+
+// countTransmitSteps sets the create, properties and move totals for transmitting cues,
+// following the rules processCueListWithParentMappingAndChangeDetectionWithIndex applies
+func (q *Workspace) countTransmitSteps(p *progressTracker, cuesData []any, changeResults map[string]*CueChangeResult) {
+	if p == nil {
+		return
+	}
+	totals := make(map[TransmitPhase]int)
+	var walk func(cues []any, parentNumber string, nested, inGroup bool)
+	walk = func(cues []any, parentNumber string, nested, inGroup bool) {
+		for i, cueAny := range cues {
+			cueData, ok := cueAny.(map[string]any)
+			if !ok {
+				continue
+			}
+			cueType, _ := cueData["type"].(string)
+			cueName, _ := cueData["name"].(string)
+			fullNumber := q.fullCueNumber(parentNumber, formatCueNumber(cueData["number"]))
+
+			// Only nested cues without a number are looked up by position
+			key := fullNumber
+			if key == "" && nested {
+				key = positionCueKey(parentNumber, i, cueType, cueName)
+			}
+			result := changeResults[key]
+			if result == nil && cueType == "list" && q.lookupCueList(cueName) != "" {
+				continue // Existing cue lists are reused as they are
+			}
+
+			switch {
+			case result != nil && result.Action == "skip":
+				continue // Unchanged cues are left in place along with their children
+			case result != nil && (result.Action == "move" || result.Action == "keep"):
+				// Moves are counted with the pending moves below; kept cues take no steps
+			case result != nil && result.Action == "update":
+				totals[PhaseProperties]++
+			default:
+				totals[PhaseCreate]++
+			}
+			// Cues are moved into groups; cue lists take their cues where they're created
+			if inGroup && (result == nil || (result.Action != "move" && result.Action != "keep")) {
+				totals[PhaseMove]++
+			}
+			if subCues, ok := cueData["cues"].([]any); ok {
+				walk(subCues, fullNumber, true, cueType != "list")
+			}
+		}
+	}
+	walk(cuesData, "", false, false)
+	totals[PhaseMove] += len(pendingMoves(changeResults))
+
+	for phase, total := range totals {
+		p.setTotal(phase, total)
+	}
+}
+
+// positionCueKey is the change detection key of a cue without a number
+func positionCueKey(parentNumber string, index int, cueType, cueName string) string {
+	return fmt.Sprintf("%s@%d[%s:%s]", parentNumber, index, strings.ToLower(cueType), cueName)
+}
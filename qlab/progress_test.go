@@ -0,0 +1,83 @@
+package qlab
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestCountTransmitSteps tests that phase totals follow the change detection actions
+func TestCountTransmitSteps(t *testing.T) {
+	workspace := &Workspace{cueListNames: make(map[string]string)}
+	workspace.SetProgressReporter(ProgressFunc(func(TransmitProgress) {}))
+	progress := workspace.startProgress()
+
+	cues := []any{
+		map[string]any{"type": "list", "name": "Main", "cues": []any{
+			map[string]any{"type": "group", "number": "10", "cues": []any{
+				map[string]any{"type": "audio", "number": "1"},
+				map[string]any{"type": "audio", "number": "2"},
+			}},
+			map[string]any{"type": "group", "number": "20", "cues": []any{
+				map[string]any{"type": "audio", "number": "1"},
+			}},
+			map[string]any{"type": "memo", "number": "30"},
+		}},
+	}
+	results := map[string]*CueChangeResult{
+		"20": {Action: "skip"}, // Its child is left alone too
+		"30": {Action: "update"},
+	}
+	workspace.countTransmitSteps(progress, cues, results)
+
+	want := map[TransmitPhase]int{PhaseCompare: 1, PhaseCreate: 4, PhaseProperties: 1, PhaseMove: 2}
+	for phase, total := range want {
+		if progress.totals[phase] != total {
+			t.Errorf("Expected %d %s steps, got %d", total, phase, progress.totals[phase])
+		}
+	}
+}
+
+// TestTransmitProgress tests that each phase reports its steps while cues are transmitted
+func TestTransmitProgress(t *testing.T) {
+	workspace, _ := setupWorkspaceWithCleanup(t)
+
+	var mu sync.Mutex
+	var reports []TransmitProgress
+	workspace.SetProgressReporter(ProgressFunc(func(progress TransmitProgress) {
+		mu.Lock()
+		defer mu.Unlock()
+		reports = append(reports, progress)
+	}))
+	workspace.startProgress()
+	defer workspace.finishProgress()
+
+	workspaceData := map[string]any{
+		"cues": []any{
+			map[string]any{"type": "group", "number": "10", "name": "Scene", "cues": []any{
+				map[string]any{"type": "memo", "number": "10.1", "name": "Cue A"},
+				map[string]any{"type": "start", "number": "10.2", "name": "Cue B", "cueTargetNumber": "10.1"},
+			}},
+		},
+	}
+	comparison := &ThreeWayComparison{CueResults: make(map[string]*CueChangeResult)}
+	if err := workspace.transmitCueFileWithChangeDetection(workspaceData, comparison); err != nil {
+		t.Fatalf("Transmit failed: %v", err)
+	}
+
+	last := make(map[TransmitPhase]TransmitProgress)
+	for _, report := range reports {
+		if report.Current > report.Total {
+			t.Errorf("Step beyond its total: %+v", report)
+		}
+		last[report.Phase] = report
+	}
+	want := map[TransmitPhase]int{PhaseCreate: 3, PhaseMove: 2, PhaseTargets: 1}
+	for phase, total := range want {
+		if got := last[phase]; got.Current != total || got.Total != total {
+			t.Errorf("Expected %s to finish at %d/%d, got %+v", phase, total, total, got)
+		}
+	}
+	if got := last[PhaseTargets].CueNumber; got != "10.2" {
+		t.Errorf("Expected the target step for cue 10.2, got %q", got)
+	}
+}
@@ -0,0 +1,132 @@
+package qlab
+
+import (
+	"strconv"
+	"strings"
+)
+
+// QLabFeature is a part of QLab's OSC interface that older versions of QLab lack
+type QLabFeature string
+
+const (
+	FeatureCueListUniqueIDs QLabFeature = "cueListUniqueIDs" // /cueLists/uniqueIDs lists every cue's uniqueID in one reply
+	FeatureParentProperty   QLabFeature = "parent"           // /cue_id/{id}/parent reports the group or cue list holding a cue
+	FeatureTextCues         QLabFeature = "textCues"         // Text cues are created as "text"; QLab 4 calls them "titles"
+	FeatureExtendedColors   QLabFeature = "extendedColors"   // Cue colors beyond red, orange, green, blue and purple
+)
+
+// qlabFeatureSince is the first major version of QLab with each feature
+var qlabFeatureSince = map[QLabFeature]int{
+	FeatureCueListUniqueIDs: 5,
+	FeatureParentProperty:   5,
+	FeatureTextCues:         5,
+	FeatureExtendedColors:   5,
+}
+
+// QLabVersion returns the version QLab reported when the workspace connected, e.g. "5.4.1",
+// or "" if it didn't report one
+func (q *Workspace) QLabVersion() string {
+	q.stateMu.Lock()
+	defer q.stateMu.Unlock()
+	return q.qlabVersion
+}
+
+// QLabMajorVersion returns the major version of QLabVersion, or 0 if it's unknown
+func (q *Workspace) QLabMajorVersion() int {
+	return parseMajorVersion(q.QLabVersion())
+}
+
+// Supports reports whether the connected QLab has a feature. QLab 5 is assumed when the
+// version is unknown.
+func (q *Workspace) Supports(feature QLabFeature) bool {
+	major := q.QLabMajorVersion()
+	return major == 0 || major >= qlabFeatureSince[feature]
+}
+
+// detectQLabVersion asks QLab for its version on connect, so addresses and features can be
+// adjusted for older versions
+func (q *Workspace) detectQLabVersion() {
+	version, err := q.queryVersion()
+	if err != nil || version == "" {
+		q.log().Warnf("Could not query QLab version, assuming QLab 5: %v", err)
+		version = ""
+	}
+	q.stateMu.Lock()
+	q.qlabVersion = version
+	q.stateMu.Unlock()
+	if version == "" {
+		return
+	}
+	if q.QLabMajorVersion() < 5 {
+		q.log().Infof("Connected to QLab %s; using QLab 4 addresses", version)
+	} else {
+		q.log().Debugf("Connected to QLab %s", version)
+	}
+}
+
+// parseMajorVersion returns the major version of a version string such as "4.6.10", or 0
+// if it doesn't start with one
+func parseMajorVersion(version string) int {
+	major, _, _ := strings.Cut(strings.TrimSpace(version), ".")
+	n, err := strconv.Atoi(major)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// newCueType returns the type name the connected QLab creates a cue of cueType with
+func (q *Workspace) newCueType(cueType string) string {
+	if strings.EqualFold(cueType, CueTypeText) && !q.Supports(FeatureTextCues) {
+		return "titles"
+	}
+	return cueType
+}
+
+// cueIDsFromCueLists returns the uniqueID of every cue from a fresh /cueLists query, for
+// QLab versions without /cueLists/uniqueIDs
+func (q *Workspace) cueIDsFromCueLists() ([]string, error) {
+	q.InvalidateCueCache()
+	data, err := q.getCueLists()
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, cueListData := range data {
+		if cueList, ok := cueListData.(map[string]any); ok {
+			cues, _ := cueList["cues"].([]any)
+			ids = append(ids, extractCueIDs(cues)...)
+		}
+	}
+	return ids, nil
+}
+
+// locateCue returns a cue's parent ID and its index within the parent from a fresh
+// /cueLists query, for QLab versions without the parent property; "", -1 if not found
+func (q *Workspace) locateCue(cueID string) (string, int) {
+	q.InvalidateCueCache()
+	data, err := q.getCueLists()
+	if err != nil {
+		return "", -1
+	}
+	var find func(parentID string, children []any) (string, int)
+	find = func(parentID string, children []any) (string, int) {
+		for i, child := range children {
+			cue, ok := child.(map[string]any)
+			if !ok {
+				continue
+			}
+			uniqueID, _ := cue["uniqueID"].(string)
+			if uniqueID == cueID {
+				return parentID, i
+			}
+			if nested, ok := cue["cues"].([]any); ok {
+				if found, index := find(uniqueID, nested); index >= 0 {
+					return found, index
+				}
+			}
+		}
+		return "", -1
+	}
+	return find("", data)
+}
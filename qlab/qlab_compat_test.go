@@ -0,0 +1,82 @@
+package qlab
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestQLabVersionShim tests that the QLab version is detected on connect and that QLab 4
+// gets the addresses and cue types it understands
+func TestQLabVersionShim(t *testing.T) {
+	port, err := getFreePort()
+	if err != nil {
+		t.Fatalf("Failed to get free port: %v", err)
+	}
+	mockServer := NewMockOSCServer("localhost", port)
+	mockServer.SetVersion("4.6.10")
+	if err := mockServer.Start(); err != nil {
+		t.Fatalf("Failed to start mock server: %v", err)
+	}
+	workspace := NewWorkspace("localhost", port)
+	workspace.SetTimeout(1)
+	t.Cleanup(func() {
+		workspace.Close()
+		if err := mockServer.Stop(); err != nil {
+			t.Logf("Failed to stop mock server: %v", err)
+		}
+	})
+
+	if !workspace.Supports(FeatureParentProperty) {
+		t.Error("Expected QLab 5 features to be assumed before connecting")
+	}
+	if _, err := workspace.Init(""); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	if workspace.QLabVersion() != "4.6.10" || workspace.QLabMajorVersion() != 4 {
+		t.Errorf("Expected QLab 4.6.10, got %q (major %d)", workspace.QLabVersion(), workspace.QLabMajorVersion())
+	}
+	for _, feature := range []QLabFeature{FeatureCueListUniqueIDs, FeatureParentProperty, FeatureTextCues} {
+		if workspace.Supports(feature) {
+			t.Errorf("Expected QLab 4 not to support %s", feature)
+		}
+	}
+
+	group, err := workspace.createCueWithoutTarget(map[string]any{"type": "group", "number": "1"}, "1")
+	if err != nil {
+		t.Fatalf("Failed to create group: %v", err)
+	}
+	text, err := workspace.createCueWithoutTarget(map[string]any{"type": "text", "number": "1.1"}, "1.1")
+	if err != nil {
+		t.Fatalf("Failed to create text cue: %v", err)
+	}
+	if cue := mockServer.GetCue(text); cue == nil || cue.Type != "titles" {
+		t.Errorf("Expected the text cue created as a QLab 4 titles cue, got %+v", cue)
+	}
+	if err := workspace.moveCueToParentWithIndex(text, group, 0); err != nil {
+		t.Fatalf("Failed to move cue into group: %v", err)
+	}
+
+	mockServer.ClearReceivedMessages()
+	ids, err := workspace.getAllCueIDs()
+	if err != nil || !slices.Equal(ids, []string{group, text}) {
+		t.Errorf("Expected every cue ID, got %v (%v)", ids, err)
+	}
+	if parentID, index := workspace.queryCueLocation(text); parentID != group || index != 0 {
+		t.Errorf("Expected the cue at index 0 of the group, got %s at %d", parentID, index)
+	}
+	if sent := mockServer.GetMessagesForAddress("/cueLists/uniqueIDs"); len(sent) != 0 {
+		t.Errorf("Expected no /cueLists/uniqueIDs query on QLab 4, got %d", len(sent))
+	}
+	if sent := mockServer.GetMessagesForAddress("/parent"); len(sent) != 0 {
+		t.Errorf("Expected no /parent query on QLab 4, got %d", len(sent))
+	}
+}
+
+// TestParseMajorVersion tests reading the major version from QLab's version strings
+func TestParseMajorVersion(t *testing.T) {
+	for version, want := range map[string]int{"5.4.1": 5, "4.6.10": 4, "5": 5, "": 0, "beta": 0} {
+		if got := parseMajorVersion(version); got != want {
+			t.Errorf("parseMajorVersion(%q) = %d, want %d", version, got, want)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+// Package qlabexpvar publishes the metrics of a qlab.MetricsCollector through expvar. It is
+// kept out of package qlab because importing expvar registers /debug/vars on
+// http.DefaultServeMux, which programs that don't want it shouldn't get.
+package qlabexpvar
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+
+	"github.com/zenibako/qlab-golang/qlab"
+)
+
+// ErrNameInUse is returned by Publish when another expvar variable already has the name
+var ErrNameInUse = errors.New("expvar name already in use")
+
+// Publish publishes the metrics collected by collector as the expvar variable name, served
+// on /debug/vars. Unlike expvar.Publish it doesn't panic when the name is taken; it
+// returns ErrNameInUse and leaves the existing variable alone.
+func Publish(name string, collector *qlab.MetricsCollector) error {
+	if expvar.Get(name) != nil {
+		return fmt.Errorf("%q: %w", name, ErrNameInUse)
+	}
+	expvar.Publish(name, expvar.Func(func() any { return collector.Snapshot() }))
+	return nil
+}
@@ -0,0 +1,31 @@
+package qlabexpvar
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"testing"
+
+	"github.com/zenibako/qlab-golang/qlab"
+)
+
+// TestPublish tests that the collector's snapshot is published and a taken name is refused
+func TestPublish(t *testing.T) {
+	collector := qlab.NewMetricsCollector()
+	collector.MessageSent("/workspace/W/go")
+
+	if err := Publish("qlabexpvar_test", collector); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	var snapshot qlab.MetricsSnapshot
+	if err := json.Unmarshal([]byte(expvar.Get("qlabexpvar_test").String()), &snapshot); err != nil {
+		t.Fatalf("Failed to decode published metrics: %v", err)
+	}
+	if snapshot.MessagesSent != 1 {
+		t.Errorf("Expected 1 message sent, got %d", snapshot.MessagesSent)
+	}
+
+	if err := Publish("qlabexpvar_test", qlab.NewMetricsCollector()); !errors.Is(err, ErrNameInUse) {
+		t.Errorf("Expected ErrNameInUse publishing a taken name, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package qlab
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+// TestGetCueByNumberAndID tests that cues are found by number or ID with their type-specific properties
+func TestGetCueByNumberAndID(t *testing.T) {
+	workspace, _ := setupWorkspaceWithCleanup(t)
+
+	cueID, err := workspace.createCueWithoutTarget(map[string]any{
+		"type":      "video",
+		"name":      "Backdrop",
+		"layer":     5.0,
+		"holdAtEnd": true,
+	}, "12")
+	if err != nil {
+		t.Fatalf("createCueWithoutTarget failed: %v", err)
+	}
+
+	cue, err := workspace.GetCueByNumber("12")
+	if err != nil {
+		t.Fatalf("GetCueByNumber failed: %v", err)
+	}
+	if cue.UniqueID != cueID || cue.Name != "Backdrop" || cue.Type != CueTypeVideo {
+		t.Errorf("Unexpected cue: %+v", cue)
+	}
+	if cue.Layer != 5 || !cue.HoldAtEnd {
+		t.Errorf("Expected enriched video properties, got layer=%d holdAtEnd=%v", cue.Layer, cue.HoldAtEnd)
+	}
+
+	byID, err := workspace.GetCueByID(cueID)
+	if err != nil {
+		t.Fatalf("GetCueByID failed: %v", err)
+	}
+	if byID.Number != "12" {
+		t.Errorf("Expected cue 12, got %q", byID.Number)
+	}
+
+	if _, err := workspace.GetCueByNumber("404"); !errors.Is(err, ErrCueNotFound) {
+		t.Errorf("Expected ErrCueNotFound, got %v", err)
+	}
+}
+
+// TestFindCuesUsesCache tests that FindCues filters cached cue lists until the cache is invalidated
+func TestFindCuesUsesCache(t *testing.T) {
+	workspace, _ := setupWorkspaceWithCleanup(t)
+
+	for i, name := range []string{"Music", "Note", "Sting"} {
+		cueType := "audio"
+		if name == "Note" {
+			cueType = "memo"
+		}
+		if _, err := workspace.createCueWithoutTarget(map[string]any{"type": cueType, "name": name}, strconv.Itoa(i+1)); err != nil {
+			t.Fatalf("createCueWithoutTarget failed: %v", err)
+		}
+	}
+
+	isAudio := func(cue *Cue) bool { return cue.Type == CueTypeAudio }
+	audio, err := workspace.FindCues(isAudio)
+	if err != nil {
+		t.Fatalf("FindCues failed: %v", err)
+	}
+	if len(audio) != 2 {
+		t.Fatalf("Expected 2 audio cues, got %d", len(audio))
+	}
+
+	// New cues aren't seen until the cache is invalidated
+	if _, err := workspace.createCueWithoutTarget(map[string]any{"type": "audio", "name": "Encore"}, "4"); err != nil {
+		t.Fatalf("createCueWithoutTarget failed: %v", err)
+	}
+	if audio, _ := workspace.FindCues(isAudio); len(audio) != 2 {
+		t.Errorf("Expected cached result of 2 audio cues, got %d", len(audio))
+	}
+	workspace.InvalidateCueCache()
+	if audio, _ := workspace.FindCues(isAudio); len(audio) != 3 {
+		t.Errorf("Expected 3 audio cues after invalidating, got %d", len(audio))
+	}
+}
@@ -0,0 +1,113 @@
+package qlab
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ReadOnlyError is returned when a read-only workspace refuses to change QLab. It unwraps
+// to ErrReadOnly.
+type ReadOnlyError struct {
+	Operation string // The refused operation, or the address of the refused message
+}
+
+func (e *ReadOnlyError) Error() string {
+	return fmt.Sprintf("%v: refused %s", ErrReadOnly, e.Operation)
+}
+
+// Unwrap returns ErrReadOnly
+func (e *ReadOnlyError) Unwrap() error {
+	return ErrReadOnly
+}
+
+// refusedReply builds the error reply returned by the legacy Send APIs for messages refused
+// with ErrReadOnly or ErrInsufficientPermissions
+func refusedReply(address string, err error) []any {
+	sentinel := ErrReadOnly
+	if errors.Is(err, ErrInsufficientPermissions) {
+		sentinel = ErrInsufficientPermissions
+	}
+	return []any{fmt.Sprintf(`{"status": "error", "error": %q, "address": %q}`, sentinel.Error(), address)}
+}
+
+// SetReadOnly sets whether the workspace refuses every message that would change QLab:
+// creating, moving and deleting cues, setting properties, selecting cues and playback
+// commands. Queries and update subscriptions still work. Refused messages are never sent;
+// the calls making them fail with an error matching ErrReadOnly, and transmits, batches,
+// snapshot imports, renumbering and bulk edits are refused before they start. Dry runs
+// still log what they would send.
+func (q *Workspace) SetReadOnly(readOnly bool) {
+	q.readOnly = readOnly
+}
+
+// IsReadOnly reports whether the workspace refuses messages that would change QLab
+func (q *Workspace) IsReadOnly() bool {
+	return q.readOnly
+}
+
+// checkWritable returns a *ReadOnlyError if the workspace is read-only, or a
+// *PermissionError if its passcode doesn't allow editing, for an operation that would
+// change cues
+func (q *Workspace) checkWritable(operation string) error {
+	if q.dryRun {
+		return nil
+	}
+	if q.readOnly {
+		return &ReadOnlyError{Operation: operation}
+	}
+	return q.checkPermission(operation, "edit")
+}
+
+// checkMessageWritable returns a *ReadOnlyError if the workspace is read-only, or a
+// *PermissionError if its passcode doesn't allow it, for a message that would change QLab
+func (q *Workspace) checkMessageWritable(address, input string, args []any) error {
+	if !isMutation(address, input, args) {
+		return nil
+	}
+	if q.readOnly {
+		return &ReadOnlyError{Operation: address}
+	}
+	return q.checkPermission(address, requiredPermission(address))
+}
+
+// argQueries are messages whose arguments say what to read or how to reply, not a value to set
+var argQueries = []string{"connect", "alwaysReply", "updates", "udpReplyPort", "thump", "valuesForKeys"}
+
+// controlCommands are playback commands, which change the workspace without arguments
+var controlCommands = []string{
+	"go", "stop", "pause", "resume", "panic", "hardStop", "reset",
+	"start", "load", "loadAt", "preview", "hardPause", "togglePause", "panicInTime",
+}
+
+// pathChanges are address parts that come before the cue a change applies to
+var pathChanges = []string{"select_id", "delete_id", "delete", "move"}
+
+// isChange reports whether a message changes the workspace, as opposed to a query or a
+// playback command
+func isChange(address, input string, args []any) bool {
+	return isMutation(address, input, args) && requiredPermission(address) != "control"
+}
+
+// isMutation reports whether a message would change QLab. Property addresses are queries
+// without arguments and sets with them, except levels and gangs, which are read with a
+// row and column and set with a row, column and value.
+func isMutation(address, input string, args []any) bool {
+	parts := strings.Split(strings.Trim(address, "/"), "/")
+	last := parts[len(parts)-1]
+	argCount := len(args)
+	if input != "" {
+		argCount++
+	}
+
+	switch {
+	case slices.Contains(argQueries, last):
+		return false
+	case last == "new", last == "delete", slices.Contains(controlCommands, last), slices.ContainsFunc(parts, func(part string) bool { return slices.Contains(pathChanges, part) }):
+		return true
+	case last == "level" || last == "sliderLevel" || last == "gang":
+		return argCount > 2
+	}
+	return argCount > 0
+}
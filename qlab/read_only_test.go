@@ -0,0 +1,90 @@
+package qlab
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// TestReadOnlyRefusesChanges tests that a read-only workspace refuses changes without
+// sending them while queries keep working
+func TestReadOnlyRefusesChanges(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+
+	cueID, err := workspace.createCue(map[string]any{"type": "memo", "name": "Preshow"}, "1")
+	if err != nil {
+		t.Fatalf("Failed to create cue: %v", err)
+	}
+	workspace.SetReadOnly(true)
+	if !workspace.IsReadOnly() {
+		t.Fatal("Expected the workspace to be read-only")
+	}
+	mockServer.ClearReceivedMessages()
+
+	_, err = workspace.TransmitWorkspaceData(filepath.Join(t.TempDir(), "show.json"), map[string]any{"cues": []any{
+		map[string]any{"type": "memo", "number": "2", "name": "Refused"},
+	}})
+	var readOnlyErr *ReadOnlyError
+	if !errors.As(err, &readOnlyErr) || readOnlyErr.Operation != "transmit" {
+		t.Errorf("Expected the transmit refused with a *ReadOnlyError, got %v", err)
+	}
+	if err := workspace.setCueProperty(cueID, "name", "Renamed"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected the property set refused with ErrReadOnly, got %v", err)
+	}
+	if err := workspace.Go(); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected GO refused with ErrReadOnly, got %v", err)
+	}
+	if _, err := workspace.RenumberCues("Main Cue List", 1, 1); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected renumbering refused with ErrReadOnly, got %v", err)
+	}
+
+	for _, msg := range mockServer.GetReceivedMessages() {
+		if isMutation(msg.Address, "", msg.Arguments) {
+			t.Errorf("Expected nothing that changes QLab sent, got %s %v", msg.Address, msg.Arguments)
+		}
+	}
+	if name := mockServer.GetCue(cueID).Name; name != "Preshow" {
+		t.Errorf("Expected the cue name unchanged, got %q", name)
+	}
+	if mockServer.GetCueCount() != 1 {
+		t.Errorf("Expected no cues created, got %d cues", mockServer.GetCueCount())
+	}
+
+	cue, err := workspace.GetCueByNumber("1")
+	if err != nil || cue.UniqueID != cueID {
+		t.Errorf("Expected queries to work while read-only, got %v, %v", cue, err)
+	}
+}
+
+// TestIsMutation tests which messages count as changing QLab
+func TestIsMutation(t *testing.T) {
+	tests := []struct {
+		address  string
+		input    string
+		args     []any
+		mutation bool
+	}{
+		{"/workspace/W/cue_id/C/name", "", nil, false},
+		{"/workspace/W/cue_id/C/name", "House open", nil, true},
+		{"/workspace/W/cue_id/C/number", "", []any{""}, true},
+		{"/workspace/W/cue_id/C/valuesForKeys", `["name"]`, nil, false},
+		{"/workspace/W/cue_id/C/level", "", []any{int32(0), int32(0)}, false},
+		{"/workspace/W/cue_id/C/level", "", []any{int32(0), int32(0), -6.0}, true},
+		{"/workspace/W/new", "memo", nil, true},
+		{"/workspace/W/delete_id/C", "", nil, true},
+		{"/workspace/W/move/C", "", []any{int32(0), "G"}, true},
+		{"/workspace/W/select_id/C", "", nil, true},
+		{"/workspace/W/go", "", nil, true},
+		{"/workspace/W/cue_id/C/start", "", nil, true},
+		{"/workspace/W/cueLists", "", nil, false},
+		{"/workspace/W/updates", "", []any{int32(1)}, false},
+		{"/workspace/W/connect", "1234", nil, false},
+		{"/alwaysReply", "1", nil, false},
+		{"/thump", "", nil, false},
+	}
+	for _, tt := range tests {
+		if got := isMutation(tt.address, tt.input, tt.args); got != tt.mutation {
+			t.Errorf("isMutation(%s, %q, %v) = %v, want %v", tt.address, tt.input, tt.args, got, tt.mutation)
+		}
+	}
+}
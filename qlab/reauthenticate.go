@@ -0,0 +1,60 @@
+package qlab
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// NeedsReauthentication reports whether QLab stopped accepting the workspace's passcode
+// after it connected, so workspace messages fail with ErrReauthenticationRequired until
+// Reauthenticate succeeds
+func (q *Workspace) NeedsReauthentication() bool {
+	return q.reauthRequired.Load()
+}
+
+// Reauthenticate connects again with a new passcode after the workspace's passcode changed
+// mid-session. The cue indexes, cache, hooks and settings are kept, the cues are
+// re-indexed, and updates are subscribed to again if they were. A passcode QLab rejects
+// returns an error matching ErrAuthFailed, and messages keep failing until another
+// succeeds.
+func (q *Workspace) Reauthenticate(passcode string) error {
+	if q.workspace_id == "" {
+		return fmt.Errorf("workspace isn't connected; call Init instead")
+	}
+	// Init re-indexes the cues, which needs workspace messages to go out
+	q.reauthRequired.Store(false)
+	q.passcode = passcode
+	if err := q.resumeSession(); err != nil {
+		if errors.Is(err, ErrAuthFailed) {
+			q.reauthRequired.Store(true)
+		}
+		return fmt.Errorf("failed to reauthenticate: %w", err)
+	}
+	q.log().Info("Reauthenticated with QLab", "workspace_id", q.workspace_id)
+	return nil
+}
+
+// passcodeRevoked records that QLab rejected the passcode of a connected workspace for a
+// message that needs it, and reports whether it did
+func (q *Workspace) passcodeRevoked(address string) bool {
+	if !q.initialized || !needsPasscode(address) {
+		return false
+	}
+	if !q.reauthRequired.Swap(true) {
+		q.log().Warnf("QLab rejected the passcode for %s; call Reauthenticate with the new one", address)
+	}
+	return true
+}
+
+// needsPasscode reports whether a message is one QLab refuses without the workspace's
+// passcode: a workspace message other than connecting
+func needsPasscode(address string) bool {
+	return strings.HasPrefix(address, "/workspace/") && !strings.HasSuffix(address, "/connect")
+}
+
+// reauthenticationReply is the reply returned in place of QLab's to a message refused
+// because the passcode is no longer accepted
+func reauthenticationReply(address string) []any {
+	return []any{fmt.Sprintf(`{"status": "denied", "error": %q, "address": %q}`, ErrReauthenticationRequired.Error(), address)}
+}
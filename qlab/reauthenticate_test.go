@@ -0,0 +1,51 @@
+package qlab
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestReauthenticate tests that a passcode changed mid-session fails every workspace
+// message with ErrReauthenticationRequired until Reauthenticate connects with the new one
+func TestReauthenticate(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+	cueID, err := workspace.createCue(map[string]any{"type": "memo", "name": "Standby"}, "")
+	if err != nil {
+		t.Fatalf("Failed to create cue: %v", err)
+	}
+
+	mockServer.SetPasscode("4321")
+	if _, err := workspace.Query("/cueLists"); !errors.Is(err, ErrReauthenticationRequired) {
+		t.Fatalf("Expected ErrReauthenticationRequired once the passcode changed, got %v", err)
+	}
+	if !workspace.NeedsReauthentication() {
+		t.Error("Expected the workspace to need reauthentication")
+	}
+
+	// Later messages are refused without being sent
+	sent := len(mockServer.GetReceivedMessages())
+	if _, err := workspace.Query(workspace.CueAddress(cueID, "name")); !errors.Is(err, ErrReauthenticationRequired) || !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("Expected a cue query to fail with ErrReauthenticationRequired, got %v", err)
+	}
+	if got := len(mockServer.GetReceivedMessages()); got != sent {
+		t.Errorf("Expected no messages sent while reauthentication is needed, got %d", got-sent)
+	}
+
+	if err := workspace.Reauthenticate("1111"); !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("Expected the old passcode rejected, got %v", err)
+	}
+	if !workspace.NeedsReauthentication() {
+		t.Error("Expected a rejected passcode to leave the workspace needing reauthentication")
+	}
+
+	if err := workspace.Reauthenticate("4321"); err != nil {
+		t.Fatalf("Reauthenticate failed: %v", err)
+	}
+	if workspace.NeedsReauthentication() {
+		t.Error("Expected reauthentication no longer needed")
+	}
+	if reply, err := workspace.Query(workspace.CueAddress(cueID, "name")); err != nil || !reply.OK() {
+		t.Errorf("Expected the cue queried after reauthenticating, got %+v, %v", reply, err)
+	}
+}
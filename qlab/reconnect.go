@@ -0,0 +1,340 @@
+package qlab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default reconnect policy: retry every second, doubling up to 30 seconds, until Close,
+// and replay up to 32 failed changes
+const (
+	DefaultReconnectInterval    = time.Second
+	DefaultMaxReconnectInterval = 30 * time.Second
+	DefaultReconnectReplay      = 32
+)
+
+// ErrReconnectStopped is the error of the ReconnectGaveUp event sent when Close ends a reconnect
+var ErrReconnectStopped = errors.New("reconnect stopped by Close")
+
+// ReconnectPolicy controls automatic reconnection
+type ReconnectPolicy struct {
+	MaxAttempts int           // Attempts before giving up, 0 to keep trying until Close
+	Interval    time.Duration // Wait before the first attempt; doubles after each failure
+	MaxInterval time.Duration // Longest wait between attempts
+	Replay      int           // Failed changes kept and resent after reconnecting, -1 for none
+}
+
+// ReconnectState is the stage of a reconnect a ReconnectEvent reports
+type ReconnectState string
+
+const (
+	ReconnectStarted   ReconnectState = "started"   // A disconnect was detected and reconnecting began
+	ReconnectFailed    ReconnectState = "failed"    // An attempt failed; another will follow
+	ReconnectSucceeded ReconnectState = "succeeded" // The session was restored
+	ReconnectGaveUp    ReconnectState = "gave_up"   // MaxAttempts failed, or the workspace was closed
+)
+
+// ReconnectEvent reports progress of an automatic reconnect
+type ReconnectEvent struct {
+	State    ReconnectState
+	Reason   DisconnectReason // Why the disconnect was detected
+	Attempt  int              // Attempts so far, 0 for ReconnectStarted
+	Replayed int              // Failed changes resent, for ReconnectSucceeded
+	Dropped  int              // Failed changes that didn't fit in the replay buffer
+	Skipped  int              // Failed changes not resent: their cue is gone or their transaction didn't commit
+	Err      error            // Why the attempt failed, for ReconnectFailed and ReconnectGaveUp
+}
+
+// failedSend is a property set that timed out and may be replayed after reconnecting
+type failedSend struct {
+	address string
+	input   string
+	args    []any
+	cueID   string       // Cue the property belongs to
+	tx      *Transaction // Transaction active when it was sent, if any
+}
+
+// reconnectState tracks automatic reconnection for a workspace
+type reconnectState struct {
+	mu       sync.Mutex
+	enabled  bool
+	policy   ReconnectPolicy
+	active   bool          // Whether a reconnect loop is running
+	stop     chan struct{} // Closed by Close to end the loop
+	failed   []failedSend  // Changes to replay, oldest first
+	dropped  int           // Changes dropped from failed since the last reconnect
+	callback func(ReconnectEvent)
+}
+
+// SetAutoReconnect sets whether the workspace reconnects by itself after a disconnect is
+// detected. Reconnecting re-runs /connect with the passcode given to Init, subscribes to
+// updates again, re-indexes the cues, and then resends changes that timed out during the
+// outage. A rejected passcode is not retried.
+func (q *Workspace) SetAutoReconnect(enabled bool) {
+	q.reconnect.mu.Lock()
+	defer q.reconnect.mu.Unlock()
+	q.reconnect.enabled = enabled
+	if !enabled {
+		q.reconnect.failed = nil
+		q.reconnect.dropped = 0
+	}
+}
+
+// SetReconnectPolicy sets the attempts, intervals and replay limit of automatic
+// reconnection. Zero durations use the defaults.
+func (q *Workspace) SetReconnectPolicy(policy ReconnectPolicy) {
+	q.reconnect.mu.Lock()
+	defer q.reconnect.mu.Unlock()
+	q.reconnect.policy = policy
+}
+
+// OnReconnect sets a callback that receives each stage of an automatic reconnect
+func (q *Workspace) OnReconnect(callback func(ReconnectEvent)) {
+	q.reconnect.mu.Lock()
+	defer q.reconnect.mu.Unlock()
+	q.reconnect.callback = callback
+}
+
+// Reconnecting reports whether an automatic reconnect is in progress
+func (q *Workspace) Reconnecting() bool {
+	q.reconnect.mu.Lock()
+	defer q.reconnect.mu.Unlock()
+	return q.reconnect.active
+}
+
+// replayLimit returns how many failed changes are kept
+func (p ReconnectPolicy) replayLimit() int {
+	switch {
+	case p.Replay < 0:
+		return 0
+	case p.Replay == 0:
+		return DefaultReconnectReplay
+	default:
+		return p.Replay
+	}
+}
+
+// recordFailedSend keeps a property set that timed out so it can be replayed, dropping
+// the oldest when the buffer is full. Only property sets are kept: setting a value twice
+// is harmless, while resending a /new, /move or /delete that QLab may have acted on
+// before the outage is not. Queries aren't kept either.
+func (q *Workspace) recordFailedSend(address, input string, args []any) {
+	cueID, ok := replayableCueID(address, input, args)
+	if !ok {
+		return
+	}
+	send := failedSend{address: address, input: input, args: args, cueID: cueID, tx: q.activeTransaction()}
+	q.reconnect.mu.Lock()
+	defer q.reconnect.mu.Unlock()
+	limit := q.reconnect.policy.replayLimit()
+	if !q.reconnect.enabled || limit == 0 {
+		return
+	}
+	q.reconnect.failed = append(q.reconnect.failed, send)
+	if over := len(q.reconnect.failed) - limit; over > 0 {
+		q.reconnect.failed = q.reconnect.failed[over:]
+		q.reconnect.dropped += over
+	}
+}
+
+// replayableCueID returns the cue a message sets a property of, and whether it is such a
+// property set
+func replayableCueID(address, input string, args []any) (string, bool) {
+	parts := strings.Split(strings.Trim(address, "/"), "/")
+	if len(parts) >= 2 && parts[0] == "workspace" {
+		parts = parts[2:]
+	}
+	if len(parts) < 3 || parts[0] != "cue_id" || !isChange(address, input, args) {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// isArglessChange reports whether a message changes the workspace without arguments
+func isArglessChange(address string) bool {
+	return strings.Contains(address, "/delete") || strings.HasSuffix(address, "/new")
+}
+
+// startReconnect begins reconnecting in the background after a disconnect, unless
+// automatic reconnection is off or already under way
+func (q *Workspace) startReconnect(reason DisconnectReason) {
+	if reason == DisconnectBadPasscode {
+		return
+	}
+	q.reconnect.mu.Lock()
+	if !q.reconnect.enabled || q.reconnect.active || q.workspace_id == "" {
+		q.reconnect.mu.Unlock()
+		return
+	}
+	q.reconnect.active = true
+	if q.reconnect.stop == nil {
+		q.reconnect.stop = make(chan struct{})
+	}
+	policy, stop := q.reconnect.policy, q.reconnect.stop
+	q.reconnect.mu.Unlock()
+
+	q.emitReconnect(ReconnectEvent{State: ReconnectStarted, Reason: reason})
+	go q.reconnectLoop(reason, policy, stop)
+}
+
+// reconnectLoop retries the session until it is restored, the attempts run out, or the
+// workspace is closed
+func (q *Workspace) reconnectLoop(reason DisconnectReason, policy ReconnectPolicy, stop chan struct{}) {
+	interval, limit := policy.Interval, policy.MaxInterval
+	if interval <= 0 {
+		interval = DefaultReconnectInterval
+	}
+	if limit < interval {
+		limit = max(DefaultMaxReconnectInterval, interval)
+	}
+
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-stop:
+			q.finishReconnect(ReconnectEvent{State: ReconnectGaveUp, Reason: reason, Attempt: attempt - 1, Err: ErrReconnectStopped})
+			return
+		case <-time.After(interval):
+		}
+
+		err := q.resumeSession()
+		if err == nil {
+			replayed, dropped, skipped := q.replayFailedSends()
+			q.finishReconnect(ReconnectEvent{State: ReconnectSucceeded, Reason: reason, Attempt: attempt, Replayed: replayed, Dropped: dropped, Skipped: skipped})
+			return
+		}
+		q.log().Debugf("Reconnect attempt %d failed: %v", attempt, err)
+		if policy.MaxAttempts > 0 && attempt >= policy.MaxAttempts {
+			q.finishReconnect(ReconnectEvent{State: ReconnectGaveUp, Reason: reason, Attempt: attempt, Err: err})
+			return
+		}
+		q.emitReconnect(ReconnectEvent{State: ReconnectFailed, Reason: reason, Attempt: attempt, Err: err})
+		interval = min(interval*2, limit)
+	}
+}
+
+// resumeSession reconnects to the workspace, subscribes to updates again, and
+// re-indexes its cues
+func (q *Workspace) resumeSession() error {
+	q.InvalidateCueCache()
+	if _, err := q.Init(q.passcode); err != nil {
+		return err
+	}
+	if q.updateHandler != nil || q.pool != nil || q.useTCP {
+		if err := q.SendNoReply("/updates", int32(1)); err != nil {
+			return fmt.Errorf("failed to subscribe to updates: %w", err)
+		}
+	}
+	// Init indexes the cues QLab has now; drop any it no longer has
+	if _, err := q.ValidateIndexes(); err != nil {
+		q.log().Warnf("Failed to re-index cues after reconnecting: %v", err)
+	}
+	return nil
+}
+
+// replayFailedSends resends the property sets that timed out during the outage, in order.
+// Sets on cues QLab no longer has are skipped, as are those sent within a transaction
+// that didn't commit: a rolled-back transaction has undone them, and one still open
+// failed on the timeout and will roll back.
+func (q *Workspace) replayFailedSends() (replayed, dropped, skipped int) {
+	q.reconnect.mu.Lock()
+	failed, dropped := q.reconnect.failed, q.reconnect.dropped
+	q.reconnect.failed, q.reconnect.dropped = nil, 0
+	q.reconnect.mu.Unlock()
+	if len(failed) == 0 {
+		return 0, dropped, 0
+	}
+
+	existing, err := q.existingCueIDs()
+	if err != nil {
+		q.log().Warnf("Not replaying %d changes; failed to read the cues after reconnecting: %v", len(failed), err)
+		return 0, dropped, len(failed)
+	}
+	for _, send := range failed {
+		if send.tx != nil && !send.tx.isCommitted() {
+			q.log().Debugf("Not replaying %s; its transaction didn't commit", send.address)
+			skipped++
+			continue
+		}
+		if !existing[send.cueID] {
+			q.log().Debugf("Not replaying %s; the cue no longer exists", send.address)
+			skipped++
+			continue
+		}
+		reply, err := q.sendWithRetryCtx(context.Background(), send.address, send.input, send.args)
+		if err != nil || isTimeoutReply(reply) {
+			q.log().Warnf("Failed to replay %s after reconnecting", send.address)
+			continue
+		}
+		replayed++
+	}
+	return replayed, dropped, skipped
+}
+
+// existingCueIDs returns the uniqueIDs of every cue and cue list QLab has now
+func (q *Workspace) existingCueIDs() (map[string]bool, error) {
+	cueLists, err := q.getCueLists()
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]bool)
+	collectCueIDs(cueLists, ids)
+	return ids, nil
+}
+
+// collectCueIDs adds the uniqueIDs of cues and their children to ids
+func collectCueIDs(cues []any, ids map[string]bool) {
+	for _, cueData := range cues {
+		cue, ok := cueData.(map[string]any)
+		if !ok {
+			continue
+		}
+		if uniqueID, ok := cue["uniqueID"].(string); ok {
+			ids[uniqueID] = true
+		}
+		if children, ok := cue["cues"].([]any); ok {
+			collectCueIDs(children, ids)
+		}
+	}
+}
+
+// isTimeoutReply reports whether a reply stands in for one that never arrived
+func isTimeoutReply(reply []any) bool {
+	return len(reply) == 1 && reply[0] == timeoutReply
+}
+
+// finishReconnect ends the reconnect loop and reports how it ended
+func (q *Workspace) finishReconnect(event ReconnectEvent) {
+	q.reconnect.mu.Lock()
+	q.reconnect.active = false
+	q.reconnect.mu.Unlock()
+	if event.State == ReconnectSucceeded {
+		q.log().Infof("Reconnected to QLab after %d attempts", event.Attempt)
+	} else {
+		q.log().Warnf("Gave up reconnecting to QLab after %d attempts", event.Attempt)
+	}
+	q.emitReconnect(event)
+}
+
+// emitReconnect passes an event to the OnReconnect callback, if there is one
+func (q *Workspace) emitReconnect(event ReconnectEvent) {
+	q.reconnect.mu.Lock()
+	callback := q.reconnect.callback
+	q.reconnect.mu.Unlock()
+	if callback != nil {
+		callback(event)
+	}
+}
+
+// stopReconnect ends any reconnect loop, for Close
+func (q *Workspace) stopReconnect() {
+	q.reconnect.mu.Lock()
+	defer q.reconnect.mu.Unlock()
+	if q.reconnect.stop != nil {
+		close(q.reconnect.stop)
+		q.reconnect.stop = nil
+	}
+}
@@ -0,0 +1,124 @@
+package qlab
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestAutoReconnect tests that changes lost during an outage are replayed once the session resumes
+func TestAutoReconnect(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+	workspace.SetAutoReconnect(true)
+	workspace.SetReconnectPolicy(ReconnectPolicy{Interval: time.Second, MaxAttempts: 3})
+
+	events := make(chan ReconnectEvent, 10)
+	workspace.OnReconnect(func(event ReconnectEvent) { events <- event })
+
+	// Connect, then rename a cue the mock doesn't know yet, so both changes go unanswered
+	if _, err := workspace.queryVersion(); err != nil {
+		t.Fatalf("queryVersion failed: %v", err)
+	}
+	rename := workspace.GetAddress("/cue_id/MOCK-CUE-1/name")
+	workspace.Send(rename, "Lost")
+	workspace.Send(rename, "Found")
+
+	select {
+	case event := <-events:
+		if event.State != ReconnectStarted || event.Reason != DisconnectTimeoutStorm {
+			t.Fatalf("Expected reconnecting to start after a timeout storm, got %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected reconnecting to start")
+	}
+	if !workspace.Reconnecting() {
+		t.Error("Expected Reconnecting to report the reconnect in progress")
+	}
+
+	// The cue appears before the session resumes, so the replayed changes land on it
+	if _, err := workspace.createCueWithoutTarget(map[string]any{"type": "memo"}, ""); err != nil {
+		t.Fatalf("Failed to create cue: %v", err)
+	}
+
+	select {
+	case event := <-events:
+		if event.State != ReconnectSucceeded || event.Attempt != 1 || event.Replayed != 2 {
+			t.Fatalf("Expected a first-attempt reconnect replaying 2 changes, got %+v", event)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Expected the reconnect to succeed")
+	}
+	if cue := mockServer.GetCue("MOCK-CUE-1"); cue == nil || cue.Name != "Found" {
+		t.Errorf("Expected the changes replayed in order, got %+v", cue)
+	}
+	if workspace.Reconnecting() {
+		t.Error("Expected the reconnect to be over")
+	}
+}
+
+// TestReconnectStoppedByClose tests that Close ends a reconnect that hasn't succeeded
+func TestReconnectStoppedByClose(t *testing.T) {
+	workspace := &Workspace{workspace_id: "WS"}
+	workspace.SetAutoReconnect(true)
+	workspace.SetReconnectPolicy(ReconnectPolicy{Interval: time.Hour})
+	events := make(chan ReconnectEvent, 10)
+	workspace.OnReconnect(func(event ReconnectEvent) { events <- event })
+
+	workspace.startReconnect(DisconnectBadPasscode)
+	if workspace.Reconnecting() {
+		t.Fatal("Expected a rejected passcode not to be retried")
+	}
+
+	workspace.startReconnect(DisconnectNetworkUnreachable)
+	<-events
+	workspace.stopReconnect()
+	select {
+	case event := <-events:
+		if event.State != ReconnectGaveUp || !errors.Is(event.Err, ErrReconnectStopped) {
+			t.Errorf("Expected the reconnect to give up, got %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected Close to end the reconnect")
+	}
+}
+
+// TestReplayOnlySafeChanges tests that only property sets on cues that still exist, outside
+// of transactions that didn't commit, are replayed after reconnecting
+func TestReplayOnlySafeChanges(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetAutoReconnect(true)
+
+	cueID, err := workspace.createCueWithoutTarget(map[string]any{"type": "memo", "name": "Original"}, "")
+	if err != nil {
+		t.Fatalf("Failed to create cue: %v", err)
+	}
+
+	// Creates, moves and deletions may have reached QLab before the outage
+	workspace.recordFailedSend(workspace.GetAddress("/new"), "memo", nil)
+	workspace.recordFailedSend(workspace.GetAddress("/delete_id/"+cueID), "", nil)
+	workspace.recordFailedSend(workspace.GetAddress("/move/"+cueID), "", []any{int32(0)})
+	workspace.recordFailedSend(workspace.GetAddress("/cue_id/"+cueID+"/name"), "", nil)
+	if len(workspace.reconnect.failed) != 0 {
+		t.Fatalf("Expected only property sets to be kept, got %+v", workspace.reconnect.failed)
+	}
+
+	tx, err := workspace.BeginTransaction()
+	if err != nil {
+		t.Fatalf("BeginTransaction failed: %v", err)
+	}
+	workspace.recordFailedSend(workspace.GetAddress("/cue_id/"+cueID+"/notes"), "Rolled back", nil)
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	workspace.recordFailedSend(workspace.GetAddress("/cue_id/"+cueID+"/name"), "Replayed", nil)
+	workspace.recordFailedSend(workspace.GetAddress("/cue_id/GONE/name"), "Deleted", nil)
+
+	replayed, dropped, skipped := workspace.replayFailedSends()
+	if replayed != 1 || dropped != 0 || skipped != 2 {
+		t.Errorf("Expected 1 change replayed and 2 skipped, got %d replayed, %d dropped, %d skipped", replayed, dropped, skipped)
+	}
+	if cue := mockServer.GetCue(cueID); cue == nil || cue.Name != "Replayed" || cue.Properties["notes"] != "" {
+		t.Errorf("Expected only the committed property set replayed, got %+v", cue)
+	}
+}
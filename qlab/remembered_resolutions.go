@@ -0,0 +1,231 @@
+package qlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// RememberedResolution is a conflict choice kept in the cache, so later syncs of the same
+// source file to the same workspace don't ask again
+type RememberedResolution struct {
+	Cue       string                   `json:"cue"`             // Cue number, or position key for unnumbered cues
+	Field     string                   `json:"field,omitempty"` // Field the choice is for, "" for the whole cue
+	Choice    ConflictResolutionChoice `json:"choice"`          // ChoiceUseSource or ChoiceKeepQLab
+	DecidedAt time.Time                `json:"decidedAt"`
+}
+
+func (r RememberedResolution) String() string {
+	choice := "use source"
+	if r.Choice == ChoiceKeepQLab {
+		choice = "keep QLab"
+	}
+	if r.Field == "" {
+		return fmt.Sprintf("always %s for cue %s", choice, r.Cue)
+	}
+	return fmt.Sprintf("always %s for cue %s %s", choice, r.Cue, r.Field)
+}
+
+// SetRememberResolutions turns remembering conflict choices on or off. When on, the choices
+// made for conflicts are kept in the cache, and later syncs resolve the conflicts they cover
+// without asking. Cues and fields kept from QLab also stay out of later updates that aren't
+// conflicts, so a sync after QLab's edit was kept doesn't overwrite it. Skipping a cue isn't
+// remembered.
+func (q *Workspace) SetRememberResolutions(enabled bool) {
+	q.rememberChoices = enabled
+}
+
+// RememberedResolutions returns the conflict choices remembered for a source file sent to
+// this workspace, ordered by cue and field
+func (q *Workspace) RememberedResolutions(filePath string) ([]RememberedResolution, error) {
+	_, _, meta, err := q.loadLatestCache(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return meta.Resolutions, nil
+}
+
+// ForgetResolutions forgets the conflict choices remembered for the given cues of a source
+// file, or every choice when no cues are given, and returns how many were forgotten. The
+// newest snapshot is saved again without them, like RestoreCacheSnapshot.
+func (q *Workspace) ForgetResolutions(filePath string, cueNumbers ...string) (int, error) {
+	store, err := q.cache()
+	if err != nil {
+		return 0, err
+	}
+	_, workspace, meta, err := q.loadLatestCache(filePath)
+	if err != nil {
+		return 0, err
+	}
+
+	kept := slices.DeleteFunc(slices.Clone(meta.Resolutions), func(r RememberedResolution) bool {
+		return len(cueNumbers) == 0 || slices.Contains(cueNumbers, r.Cue)
+	})
+	forgotten := len(meta.Resolutions) - len(kept)
+	if forgotten == 0 {
+		return 0, nil
+	}
+	meta.Resolutions = kept
+
+	workspace[cacheMetadataKey] = meta
+	data, err := json.MarshalIndent(workspace, "", "  ")
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal cache data: %v", err)
+	}
+	key, err := store.Save(q.cacheName(filePath), data)
+	if err != nil {
+		return 0, err
+	}
+	q.log().Infof("Forgot %d remembered conflict choices, saved as %s", forgotten, key)
+	return forgotten, nil
+}
+
+// applyRememberedResolutions resolves the conflicts a remembered choice covers and returns
+// the others. A conflict is covered by a choice for its cue, or by choices for every one of
+// its fields. Cues and fields remembered as kept from QLab are also left out of updates.
+func (q *Workspace) applyRememberedResolutions(comparison *ThreeWayComparison, conflicts []CueConflict) []CueConflict {
+	if !q.rememberChoices || len(comparison.Remembered) == 0 {
+		return conflicts
+	}
+	cueChoices := make(map[string]ConflictResolutionChoice)
+	fieldChoices := make(FieldResolutions)
+	for _, remembered := range comparison.Remembered {
+		if remembered.Field == "" {
+			cueChoices[remembered.Cue] = remembered.Choice
+			continue
+		}
+		if fieldChoices[remembered.Cue] == nil {
+			fieldChoices[remembered.Cue] = make(map[string]ConflictResolutionChoice)
+		}
+		fieldChoices[remembered.Cue][remembered.Field] = remembered.Choice
+	}
+
+	resolutions := make(map[string]ConflictResolutionChoice)
+	fieldResolutions := make(FieldResolutions)
+	var remaining []CueConflict
+	for _, conflict := range conflicts {
+		if choice, ok := cueChoices[conflict.CueNumber]; ok {
+			resolutions[conflict.CueNumber] = choice
+			continue
+		}
+		fields := conflict.Fields()
+		choices := fieldChoices[conflict.CueNumber]
+		covered := len(fields) > 0 && !slices.ContainsFunc(fields, func(field string) bool {
+			_, ok := choices[field]
+			return !ok
+		})
+		if !covered {
+			remaining = append(remaining, conflict)
+			continue
+		}
+		resolutions[conflict.CueNumber] = ChoicePerField
+		fieldResolutions[conflict.CueNumber] = make(map[string]ConflictResolutionChoice, len(fields))
+		for _, field := range fields {
+			fieldResolutions[conflict.CueNumber][field] = choices[field]
+		}
+	}
+	if resolved := len(conflicts) - len(remaining); resolved > 0 {
+		q.log().Infof("Resolved %d conflicts with remembered choices", resolved)
+	}
+	ApplyResolutions(comparison, resolutions)
+	ApplyFieldResolutions(comparison, fieldResolutions)
+
+	// Keep QLab's edits out of updates that aren't conflicts too
+	for cueNumber, result := range comparison.CueResults {
+		if _, resolved := resolutions[cueNumber]; resolved || (result.Action != "update" && result.Action != "delete") {
+			continue
+		}
+		if cueChoices[cueNumber] == ChoiceKeepQLab {
+			result.Action = "skip"
+			result.Reason = "Keeping QLab version, as remembered"
+			if comparison.QLabChosenCues == nil {
+				comparison.QLabChosenCues = make(map[string]bool)
+			}
+			comparison.QLabChosenCues[cueNumber] = true
+			continue
+		}
+		var kept []string
+		for field, choice := range fieldChoices[cueNumber] {
+			if choice == ChoiceKeepQLab {
+				kept = append(kept, field)
+			}
+		}
+		if len(kept) == 0 || result.Action != "update" {
+			continue
+		}
+		slices.Sort(kept)
+		result.KeptFields = append(result.KeptFields, kept...)
+		slices.Sort(result.KeptFields)
+		result.KeptFields = slices.Compact(result.KeptFields)
+		if comparison.QLabChosenFields == nil {
+			comparison.QLabChosenFields = make(map[string]map[string]bool)
+		}
+		if comparison.QLabChosenFields[cueNumber] == nil {
+			comparison.QLabChosenFields[cueNumber] = make(map[string]bool)
+		}
+		for _, field := range kept {
+			comparison.QLabChosenFields[cueNumber][field] = true
+		}
+
+		// Nothing is left to send when only kept fields changed
+		onlyKept := len(result.ModifiedFields) > 0
+		for field := range result.ModifiedFields {
+			onlyKept = onlyKept && slices.Contains(kept, field)
+		}
+		if onlyKept {
+			result.Action = "skip"
+			result.Reason = fmt.Sprintf("Keeping QLab's %s, as remembered", strings.Join(kept, ", "))
+		}
+	}
+	return remaining
+}
+
+// rememberedResolutions returns the conflict choices to write with a snapshot: the ones the
+// comparison started with, updated with the choices made in it when remembering is on. A
+// choice for a whole cue replaces the cue's field choices, and a field choice the cue's
+// whole-cue choice.
+func (q *Workspace) rememberedResolutions(comparison *ThreeWayComparison) []RememberedResolution {
+	if comparison == nil {
+		return nil
+	}
+	remembered := slices.Clone(comparison.Remembered)
+	if !q.rememberChoices {
+		return remembered
+	}
+
+	now := time.Now()
+	remember := func(cue, field string, choice ConflictResolutionChoice) {
+		if choice != ChoiceUseSource && choice != ChoiceKeepQLab {
+			return
+		}
+		// A choice remembered before keeps when it was made
+		if slices.ContainsFunc(remembered, func(r RememberedResolution) bool {
+			return r.Cue == cue && r.Field == field && r.Choice == choice
+		}) {
+			return
+		}
+		remembered = slices.DeleteFunc(remembered, func(r RememberedResolution) bool {
+			return r.Cue == cue && (field == "" || r.Field == "" || r.Field == field)
+		})
+		remembered = append(remembered, RememberedResolution{Cue: cue, Field: field, Choice: choice, DecidedAt: now})
+	}
+	for _, cue := range slices.Sorted(maps.Keys(comparison.Resolutions)) {
+		remember(cue, "", comparison.Resolutions[cue])
+	}
+	for _, cue := range slices.Sorted(maps.Keys(comparison.FieldResolutions)) {
+		for _, field := range slices.Sorted(maps.Keys(comparison.FieldResolutions[cue])) {
+			remember(cue, field, comparison.FieldResolutions[cue][field])
+		}
+	}
+
+	slices.SortFunc(remembered, func(a, b RememberedResolution) int {
+		if c := strings.Compare(a.Cue, b.Cue); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Field, b.Field)
+	})
+	return remembered
+}
@@ -0,0 +1,65 @@
+package qlab
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// TestRememberResolutions tests that a field kept from QLab stays kept on later syncs without
+// asking, and is sent again once the choice is forgotten
+func TestRememberResolutions(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+	workspace.SetCacheStore(NewMemoryCacheStore())
+	workspace.SetSkipInbox(true)
+	workspace.SetRememberResolutions(true)
+	path := filepath.Join(t.TempDir(), "show.json")
+
+	source := func(name, notes string) map[string]any {
+		return map[string]any{"cues": []any{
+			map[string]any{"type": "memo", "number": "1", "name": name, "notes": notes},
+		}}
+	}
+	if _, err := workspace.TransmitWorkspaceData(path, source("House open", "Check levels")); err != nil {
+		t.Fatalf("First transmit failed: %v", err)
+	}
+	uniqueID, _ := workspace.lookupCueNumber("1")
+	if err := workspace.setCueProperty(uniqueID, "notes", "Levels checked"); err != nil {
+		t.Fatalf("setCueProperty failed: %v", err)
+	}
+
+	keepNotes := FieldPolicyResolver{Fields: map[string]ConflictResolutionChoice{"notes": ChoiceKeepQLab}, Default: ChoiceUseSource}
+	if _, err := workspace.TransmitWorkspaceData(path, source("House open", "Check levels twice"), WithConflictResolver(keepNotes)); err != nil {
+		t.Fatalf("Second transmit failed: %v", err)
+	}
+	remembered, err := workspace.RememberedResolutions(path)
+	if err != nil || len(remembered) != 1 || remembered[0].String() != "always keep QLab for cue 1 notes" {
+		t.Fatalf("Expected QLab's notes remembered, got %v (%v)", remembered, err)
+	}
+
+	// The notes changed in the source again, but QLab's stay without asking
+	refuse := CallbackResolver(func(conflict CueConflict) (ConflictResolutionChoice, error) {
+		return "", errors.New("asked again")
+	})
+	if _, err := workspace.TransmitWorkspaceData(path, source("Walk-in", "Check levels thrice"), WithConflictResolver(refuse)); err != nil {
+		t.Fatalf("Third transmit failed: %v", err)
+	}
+	cue := mockServer.GetCue(uniqueID)
+	if cue == nil || cue.Name != "Walk-in" || cue.Properties["notes"] != "Levels checked" {
+		t.Errorf("Expected the new name and QLab's notes, got %+v", cue)
+	}
+
+	if forgotten, err := workspace.ForgetResolutions(path, "1"); err != nil || forgotten != 1 {
+		t.Fatalf("Expected one choice forgotten, got %d (%v)", forgotten, err)
+	}
+	if remembered, _ := workspace.RememberedResolutions(path); len(remembered) != 0 {
+		t.Errorf("Expected nothing remembered, got %v", remembered)
+	}
+	if _, err := workspace.TransmitWorkspaceData(path, source("Walk-in", "Check levels once more"), WithConflictResolver(refuse)); err != nil {
+		t.Fatalf("Fourth transmit failed: %v", err)
+	}
+	if cue := mockServer.GetCue(uniqueID); cue == nil || cue.Properties["notes"] != "Check levels once more" {
+		t.Errorf("Expected the source notes sent once forgotten, got %+v", cue)
+	}
+}
@@ -0,0 +1,134 @@
+package qlab
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// RenumberedCue is a cue given a new number by RenumberCues
+type RenumberedCue struct {
+	UniqueID  string
+	OldNumber string
+	NewNumber string
+}
+
+// RenumberCues numbers the cues of a cue list in order as start, start+increment, and so
+// on, the way QLab's Renumber command does after cues are inserted. The cue list is given
+// by uniqueID or name. Cues inside groups are numbered in the order they appear; cues
+// without a number are left unnumbered. A new number held by a cue outside the list is a
+// *CueNumberConflictError, returned before anything is changed, unless SetForceCueNumbers
+// is on, in which case that cue's number is cleared. The cues that were renumbered are
+// returned.
+func (q *Workspace) RenumberCues(cueListID string, start, increment float64) ([]RenumberedCue, error) {
+	if increment <= 0 {
+		return nil, fmt.Errorf("renumber increment must be positive, got %g", increment)
+	}
+
+	if err := q.checkWritable("renumber"); err != nil {
+		return nil, err
+	}
+
+	q.opMu.Lock()
+	defer q.opMu.Unlock()
+	defer q.InvalidateCueCache()
+
+	// Renumber against QLab's current numbers, not whatever the indexes last saw
+	if _, err := q.ValidateIndexes(); err != nil {
+		return nil, fmt.Errorf("failed to index cue numbers: %w", err)
+	}
+	data, err := q.getCueLists()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cue lists: %w", err)
+	}
+	cueList := findCueList(data, cueListID)
+	if cueList == nil {
+		return nil, fmt.Errorf("cue list %q not found", cueListID)
+	}
+
+	var cues []RenumberedCue
+	var collect func(children []any)
+	collect = func(children []any) {
+		for _, child := range children {
+			cue, ok := child.(map[string]any)
+			if !ok {
+				continue
+			}
+			uniqueID, _ := cue["uniqueID"].(string)
+			if number := q.normalizeProperty(cue["number"]); uniqueID != "" && number != "" {
+				cues = append(cues, RenumberedCue{UniqueID: uniqueID, OldNumber: number, NewNumber: renumberedNumber(start, increment, len(cues))})
+			}
+			if nested, ok := cue["cues"].([]any); ok {
+				collect(nested)
+			}
+		}
+	}
+	nested, _ := cueList["cues"].([]any)
+	collect(nested)
+
+	renumbering := make(map[string]bool, len(cues))
+	for _, cue := range cues {
+		renumbering[cue.UniqueID] = true
+	}
+
+	// Check every new number against cues outside the list before changing anything
+	var changed []RenumberedCue
+	for _, cue := range cues {
+		if cue.NewNumber == cue.OldNumber {
+			continue
+		}
+		changed = append(changed, cue)
+		if existingID, ok := q.lookupCueNumber(cue.NewNumber); ok && !renumbering[existingID] && !q.forceCueNumbers {
+			return nil, &CueNumberConflictError{CueNumber: cue.NewNumber, ExistingID: existingID, NewCueID: cue.UniqueID}
+		}
+	}
+
+	// Clear the numbers another renumbered cue is about to take, so no two cues share one
+	newNumbers := make(map[string]bool, len(changed))
+	for _, cue := range changed {
+		newNumbers[cue.NewNumber] = true
+	}
+	for _, cue := range changed {
+		if !newNumbers[cue.OldNumber] {
+			continue
+		}
+		if err := q.clearCueNumber(cue.UniqueID); err != nil {
+			return nil, fmt.Errorf("failed to clear number %s of cue %s: %w", cue.OldNumber, cue.UniqueID, err)
+		}
+		q.forgetCueNumber(cue.OldNumber)
+	}
+
+	for i, cue := range changed {
+		if err := q.setCueProperty(cue.UniqueID, "number", cue.NewNumber); err != nil {
+			return changed[:i], fmt.Errorf("failed to renumber cue %s from %s to %s: %w", cue.UniqueID, cue.OldNumber, cue.NewNumber, err)
+		}
+		// A number no cue takes over is free now
+		if !newNumbers[cue.OldNumber] {
+			q.forgetCueNumber(cue.OldNumber)
+		}
+	}
+
+	q.log().Infof("Renumbered %d of %d cues in cue list %s", len(changed), len(cues), cueListID)
+	return changed, nil
+}
+
+// findCueList returns the cue list in /cueLists data with a uniqueID or name
+func findCueList(data []any, cueListID string) map[string]any {
+	for _, cueListData := range data {
+		cueList, ok := cueListData.(map[string]any)
+		if !ok {
+			continue
+		}
+		if cueList["uniqueID"] == cueListID || cueList["name"] == cueListID {
+			return cueList
+		}
+	}
+	return nil
+}
+
+// renumberedNumber returns the i-th number of a renumbering, rounded so repeated decimal
+// increments don't show float error
+func renumberedNumber(start, increment float64, i int) string {
+	number := math.Round((start+increment*float64(i))*1e6) / 1e6
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
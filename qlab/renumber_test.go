@@ -0,0 +1,63 @@
+package qlab
+
+import "testing"
+
+// TestRenumberCues tests that renumbering after an insertion shifts cues onto each
+// other's numbers without a collision and keeps the number index current
+func TestRenumberCues(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+
+	var ids []string
+	for _, number := range []string{"1", "1.5", "2", "3", ""} {
+		cueID, err := workspace.createCue(map[string]any{"type": "memo", "name": "Cue " + number}, number)
+		if err != nil {
+			t.Fatalf("createCue failed: %v", err)
+		}
+		ids = append(ids, cueID)
+	}
+
+	renumbered, err := workspace.RenumberCues("main-cue-list", 1, 1)
+	if err != nil {
+		t.Fatalf("RenumberCues failed: %v", err)
+	}
+	if len(renumbered) != 3 {
+		t.Errorf("Expected 3 cues renumbered, got %v", renumbered)
+	}
+
+	for i, want := range []string{"1", "2", "3", "4", ""} {
+		if got := mockServer.GetCue(ids[i]).Number; got != want {
+			t.Errorf("Expected cue %d numbered %q, got %q", i, want, got)
+		}
+	}
+	// Cue 2 was cleared before 1.5 took its number
+	var numbers []any
+	for _, msg := range mockServer.GetReceivedMessages() {
+		if msg.Address == workspace.addressBuilder.BuildCuePropertyAddress(ids[2], "number") {
+			numbers = append(numbers, msg.Arguments...)
+		}
+	}
+	if len(numbers) != 3 || numbers[1] != "" || numbers[2] != "3" {
+		t.Errorf("Expected cue 2 cleared before it was renumbered, got %v", numbers)
+	}
+	if cueID, ok := workspace.lookupCueNumber("4"); !ok || cueID != ids[3] {
+		t.Errorf("Expected the index to follow the new numbers, got %q", cueID)
+	}
+	if _, ok := workspace.lookupCueNumber("1.5"); ok {
+		t.Error("Expected the old number 1.5 dropped from the index")
+	}
+
+	renumbered, err = workspace.RenumberCues("Main Cue List", 10, 0.1)
+	if err != nil {
+		t.Fatalf("RenumberCues by name failed: %v", err)
+	}
+	if len(renumbered) != 4 || renumbered[3].NewNumber != "10.3" {
+		t.Errorf("Expected decimal numbers without float error, got %v", renumbered)
+	}
+
+	if _, err := workspace.RenumberCues("main-cue-list", 1, 0); err == nil {
+		t.Error("Expected a zero increment to fail")
+	}
+	if _, err := workspace.RenumberCues("missing", 1, 1); err == nil {
+		t.Error("Expected an unknown cue list to fail")
+	}
+}
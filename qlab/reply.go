@@ -0,0 +1,83 @@
+package qlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Reply is QLab's reply to a message sent with Query
+type Reply struct {
+	Address     string          `json:"address"`
+	Status      string          `json:"status"`                 // "ok", "error" or "denied"
+	Data        json.RawMessage `json:"data,omitempty"`         // The reply's data as QLab sent it, nil if none
+	WorkspaceID string          `json:"workspace_id,omitempty"` // Set on workspace-scoped replies
+}
+
+// OK reports whether QLab accepted the message
+func (r *Reply) OK() bool {
+	return r.Status == "ok"
+}
+
+// Decode unmarshals the reply's data into v
+func (r *Reply) Decode(v any) error {
+	if len(r.Data) == 0 {
+		return fmt.Errorf("reply to %s has no data", r.Address)
+	}
+	if err := json.Unmarshal(r.Data, v); err != nil {
+		return fmt.Errorf("failed to decode reply to %s: %w", r.Address, err)
+	}
+	return nil
+}
+
+// Query sends a message to any QLab address and returns the parsed reply, for endpoints
+// this package doesn't wrap. Addresses starting with /cue, /cue_id and the like are scoped
+// to the workspace as GetAddress does. A reply whose status isn't ok is returned along
+// with a *QLabError; an unanswered message returns an error wrapping ErrTimeout.
+func (q *Workspace) Query(address string, args ...any) (*Reply, error) {
+	return q.QueryCtx(context.Background(), address, args...)
+}
+
+// QueryCtx is Query with a context, which ends the wait for the reply when it's done
+func (q *Workspace) QueryCtx(ctx context.Context, address string, args ...any) (*Reply, error) {
+	address = q.GetAddress(address)
+	raw, err := q.SendCtx(ctx, address, args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseReply(address, raw)
+}
+
+// CueAddress returns the workspace-scoped address of a cue's method or property, e.g.
+// CueAddress(id, "name") for /workspace/{workspace}/cue_id/{id}/name
+func (q *Workspace) CueAddress(uniqueID, method string) string {
+	return q.GetAddress(fmt.Sprintf("/cue_id/%s/%s", uniqueID, method))
+}
+
+// parseReply turns the arguments of a reply message into a Reply
+func parseReply(address string, raw []any) (*Reply, error) {
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("no reply received for %s", address)
+	}
+	replyStr, ok := raw[0].(string)
+	if !ok {
+		// Not JSON; pass the argument through as the data
+		data, err := json.Marshal(raw[0])
+		if err != nil {
+			return nil, fmt.Errorf("unexpected reply to %s: %v", address, raw[0])
+		}
+		return &Reply{Address: address, Status: "ok", Data: data}, nil
+	}
+
+	reply := &Reply{}
+	if err := json.Unmarshal([]byte(replyStr), reply); err != nil {
+		return nil, fmt.Errorf("failed to parse reply to %s: %w", address, err)
+	}
+	if reply.Address == "" {
+		reply.Address = address
+	}
+	if !reply.OK() {
+		return reply, newQLabError(fmt.Sprintf("QLab rejected %s", address), replyStr)
+	}
+	return reply, nil
+}
@@ -0,0 +1,163 @@
+package qlab
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/hypebeast/go-osc/osc"
+)
+
+// ReplyStrategy is how QLab's UDP replies find the workspace's listener
+type ReplyStrategy string
+
+const (
+	ReplyNegotiate  ReplyStrategy = "negotiate" // Tell QLab the listener's port with /udpReplyPort (default)
+	ReplySourcePort ReplyStrategy = "source"    // Send from the listener's socket, for QLab to reply to the source port
+)
+
+// SetReplyStrategy sets how QLab's UDP replies reach the workspace. With ReplyNegotiate,
+// QLab is sent /udpReplyPort with the listener's port when the listener starts and on each
+// Init. With ReplySourcePort, messages are sent from the listener's own socket, so replies
+// sent back to where a message came from arrive on it. Set it before the first message.
+func (q *Workspace) SetReplyStrategy(strategy ReplyStrategy) {
+	q.replyStrategy = strategy
+}
+
+// SetReplyPort sets the UDP port replies and updates are received on. With 0, the default,
+// the QLab port plus one is used, or a free port the system picks if that's taken. Set it
+// before the first message.
+func (q *Workspace) SetReplyPort(port int) {
+	q.listenPort = port
+}
+
+// ReplyPort returns the UDP port the workspace receives replies on, 0 before its listener
+// starts and over TCP
+func (q *Workspace) ReplyPort() int {
+	q.serverMux.Lock()
+	defer q.serverMux.Unlock()
+	return packetConnPort(q.replyConn)
+}
+
+// startReplyListener starts the UDP listener replies and updates arrive on, unless it's
+// running or shared with a client, and tells QLab where to reply
+func (q *Workspace) startReplyListener() error {
+	q.serverMux.Lock()
+	if q.updateServer != nil {
+		q.serverMux.Unlock()
+		return nil
+	}
+	conn, err := listenForReplies(q.listenPort, q.port+1)
+	if err != nil {
+		q.serverMux.Unlock()
+		return err
+	}
+	d := osc.NewStandardDispatcher()
+	_ = d.AddMsgHandler("*", q.handleIncomingMessage)
+	server := &osc.Server{Dispatcher: &timetagDispatcher{dispatcher: d, clock: &q.qlabClock}}
+	q.updateServer = server
+	q.replyConn = conn
+	q.serverMux.Unlock()
+
+	go func() {
+		if err := servePackets(server, conn); err != nil && !errors.Is(err, net.ErrClosed) {
+			q.log().Errorf("OSC listener exited with error: %v", err)
+		}
+	}()
+	q.log().Infof("OSC listener started on port %d", packetConnPort(conn))
+	q.negotiateReplyPort()
+	return nil
+}
+
+// negotiateReplyPort sends /udpReplyPort with the listener's port, so QLab replies to it
+// rather than to the port each message was sent from
+func (q *Workspace) negotiateReplyPort() {
+	if q.useTCP || q.replyStrategy == ReplySourcePort {
+		return
+	}
+	port := q.ReplyPort()
+	if port == 0 {
+		return
+	}
+	if err := q.SendNoReply("/udpReplyPort", int32(port)); err != nil {
+		q.log().Warnf("Failed to set the reply port: %v", err)
+		return
+	}
+	q.log().Debugf("Asked QLab to reply to port %d", port)
+}
+
+// sourcePortConn returns the listener's socket when messages are to be sent from it, nil
+// otherwise
+func (q *Workspace) sourcePortConn() net.PacketConn {
+	if q.replyStrategy != ReplySourcePort {
+		return nil
+	}
+	q.serverMux.Lock()
+	defer q.serverMux.Unlock()
+	return q.replyConn
+}
+
+// sendFrom sends an encoded packet to QLab from conn
+func (q *Workspace) sendFrom(conn net.PacketConn, data []byte) error {
+	addr, err := net.ResolveUDPAddr("udp", net.JoinHostPort(q.host, fmt.Sprint(q.port)))
+	if err != nil {
+		return err
+	}
+	_, err = conn.WriteTo(data, addr)
+	return err
+}
+
+// servePackets reads OSC packets from a UDP socket and dispatches them until the socket is
+// closed. Replies are dispatched one at a time, in the order they arrive: the server's own
+// Serve dispatches each packet on a goroutine of its own, which can hand replies to the
+// same address to the requests waiting for them out of order. Updates still get a
+// goroutine each, so an update handler can query QLab without holding up the replies.
+func servePackets(server *osc.Server, conn net.PacketConn) error {
+	for {
+		packet, err := server.ReceivePacket(conn)
+		var netErr net.Error
+		switch {
+		case errors.As(err, &netErr):
+			return err
+		case err != nil:
+			continue // Not an OSC packet
+		}
+		if msg, ok := packet.(*osc.Message); ok && strings.HasPrefix(msg.Address, "/update") {
+			go server.Dispatcher.Dispatch(packet)
+			continue
+		}
+		server.Dispatcher.Dispatch(packet)
+	}
+}
+
+// listenForReplies binds a UDP socket on every interface to port, or if port is 0 to
+// preferred, falling back to a free port when preferred is taken
+func listenForReplies(port, preferred int) (net.PacketConn, error) {
+	if port != 0 {
+		conn, err := net.ListenPacket("udp", fmt.Sprintf(":%d", port))
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen for replies on port %d: %w", port, err)
+		}
+		return conn, nil
+	}
+	if conn, err := net.ListenPacket("udp", fmt.Sprintf(":%d", preferred)); err == nil {
+		return conn, nil
+	}
+	conn, err := net.ListenPacket("udp", ":0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for replies: %w", err)
+	}
+	return conn, nil
+}
+
+// packetConnPort returns the local port of a socket, 0 for nil
+func packetConnPort(conn net.PacketConn) int {
+	if conn == nil {
+		return 0
+	}
+	if addr, ok := conn.LocalAddr().(*net.UDPAddr); ok {
+		return addr.Port
+	}
+	return 0
+}
@@ -0,0 +1,77 @@
+package qlab
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+)
+
+// TestReplyPort tests that replies reach a workspace whose usual reply port is taken, by
+// negotiating the port or by sending from the listener's socket
+func TestReplyPort(t *testing.T) {
+	fixedPort, err := getFreePort()
+	if err != nil {
+		t.Fatalf("Failed to get free port: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		strategy   ReplyStrategy
+		port       int
+		toSource   bool // Whether the mock replies to the source port, as QLab does before /udpReplyPort
+		negotiated bool
+	}{
+		{name: "negotiate", strategy: ReplyNegotiate, negotiated: true},
+		{name: "negotiate fixed port", strategy: ReplyNegotiate, port: fixedPort, toSource: true, negotiated: true},
+		{name: "source port", strategy: ReplySourcePort, toSource: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port, err := getFreePort()
+			if err != nil {
+				t.Fatalf("Failed to get free port: %v", err)
+			}
+			// Something else holds the port replies used to be guessed at
+			taken, err := net.ListenPacket("udp", fmt.Sprintf(":%d", port+1))
+			if err != nil {
+				t.Fatalf("Failed to take port %d: %v", port+1, err)
+			}
+			defer taken.Close()
+
+			mockServer := NewMockOSCServer("localhost", port)
+			mockServer.SetReplyToSource(tt.toSource)
+			if err := mockServer.Start(); err != nil {
+				t.Fatalf("Failed to start mock server: %v", err)
+			}
+			workspace := NewWorkspace("localhost", port)
+			workspace.SetTimeout(1)
+			workspace.SetSkipInbox(true)
+			workspace.SetReplyStrategy(tt.strategy)
+			workspace.SetReplyPort(tt.port)
+			defer func() {
+				workspace.Close()
+				_ = mockServer.Stop()
+				time.Sleep(150 * time.Millisecond)
+			}()
+
+			if _, err := workspace.Init(""); err != nil {
+				t.Fatalf("Init failed: %v", err)
+			}
+			replyPort := workspace.ReplyPort()
+			if replyPort == 0 || replyPort == port+1 || (tt.port != 0 && replyPort != tt.port) {
+				t.Errorf("Expected a reply port other than %d, got %d", port+1, replyPort)
+			}
+			negotiated := mockServer.GetMessagesForAddress("/udpReplyPort")
+			if got := len(negotiated) > 0; got != tt.negotiated {
+				t.Fatalf("Expected /udpReplyPort sent: %t, got %d messages", tt.negotiated, len(negotiated))
+			}
+			if tt.negotiated && mockServer.ReplyPort() != replyPort {
+				t.Errorf("Expected QLab to reply to port %d, got %d", replyPort, mockServer.ReplyPort())
+			}
+			if version, err := workspace.queryVersion(); err != nil || version != "5.4.1" {
+				t.Errorf("Expected the version reply, got %q, %v", version, err)
+			}
+		})
+	}
+}
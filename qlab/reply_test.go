@@ -0,0 +1,142 @@
+package qlab
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hypebeast/go-osc/osc"
+)
+
+// TestQuery tests raw queries against application and workspace addresses
+func TestQuery(t *testing.T) {
+	workspace, _ := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+
+	reply, err := workspace.Query("/version")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	var version string
+	if err := reply.Decode(&version); err != nil || version != "5.4.1" || !reply.OK() {
+		t.Errorf("Expected version 5.4.1, got %q (%v) from %+v", version, err, reply)
+	}
+
+	id, err := workspace.createCueWithoutTarget(map[string]any{"type": "memo", "name": "Preshow"}, "")
+	if err != nil {
+		t.Fatalf("Failed to create cue: %v", err)
+	}
+	address := workspace.CueAddress(id, "name")
+	if want := "/workspace/" + workspace.WorkspaceID() + "/cue_id/" + id + "/name"; address != want {
+		t.Errorf("Expected %s, got %s", want, address)
+	}
+	if _, err := workspace.Query("/cue_id/"+id+"/name", "Walk-in"); err != nil {
+		t.Fatalf("Query failed to set the name: %v", err)
+	}
+	reply, err = workspace.Query(address)
+	var name string
+	if err != nil || reply.Decode(&name) != nil || name != "Walk-in" {
+		t.Errorf("Expected the new name, got %q from %+v (%v)", name, reply, err)
+	}
+
+	if _, err := workspace.Query("/noSuchCommand"); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected an unanswered query to time out, got %v", err)
+	}
+}
+
+// TestParseReply tests the replies Query turns into errors
+func TestParseReply(t *testing.T) {
+	reply, err := parseReply("/workspace/WS/connect", []any{`{"address": "/workspace/WS/connect", "status": "denied"}`})
+	var qlabErr *QLabError
+	if !errors.As(err, &qlabErr) || !errors.Is(err, ErrAuthFailed) || reply == nil || reply.Status != "denied" {
+		t.Errorf("Expected a denied reply with an auth error, got %+v, %v", reply, err)
+	}
+
+	reply, err = parseReply("/thump", []any{int32(1)})
+	if err != nil || string(reply.Data) != "1" {
+		t.Errorf("Expected a non-JSON reply passed through as data, got %+v, %v", reply, err)
+	}
+	if err := (&Reply{Address: "/x"}).Decode(new(string)); err == nil {
+		t.Error("Expected decoding a reply without data to fail")
+	}
+}
+
+// TestReplyCorrelation tests that replies to the same address go to the requests waiting
+// for them in the order they were sent, and that messages never sent are skipped
+func TestReplyCorrelation(t *testing.T) {
+	workspace := newPayloadTestWorkspace()
+	workspace.useTCP = true
+
+	address := "/workspace/test-workspace/cue_id/A/name"
+	replyAddress := workspace.addressBuilder.BuildReplyAddress(address)
+	replies := make([]chan []any, 3)
+	for i := range replies {
+		replies[i] = make(chan []any, 1)
+		workspace.ListenForReply(address, replies[i], i+1)
+	}
+	workspace.removeReplyHandler(address, 2)
+
+	for _, data := range []string{"first", "second"} {
+		if !workspace.deliverReply(osc.NewMessage(replyAddress, data)) {
+			t.Fatalf("Expected a handler for reply %q", data)
+		}
+	}
+	for i, want := range map[int]string{0: "first", 2: "second"} {
+		if got := <-replies[i]; len(got) != 1 || got[0] != want {
+			t.Errorf("Expected request %d to get %q, got %v", i+1, want, got)
+		}
+	}
+	if len(replies[1]) != 0 {
+		t.Errorf("Expected the abandoned request to get no reply")
+	}
+	if workspace.deliverReply(osc.NewMessage(replyAddress, "third")) {
+		t.Errorf("Expected no handler left for a third reply")
+	}
+	if count := workspace.pendingReplyCount(); count != 0 {
+		t.Errorf("Expected no pending replies, got %d", count)
+	}
+}
+
+// TestLateReplyDropped tests that the reply to a message whose request stopped waiting is
+// dropped instead of going to the next request, until it's taken to be lost
+func TestLateReplyDropped(t *testing.T) {
+	workspace := newPayloadTestWorkspace()
+	workspace.useTCP = true
+
+	address := "/workspace/test-workspace/cue_id/A/name"
+	replyAddress := workspace.addressBuilder.BuildReplyAddress(address)
+	replies := make([]chan []any, 3)
+	for i := range replies {
+		replies[i] = make(chan []any, 1)
+		workspace.ListenForReply(address, replies[i], i+1)
+	}
+	workspace.settleReplies(address, time.Minute, 1)  // Timed out, its reply may still come
+	workspace.settleReplies(address, -time.Second, 2) // Timed out long ago, its reply was lost
+
+	for _, data := range []string{"late", "third"} {
+		if !workspace.deliverReply(osc.NewMessage(replyAddress, data)) {
+			t.Fatalf("Expected a handler for reply %q", data)
+		}
+	}
+	if len(replies[0]) != 0 || len(replies[1]) != 0 {
+		t.Error("Expected no reply passed to requests that stopped waiting")
+	}
+	if got := <-replies[2]; len(got) != 1 || got[0] != "third" {
+		t.Errorf("Expected the waiting request to get its own reply, got %v", got)
+	}
+	if count := workspace.pendingReplyCount(); count != 0 {
+		t.Errorf("Expected no pending replies, got %d", count)
+	}
+
+	// The reply dropped as a late one may have been the next message's, so that one isn't
+	// waited for as well once it goes unanswered
+	for i := 4; i <= 5; i++ {
+		workspace.ListenForReply(address, make(chan []any, 1), i)
+	}
+	workspace.settleReplies(address, time.Minute, 4)
+	workspace.deliverReply(osc.NewMessage(replyAddress, "fifth"))
+	workspace.settleReplies(address, time.Minute, 5)
+	if workspace.deliverReply(osc.NewMessage(replyAddress, "sixth")) {
+		t.Error("Expected no handler left once the shifted message stopped waiting")
+	}
+}
@@ -0,0 +1,194 @@
+package qlab
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// resolverTestComparison builds a comparison with an update pending for each cue
+func resolverTestComparison(cueNumbers ...string) (*ThreeWayComparison, []CueConflict) {
+	comparison := &ThreeWayComparison{CueResults: make(map[string]*CueChangeResult)}
+	var conflicts []CueConflict
+	for _, number := range cueNumbers {
+		comparison.CueResults[number] = &CueChangeResult{Action: "update", HasChanged: true}
+		conflicts = append(conflicts, CueConflict{CueNumber: number, ConflictType: ConflictThreeWayDivergence})
+	}
+	return comparison, conflicts
+}
+
+// TestPolicyResolvers tests that the fixed-policy resolvers apply one choice to every conflict
+func TestPolicyResolvers(t *testing.T) {
+	for resolver, action := range map[ConflictResolver]string{
+		AlwaysSource: "update",
+		AlwaysQLab:   "skip",
+		AlwaysSkip:   "skip",
+	} {
+		comparison, conflicts := resolverTestComparison("1", "2")
+		if err := applyResolver(resolver, conflicts, comparison); err != nil {
+			t.Fatalf("%v: applyResolver failed: %v", resolver, err)
+		}
+		for number, result := range comparison.CueResults {
+			if result.Action != action {
+				t.Errorf("%v: expected cue %s to %s, got %s", resolver, number, action, result.Action)
+			}
+		}
+		if keepQLab := resolver == AlwaysQLab; comparison.QLabChosenCues["1"] != keepQLab {
+			t.Errorf("%v: expected QLab chosen=%t for cue 1", resolver, keepQLab)
+		}
+	}
+}
+
+// TestCallbackResolver tests that a callback decides each conflict and that its errors stop resolution
+func TestCallbackResolver(t *testing.T) {
+	comparison, conflicts := resolverTestComparison("1", "2")
+	resolver := CallbackResolver(func(conflict CueConflict) (ConflictResolutionChoice, error) {
+		if conflict.CueNumber == "1" {
+			return ChoiceKeepQLab, nil
+		}
+		return ChoiceUseSource, nil
+	})
+	if err := applyResolver(resolver, conflicts, comparison); err != nil {
+		t.Fatalf("applyResolver failed: %v", err)
+	}
+	if comparison.CueResults["1"].Action != "skip" || comparison.CueResults["2"].Action != "update" {
+		t.Errorf("Expected cue 1 kept and cue 2 updated, got %s and %s", comparison.CueResults["1"].Action, comparison.CueResults["2"].Action)
+	}
+
+	errAbort := errors.New("operator cancelled")
+	failing := CallbackResolver(func(CueConflict) (ConflictResolutionChoice, error) { return "", errAbort })
+	if err := applyResolver(failing, conflicts, comparison); !errors.Is(err, errAbort) {
+		t.Errorf("Expected callback error, got %v", err)
+	}
+}
+
+// TestApplyResolverRejectsIncompleteResolutions tests that unresolved conflicts and unknown choices are errors
+func TestApplyResolverRejectsIncompleteResolutions(t *testing.T) {
+	comparison, conflicts := resolverTestComparison("1", "2")
+	partial := CallbackResolver(func(conflict CueConflict) (ConflictResolutionChoice, error) {
+		if conflict.CueNumber == "2" {
+			return "overwrite", nil
+		}
+		return ChoiceSkip, nil
+	})
+	err := applyResolver(partial, conflicts, comparison)
+	if err == nil || !strings.Contains(err.Error(), `invalid resolution "overwrite" for cue 2`) {
+		t.Errorf("Expected invalid resolution error, got %v", err)
+	}
+	if comparison.CueResults["1"].Action != "update" {
+		t.Error("Expected no resolutions to be applied when one is invalid")
+	}
+
+	err = applyResolver(mapResolver{"1": ChoiceSkip}, conflicts, comparison)
+	if err == nil || !strings.Contains(err.Error(), "cue 2 was left unresolved") {
+		t.Errorf("Expected unresolved conflict error, got %v", err)
+	}
+}
+
+// mapResolver returns fixed resolutions regardless of the conflicts
+type mapResolver map[string]ConflictResolutionChoice
+
+func (m mapResolver) ResolveConflicts([]CueConflict) (map[string]ConflictResolutionChoice, error) {
+	return m, nil
+}
+
+// TestFieldPolicyResolver tests per-field choices: fields kept from QLab are recorded and left
+// out of the update, and a cue with every field kept is skipped
+func TestFieldPolicyResolver(t *testing.T) {
+	comparison, conflicts := resolverTestComparison("1", "2", "3")
+	conflicts[0].FieldConflicts = map[string]*FieldConflict{"name": {}, "duration": {}}
+	conflicts[1].Properties = []string{"duration"}
+	conflicts[2].Properties = []string{"name"}
+	resolver := FieldPolicyResolver{Fields: map[string]ConflictResolutionChoice{"duration": ChoiceKeepQLab}, Default: ChoiceUseSource}
+
+	if err := applyResolver(resolver, conflicts, comparison); err != nil {
+		t.Fatalf("applyResolver failed: %v", err)
+	}
+	if result := comparison.CueResults["1"]; result.Action != "update" || !slices.Equal(result.KeptFields, []string{"duration"}) {
+		t.Errorf("Expected cue 1 updated without its duration, got %+v", result)
+	}
+	if !comparison.QLabChosenFields["1"]["duration"] || comparison.QLabChosenFields["1"]["name"] {
+		t.Errorf("Expected only cue 1's duration chosen from QLab, got %v", comparison.QLabChosenFields["1"])
+	}
+	if result := comparison.CueResults["2"]; result.Action != "skip" {
+		t.Errorf("Expected cue 2 skipped with every field kept, got %+v", result)
+	}
+	if result := comparison.CueResults["3"]; result.Action != "update" || result.KeptFields != nil {
+		t.Errorf("Expected cue 3 resolved with the default, got %+v", result)
+	}
+
+	err := applyResolver(mapResolver{"1": ChoicePerField, "2": ChoiceSkip, "3": ChoiceSkip}, conflicts, comparison)
+	if err == nil || !strings.Contains(err.Error(), "can't resolve cue 1 per field") {
+		t.Errorf("Expected a resolver without field choices to be refused, got %v", err)
+	}
+	partial := FieldPolicyResolver{Fields: map[string]ConflictResolutionChoice{"duration": ChoiceKeepQLab}, Default: ChoiceSkip}
+	err = applyResolver(partial, conflicts, comparison)
+	if err == nil || !strings.Contains(err.Error(), `invalid resolution "skip" for field name of cue 1`) {
+		t.Errorf("Expected skipping a field to be refused, got %v", err)
+	}
+}
+
+// TestInteractiveFieldResolutions tests that a response's field choices resolve the conflicts
+// they cover
+func TestInteractiveFieldResolutions(t *testing.T) {
+	comparison, conflicts := resolverTestComparison("1", "2")
+	conflicts[0].Properties = []string{"name", "notes"}
+	resolver := NewInteractiveResolver(func(request ConflictResolutionRequest) error {
+		return nil
+	})
+	resolver.SubmitResolution(ConflictResolutionResponse{
+		RequestID:        "conflict-req-2",
+		Resolutions:      map[string]ConflictResolutionChoice{"2": ChoiceKeepQLab},
+		FieldResolutions: FieldResolutions{"1": {"name": ChoiceUseSource, "notes": ChoiceKeepQLab}},
+	})
+	if err := applyResolver(resolver, conflicts, comparison); err != nil {
+		t.Fatalf("applyResolver failed: %v", err)
+	}
+	if result := comparison.CueResults["1"]; result.Action != "update" || !slices.Equal(result.KeptFields, []string{"notes"}) {
+		t.Errorf("Expected cue 1 updated without its notes, got %+v", result)
+	}
+	if !comparison.QLabChosenCues["2"] {
+		t.Error("Expected cue 2 kept from QLab")
+	}
+}
+
+// TestTransmitResolvesPerField tests that a cue resolved per field gets the fields taken from
+// source and keeps the ones kept from QLab
+func TestTransmitResolvesPerField(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+	workspace.SetCacheStore(NewMemoryCacheStore())
+	workspace.SetSkipInbox(true)
+	path := filepath.Join(t.TempDir(), "show.json")
+
+	source := func(name, notes string) map[string]any {
+		return map[string]any{"cues": []any{
+			map[string]any{"type": "memo", "number": "1", "name": name, "notes": notes},
+		}}
+	}
+	if _, err := workspace.TransmitWorkspaceData(path, source("House open", "Check levels")); err != nil {
+		t.Fatalf("First transmit failed: %v", err)
+	}
+	uniqueID, _ := workspace.lookupCueNumber("1")
+	if err := workspace.setCueProperty(uniqueID, "name", "Doors"); err != nil {
+		t.Fatalf("setCueProperty failed: %v", err)
+	}
+	if err := workspace.setCueProperty(uniqueID, "notes", "Levels checked"); err != nil {
+		t.Fatalf("setCueProperty failed: %v", err)
+	}
+
+	resolver := FieldPolicyResolver{Fields: map[string]ConflictResolutionChoice{"notes": ChoiceKeepQLab}, Default: ChoiceUseSource}
+	comparison, err := workspace.TransmitWorkspaceData(path, source("Walk-in", "Check levels twice"), WithConflictResolver(resolver))
+	if err != nil {
+		t.Fatalf("Second transmit failed: %v", err)
+	}
+	if !comparison.QLabChosenFields["1"]["notes"] {
+		t.Errorf("Expected the notes kept from QLab, got %+v", comparison.CueResults["1"])
+	}
+	cue := mockServer.GetCue(uniqueID)
+	if cue == nil || cue.Name != "Walk-in" || cue.Properties["notes"] != "Levels checked" {
+		t.Errorf("Expected the source name and QLab's notes, got %+v", cue)
+	}
+}
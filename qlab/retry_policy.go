@@ -0,0 +1,96 @@
+package qlab
+
+import (
+	"math"
+	"math/rand/v2"
+	"strings"
+	"time"
+)
+
+// Address classes for RetryPolicy overrides
+const (
+	RetryClassQuery    = "query"    // Messages that only read from QLab
+	RetryClassChange   = "change"   // Messages that create, change, move or delete cues
+	RetryClassPlayback = "playback" // Playback commands, selection and playheads
+)
+
+// defaultRetryDelay is the delay before a retry when a policy doesn't set one
+const defaultRetryDelay = 100 * time.Millisecond
+
+// RetryPolicy controls how messages that time out are retried. The delay before the
+// first retry is InitialDelay and grows by Multiplier with each retry up to MaxDelay;
+// Jitter randomizes each delay so clients that timed out together don't retry together.
+type RetryPolicy struct {
+	MaxRetries   int           // Retries after the first attempt
+	InitialDelay time.Duration // 0 uses 100ms
+	MaxDelay     time.Duration // 0 leaves the delay uncapped
+	Multiplier   float64       // Below 1 keeps the delay constant
+	Jitter       float64       // Fraction of each delay randomized, from 0 to 1
+
+	// Overrides replace the policy for some messages, keyed by an address suffix such as
+	// "/cueLists" or "/go", or by RetryClassQuery, RetryClassChange or RetryClassPlayback.
+	// The longest matching suffix wins over a class. Overrides of overrides are ignored.
+	Overrides map[string]RetryPolicy
+}
+
+// SetRetryPolicy sets how messages that time out are retried, replacing the fixed 100ms
+// delay between retries. SetMaxRetries afterwards changes the policy's MaxRetries.
+func (q *Workspace) SetRetryPolicy(policy RetryPolicy) {
+	q.retryPolicy = &policy
+	q.maxRetries = policy.MaxRetries
+}
+
+// retryPolicyFor returns the retry policy for a message
+func (q *Workspace) retryPolicyFor(address, input string, args []any) RetryPolicy {
+	if q.retryPolicy == nil {
+		return RetryPolicy{MaxRetries: q.maxRetries}
+	}
+	policy := *q.retryPolicy
+	if len(policy.Overrides) == 0 {
+		return policy
+	}
+
+	match := ""
+	for key := range policy.Overrides {
+		if strings.HasPrefix(key, "/") && strings.HasSuffix(address, key) && len(key) > len(match) {
+			match = key
+		}
+	}
+	if match == "" {
+		match = retryClass(address, input, args)
+	}
+	if override, ok := policy.Overrides[match]; ok {
+		return override
+	}
+	return policy
+}
+
+// retryClass returns the RetryPolicy address class of a message
+func retryClass(address, input string, args []any) string {
+	switch {
+	case !isMutation(address, input, args):
+		return RetryClassQuery
+	case requiredPermission(address) == "control":
+		return RetryClassPlayback
+	default:
+		return RetryClassChange
+	}
+}
+
+// delay returns how long to wait before a retry, counting retries from 1
+func (p RetryPolicy) delay(retry int) time.Duration {
+	delay := p.InitialDelay
+	if delay <= 0 {
+		delay = defaultRetryDelay
+	}
+	if p.Multiplier > 1 && retry > 1 {
+		delay = time.Duration(float64(delay) * math.Pow(p.Multiplier, float64(retry-1)))
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if jitter := min(max(p.Jitter, 0), 1); jitter > 0 {
+		delay -= time.Duration(jitter * rand.Float64() * float64(delay))
+	}
+	return delay
+}
@@ -0,0 +1,97 @@
+package qlab
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// retryCounter counts retries reported to Metrics
+type retryCounter struct{ retries atomic.Int32 }
+
+func (c *retryCounter) MessageSent(string)                  {}
+func (c *retryCounter) ReplyReceived(string, time.Duration) {}
+func (c *retryCounter) Timeout(string)                      {}
+func (c *retryCounter) Retry(string)                        { c.retries.Add(1) }
+
+// TestRetryPolicyDelay tests backoff growth, capping and jitter
+func TestRetryPolicyDelay(t *testing.T) {
+	if delay := (RetryPolicy{}).delay(3); delay != defaultRetryDelay {
+		t.Errorf("Expected the default delay to stay %v, got %v", defaultRetryDelay, delay)
+	}
+
+	backoff := RetryPolicy{InitialDelay: 100 * time.Millisecond, Multiplier: 2, MaxDelay: 300 * time.Millisecond}
+	for retry, expected := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 300 * time.Millisecond, 6: 300 * time.Millisecond} {
+		if delay := backoff.delay(retry); delay != expected {
+			t.Errorf("Expected retry %d after %v, got %v", retry, expected, delay)
+		}
+	}
+
+	jittered := RetryPolicy{InitialDelay: 100 * time.Millisecond, Jitter: 0.5}
+	for range 20 {
+		if delay := jittered.delay(1); delay < 50*time.Millisecond || delay > 100*time.Millisecond {
+			t.Fatalf("Expected a jittered delay between 50ms and 100ms, got %v", delay)
+		}
+	}
+}
+
+// TestRetryPolicyFor tests which policy applies to a message
+func TestRetryPolicyFor(t *testing.T) {
+	workspace := NewWorkspace("localhost", DefaultPort)
+	workspace.SetMaxRetries(2)
+	if policy := workspace.retryPolicyFor("/workspace/W/cueLists", "", nil); policy.MaxRetries != 2 {
+		t.Errorf("Expected SetMaxRetries to apply without a policy, got %d retries", policy.MaxRetries)
+	}
+
+	workspace.SetRetryPolicy(RetryPolicy{
+		MaxRetries: 1,
+		Overrides: map[string]RetryPolicy{
+			"/cueLists":        {MaxRetries: 5},
+			"/go":              {MaxRetries: 0},
+			RetryClassPlayback: {MaxRetries: 0},
+			RetryClassChange:   {MaxRetries: 3},
+		},
+	})
+	tests := []struct {
+		address string
+		input   string
+		retries int
+	}{
+		{"/workspace/W/cueLists", "", 5},
+		{"/workspace/W/go", "", 0},
+		{"/workspace/W/cue_id/C/start", "", 0},
+		{"/workspace/W/cue_id/C/name", "House open", 3},
+		{"/workspace/W/cue_id/C/name", "", 1},
+	}
+	for _, tt := range tests {
+		if policy := workspace.retryPolicyFor(tt.address, tt.input, nil); policy.MaxRetries != tt.retries {
+			t.Errorf("Expected %d retries for %s %q, got %d", tt.retries, tt.address, tt.input, policy.MaxRetries)
+		}
+	}
+
+	workspace.SetMaxRetries(4)
+	if policy := workspace.retryPolicyFor("/workspace/W/cue_id/C/name", "", nil); policy.MaxRetries != 4 {
+		t.Errorf("Expected SetMaxRetries to change the policy, got %d retries", policy.MaxRetries)
+	}
+}
+
+// TestRetryPolicyOverrideRetries tests that an unanswered message is retried as its
+// override says
+func TestRetryPolicyOverrideRetries(t *testing.T) {
+	workspace, _ := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+	counter := &retryCounter{}
+	workspace.SetMetrics(counter)
+	workspace.SetRetryPolicy(RetryPolicy{Overrides: map[string]RetryPolicy{
+		"/unanswered": {MaxRetries: 1, InitialDelay: 10 * time.Millisecond},
+	}})
+
+	// The mock doesn't answer this address
+	reply := workspace.Send(workspace.GetAddress("/unanswered"), "")
+	if !isTimeoutReply(reply) {
+		t.Fatalf("Expected a timeout reply, got %v", reply)
+	}
+	if retries := counter.retries.Load(); retries != 1 {
+		t.Errorf("Expected 1 retry, got %d", retries)
+	}
+}
@@ -0,0 +1,115 @@
+package qlab
+
+import (
+	"errors"
+	"slices"
+	"sort"
+)
+
+// SkipScope is returned by a WalkScopeFunc to leave out the children of the scope it was called for
+var SkipScope = errors.New("skip this scope")
+
+// WalkScopeFunc is called by Walk for each scope, with the scopes enclosing it outermost first
+type WalkScopeFunc func(scope *ScopeComparison, parents []*ScopeComparison) error
+
+// Walk visits the scope and then its children depth-first, in order. Returning SkipScope
+// from fn skips the children of that scope; any other error stops the walk and is returned.
+//
+// This API is stable.
+func (s *ScopeComparison) Walk(fn WalkScopeFunc) error {
+	err := s.walk(fn, nil)
+	if errors.Is(err, SkipScope) {
+		return nil
+	}
+	return err
+}
+
+func (s *ScopeComparison) walk(fn WalkScopeFunc, parents []*ScopeComparison) error {
+	if err := fn(s, parents); err != nil {
+		return err
+	}
+	parents = append(slices.Clip(parents), s)
+	for _, child := range s.ChildScopes {
+		if err := child.walk(fn, parents); err != nil && !errors.Is(err, SkipScope) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Find returns the scope with an identifier among the scope and its descendants, or nil
+func (s *ScopeComparison) Find(identifier string) *ScopeComparison {
+	var found *ScopeComparison
+	s.Walk(func(scope *ScopeComparison, _ []*ScopeComparison) error {
+		if scope.Identifier == identifier {
+			found = scope
+			return errFoundScope
+		}
+		return nil
+	})
+	return found
+}
+
+// errFoundScope stops Find's walk
+var errFoundScope = errors.New("found scope")
+
+// FieldNames returns the names of the scope's field changes, sorted
+func (s *ScopeComparison) FieldNames() []string {
+	names := make([]string, 0, len(s.FieldChanges))
+	for name := range s.FieldChanges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Nested returns a copy of a workspace scope with its cues arranged as a tree for display: a
+// cue list scope for each cue list QLab reported, holding its cues, and the cues of each
+// group or source cue list under that cue's scope. Cue list scopes have changes and
+// conflicts when a cue inside them does. The scope itself is left alone.
+//
+// This API is stable.
+func (s *ScopeComparison) Nested() *ScopeComparison {
+	root := *s
+	root.ChildScopes = nil
+
+	copies := make(map[string]*ScopeComparison, len(s.ChildScopes))
+	for _, child := range s.ChildScopes {
+		clone := *child
+		clone.ChildScopes = nil
+		copies[child.Identifier] = &clone
+	}
+
+	lists := make(map[string]*ScopeComparison)
+	for _, child := range s.ChildScopes {
+		scope := copies[child.Identifier]
+		parent := &root
+		if i := slices.IndexFunc(child.Ancestors, func(id string) bool { return copies[id] != nil }); i >= 0 {
+			parent = copies[child.Ancestors[i]]
+		} else if child.CueList != "" {
+			list, ok := lists[child.CueList]
+			if !ok {
+				list = &ScopeComparison{Scope: ScopeCueList, Identifier: child.CueList, ChangeType: "none", Resolved: true}
+				lists[child.CueList] = list
+				root.ChildScopes = append(root.ChildScopes, list)
+			}
+			parent = list
+		}
+		parent.ChildScopes = append(parent.ChildScopes, scope)
+	}
+
+	for _, list := range lists {
+		for _, child := range list.ChildScopes {
+			child.Walk(func(scope *ScopeComparison, _ []*ScopeComparison) error {
+				list.HasChanges = list.HasChanges || scope.HasChanges
+				list.ConflictExists = list.ConflictExists || scope.ConflictExists
+				list.Resolved = list.Resolved && (scope.Resolved || !scope.ConflictExists)
+				return nil
+			})
+		}
+		if list.HasChanges {
+			list.ChangeType = "update"
+		}
+	}
+	return &root
+}
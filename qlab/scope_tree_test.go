@@ -0,0 +1,90 @@
+package qlab
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// scopeTreeFixture is a flat workspace scope as PerformScopeBasedComparison returns it
+func scopeTreeFixture() *ScopeComparison {
+	return &ScopeComparison{Scope: ScopeWorkspace, Identifier: "workspace", HasChanges: true, ChildScopes: []*ScopeComparison{
+		{Scope: ScopeCue, Identifier: "1", CueList: "Main", ChangeType: "none"},
+		{Scope: ScopeCue, Identifier: "2", CueList: "Main", ChangeType: "none"},
+		{Scope: ScopeCue, Identifier: "2.1", CueList: "Main", Ancestors: []string{"2"}, HasChanges: true, ChangeType: "update", ConflictExists: true,
+			FieldChanges: map[string]*FieldConflict{
+				"name":  {FieldName: "name", SourceValue: "Thunder", CacheValue: "Rain", QLabValue: "Storm"},
+				"notes": {FieldName: "notes", SourceValue: "Loud", CacheValue: "", QLabValue: ""},
+			}},
+		{Scope: ScopeCue, Identifier: "10", CueList: "Preshow", ChangeType: "none"},
+	}}
+}
+
+// TestScopeNestedAndWalk tests arranging a flat scope into cue lists and groups and walking it
+func TestScopeNestedAndWalk(t *testing.T) {
+	flat := scopeTreeFixture()
+	tree := flat.Nested()
+	if len(flat.ChildScopes) != 4 || len(flat.ChildScopes[1].ChildScopes) != 0 {
+		t.Fatal("Expected the flat scope left alone")
+	}
+
+	var visited []string
+	err := tree.Walk(func(scope *ScopeComparison, parents []*ScopeComparison) error {
+		path := []string{}
+		for _, parent := range parents {
+			path = append(path, parent.Identifier)
+		}
+		visited = append(visited, strings.Join(append(path, scope.Identifier), "/"))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	expected := "workspace workspace/Main workspace/Main/1 workspace/Main/2 workspace/Main/2/2.1 workspace/Preshow workspace/Preshow/10"
+	if got := strings.Join(visited, " "); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+
+	main := tree.Find("Main")
+	if main == nil || main.Scope != ScopeCueList || !main.HasChanges || !main.ConflictExists || main.Resolved {
+		t.Errorf("Expected Main to carry the conflict inside it, got %+v", main)
+	}
+	if preshow := tree.Find("Preshow"); preshow == nil || preshow.HasChanges {
+		t.Errorf("Expected Preshow without changes, got %+v", preshow)
+	}
+	if names := tree.Find("2.1").FieldNames(); strings.Join(names, ",") != "name,notes" {
+		t.Errorf("Expected sorted field names, got %v", names)
+	}
+
+	var skipped []string
+	tree.Walk(func(scope *ScopeComparison, parents []*ScopeComparison) error {
+		skipped = append(skipped, scope.Identifier)
+		if scope.Scope == ScopeCueList {
+			return SkipScope
+		}
+		return nil
+	})
+	if got := strings.Join(skipped, " "); got != "workspace Main Preshow" {
+		t.Errorf("Expected SkipScope to leave out the cues, got %s", got)
+	}
+}
+
+// TestScopeComparisonJSON tests that the scope tree round-trips through JSON
+func TestScopeComparisonJSON(t *testing.T) {
+	data, err := json.Marshal(scopeTreeFixture().Nested())
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !strings.Contains(string(data), `"fieldChanges":{"name":{"fieldName":"name","sourceValue":"Thunder","cacheValue":"Rain","qlabValue":"Storm"}`) {
+		t.Errorf("Unexpected JSON: %s", data)
+	}
+
+	var decoded ScopeComparison
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	cue := decoded.Find("2.1")
+	if cue == nil || cue.FieldChanges["name"].QLabValue != "Storm" || cue.Ancestors[0] != "2" || decoded.Find("Main").Scope != ScopeCueList {
+		t.Errorf("Expected the tree decoded, got %+v", cue)
+	}
+}
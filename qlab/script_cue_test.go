@@ -0,0 +1,69 @@
+package qlab
+
+import (
+	"strings"
+	"testing"
+)
+
+// testScript is an AppleScript with indentation, blank lines, and a trailing newline
+const testScript = "tell application \"QLab\"\n\ttell front workspace\n\t\tgo\n\n\tend tell\nend tell\n"
+
+// TestScriptCue tests that script sources are sent and read back exactly and compared by
+// content hash
+func TestScriptCue(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+
+	cueData := map[string]any{"type": "script", "name": "Start show", "scriptSource": testScript}
+	cueID, err := workspace.createCueWithoutTarget(cueData, "20")
+	if err != nil {
+		t.Fatalf("createCueWithoutTarget failed: %v", err)
+	}
+	if got := mockServer.GetCue(cueID).Properties["scriptSource"]; got != testScript {
+		t.Errorf("Expected the script sent exactly, got %q", got)
+	}
+
+	cue, err := workspace.GetCueByID(cueID)
+	if err != nil {
+		t.Fatalf("GetCueByID failed: %v", err)
+	}
+	if cue.ScriptSource != testScript {
+		t.Errorf("Expected the script read back exactly, got %q", cue.ScriptSource)
+	}
+	var builder strings.Builder
+	writeCue(&builder, *cue, 0)
+	if !strings.Contains(builder.String(), `scriptSource: "tell application \"QLab\"\n\ttell front workspace\n`) {
+		t.Errorf("Expected the script written as one quoted string, got:\n%s", builder.String())
+	}
+
+	current := map[string]any{"type": "Script", "name": "Start show", "scriptSource": testScript}
+	if diffs := workspace.compareCuePropertiesDetailed(cueData, current); len(diffs) != 0 {
+		t.Errorf("Expected an identical script to match, got %v", diffs)
+	}
+	current["scriptSource"] = strings.Replace(testScript, "\t\tgo", "\t\tstop", 1)
+	diffs := workspace.compareCuePropertiesDetailed(cueData, current)
+	if diff := diffs["scriptSource"]; !strings.Contains(diff, "sha256:") || strings.Contains(diff, "tell") {
+		t.Errorf("Expected a changed script reported as hashes, got %v", diffs)
+	}
+	current["scriptSource"] = strings.TrimSuffix(testScript, "\n")
+	if diffs := workspace.compareCuePropertiesDetailed(cueData, current); diffs["scriptSource"] == "" {
+		t.Error("Expected a whitespace change to count as a change")
+	}
+	delete(current, "scriptSource")
+	if diffs := workspace.compareCuePropertiesDetailed(cueData, current); len(diffs) != 0 {
+		t.Errorf("Expected a script QLab didn't report not to be compared, got %v", diffs)
+	}
+}
+
+// TestScriptHash tests the hash and line count reported for scripts
+func TestScriptHash(t *testing.T) {
+	if got := scriptHash(""); got != "" {
+		t.Errorf("Expected no hash for an empty script, got %q", got)
+	}
+	if got := scriptHash(testScript); !strings.HasPrefix(got, "sha256:") || !strings.HasSuffix(got, "(6 lines)") {
+		t.Errorf("Expected a hash and 6 lines, got %q", got)
+	}
+	if got := scriptHash("beep"); !strings.HasSuffix(got, "(1 line)") {
+		t.Errorf("Expected 1 line, got %q", got)
+	}
+}
@@ -0,0 +1,111 @@
+package qlab
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestSetPreserveSelection tests the preserve selection option
+func TestSetPreserveSelection(t *testing.T) {
+	workspace := NewWorkspace("localhost", 53000)
+
+	if workspace.preserveSelection {
+		t.Error("Selection preservation should be disabled by default")
+	}
+
+	workspace.SetPreserveSelection(true)
+	if !workspace.preserveSelection {
+		t.Error("Expected selection preservation to be enabled")
+	}
+}
+
+// TestRestoreSelectionEmptySnapshot tests that restoring nothing sends no OSC messages
+func TestRestoreSelectionEmptySnapshot(t *testing.T) {
+	// A zero workspace has no OSC client, so any send would panic
+	workspace := &Workspace{}
+
+	if err := workspace.RestoreSelection(nil); err != nil {
+		t.Errorf("Expected nil snapshot to be a no-op, got %v", err)
+	}
+
+	snapshot := &SelectionSnapshot{Playheads: map[string]string{}}
+	if err := workspace.RestoreSelection(snapshot); err != nil {
+		t.Errorf("Expected empty snapshot to be a no-op, got %v", err)
+	}
+}
+
+// TestRecordSelectionRequiresWorkspace tests that recording needs a connected workspace
+func TestRecordSelectionRequiresWorkspace(t *testing.T) {
+	workspace := &Workspace{}
+
+	if _, err := workspace.RecordSelection(); err == nil {
+		t.Error("Expected error when workspace ID is missing")
+	}
+}
+
+// TestRecordAndRestoreSelection tests that the playhead and the first selected cue are put
+// back after a sync moves them
+func TestRecordAndRestoreSelection(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+
+	var cueIDs []string
+	for _, name := range []string{"First", "Second", "Third"} {
+		cueID, err := workspace.createCueWithoutTarget(map[string]any{"type": "memo", "name": name}, "")
+		if err != nil {
+			t.Fatalf("Failed to create cue %s: %v", name, err)
+		}
+		cueIDs = append(cueIDs, cueID)
+	}
+	mockServer.SetPlayhead("", cueIDs[1])
+	mockServer.SetSelection(cueIDs[0], cueIDs[1])
+
+	snapshot, err := workspace.RecordSelection()
+	if err != nil {
+		t.Fatalf("RecordSelection failed: %v", err)
+	}
+	if !slices.Equal(snapshot.SelectedCueIDs, cueIDs[:2]) {
+		t.Errorf("Expected both selected cues recorded, got %v", snapshot.SelectedCueIDs)
+	}
+	if snapshot.Playheads["main-cue-list"] != cueIDs[1] {
+		t.Errorf("Expected the main cue list's playhead recorded, got %v", snapshot.Playheads)
+	}
+
+	// A sync moves the selection and the playhead
+	if err := checkReplyStatus(workspace.Send(workspace.GetAddress("/select_id/"+cueIDs[2]), "")); err != nil {
+		t.Fatalf("Failed to select cue: %v", err)
+	}
+	mockServer.SetPlayhead("", cueIDs[2])
+
+	if err := workspace.RestoreSelection(snapshot); err != nil {
+		t.Fatalf("RestoreSelection failed: %v", err)
+	}
+	if selection := mockServer.GetSelection(); !slices.Equal(selection, cueIDs[:1]) {
+		t.Errorf("Expected the first selected cue selected again, got %v", selection)
+	}
+	if playhead, err := workspace.queryPlayheadID("main-cue-list"); err != nil || playhead != cueIDs[1] {
+		t.Errorf("Expected the playhead restored to %s, got %q (%v)", cueIDs[1], playhead, err)
+	}
+}
+
+// TestCheckReplyStatus tests reply status checking
+func TestCheckReplyStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   []any
+		wantErr bool
+	}{
+		{"ok status", []any{`{"status": "ok"}`}, false},
+		{"error status", []any{`{"status": "error", "error": "no such cue"}`}, true},
+		{"no reply", []any{}, true},
+		{"non-JSON reply", []any{"plain"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkReplyStatus(tt.reply)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkReplyStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
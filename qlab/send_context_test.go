@@ -0,0 +1,86 @@
+package qlab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hypebeast/go-osc/osc"
+)
+
+// TestSendCtxReturnsReply tests that SendCtx behaves like Send when the context stays live
+func TestSendCtxReturnsReply(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+
+	address := fmt.Sprintf("/workspace/%s/basePath", mockServer.GetWorkspaceID())
+	reply, err := workspace.SendCtx(context.Background(), address)
+	if err != nil {
+		t.Fatalf("SendCtx failed: %v", err)
+	}
+	if len(reply) == 0 {
+		t.Fatal("Expected a reply from mock server")
+	}
+}
+
+// TestSendCtxCancelled tests that an already-cancelled context never sends
+func TestSendCtxCancelled(t *testing.T) {
+	workspace := &Workspace{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	reply, err := workspace.SendCtx(ctx, "/workspace/test/go")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if reply != nil {
+		t.Errorf("Expected nil reply, got %v", reply)
+	}
+}
+
+// TestSendCtxDeadline tests that a per-call deadline shorter than the workspace timeout is honored
+func TestSendCtxDeadline(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(10)
+
+	// The mock server has no handler for this address so no reply will ever arrive
+	address := fmt.Sprintf("/workspace/%s/unhandled", mockServer.GetWorkspaceID())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := workspace.SendCtx(ctx, address)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("SendCtx took %v, expected to return near the context deadline", elapsed)
+	}
+
+	if remaining := workspace.pendingReplyCount(); remaining != 0 {
+		t.Errorf("Expected reply handler to be removed after cancellation, %d remain", remaining)
+	}
+}
+
+// TestDeliverReplyAfterWaitEnds tests that a reply arriving as its request stops waiting is
+// dropped instead of blocking the listener that delivers it
+func TestDeliverReplyAfterWaitEnds(t *testing.T) {
+	workspace := newPayloadTestWorkspace()
+	workspace.useTCP = true
+
+	address := "/workspace/test-workspace/cue_id/A/name"
+	workspace.ListenForReply(address, make(chan []any), 1) // Nobody receives from it
+
+	delivered := make(chan bool)
+	go func() {
+		delivered <- workspace.deliverReply(osc.NewMessage(workspace.addressBuilder.BuildReplyAddress(address), "late"))
+	}()
+	select {
+	case <-delivered:
+	case <-time.After(time.Second):
+		t.Fatal("Expected delivering a reply nobody waits for not to block")
+	}
+}
@@ -0,0 +1,178 @@
+package qlab
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SendStats counts OSC messages passing through the send queue
+type SendStats struct {
+	Queued   int // Messages waiting for an in-flight slot or the rate limit
+	InFlight int // Requests sent and awaiting a reply
+	Sent     int // Messages handed to the transport
+	Failed   int // Messages that could not be sent or were never answered
+}
+
+// sendLimiter paces outgoing messages and bounds how many requests await a reply.
+// The zero value sends without limits.
+type sendLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration // Minimum gap between messages, 0 for no rate limit
+	next     time.Time     // Earliest time the next message may go
+	slots    chan struct{} // In-flight slots, nil for no limit
+	stats    SendStats
+}
+
+// SetSendRate limits how many OSC messages per second are sent to QLab, so large syncs
+// don't flood it into dropping replies. Messages over the rate wait in a queue. Zero or
+// less sends as fast as possible.
+func (q *Workspace) SetSendRate(perSecond float64) {
+	q.sendLimits.mu.Lock()
+	defer q.sendLimits.mu.Unlock()
+	if perSecond <= 0 {
+		q.sendLimits.interval = 0
+		return
+	}
+	q.sendLimits.interval = time.Duration(float64(time.Second) / perSecond)
+}
+
+// SetMaxInFlight limits how many requests may await a reply at once; further requests
+// wait in a queue until one is answered or times out. Zero or less removes the limit.
+// Requests already in flight finish under the previous limit.
+func (q *Workspace) SetMaxInFlight(n int) {
+	q.sendLimits.mu.Lock()
+	defer q.sendLimits.mu.Unlock()
+	if n <= 0 {
+		q.sendLimits.slots = nil
+		return
+	}
+	q.sendLimits.slots = make(chan struct{}, n)
+}
+
+// SendStats returns the send queue counters
+func (q *Workspace) SendStats() SendStats {
+	q.sendLimits.mu.Lock()
+	defer q.sendLimits.mu.Unlock()
+	return q.sendLimits.stats
+}
+
+// acquire waits for an in-flight slot and returns the function that frees it
+func (l *sendLimiter) acquire(ctx context.Context) (func(), error) {
+	l.mu.Lock()
+	slots := l.slots
+	if slots == nil {
+		l.stats.InFlight++
+		l.mu.Unlock()
+		return l.releaser(nil), nil
+	}
+	l.stats.Queued++
+	l.mu.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+		l.mu.Lock()
+		l.stats.Queued--
+		l.stats.InFlight++
+		l.mu.Unlock()
+		return l.releaser(slots), nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.stats.Queued--
+		l.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// tryAcquire takes an in-flight slot if one is free, without waiting, and returns the
+// function that frees it
+func (l *sendLimiter) tryAcquire() (func(), bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.slots == nil {
+		l.stats.InFlight++
+		return l.releaser(nil), true
+	}
+	select {
+	case l.slots <- struct{}{}:
+		l.stats.InFlight++
+		return l.releaser(l.slots), true
+	default:
+		return nil, false
+	}
+}
+
+// maxInFlight returns how many requests may await a reply at once, 0 for no limit
+func (l *sendLimiter) maxInFlight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return cap(l.slots)
+}
+
+// releaser returns the function that frees an in-flight slot taken from slots
+func (l *sendLimiter) releaser(slots chan struct{}) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			if slots != nil {
+				<-slots
+			}
+			l.mu.Lock()
+			l.stats.InFlight--
+			l.mu.Unlock()
+		})
+	}
+}
+
+// pace waits until the rate limit allows another message
+func (l *sendLimiter) pace(ctx context.Context) error {
+	l.mu.Lock()
+	if l.interval == 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	wait := at.Sub(now)
+	if wait == 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	l.stats.Queued++
+	l.mu.Unlock()
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	var err error
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	l.mu.Lock()
+	l.stats.Queued--
+	l.mu.Unlock()
+	return err
+}
+
+// record counts a message as sent, or as failed when err is set
+func (l *sendLimiter) record(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err != nil {
+		l.stats.Failed++
+		return
+	}
+	l.stats.Sent++
+}
+
+// recordUnanswered counts a sent message that was never answered as failed
+func (l *sendLimiter) recordUnanswered() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stats.Failed++
+}
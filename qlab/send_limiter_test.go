@@ -0,0 +1,83 @@
+package qlab
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestSendRate tests that messages over the send rate wait their turn
+func TestSendRate(t *testing.T) {
+	workspace, _ := setupWorkspaceWithCleanup(t)
+	workspace.SetSendRate(50)
+	before := workspace.SendStats()
+
+	start := time.Now()
+	for range 6 {
+		if err := workspace.SendNoReply("/thump"); err != nil {
+			t.Fatalf("SendNoReply failed: %v", err)
+		}
+	}
+	// The first message goes at once, the other five 20ms apart
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("Expected 6 messages at 50/s to take at least 100ms, took %v", elapsed)
+	}
+	if sent := workspace.SendStats().Sent - before.Sent; sent != 6 {
+		t.Errorf("Expected 6 messages counted as sent, got %d", sent)
+	}
+
+	workspace.SetSendRate(0)
+	start = time.Now()
+	for range 6 {
+		_ = workspace.SendNoReply("/thump")
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("Expected unlimited sends to go at once, took %v", elapsed)
+	}
+}
+
+// TestMaxInFlight tests that requests over the in-flight limit queue until one is answered
+func TestMaxInFlight(t *testing.T) {
+	workspace, _ := setupWorkspaceWithCleanup(t)
+	workspace.SetMaxInFlight(1)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reply, err := workspace.SendCtx(context.Background(), "/workspace/"+workspace.WorkspaceID()+"/cueLists")
+			if err == nil && isTimeoutReply(reply) {
+				err = ErrTimeout
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Expected every queued request answered, got %v", err)
+		}
+	}
+	if stats := workspace.SendStats(); stats.InFlight != 0 || stats.Queued != 0 || stats.Failed != 0 {
+		t.Errorf("Expected an idle send queue without failures, got %+v", stats)
+	}
+
+	// A request cancelled while queued leaves the queue
+	release, err := workspace.sendLimits.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire failed: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := workspace.SendCtx(ctx, "/thump"); err != context.DeadlineExceeded {
+		t.Errorf("Expected the queued request to hit its deadline, got %v", err)
+	}
+	release()
+	if stats := workspace.SendStats(); stats.InFlight != 0 || stats.Queued != 0 {
+		t.Errorf("Expected an idle send queue, got %+v", stats)
+	}
+}
@@ -0,0 +1,74 @@
+package qlab
+
+import (
+	"testing"
+)
+
+// TestSettingsPatches tests listing, finding, and renaming patches and video stages
+func TestSettingsPatches(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	mockServer.SetPatches(PatchAudio, "Main", "Monitors")
+	mockServer.SetPatches(PatchStage, "Projector")
+	settings := workspace.Settings()
+
+	audio, err := settings.AudioPatches()
+	if err != nil || len(audio) != 2 || audio[1].Name != "Monitors" || audio[1].UniqueID != "MOCK-AUDIO-2" {
+		t.Fatalf("Expected two audio patches, got %v (%v)", audio, err)
+	}
+	if stages, err := settings.VideoStages(); err != nil || len(stages) != 1 || stages[0].Kind != PatchStage {
+		t.Errorf("Expected one video stage, got %v (%v)", stages, err)
+	}
+	if network, err := settings.NetworkPatches(); err != nil || len(network) != 0 {
+		t.Errorf("Expected no network patches, got %v (%v)", network, err)
+	}
+
+	if patch, err := settings.Patch(PatchAudio, "monitors"); err != nil || patch.UniqueID != "MOCK-AUDIO-2" {
+		t.Errorf("Expected to find the patch by name, got %v (%v)", patch, err)
+	}
+	if _, err := settings.Patch(PatchAudio, "Lobby"); err == nil {
+		t.Error("Expected an unknown patch to fail")
+	}
+
+	if err := settings.RenamePatch(PatchAudio, "MOCK-AUDIO-1", "Mains"); err != nil {
+		t.Fatalf("RenamePatch failed: %v", err)
+	}
+	if patch, err := settings.Patch(PatchAudio, "Mains"); err != nil || patch.UniqueID != "MOCK-AUDIO-1" {
+		t.Errorf("Expected the renamed patch after the cache refresh, got %v (%v)", patch, err)
+	}
+}
+
+// TestCuePatchNameResolution tests that patches named in cue data are set by ID
+func TestCuePatchNameResolution(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	mockServer.SetPatches(PatchAudio, "Main")
+	mockServer.SetPatches(PatchNetwork, "Console", "Eos")
+
+	audioID, err := workspace.createCueWithoutTarget(map[string]any{"type": "audio", "audioOutputPatchName": "main"}, "")
+	if err != nil {
+		t.Fatalf("Failed to create audio cue: %v", err)
+	}
+	if got := mockServer.GetCue(audioID).Properties["audioOutputPatchID"]; got != "MOCK-AUDIO-1" {
+		t.Errorf("Expected the audio patch set by ID, got %q", got)
+	}
+
+	networkData := map[string]any{"type": "network", "networkPatchName": "Eos", "customString": "/eos/go"}
+	networkID, err := workspace.createCueWithoutTarget(networkData, "")
+	if err != nil {
+		t.Fatalf("Failed to create network cue: %v", err)
+	}
+	cue := mockServer.GetCue(networkID)
+	if cue.Properties["networkPatchID"] != "MOCK-NETWORK-2" || cue.Properties["networkPatchName"] != "" {
+		t.Errorf("Expected only the network patch ID set, got %v", cue.Properties)
+	}
+	if networkData["networkPatchName"] != "Eos" {
+		t.Error("Expected the source cue data left unchanged")
+	}
+
+	count := mockServer.GetCueCount()
+	if _, err := workspace.createCueWithoutTarget(map[string]any{"type": "audio", "audioOutputPatchName": "Lobby"}, ""); err == nil {
+		t.Error("Expected an unknown patch name to fail")
+	}
+	if got := mockServer.GetCueCount(); got != count {
+		t.Errorf("Expected no cue created for an unknown patch, went from %d to %d cues", count, got)
+	}
+}
@@ -0,0 +1,105 @@
+package qlab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// shutdownState refuses new requests once a shutdown starts, and tracks the requests in
+// flight so the shutdown can wait for their replies
+type shutdownState struct {
+	mu       sync.Mutex
+	closing  bool
+	inFlight int
+	drained  chan struct{} // Closed once no request is in flight after closing
+}
+
+// begin counts a request in flight, reporting false if the workspace is shutting down
+func (s *shutdownState) begin() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closing {
+		return false
+	}
+	s.inFlight++
+	return true
+}
+
+// end counts a request as finished
+func (s *shutdownState) end() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inFlight--
+	if s.closing && s.inFlight == 0 {
+		close(s.drained)
+	}
+}
+
+// isClosing reports whether the workspace is shutting down
+func (s *shutdownState) isClosing() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closing
+}
+
+// close refuses new requests and returns a channel closed once those in flight finish
+func (s *shutdownState) close() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closing {
+		s.closing = true
+		s.drained = make(chan struct{})
+		if s.inFlight == 0 {
+			close(s.drained)
+		}
+	}
+	return s.drained
+}
+
+// pending returns the number of requests in flight
+func (s *shutdownState) pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inFlight
+}
+
+// Shutdown closes the workspace gracefully. New messages are refused at once with
+// ErrClosed; requests already sent are given until ctx is done to get their replies. The
+// workspace then unsubscribes from updates, sends /disconnect, and closes its listener and
+// TCP connection before returning. The errors of each step are joined; requests still in
+// flight when ctx ends are reported with ctx's error, and their replies are dropped. A
+// workspace that's shut down stays closed.
+func (q *Workspace) Shutdown(ctx context.Context) error {
+	q.stopReconnect()
+	q.StopHeartbeat()
+
+	var errs []error
+	select {
+	case <-q.shutdown.close():
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("%d requests still in flight: %w", q.shutdown.pending(), ctx.Err()))
+	}
+
+	if q.updateHandler != nil || q.pool != nil {
+		address := "/updates"
+		if q.pool != nil {
+			address = q.addressBuilder.GetWorkspacePrefix() + "/updates"
+		}
+		if err := q.sendNoReply(address, int32(0)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to unsubscribe from updates: %w", err))
+		}
+	}
+	if err := q.disconnect(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to disconnect: %w", err))
+	}
+	if err := q.closeTransports(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	q.log().Info("Workspace shut down", "workspace_id", q.workspace_id)
+	return nil
+}
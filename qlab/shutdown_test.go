@@ -0,0 +1,90 @@
+package qlab
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// TestShutdown tests that Shutdown waits for replies in flight, refuses new messages, and
+// gives up on a reply that doesn't come by the context's deadline
+func TestShutdown(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(5)
+
+	// A slow reply in flight is waited for
+	mockServer.SetFault("/version", MockFault{Delay: 300 * time.Millisecond})
+	done := make(chan error, 1)
+	go func() {
+		_, err := workspace.Query("/version")
+		done <- err
+	}()
+	for workspace.shutdown.pending() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := workspace.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected the query in flight answered, got %v", err)
+		}
+	default:
+		t.Error("Expected Shutdown to return after the query in flight")
+	}
+	if _, err := workspace.Query("/version"); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected a query after Shutdown refused, got %v", err)
+	}
+	if err := workspace.SendNoReply("/cue/1/go"); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected a message after Shutdown refused, got %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for len(mockServer.GetMessagesForAddress("/disconnect")) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected Shutdown to disconnect")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if workspace.ReplyPort() != 0 {
+		t.Error("Expected the listener closed")
+	}
+
+	// A reply that never comes is given up on at the deadline
+	other, otherServer := setupWorkspaceWithCleanup(t)
+	other.SetTimeout(5)
+	otherServer.SetFault("/version", MockFault{DropReplies: -1})
+	go func() { _, _ = other.Query("/version") }()
+	for other.shutdown.pending() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if err := other.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the deadline reported, got %v", err)
+	}
+}
+
+// TestCloseReleasesListener tests that Close has closed the reply listener by the time it
+// returns, so its port can be bound again at once
+func TestCloseReleasesListener(t *testing.T) {
+	workspace, _ := setupWorkspaceWithCleanup(t)
+	if _, err := workspace.Query("/version"); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	port := workspace.ReplyPort()
+	if port == 0 {
+		t.Fatal("Expected a reply listener")
+	}
+
+	workspace.Close()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{Port: port})
+	if err != nil {
+		t.Fatalf("Expected port %d free after Close: %v", port, err)
+	}
+	_ = conn.Close()
+}
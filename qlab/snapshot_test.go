@@ -0,0 +1,75 @@
+package qlab
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestSnapshotRoundTrip tests exporting a workspace and rebuilding it in an empty one
+func TestSnapshotRoundTrip(t *testing.T) {
+	source, mockServer := setupWorkspaceWithCleanup(t)
+	source.SetTimeout(3)
+
+	cues := []map[string]any{
+		{"type": "audio", "number": "1", "name": "Intro", "fileTarget": "music/intro.mp3"},
+		{"type": "start", "number": "2", "name": "Go Intro"},
+	}
+	for _, cue := range cues {
+		if _, err := source.createCueWithoutTarget(cue, cue["number"].(string)); err != nil {
+			t.Fatalf("Failed to create cue: %v", err)
+		}
+	}
+	if err := source.setCueProperty("MOCK-CUE-2", "cueTargetNumber", "1"); err != nil {
+		t.Fatalf("Failed to set target: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := source.ExportSnapshot(&buf); err != nil {
+		t.Fatalf("ExportSnapshot failed: %v", err)
+	}
+
+	var snapshot WorkspaceSnapshot
+	if err := json.Unmarshal(buf.Bytes(), &snapshot); err != nil {
+		t.Fatalf("Snapshot is not valid JSON: %v", err)
+	}
+	if snapshot.Version != SnapshotVersion || len(snapshot.CueLists) != 1 || len(snapshot.CueLists[0].Cues) != 2 {
+		t.Fatalf("Unexpected snapshot: %s", buf.String())
+	}
+	intro := snapshot.CueLists[0].Cues[0].(map[string]any)
+	if intro["type"] != "audio" || intro["fileTarget"] != "music/intro.mp3" {
+		t.Errorf("Expected the audio cue with its file target, got %v", intro)
+	}
+
+	// Rebuild into the emptied workspace
+	mockServer.Clear()
+	if err := source.ImportSnapshot(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatalf("ImportSnapshot failed: %v", err)
+	}
+	if got := mockServer.GetCueCount(); got != 2 {
+		t.Fatalf("Expected 2 rebuilt cues, got %d", got)
+	}
+	if cue := mockServer.GetCue("MOCK-CUE-1"); cue.FileTarget == "" || cue.Number != "1" {
+		t.Errorf("Expected cue 1 with its file target, got %+v", cue)
+	}
+	if cue := mockServer.GetCue("MOCK-CUE-2"); cue.CueTargetNumber != "1" {
+		t.Errorf("Expected cue 2 to target cue 1, got %q", cue.CueTargetNumber)
+	}
+
+	// The workspace has cues now, so it can't take the snapshot again
+	err := source.ImportSnapshot(bytes.NewReader(buf.Bytes()))
+	if !errors.Is(err, ErrWorkspaceNotEmpty) {
+		t.Errorf("Expected ErrWorkspaceNotEmpty, got %v", err)
+	}
+}
+
+// TestImportSnapshotVersion tests that snapshots from a newer schema are refused
+func TestImportSnapshotVersion(t *testing.T) {
+	workspace := NewWorkspace("localhost", 53000)
+	err := workspace.ImportSnapshot(strings.NewReader(`{"version": 99, "cueLists": []}`))
+	if err == nil || !strings.Contains(err.Error(), "unsupported snapshot version 99") {
+		t.Errorf("Expected an unsupported version error, got %v", err)
+	}
+}
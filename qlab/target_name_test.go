@@ -0,0 +1,129 @@
+package qlab
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestResolveTargetNameAmbiguity tests that a name matching several cues is refused
+func TestResolveTargetNameAmbiguity(t *testing.T) {
+	mapping := &CueMapping{NumberToID: make(map[string]string)}
+	mapping.recordCue("1", "Music", "id-1")
+	mapping.recordCue("2", "Blackout", "id-2")
+	mapping.recordCue("3", "Blackout", "id-3")
+	mapping.recordCue("3", "Blackout", "id-3") // Recording a cue twice doesn't make it ambiguous
+
+	target, err := mapping.ResolveTargetName("Music")
+	if err != nil || target.UniqueID != "id-1" || target.Number != "1" {
+		t.Errorf("Expected Music to resolve to cue 1, got %+v, %v", target, err)
+	}
+
+	_, err = mapping.ResolveTargetName("Blackout")
+	var nameErr *TargetNameError
+	if !errors.As(err, &nameErr) || len(nameErr.Candidates) != 2 {
+		t.Fatalf("Expected ambiguity error with two candidates, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("Expected ambiguous in error, got %q", err.Error())
+	}
+
+	_, err = mapping.ResolveTargetName("Missing")
+	if !errors.As(err, &nameErr) || len(nameErr.Candidates) != 0 {
+		t.Errorf("Expected not-found error, got %v", err)
+	}
+}
+
+// TestValidateTargetNames tests that unresolvable cueTargetName references are reported
+func TestValidateTargetNames(t *testing.T) {
+	workspace := &Workspace{}
+	workspaceData := map[string]any{
+		"cues": []any{
+			map[string]any{"type": "audio", "number": "1", "name": "Music"},
+			map[string]any{"type": "fade", "number": "2", "cueTargetName": "Music"},
+			map[string]any{"type": "memo", "number": "3", "name": "Note"},
+			map[string]any{"type": "memo", "number": "4", "name": "Note"},
+			map[string]any{"type": "start", "number": "5", "cueTargetName": "Note"},
+			map[string]any{"type": "stop", "number": "6", "cueTargetName": "Nothing"},
+			map[string]any{"type": "stop", "number": "7", "cueTargetName": "Nothing", "cueTargetNumber": "1"},
+		},
+	}
+
+	warnings := workspace.ValidateTargetNames(workspaceData)
+	if len(warnings) != 2 {
+		t.Fatalf("Expected 2 warnings, got %v", warnings)
+	}
+	if !strings.HasPrefix(warnings[0], "cue 5:") || !strings.Contains(warnings[0], "ambiguous") {
+		t.Errorf("Expected ambiguity warning for cue 5, got %q", warnings[0])
+	}
+	if !strings.HasPrefix(warnings[1], "cue 6:") || !strings.Contains(warnings[1], "no cue named") {
+		t.Errorf("Expected not-found warning for cue 6, got %q", warnings[1])
+	}
+}
+
+// TestSetCueTargetsByName tests that cueTargetName is resolved to the target's number when targets are set
+func TestSetCueTargetsByName(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+
+	mapping := &CueMapping{NumberToID: make(map[string]string)}
+	for _, cueData := range []map[string]any{
+		{"type": "audio", "number": "10", "name": "Music"},
+		{"type": "fade", "number": "11", "name": "Fade music", "cueTargetName": "Music"},
+	} {
+		if _, err := workspace.processCueListWithParentMappingAndChangeDetection(cueData, "", "", mapping, nil); err != nil {
+			t.Fatalf("processing cue failed: %v", err)
+		}
+	}
+
+	if len(mapping.CuesWithTargets) != 1 || mapping.CuesWithTargets[0].TargetName != "Music" {
+		t.Fatalf("Expected one name-based target, got %+v", mapping.CuesWithTargets)
+	}
+	if err := workspace.setCueTargets(mapping); err != nil {
+		t.Fatalf("setCueTargets failed: %v", err)
+	}
+
+	fade := mockServer.GetCue(mapping.NumberToID["11"])
+	if fade == nil || fade.CueTargetNumber != "10" {
+		t.Errorf("Expected fade to target cue 10, got %+v", fade)
+	}
+}
+
+// TestSecondPassTargets tests that every targeting cue type has its target set in the second
+// pass, falling back to the target's ID, and that other cue types are left alone
+func TestSecondPassTargets(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+
+	mapping := &CueMapping{NumberToID: make(map[string]string)}
+	for _, cueData := range []map[string]any{
+		{"type": "audio", "number": "1", "name": "Music"},
+		{"type": "pause", "number": "2", "cueTargetNumber": "1"},
+		{"type": "reset", "number": "3", "cueTargetNumber": "1"},
+		{"type": "goto", "number": "4", "cueTargetNumber": "1"},
+		{"type": "load", "number": "5", "cueTargetNumber": "1"},
+		{"type": "memo", "number": "6", "cueTargetNumber": "1"},
+		{"type": "stop", "number": "7", "cueTargetID": "MOCK-CUE-1"},
+	} {
+		if _, err := workspace.processCueListWithParentMappingAndChangeDetection(cueData, "", "", mapping, nil); err != nil {
+			t.Fatalf("processing cue %v failed: %v", cueData["number"], err)
+		}
+	}
+	if len(mapping.CuesWithTargets) != 5 {
+		t.Fatalf("Expected five targeting cues queued, got %+v", mapping.CuesWithTargets)
+	}
+
+	// Numbers that can't be set fall back to the ID of the cue created with that number
+	mockServer.SetFault("/cueTargetNumber", MockFault{Error: "busy"})
+	if err := workspace.setCueTargets(mapping); err != nil {
+		t.Fatalf("setCueTargets failed: %v", err)
+	}
+	musicID := mapping.NumberToID["1"]
+	for _, number := range []string{"2", "3", "4", "5", "7"} {
+		if cue := mockServer.GetCue(mapping.NumberToID[number]); cue == nil || cue.CueTargetID != musicID {
+			t.Errorf("Expected cue %s to target %s, got %+v", number, musicID, cue)
+		}
+	}
+	if cue := mockServer.GetCue(mapping.NumberToID["6"]); cue.CueTargetID != "" || cue.CueTargetNumber != "" {
+		t.Errorf("Expected the memo cue not to get a target, got %+v", cue)
+	}
+}
@@ -0,0 +1,99 @@
+package qlab
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestValidateTargets tests each kind of problem ValidateTargets reports for source data
+func TestValidateTargets(t *testing.T) {
+	workspaceData := map[string]any{"cues": []any{
+		map[string]any{"type": "audio", "number": "1", "name": "Preshow", "fileTarget": "preshow.wav"},
+		map[string]any{"type": "audio", "number": "2", "name": "Thunder"},
+		map[string]any{"type": "fade", "number": "3", "cueTargetNumber": "1"},
+		map[string]any{"type": "fade", "number": "4", "cueTargetNumber": "99"},
+		map[string]any{"type": "stop", "number": "5", "cueTargetName": "Nobody"},
+		map[string]any{"type": "start", "number": "6", "cueTargetName": "In QLab"},
+		map[string]any{"type": "devamp", "number": "7"},
+		map[string]any{"type": "start", "number": "8", "name": "Loop A", "cueTargetNumber": "9"},
+		map[string]any{"type": "group", "number": "9", "cues": []any{
+			map[string]any{"type": "start", "number": "9.1", "cueTargetNumber": "8"},
+		}},
+		map[string]any{"type": "start", "number": "10", "cueTargetNumber": "11"},
+		map[string]any{"type": "start", "number": "11", "cueTargetNumber": "12"},
+		map[string]any{"type": "stop", "number": "12", "cueTargetNumber": "10"},
+		map[string]any{"type": "memo", "number": "13", "name": "Note"},
+	}}
+
+	workspace := &Workspace{cueListsCache: []any{
+		map[string]any{"type": "cue list", "cues": []any{
+			map[string]any{"type": "memo", "number": "50", "name": "In QLab"},
+		}},
+	}}
+	report, err := workspace.ValidateTargets(workspaceData)
+	if err != nil {
+		t.Fatalf("ValidateTargets failed: %v", err)
+	}
+
+	want := []struct {
+		kind TargetProblemKind
+		cue  string
+	}{
+		{TargetFileMissing, "2"},
+		{TargetBroken, "4"},
+		{TargetBroken, "5"},
+		{TargetMissing, "7"},
+		{TargetCircular, "10"},
+	}
+	if report.OK() || len(report.Problems) != len(want) {
+		t.Fatalf("Expected %d problems, got %v", len(want), report.Problems)
+	}
+	for i, w := range want {
+		if got := report.Problems[i]; got.Kind != w.kind || got.Cue != w.cue {
+			t.Errorf("Problem %d: expected %s on cue %s, got %s", i, w.kind, w.cue, got)
+		}
+	}
+	if loop := report.Problems[4].Target; loop != "10 -> 11 -> 12 -> 10" {
+		t.Errorf("Unexpected loop %q", loop)
+	}
+	if !strings.Contains(report.Problems[1].String(), "number 99") {
+		t.Errorf("Expected the failed target in %q", report.Problems[1])
+	}
+	if report.Cues != 14 {
+		t.Errorf("Expected 14 cues checked, got %d", report.Cues)
+	}
+}
+
+// TestValidateTargetsQLab tests checking the cues already in QLab
+func TestValidateTargetsQLab(t *testing.T) {
+	workspace, _ := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(3)
+
+	cues := []map[string]any{
+		{"type": "audio", "number": "1", "name": "Intro", "fileTarget": "music/intro.mp3"},
+		{"type": "start", "number": "2", "name": "Again"},
+		{"type": "stop", "number": "3"},
+	}
+	for _, cue := range cues {
+		if _, err := workspace.createCueWithoutTarget(cue, cue["number"].(string)); err != nil {
+			t.Fatalf("Failed to create cue: %v", err)
+		}
+	}
+	if err := workspace.setCueProperty("MOCK-CUE-2", "cueTargetNumber", "2"); err != nil {
+		t.Fatalf("Failed to set target: %v", err)
+	}
+
+	report, err := workspace.ValidateTargets(nil)
+	if err != nil {
+		t.Fatalf("ValidateTargets failed: %v", err)
+	}
+	if report.Cues != 3 || len(report.Problems) != 2 {
+		t.Fatalf("Expected 2 problems in 3 cues, got %d in %d: %v", len(report.Problems), report.Cues, report.Problems)
+	}
+	if problem := report.Problems[0]; problem.Kind != TargetMissing || problem.Cue != "3" {
+		t.Errorf("Expected cue 3 to have no target, got %s", problem)
+	}
+	if problem := report.Problems[1]; problem.Kind != TargetCircular || problem.Target != "2 -> 2" {
+		t.Errorf("Expected cue 2 to start itself, got %s", problem)
+	}
+}
@@ -0,0 +1,131 @@
+package qlab
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// TestTransactionRollbackCreatedCue tests that rolling back deletes cues created in the transaction
+func TestTransactionRollbackCreatedCue(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+
+	tx, err := workspace.BeginTransaction()
+	if err != nil {
+		t.Fatalf("BeginTransaction failed: %v", err)
+	}
+
+	cueID, err := workspace.createCueWithoutTarget(map[string]any{"type": "memo", "name": "Temporary"}, "42")
+	if err != nil {
+		t.Fatalf("createCueWithoutTarget failed: %v", err)
+	}
+
+	ops := tx.Operations()
+	if len(ops) != 3 || ops[0].Kind != TransactionCreate || ops[0].CueID != cueID {
+		t.Fatalf("Expected create followed by two property sets, got %+v", ops)
+	}
+	for _, op := range ops[1:] {
+		if op.Kind != TransactionSetProperty || op.HasPreviousValue {
+			t.Errorf("Expected property set without previous value on created cue, got %+v", op)
+		}
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if mockServer.GetCue(cueID) != nil {
+		t.Errorf("Expected cue %s to be deleted by rollback", cueID)
+	}
+	if workspace.activeTransaction() != nil {
+		t.Error("Expected transaction to be detached after rollback")
+	}
+}
+
+// TestTransactionRollbackRestoresProperty tests that property sets on existing cues are restored
+func TestTransactionRollbackRestoresProperty(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+
+	cueID, err := workspace.createCueWithoutTarget(map[string]any{"type": "memo", "name": "Original"}, "")
+	if err != nil {
+		t.Fatalf("createCueWithoutTarget failed: %v", err)
+	}
+
+	tx, err := workspace.BeginTransaction()
+	if err != nil {
+		t.Fatalf("BeginTransaction failed: %v", err)
+	}
+	if err := workspace.setCueProperty(cueID, "name", "Changed"); err != nil {
+		t.Fatalf("setCueProperty failed: %v", err)
+	}
+	if name := mockServer.GetCue(cueID).Name; name != "Changed" {
+		t.Fatalf("Expected name 'Changed', got %q", name)
+	}
+
+	ops := tx.Operations()
+	if len(ops) != 1 || ops[0].PreviousValue != "Original" {
+		t.Fatalf("Expected one property set with previous value 'Original', got %+v", ops)
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if name := mockServer.GetCue(cueID).Name; name != "Original" {
+		t.Errorf("Expected name restored to 'Original', got %q", name)
+	}
+}
+
+// TestTransactionLifecycle tests that only one transaction is active and finished ones can't roll back
+func TestTransactionLifecycle(t *testing.T) {
+	workspace := &Workspace{}
+
+	tx, err := workspace.BeginTransaction()
+	if err != nil {
+		t.Fatalf("BeginTransaction failed: %v", err)
+	}
+	if _, err := workspace.BeginTransaction(); err == nil {
+		t.Error("Expected error starting a second transaction")
+	}
+
+	tx.Commit()
+	if err := tx.Rollback(); err == nil {
+		t.Error("Expected error rolling back a committed transaction")
+	}
+	if _, err := workspace.BeginTransaction(); err != nil {
+		t.Errorf("Expected new transaction after commit, got %v", err)
+	}
+}
+
+// TestPreviousValueArgs tests conversion of queried values back into OSC arguments
+func TestPreviousValueArgs(t *testing.T) {
+	args := previousValueArgs([]any{1.5, 2.0})
+	if len(args) != 2 || args[0] != float32(1.5) || args[1] != float32(2.0) {
+		t.Errorf("Expected float32 components, got %v", args)
+	}
+	if args := previousValueArgs(nil); len(args) != 1 || args[0] != "" {
+		t.Errorf("Expected empty string for nil, got %v", args)
+	}
+	if args := previousValueArgs(3.0); args[0] != "3" {
+		t.Errorf("Expected \"3\", got %v", args)
+	}
+}
+
+// TestTransmitRollbackKeepsError tests that a transactional transmit that times out part-way
+// rolls back and still reports ErrTimeout
+func TestTransmitRollbackKeepsError(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+	workspace.SetTransactional(true)
+	mockServer.SetFault("/notes", MockFault{DropReplies: -1})
+
+	workspaceData := map[string]any{"cues": []any{
+		map[string]any{"type": "memo", "number": "1", "name": "First"},
+		map[string]any{"type": "memo", "number": "2", "name": "Second", "notes": "Never answered"},
+	}}
+	_, err := workspace.TransmitWorkspaceData(filepath.Join(t.TempDir(), "show.json"), workspaceData, WithConflictResolver(AlwaysSource))
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Expected ErrTimeout from the rolled back transmit, got %v", err)
+	}
+	if count := mockServer.GetCueCount(); count != 0 {
+		t.Errorf("Expected the rollback to delete every cue, got %d", count)
+	}
+}
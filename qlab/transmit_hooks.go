@@ -0,0 +1,154 @@
+package qlab
+
+import (
+	"slices"
+	"sync"
+)
+
+// CueCreatedEvent is passed to OnCueCreated hooks after a source cue is created in QLab
+type CueCreatedEvent struct {
+	CueNumber string // Full cue number, or position key for unnumbered cues
+	UniqueID  string // QLab's uniqueID for the new cue
+	Type      string
+	Name      string
+	Data      map[string]any // Source cue data the cue was created from
+}
+
+// CueUpdatedEvent is passed to OnCueUpdated hooks after an existing cue's properties are set
+// from the source
+type CueUpdatedEvent struct {
+	CueNumber      string
+	UniqueID       string
+	Type           string
+	Name           string
+	Data           map[string]any    // Source cue data the cue was updated from
+	ModifiedFields map[string]string // Fields that differed, field -> "'old' -> 'new'"
+	KeptFields     []string          // Fields a per-field resolution kept from QLab, not sent
+	Reason         string            // Why the cue was updated, from the comparison
+}
+
+// CueSkippedEvent is passed to OnCueSkipped hooks for a source cue left as it is in QLab,
+// because it's unchanged, outside the selection, or a conflict resolved that way. The cues
+// inside a skipped group aren't reported.
+type CueSkippedEvent struct {
+	CueNumber string
+	UniqueID  string
+	Type      string
+	Name      string
+	Reason    string // Why the cue was skipped, from the comparison
+}
+
+// CueMovedEvent is passed to OnCueMoved hooks after an existing cue is moved to the place it
+// has in the source
+type CueMovedEvent struct {
+	CueNumber string
+	UniqueID  string
+	Parent    string // Key of the group, or "list:" and the name of the cue list, the cue was moved into
+	ParentID  string // uniqueID of the group or cue list
+	Index     int    // Position in the parent, from 0
+}
+
+// TransmitErrorEvent is passed to OnError hooks when a cue fails to be created, updated,
+// moved or deleted. The transmit stops with the same error.
+type TransmitErrorEvent struct {
+	CueNumber string
+	Phase     TransmitPhase // What was being done to the cue
+	Err       error
+}
+
+// transmitHooks are the functions registered with OnCueCreated and the other hooks
+type transmitHooks struct {
+	mu      sync.Mutex
+	created []func(CueCreatedEvent)
+	updated []func(CueUpdatedEvent)
+	skipped []func(CueSkippedEvent)
+	moved   []func(CueMovedEvent)
+	failed  []func(TransmitErrorEvent)
+}
+
+// OnCueCreated registers a function called during TransmitWorkspaceData after each cue is
+// created, for mirroring progress into a database of your own or starting side effects such
+// as copying media. Hooks are called in the transmitting goroutine, in the order they were
+// registered, so a slow hook slows the transmit. Changes a rolled back transaction undoes
+// have been reported already.
+func (q *Workspace) OnCueCreated(hook func(event CueCreatedEvent)) {
+	q.hooks.mu.Lock()
+	defer q.hooks.mu.Unlock()
+	q.hooks.created = append(q.hooks.created, hook)
+}
+
+// OnCueUpdated registers a function called after each existing cue is updated, like OnCueCreated
+func (q *Workspace) OnCueUpdated(hook func(event CueUpdatedEvent)) {
+	q.hooks.mu.Lock()
+	defer q.hooks.mu.Unlock()
+	q.hooks.updated = append(q.hooks.updated, hook)
+}
+
+// OnCueSkipped registers a function called for each cue left as it is, like OnCueCreated
+func (q *Workspace) OnCueSkipped(hook func(event CueSkippedEvent)) {
+	q.hooks.mu.Lock()
+	defer q.hooks.mu.Unlock()
+	q.hooks.skipped = append(q.hooks.skipped, hook)
+}
+
+// OnCueMoved registers a function called after each existing cue is moved into place, like
+// OnCueCreated
+func (q *Workspace) OnCueMoved(hook func(event CueMovedEvent)) {
+	q.hooks.mu.Lock()
+	defer q.hooks.mu.Unlock()
+	q.hooks.moved = append(q.hooks.moved, hook)
+}
+
+// OnError registers a function called when a cue fails to be created, updated, moved or
+// deleted, like OnCueCreated
+func (q *Workspace) OnError(hook func(event TransmitErrorEvent)) {
+	q.hooks.mu.Lock()
+	defer q.hooks.mu.Unlock()
+	q.hooks.failed = append(q.hooks.failed, hook)
+}
+
+// fireHooks calls the hooks registered in list with event, outside the lock so hooks may
+// register others
+func fireHooks[E any](q *Workspace, list *[]func(E), event E) {
+	q.hooks.mu.Lock()
+	hooks := slices.Clone(*list)
+	q.hooks.mu.Unlock()
+	for _, hook := range hooks {
+		hook(event)
+	}
+}
+
+// cueCreated reports a created cue to the OnCueCreated hooks
+func (q *Workspace) cueCreated(cueNumber, uniqueID string, cueData map[string]any) {
+	q.journal.record(JournalEntry{Phase: PhaseCreate, Cue: cueNumber, UniqueID: uniqueID})
+	event := CueCreatedEvent{CueNumber: cueNumber, UniqueID: uniqueID, Data: cueData}
+	event.Type, _ = cueData["type"].(string)
+	event.Name, _ = cueData["name"].(string)
+	fireHooks(q, &q.hooks.created, event)
+}
+
+// cueUpdated reports an updated cue to the OnCueUpdated hooks
+func (q *Workspace) cueUpdated(cueNumber, uniqueID string, cueData map[string]any, result *CueChangeResult) {
+	q.journal.record(JournalEntry{Phase: PhaseProperties, Cue: cueNumber, UniqueID: uniqueID})
+	event := CueUpdatedEvent{
+		CueNumber: cueNumber, UniqueID: uniqueID, Data: cueData,
+		ModifiedFields: result.ModifiedFields, KeptFields: result.KeptFields, Reason: result.Reason,
+	}
+	event.Type, _ = cueData["type"].(string)
+	event.Name, _ = cueData["name"].(string)
+	fireHooks(q, &q.hooks.updated, event)
+}
+
+// cueSkipped reports a skipped cue to the OnCueSkipped hooks
+func (q *Workspace) cueSkipped(cueNumber string, cueData map[string]any, result *CueChangeResult) {
+	event := CueSkippedEvent{CueNumber: cueNumber, UniqueID: result.ExistingID, Reason: result.Reason}
+	event.Type, _ = cueData["type"].(string)
+	event.Name, _ = cueData["name"].(string)
+	fireHooks(q, &q.hooks.skipped, event)
+}
+
+// transmitFailed reports a cue that failed to the OnError hooks and returns err
+func (q *Workspace) transmitFailed(cueNumber string, phase TransmitPhase, err error) error {
+	fireHooks(q, &q.hooks.failed, TransmitErrorEvent{CueNumber: cueNumber, Phase: phase, Err: err})
+	return err
+}
@@ -0,0 +1,59 @@
+package qlab
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestTransmitHooks tests that cue lifecycle hooks are called as cues are created, skipped
+// and updated, and that a failed update reaches the error hooks
+func TestTransmitHooks(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+	workspace.SetCacheStore(NewMemoryCacheStore())
+	workspace.SetSkipInbox(true)
+	path := filepath.Join(t.TempDir(), "show.json")
+
+	var created []CueCreatedEvent
+	var updated []CueUpdatedEvent
+	var skipped []CueSkippedEvent
+	var failed []TransmitErrorEvent
+	workspace.OnCueCreated(func(event CueCreatedEvent) { created = append(created, event) })
+	workspace.OnCueUpdated(func(event CueUpdatedEvent) { updated = append(updated, event) })
+	workspace.OnCueSkipped(func(event CueSkippedEvent) { skipped = append(skipped, event) })
+	workspace.OnError(func(event TransmitErrorEvent) { failed = append(failed, event) })
+
+	source := func(notes string) map[string]any {
+		return map[string]any{"cues": []any{
+			map[string]any{"type": "memo", "number": "1", "name": "House open", "notes": notes},
+			map[string]any{"type": "memo", "number": "2", "name": "Preshow"},
+		}}
+	}
+	if _, err := workspace.TransmitWorkspaceData(path, source("Check levels")); err != nil {
+		t.Fatalf("First transmit failed: %v", err)
+	}
+	if len(created) != 2 || created[0].CueNumber != "1" || created[0].Name != "House open" || created[0].Type != "memo" {
+		t.Fatalf("Expected cues 1 and 2 created, got %+v", created)
+	}
+	if uniqueID, _ := workspace.lookupCueNumber("1"); created[0].UniqueID != uniqueID {
+		t.Errorf("Expected the created cue's uniqueID %s, got %s", uniqueID, created[0].UniqueID)
+	}
+
+	if _, err := workspace.TransmitWorkspaceData(path, source("Check levels twice")); err != nil {
+		t.Fatalf("Second transmit failed: %v", err)
+	}
+	if len(updated) != 1 || updated[0].CueNumber != "1" || updated[0].ModifiedFields["notes"] == "" {
+		t.Errorf("Expected cue 1 updated with its notes modified, got %+v", updated)
+	}
+	if len(skipped) != 1 || skipped[0].CueNumber != "2" || skipped[0].Reason == "" {
+		t.Errorf("Expected unchanged cue 2 skipped, got %+v", skipped)
+	}
+
+	mockServer.SetFault("/notes", MockFault{Error: "busy"})
+	if _, err := workspace.TransmitWorkspaceData(path, source("Check levels thrice")); err == nil {
+		t.Fatal("Expected the faulted transmit to fail")
+	}
+	if len(failed) != 1 || failed[0].CueNumber != "1" || failed[0].Phase != PhaseProperties || failed[0].Err == nil {
+		t.Errorf("Expected cue 1's update reported failed, got %+v", failed)
+	}
+}
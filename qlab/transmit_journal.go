@@ -0,0 +1,172 @@
+package qlab
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// JournalEntry is a line of an operation journal. The first holds the plan being applied,
+// each of the others an operation as it completed, and the last marks the apply complete.
+type JournalEntry struct {
+	Plan     *TransmitPlan `json:"plan,omitempty"`
+	Phase    TransmitPhase `json:"phase,omitempty"`
+	Cue      string        `json:"cue,omitempty"`      // Key of the cue, as in PlanOperation
+	UniqueID string        `json:"uniqueID,omitempty"` // QLab's cue, for a created cue the one created
+	Complete bool          `json:"complete,omitempty"`
+	Time     time.Time     `json:"time"`
+}
+
+// operationJournal appends entries to a journal file, syncing each to disk so the journal
+// survives a crash right after the operation
+type operationJournal struct {
+	mu   sync.Mutex
+	file *os.File
+	err  error
+}
+
+// SetJournal sets a file ApplyPlan journals to, "" for none: the plan first, then each
+// operation as it completes. If the apply is cut short, by a crash or otherwise,
+// ResumeTransmit finishes it from the journal without repeating what was done. Each
+// ApplyPlan starts the journal afresh.
+func (q *Workspace) SetJournal(path string) {
+	q.journalPath = path
+}
+
+// ResumeTransmit finishes the apply journaled in journalPath, as ApplyPlan would apply the
+// plan again, but with the journaled operations done and the cues journaled as created
+// found by their uniqueIDs, even without a cache that knows them. A journal marked
+// complete has nothing left to do. The operations of the resumed apply are added to the
+// same journal.
+func (q *Workspace) ResumeTransmit(journalPath string) error {
+	if err := q.checkWritable("resume transmit"); err != nil {
+		return err
+	}
+	plan, created, complete, err := readJournal(journalPath)
+	if err != nil {
+		return err
+	}
+	if complete {
+		q.log().Infof("Journal %s is complete, nothing to resume", journalPath)
+		return nil
+	}
+
+	q.opMu.Lock()
+	defer q.opMu.Unlock()
+
+	var journal *operationJournal
+	if !q.dryRun {
+		file, err := os.OpenFile(journalPath, os.O_WRONLY|os.O_APPEND, 0)
+		if err != nil {
+			return fmt.Errorf("failed to open journal: %w", err)
+		}
+		journal = &operationJournal{file: file}
+	}
+	q.log().Infof("Resuming transmit of %s: %d operations pending", plan.FilePath, len(plan.Pending()))
+	return q.runPlan(plan, created, journal)
+}
+
+// createJournal starts a journal at path with the plan to be applied
+func createJournal(path string, plan *TransmitPlan) (*operationJournal, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create journal: %w", err)
+	}
+	journal := &operationJournal{file: file}
+	journal.record(JournalEntry{Plan: plan})
+	if journal.err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to write journal: %w", journal.err)
+	}
+	return journal, nil
+}
+
+// readJournal returns the plan of a journal with its journaled operations done, the
+// uniqueIDs of the cues it created by key, and whether the apply completed. A last line cut
+// off by a crash is ignored.
+func readJournal(path string) (*TransmitPlan, map[string]string, bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer file.Close()
+
+	var plan *TransmitPlan
+	created := make(map[string]string)
+	done := make(map[string]map[TransmitPhase]bool)
+	complete := false
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 256*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			if plan == nil {
+				return nil, nil, false, fmt.Errorf("failed to read journal %s: %w", path, err)
+			}
+			break
+		}
+		switch {
+		case line == 1:
+			if entry.Plan == nil {
+				return nil, nil, false, fmt.Errorf("journal %s doesn't start with a plan", path)
+			}
+			plan = entry.Plan
+		case entry.Complete:
+			complete = true
+		case entry.Cue != "":
+			if done[entry.Cue] == nil {
+				done[entry.Cue] = make(map[TransmitPhase]bool)
+			}
+			done[entry.Cue][entry.Phase] = true
+			if entry.Phase == PhaseCreate && entry.UniqueID != "" {
+				created[entry.Cue] = entry.UniqueID
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, false, fmt.Errorf("failed to read journal %s: %w", path, err)
+	}
+	if plan == nil {
+		return nil, nil, false, fmt.Errorf("journal %s is empty", path)
+	}
+
+	for i, op := range plan.Operations {
+		if done[op.Cue][op.Phase] {
+			plan.Operations[i].Done = true
+		}
+	}
+	return plan, created, complete, nil
+}
+
+// record appends an entry to the journal, if there is one. The first write error is kept
+// and returned by close.
+func (j *operationJournal) record(entry JournalEntry) {
+	if j == nil {
+		return
+	}
+	entry.Time = time.Now()
+	line, err := json.Marshal(entry)
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err == nil {
+		if _, err = j.file.Write(append(line, '\n')); err == nil {
+			err = j.file.Sync()
+		}
+	}
+	if err != nil && j.err == nil {
+		j.err = err
+	}
+}
+
+// close closes the journal file and returns the first error writing it
+func (j *operationJournal) close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.file.Close(); err != nil && j.err == nil {
+		j.err = err
+	}
+	return j.err
+}
@@ -0,0 +1,72 @@
+package qlab
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// TestResumeTransmit tests that an apply cut short after creating some cues is finished
+// from its journal without creating them again
+func TestResumeTransmit(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+	workspace.SetCacheStore(NewMemoryCacheStore())
+	workspace.SetSkipInbox(true)
+	dir := t.TempDir()
+	journalPath := filepath.Join(dir, "show.journal")
+	workspace.SetJournal(journalPath)
+
+	source := map[string]any{"cues": []any{
+		map[string]any{"type": "group", "number": "1", "name": "Act 1", "cues": []any{
+			map[string]any{"type": "memo", "name": "Preset"},
+			map[string]any{"type": "memo", "name": "Places"},
+			map[string]any{"type": "memo", "name": "Go"},
+		}},
+	}}
+	plan, err := workspace.PlanTransmit(filepath.Join(dir, "show.json"), source)
+	if err != nil {
+		t.Fatalf("PlanTransmit failed: %v", err)
+	}
+	before := mockServer.GetCueCount()
+
+	// The process dies after the group's second cue is created, before it's moved into the
+	// group and before anything is cached
+	created := 0
+	workspace.OnCueCreated(func(event CueCreatedEvent) {
+		if created++; created == 3 {
+			runtime.Goexit()
+		}
+	})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		workspace.ApplyPlan(plan)
+		t.Error("Expected the apply to be cut short")
+	}()
+	<-finished
+	if got := mockServer.GetCueCount() - before; got != 3 {
+		t.Fatalf("Expected 3 cues created before the crash, got %d", got)
+	}
+
+	if err := workspace.ResumeTransmit(journalPath); err != nil {
+		t.Fatalf("ResumeTransmit failed: %v", err)
+	}
+	if got := mockServer.GetCueCount() - before; got != 4 {
+		t.Errorf("Expected 4 cues created in all, got %d", got)
+	}
+	groupID, _ := workspace.lookupCueNumber("1")
+	if group := mockServer.GetCue(groupID); group == nil || len(group.Children) != 3 {
+		t.Errorf("Expected the group to hold its 3 cues, got %+v", group)
+	}
+	resumed, _, complete, err := readJournal(journalPath)
+	if err != nil || !complete {
+		t.Fatalf("Expected the journal complete, got %v (%v)", complete, err)
+	}
+	if pending := resumed.Pending(); len(pending) != 0 {
+		t.Errorf("Expected every operation journaled, got %+v pending", pending)
+	}
+	if err := workspace.ResumeTransmit(journalPath); err != nil {
+		t.Errorf("Resuming a complete journal failed: %v", err)
+	}
+}
@@ -0,0 +1,420 @@
+package qlab
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+)
+
+// reasonAppliedEarlier is the Reason of cues an earlier ApplyPlan of the same plan got through
+const reasonAppliedEarlier = "applied by an earlier apply of the plan"
+
+// PlanOperation is one change a TransmitPlan makes to a cue
+type PlanOperation struct {
+	Phase    TransmitPhase     `json:"phase"` // PhaseDelete, PhaseMove, PhaseCreate or PhaseProperties
+	Cue      string            `json:"cue"`   // Full cue number, or position key for unnumbered cues
+	Name     string            `json:"name,omitempty"`
+	Type     string            `json:"type,omitempty"`
+	UniqueID string            `json:"uniqueID,omitempty"` // QLab's cue, empty for a cue to be created
+	Parent   string            `json:"parent,omitempty"`   // Where a move puts the cue, as in CueMove
+	Changes  map[string]string `json:"changes,omitempty"`  // Fields a properties operation sets, field -> "old -> new"
+	Reason   string            `json:"reason,omitempty"`
+	Skip     bool              `json:"skip,omitempty"` // Left out when the plan is applied
+	Done     bool              `json:"done,omitempty"` // Applied, or found already done
+}
+
+// TransmitPlan is what TransmitWorkspaceData would do with source data, worked out by
+// PlanTransmit and carried out by ApplyPlan. It marshals to JSON, so a plan can be reviewed
+// before it's applied, and applied later by another process.
+type TransmitPlan struct {
+	FilePath         string                              `json:"filePath"`
+	Source           map[string]any                      `json:"source"` // Prepared source data: numbered, validated and rendered
+	AbsoluteNumbers  bool                                `json:"absoluteNumbers,omitempty"`
+	Operations       []PlanOperation                     `json:"operations"` // In the order they're applied
+	Resolutions      map[string]ConflictResolutionChoice `json:"resolutions,omitempty"`
+	FieldResolutions FieldResolutions                    `json:"fieldResolutions,omitempty"`
+
+	// Comparison the plan was made from, then the one the last ApplyPlan applied
+	Comparison *ThreeWayComparison `json:"-"`
+}
+
+// Filter marks the operations keep rejects to be skipped, so ApplyPlan leaves them out
+func (p *TransmitPlan) Filter(keep func(op PlanOperation) bool) {
+	for i, op := range p.Operations {
+		if !keep(op) {
+			p.Operations[i].Skip = true
+		}
+	}
+}
+
+// Pending returns the operations ApplyPlan has still to apply
+func (p *TransmitPlan) Pending() []PlanOperation {
+	var pending []PlanOperation
+	for _, op := range p.Operations {
+		if !op.Skip && !op.Done {
+			pending = append(pending, op)
+		}
+	}
+	return pending
+}
+
+// PlanTransmit works out what TransmitWorkspaceData would do with workspace data without
+// changing anything. The data is prepared and compared like a transmit's, media collection
+// is only reported, and conflicts are resolved as the options say, prompting without a
+// resolver. The resulting operations are listed in the order ApplyPlan applies them:
+// deletions, moves, then creations and property changes in source order.
+func (q *Workspace) PlanTransmit(filePath string, workspaceData map[string]any, opts ...TransmitOption) (*TransmitPlan, error) {
+	q.opMu.Lock()
+	defer q.opMu.Unlock()
+
+	var options transmitOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.absoluteNumbers && !q.absoluteNumbers.Load() {
+		q.absoluteNumbers.Store(true)
+		defer q.absoluteNumbers.Store(false)
+	}
+
+	prepared, err := q.prepareTransmit(filePath, workspaceData, options)
+	if err != nil {
+		return nil, err
+	}
+	plan := &TransmitPlan{FilePath: filePath, Source: prepared.data, AbsoluteNumbers: q.absoluteNumbers.Load()}
+	if err := q.collectTransmitMedia(filePath, prepared, true); err != nil {
+		return nil, err
+	}
+	if err := q.setCueFileDirectory(filePath); err != nil {
+		return nil, err
+	}
+
+	comparison, err := q.PerformThreeWayComparison(filePath, prepared.data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare with QLab: %w", err)
+	}
+	prepared.annotate(comparison)
+	if options.filter != nil {
+		q.applyTransmitFilter(comparison, prepared.data, options.filter)
+	}
+	if err := q.resolveTransmitConflicts(comparison, options.resolver); err != nil {
+		return nil, err
+	}
+
+	plan.Operations = q.planOperations(prepared.data, comparison)
+	plan.Resolutions = maps.Clone(comparison.Resolutions)
+	plan.FieldResolutions = comparison.FieldResolutions
+	plan.Comparison = comparison
+	return plan, nil
+}
+
+// planOperations lists the changes of a resolved comparison in the order they're applied
+func (q *Workspace) planOperations(workspaceData map[string]any, comparison *ThreeWayComparison) []PlanOperation {
+	results := comparison.CueResults
+	sourceCues := make(map[string]map[string]any)
+	var order []string
+	eachSourceCue(workspaceData, q.sourceCueKey, func(cue map[string]any, key string) {
+		if _, seen := sourceCues[key]; key != "" && !seen {
+			sourceCues[key] = cue
+			order = append(order, key)
+		}
+	})
+	operation := func(phase TransmitPhase, key string, cue map[string]any) PlanOperation {
+		result := results[key]
+		op := PlanOperation{Phase: phase, Cue: key, UniqueID: result.ExistingID, Reason: result.Reason}
+		op.Name, _ = cue["name"].(string)
+		op.Type, _ = cue["type"].(string)
+		return op
+	}
+
+	var operations []PlanOperation
+	qlabCues := q.indexCuesFromWorkspace(comparison.CurrentQLabData)
+	for _, key := range sortedResultKeys(results, "delete") {
+		operations = append(operations, operation(PhaseDelete, key, qlabCues[key]))
+	}
+	moves := pendingMoves(results)
+	sort.Slice(moves, func(i, j int) bool {
+		return results[moves[i]].Move.sequence < results[moves[j]].Move.sequence
+	})
+	for _, key := range moves {
+		op := operation(PhaseMove, key, sourceCues[key])
+		op.Parent = results[key].Move.Parent
+		operations = append(operations, op)
+	}
+	for _, key := range order {
+		switch result := results[key]; {
+		case result == nil:
+		case result.Action == "create":
+			operations = append(operations, operation(PhaseCreate, key, sourceCues[key]))
+		case result.Action == "update":
+			op := operation(PhaseProperties, key, sourceCues[key])
+			op.Changes = maps.Clone(result.ModifiedFields)
+			operations = append(operations, op)
+		}
+	}
+	return operations
+}
+
+// ApplyPlan carries out the operations of a plan that aren't skipped or done, with the
+// safeguards of TransmitWorkspaceData. QLab is compared again first: operations no longer
+// needed, such as those an earlier apply got through, are marked done, and the cues of the
+// other operations are left as they are. Conflicts are resolved with the plan's choices; one
+// the plan didn't resolve fails the apply, as the plan is out of date. A group or cue list
+// the plan creates is created around the cues it applies to inside it, even if its own
+// operation is skipped.
+//
+// Applied operations are marked done. Without SetTransactional, a failed
+// apply still caches what it got through, so applying the plan again picks up where it
+// stopped. With SetJournal, each operation is also journaled as it completes, for
+// ResumeTransmit to finish an apply cut short by a crash.
+func (q *Workspace) ApplyPlan(plan *TransmitPlan) error {
+	if err := q.checkWritable("apply plan"); err != nil {
+		return err
+	}
+
+	q.opMu.Lock()
+	defer q.opMu.Unlock()
+
+	var journal *operationJournal
+	if q.journalPath != "" && !q.dryRun {
+		var err error
+		if journal, err = createJournal(q.journalPath, plan); err != nil {
+			return err
+		}
+	}
+	return q.runPlan(plan, nil, journal)
+}
+
+// runPlan applies a plan, journaling its operations if journal isn't nil. The source cues a
+// journal recorded as created are given their uniqueIDs, so they're found rather than
+// created again.
+func (q *Workspace) runPlan(plan *TransmitPlan, created map[string]string, journal *operationJournal) error {
+	defer q.InvalidateCueCache()
+	if journal != nil {
+		q.journal = journal
+		defer func() {
+			q.journal = nil
+			if err := journal.close(); err != nil {
+				q.log().Warnf("Failed to write journal: %v", err)
+			}
+		}()
+	}
+
+	if plan.AbsoluteNumbers && !q.absoluteNumbers.Load() {
+		q.absoluteNumbers.Store(true)
+		defer q.absoluteNumbers.Store(false)
+	}
+
+	prepared := &preparedTransmit{data: plan.Source}
+	if len(created) > 0 {
+		prepared.data, _ = cloneMergeValue(plan.Source).(map[string]any)
+		eachSourceCue(prepared.data, q.sourceCueKey, func(cue map[string]any, key string) {
+			if uniqueID := created[key]; uniqueID != "" {
+				cue["uniqueID"] = uniqueID
+			}
+		})
+	}
+	if err := q.collectTransmitMedia(plan.FilePath, prepared, q.dryRun); err != nil {
+		return err
+	}
+	comparison, err := q.guardTransmit(plan.FilePath, func() (*ThreeWayComparison, error) {
+		return q.applyPlan(plan, prepared.data)
+	})
+	prepared.annotate(comparison)
+	if comparison != nil {
+		plan.Comparison = comparison
+	}
+	if err == nil {
+		journal.record(JournalEntry{Complete: true})
+	}
+	return err
+}
+
+// applyPlan compares the plan's source with QLab again and applies the plan's operations
+func (q *Workspace) applyPlan(plan *TransmitPlan, workspaceData map[string]any) (*ThreeWayComparison, error) {
+	if q.progressCallback != nil {
+		q.progressCallback("compare", "Comparing with QLab workspace...")
+	}
+	progress := q.startProgress()
+	defer q.finishProgress()
+
+	comparison, err := q.PerformThreeWayComparison(plan.FilePath, workspaceData)
+	progress.step(PhaseCompare, "")
+	if err != nil {
+		return nil, fmt.Errorf("applying a plan needs change detection: %w", err)
+	}
+
+	wanted := q.restrictToPlan(plan, workspaceData, comparison)
+	if err := q.resolveTransmitConflicts(comparison, planResolver{plan: plan, wanted: wanted}); err != nil {
+		return nil, err
+	}
+	if err := q.applyComparison(plan.FilePath, workspaceData, comparison); err != nil {
+		// What got through is cached so the plan can be applied again from there, unless
+		// the transaction around it rolls it back
+		if !q.dryRun && q.activeTransaction() == nil {
+			if cacheErr := q.writeCueFileToCache(plan.FilePath, workspaceData, nil, comparison); cacheErr != nil {
+				q.log().Warnf("Failed to cache the partly applied plan: %v", cacheErr)
+			}
+		}
+		return nil, err
+	}
+
+	if !q.dryRun {
+		for i, op := range plan.Operations {
+			if wanted[op.Cue][op.Phase] {
+				plan.Operations[i].Done = true
+			}
+		}
+	}
+	return comparison, nil
+}
+
+// restrictToPlan narrows a comparison to the plan's pending operations, marking those no
+// longer needed done, and returns the phases still to apply to each cue
+func (q *Workspace) restrictToPlan(plan *TransmitPlan, workspaceData map[string]any, comparison *ThreeWayComparison) map[string]map[TransmitPhase]bool {
+	wanted := make(map[string]map[TransmitPhase]bool)
+	applied := make(map[string]bool)
+	want := func(key string, phase TransmitPhase) {
+		if wanted[key] == nil {
+			wanted[key] = make(map[TransmitPhase]bool)
+		}
+		wanted[key][phase] = true
+	}
+	for i, op := range plan.Operations {
+		if op.Skip {
+			continue
+		}
+		result := comparison.CueResults[op.Cue]
+		if op.Done || !operationNeeded(op, result) {
+			plan.Operations[i].Done = true
+			applied[op.Cue] = true
+			// A cue created by an apply cut short may not have been put in its group yet
+			if op.Phase == PhaseCreate && operationNeeded(PlanOperation{Phase: PhaseMove}, result) {
+				want(op.Cue, PhaseMove)
+			}
+			continue
+		}
+		want(op.Cue, op.Phase)
+	}
+
+	// Cues the plan resolved a conflict for are resolved the same way again
+	selected := make(map[string]bool)
+	for key := range wanted {
+		selected[key] = true
+	}
+	for key := range plan.Resolutions {
+		selected[key] = true
+	}
+	containers := make(map[string]bool)
+	var walk func(cues []any, parentNumber string) bool
+	walk = func(cues []any, parentNumber string) bool {
+		found := false
+		for i, cueData := range cues {
+			cue, ok := cueData.(map[string]any)
+			if !ok {
+				continue
+			}
+			key, fullNumber := q.sourceCueKey(cue, parentNumber, i)
+			children, _ := cue["cues"].([]any)
+			if walk(children, fullNumber) && !selected[key] {
+				containers[key] = true
+			}
+			if selected[key] || containers[key] {
+				found = true
+			}
+		}
+		return found
+	}
+	if cues, ok := workspaceData["cues"].([]any); ok {
+		walk(cues, "")
+	} else if nested, ok := workspaceData["workspace"].(map[string]any); ok {
+		if cues, ok := nested["cues"].([]any); ok {
+			walk(cues, "")
+		}
+	}
+	restrictComparison(comparison, selected, containers)
+
+	// Cues already applied are cached as they are in QLab now
+	for key := range applied {
+		if result := comparison.CueResults[key]; result != nil && !selected[key] && !containers[key] && result.Reason == reasonNotSelected {
+			result.Reason = reasonAppliedEarlier
+		}
+	}
+
+	// A cue only part of whose operations are wanted gets only those
+	for key, phases := range wanted {
+		result := comparison.CueResults[key]
+		if result.Move != nil && !phases[PhaseMove] {
+			result.Move = nil
+			if result.Action == "move" {
+				result.Action, result.Reason = "skip", reasonNotSelected
+			}
+		}
+		if result.Action == "update" && !phases[PhaseCreate] && !phases[PhaseProperties] {
+			result.Action = "move"
+			result.ModifiedFields = make(map[string]string)
+			result.FieldConflicts = make(map[string]*FieldConflict)
+		}
+	}
+	q.log().Infof("Applying plan: %d cues with operations pending", len(wanted))
+	return wanted
+}
+
+// operationNeeded reports whether a plan operation still has work to do, given the result
+// the cue has now. Creating a cue and setting its properties stand in for each other, as an
+// earlier apply may have created it.
+func operationNeeded(op PlanOperation, result *CueChangeResult) bool {
+	if result == nil {
+		return false
+	}
+	switch op.Phase {
+	case PhaseDelete:
+		return result.Action == "delete"
+	case PhaseMove:
+		return result.Move != nil && (result.Action == "move" || result.Action == "update")
+	case PhaseCreate, PhaseProperties:
+		return result.Action == "create" || result.Action == "update"
+	}
+	return false
+}
+
+// planResolver resolves conflicts with the choices recorded in a plan. A cue whose changes
+// the plan skips is skipped rather than taken from source.
+type planResolver struct {
+	plan   *TransmitPlan
+	wanted map[string]map[TransmitPhase]bool
+}
+
+// ResolveConflicts returns the plan's choice for each conflict
+func (r planResolver) ResolveConflicts(conflicts []CueConflict) (map[string]ConflictResolutionChoice, error) {
+	resolutions := make(map[string]ConflictResolutionChoice, len(conflicts))
+	for _, conflict := range conflicts {
+		choice, ok := r.plan.Resolutions[conflict.CueNumber]
+		phases := r.wanted[conflict.CueNumber]
+		switch {
+		case !ok && len(phases) == 0:
+			choice = ChoiceSkip
+		case !ok:
+			return nil, fmt.Errorf("conflict for cue %s isn't resolved by the plan; plan the transmit again", conflict.CueNumber)
+		case (choice == ChoiceUseSource || choice == ChoicePerField) && conflict.ConflictType != ConflictDeletedInSource &&
+			!phases[PhaseCreate] && !phases[PhaseProperties]:
+			choice = ChoiceSkip
+		case choice == ChoiceUseSource && conflict.ConflictType == ConflictDeletedInSource && !phases[PhaseDelete]:
+			choice = ChoiceSkip
+		}
+		resolutions[conflict.CueNumber] = choice
+	}
+	return resolutions, nil
+}
+
+// ResolveFieldConflicts returns the plan's per-field choices
+func (r planResolver) ResolveFieldConflicts(conflicts []CueConflict) (FieldResolutions, error) {
+	resolutions := make(FieldResolutions, len(conflicts))
+	for _, conflict := range conflicts {
+		choices, ok := r.plan.FieldResolutions[conflict.CueNumber]
+		if !ok {
+			return nil, fmt.Errorf("fields of cue %s aren't resolved by the plan; plan the transmit again", conflict.CueNumber)
+		}
+		resolutions[conflict.CueNumber] = choices
+	}
+	return resolutions, nil
+}
@@ -0,0 +1,88 @@
+package qlab
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+// TestPlanTransmit tests that a plan lists its operations in order without changing QLab,
+// survives JSON, leaves out skipped operations when applied, and that applying it again
+// after a failure picks up where it stopped
+func TestPlanTransmit(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+	workspace.SetCacheStore(NewMemoryCacheStore())
+	workspace.SetSkipInbox(true)
+	path := filepath.Join(t.TempDir(), "show.json")
+
+	original := map[string]any{"cues": []any{
+		map[string]any{"type": "memo", "number": "1", "name": "Places"},
+		map[string]any{"type": "memo", "number": "2", "name": "Standby"},
+	}}
+	if _, err := workspace.TransmitWorkspaceData(path, original); err != nil {
+		t.Fatalf("Transmit failed: %v", err)
+	}
+
+	edited := map[string]any{"cues": []any{
+		map[string]any{"type": "memo", "number": "1", "name": "Places please"},
+		map[string]any{"type": "memo", "number": "2", "name": "Standby all"},
+		map[string]any{"type": "memo", "number": "3", "name": "Go"},
+	}}
+	plan, err := workspace.PlanTransmit(path, edited, WithConflictResolver(AlwaysSource))
+	if err != nil {
+		t.Fatalf("PlanTransmit failed: %v", err)
+	}
+	var got []string
+	for _, op := range plan.Operations {
+		got = append(got, string(op.Phase)+" "+op.Cue)
+	}
+	want := []string{"properties 1", "properties 2", "create 3"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Fatalf("Expected operations %v, got %v", want, got)
+	}
+	if _, ok := workspace.lookupCueNumber("3"); ok {
+		t.Fatal("Expected planning to leave QLab alone")
+	}
+
+	// Reviewed elsewhere, with the change to cue 2 left out
+	data, err := json.Marshal(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reviewed TransmitPlan
+	if err := json.Unmarshal(data, &reviewed); err != nil {
+		t.Fatal(err)
+	}
+	reviewed.Filter(func(op PlanOperation) bool { return op.Cue != "2" })
+
+	// Creating cue 3 fails after cue 1 is updated; the second apply finishes the job
+	mockServer.SetFault("/new", MockFault{Error: "busy"})
+	if err := workspace.ApplyPlan(&reviewed); err == nil {
+		t.Fatal("Expected the apply to fail creating cue 3")
+	}
+	mockServer.SetFault("/new", MockFault{})
+	if err := workspace.ApplyPlan(&reviewed); err != nil {
+		t.Fatalf("Second ApplyPlan failed: %v", err)
+	}
+	if pending := reviewed.Pending(); len(pending) != 0 {
+		t.Errorf("Expected nothing pending, got %+v", pending)
+	}
+	for number, name := range map[string]string{"1": "Places please", "2": "Standby", "3": "Go"} {
+		uniqueID, _ := workspace.lookupCueNumber(number)
+		if cue := mockServer.GetCue(uniqueID); cue == nil || cue.Name != name {
+			t.Errorf("Expected cue %s named %q, got %+v", number, name, cue)
+		}
+	}
+
+	// Cue 2's skipped change is still there to transmit
+	comparison, err := workspace.TransmitWorkspaceData(path, edited, WithConflictResolver(AlwaysSource))
+	if err != nil {
+		t.Fatalf("Transmit failed: %v", err)
+	}
+	for key, result := range comparison.CueResults {
+		if changed := result.Action != "skip"; changed != (key == "2") {
+			t.Errorf("Cue %s: %s (%s)", key, result.Action, result.Reason)
+		}
+	}
+}
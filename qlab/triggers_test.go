@@ -0,0 +1,69 @@
+package qlab
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCueTriggers tests that triggers are set, read back, and compared
+func TestCueTriggers(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+
+	cueID, err := workspace.createCueWithoutTarget(map[string]any{
+		"type":                "memo",
+		"name":                "Preshow",
+		"hotkeyTrigger":       true,
+		"hotkeyTriggerString": "p",
+		"wallClockTrigger":    true,
+		"wallClockHours":      19.0,
+		"wallClockMinutes":    30.0,
+		"midiTriggerStatus":   "noteOn",
+		"midiTriggerByteOne":  60.0,
+	}, "")
+	if err != nil {
+		t.Fatalf("createCueWithoutTarget failed: %v", err)
+	}
+
+	cue := mockServer.GetCue(cueID)
+	expected := map[string]string{
+		"hotkeyTrigger":       "1",
+		"hotkeyTriggerString": "p",
+		"wallClockTrigger":    "1",
+		"wallClockHours":      "19",
+		"wallClockMinutes":    "30",
+		"midiTriggerStatus":   "144",
+		"midiTriggerByteOne":  "60",
+	}
+	for property, value := range expected {
+		if got := cue.Properties[property]; got != value {
+			t.Errorf("Expected %s=%q, got %q", property, value, got)
+		}
+	}
+
+	// Settings go before the flag that arms the trigger
+	var order []string
+	for _, msg := range mockServer.GetReceivedMessages() {
+		if i := strings.LastIndex(msg.Address, "/"); strings.Contains(msg.Address, "hotkeyTrigger") {
+			order = append(order, msg.Address[i+1:])
+		}
+	}
+	if len(order) < 2 || order[0] != "hotkeyTriggerString" {
+		t.Errorf("Expected the hotkey set before the trigger is armed, got %v", order)
+	}
+
+	// Only armed triggers are read back
+	enriched := map[string]any{"type": "Memo", "uniqueID": cueID, "name": "Preshow"}
+	workspace.enrichCueProperties(enriched, cueID)
+	if enriched["hotkeyTriggerString"] != "p" || enriched["wallClockHours"] != 19.0 || enriched["wallClockTrigger"] != true {
+		t.Errorf("Expected the armed triggers read back, got %v", enriched)
+	}
+	if _, ok := enriched["midiTriggerByteOne"]; ok {
+		t.Errorf("Expected the disarmed MIDI trigger left out, got %v", enriched)
+	}
+
+	source := map[string]any{"type": "memo", "name": "Preshow", "wallClockHours": 20.0}
+	differences := workspace.compareCuePropertiesDetailed(source, enriched)
+	if len(differences) != 1 || differences["wallClockHours"] == "" {
+		t.Errorf("Expected only the wall-clock hour to differ, got %v", differences)
+	}
+}
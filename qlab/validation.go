@@ -0,0 +1,262 @@
+package qlab
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ValidationSeverity is how seriously TransmitWorkspaceData takes a ValidationProblem
+type ValidationSeverity string
+
+const (
+	ValidationFailure ValidationSeverity = "error"   // Refuses the transmit
+	ValidationWarning ValidationSeverity = "warning" // Logged, and the transmit goes ahead
+)
+
+// ValidationProblem is something wrong with a source cue, found by Validate
+type ValidationProblem struct {
+	Rule     string // Name of the rule that found it
+	Severity ValidationSeverity
+	Cue      string // Full cue number, or position key for unnumbered cues
+	Name     string
+	Path     string // Location in the source data, e.g. "cues[2].cues[0]"
+	Message  string
+	Err      error // Underlying error, if the rule returned a typed one
+}
+
+func (p ValidationProblem) String() string {
+	label := p.Path
+	if p.Cue != "" {
+		label = "cue " + p.Cue
+		if p.Name != "" {
+			label += fmt.Sprintf(" %q", p.Name)
+		}
+		if p.Path != "" {
+			label += " at " + p.Path
+		}
+	}
+	return fmt.Sprintf("%s: %s", label, p.Message)
+}
+
+// ValidationReport is the result of Validate
+type ValidationReport struct {
+	Problems []ValidationProblem // In workspace order, duplicates last
+	Cues     int                 // Cues checked
+}
+
+// OK reports whether no problem is serious enough to refuse the transmit
+func (r *ValidationReport) OK() bool {
+	return len(r.Errors()) == 0
+}
+
+// Errors returns the problems that refuse the transmit
+func (r *ValidationReport) Errors() []ValidationProblem {
+	return r.bySeverity(ValidationFailure)
+}
+
+// Warnings returns the problems that are only logged
+func (r *ValidationReport) Warnings() []ValidationProblem {
+	return r.bySeverity(ValidationWarning)
+}
+
+func (r *ValidationReport) bySeverity(severity ValidationSeverity) []ValidationProblem {
+	var problems []ValidationProblem
+	for _, problem := range r.Problems {
+		if problem.Severity == severity {
+			problems = append(problems, problem)
+		}
+	}
+	return problems
+}
+
+// ValidationError is returned by TransmitWorkspaceData when validation finds errors. The
+// typed errors of its problems, such as a *DuplicateCueError, can be found with errors.As.
+type ValidationError struct {
+	Problems []ValidationProblem
+}
+
+func (e *ValidationError) Error() string {
+	details := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		details[i] = problem.String()
+	}
+	return fmt.Sprintf("source data failed validation with %d problems: %s", len(e.Problems), strings.Join(details, "; "))
+}
+
+// Unwrap returns the underlying errors of the problems, each once
+func (e *ValidationError) Unwrap() []error {
+	var errs []error
+	for _, problem := range e.Problems {
+		if problem.Err != nil && !slices.Contains(errs, problem.Err) {
+			errs = append(errs, problem.Err)
+		}
+	}
+	return errs
+}
+
+// ValidationCue is a source cue as a ValidationRule sees it
+type ValidationCue struct {
+	Data   map[string]any
+	Type   string // Lowercase
+	Key    string // Full cue number, or position key for unnumbered cues
+	Path   string
+	Parent map[string]any // Group, cart or cue list holding the cue, nil at the top level
+}
+
+// ValidationRule checks every source cue before anything is transmitted. Check returns nil
+// for a cue that passes.
+type ValidationRule struct {
+	Name     string
+	Severity ValidationSeverity
+	Check    func(cue ValidationCue) error
+}
+
+// knownCueTypes are the cue types the source data may use, including the names cue lists go by
+var knownCueTypes = []string{
+	CueTypeAudio, CueTypeVideo, CueTypeText, CueTypeLight, CueTypeFade, CueTypeStart, CueTypeStop,
+	CueTypePause, CueTypeReset, CueTypeDevamp, CueTypeArm, CueTypeDisarm, CueTypeWait, CueTypeLoad,
+	CueTypeGoto, CueTypeTarget, CueTypeGroup, CueTypeMemo, CueTypeScript, CueTypeMIDI, CueTypeMIDIFile,
+	CueTypeTimecode, CueTypeNetwork, CueTypeOSC, CueTypeMSC, CueTypeCamera, CueTypeMicrophone,
+	CueTypeList, CueTypeCart, "list", "cuelist", "cue_list", "titles",
+}
+
+// builtinValidationRules are checked before the rules added with AddValidationRule
+var builtinValidationRules = []ValidationRule{
+	{Name: "cue-type", Severity: ValidationFailure, Check: checkCueType},
+	{Name: "required-fields", Severity: ValidationWarning, Check: checkRequiredFields},
+	{Name: "color", Severity: ValidationFailure, Check: checkCueColor},
+	{Name: "group-mode", Severity: ValidationFailure, Check: checkGroupMode},
+}
+
+// checkCueType refuses cues without a type or with one QLab doesn't have
+func checkCueType(cue ValidationCue) error {
+	if cue.Type == "" {
+		return errors.New("no cue type")
+	}
+	if !slices.Contains(knownCueTypes, cue.Type) {
+		return fmt.Errorf("unknown cue type %q", cue.Data["type"])
+	}
+	return nil
+}
+
+// checkRequiredFields warns about targeting cues without a cue target and file cues without
+// a file target; QLab creates them, but they do nothing until one is set
+func checkRequiredFields(cue ValidationCue) error {
+	switch {
+	case isTargetingCueType(cue.Type):
+		for _, property := range []string{"cueTargetNumber", "cueTargetName", "cueTargetID"} {
+			if value := cue.Data[property]; value != nil && value != "" {
+				return nil
+			}
+		}
+		return errors.New("no cue target")
+	case slices.Contains(fileCueTypes, cue.Type):
+		if fileTarget, _ := cue.Data["fileTarget"].(string); fileTarget == "" {
+			return errors.New("no file target")
+		}
+	}
+	return nil
+}
+
+// checkCueColor refuses colors that are neither a QLab color name nor a hex or RGB color
+func checkCueColor(cue ValidationCue) error {
+	_, _, err := NormalizeColor(cue.Data["colorName"], true)
+	return err
+}
+
+// checkGroupMode refuses modes the cue's type can't have and carts whose children don't fit
+// their grid
+func checkGroupMode(cue ValidationCue) error {
+	switch cue.Type {
+	case CueTypeGroup, "list", CueTypeList, CueTypeCart:
+		if _, _, err := groupModeOf(cue.Data); err != nil {
+			return err
+		}
+	}
+	if cue.Type == CueTypeCart {
+		return ValidateCartGrid(cue.Data)
+	}
+	return nil
+}
+
+// AddValidationRule adds a rule checked for every source cue by Validate and before
+// TransmitWorkspaceData sends anything, after the built-in rules
+func (q *Workspace) AddValidationRule(rule ValidationRule) {
+	if rule.Severity == "" {
+		rule.Severity = ValidationFailure
+	}
+	q.validationRules = append(q.validationRules, rule)
+}
+
+// Validate checks source data without sending anything: every cue's type, the fields its
+// type needs, its color and group mode, cue identifiers used more than once, and the rules
+// added with AddValidationRule. Every problem is reported at once. Duplicates are errors
+// unless the duplicate policy warns about or suffixes them.
+func (q *Workspace) Validate(workspaceData map[string]any) *ValidationReport {
+	workspaceData = UnwrapEnvelope(workspaceData)
+	cues, ok := workspaceData["cues"].([]any)
+	if !ok {
+		if nested, ok := workspaceData["workspace"].(map[string]any); ok {
+			cues, _ = nested["cues"].([]any)
+		}
+	}
+	rules := append(slices.Clip(builtinValidationRules), q.validationRules...)
+
+	report := &ValidationReport{}
+	var walk func(cues []any, parent map[string]any, parentNumber, path string)
+	walk = func(cues []any, parent map[string]any, parentNumber, path string) {
+		for i, cueData := range cues {
+			data, ok := cueData.(map[string]any)
+			if !ok {
+				continue
+			}
+			cueType, _ := data["type"].(string)
+			key, fullNumber := q.sourceCueKey(data, parentNumber, i)
+			cue := ValidationCue{Data: data, Type: strings.ToLower(cueType), Key: key, Path: fmt.Sprintf("%s[%d]", path, i), Parent: parent}
+			name, _ := data["name"].(string)
+			report.Cues++
+
+			for _, rule := range rules {
+				if err := rule.Check(cue); err != nil {
+					report.Problems = append(report.Problems, ValidationProblem{
+						Rule: rule.Name, Severity: rule.Severity, Cue: key, Name: name, Path: cue.Path, Message: err.Error(), Err: err,
+					})
+				}
+			}
+			if children, ok := data["cues"].([]any); ok {
+				walk(children, data, fullNumber, cue.Path+".cues")
+			}
+		}
+	}
+	walk(cues, nil, "", "cues")
+
+	if duplicates := findDuplicateCues(workspaceData, q.sourceCueKey); len(duplicates) > 0 {
+		severity := ValidationFailure
+		if q.duplicatePolicy != DuplicatePolicyRefuse {
+			severity = ValidationWarning
+		}
+		dupErr := &DuplicateCueError{Duplicates: duplicates}
+		for _, dup := range duplicates {
+			report.Problems = append(report.Problems, ValidationProblem{
+				Rule: "duplicate", Severity: severity, Path: strings.Join(dup.Paths, ", "),
+				Message: fmt.Sprintf("duplicate cue identifier %s", dup.Identifier), Err: dupErr,
+			})
+		}
+	}
+	return report
+}
+
+// validate runs Validate before a transmit, logging warnings and returning a
+// *ValidationError when there are errors
+func (q *Workspace) validate(workspaceData map[string]any) error {
+	report := q.Validate(workspaceData)
+	for _, problem := range report.Warnings() {
+		q.log().Warnf("Validation: %s", problem)
+	}
+	if !report.OK() {
+		return &ValidationError{Problems: report.Errors()}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package qlab
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestValidateReportsEveryProblem tests that every problem in the source is reported at once
+func TestValidateReportsEveryProblem(t *testing.T) {
+	workspaceData := map[string]any{
+		"cues": []any{
+			map[string]any{"type": "memo", "number": "1", "name": "Fine", "colorName": "red"},
+			map[string]any{"type": "hologram", "number": "2"},
+			map[string]any{"number": "3"},
+			map[string]any{"type": "memo", "number": "4", "colorName": "teal"},
+			map[string]any{"type": "group", "number": "5", "mode": "cart", "cues": []any{
+				map[string]any{"type": "start", "number": "5.1"},
+			}},
+			map[string]any{"type": "memo", "number": "1"},
+		},
+	}
+
+	report := (&Workspace{}).Validate(workspaceData)
+	rules := make(map[string]ValidationSeverity)
+	for _, problem := range report.Problems {
+		rules[problem.Rule+" "+problem.Cue] = problem.Severity
+	}
+	expected := map[string]ValidationSeverity{
+		"cue-type 2":          ValidationFailure,
+		"cue-type 3":          ValidationFailure,
+		"color 4":             ValidationFailure,
+		"group-mode 5":        ValidationFailure,
+		"required-fields 5.1": ValidationWarning,
+		"duplicate ":          ValidationFailure,
+	}
+	for rule, severity := range expected {
+		if rules[rule] != severity {
+			t.Errorf("Expected a %s problem from %s, got %v", severity, rule, report.Problems)
+		}
+	}
+	if len(report.Problems) != len(expected) {
+		t.Errorf("Expected %d problems, got %d: %v", len(expected), len(report.Problems), report.Problems)
+	}
+	if report.Cues != 7 || report.OK() {
+		t.Errorf("Expected 7 cues checked and a failed report, got %d cues", report.Cues)
+	}
+}
+
+// TestTransmitRefusesInvalidSource tests that added rules run and refuse before any OSC is sent
+func TestTransmitRefusesInvalidSource(t *testing.T) {
+	workspaceData := map[string]any{
+		"cues": []any{
+			map[string]any{"type": "audio", "number": "1", "name": "Preshow"},
+			map[string]any{"type": "audio", "number": "1", "name": "Preshow"},
+		},
+	}
+
+	// No client is configured, so any attempt to send would panic
+	workspace := &Workspace{}
+	workspace.AddValidationRule(ValidationRule{Name: "named", Check: func(cue ValidationCue) error {
+		if name, _ := cue.Data["name"].(string); strings.Contains(name, "show") {
+			return fmt.Errorf("name %q mentions the show", name)
+		}
+		return nil
+	}})
+	_, err := workspace.TransmitWorkspaceData("show.cue", workspaceData)
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected ValidationError, got %v", err)
+	}
+	if len(validationErr.Problems) != 3 {
+		t.Errorf("Expected two rule problems and a duplicate, got %v", validationErr.Problems)
+	}
+	var dupErr *DuplicateCueError
+	if !errors.As(err, &dupErr) || len(dupErr.Duplicates) != 1 {
+		t.Errorf("Expected the DuplicateCueError to be found, got %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+package qlab
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Package version, following semantic versioning. Exported APIs whose doc comment says
+// "This API is stable" only change after a deprecation period of at least one minor
+// release; deprecated ones say "Deprecated:" and name their replacement. Every other API
+// is experimental and may change in any minor release while the major version is 0.
+const (
+	VersionMajor = 0
+	VersionMinor = 1
+	VersionPatch = 0
+)
+
+// Version returns the semantic version of the qlab package
+func Version() string {
+	return fmt.Sprintf("%d.%d.%d", VersionMajor, VersionMinor, VersionPatch)
+}
+
+var (
+	deprecationWarned    = make(map[string]bool)
+	deprecationWarnedMux sync.Mutex
+)
+
+// warnDeprecated logs a deprecation warning the first time a deprecated API is used
+func warnDeprecated(logger Logger, name, replacement string) {
+	deprecationWarnedMux.Lock()
+	defer deprecationWarnedMux.Unlock()
+
+	if deprecationWarned[name] {
+		return
+	}
+	deprecationWarned[name] = true
+	logger.Warnf("%s is deprecated and will be removed in a future release; use %s instead", name, replacement)
+}
@@ -0,0 +1,23 @@
+package qlab
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestVersionIsSemver(t *testing.T) {
+	if !regexp.MustCompile(`^\d+\.\d+\.\d+$`).MatchString(Version()) {
+		t.Errorf("Version() = %q, want MAJOR.MINOR.PATCH", Version())
+	}
+}
+
+func TestWarnDeprecatedOnce(t *testing.T) {
+	warnDeprecated(NopLogger{}, "Test.Old", "Test.New")
+	warnDeprecated(NopLogger{}, "Test.Old", "Test.New")
+
+	deprecationWarnedMux.Lock()
+	defer deprecationWarnedMux.Unlock()
+	if !deprecationWarned["Test.Old"] {
+		t.Error("expected deprecation to be recorded")
+	}
+}
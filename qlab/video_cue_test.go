@@ -0,0 +1,63 @@
+package qlab
+
+import (
+	"testing"
+)
+
+// TestCreateVideoCueProperties tests that stage, layer, fill, hold, rate, and geometry are sent for video cues
+func TestCreateVideoCueProperties(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+
+	cueID, err := workspace.createCueWithoutTarget(map[string]any{
+		"type":        "video",
+		"name":        "Backdrop",
+		"stageName":   "Main",
+		"layer":       5.0,
+		"fillMode":    "fit",
+		"holdAtEnd":   true,
+		"rate":        0.5,
+		"opacity":     0.8,
+		"translation": []any{10.0, -20.0},
+		"scale":       []any{1.5, 1.5},
+		"rotation":    90.0,
+	}, "")
+	if err != nil {
+		t.Fatalf("createCueWithoutTarget failed: %v", err)
+	}
+
+	mockCue := mockServer.GetCue(cueID)
+	expected := map[string]string{
+		"stageName":           "Main",
+		"layer":               "5",
+		"fillStage":           "1",
+		"preserveAspectRatio": "1",
+		"holdAtEnd":           "1",
+		"rate":                "0.5",
+		"opacity":             "0.8",
+		"translation":         "10",
+		"scale":               "1.5",
+		"rotation":            "90",
+	}
+	for property, value := range expected {
+		if got := mockCue.Properties[property]; got != value {
+			t.Errorf("Expected %s=%q, got %q", property, value, got)
+		}
+	}
+}
+
+// TestUpdateVideoCueRejectsUnknownFillMode tests that updates surface invalid fill modes
+func TestUpdateVideoCueRejectsUnknownFillMode(t *testing.T) {
+	workspace, _ := setupWorkspaceWithCleanup(t)
+
+	cueID, err := workspace.createCueWithoutTarget(map[string]any{"type": "video", "name": "Loop"}, "")
+	if err != nil {
+		t.Fatalf("createCueWithoutTarget failed: %v", err)
+	}
+
+	if err := workspace.updateCueProperties(cueID, map[string]any{"type": "video", "fillMode": "zoom"}); err == nil {
+		t.Error("Expected error for unknown fill mode")
+	}
+	if err := workspace.updateCueProperties(cueID, map[string]any{"type": "video", "fillMode": "stretch", "layer": 2.0}); err != nil {
+		t.Errorf("Expected valid update to succeed, got %v", err)
+	}
+}
@@ -2,54 +2,121 @@ package qlab
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"path/filepath"
 	"strings"
 	"sync"
-	"time"
+	"sync/atomic"
+	"text/template"
 
 	"github.com/zenibako/qlab-golang/messages"
 
-	"github.com/charmbracelet/log"
 	"github.com/hypebeast/go-osc/osc"
 	// Removed cuejitsu dependency
 )
 
 type Workspace struct {
-	initialized       bool
-	host              string
-	port              int
-	client            *osc.Client
-	workspace_id      string
-	addressBuilder    *messages.OSCAddressBuilder
-	cueNumbers        map[string]string          // Maps cue number -> cue ID for conflict detection
-	cueListNames      map[string]string          // Maps cue list name -> cue list ID for duplicate prevention
-	inboxID           string                     // ID of the "Cuejitsu Inbox" cue list for staging
-	forceCueNumbers   bool                       // Whether to force cue number conflicts by clearing existing numbers
-	dryRun            bool                       // Whether to run in dry-run mode (no actual changes)
-	dryRunCounter     int                        // Counter for generating unique mock IDs in dry-run mode
-	replyServer       *osc.Server                // Current reply server for cleanup
-	updateServer      *osc.Server                // Persistent server for QLab updates
-	replyHandlers     map[string]chan []any      // Handlers for reply messages
-	replyHandlersMux  sync.Mutex                 // Mutex to protect replyHandlers map
-	updateHandler     func(string, []any)        // Handler for update messages
-	requestCounter    int                        // Counter for generating unique request IDs
-	cueListsCache     []any                      // Cached cue lists data to avoid duplicate requests
-	videoStagesCache  []map[string]any           // Cached video stages to avoid duplicate queries
-	onDisconnect      func()                     // Callback for when QLab appears to be disconnected
-	wasConnected      bool                       // Tracks if we were previously connected
-	consecutiveErrors int                        // Counter for consecutive timeout errors
-	serverMux         sync.Mutex                 // Mutex to protect server access
-	updateServerReady chan struct{}              // Signal that update server is ready
-	replyServerReady  chan struct{}              // Signal that reply server is ready
-	maxRetries        int                        // Maximum number of retries for OSC commands (default 0)
-	timeout           int                        // Timeout in seconds for OSC replies (default 10)
-	cueFileDirectory  string                     // Directory of the CUE file being processed (for resolving relative paths)
-	progressCallback  func(step, message string) // Callback for progress updates during operations
-	createdCueIDs     []string                   // Track IDs of cues created during current operation for rollback
-	createdCueIDsMux  sync.Mutex                 // Mutex to protect createdCueIDs slice
+	initialized        bool
+	host               string
+	port               int
+	client             *osc.Client
+	workspace_id       string
+	addressBuilder     *messages.OSCAddressBuilder
+	cueNumbers         map[string]string          // Maps cue number -> cue ID for conflict detection
+	cueListNames       map[string]string          // Maps cue list name -> cue list ID for duplicate prevention
+	inboxID            string                     // ID of the "Cuejitsu Inbox" cue list for staging
+	inboxName          string                     // Name of the staging cue list, "" for "Cuejitsu Inbox"
+	skipInbox          bool                       // Whether never to find or create the staging cue list
+	stateMu            sync.Mutex                 // Protects the cue indexes and caches, inboxID, permissions, qlabVersion and transaction
+	opMu               sync.Mutex                 // Serializes transmits, batches, imports and the other cue mutators
+	forceCueNumbers    bool                       // Whether to force cue number conflicts by clearing existing numbers
+	dryRun             bool                       // Whether to run in dry-run mode (no actual changes)
+	readOnly           bool                       // Whether messages that would change QLab are refused
+	permissions        *Permissions               // What the passcode allows, nil until Init
+	dryRunCounter      int                        // Counter for generating unique mock IDs in dry-run mode
+	updateServer       *osc.Server                // Listener for QLab's replies and updates, started by the first message
+	replyConn          net.PacketConn             // Socket of the UDP listener, nil before it starts
+	listenPort         int                        // Port asked for with SetReplyPort, 0 for the QLab port plus one
+	replyStrategy      ReplyStrategy              // How UDP replies reach the listener, "" for ReplyNegotiate
+	replyHandlers      map[string][]pendingReply  // Handlers awaiting replies, oldest first per reply address
+	replyHandlersMux   sync.Mutex                 // Mutex to protect replyHandlers map
+	updateHandler      func(string, []any)        // Handler for update messages
+	requestCounter     int                        // Counter for generating unique request IDs
+	cueListsCache      []any                      // Cached cue lists data to avoid duplicate requests
+	patchCache         map[PatchKind][]Patch      // Cached patches and video stages to avoid duplicate queries
+	patchMu            sync.Mutex                 // Mutex to protect patchCache
+	cueDetailsCache    map[string]map[string]any  // Cues enriched by GetCueByID and GetCueByNumber, keyed by uniqueID
+	onDisconnect       func()                     // Callback for when QLab appears to be disconnected, guarded by disconnects.mu
+	onDisconnectEvent  func(DisconnectEvent)      // Callback receiving the reason for each disconnect, guarded by disconnects.mu
+	disconnects        disconnectState            // Debounce state for disconnect notifications
+	wasConnected       atomic.Bool                // Tracks if we were previously connected
+	reauthRequired     atomic.Bool                // Whether QLab stopped accepting the passcode mid-session
+	consecutiveErrors  atomic.Int32               // Counter for consecutive timeout errors
+	serverMux          sync.Mutex                 // Mutex to protect server access
+	shutdown           shutdownState              // Refuses new requests once Shutdown starts, and counts those in flight
+	maxRetries         int                        // Maximum number of retries for OSC commands (default 0)
+	retryPolicy        *RetryPolicy               // Backoff and per-address retries, nil for a fixed delay
+	timeout            int                        // Timeout in seconds for OSC replies (default 10)
+	cueFileDirectory   string                     // Directory of the CUE file being processed (for resolving relative paths)
+	progressCallback   func(step, message string) // Callback for progress updates during operations
+	createdCueIDs      []string                   // Track IDs of cues created during current operation for rollback
+	createdCueIDsMux   sync.Mutex                 // Mutex to protect createdCueIDs slice
+	preserveSelection  bool                       // Whether to restore selection and playheads after transmitting
+	batchWindow        int                        // Maximum in-flight messages when batching cue creation (0 disables)
+	batchBundles       bool                       // Whether to group a cue's batched property sets into OSC bundles
+	batch              *batchState                // Active batch queue, nil when not batching; only used with opMu held
+	duplicatePolicy    DuplicatePolicy            // How duplicate cue identifiers in source data are handled
+	validationRules    []ValidationRule           // Rules checked before transmitting, after the built-in ones
+	nameTemplate       *template.Template         // Template source cue names are rendered through, nil when not used
+	notesTemplate      *template.Template         // Template source cue notes are rendered through, nil when not used
+	rememberChoices    bool                       // Whether conflict choices are kept in the cache and applied to later syncs
+	conflictPresenter  ConflictPresenter          // Renders conflicts for the terminal prompt, nil for TerminalPresenter
+	transactional      bool                       // Whether TransmitWorkspaceData rolls back all changes on failure
+	transaction        *Transaction               // Active transaction recording mutating calls, nil when none
+	maxUDPPayload      int                        // Largest OSC packet sent over UDP in bytes (0 uses DefaultMaxUDPPayload)
+	stream             streamTransport            // Transport for packets too large for UDP, nil when unavailable
+	useTCP             bool                       // Whether all OSC goes over the stream transport (NewWorkspaceTCP)
+	tcpFallback        bool                       // Whether packets over the UDP limit go over a TCP connection dialed for them
+	dimmedLevels       map[string]float64         // Master levels of cues lowered by Dim, keyed by uniqueID
+	dimMu              sync.Mutex                 // Mutex to protect dimmedLevels
+	compareUnknown     bool                       // Whether scope comparisons diff properties the library doesn't recognize
+	comparedExtra      map[string][]string        // Properties added to change detection by cue type, "" for every cue
+	qlabClock          remoteClock                // QLab's clock as read from reply bundle timetags
+	cacheStore         CacheStore                 // Where cache snapshots are kept, nil for ~/.cache/cuejitsu
+	cacheRetention     CacheRetention             // How many cache snapshots are kept
+	cacheDisabled      bool                       // Whether caching is turned off
+	pool               *Client                    // Client whose listener this workspace shares, nil when standalone
+	progressReporter   ProgressReporter           // Receives per-step progress during TransmitWorkspaceData
+	progress           *progressTracker           // Step counts of the transmit in progress, nil when none
+	hooks              transmitHooks              // Functions registered with OnCueCreated and the other hooks
+	syncDeletions      bool                       // Whether transmits delete managed cues removed from the source
+	enrichWorkers      int                        // Cues enriched in parallel when snapshotting (0 uses the default)
+	enrichProperties   []string                   // Properties queried for every cue, nil for the defaults
+	externalIDKey      string                     // Source cue property holding a stable external ID, "" when not used
+	externalIDProperty string                     // QLab property external IDs are stamped in, "" for the notes
+	mediaPreflight     bool                       // Whether TransmitWorkspaceData checks file targets before creating cues
+	mediaManager       *MediaManager              // Collects media outside the workspace folder before transmitting, nil for none
+	operationalSync    bool                       // Whether armed and flagged states are compared and sent
+	numberingStrategy  NumberingStrategy          // Numbers source cues before comparison; nil keeps the source's
+	absoluteNumbers    atomic.Bool                // Whether cue numbers are full as written, without group prefixes
+	journalPath        string                     // File ApplyPlan journals its operations to, "" for none
+	journal            *operationJournal          // Journal of the plan being applied, nil otherwise
+	passcode           string                     // Passcode given to Init, reused when reconnecting
+	reconnect          reconnectState             // Automatic reconnection settings and progress
+	heartbeat          heartbeatState             // Heartbeat loop and the connection health it measures
+	logger             Logger                     // Receives log output, nil for the global charmbracelet logger
+	sendLimits         sendLimiter                // Send rate, in-flight limit, and send queue counters
+	metrics            Metrics                    // Receives OSC metrics, nil when not reported
+	auditSink          AuditSink                  // Receives every change sent to QLab, nil when not audited
+	qlabVersion        string                     // Version QLab reported on connect, "" when unknown
 }
 
+// NewWorkspace returns a workspace that talks to QLab at host and port over UDP; call Init
+// to connect
+//
+// This API is stable.
 func NewWorkspace(host string, port int) Workspace {
 	return Workspace{
 		initialized:    false,
@@ -59,7 +126,7 @@ func NewWorkspace(host string, port int) Workspace {
 		addressBuilder: messages.NewOSCAddressBuilder(""),
 		cueNumbers:     make(map[string]string),
 		cueListNames:   make(map[string]string),
-		replyHandlers:  make(map[string]chan []any),
+		replyHandlers:  make(map[string][]pendingReply),
 		timeout:        10,
 	}
 }
@@ -75,7 +142,7 @@ func NewTestWorkspace(host string, port int, workspaceID string) *Workspace {
 		addressBuilder: messages.NewOSCAddressBuilder(workspaceID),
 		cueNumbers:     make(map[string]string),
 		cueListNames:   make(map[string]string),
-		replyHandlers:  make(map[string]chan []any),
+		replyHandlers:  make(map[string][]pendingReply),
 	}
 
 	// Start update listener to handle replies (with no-op update handler)
@@ -83,44 +150,66 @@ func NewTestWorkspace(host string, port int, workspaceID string) *Workspace {
 		// No-op for tests
 	}); err != nil {
 		// Log error but don't fail - tests may still work without update listener
-		log.Warnf("Failed to start update listener: %v", err)
+		w.log().Warnf("Failed to start update listener: %v", err)
 	}
 
 	return w
 }
 
+// WorkspaceID returns the ID of the connected QLab workspace, or an empty string before Init succeeds
+func (q *Workspace) WorkspaceID() string {
+	return q.workspace_id
+}
+
 // SetForceCueNumbers sets whether to force cue number conflicts by clearing existing numbers
+//
+// This API is stable.
 func (q *Workspace) SetForceCueNumbers(force bool) {
 	q.forceCueNumbers = force
 }
 
 // SetDryRun sets whether to run in dry-run mode (no actual changes)
+//
+// This API is stable.
 func (q *Workspace) SetDryRun(dryRun bool) {
 	q.dryRun = dryRun
 }
 
 // OnDisconnect sets a callback for when QLab appears to be disconnected
+//
+// This API is stable.
 func (q *Workspace) OnDisconnect(callback func()) {
+	q.disconnects.mu.Lock()
+	defer q.disconnects.mu.Unlock()
 	q.onDisconnect = callback
 }
 
 // SetMaxRetries sets the maximum number of retry attempts for OSC commands
+//
+// This API is stable.
 func (q *Workspace) SetMaxRetries(retries int) {
 	q.maxRetries = retries
+	if q.retryPolicy != nil {
+		q.retryPolicy.MaxRetries = retries
+	}
 }
 
 // SetTimeout sets the timeout in seconds for OSC replies
 // For large workspaces with many cues, consider increasing this to 30-60 seconds
 // Default is 10 seconds
+//
+// This API is stable.
 func (q *Workspace) SetTimeout(seconds int) {
 	q.timeout = seconds
 	if seconds > 10 {
-		log.Infof("OSC timeout increased to %d seconds for large workspace support", seconds)
+		q.log().Infof("OSC timeout increased to %d seconds for large workspace support", seconds)
 	}
 }
 
 // SetProgressCallback sets a callback function for progress updates during operations
 // The callback receives a step identifier and a human-readable message
+//
+// This API is stable.
 func (q *Workspace) SetProgressCallback(callback func(step, message string)) {
 	q.progressCallback = callback
 }
@@ -129,16 +218,28 @@ func (q *Workspace) SetProgressCallback(callback func(step, message string)) {
 // Use TransmitWorkspaceData() and ReceiveWorkspaceData() instead.
 
 // Cleanup closes the update server and cleans up resources
+//
+// Deprecated: use Close, which also releases reply servers and pending handlers.
 func (q *Workspace) Cleanup() {
-	if q.updateServer != nil {
-		if err := q.updateServer.CloseConnection(); err != nil {
-			log.Warnf("Failed to close update server: %v", err)
+	warnDeprecated(q.log(), "Workspace.Cleanup", "Workspace.Close")
+	if q.pool != nil {
+		q.Close() // The listener belongs to the client
+		return
+	}
+	q.serverMux.Lock()
+	defer q.serverMux.Unlock()
+	if q.replyConn != nil {
+		if err := q.replyConn.Close(); err != nil {
+			q.log().Warnf("Failed to close update server: %v", err)
 		}
 		q.updateServer = nil
+		q.replyConn = nil
 	}
-	// Reply servers are now self-managing and close themselves after receiving replies
 }
 
+// IsConnected reports whether Init connected to a workspace
+//
+// This API is stable.
 func (q *Workspace) IsConnected() bool {
 	return q.initialized && q.workspace_id != ""
 }
@@ -203,9 +304,18 @@ func (q *Workspace) mockDryRunResponse(address string, input string) []any {
 //   - A four-digit string (e.g., "0000", "1234", "9999") for workspaces with a passcode
 //
 // QLab only accepts four-digit integer passcodes (0000-9999)
+//
+// This API is stable.
 func (q *Workspace) Init(passcode string) ([]any, error) {
-	log.Debugf("Init called with passcode: %q (length: %d)", passcode, len(passcode))
+	q.log().Debugf("Init called with passcode: %q (length: %d)", passcode, len(passcode))
+	q.passcode = passcode
+	// QLab forgets the reply port when it restarts, so it's sent again on reconnecting
+	q.negotiateReplyPort()
 	connectAddr := q.addressBuilder.BuildAddress(messages.MsgConnect, nil)
+	if q.workspace_id != "" {
+		// Connect to this workspace rather than whichever is frontmost in QLab
+		connectAddr = q.addressBuilder.BuildAddress(messages.MsgWorkspaceConnect, nil)
+	}
 	reply := q.Send(connectAddr, passcode)
 
 	if len(reply) == 0 {
@@ -218,64 +328,69 @@ func (q *Workspace) Init(passcode string) ([]any, error) {
 		return nil, fmt.Errorf("invalid reply format from QLab")
 	}
 
-	logInfoJSON("Reply object", arg_string)
+	logInfoJSON(q.log(), "Reply object", arg_string)
 	err := json.Unmarshal([]byte(arg_string), &arg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse connection reply: %v", err)
 	}
 
-	log.Infof("Connection status: %s", arg.Status)
+	q.log().Infof("Connection status: %s", arg.Status)
 
 	// Check if the connection was successful
-	if arg.Status == "error" {
-		// Check if it's a timeout error
-		if arg.Error == "timeout waiting for reply from QLab" {
-			return reply, fmt.Errorf("connection timeout - is QLab running and accessible at %s:%d?", q.host, q.port)
-		}
-		return reply, fmt.Errorf("QLab connection failed - check passcode and workspace availability")
-	}
-
-	// Check for "badpass" in the data field (QLab returns this when passcode is incorrect)
-	if arg.Data == "badpass" {
-		return reply, fmt.Errorf("QLab authentication failed - incorrect passcode. Check your passcode in the CUE file, config file, or --passcode flag")
-	}
-
-	if arg.Status != "ok" {
+	replyErr := newQLabError("QLab connection failed", arg_string)
+	switch {
+	case errors.Is(replyErr, ErrTimeout):
+		return reply, fmt.Errorf("connection %w - is QLab running and accessible at %s:%d?", ErrTimeout, q.host, q.port)
+	case errors.Is(replyErr, ErrAuthFailed):
+		// QLab returns "badpass" in the data field when the passcode is incorrect
+		return reply, fmt.Errorf("%w - incorrect passcode. Check your passcode in the CUE file, config file, or --passcode flag", ErrAuthFailed)
+	case arg.Status == "error":
+		return reply, fmt.Errorf("%w - check passcode and workspace availability: %w", ErrWorkspaceNotFound, replyErr)
+	case arg.Status != "ok":
 		return reply, fmt.Errorf("unexpected connection status: %s", arg.Status)
+	case arg.WorkspaceId == "":
+		return reply, fmt.Errorf("%w - QLab did not report a workspace ID", ErrWorkspaceNotFound)
 	}
 
 	q.workspace_id = arg.WorkspaceId
 	q.addressBuilder = messages.NewOSCAddressBuilder(q.workspace_id)
+	q.setPermissions(arg.Data)
 	q.initialized = true
-	log.Info("Successfully initialized workspace", "workspace_id", q.workspace_id)
+	q.log().Info("Successfully initialized workspace", "workspace_id", q.workspace_id)
 
 	// Send /alwaysReply 1 to ensure cue messages don't time out
 	alwaysReplyReply := q.Send("/alwaysReply", "1")
 	if len(alwaysReplyReply) > 0 {
 		if jsonStr, ok := alwaysReplyReply[0].(string); ok {
-			logInfoJSON("alwaysReply response", jsonStr)
+			logInfoJSON(q.log(), "alwaysReply response", jsonStr)
 		} else {
-			log.Info("alwaysReply response", "data", alwaysReplyReply[0])
+			q.log().Info("alwaysReply response", "data", alwaysReplyReply[0])
 		}
 	}
 
-	// Ensure "Cuejitsu Inbox" cue list exists for staging imported content
-	q.inboxID, err = q.ensureCuejitsuInbox()
-	if err != nil {
-		log.Warnf("Failed to ensure Cuejitsu Inbox exists: %v", err)
-		// Don't fail initialization if inbox creation fails
-	}
+	// Addresses and features differ between QLab versions
+	q.detectQLabVersion()
 
 	// Index existing cues for conflict detection
 	err = q.indexExistingCues()
 	if err != nil {
-		log.Warnf("Failed to index existing cues: %v", err)
+		q.log().Warnf("Failed to index existing cues: %v", err)
 		// Don't fail initialization if cue indexing fails
 	}
 
+	// Pick up an existing staging cue list; a missing one is created by the first import
+	if !q.skipInbox {
+		q.setInboxID(q.lookupCueList(q.inboxListName()))
+	}
+
 	return reply, nil
 }
 
+// GetAddress scopes an address to the connected workspace, e.g. /cue/1/go becomes
+// /workspace/{id}/cue/1/go. Application-level addresses, addresses already scoped, and
+// addresses before Init are returned unchanged.
+//
+// This API is stable.
 func (q *Workspace) GetAddress(msg string) string {
 	if q.addressBuilder == nil {
 		return ""
@@ -292,6 +407,7 @@ func (q *Workspace) GetAddress(msg string) string {
 	applicationLevelCommands := []string{
 		"/connect",
 		"/disconnect",
+		"/open",
 		"/alwaysReply",
 		"/version",
 		"/updates",
@@ -312,6 +428,9 @@ func (q *Workspace) GetAddress(msg string) string {
 	return fmt.Sprintf("/workspace/%s%s", q.workspace_id, msg)
 }
 
+// GetContent sends a workspace-scoped message and returns QLab's raw reply, "" if none
+//
+// This API is stable.
 func (q *Workspace) GetContent(msg string) string {
 	reply := q.Send(q.GetAddress(msg), "")
 	if len(reply) > 0 {
@@ -322,6 +441,9 @@ func (q *Workspace) GetContent(msg string) string {
 	return ""
 }
 
+// GetRunningCues returns the cues currently running
+//
+// This API is stable.
 func (q *Workspace) GetRunningCues() []map[string]any {
 	address := q.GetAddress("/runningCues/shallow")
 	reply := q.Send(address, "")
@@ -347,6 +469,9 @@ func (q *Workspace) GetRunningCues() []map[string]any {
 	return runningCues
 }
 
+// GetSelectedCues returns the selected cues, in selection order
+//
+// This API is stable.
 func (q *Workspace) GetSelectedCues() []map[string]any {
 	address := q.GetAddress("/selectedCues/shallow")
 	reply := q.Send(address, "")
@@ -372,48 +497,287 @@ func (q *Workspace) GetSelectedCues() []map[string]any {
 	return selectedCues
 }
 
+// TransmitOption configures a single TransmitWorkspaceData call
+type TransmitOption func(*transmitOptions)
+
+// transmitOptions holds the settings TransmitOptions configure
+type transmitOptions struct {
+	resolver        ConflictResolver  // Resolves conflicts; nil prompts in the terminal
+	filter          *transmitFilter   // Restricts the cues transmitted; nil transmits them all
+	absoluteNumbers bool              // Takes cue numbers as written for this transmit
+	numbering       NumberingStrategy // Overrides the workspace's numbering strategy
+}
+
+// WithConflictResolver resolves conflicts with resolver instead of prompting in the terminal
+func WithConflictResolver(resolver ConflictResolver) TransmitOption {
+	return func(o *transmitOptions) {
+		o.resolver = resolver
+	}
+}
+
 // TransmitWorkspaceData transmits workspace data to QLab with three-way comparison and conflict resolution.
 // The caller is responsible for parsing the file and providing the workspace data.
 // filePath is used for caching and logging purposes.
 // Returns the comparison results which the caller can use to update source files if needed.
-func (q *Workspace) TransmitWorkspaceData(filePath string, workspaceData map[string]any) (*ThreeWayComparison, error) {
-	// Store the file directory for resolving relative file paths
+//
+// This API is stable.
+func (q *Workspace) TransmitWorkspaceData(filePath string, workspaceData map[string]any, opts ...TransmitOption) (comparison *ThreeWayComparison, err error) {
+	// One transmit at a time; queries from other goroutines go ahead meanwhile, and
+	// whatever they cached is stale once the transmit is done
+	if err := q.checkWritable("transmit"); err != nil {
+		return nil, err
+	}
+
+	q.opMu.Lock()
+	defer q.opMu.Unlock()
+	defer q.InvalidateCueCache()
+
+	var options transmitOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	// Absolute numbers apply to everything this transmit identifies, in the source and QLab
+	if options.absoluteNumbers && !q.absoluteNumbers.Load() {
+		q.absoluteNumbers.Store(true)
+		defer q.absoluteNumbers.Store(false)
+	}
+
+	prepared, err := q.prepareTransmit(filePath, workspaceData, options)
+	if err != nil {
+		return nil, err
+	}
+	if err := q.collectTransmitMedia(filePath, prepared, q.dryRun); err != nil {
+		return nil, err
+	}
+	defer func() { prepared.annotate(comparison) }()
+	workspaceData = prepared.data
+
+	return q.guardTransmit(filePath, func() (*ThreeWayComparison, error) {
+		return q.transmitWorkspaceData(filePath, workspaceData, options)
+	})
+}
+
+// guardTransmit runs apply with a transmit's safeguards: the cue indexes are repaired if it
+// fails, the operator's selection is preserved, and under SetTransactional its changes are
+// rolled back if it fails
+func (q *Workspace) guardTransmit(filePath string, apply func() (*ThreeWayComparison, error)) (comparison *ThreeWayComparison, err error) {
+	// A failed transmit can leave the cue indexes out of step with QLab; resynchronize them
+	defer func() {
+		if err != nil && !q.dryRun {
+			q.repairIndexesAfterFailure()
+		}
+	}()
+
+	if err := q.setCueFileDirectory(filePath); err != nil {
+		return nil, err
+	}
+
+	// Record the operator's selection and playheads so the sync doesn't disturb them
+	if q.preserveSelection && !q.dryRun {
+		snapshot, err := q.RecordSelection()
+		if err != nil {
+			q.log().Warnf("Failed to record selection before transmit: %v", err)
+		}
+		defer func() {
+			if err := q.RestoreSelection(snapshot); err != nil {
+				q.log().Warnf("Failed to restore selection after transmit: %v", err)
+			}
+		}()
+	}
+
+	// Record every change so a failure part-way through can be undone
+	if q.transactional && !q.dryRun && q.activeTransaction() == nil {
+		tx, err := q.BeginTransaction()
+		if err != nil {
+			return nil, err
+		}
+		comparison, err := apply()
+		if err != nil {
+			if rollbackErr := tx.rollback(); rollbackErr != nil {
+				return nil, fmt.Errorf("%w (rollback failed: %w)", err, rollbackErr)
+			}
+			return nil, fmt.Errorf("%w (changes rolled back)", err)
+		}
+		tx.Commit()
+		return comparison, nil
+	}
+
+	return apply()
+}
+
+// setCueFileDirectory stores the directory of the cue file for resolving relative file paths
+func (q *Workspace) setCueFileDirectory(filePath string) error {
 	absFilePath, err := filepath.Abs(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get absolute path: %v", err)
+		return fmt.Errorf("failed to get absolute path: %v", err)
 	}
 	q.cueFileDirectory = filepath.Dir(absFilePath)
-	log.Debug("Set cue file directory", "directory", q.cueFileDirectory)
+	q.log().Debug("Set cue file directory", "directory", q.cueFileDirectory)
+	return nil
+}
+
+// preparedTransmit is source data made ready for comparison, with what preparing it found
+type preparedTransmit struct {
+	data       map[string]any
+	duplicates []DuplicateCue      // Duplicates renumbered under DuplicatePolicySuffix
+	media      *MediaCollectReport // Media collected, nil without a media manager
+}
+
+// annotate records what preparing the data found in the comparison made from it
+func (p *preparedTransmit) annotate(comparison *ThreeWayComparison) {
+	if comparison == nil {
+		return
+	}
+	if len(p.duplicates) > 0 {
+		comparison.Duplicates = p.duplicates
+	}
+	if p.media != nil {
+		comparison.Media = p.media
+	}
+}
+
+// prepareTransmit makes source data ready for comparison: envelopes are unwrapped, cues
+// numbered and duplicates suffixed, the data validated, templates rendered and the media
+// checked. Media is collected separately, by collectTransmitMedia.
+func (q *Workspace) prepareTransmit(filePath string, workspaceData map[string]any, options transmitOptions) (*preparedTransmit, error) {
+	prepared := &preparedTransmit{}
+
+	// Envelopes are compared as cue lists holding their cues
+	if isEnvelope(workspaceData) {
+		if from, _ := workspaceData["workspaceID"].(string); from != "" && from != q.workspace_id {
+			q.log().Infof("Transmitting cue lists received from workspace %s to workspace %s", from, q.workspace_id)
+		}
+		workspaceData = UnwrapEnvelope(workspaceData)
+	}
+
+	// Number the source cues with the numbering strategy, around the numbers QLab's cues hold
+	strategy := q.numberingStrategy
+	if options.numbering != nil {
+		strategy = options.numbering
+	}
+	if strategy != nil {
+		allocator := NewCueNumberAllocator(strategy)
+		allocator.Absolute = q.absoluteNumbers.Load()
+		if err := q.ReserveCueNumbers(allocator); err != nil {
+			return nil, err
+		}
+		var allocations []CueNumberAllocation
+		workspaceData, allocations = allocator.Apply(workspaceData)
+		for _, allocation := range allocations {
+			if allocation.Conflict != "" {
+				q.log().Warnf("Cue %s at %s is numbered %s like the cue at %s", allocation.Name, allocation.Path, allocation.Number, allocation.Conflict)
+			}
+		}
+	}
+
+	// Renumber duplicate cues, reporting them in place of those the comparison would find
+	if q.duplicatePolicy == DuplicatePolicySuffix {
+		workspaceData, prepared.duplicates = suffixDuplicateCues(workspaceData, q.sourceCueKey)
+		for _, dup := range prepared.duplicates {
+			q.log().Infof("Duplicate cue identifier %s at %s renumbered %s", dup.Identifier, strings.Join(dup.Paths[1:], ", "), strings.Join(dup.Suffixed, ", "))
+		}
+	}
+
+	// Refuse unknown cue types, bad colors and group modes, duplicate cue identifiers and
+	// anything the added rules catch, all at once and before any OSC is sent
+	if err := q.validate(workspaceData); err != nil {
+		return nil, err
+	}
+
+	// Names and notes are rendered through the naming templates before anything is compared
+	workspaceData, err := q.RenderCueTemplates(workspaceData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refuse to create cues whose media is missing
+	if q.mediaPreflight {
+		report, err := q.CheckMedia(filePath, workspaceData)
+		if err != nil {
+			return nil, err
+		}
+		if !report.OK() {
+			return nil, &MediaError{Problems: report.Problems}
+		}
+	}
+
+	prepared.data = workspaceData
+	return prepared, nil
+}
+
+// collectTransmitMedia brings media from outside the workspace folder into it with the media
+// manager, if there is one, pointing the prepared cues at the copies. In a dry run nothing is
+// copied but the cues are pointed at where the copies would be.
+func (q *Workspace) collectTransmitMedia(filePath string, prepared *preparedTransmit, dryRun bool) error {
+	if q.mediaManager == nil {
+		return nil
+	}
+	data, report, err := q.collectMedia(filePath, prepared.data, dryRun)
+	if err != nil {
+		return err
+	}
+	prepared.data, prepared.media = data, report
+	return nil
+}
 
+// transmitWorkspaceData compares the source data with QLab and applies the changes
+func (q *Workspace) transmitWorkspaceData(filePath string, workspaceData map[string]any, options transmitOptions) (*ThreeWayComparison, error) {
 	// Report progress: comparing changes
 	if q.progressCallback != nil {
 		q.progressCallback("compare", "Comparing with QLab workspace...")
 	}
+	progress := q.startProgress()
+	defer q.finishProgress()
 
 	// Perform three-way comparison to detect changes
-	log.Debug("Starting three-way comparison", "file", filePath)
+	q.log().Debug("Starting three-way comparison", "file", filePath)
 	comparison, err := q.PerformThreeWayComparison(filePath, workspaceData)
+	progress.step(PhaseCompare, "")
+	if err != nil && options.filter != nil {
+		return nil, fmt.Errorf("selective transmit needs change detection: %w", err)
+	}
 	if err != nil {
-		log.Debug("Change detection failed, proceeding without cache optimization", "error", err)
+		q.log().Debug("Change detection failed, proceeding without cache optimization", "error", err)
 		// Fallback to old behavior if change detection fails
 		err = q.transmitCueFileWithoutChangeDetection(workspaceData)
 		return nil, err
 	}
 
+	// Leave cues outside the selection alone before anything is reported or resolved
+	if options.filter != nil {
+		q.applyTransmitFilter(comparison, workspaceData, options.filter)
+	}
+
+	if err := q.resolveTransmitConflicts(comparison, options.resolver); err != nil {
+		return nil, err
+	}
+	if err := q.applyComparison(filePath, workspaceData, comparison); err != nil {
+		return nil, err
+	}
+
+	// Return comparison results so caller can update source file if needed
+	// (for cues where user chose "Keep QLab version")
+	return comparison, nil
+}
+
+// resolveTransmitConflicts finds the conflicts of a comparison and resolves them with the
+// resolver, nil to prompt in the terminal, and the remembered choices
+func (q *Workspace) resolveTransmitConflicts(comparison *ThreeWayComparison, resolver ConflictResolver) error {
 	// Initialize field-level tracking if not present
 	if comparison.QLabChosenFields == nil {
 		comparison.QLabChosenFields = make(map[string]map[string]bool)
 	}
 
 	// Print detailed results of the three-way comparison
-	log.Debug("Printing three-way comparison results")
-	log.Debug("Three-way comparison summary",
+	q.log().Debug("Printing three-way comparison results")
+	q.log().Debug("Three-way comparison summary",
 		"has_cache", comparison.HasCache,
 		"has_qlab_data", comparison.HasQLabData,
 		"cache_matches_qlab", comparison.CacheMatchesQLab)
-	log.Debug("Three-way comparison results", "cue_result_count", len(comparison.CueResults))
+	q.log().Debug("Three-way comparison results", "cue_result_count", len(comparison.CueResults))
 	for cueNumber, result := range comparison.CueResults {
-		log.Debug("Cue change detected",
+		q.log().Debug("Cue change detected",
 			"cue_number", cueNumber,
 			"action", result.Action,
 			"has_changed", result.HasChanged,
@@ -422,19 +786,25 @@ func (q *Workspace) TransmitWorkspaceData(filePath string, workspaceData map[str
 	q.PrintThreeWayComparisonResults(comparison)
 
 	// Check for conflicts that need user resolution
-	log.Debug("Identifying conflicts")
+	q.log().Debug("Identifying conflicts")
 	conflicts, err := q.IdentifyConflicts(comparison)
 	if err != nil {
-		return nil, fmt.Errorf("failed to identify conflicts: %v", err)
+		return fmt.Errorf("failed to identify conflicts: %v", err)
 	}
-	log.Debug("Found", len(conflicts), "conflicts")
+	q.log().Debug("Found", len(conflicts), "conflicts")
+	conflicts = q.applyRememberedResolutions(comparison, conflicts)
 
-	// Prompt user for conflict resolution if needed
+	// Resolve conflicts with the caller's resolver, or prompt the user
 	if len(conflicts) > 0 {
-		log.Debug("Prompting user for conflict resolution")
-		err = q.PromptUserForConflictResolution(conflicts, comparison)
+		if resolver != nil {
+			q.log().Debug("Resolving conflicts with configured resolver")
+			err = applyResolver(resolver, conflicts, comparison)
+		} else {
+			q.log().Debug("Prompting user for conflict resolution")
+			err = q.PromptUserForConflictResolution(conflicts, comparison)
+		}
 		if err != nil {
-			return nil, fmt.Errorf("failed to resolve conflicts: %v", err)
+			return fmt.Errorf("failed to resolve conflicts: %v", err)
 		}
 
 		// Mark conflicts as resolved
@@ -445,16 +815,21 @@ func (q *Workspace) TransmitWorkspaceData(filePath string, workspaceData map[str
 
 	// Generate merged scope result if scope comparison was performed
 	if comparison.WorkspaceScope != nil {
-		log.Debug("Generating merged scope result")
+		q.log().Debug("Generating merged scope result")
 		mergedScope, err := q.GenerateMergedScope(comparison.WorkspaceScope, comparison)
 		if err != nil {
-			log.Warnf("Failed to generate merged scope: %v", err)
+			q.log().Warnf("Failed to generate merged scope: %v", err)
 		} else {
 			comparison.MergedResult = mergedScope
-			log.Infof("Merged result generated with %d top-level scopes", len(mergedScope.ChildScopes))
+			q.log().Infof("Merged result generated with %d top-level scopes", len(mergedScope.ChildScopes))
 		}
 	}
 
+	return nil
+}
+
+// applyComparison sends the changes of a resolved comparison to QLab and caches the source
+func (q *Workspace) applyComparison(filePath string, workspaceData map[string]any, comparison *ThreeWayComparison) error {
 	// Report progress: applying changes
 	if q.progressCallback != nil {
 		changedCount := 0
@@ -471,10 +846,10 @@ func (q *Workspace) TransmitWorkspaceData(filePath string, workspaceData map[str
 	}
 
 	// Process the workspace data with change detection
-	log.Debug("Transmitting with change detection")
-	err = q.transmitCueFileWithChangeDetection(workspaceData, comparison)
+	q.log().Debug("Transmitting with change detection")
+	err := q.transmitCueFileWithChangeDetection(workspaceData, comparison)
 	if err != nil {
-		return nil, fmt.Errorf("failed to transmit cue file with change detection: %v", err)
+		return fmt.Errorf("failed to transmit cue file with change detection: %w", err)
 	}
 
 	// Report progress: saving cache
@@ -483,23 +858,48 @@ func (q *Workspace) TransmitWorkspaceData(filePath string, workspaceData map[str
 	}
 
 	// Save cache after successful transmission
-	log.Debug("Saving cache after successful transmission")
+	q.log().Debug("Saving cache after successful transmission")
 	err = q.writeCueFileToCache(filePath, workspaceData, nil, comparison)
 	if err != nil {
 		// Log warning but don't fail the transmission
-		log.Debug("Warning: Failed to save cache", "error", err)
+		q.log().Debug("Warning: Failed to save cache", "error", err)
 	} else {
-		log.Debug("Cache saved successfully")
+		q.log().Debug("Cache saved successfully")
 	}
 
-	// Return comparison results so caller can update source file if needed
-	// (for cues where user chose "Keep QLab version")
-	return comparison, nil
+	return nil
+}
+
+// ReceiveOption configures a single ReceiveWorkspaceData call
+type ReceiveOption func(*receiveOptions)
+
+// receiveOptions holds the settings ReceiveOptions configure
+type receiveOptions struct {
+	relativePaths bool   // Whether file targets are rewritten relative to pathRoot
+	pathRoot      string // Folder file targets are made relative to, "" for the workspace's basePath
+}
+
+// WithRelativePaths rewrites the file targets inside root relative to it, with forward
+// slashes, so the exported data can move with the show folder. root "" is the workspace's
+// basePath. File targets outside root stay absolute. Transmitting resolves relative file
+// targets against the cue file's folder, so save the data there to round-trip it.
+func WithRelativePaths(root string) ReceiveOption {
+	return func(o *receiveOptions) {
+		o.relativePaths = true
+		o.pathRoot = root
+	}
 }
 
 // ReceiveWorkspaceData queries the current QLab workspace state and returns the cues data.
 // The caller is responsible for writing this data to a file if needed.
-func (q *Workspace) ReceiveWorkspaceData() ([]any, error) {
+//
+// This API is stable.
+func (q *Workspace) ReceiveWorkspaceData(opts ...ReceiveOption) ([]any, error) {
+	var options receiveOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	// Report progress: querying QLab
 	if q.progressCallback != nil {
 		q.progressCallback("query", "Querying QLab workspace...")
@@ -517,12 +917,41 @@ func (q *Workspace) ReceiveWorkspaceData() ([]any, error) {
 
 	cuesData := q.extractCuesFromWorkspace(currentWorkspace)
 	if len(cuesData) == 0 {
-		log.Warn("No cues found in QLab workspace")
+		q.log().Warn("No cues found in QLab workspace")
+	}
+
+	if options.relativePaths {
+		root := options.pathRoot
+		if root == "" {
+			if root, err = q.getWorkspaceBasePath(); err != nil {
+				return nil, fmt.Errorf("failed to get workspace basePath: %v", err)
+			}
+		}
+		cuesData = q.relativeFileTargets(cuesData, root)
 	}
 
 	return cuesData, nil
 }
 
+// relativeFileTargets returns a copy of cues with the absolute file targets inside root
+// made relative to it
+func (q *Workspace) relativeFileTargets(cues []any, root string) []any {
+	cues, _ = cloneMergeValue(cues).([]any)
+	eachSourceCue(map[string]any{"cues": cues}, q.sourceCueKey, func(cue map[string]any, key string) {
+		fileTarget, _ := cue["fileTarget"].(string)
+		if fileTarget == "" || !filepath.IsAbs(fileTarget) {
+			return
+		}
+		rel, inside := pathInside(root, fileTarget)
+		if !inside {
+			q.log().Debug("Leaving file target outside the root absolute", "cue", key, "file_target", fileTarget, "root", root)
+			return
+		}
+		cue["fileTarget"] = filepath.ToSlash(rel)
+	})
+	return cues
+}
+
 func (q *Workspace) extractCuesFromWorkspace(workspace map[string]any) []any {
 	var cuesData []any
 
@@ -559,21 +988,33 @@ func (q *Workspace) transmitCueFileWithoutChangeDetection(workspaceData map[stri
 	if len(cuesData) == 0 {
 		return fmt.Errorf("no cues found in CUE file")
 	}
+	q.ensureInbox()
 
-	// Process each cue
-	for _, cueAny := range cuesData {
-		cueData, ok := cueAny.(map[string]any)
-		if !ok {
-			continue // Skip invalid cue data
-		}
+	processCues := func() error {
+		var selectedList string
+		for _, cueAny := range cuesData {
+			cueData, ok := cueAny.(map[string]any)
+			if !ok {
+				continue // Skip invalid cue data
+			}
 
-		err := q.processCueList(cueData, "")
-		if err != nil {
-			return fmt.Errorf("failed to process cue: %v", err)
+			if err := q.routeCue(cueData, &selectedList); err != nil {
+				return err
+			}
+			err := q.processCueList(cueData, "")
+			if err != nil {
+				return fmt.Errorf("failed to process cue: %w", err)
+			}
 		}
+		return nil
 	}
 
-	return nil
+	// Pipeline property sets when batching is enabled
+	if q.batchWindow > 0 {
+		_, err := q.runBatched(processCues)
+		return err
+	}
+	return processCues()
 }
 
 // transmitCueFileWithChangeDetection processes cues using change detection results
@@ -599,27 +1040,59 @@ func (q *Workspace) transmitCueFileWithChangeDetection(workspaceData map[string]
 		NumberToID:      make(map[string]string),
 		CuesWithTargets: []CueTarget{},
 	}
+	q.countTransmitSteps(q.progress, cuesData, comparison.CueResults)
+
+	// Delete first so the numbers of removed cues are free for new ones
+	if err := q.applyDeletions(comparison.CueResults); err != nil {
+		return err
+	}
+	// Move reordered cues before new ones are placed among them
+	if err := q.applyMoves(comparison.CueResults); err != nil {
+		return err
+	}
+	if createsCues(comparison.CueResults) {
+		q.ensureInbox()
+	}
 
 	// Process each cue with change detection
-	log.Debug("About to process cues from workspace data", "cue_count", len(cuesData))
-	for i, cueAny := range cuesData {
-		cueData, ok := cueAny.(map[string]any)
-		if !ok {
-			log.Debug("Skipping invalid cue data", "index", i)
-			continue // Skip invalid cue data
-		}
+	q.log().Debug("About to process cues from workspace data", "cue_count", len(cuesData))
+	processCues := func() error {
+		var selectedList string
+		for i, cueAny := range cuesData {
+			cueData, ok := cueAny.(map[string]any)
+			if !ok {
+				q.log().Debug("Skipping invalid cue data", "index", i)
+				continue // Skip invalid cue data
+			}
 
-		log.Debug("Processing cue", "current", i+1, "total", len(cuesData))
-		err := q.processCueListWithMappingAndChangeDetection(cueData, "", mapping, comparison.CueResults)
-		if err != nil {
-			log.Debug("ERROR - Failed to process cue", "index", i+1, "error", err)
-			return fmt.Errorf("failed to process cue: %v", err)
+			if err := q.routeCue(cueData, &selectedList); err != nil {
+				return err
+			}
+
+			q.log().Debug("Processing cue", "current", i+1, "total", len(cuesData))
+			err := q.processCueListWithMappingAndChangeDetection(cueData, "", mapping, comparison.CueResults)
+			if err != nil {
+				q.log().Debug("ERROR - Failed to process cue", "index", i+1, "error", err)
+				return fmt.Errorf("failed to process cue: %w", err)
+			}
+			q.log().Debug("Completed processing cue", "current", i+1, "total", len(cuesData))
 		}
-		log.Debug("Completed processing cue", "current", i+1, "total", len(cuesData))
+		return nil
+	}
+
+	// When batching, property sets are flushed before targets are set so target numbers exist
+	var err error
+	if q.batchWindow > 0 {
+		_, err = q.runBatched(processCues)
+	} else {
+		err = processCues()
+	}
+	if err != nil {
+		return err
 	}
 
 	// Set cue targets using the mapping
-	err := q.setCueTargets(mapping)
+	err = q.setCueTargets(mapping)
 	if err != nil {
 		return fmt.Errorf("failed to set cue targets: %v", err)
 	}
@@ -640,13 +1113,13 @@ func (q *Workspace) setCueListProperty(cueListID, property, value string) error
 			err := json.Unmarshal([]byte(replyStr), &replyData)
 			if err == nil {
 				if status, ok := replyData["status"].(string); ok && status == "error" {
-					return formatErrorWithJSON("QLab error setting cue list property", replyStr)
+					return newQLabError("QLab error setting cue list property", replyStr)
 				}
 			}
 		}
 	}
 
-	log.Debug("Set cue list property", "property", property, "value", value, "cue_list_id", cueListID)
+	q.log().Debug("Set cue list property", "property", property, "value", value, "cue_list_id", cueListID)
 	return nil
 }
 
@@ -663,7 +1136,7 @@ func (q *Workspace) indexCuesFromWorkspace(workspace map[string]any) map[string]
 	for k := range workspace {
 		keys = append(keys, k)
 	}
-	log.Debug("Workspace keys found", "keys", keys)
+	q.log().Debug("Workspace keys found", "keys", keys)
 
 	// Extract cue lists from workspace data structure
 	var cuesData []any
@@ -672,24 +1145,24 @@ func (q *Workspace) indexCuesFromWorkspace(workspace map[string]any) map[string]
 	if cues, ok := workspace["cues"].([]any); ok {
 		// Direct cues array (source CUE format)
 		cuesData = cues
-		log.Debug("Found cues via direct cues array", "cue_count", len(cuesData))
+		q.log().Debug("Found cues via direct cues array", "cue_count", len(cuesData))
 	} else if workspaceData, ok := workspace["workspace"].(map[string]any); ok {
 		// Nested workspace structure (parsed CUE file format)
 		if cues, ok := workspaceData["cues"].([]any); ok {
 			cuesData = cues
-			log.Debug("Found cues via nested workspace structure", "cue_count", len(cuesData))
+			q.log().Debug("Found cues via nested workspace structure", "cue_count", len(cuesData))
 		}
 	} else if data, ok := workspace["data"].(map[string]any); ok {
 		// QLab response format with data wrapper containing cueLists key
-		log.Debug("Found data map, checking for cueLists")
+		q.log().Debug("Found data map, checking for cueLists")
 		if cueLists, ok := data["cueLists"].([]any); ok {
-			log.Debug("Found cueLists in data map", "cue_list_count", len(cueLists))
+			q.log().Debug("Found cueLists in data map", "cue_list_count", len(cueLists))
 			// Extract cues from cue lists
 			for _, cueListData := range cueLists {
 				if cueList, ok := cueListData.(map[string]any); ok {
 					if listCues, ok := cueList["cues"].([]any); ok {
 						cuesData = append(cuesData, listCues...)
-						log.Debug("Added cues from cueList", "cue_count", len(listCues))
+						q.log().Debug("Added cues from cueList", "cue_count", len(listCues))
 					}
 				}
 			}
@@ -697,11 +1170,11 @@ func (q *Workspace) indexCuesFromWorkspace(workspace map[string]any) map[string]
 		// Also check for direct cues array in data
 		if directCues, ok := data["cues"].([]any); ok {
 			cuesData = append(cuesData, directCues...)
-			log.Debug("Added direct cues from data", "cue_count", len(directCues))
+			q.log().Debug("Added direct cues from data", "cue_count", len(directCues))
 		}
 	} else if cueLists, ok := workspace["data"].([]any); ok {
 		// QLab response format where data is directly an array of cue lists
-		log.Debug("Found data array with cueLists", "cue_list_count", len(cueLists))
+		q.log().Debug("Found data array with cueLists", "cue_list_count", len(cueLists))
 		for i, cueListData := range cueLists {
 			if cueList, ok := cueListData.(map[string]any); ok {
 				// Debug: show keys in each cueList
@@ -709,27 +1182,27 @@ func (q *Workspace) indexCuesFromWorkspace(workspace map[string]any) map[string]
 				for k := range cueList {
 					listKeys = append(listKeys, k)
 				}
-				log.Debug("CueList keys found", "index", i, "keys", listKeys)
+				q.log().Debug("CueList keys found", "index", i, "keys", listKeys)
 
 				if cuesValue, exists := cueList["cues"]; exists {
-					log.Debug("CueList cues value found", "index", i, "type", fmt.Sprintf("%T", cuesValue))
+					q.log().Debug("CueList cues value found", "index", i, "type", fmt.Sprintf("%T", cuesValue))
 					if listCues, ok := cuesValue.([]any); ok {
 						cuesData = append(cuesData, listCues...)
-						log.Debug("Added cues from cueList array", "cue_count", len(listCues))
+						q.log().Debug("Added cues from cueList array", "cue_count", len(listCues))
 					} else {
-						log.Debug("CueList cues exists but wrong type", "index", i, "type", fmt.Sprintf("%T", cuesValue))
+						q.log().Debug("CueList cues exists but wrong type", "index", i, "type", fmt.Sprintf("%T", cuesValue))
 					}
 				} else {
-					log.Debug("CueList has no cues key", "index", i)
+					q.log().Debug("CueList has no cues key", "index", i)
 				}
 			}
 		}
 	}
 
 	// Recursively index all cues
-	log.Debug("Processing total cues for indexing", "cue_count", len(cuesData))
+	q.log().Debug("Processing total cues for indexing", "cue_count", len(cuesData))
 	q.indexCuesRecursively(cuesData, "", cueIndex)
-	log.Debug("Final cue index complete", "entry_count", len(cueIndex))
+	q.log().Debug("Final cue index complete", "entry_count", len(cueIndex))
 
 	return cueIndex
 }
@@ -742,60 +1215,11 @@ func (q *Workspace) indexCuesRecursively(cuesData []any, parentNumber string, cu
 			continue
 		}
 
-		// Extract cue number
-		var cueNumber string
-		if num, ok := cue["number"]; ok && num != nil {
-			switch v := num.(type) {
-			case string:
-				cueNumber = v
-			case float64:
-				if v == float64(int64(v)) && v >= 0 && v <= 999 {
-					cueNumber = fmt.Sprintf("%.1f", v)
-				} else {
-					cueNumber = fmt.Sprintf("%g", v)
-				}
-			case int64:
-				cueNumber = fmt.Sprintf("%d", v)
-			case int:
-				cueNumber = fmt.Sprintf("%d", v)
-			default:
-				cueNumber = fmt.Sprintf("%v", v)
-			}
-		}
-
-		// Build full cue number with parent prefix (same logic as processing)
-		fullNumber := cueNumber
-		if parentNumber != "" && cueNumber != "" {
-			if strings.Contains(cueNumber, ".") {
-				fullNumber = cueNumber
-			} else {
-				fullNumber = parentNumber + "." + cueNumber
-			}
-		}
-
-		// Add to index if we have a number
-		if fullNumber != "" {
-			cueIndex[fullNumber] = cue
-		} else {
-			// Fallback: use position-based identification for cues without numbers
-			// Include parent context, cue name, and position to create unique identifier
-			cueName, _ := cue["name"].(string)
-			cueType, _ := cue["type"].(string)
-
-			// Create composite key: parent@position[type:name]
-			// Normalize type to lowercase for consistent matching between source and QLab data
-			normalizedType := strings.ToLower(cueType)
-			var positionKey string
-			if parentNumber != "" {
-				positionKey = fmt.Sprintf("%s@%d[%s:%s]", parentNumber, i, normalizedType, cueName)
-			} else {
-				positionKey = fmt.Sprintf("@%d[%s:%s]", i, normalizedType, cueName)
-			}
-
-			// Only index if we have enough identifying information
-			if cueType != "" || cueName != "" {
-				cueIndex[positionKey] = cue
-				log.Debug("Indexed cue by position", "position_key", positionKey, "parent", parentNumber, "index", i, "type", cueType, "name", cueName)
+		key, fullNumber := q.sourceCueKey(cue, parentNumber, i)
+		if key != "" {
+			cueIndex[key] = cue
+			if fullNumber == "" {
+				q.log().Debug("Indexed cue by position", "position_key", key, "parent", parentNumber, "index", i)
 			}
 		}
 
@@ -806,79 +1230,73 @@ func (q *Workspace) indexCuesRecursively(cuesData []any, parentNumber string, cu
 	}
 }
 
-// createCuejitsuInbox creates a new "Cuejitsu Inbox" cue list
-func (q *Workspace) createCuejitsuInbox() (string, error) {
-	// Create a new cue list using /new list
-	address := fmt.Sprintf("/workspace/%s/new", q.workspace_id)
-	reply := q.Send(address, "list")
+// sourceCueKey returns the index key for a cue at the given position and its full cue number.
+// Numbered cues are keyed by full number; unnumbered cues fall back to a position key of the
+// form parent@position[type:name]. The key is empty if the cue has no identifying information.
+func sourceCueKey(cue map[string]any, parentNumber string, position int) (key string, fullNumber string) {
+	// Full cue number with the parent prefix, as processing builds it
+	cueNumber := formatCueNumber(cue["number"])
+	fullNumber = qualifyCueNumber(parentNumber, cueNumber)
 
-	if len(reply) == 0 {
-		return "", fmt.Errorf("no reply received when creating cue list")
-	}
-
-	replyStr, ok := reply[0].(string)
-	if !ok {
-		return "", fmt.Errorf("invalid reply format from cue list creation")
-	}
-
-	var replyData map[string]any
-	err := json.Unmarshal([]byte(replyStr), &replyData)
-	if err != nil {
-		return "", fmt.Errorf("failed to parse cue list creation reply: %v", err)
+	if fullNumber != "" {
+		return fullNumber, fullNumber
 	}
 
-	// Check for error status
-	if status, ok := replyData["status"].(string); ok && status == "error" {
-		return "", formatErrorWithJSON("QLab error creating cue list", replyStr)
-	}
+	// Fallback: use position-based identification for cues without numbers
+	// Include parent context, cue name, and position to create unique identifier
+	cueName, _ := cue["name"].(string)
+	cueType, _ := cue["type"].(string)
 
-	// Extract the new cue list ID
-	cueListID, ok := replyData["data"].(string)
-	if !ok {
-		return "", fmt.Errorf("unexpected cue list creation reply format")
+	// Only index if we have enough identifying information
+	if cueType == "" && cueName == "" {
+		return "", ""
 	}
 
-	log.Debug("Created new cue list", "cue_list_id", cueListID)
-
-	// Set the name to "Cuejitsu Inbox"
-	err = q.setCueListProperty(cueListID, "name", "Cuejitsu Inbox")
-	if err != nil {
-		return "", fmt.Errorf("failed to set cue list name: %v", err)
+	// Create composite key: parent@position[type:name]
+	// Normalize type to lowercase for consistent matching between source and QLab data
+	normalizedType := strings.ToLower(cueType)
+	if parentNumber != "" {
+		return fmt.Sprintf("%s@%d[%s:%s]", parentNumber, position, normalizedType, cueName), ""
 	}
+	return fmt.Sprintf("@%d[%s:%s]", position, normalizedType, cueName), ""
+}
 
-	log.Debug("Set cue list name to 'Cuejitsu Inbox'")
-	return cueListID, nil
+// createCuejitsuInbox creates a new staging cue list
+func (q *Workspace) createCuejitsuInbox() (string, error) {
+	return q.createCueList(q.inboxListName())
 }
 
 // updateSourceFileWithQLabValues updates the source CUE file with QLab values for cues where user chose "Keep QLab version"
 // ExtractQLabUpdates extracts cue field updates from QLab data for user-chosen cues.
 // Returns a map of cue identifiers to field updates.
 // The caller can use this to update source files.
+//
+// This API is stable.
 func (q *Workspace) ExtractQLabUpdates(comparison *ThreeWayComparison) (map[string]map[string]any, error) {
-	log.Debugf("ExtractQLabUpdates called: chosenCues=%+v", comparison.QLabChosenCues)
+	q.log().Debugf("ExtractQLabUpdates called: chosenCues=%+v", comparison.QLabChosenCues)
 
 	if len(comparison.QLabChosenCues) == 0 || len(comparison.CurrentQLabData) == 0 {
-		log.Debug("ExtractQLabUpdates: No chosen cues or QLab data, returning empty map")
+		q.log().Debug("ExtractQLabUpdates: No chosen cues or QLab data, returning empty map")
 		return make(map[string]map[string]any), nil
 	}
 
 	// Extract cue updates from QLab data
 	cueUpdates := make(map[string]map[string]any)
 
-	log.Debug("ExtractQLabUpdates: Extracting cue values from QLab data")
+	q.log().Debug("ExtractQLabUpdates: Extracting cue values from QLab data")
 	err := q.extractQLabCueValues(comparison.CurrentQLabData, comparison.QLabChosenCues, cueUpdates)
 	if err != nil {
-		log.Errorf("ExtractQLabUpdates: Failed to extract QLab cue values: %v", err)
+		q.log().Errorf("ExtractQLabUpdates: Failed to extract QLab cue values: %v", err)
 		return nil, fmt.Errorf("failed to extract QLab cue values: %v", err)
 	}
 
-	log.Debugf("ExtractQLabUpdates: Extracted %d cue updates", len(cueUpdates))
+	q.log().Debugf("ExtractQLabUpdates: Extracted %d cue updates", len(cueUpdates))
 	return cueUpdates, nil
 }
 
 // extractQLabCueValues extracts cue field values from QLab workspace data
 func (q *Workspace) extractQLabCueValues(qlabData map[string]any, chosenCues map[string]bool, cueUpdates map[string]map[string]any) error {
-	log.Debug("extractQLabCueValues called", "chosenCues", chosenCues)
+	q.log().Debug("extractQLabCueValues called", "chosenCues", chosenCues)
 
 	// Navigate through QLab data structure to find cues
 	if data, ok := qlabData["data"].([]any); ok {
@@ -891,9 +1309,9 @@ func (q *Workspace) extractQLabCueValues(qlabData map[string]any, chosenCues map
 		}
 	}
 
-	log.Debug("extractQLabCueValues completed", "totalUpdates", len(cueUpdates))
+	q.log().Debug("extractQLabCueValues completed", "totalUpdates", len(cueUpdates))
 	for id, updates := range cueUpdates {
-		log.Debug("Extracted cue update", "identifier", id, "updates", updates)
+		q.log().Debug("Extracted cue update", "identifier", id, "updates", updates)
 	}
 
 	return nil
@@ -909,7 +1327,7 @@ func (q *Workspace) extractCueValuesFromArrayWithPosition(cues []any, parentNumb
 			// Debug: log all generated identifiers during extraction
 			cueName, _ := cueMap["name"].(string)
 			cueType, _ := cueMap["type"].(string)
-			log.Debugf("Generated QLab identifier: '%s' (parent=%s, pos=%d, type=%s, name=%s)", cueNumber, parentNumber, i, cueType, cueName)
+			q.log().Debugf("Generated QLab identifier: '%s' (parent=%s, pos=%d, type=%s, name=%s)", cueNumber, parentNumber, i, cueType, cueName)
 
 			// If this cue was chosen to keep QLab version, extract its values
 			if cueNumber != "" && chosenCues[cueNumber] {
@@ -931,37 +1349,12 @@ func (q *Workspace) extractCueValuesFromArrayWithPosition(cues []any, parentNumb
 
 				if len(updates) > 0 {
 					cueUpdates[cueNumber] = updates
-					log.Debugf("Extracted %d field updates for cue %s from QLab", len(updates), cueNumber)
+					q.log().Debugf("Extracted %d field updates for cue %s from QLab", len(updates), cueNumber)
 				}
 			}
 
 			// Get the full number for this cue to pass to children (same logic as indexCuesRecursively)
-			var currentFullNumber string
-			if num, ok := cueMap["number"]; ok && num != nil {
-				switch v := num.(type) {
-				case string:
-					currentFullNumber = v
-				case float64:
-					if v == float64(int64(v)) && v >= 0 && v <= 999 {
-						currentFullNumber = fmt.Sprintf("%.1f", v)
-					} else {
-						currentFullNumber = fmt.Sprintf("%g", v)
-					}
-				case int64:
-					currentFullNumber = fmt.Sprintf("%d", v)
-				case int:
-					currentFullNumber = fmt.Sprintf("%d", v)
-				default:
-					currentFullNumber = fmt.Sprintf("%v", v)
-				}
-
-				// Build full cue number with parent prefix
-				if parentNumber != "" && currentFullNumber != "" {
-					if !strings.Contains(currentFullNumber, ".") {
-						currentFullNumber = parentNumber + "." + currentFullNumber
-					}
-				}
-			}
+			currentFullNumber := q.fullCueNumber(parentNumber, formatCueNumber(cueMap["number"]))
 
 			// Recursively check children (QLab uses "cues" for nested cues)
 			if children, ok := cueMap["cues"].([]any); ok {
@@ -979,44 +1372,17 @@ func (q *Workspace) getQLabCueIdentifier(cue map[string]any) string {
 // getQLabCueIdentifierWithPosition extracts cue identifier from QLab cue data with position context
 // Uses the same logic as indexCuesRecursively to ensure consistent identifiers
 func (q *Workspace) getQLabCueIdentifierWithPosition(cue map[string]any, parentNumber string, position int) string {
-	// Extract cue number (same logic as indexCuesRecursively)
-	var cueNumber string
-	if num, ok := cue["number"]; ok && num != nil {
-		switch v := num.(type) {
-		case string:
-			cueNumber = v
-		case float64:
-			if v == float64(int64(v)) && v >= 0 && v <= 999 {
-				cueNumber = fmt.Sprintf("%.1f", v)
-			} else {
-				cueNumber = fmt.Sprintf("%g", v)
-			}
-		case int64:
-			cueNumber = fmt.Sprintf("%d", v)
-		case int:
-			cueNumber = fmt.Sprintf("%d", v)
-		default:
-			cueNumber = fmt.Sprintf("%v", v)
-		}
-	}
-
-	// Build full cue number with parent prefix (same logic as indexCuesRecursively)
-	fullNumber := cueNumber
-	if parentNumber != "" && cueNumber != "" {
-		if strings.Contains(cueNumber, ".") {
-			fullNumber = cueNumber
-		} else {
-			fullNumber = parentNumber + "." + cueNumber
-		}
-	}
+	// Same numbering as indexCuesRecursively
+	cueNumber := formatCueNumber(cue["number"])
+	fullNumber := q.fullCueNumber(parentNumber, cueNumber)
 
 	cueName, _ := cue["name"].(string)
 	cueType, _ := cue["type"].(string)
-	log.Debug("getQLabCueIdentifierWithPosition called", "parentNumber", parentNumber, "position", position, "cueNumber", cueNumber, "fullNumber", fullNumber, "cueName", cueName, "cueType", cueType)
+	q.log().Debug("getQLabCueIdentifierWithPosition called", "parentNumber", parentNumber, "position", position, "cueNumber", cueNumber, "fullNumber", fullNumber, "cueName", cueName, "cueType", cueType)
 
 	// Return numbered identifier if we have one
 	if fullNumber != "" {
-		log.Debug("Returning numbered identifier", "identifier", fullNumber)
+		q.log().Debug("Returning numbered identifier", "identifier", fullNumber)
 		return fullNumber
 	}
 
@@ -1033,70 +1399,17 @@ func (q *Workspace) getQLabCueIdentifierWithPosition(cue map[string]any, parentN
 
 	// Only return if we have enough identifying information
 	if cueType != "" || cueName != "" {
-		log.Debug("Returning position-based identifier", "identifier", positionKey)
+		q.log().Debug("Returning position-based identifier", "identifier", positionKey)
 		return positionKey
 	}
 
-	log.Debug("No identifier found - returning empty string")
+	q.log().Debug("No identifier found - returning empty string")
 	return ""
 }
 
 // applyCueUpdatesToSourceFile has been removed - file writing is now handled by the caller.
 // Use ExtractQLabUpdates() to get the updates, then write them to your file format.
 
-// getVideoStages queries QLab for available video stages (cached)
-func (q *Workspace) getVideoStages() ([]map[string]any, error) {
-	// Return cached result if available
-	if q.videoStagesCache != nil {
-		log.Debugf("Returning cached video stages (%d stages)", len(q.videoStagesCache))
-		return q.videoStagesCache, nil
-	}
-
-	if q.workspace_id == "" {
-		return nil, fmt.Errorf("workspace ID is required")
-	}
-
-	log.Debugf("Querying QLab for video stages")
-	address := fmt.Sprintf("/workspace/%s/settings/video/stages", q.workspace_id)
-	reply := q.Send(address, "")
-
-	if len(reply) == 0 {
-		return nil, fmt.Errorf("no reply received from QLab")
-	}
-
-	replyStr, ok := reply[0].(string)
-	if !ok {
-		return nil, fmt.Errorf("invalid reply format")
-	}
-
-	var replyData map[string]any
-	if err := json.Unmarshal([]byte(replyStr), &replyData); err != nil {
-		return nil, fmt.Errorf("failed to parse stages reply: %v", err)
-	}
-
-	if status, ok := replyData["status"].(string); ok && status == "error" {
-		return nil, fmt.Errorf("QLab returned error getting stages")
-	}
-
-	data, ok := replyData["data"].([]any)
-	if !ok {
-		return nil, fmt.Errorf("no stages data in reply")
-	}
-
-	stages := make([]map[string]any, 0, len(data))
-	for _, stageAny := range data {
-		if stage, ok := stageAny.(map[string]any); ok {
-			stages = append(stages, stage)
-		}
-	}
-
-	// Cache the result
-	q.videoStagesCache = stages
-	log.Debugf("Cached %d video stages", len(stages))
-
-	return stages, nil
-}
-
 // ValidateCueConfiguration checks if a cue has warnings and returns descriptive messages
 func (q *Workspace) ValidateCueConfiguration(cueID string, cueNumber string) []string {
 	var warnings []string
@@ -1233,53 +1546,56 @@ func (q *Workspace) ValidateAllCues() ([]string, error) {
 	return allWarnings, nil
 }
 
-// Close cleans up resources used by the workspace
+// Close disconnects from QLab and cleans up resources used by the workspace, without
+// waiting for replies in flight; Shutdown waits for them
+//
+// This API is stable.
 func (q *Workspace) Close() {
+	q.stopReconnect()
+	q.StopHeartbeat()
+	if err := q.disconnect(); err != nil {
+		q.log().Debugf("Failed to disconnect: %v", err)
+	}
+	_ = q.closeTransports()
+}
+
+// closeTransports releases the listener or the client's share of it, and the TCP
+// connection, and drops the handlers of pending replies. Everything is closed before it
+// returns; Shutdown waits for replies in flight before calling it.
+func (q *Workspace) closeTransports() error {
 	q.serverMux.Lock()
 	defer q.serverMux.Unlock()
 
-	// Close update server if it exists
-	// The go-osc library has some race conditions, but we need to close
-	// the connections to free up the ports for subsequent tests.
-	// We use a small delay to let the server finish initializing first.
-	if q.updateServer != nil {
-		server := q.updateServer
+	// A pooled workspace leaves the shared listener to its client
+	if q.pool != nil {
+		q.pool.release(q)
+		q.pool = nil
 		q.updateServer = nil
-
-		// Close in background to avoid blocking
-		go func() {
-			time.Sleep(100 * time.Millisecond)
-			log.Debugf("Closing update server")
-			if err := server.CloseConnection(); err != nil {
-				log.Warnf("Failed to close update server: %v", err)
-			}
-		}()
+		q.replyConn = nil
 	}
 
-	// Close reply server if it exists
-	if q.replyServer != nil {
-		server := q.replyServer
-		q.replyServer = nil
-
-		// Close in background to avoid blocking
-		go func() {
-			time.Sleep(100 * time.Millisecond)
-			log.Debugf("Closing reply server")
-			if err := server.CloseConnection(); err != nil {
-				log.Warnf("Failed to close reply server: %v", err)
-			}
-		}()
+	// Close the UDP listener if it exists, freeing its port
+	var errs []error
+	if q.replyConn != nil {
+		q.log().Debugf("Closing update server")
+		if err := q.replyConn.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close update server: %w", err))
+		}
+		q.updateServer = nil
+		q.replyConn = nil
 	}
 
-	// Clear ready channels
-	q.updateServerReady = nil
-	q.replyServerReady = nil
+	// Close the TCP connection if there is one
+	if err := q.closeStreamLocked(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to close TCP connection: %w", err))
+	}
 
 	// Don't close reply handler channels as they may still be in use
 	// Just clear the map
 	q.replyHandlersMux.Lock()
-	q.replyHandlers = make(map[string]chan []any)
+	q.replyHandlers = make(map[string][]pendingReply)
 	q.replyHandlersMux.Unlock()
+	return errors.Join(errs...)
 }
 
 // trackCreatedCue adds a cue ID to the tracking list for potential rollback
@@ -1288,16 +1604,21 @@ func (q *Workspace) trackCreatedCue(cueID string) {
 	defer q.createdCueIDsMux.Unlock()
 
 	q.createdCueIDs = append(q.createdCueIDs, cueID)
-	log.Debugf("Tracked created cue: %s (total tracked: %d)", cueID, len(q.createdCueIDs))
+	if q.batch != nil {
+		q.batch.createdCueIDs = append(q.batch.createdCueIDs, cueID)
+	}
+	q.log().Debugf("Tracked created cue: %s (total tracked: %d)", cueID, len(q.createdCueIDs))
 }
 
 // ClearTrackedCues clears the list of tracked cue IDs
+//
+// This API is stable.
 func (q *Workspace) ClearTrackedCues() {
 	q.createdCueIDsMux.Lock()
 	defer q.createdCueIDsMux.Unlock()
 
 	q.createdCueIDs = make([]string, 0)
-	log.Debug("Cleared tracked cues list")
+	q.log().Debug("Cleared tracked cues list")
 }
 
 // getTrackedCues returns a copy of the tracked cue IDs
@@ -1312,13 +1633,18 @@ func (q *Workspace) getTrackedCues() []string {
 }
 
 // DeleteCue deletes a cue from QLab by its unique ID
+//
+// This API is stable.
 func (q *Workspace) DeleteCue(cueID string) error {
+	q.opMu.Lock()
+	defer q.opMu.Unlock()
+
 	if q.workspace_id == "" {
 		return fmt.Errorf("workspace ID is required for cue deletion")
 	}
 
 	address := fmt.Sprintf("/workspace/%s/delete_id/%s", q.workspace_id, cueID)
-	log.Debugf("Deleting cue: %s", cueID)
+	q.log().Debugf("Deleting cue: %s", cueID)
 
 	reply := q.Send(address, "")
 	if len(reply) == 0 {
@@ -1340,25 +1666,30 @@ func (q *Workspace) DeleteCue(cueID string) error {
 		return fmt.Errorf("QLab returned error deleting cue %s: %v", cueID, replyData["error"])
 	}
 
-	log.Infof("Deleted cue: %s", cueID)
+	q.log().Infof("Deleted cue: %s", cueID)
 	return nil
 }
 
 // RollbackCreatedCues deletes all cues that were tracked during the current operation
+//
+// This API is stable.
 func (q *Workspace) RollbackCreatedCues() error {
+	q.opMu.Lock()
+	defer q.opMu.Unlock()
+
 	cues := q.getTrackedCues()
 	if len(cues) == 0 {
-		log.Debug("No cues to rollback")
+		q.log().Debug("No cues to rollback")
 		return nil
 	}
 
-	log.Infof("Rolling back %d created cues", len(cues))
+	q.log().Infof("Rolling back %d created cues", len(cues))
 
 	// Delete cues in reverse order (children first, then parents)
 	for i := len(cues) - 1; i >= 0; i-- {
 		cueID := cues[i]
-		if err := q.DeleteCue(cueID); err != nil {
-			log.Warnf("Failed to delete cue during rollback: %s, error: %v", cueID, err)
+		if err := q.deleteCue(cueID); err != nil {
+			q.log().Warnf("Failed to delete cue during rollback: %s, error: %v", cueID, err)
 			// Continue with other deletions
 		}
 	}
@@ -1366,6 +1697,6 @@ func (q *Workspace) RollbackCreatedCues() error {
 	// Clear the tracking list
 	q.ClearTrackedCues()
 
-	log.Info("Rollback completed")
+	q.log().Info("Rollback completed")
 	return nil
 }
@@ -0,0 +1,437 @@
+package qlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hypebeast/go-osc/osc"
+)
+
+// DefaultBatchWindow is the number of in-flight messages used when batching is enabled without an explicit window
+const DefaultBatchWindow = 32
+
+// queuedMessage is a property-set (or query) message waiting to be pipelined
+type queuedMessage struct {
+	cueID    string
+	property string
+	address  string
+	args     []any
+}
+
+// inFlightMessage tracks a pipelined message awaiting its reply
+type inFlightMessage struct {
+	message   queuedMessage
+	requestID int
+	reply     chan []any
+	sentAt    time.Time
+	release   func() // Frees the message's in-flight slot
+}
+
+// batchState holds the queue and bookkeeping for an active batch
+type batchState struct {
+	queue           []queuedMessage
+	createdCueIDs   []string
+	expectedNumbers map[string]string // cue ID -> number queued for that cue
+}
+
+// BatchFailure describes a single message in a batch that QLab rejected or never answered
+type BatchFailure struct {
+	CueID    string
+	Property string
+	Err      error
+}
+
+// BatchResult summarizes a batched cue creation run
+type BatchResult struct {
+	CreatedCueIDs   []string          // Cues created while the batch was active
+	MessagesSent    int               // Property-set messages pipelined to QLab
+	Failures        []BatchFailure    // Property sets that failed or timed out
+	MissingCueIDs   []string          // Created cues that reconciliation could not find in QLab
+	NumberMismatch  map[string]string // Cue ID -> number QLab reported when it differs from the one sent
+	ReconcileErrors []error           // Errors querying QLab during reconciliation
+}
+
+// OK reports whether every message succeeded and reconciliation found every cue as expected
+func (r *BatchResult) OK() bool {
+	return len(r.Failures) == 0 && len(r.MissingCueIDs) == 0 && len(r.NumberMismatch) == 0 && len(r.ReconcileErrors) == 0
+}
+
+// Err summarizes the batch problems as a single error, or nil if the batch succeeded
+func (r *BatchResult) Err() error {
+	if r.OK() {
+		return nil
+	}
+	var parts []string
+	if len(r.Failures) > 0 {
+		parts = append(parts, fmt.Sprintf("%d property sets failed (first: %s on cue %s: %v)",
+			len(r.Failures), r.Failures[0].Property, r.Failures[0].CueID, r.Failures[0].Err))
+	}
+	if len(r.MissingCueIDs) > 0 {
+		parts = append(parts, fmt.Sprintf("%d created cues missing from QLab: %s", len(r.MissingCueIDs), strings.Join(r.MissingCueIDs, ", ")))
+	}
+	if len(r.NumberMismatch) > 0 {
+		parts = append(parts, fmt.Sprintf("%d cues have unexpected numbers", len(r.NumberMismatch)))
+	}
+	if len(r.ReconcileErrors) > 0 {
+		parts = append(parts, fmt.Sprintf("%d reconciliation queries failed (first: %v)", len(r.ReconcileErrors), r.ReconcileErrors[0]))
+	}
+	return fmt.Errorf("batch incomplete: %s", strings.Join(parts, "; "))
+}
+
+// SetBatchWindow enables batched cue creation with at most window messages in flight.
+// Property sets are queued while cues are created and pipelined to QLab without waiting
+// for each reply, followed by a reconciliation pass that verifies every cue exists.
+// A window of 0 (the default) disables batching and sends one message at a time.
+func (q *Workspace) SetBatchWindow(window int) {
+	if window < 0 {
+		window = 0
+	}
+	q.batchWindow = window
+}
+
+// SetBatchBundles sets whether queued property sets for the same cue are grouped into a single OSC bundle
+func (q *Workspace) SetBatchBundles(useBundles bool) {
+	q.batchBundles = useBundles
+}
+
+// CreateCuesBatch creates the given cues (including nested group children) using the batching layer.
+// Batching is used even if SetBatchWindow was not called; DefaultBatchWindow applies in that case.
+func (q *Workspace) CreateCuesBatch(cues []map[string]any) (*BatchResult, error) {
+	if err := q.checkWritable("batch"); err != nil {
+		return nil, err
+	}
+	q.opMu.Lock()
+	defer q.opMu.Unlock()
+	return q.runBatched(func() error {
+		for i, cueData := range cues {
+			if err := q.processCueList(cueData, ""); err != nil {
+				return fmt.Errorf("failed to process cue %d: %v", i, err)
+			}
+		}
+		return nil
+	})
+}
+
+// runBatched runs fn with property sets queued, then flushes the queue and reconciles the result
+func (q *Workspace) runBatched(fn func() error) (*BatchResult, error) {
+	if q.batch != nil {
+		// Already batching (nested call) - let the outer batch flush
+		return &BatchResult{}, fn()
+	}
+
+	q.batch = &batchState{expectedNumbers: make(map[string]string)}
+	fnErr := fn()
+	state := q.batch
+	q.batch = nil
+
+	result := &BatchResult{
+		CreatedCueIDs:  state.createdCueIDs,
+		NumberMismatch: make(map[string]string),
+	}
+
+	// Flush even if fn failed so cues that were created get their properties
+	result.MessagesSent = len(state.queue)
+	result.Failures = q.flushMessages(state.queue)
+
+	if fnErr != nil {
+		return result, fnErr
+	}
+
+	q.reconcileBatch(state, result)
+
+	if !result.OK() {
+		return result, result.Err()
+	}
+	q.log().Infof("Batch complete: %d cues created, %d property sets sent", len(result.CreatedCueIDs), result.MessagesSent)
+	return result, nil
+}
+
+// queuePropertySet adds a property-set message to the active batch
+func (q *Workspace) queuePropertySet(uniqueID, property string, args ...any) {
+	address := q.addressBuilder.BuildCuePropertyAddress(uniqueID, property)
+	q.batch.queue = append(q.batch.queue, queuedMessage{
+		cueID:    uniqueID,
+		property: property,
+		address:  address,
+		args:     args,
+	})
+	if tx := q.activeTransaction(); tx != nil && !q.dryRun {
+		// Batched sets are recorded when queued since their replies are checked later
+		if op := tx.beginOp(address, args); op != nil {
+			tx.record(*op)
+		}
+	}
+	if property == "number" && len(args) == 1 {
+		if number, ok := args[0].(string); ok {
+			q.batch.expectedNumbers[uniqueID] = number
+		}
+	}
+	q.log().Debug("Queued cue property", "address", address, "args", args)
+}
+
+// flushMessages pipelines the queued messages to QLab and returns the ones that failed
+func (q *Workspace) flushMessages(queue []queuedMessage) []BatchFailure {
+	if q.dryRun {
+		for _, m := range queue {
+			q.log().Infof("[DRY RUN] Would send OSC message: %s %v", m.address, m.args)
+		}
+		return nil
+	}
+
+	var failures []BatchFailure
+	q.pipelineMessages(queue, func(m queuedMessage, reply []any, err error) {
+		if err == nil {
+			err = checkReplyStatus(reply)
+		}
+		if err != nil {
+			failures = append(failures, BatchFailure{CueID: m.cueID, Property: m.property, Err: err})
+		}
+	})
+	return failures
+}
+
+// pipelineMessages sends the messages without waiting for each reply, keeping at most the
+// batch window in flight, and calls handle once per message
+func (q *Workspace) pipelineMessages(queue []queuedMessage, handle func(m queuedMessage, reply []any, err error)) {
+	if len(queue) == 0 {
+		return
+	}
+	if !q.shutdown.begin() {
+		for _, m := range queue {
+			handle(m, nil, ErrClosed)
+		}
+		return
+	}
+	defer q.shutdown.end()
+
+	// Pipelining relies on the persistent listener, or the TCP connection, to route
+	// concurrent replies
+	q.serverMux.Lock()
+	listening := q.updateServer != nil || q.useTCP
+	q.serverMux.Unlock()
+	if !listening {
+		q.log().Debug("No persistent listener, sending batch sequentially")
+		for _, m := range queue {
+			handle(m, q.sendWithRetry(m.address, "", m.args), nil)
+		}
+		return
+	}
+
+	window := q.batchWindow
+	if window <= 0 {
+		window = DefaultBatchWindow
+	}
+	if limit := q.sendLimits.maxInFlight(); limit > 0 {
+		window = min(window, limit)
+	}
+	timeout := q.replyTimeout()
+
+	var timedOut []queuedMessage
+	var inFlight []inFlightMessage
+
+	awaitOldest := func() {
+		oldest := inFlight[0]
+		inFlight = inFlight[1:]
+		defer oldest.release()
+		select {
+		case reply := <-oldest.reply:
+			q.reportReply(oldest.message.address, oldest.sentAt)
+			q.audit(oldest.message.address, "", oldest.message.args, oldest.sentAt, reply, nil)
+			handle(oldest.message, reply, nil)
+		case <-time.After(time.Until(oldest.sentAt.Add(timeout))):
+			q.settleReplies(oldest.message.address, timeout, oldest.requestID)
+			q.reportTimeout(oldest.message.address)
+			timedOut = append(timedOut, oldest.message)
+		}
+	}
+
+	// Each message takes an in-flight slot as sendWithRetryCtx does; while other requests
+	// hold the slots, the batch's own oldest message is awaited to free one
+	acquire := func() func() {
+		for len(inFlight) > 0 {
+			if release, ok := q.sendLimits.tryAcquire(); ok {
+				return release
+			}
+			awaitOldest()
+		}
+		release, _ := q.sendLimits.acquire(context.Background())
+		return release
+	}
+
+	for start := 0; start < len(queue); {
+		group := q.nextSendGroup(queue[start:], window)
+		for len(inFlight) > 0 && len(inFlight)+len(group) > window {
+			awaitOldest()
+		}
+
+		sent, err := q.sendGroup(group, acquire)
+		if errors.Is(err, ErrPayloadTooLarge) && len(group) > 1 {
+			// The bundle is too large for UDP, send its messages one at a time instead
+			for _, m := range group {
+				single, err := q.sendGroup([]queuedMessage{m}, acquire)
+				if err != nil {
+					q.audit(m.address, "", m.args, time.Now(), nil, err)
+					handle(m, nil, err)
+				}
+				inFlight = append(inFlight, single...)
+			}
+		} else if err != nil {
+			for _, m := range group {
+				q.audit(m.address, "", m.args, time.Now(), nil, err)
+				handle(m, nil, err)
+			}
+		}
+		inFlight = append(inFlight, sent...)
+		start += len(group)
+	}
+	for len(inFlight) > 0 {
+		awaitOldest()
+	}
+
+	// Give timed-out messages one more chance through the regular retrying send path
+	if len(timedOut) > 0 {
+		q.log().Warnf("%d batched messages timed out, resending individually", len(timedOut))
+		for _, m := range timedOut {
+			q.reportRetry(m.address)
+			handle(m, q.sendWithRetry(m.address, "", m.args), nil)
+		}
+	}
+}
+
+// nextSendGroup returns the messages to send together: all consecutive messages for one cue when
+// bundling is enabled (capped at the window), otherwise a single message
+func (q *Workspace) nextSendGroup(queue []queuedMessage, window int) []queuedMessage {
+	if !q.batchBundles {
+		return queue[:1]
+	}
+	end := 1
+	for end < len(queue) && end < window && queue[end].cueID == queue[0].cueID {
+		end++
+	}
+	return queue[:end]
+}
+
+// sendGroup takes an in-flight slot and registers a reply handler for each message of the
+// group, and sends it as one message or bundle
+func (q *Workspace) sendGroup(group []queuedMessage, acquire func() func()) ([]inFlightMessage, error) {
+	sent := make([]inFlightMessage, 0, len(group))
+	var packet osc.Packet
+	var bundle *osc.Bundle
+	if len(group) > 1 {
+		bundle = osc.NewBundle(time.Now())
+		packet = bundle
+	}
+
+	for _, m := range group {
+		if err := q.checkMessageWritable(m.address, "", m.args); err != nil {
+			return nil, err
+		}
+	}
+	for _, m := range group {
+		msg := osc.NewMessage(m.address)
+		for _, arg := range m.args {
+			msg.Append(arg)
+		}
+		if bundle != nil {
+			if err := bundle.Append(msg); err != nil {
+				q.abandonGroup(sent)
+				return nil, err
+			}
+		} else {
+			packet = msg
+		}
+
+		release := acquire()
+		requestID := q.nextRequestID()
+		reply := make(chan []any, 1)
+		q.ListenForReply(m.address, reply, requestID)
+		sent = append(sent, inFlightMessage{message: m, requestID: requestID, reply: reply, sentAt: time.Now(), release: release})
+	}
+
+	if err := q.sendPacket(group[0].address, packet); err != nil {
+		q.abandonGroup(sent)
+		return nil, fmt.Errorf("failed to send OSC message: %w", err)
+	}
+	return sent, nil
+}
+
+// abandonGroup drops the reply handlers and frees the in-flight slots of messages that
+// weren't sent
+func (q *Workspace) abandonGroup(sent []inFlightMessage) {
+	for _, f := range sent {
+		q.removeReplyHandler(f.message.address, f.requestID)
+		f.release()
+	}
+}
+
+// reconcileBatch verifies that every cue created during the batch exists in QLab and carries its number
+func (q *Workspace) reconcileBatch(state *batchState, result *BatchResult) {
+	if q.dryRun || len(state.createdCueIDs) == 0 {
+		return
+	}
+
+	if q.progressCallback != nil {
+		q.progressCallback("reconcile", fmt.Sprintf("Verifying %d created cues...", len(state.createdCueIDs)))
+	}
+
+	queries := make([]queuedMessage, 0, len(state.createdCueIDs))
+	for _, cueID := range state.createdCueIDs {
+		queries = append(queries, queuedMessage{
+			cueID:    cueID,
+			property: "number",
+			address:  q.addressBuilder.BuildCuePropertyAddress(cueID, "number"),
+		})
+	}
+
+	q.pipelineMessages(queries, func(m queuedMessage, reply []any, err error) {
+		if err == nil {
+			err = checkReplyStatus(reply)
+		}
+		if err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				result.MissingCueIDs = append(result.MissingCueIDs, m.cueID)
+			} else {
+				result.ReconcileErrors = append(result.ReconcileErrors, fmt.Errorf("cue %s: %v", m.cueID, err))
+			}
+			return
+		}
+
+		expected, hasExpected := state.expectedNumbers[m.cueID]
+		if !hasExpected {
+			return
+		}
+		if actual := replyDataString(reply); actual != expected {
+			result.NumberMismatch[m.cueID] = actual
+		}
+	})
+}
+
+// replyDataString extracts the "data" field of a JSON reply as a string
+func replyDataString(reply []any) string {
+	data, ok := replyDataValue(reply)
+	if !ok || data == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", data)
+}
+
+// replyDataValue extracts the "data" field of a JSON reply; non-JSON replies are returned as-is
+func replyDataValue(reply []any) (any, bool) {
+	if len(reply) == 0 {
+		return nil, false
+	}
+	replyStr, ok := reply[0].(string)
+	if !ok {
+		return reply[0], true
+	}
+	var replyData map[string]any
+	if err := json.Unmarshal([]byte(replyStr), &replyData); err != nil {
+		return replyStr, true
+	}
+	data, ok := replyData["data"]
+	return data, ok
+}
@@ -2,15 +2,12 @@ package qlab
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
-	"os/user"
-	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
-
-	"github.com/charmbracelet/log"
 )
 
 // getKeys returns sorted keys from a map for debugging
@@ -26,27 +23,15 @@ func getKeys(m map[string]any) []string {
 // writeCueFileToCache saves the current QLab workspace state to cache for change detection
 // If comparison is provided, it preserves cached state for skipped cues to maintain user choices
 func (q *Workspace) writeCueFileToCache(filePath string, workspace map[string]any, mapping *CueMapping, comparison *ThreeWayComparison) error {
-	// Get current user's home directory
-	usr, err := user.Current()
-	if err != nil {
-		return fmt.Errorf("failed to get current user: %v", err)
+	store, err := q.cache()
+	if errors.Is(err, ErrCacheDisabled) {
+		q.log().Debug("Caching disabled, not saving workspace state")
+		return nil
 	}
-
-	// Create cache directory
-	cacheDir := filepath.Join(usr.HomeDir, ".cache", "cuejitsu")
-	err = os.MkdirAll(cacheDir, 0755)
 	if err != nil {
-		return fmt.Errorf("failed to create cache directory: %v", err)
+		return err
 	}
 
-	// Get base filename without extension
-	baseName := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
-
-	// Create timestamp for the cache file
-	timestamp := time.Now().Format("2006-01-02T15-04-05")
-	cacheFileName := fmt.Sprintf("%s_%s.json", baseName, timestamp)
-	cacheFilePath := filepath.Join(cacheDir, cacheFileName)
-
 	// Query current QLab workspace state
 	currentWorkspace, err := q.queryCurrentWorkspaceState()
 	if err != nil {
@@ -56,24 +41,20 @@ func (q *Workspace) writeCueFileToCache(filePath string, workspace map[string]an
 	// If comparison is provided, preserve cached state for skipped cues
 	if comparison != nil && comparison.HasCache {
 		// Load the original cache to preserve skipped cues
-		originalCacheFilePath, err := findMostRecentCacheFile(filePath)
-		if err == nil {
-			originalCache, err := loadCacheFileData(originalCacheFilePath)
-			if err == nil {
-				// Index cues from original cache
-				originalCues := q.indexCuesFromWorkspace(originalCache)
-
-				// For each cue that was skipped, restore its original cached state
-				for cueNumber, result := range comparison.CueResults {
-					if result.Action == "skip" && result.Reason == "User chose to skip this cue" {
-						// Preserve original cached state for this cue
-						if originalCue, exists := originalCues[cueNumber]; exists {
-							log.Debugf("Preserving original cached state for skipped cue: %s", cueNumber)
-							// Replace the current state with the original cached state
-							err := q.replaceWorkspaceCueWithCached(currentWorkspace, originalCue, cueNumber)
-							if err != nil {
-								log.Warnf("Failed to preserve cached state for cue %s: %v", cueNumber, err)
-							}
+		if _, originalCache, _, err := q.loadLatestCache(filePath); err == nil {
+			// Index cues from original cache
+			originalCues := q.indexCuesFromWorkspace(originalCache)
+
+			// For each cue that was skipped, restore its original cached state
+			for cueNumber, result := range comparison.CueResults {
+				if result.Action == "skip" && (result.Reason == "User chose to skip this cue" || result.Reason == reasonNotSelected) {
+					// Preserve original cached state for this cue
+					if originalCue, exists := originalCues[cueNumber]; exists {
+						q.log().Debugf("Preserving original cached state for skipped cue: %s", cueNumber)
+						// Replace the current state with the original cached state
+						err := q.replaceWorkspaceCueWithCached(currentWorkspace, originalCue, cueNumber)
+						if err != nil {
+							q.log().Warnf("Failed to preserve cached state for cue %s: %v", cueNumber, err)
 						}
 					}
 				}
@@ -81,25 +62,41 @@ func (q *Workspace) writeCueFileToCache(filePath string, workspace map[string]an
 		}
 	}
 
+	// QLab doesn't store properties it doesn't know; keep the source's in the snapshot
+	q.carryUnknownProperties(workspace, currentWorkspace)
+	q.carryLevels(workspace, currentWorkspace)
+
+	// Record both clocks so later comparisons can tell whether the cache's age is trustworthy,
+	// and which QLab cue each source cue is, so renumbered cues keep their identity
+	meta := q.currentCacheMetadata()
+	meta.Identities = q.cueIdentities(workspace, currentWorkspace, comparison)
+	meta.Resolutions = q.rememberedResolutions(comparison)
+	currentWorkspace[cacheMetadataKey] = meta
+
 	// Write the current workspace state to cache file
 	cacheData, err := json.MarshalIndent(currentWorkspace, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal cache data: %v", err)
 	}
 
-	err = os.WriteFile(cacheFilePath, cacheData, 0644)
+	cacheKey, err := store.Save(q.cacheName(filePath), cacheData)
 	if err != nil {
-		return fmt.Errorf("failed to write cache file: %v", err)
+		return err
 	}
+	q.log().Infof("Saved workspace state to cache: %s", cacheKey)
 
-	log.Infof("Saved workspace state to cache: %s", cacheFilePath)
+	if removed, err := pruneCacheStore(store, q.cacheRetention, time.Now(), q.log()); err != nil {
+		q.log().Warnf("Failed to prune cache: %v", err)
+	} else if removed > 0 {
+		q.log().Debugf("Pruned %d cache snapshots", removed)
+	}
 	return nil
 }
 
 // queryWorkspaceStateLightweight performs a minimal query when full query times out
 // Returns basic cue structure without deep enrichment
 func (q *Workspace) queryWorkspaceStateLightweight() (map[string]any, error) {
-	log.Info("Using lightweight query mode - fetching cue list names only")
+	q.log().Info("Using lightweight query mode - fetching cue list names only")
 
 	address := fmt.Sprintf("/workspace/%s/cueLists/shallow", q.workspace_id)
 	reply := q.Send(address, "")
@@ -120,13 +117,14 @@ func (q *Workspace) queryWorkspaceStateLightweight() (map[string]any, error) {
 
 	// Check for errors including timeouts
 	if status, ok := replyData["status"].(string); ok && status == "error" {
-		if errorMsg, hasError := replyData["error"].(string); hasError && strings.Contains(errorMsg, "timeout") {
-			log.Warn("Lightweight query also timed out - QLab connection may be unstable")
+		err := newQLabError("lightweight query failed", replyStr)
+		if errors.Is(err, ErrTimeout) {
+			q.log().Warn("Lightweight query also timed out - QLab connection may be unstable")
 		}
-		return nil, formatErrorWithJSON("lightweight query failed", replyStr)
+		return nil, err
 	}
 
-	log.Info("Lightweight query succeeded - using basic cue structure")
+	q.log().Info("Lightweight query succeeded - using basic cue structure")
 	return replyData, nil
 }
 
@@ -135,12 +133,12 @@ func (q *Workspace) queryCurrentWorkspaceState() (map[string]any, error) {
 	// Try multiple approaches to get all cues in the workspace
 
 	// Approach 1: Try /cueLists (should work if cue lists are Group cues with children)
-	log.Info("Attempting to fetch cues using /cueLists")
+	q.log().Info("Attempting to fetch cues using /cueLists")
 	address := fmt.Sprintf("/workspace/%s/cueLists", q.workspace_id)
 	reply := q.Send(address, "")
 
 	if len(reply) == 0 {
-		log.Warn("No reply received from /cueLists - QLab may be busy or disconnected")
+		q.log().Warn("No reply received from /cueLists - QLab may be busy or disconnected")
 		return nil, fmt.Errorf("no reply received from QLab when querying workspace state")
 	}
 
@@ -157,14 +155,12 @@ func (q *Workspace) queryCurrentWorkspaceState() (map[string]any, error) {
 
 	// Check for error status - including timeout errors
 	if status, ok := replyData["status"].(string); ok && status == "error" {
-		// Check if this is a timeout error
-		if errorMsg, hasError := replyData["error"].(string); hasError {
-			if strings.Contains(errorMsg, "timeout") {
-				log.Warn("QLab query timed out - workspace may be too large or QLab is busy")
-				log.Info("Consider increasing timeout with SetTimeout() or reducing workspace size")
-			}
+		err := newQLabError("QLab error querying workspace state", replyStr)
+		if errors.Is(err, ErrTimeout) {
+			q.log().Warn("QLab query timed out - workspace may be too large or QLab is busy")
+			q.log().Info("Consider increasing timeout with SetTimeout() or reducing workspace size")
 		}
-		return nil, formatErrorWithJSON("QLab error querying workspace state", replyStr)
+		return nil, err
 	}
 
 	// Check if we have cue lists with actual cue children
@@ -173,7 +169,7 @@ func (q *Workspace) queryCurrentWorkspaceState() (map[string]any, error) {
 		return replyData, nil // Return as-is if no data array
 	}
 
-	log.Info("Received cue lists data", "count", len(data))
+	q.log().Info("Received cue lists data", "count", len(data))
 
 	// Count total cues across all lists to see if we have actual cue data
 	totalCues := 0
@@ -187,21 +183,21 @@ func (q *Workspace) queryCurrentWorkspaceState() (map[string]any, error) {
 		}
 	}
 
-	log.Info("Total cues found in /cueLists", "count", totalCues)
+	q.log().Info("Total cues found in /cueLists", "count", totalCues)
 
 	// If we found actual cues, enrich and return the data
 	if totalCues > 0 {
-		log.Info("Successfully retrieved cues using /cueLists")
+		q.log().Info("Successfully retrieved cues using /cueLists")
 		q.enrichCuesWithProperties(replyData)
 		return replyData, nil
 	}
 
 	// Approach 2: DISABLED - /selectedCues approach has timeout issues
 	// This approach doesn't work reliably with QLab and causes 20+ second delays
-	log.Info("Skipping /selectedCues approach (disabled due to timeout issues)")
+	q.log().Info("Skipping /selectedCues approach (disabled due to timeout issues)")
 
 	// Approach 3: Try individual cue list traversal
-	log.Info("Trying individual cue list traversal")
+	q.log().Info("Trying individual cue list traversal")
 
 	for i, cueListInterface := range data {
 		cueList, ok := cueListInterface.(map[string]any)
@@ -212,7 +208,7 @@ func (q *Workspace) queryCurrentWorkspaceState() (map[string]any, error) {
 		// Check if this cue list already has cues
 		if cuesArray, exists := cueList["cues"]; exists {
 			if cues, ok := cuesArray.([]any); ok && len(cues) > 0 {
-				log.Info("Cue list already has cues", "index", i, "count", len(cues))
+				q.log().Info("Cue list already has cues", "index", i, "count", len(cues))
 				continue // This cue list already has cue data
 			}
 		}
@@ -226,7 +222,7 @@ func (q *Workspace) queryCurrentWorkspaceState() (map[string]any, error) {
 			if uniqueIDStr, ok := uniqueID.(string); ok && uniqueIDStr != "" {
 				cueIdentifier = uniqueIDStr
 				childrenAddress = fmt.Sprintf("/workspace/%s/cue_id/%s/children", q.workspace_id, uniqueIDStr)
-				log.Info("Fetching cues for cue list", "index", i, "uniqueID", uniqueIDStr)
+				q.log().Info("Fetching cues for cue list", "index", i, "uniqueID", uniqueIDStr)
 			}
 		}
 
@@ -236,61 +232,61 @@ func (q *Workspace) queryCurrentWorkspaceState() (map[string]any, error) {
 				if listNumberStr, ok := listNumber.(string); ok && listNumberStr != "" {
 					cueIdentifier = listNumberStr
 					childrenAddress = fmt.Sprintf("/workspace/%s/cue/%s/children", q.workspace_id, listNumberStr)
-					log.Info("Fetching cues for cue list", "index", i, "number", listNumberStr)
+					q.log().Info("Fetching cues for cue list", "index", i, "number", listNumberStr)
 				}
 			}
 		}
 
 		// Skip if no identifier found
 		if cueIdentifier == "" {
-			log.Warn("Cue list has no number or uniqueID", "index", i)
+			q.log().Warn("Cue list has no number or uniqueID", "index", i)
 			continue
 		}
 		childrenReply := q.Send(childrenAddress, "")
 
 		if len(childrenReply) == 0 {
-			log.Warn("No reply received for cue list children", "identifier", cueIdentifier)
+			q.log().Warn("No reply received for cue list children", "identifier", cueIdentifier)
 			continue
 		}
 
 		childrenStr, ok := childrenReply[0].(string)
 		if !ok {
-			log.Warn("Invalid reply format for cue list children", "identifier", cueIdentifier)
+			q.log().Warn("Invalid reply format for cue list children", "identifier", cueIdentifier)
 			continue
 		}
 
 		var childrenData map[string]any
 		err := json.Unmarshal([]byte(childrenStr), &childrenData)
 		if err != nil {
-			log.Error("Failed to parse cue list children", "identifier", cueIdentifier, "error", err)
+			q.log().Error("Failed to parse cue list children", "identifier", cueIdentifier, "error", err)
 			continue
 		}
 
 		// Check for error status
 		if status, ok := childrenData["status"].(string); ok && status == "error" {
-			log.Error("QLab error fetching children for cue list", "identifier", cueIdentifier, "response", childrenStr)
+			q.log().Error("QLab error fetching children for cue list", "identifier", cueIdentifier, "response", childrenStr)
 			continue
 		}
 
 		// Extract the cues and add them to the cue list
 		if childrenCues, ok := childrenData["data"].([]any); ok {
 			cueList["cues"] = childrenCues
-			log.Info("Successfully fetched cues for cue list", "identifier", cueIdentifier, "count", len(childrenCues))
+			q.log().Info("Successfully fetched cues for cue list", "identifier", cueIdentifier, "count", len(childrenCues))
 		} else {
-			log.Warn("No cues data found in children response for cue list", "identifier", cueIdentifier)
+			q.log().Warn("No cues data found in children response for cue list", "identifier", cueIdentifier)
 		}
 	}
 
 	// Enrich cues with additional properties not included in /cueLists
 	q.enrichCuesWithProperties(replyData)
 
-	// Enrich cues with additional properties not included in /cueLists
-	q.enrichCuesWithProperties(replyData)
-
 	// Return the enhanced workspace data
 	return replyData, nil
 }
 
+// cueListsProperties are the cue properties /cueLists reports
+var cueListsProperties = []string{"uniqueID", "number", "name", "listName", "type", "colorName", "flagged", "armed"}
+
 // enrichCuesWithProperties queries additional cue properties not included in /cueLists response
 // According to QLab OSC docs, /cueLists only returns: uniqueID, number, name, listName, type,
 // colorName, flagged, armed. We need to query fileTarget and other properties separately.
@@ -300,164 +296,88 @@ func (q *Workspace) enrichCuesWithProperties(workspace map[string]any) {
 		return
 	}
 
+	var cues []map[string]any
 	for _, cueListData := range data {
 		if cueList, ok := cueListData.(map[string]any); ok {
-			if cues, ok := cueList["cues"].([]any); ok {
-				q.enrichCueArrayWithProperties(cues)
+			if listCues, ok := cueList["cues"].([]any); ok {
+				cues = collectEnrichableCues(listCues, cues)
 			}
 		}
 	}
+	q.enrichCuesConcurrently(cues)
 }
 
-// enrichCueArrayWithProperties recursively enriches an array of cues with additional properties
-func (q *Workspace) enrichCueArrayWithProperties(cues []any) {
-	for _, cueData := range cues {
-		if cue, ok := cueData.(map[string]any); ok {
-			// Get uniqueID for property queries
-			uniqueID, ok := cue["uniqueID"].(string)
-			if !ok || uniqueID == "" {
-				continue
-			}
-
-			// Query fileTarget property
-			q.queryCueProperty(cue, uniqueID, "fileTarget")
-
-			// Query cueTargetNumber property
-			q.queryCueProperty(cue, uniqueID, "cueTargetNumber")
-
-			// Recursively enrich child cues
-			if children, ok := cue["cues"].([]any); ok {
-				q.enrichCueArrayWithProperties(children)
-			}
-		}
+// enrichCueProperties reads the properties of a single cue that /cueLists does not include,
+// in one valuesForKeys round trip where QLab allows it
+func (q *Workspace) enrichCueProperties(cue map[string]any, uniqueID string) {
+	cueType, _ := cue["type"].(string)
+	keys := slices.Concat(q.enrichedProperties(), typeValueKeys(cueType), followValueKeys, triggerValueKeys, colorValueKeys)
+	comparedKeys := q.comparedValueKeys(cueType, keys)
+	keys = append(keys, comparedKeys...)
+	if key := q.externalIDReadKey(); key != "" && !slices.Contains(keys, key) {
+		keys = append(keys, key)
 	}
-}
 
-// queryCueProperty queries a single property from QLab and adds it to the cue map if not empty
-func (q *Workspace) queryCueProperty(cue map[string]any, uniqueID, property string) {
-	address := fmt.Sprintf("/workspace/%s/cue_id/%s/%s", q.workspace_id, uniqueID, property)
-	reply := q.Send(address, "")
-	log.Debug("Querying cue property", "uniqueID", uniqueID, "property", property, "reply_count", len(reply))
-	if len(reply) > 0 {
-		if replyStr, ok := reply[0].(string); ok {
-			log.Debug("Got reply for property", "property", property, "reply", replyStr)
-			var replyData map[string]any
-			if err := json.Unmarshal([]byte(replyStr), &replyData); err == nil {
-				if status, ok := replyData["status"].(string); ok && status == "ok" {
-					if value, ok := replyData["data"].(string); ok && value != "" {
-						cue[property] = value
-						log.Debug("Enriched cue with property", "uniqueID", uniqueID, "property", property, "value", value)
-					} else {
-						log.Debug("Property value is empty or not a string", "property", property, "data", replyData["data"])
-					}
-				} else {
-					log.Debug("Property query status not ok", "property", property, "status", status)
-				}
+	values, err := q.GetCueValues(uniqueID, keys)
+	if err != nil {
+		q.log().Debug("valuesForKeys failed, querying properties one at a time", "uniqueID", uniqueID, "error", err)
+		values = make(map[string]any)
+		for _, key := range keys {
+			if value, ok := q.queryCueValue(uniqueID, key); ok {
+				values[key] = value
 			}
 		}
 	}
-}
-
-// findMostRecentCacheFile finds the most recent cache file for a given CUE file
-func findMostRecentCacheFile(filePath string) (string, error) {
-	// Get current user's home directory
-	usr, err := user.Current()
-	if err != nil {
-		return "", fmt.Errorf("failed to get current user: %v", err)
-	}
-
-	cacheDir := filepath.Join(usr.HomeDir, ".cache", "cuejitsu")
-
-	// Check if cache directory exists
-	if _, err := os.Stat(cacheDir); os.IsNotExist(err) {
-		return "", fmt.Errorf("cache directory does not exist")
-	}
-
-	// Get base filename without extension
-	baseName := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
-
-	// Find all cache files matching the pattern
-	pattern := filepath.Join(cacheDir, baseName+"_*.json")
-	matches, err := filepath.Glob(pattern)
-	if err != nil {
-		return "", fmt.Errorf("failed to search for cache files: %v", err)
-	}
-
-	if len(matches) == 0 {
-		return "", fmt.Errorf("no cache files found for %s", baseName)
-	}
 
-	// Sort by modification time (most recent first)
-	// We'll use a simple approach: sort by filename (timestamp is in filename)
-	var newestFile string
-	var newestTime time.Time
-
-	for _, match := range matches {
-		info, err := os.Stat(match)
-		if err != nil {
-			continue
-		}
-		if info.ModTime().After(newestTime) {
-			newestTime = info.ModTime()
-			newestFile = match
+	for _, property := range q.enrichedProperties() {
+		if value, ok := values[property].(string); ok && value != "" {
+			cue[property] = value
 		}
 	}
 
-	if newestFile == "" {
-		return "", fmt.Errorf("no valid cache files found")
-	}
+	enrichFollow(cue, values)
+	enrichTriggers(cue, values)
+	enrichCompared(cue, values, colorValueKeys)
+	q.enrichExternalID(cue, values)
 
-	return newestFile, nil
+	// Type-specific properties are not included in /cueLists
+	if strings.EqualFold(cueType, CueTypeFade) {
+		q.enrichFadeCue(cue, uniqueID, values)
+	} else if strings.EqualFold(cueType, CueTypeAudio) {
+		q.enrichMasterLevel(cue, uniqueID)
+	} else if strings.EqualFold(cueType, CueTypeVideo) {
+		enrichVideoCue(cue, values)
+	} else if specs := mappedCueProperties(cueType); specs != nil {
+		enrichMappedCue(cue, values, specs)
+	}
+	enrichCompared(cue, values, comparedKeys)
 }
 
-// loadCacheFileData loads workspace data from a cache file
-func loadCacheFileData(cacheFilePath string) (map[string]any, error) {
-	data, err := os.ReadFile(cacheFilePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read cache file: %v", err)
+// typeValueKeys returns the type-specific properties enrichment reads for a cue type
+func typeValueKeys(cueType string) []string {
+	if strings.EqualFold(cueType, CueTypeFade) {
+		return fadeValueKeys
+	} else if strings.EqualFold(cueType, CueTypeVideo) {
+		return videoValueKeys
 	}
+	return cuePropertyKeys(mappedCueProperties(cueType))
+}
 
-	var workspace map[string]any
-	err = json.Unmarshal(data, &workspace)
-	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal cache data: %v", err)
+// cueValue returns a property from values read with GetCueValues, treating missing, null,
+// and empty values as unset
+func cueValue(values map[string]any, key string) (any, bool) {
+	value, ok := values[key]
+	if !ok || value == nil || value == "" {
+		return nil, false
 	}
-
-	return workspace, nil
+	return value, true
 }
 
 // extractCueIdentifier extracts the cue identifier (similar to indexCuesFromWorkspace logic)
 func (q *Workspace) extractCueIdentifier(cue map[string]any, parentNumber string) string {
-	// Extract cue number (same logic as indexCuesFromWorkspace)
-	var cueNumber string
-	if num, ok := cue["number"]; ok && num != nil {
-		switch v := num.(type) {
-		case string:
-			cueNumber = v
-		case float64:
-			if v == float64(int64(v)) && v >= 0 && v <= 999 {
-				cueNumber = fmt.Sprintf("%.1f", v)
-			} else {
-				cueNumber = fmt.Sprintf("%g", v)
-			}
-		case int64:
-			cueNumber = fmt.Sprintf("%d", v)
-		case int:
-			cueNumber = fmt.Sprintf("%d", v)
-		default:
-			cueNumber = fmt.Sprintf("%v", v)
-		}
-	}
-
-	// Build full cue number with parent prefix (same logic as processing)
-	fullNumber := cueNumber
-	if parentNumber != "" && cueNumber != "" {
-		if strings.Contains(cueNumber, ".") {
-			fullNumber = cueNumber
-		} else {
-			fullNumber = parentNumber + "." + cueNumber
-		}
-	}
+	// Same numbering as indexCuesFromWorkspace
+	cueNumber := formatCueNumber(cue["number"])
+	fullNumber := q.fullCueNumber(parentNumber, cueNumber)
 
 	// If no number, create position-based identifier (same as indexCuesFromWorkspace)
 	if fullNumber == "" {
@@ -507,7 +427,7 @@ func (q *Workspace) findAndReplaceCueInArray(cues []any, cachedCue map[string]an
 			if q.extractCueIdentifier(cue, parentNumber) == cueNumber {
 				// Found the cue - replace it with cached data
 				cues[i] = cachedCue
-				log.Debugf("Replaced cue %s with cached data", cueNumber)
+				q.log().Debugf("Replaced cue %s with cached data", cueNumber)
 				return true
 			}
 
@@ -528,7 +448,7 @@ func (q *Workspace) findAndReplaceCueInArray(cues []any, cachedCue map[string]an
 
 // PerformThreeWayComparison compares source CUE file, cache, and current QLab state
 func (q *Workspace) PerformThreeWayComparison(filePath string, sourceCueData map[string]any) (*ThreeWayComparison, error) {
-	log.Debugf("PerformThreeWayComparison called for file: %s", filePath)
+	q.log().Debugf("PerformThreeWayComparison called for file: %s", filePath)
 	comparison := &ThreeWayComparison{
 		CueResults:       make(map[string]*CueChangeResult),
 		HasCache:         false,
@@ -537,42 +457,46 @@ func (q *Workspace) PerformThreeWayComparison(filePath string, sourceCueData map
 		QLabChosenCues:   make(map[string]bool),
 		QLabChosenFields: make(map[string]map[string]bool),
 		CurrentQLabData:  make(map[string]any),
+		SourceData:       sourceCueData,
 		WorkspaceScope:   nil,
 		MergedResult:     nil,
+		Duplicates:       findDuplicateCues(sourceCueData, q.sourceCueKey),
+	}
+	for _, dup := range comparison.Duplicates {
+		q.log().Warnf("Duplicate cue identifier %s at %s; only the last occurrence is compared", dup.Identifier, strings.Join(dup.Paths, ", "))
 	}
 
 	// Step 1: Try to load cache data
-	var cachedWorkspace map[string]any
-	cacheFilePath, err := findMostRecentCacheFile(filePath)
+	cacheEntry, cachedWorkspace, cacheMeta, err := q.loadLatestCache(filePath)
 	if err != nil {
-		log.Infof("No cache file found: %v", err)
-	} else {
-		cachedWorkspace, err = loadCacheFileData(cacheFilePath)
-		if err != nil {
-			log.Warnf("Failed to load cache data: %v", err)
+		if cacheEntry.Key == "" {
+			q.log().Infof("No cache file found: %v", err)
 		} else {
-			comparison.HasCache = true
-			log.Infof("Loaded cache from: %s", cacheFilePath)
+			q.log().Warnf("Failed to load cache data: %v", err)
 		}
+	} else {
+		comparison.HasCache = true
+		comparison.Remembered = cacheMeta.Resolutions
+		q.log().Infof("Loaded cache from: %s", cacheEntry.Key)
 	}
 
 	// Step 2: Query current QLab workspace state
 	var currentWorkspace map[string]any
 	currentWorkspace, err = q.queryCurrentWorkspaceState()
 	if err != nil {
-		if q.wasConnected {
-			log.Warnf("Failed to query current QLab state: %v", err)
+		if q.wasConnected.Load() {
+			q.log().Warnf("Failed to query current QLab state: %v", err)
 
 			// Try lightweight fallback query if full query times out
-			if strings.Contains(err.Error(), "timeout") {
-				log.Info("Attempting lightweight fallback query...")
+			if errors.Is(err, ErrTimeout) {
+				q.log().Info("Attempting lightweight fallback query...")
 				currentWorkspace, err = q.queryWorkspaceStateLightweight()
 				if err == nil {
-					log.Info("Lightweight fallback query succeeded")
+					q.log().Info("Lightweight fallback query succeeded")
 					comparison.HasQLabData = true
 					comparison.CurrentQLabData = currentWorkspace
 				} else {
-					log.Warnf("Lightweight fallback query also failed: %v", err)
+					q.log().Warnf("Lightweight fallback query also failed: %v", err)
 					comparison.HasQLabData = false
 					comparison.CurrentQLabData = nil
 				}
@@ -582,39 +506,48 @@ func (q *Workspace) PerformThreeWayComparison(filePath string, sourceCueData map
 				comparison.CurrentQLabData = nil
 			}
 		} else {
-			log.Debugf("Failed to query current QLab state (not connected): %v", err)
+			q.log().Debugf("Failed to query current QLab state (not connected): %v", err)
 			comparison.HasQLabData = false
 			comparison.CurrentQLabData = nil
 		}
 	} else {
 		comparison.HasQLabData = true
 		comparison.CurrentQLabData = currentWorkspace
-		log.Info("Queried current QLab workspace state")
+		q.log().Info("Queried current QLab workspace state")
+	}
+
+	// Check the cache's timestamps against both clocks now that QLab has replied
+	if comparison.HasCache {
+		qlabNow, qlabKnown := q.QLabClock()
+		comparison.ClockSkew = detectClockSkew(cacheMeta, time.Now(), qlabNow, qlabKnown)
+		for _, warning := range comparison.ClockSkew.Warnings {
+			q.log().Warnf("Clock skew: %s", warning)
+		}
 	}
 
 	// Step 3: Compare cache with current QLab state if both available
 	if comparison.HasCache && comparison.HasQLabData {
 		comparison.CacheMatchesQLab = q.compareCacheWithCurrentState(cachedWorkspace, currentWorkspace)
 		if comparison.CacheMatchesQLab {
-			log.Info("Cache matches current QLab state")
+			q.log().Info("Cache matches current QLab state")
 		} else {
-			log.Warn("Cache differs from current QLab state")
+			q.log().Warn("Cache differs from current QLab state")
 		}
 
 		// Perform scope-based comparison for granular conflict detection
-		log.Debug("Performing scope-based comparison")
+		q.log().Debug("Performing scope-based comparison")
 		scopeComparison, err := q.PerformScopeBasedComparison(sourceCueData, cachedWorkspace, currentWorkspace)
 		if err != nil {
-			log.Warnf("Scope-based comparison failed: %v", err)
+			q.log().Warnf("Scope-based comparison failed: %v", err)
 		} else {
 			comparison.WorkspaceScope = scopeComparison
-			log.Infof("Scope-based comparison complete: hasChanges=%t, hasConflicts=%t",
+			q.log().Infof("Scope-based comparison complete: hasChanges=%t, hasConflicts=%t",
 				scopeComparison.HasChanges, scopeComparison.ConflictExists)
 		}
 	} else if comparison.HasCache && !comparison.HasQLabData {
 		// Cache exists but QLab query failed - use cache as fallback for comparison
-		log.Warn("QLab query failed - using cache-only comparison mode")
-		log.Info("Will compare source against cached state (QLab state unavailable)")
+		q.log().Warn("QLab query failed - using cache-only comparison mode")
+		q.log().Info("Will compare source against cached state (QLab state unavailable)")
 	}
 
 	// Step 4: Build cue comparison results
@@ -633,6 +566,10 @@ func (q *Workspace) PerformThreeWayComparison(filePath string, sourceCueData map
 		currentCues = make(map[string]map[string]any)
 	}
 
+	// Cues are matched by uniqueID first, so renumbered and renamed cues are updated in place
+	identities := q.matchCueIdentities(sourceCues, cachedCues, currentCues, cacheMeta)
+	cachedByID, currentByID := cueIDIndex(cachedCues), cueIDIndex(currentCues)
+
 	// Compare each source cue
 	for cueNumber, sourceCue := range sourceCues {
 		result := &CueChangeResult{
@@ -643,7 +580,7 @@ func (q *Workspace) PerformThreeWayComparison(filePath string, sourceCueData map
 		}
 
 		// Check if cue exists in current QLab state
-		if currentCue, existsInQLab := currentCues[cueNumber]; existsInQLab {
+		if currentCue, currentKey, existsInQLab := identifiedCue(currentCues, currentByID, identities[cueNumber], cueNumber); existsInQLab {
 			// Extract existing ID
 			if id, ok := currentCue["uniqueID"].(string); ok {
 				result.ExistingID = id
@@ -651,24 +588,24 @@ func (q *Workspace) PerformThreeWayComparison(filePath string, sourceCueData map
 
 			// Debug position-based cues specifically
 			if strings.Contains(cueNumber, "[audio:") {
-				log.Debugf("Position-based audio cue found in QLab: %s", cueNumber)
-				log.Debugf("Checking if exists in cache...")
+				q.log().Debugf("Position-based audio cue found in QLab: %s", cueNumber)
+				q.log().Debugf("Checking if exists in cache...")
 			}
 
 			// Check if cue exists in cache
-			if cachedCue, existsInCache := cachedCues[cueNumber]; existsInCache {
+			if cachedCue, _, existsInCache := identifiedCue(cachedCues, cachedByID, identities[cueNumber], cueNumber); existsInCache {
 				if strings.Contains(cueNumber, "[audio:") {
-					log.Debugf("Position-based audio cue FOUND in cache: %s", cueNumber)
+					q.log().Debugf("Position-based audio cue FOUND in cache: %s", cueNumber)
 				}
 				// Debug: Show first cue properties in detail
 				if cueNumber == "0" {
-					log.Debugf("=== CUE 0 DETAILED COMPARISON ===")
-					log.Debugf("Source cue keys: %v", getKeys(sourceCue))
-					log.Debugf("Cached cue keys: %v", getKeys(cachedCue))
-					log.Debugf("Current cue keys: %v", getKeys(currentCue))
-					log.Debugf("Source name: '%v'", sourceCue["name"])
-					log.Debugf("Cached name: '%v'", cachedCue["name"])
-					log.Debugf("Current name: '%v'", currentCue["name"])
+					q.log().Debugf("=== CUE 0 DETAILED COMPARISON ===")
+					q.log().Debugf("Source cue keys: %v", getKeys(sourceCue))
+					q.log().Debugf("Cached cue keys: %v", getKeys(cachedCue))
+					q.log().Debugf("Current cue keys: %v", getKeys(currentCue))
+					q.log().Debugf("Source name: '%v'", sourceCue["name"])
+					q.log().Debugf("Cached name: '%v'", cachedCue["name"])
+					q.log().Debugf("Current name: '%v'", currentCue["name"])
 				}
 
 				// Three-way comparison: source vs cache vs current
@@ -737,6 +674,7 @@ func (q *Workspace) PerformThreeWayComparison(filePath string, sourceCueData map
 					result.ModifiedFields = sourceCurrentDiffs
 				}
 			}
+			markRenumbered(result, cueNumber, currentKey, currentCue)
 		} else {
 			// Cue doesn't exist in QLab
 			result.HasChanged = true
@@ -745,9 +683,15 @@ func (q *Workspace) PerformThreeWayComparison(filePath string, sourceCueData map
 			result.ModifiedFields = make(map[string]string) // No existing cue to compare against
 		}
 
+		result.ColorMapping = q.colorMapping(sourceCue)
 		comparison.CueResults[cueNumber] = result
 	}
 
+	q.detectMoves(comparison, sourceCueData)
+	if q.syncDeletions {
+		q.detectDeletions(comparison, sourceCueData, sourceCues, cachedCues, currentCues)
+	}
+
 	// Link scope data to cue results if scope comparison was performed
 	if comparison.WorkspaceScope != nil {
 		q.linkScopeDataToCueResults(comparison)
@@ -774,30 +718,46 @@ func (q *Workspace) linkScopeDataToCueResults(comparison *ThreeWayComparison) {
 
 // PrintThreeWayComparisonResults outputs a detailed summary of the three-way comparison
 func (q *Workspace) PrintThreeWayComparisonResults(comparison *ThreeWayComparison) {
-	log.Info("=== Three-Way Comparison Results ===")
+	q.log().Info("=== Three-Way Comparison Results ===")
 
 	// Print overall status
-	log.Infof("Has Cache: %t", comparison.HasCache)
-	log.Infof("Has QLab Data: %t", comparison.HasQLabData)
-	log.Infof("Cache Matches QLab: %t", comparison.CacheMatchesQLab)
+	q.log().Infof("Has Cache: %t", comparison.HasCache)
+	q.log().Infof("Has QLab Data: %t", comparison.HasQLabData)
+	q.log().Infof("Cache Matches QLab: %t", comparison.CacheMatchesQLab)
+	if comparison.ClockSkew != nil {
+		clock := "local clock"
+		if comparison.ClockSkew.AgeFromQLab {
+			clock = "QLab's clock"
+		}
+		q.log().Infof("Cache Age: %s (by %s)", comparison.ClockSkew.CacheAge.Round(time.Second), clock)
+		if comparison.ClockSkew.Significant() {
+			q.log().Warn("Clock skew detected - cache age and staleness judgments may be wrong:")
+			for _, warning := range comparison.ClockSkew.Warnings {
+				q.log().Warnf("  %s", warning)
+			}
+		}
+	}
 
 	// Count results by action
 	actionCounts := map[string]int{
 		"create": 0,
 		"update": 0,
 		"skip":   0,
+		"move":   0,
+		"keep":   0,
+		"delete": 0,
 	}
 
 	for _, result := range comparison.CueResults {
 		actionCounts[result.Action]++
 	}
 
-	log.Infof("Action Summary: %d create, %d update, %d skip",
-		actionCounts["create"], actionCounts["update"], actionCounts["skip"])
+	q.log().Infof("Action Summary: %d create, %d update, %d skip, %d move, %d keep, %d delete",
+		actionCounts["create"], actionCounts["update"], actionCounts["skip"], actionCounts["move"], actionCounts["keep"], actionCounts["delete"])
 
 	// Print detailed results for each cue
 	if len(comparison.CueResults) > 0 {
-		log.Info("--- Cue-by-Cue Results ---")
+		q.log().Info("--- Cue-by-Cue Results ---")
 		for cueNumber, result := range comparison.CueResults {
 			status := "CHANGED"
 			if !result.HasChanged {
@@ -813,20 +773,20 @@ func (q *Workspace) PrintThreeWayComparisonResults(comparison *ThreeWayCompariso
 				cueInfo += fmt.Sprintf(" (existing ID: %s)", result.ExistingID)
 			}
 
-			log.Infof("%s: %s - Action: %s - Reason: %s",
+			q.log().Infof("%s: %s - Action: %s - Reason: %s",
 				cueInfo, status, result.Action, result.Reason)
 
 			// Show field differences if any
 			if len(result.ModifiedFields) > 0 {
-				log.Info("  Modified fields:")
+				q.log().Info("  Modified fields:")
 				for field, diff := range result.ModifiedFields {
-					log.Infof("    %s: %s", field, diff)
+					q.log().Infof("    %s: %s", field, diff)
 				}
 			}
 		}
 	} else {
-		log.Info("No cues found in source file")
+		q.log().Info("No cues found in source file")
 	}
 
-	log.Info("=== End Three-Way Comparison ===")
+	q.log().Info("=== End Three-Way Comparison ===")
 }
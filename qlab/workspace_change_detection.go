@@ -3,11 +3,13 @@ package qlab
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/huh"
-	"github.com/charmbracelet/log"
+	"github.com/hypebeast/go-osc/osc"
 	"github.com/zenibako/qlab-golang/messages"
 )
 
@@ -45,20 +47,22 @@ func (q *Workspace) compareCueProperties(cue1, cue2 map[string]any) bool {
 	return len(differences) == 0
 }
 
-// compareCuePropertiesDetailed compares properties and returns detailed differences
+// compareCuePropertiesDetailed compares the properties in ComparedProperties for the cue
+// type and returns detailed differences
 func (q *Workspace) compareCuePropertiesDetailed(cue1, cue2 map[string]any) map[string]string {
-	// List of all properties we might want to compare
-	allProperties := []string{
-		"name", "type", "fileTarget", "duration", "cueTargetNumber",
-		"armed", "colorName", "flagged", "notes",
+	cueType, _ := cue1["type"].(string)
+	if cueType == "" {
+		cueType, _ = cue2["type"].(string)
 	}
 
 	differences := make(map[string]string)
 
-	for _, prop := range allProperties {
-		// Only compare properties that exist in both cues or where one has a meaningful value
-		val1 := q.normalizeProperty(cue1[prop])
-		val2 := q.normalizeProperty(cue2[prop])
+	for _, prop := range q.ComparedProperties(cueType) {
+		val1 := q.comparedValue(cue1, prop)
+		val2 := q.comparedValue(cue2, prop)
+		if prop == "levels" {
+			val1, val2 = sharedLevels(cue1, cue2)
+		}
 
 		// Skip comparison if both values are empty/missing
 		if val1 == "" && val2 == "" {
@@ -67,14 +71,9 @@ func (q *Workspace) compareCuePropertiesDetailed(cue1, cue2 map[string]any) map[
 
 		// For properties that may not exist in QLab data (like fileTarget, cueTargetNumber),
 		// only compare if BOTH cues have the property defined
-		if prop == "fileTarget" || prop == "cueTargetNumber" {
-			// Check if both cues actually have this property key
-			_, has1 := cue1[prop]
-			_, has2 := cue2[prop]
-
-			// Only compare if BOTH cues have this property
+		if q.isSparseComparedProperty(prop) {
 			// If one cue lacks the property entirely, skip comparison to avoid false positives
-			if !has1 || !has2 {
+			if !hasComparedValue(cue1, prop) || !hasComparedValue(cue2, prop) {
 				continue
 			}
 		}
@@ -94,21 +93,40 @@ func (q *Workspace) comparePropertyValues(property, val1, val2 string) bool {
 		return true
 	}
 
-	// Handle boolean properties: treat "false", "" and "true" as equivalent for armed/flagged
-	// These are operational states, not content that should trigger updates
+	// Armed/flagged are operational states, not content that should trigger updates,
+	// unless the source's states are being synced
 	if property == "armed" || property == "flagged" {
-		// All boolean states should be considered equivalent for cue matching
-		// Armed/flagged states are user-controlled and shouldn't prevent cue recognition
+		return q.compareOperationalState(val1, val2)
+	}
+
+	// Cues are placed in their listName's cue list when created and never moved between
+	// lists, so a cue in another list isn't a change that could be sent
+	if property == "listName" {
 		return true
 	}
 
+	// Continue modes may be given by name
+	if property == "continueMode" {
+		val1, val2 = normalizeContinueMode(val1), normalizeContinueMode(val2)
+		if val1 == val2 {
+			return true
+		}
+	}
+
 	// Handle numeric properties: treat "0" and "" as equivalent (both are zero values)
-	if property == "duration" {
+	if property == "duration" || property == "continueMode" || property == "postWait" || property == "preWait" {
 		if (val1 == "0" && val2 == "") || (val1 == "" && val2 == "0") {
 			return true
 		}
 	}
 
+	// Group modes may be given by name
+	if property == "mode" {
+		mode1, err1 := ParseGroupMode(val1)
+		mode2, err2 := ParseGroupMode(val2)
+		return err1 == nil && err2 == nil && mode1 == mode2
+	}
+
 	// Handle type property: QLab capitalizes cue types
 	if property == "type" {
 		// Normalize both values to lowercase for comparison
@@ -179,23 +197,55 @@ func (q *Workspace) processCueList(cueData map[string]any, parentNumber string)
 
 // setCueTargets sets cue targets using the number-to-ID mapping
 func (q *Workspace) setCueTargets(mapping *CueMapping) error {
+	q.progress.setTotal(PhaseTargets, len(mapping.CuesWithTargets))
 	for _, cueTarget := range mapping.CuesWithTargets {
+		q.progress.step(PhaseTargets, mapping.numberOf(cueTarget.UniqueID))
+		// Targets given only by ID are set directly
+		if cueTarget.TargetNumber == "" && cueTarget.TargetName == "" {
+			if err := q.setCueProperty(cueTarget.UniqueID, "cueTargetID", cueTarget.TargetID); err != nil {
+				return fmt.Errorf("failed to set cue target %s: %v", cueTarget.TargetID, err)
+			}
+			continue
+		}
+		// Resolve name-based targets to a number, or to an ID for unnumbered cues
+		if cueTarget.TargetName != "" {
+			target, err := q.resolveTargetName(mapping, cueTarget.TargetName)
+			if err != nil {
+				q.log().Warnf("Cannot set target of cue %s: %v", cueTarget.UniqueID, err)
+				continue
+			}
+			if target.Number == "" {
+				if err := q.setCueProperty(cueTarget.UniqueID, "cueTargetID", target.UniqueID); err != nil {
+					return fmt.Errorf("failed to set cue target %q -> %s: %v", cueTarget.TargetName, target.UniqueID, err)
+				}
+				q.log().Infof("Set cue target via name: %s -> %q (%s)", cueTarget.UniqueID, cueTarget.TargetName, target.UniqueID)
+				continue
+			}
+			cueTarget.TargetNumber = target.Number
+			mapping.NumberToID[target.Number] = target.UniqueID
+		}
+
 		// First try to use cueTargetNumber (preferred approach)
 		if err := q.setCueProperty(cueTarget.UniqueID, "cueTargetNumber", cueTarget.TargetNumber); err != nil {
-			log.Warnf("Failed to set cueTargetNumber %s for cue %s, trying cueTargetID fallback: %v",
+			q.log().Warnf("Failed to set cueTargetNumber %s for cue %s, trying cueTargetID fallback: %v",
 				cueTarget.TargetNumber, cueTarget.UniqueID, err)
 
-			// Fallback to cueTargetID if number approach failed
-			if targetID, exists := mapping.NumberToID[cueTarget.TargetNumber]; exists {
+			// Fallback to cueTargetID if number approach failed, preferring the cue created
+			// with that number over the ID given in the source
+			targetID, exists := mapping.NumberToID[cueTarget.TargetNumber]
+			if !exists && cueTarget.TargetID != "" {
+				targetID, exists = cueTarget.TargetID, true
+			}
+			if exists {
 				if err := q.setCueProperty(cueTarget.UniqueID, "cueTargetID", targetID); err != nil {
 					return fmt.Errorf("failed to set cue target %s -> %s: %v", cueTarget.TargetNumber, targetID, err)
 				}
-				log.Infof("Set cue target via ID fallback: %s -> %s (%s)", cueTarget.UniqueID, cueTarget.TargetNumber, targetID)
+				q.log().Infof("Set cue target via ID fallback: %s -> %s (%s)", cueTarget.UniqueID, cueTarget.TargetNumber, targetID)
 			} else {
-				log.Warnf("Target cue number %s not found for cue %s", cueTarget.TargetNumber, cueTarget.UniqueID)
+				q.log().Warnf("Target cue number %s not found for cue %s", cueTarget.TargetNumber, cueTarget.UniqueID)
 			}
 		} else {
-			log.Infof("Set cue target via number: %s -> %s", cueTarget.UniqueID, cueTarget.TargetNumber)
+			q.log().Infof("Set cue target via number: %s -> %s", cueTarget.UniqueID, cueTarget.TargetNumber)
 		}
 	}
 	return nil
@@ -205,61 +255,24 @@ func (q *Workspace) setCueTargets(mapping *CueMapping) error {
 func (q *Workspace) processCueListWithParent(cueData map[string]any, parentNumber string, parentUniqueID string) (string, error) {
 	cueType, _ := cueData["type"].(string)
 	cueName, _ := cueData["name"].(string)
-	var cueNumber string
-	if num, ok := cueData["number"]; ok && num != nil {
-		// Handle different number types while preserving decimal format
-		switch v := num.(type) {
-		case string:
-			// Already a string, use as-is
-			cueNumber = v
-		case float64:
-			// For float64, use %g to get natural representation,
-			// but preserve at least one decimal place for whole numbers if they came from "X.0"
-			if v == float64(int64(v)) && v >= 0 && v <= 999 {
-				// It's a whole number that might have been "X.0" originally
-				// Use %.1f to force one decimal place for common cue numbers
-				cueNumber = fmt.Sprintf("%.1f", v)
-			} else {
-				// Use %g for non-whole numbers (preserves natural format)
-				cueNumber = fmt.Sprintf("%g", v)
-			}
-		case int64:
-			cueNumber = fmt.Sprintf("%d", v)
-		case int:
-			cueNumber = fmt.Sprintf("%d", v)
-		default:
-			cueNumber = fmt.Sprintf("%v", v)
-		}
-	}
+	cueNumber := formatCueNumber(cueData["number"])
 
-	// Build full cue number with parent prefix
-	fullNumber := cueNumber
-	if parentNumber != "" && cueNumber != "" {
-		// Check if the cue number appears to be absolute (contains decimal point)
-		// or relative (simple integer/short string)
-		// Absolute numbers like "1.0", "2.5", etc. should not be concatenated
-		// Relative numbers like "1", "2", "a", etc. should be concatenated
-		if strings.Contains(cueNumber, ".") {
-			// Likely an absolute cue number, use as-is
-			fullNumber = cueNumber
-		} else {
-			// Likely a relative cue number, concatenate with parent
-			fullNumber = parentNumber + "." + cueNumber
-		}
-	}
+	// Relative numbers like 1 or a are prefixed with the parent's; ones with a decimal
+	// point like 1.0 or 2.5 are absolute
+	fullNumber := q.fullCueNumber(parentNumber, cueNumber)
 
 	if cueName != "" {
 		if fullNumber != "" {
-			log.Infof("Processing cue: [%s] %s (%s)", fullNumber, cueName, cueType)
+			q.log().Infof("Processing cue: [%s] %s (%s)", fullNumber, cueName, cueType)
 		} else {
-			log.Infof("Processing cue: %s (%s)", cueName, cueType)
+			q.log().Infof("Processing cue: %s (%s)", cueName, cueType)
 		}
 	}
 
 	// Create the cue in QLab
 	uniqueID, err := q.createCue(cueData, fullNumber)
 	if err != nil {
-		return "", fmt.Errorf("failed to create cue %s: %v", fullNumber, err)
+		return "", fmt.Errorf("failed to create cue %s: %w", fullNumber, err)
 	}
 
 	// Move cue into parent group if we have a parent
@@ -303,9 +316,15 @@ func (q *Workspace) createCue(cueData map[string]any, cueNumber string) (string,
 		return "", fmt.Errorf("workspace ID is required for cue creation but not available")
 	}
 
+	// Resolve patch and stage names to IDs before creating anything
+	cueData, err := q.resolvePatchNames(cueData)
+	if err != nil {
+		return "", err
+	}
+
 	address := q.addressBuilder.BuildAddress(messages.MsgWorkspaceNew, nil)
-	log.Debug("Creating cue with OSC", "address", address, "type", cueType)
-	reply := q.Send(address, cueType)
+	q.log().Debug("Creating cue with OSC", "address", address, "type", cueType)
+	reply := q.Send(address, q.newCueType(cueType))
 
 	if len(reply) == 0 {
 		return "", fmt.Errorf("no reply received when creating cue")
@@ -318,28 +337,26 @@ func (q *Workspace) createCue(cueData map[string]any, cueNumber string) (string,
 	}
 
 	var newCueData map[string]any
-	err := json.Unmarshal([]byte(replyStr), &newCueData)
+	err = json.Unmarshal([]byte(replyStr), &newCueData)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse new cue reply: %v", err)
 	}
 
 	// Check for error status in reply
 	if status, ok := newCueData["status"].(string); ok && status == "error" {
-		return "", formatErrorWithJSON("QLab rejected cue creation", replyStr)
+		return "", newQLabError("QLab rejected cue creation", replyStr)
 	}
 
 	uniqueID, ok := newCueData["data"].(string)
+	if ok && uniqueID == "badpass" {
+		// "badpass" in the data field means the connection isn't authenticated
+		return "", newQLabError("QLab authentication failed - check passcode and ensure workspace is connected", replyStr)
+	}
 	if !ok {
-		// Check if data contains "badpass" indicating connection issues
-		if data, hasData := newCueData["data"]; hasData {
-			if dataStr, isStr := data.(string); isStr && dataStr == "badpass" {
-				return "", fmt.Errorf("QLab authentication failed - check passcode and ensure workspace is connected")
-			}
-		}
-		return "", formatErrorWithJSON("no uniqueID in new cue reply", replyStr)
+		return "", newQLabError("no uniqueID in new cue reply", replyStr)
 	}
 
-	log.Infof("Created cue with ID: %s", uniqueID)
+	q.log().Infof("Created cue with ID: %s", uniqueID)
 
 	// Track this cue for potential rollback
 	q.trackCreatedCue(uniqueID)
@@ -355,7 +372,7 @@ func (q *Workspace) createCue(cueData map[string]any, cueNumber string) (string,
 		if err := q.setCueProperty(uniqueID, "number", cueNumber); err != nil {
 			// Check if this is a cue number conflict error
 			if _, isConflict := err.(*CueNumberConflictError); isConflict {
-				log.Warnf("Skipping cue number assignment due to conflict: %v", err)
+				q.log().Warnf("Skipping cue number assignment due to conflict: %v", err)
 			} else {
 				return "", fmt.Errorf("failed to set cue number: %v", err)
 			}
@@ -380,7 +397,7 @@ func (q *Workspace) createCue(cueData map[string]any, cueNumber string) (string,
 	case "text":
 		if text, ok := cueData["text"].(string); ok && text != "" {
 			if err := q.setCueProperty(uniqueID, "text", text); err != nil {
-				return "", fmt.Errorf("failed to set text: %v", err)
+				return "", fmt.Errorf("failed to set text: %w", err)
 			}
 		}
 		// Set text format color (text/format/color) - requires 4 separate numeric arguments
@@ -396,103 +413,82 @@ func (q *Workspace) createCue(cueData map[string]any, cueNumber string) (string,
 			}
 		}
 	case "audio":
+		if err := q.setAudioOutputPatch(uniqueID, cueData); err != nil {
+			return "", err
+		}
+		if err := q.setAudioLevels(uniqueID, cueData); err != nil {
+			return "", err
+		}
 		if infiniteLoop, ok := cueData["infiniteLoop"].(bool); ok && infiniteLoop {
 			if err := q.setCueProperty(uniqueID, "infiniteLoop", "1"); err != nil {
 				return "", fmt.Errorf("failed to set infinite loop: %v", err)
 			}
 		}
 	case "group":
-		if mode, ok := cueData["mode"].(float64); ok {
-			if err := q.setCueProperty(uniqueID, "mode", fmt.Sprintf("%.0f", mode)); err != nil {
-				return "", fmt.Errorf("failed to set group mode: %v", err)
-			}
+		if err := q.setGroupMode(uniqueID, cueData); err != nil {
+			return "", err
 		}
 	case "fade":
-		// Set fade cue target
-		if targetNumber, ok := cueData["cueTargetNumber"].(string); ok && targetNumber != "" {
-			if err := q.setCueProperty(uniqueID, "cueTargetNumber", targetNumber); err != nil {
-				log.Warnf("Failed to set cueTargetNumber %s, trying cueTargetID fallback: %v", targetNumber, err)
-				// Fallback to cueTargetID if we have it
-				if targetID, ok := cueData["cueTargetID"].(string); ok && targetID != "" {
-					if err := q.setCueProperty(uniqueID, "cueTargetID", targetID); err != nil {
-						return "", fmt.Errorf("failed to set cue target: %v", err)
-					}
-				}
-			}
-		} else if targetID, ok := cueData["cueTargetID"].(string); ok && targetID != "" {
-			// Only cueTargetID is available
-			if err := q.setCueProperty(uniqueID, "cueTargetID", targetID); err != nil {
-				return "", fmt.Errorf("failed to set cue target: %v", err)
-			}
+		if err := q.setCueTarget(uniqueID, cueData, false); err != nil {
+			return "", err
 		}
-		// Set fade geometry parameter enables
-		if doOpacity, ok := cueData["doOpacity"].(bool); ok && doOpacity {
-			if err := q.setCueProperty(uniqueID, "doOpacity", "1"); err != nil {
-				log.Warnf("Failed to set doOpacity for fade cue %s: %v", uniqueID, err)
-			}
+		if err := q.setFadeCueProperties(uniqueID, cueData, false); err != nil {
+			return "", err
 		}
-		if doTranslation, ok := cueData["doTranslation"].(bool); ok && doTranslation {
-			if err := q.setCueProperty(uniqueID, "doTranslation", "1"); err != nil {
-				log.Warnf("Failed to set doTranslation for fade cue %s: %v", uniqueID, err)
-			}
+	case CueTypeVideo:
+		if err := q.setVideoCueProperties(uniqueID, cueData, false); err != nil {
+			return "", err
 		}
-		if doScale, ok := cueData["doScale"].(bool); ok && doScale {
-			if err := q.setCueProperty(uniqueID, "doScale", "1"); err != nil {
-				log.Warnf("Failed to set doScale for fade cue %s: %v", uniqueID, err)
-			}
+	case CueTypeMIDI, CueTypeNetwork, CueTypeOSC, CueTypeMIDIFile, CueTypeScript:
+		if err := q.setMappedCueProperties(uniqueID, cueData, mappedCueProperties(cueType), false); err != nil {
+			return "", err
 		}
-		if doRotation, ok := cueData["doRotation"].(bool); ok && doRotation {
-			if err := q.setCueProperty(uniqueID, "doRotation", "1"); err != nil {
-				log.Warnf("Failed to set doRotation for fade cue %s: %v", uniqueID, err)
-			}
+	case CueTypeLight:
+		if err := q.setLightCueProperties(uniqueID, cueData, false); err != nil {
+			return "", err
 		}
-		// Set geometry properties for fade cues
-		if opacity, ok := cueData["opacity"].(float64); ok && opacity > 0 {
-			if err := q.setCueProperty(uniqueID, "opacity", fmt.Sprintf("%g", opacity)); err != nil {
-				log.Warnf("Failed to set opacity for fade cue %s: %v", uniqueID, err)
-			}
+	case "list":
+		// List cues have read-only mode properties, skip mode setting
+	case "cart":
+		// Cart mode is read-only, but the grid must be sized before children are moved in
+		if err := q.setMappedCueProperties(uniqueID, cueData, cartCueProperties, false); err != nil {
+			return "", err
 		}
-		if translation, ok := cueData["translation"].([]any); ok && len(translation) == 2 {
-			x, _ := translation[0].(float64)
-			y, _ := translation[1].(float64)
-			if err := q.setCuePropertyWithArgs(uniqueID, "translation", float32(x), float32(y)); err != nil {
-				log.Warnf("Failed to set translation for fade cue %s: %v", uniqueID, err)
-			}
+	case CueTypeStart, CueTypeStop, CueTypePause, CueTypeReset, CueTypeGoto, CueTypeTarget, CueTypeLoad, CueTypeArm, CueTypeDisarm:
+		if err := q.setCueTarget(uniqueID, cueData, false); err != nil {
+			return "", err
 		}
-		if scale, ok := cueData["scale"].([]any); ok && len(scale) == 2 {
-			x, _ := scale[0].(float64)
-			y, _ := scale[1].(float64)
-			if err := q.setCuePropertyWithArgs(uniqueID, "scale", float32(x), float32(y)); err != nil {
-				log.Warnf("Failed to set scale for fade cue %s: %v", uniqueID, err)
-			}
+	case CueTypeDevamp:
+		if err := q.setCueTarget(uniqueID, cueData, false); err != nil {
+			return "", err
 		}
-		if rotation, ok := cueData["rotation"].(float64); ok && rotation != 0 {
-			if err := q.setCueProperty(uniqueID, "rotation", fmt.Sprintf("%g", rotation)); err != nil {
-				log.Warnf("Failed to set rotation for fade cue %s: %v", uniqueID, err)
-			}
+		if err := q.setMappedCueProperties(uniqueID, cueData, devampCueProperties, false); err != nil {
+			return "", err
 		}
-	case "list", "cart":
-		// List and Cart cues have read-only mode properties, skip mode setting
-	case "start", "stop":
-		// First try cueTargetNumber (preferred approach)
-		if targetNumber, ok := cueData["cueTargetNumber"].(string); ok && targetNumber != "" {
-			if err := q.setCueProperty(uniqueID, "cueTargetNumber", targetNumber); err != nil {
-				log.Warnf("Failed to set cueTargetNumber %s, trying cueTargetID fallback: %v", targetNumber, err)
-				// Fallback to cueTargetID if we have it
-				if targetID, ok := cueData["cueTargetID"].(string); ok && targetID != "" {
-					if err := q.setCueProperty(uniqueID, "cueTargetID", targetID); err != nil {
-						return "", fmt.Errorf("failed to set cue target: %v", err)
-					}
-				}
-			}
-		} else if targetID, ok := cueData["cueTargetID"].(string); ok && targetID != "" {
-			// Only cueTargetID is available
-			if err := q.setCueProperty(uniqueID, "cueTargetID", targetID); err != nil {
-				return "", fmt.Errorf("failed to set cue target: %v", err)
-			}
+	case CueTypeWait:
+		if err := q.setWaitCueProperties(uniqueID, cueData, false); err != nil {
+			return "", err
+		}
+	case CueTypeMemo:
+		if err := q.setMemoCueProperties(uniqueID, cueData, false); err != nil {
+			return "", err
 		}
 	}
 
+	// Follows and triggers apply to every cue type
+	if err := q.setFollowProperties(uniqueID, cueData, false); err != nil {
+		return "", err
+	}
+	if err := q.setTriggerProperties(uniqueID, cueData, false); err != nil {
+		return "", err
+	}
+	if err := q.setCueColor(uniqueID, cueData, true, false); err != nil {
+		return "", err
+	}
+	if err := q.stampExternalID(uniqueID, cueData, true); err != nil {
+		return "", fmt.Errorf("failed to stamp external ID: %w", err)
+	}
+
 	return uniqueID, nil
 }
 
@@ -506,46 +502,50 @@ func (q *Workspace) createCueWithoutTarget(cueData map[string]any, cueNumber str
 		return "", fmt.Errorf("workspace ID is required for cue creation but not available")
 	}
 
+	// Resolve patch and stage names to IDs before creating anything
+	cueData, err := q.resolvePatchNames(cueData)
+	if err != nil {
+		return "", err
+	}
+
 	address := q.addressBuilder.BuildAddress(messages.MsgWorkspaceNew, nil)
-	log.Debug("Creating cue - sending OSC", "address", address, "type", cueType)
-	reply := q.Send(address, cueType)
+	q.log().Debug("Creating cue - sending OSC", "address", address, "type", cueType)
+	reply := q.Send(address, q.newCueType(cueType))
 
 	if len(reply) == 0 {
-		log.Debug("ERROR - No reply received when creating cue", "type", cueType)
+		q.log().Debug("ERROR - No reply received when creating cue", "type", cueType)
 		return "", fmt.Errorf("no reply received when creating cue")
 	}
 
 	// Extract the new cue's unique ID from reply
 	replyStr, ok := reply[0].(string)
 	if !ok {
-		log.Debug("ERROR - Invalid reply format for cue creation", "reply", reply)
+		q.log().Debug("ERROR - Invalid reply format for cue creation", "reply", reply)
 		return "", fmt.Errorf("invalid reply format")
 	}
-	log.Debug("Received OSC reply for cue creation", "reply", replyStr)
+	q.log().Debug("Received OSC reply for cue creation", "reply", replyStr)
 
 	var newCueData map[string]any
-	err := json.Unmarshal([]byte(replyStr), &newCueData)
+	err = json.Unmarshal([]byte(replyStr), &newCueData)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse new cue reply: %v", err)
 	}
 
 	// Check for error status in reply
 	if status, ok := newCueData["status"].(string); ok && status == "error" {
-		return "", formatErrorWithJSON("QLab rejected cue creation", replyStr)
+		return "", newQLabError("QLab rejected cue creation", replyStr)
 	}
 
 	uniqueID, ok := newCueData["data"].(string)
+	if ok && uniqueID == "badpass" {
+		// "badpass" in the data field means the connection isn't authenticated
+		return "", newQLabError("QLab authentication failed - check passcode and ensure workspace is connected", replyStr)
+	}
 	if !ok {
-		// Check if data contains "badpass" indicating connection issues
-		if data, hasData := newCueData["data"]; hasData {
-			if dataStr, isStr := data.(string); isStr && dataStr == "badpass" {
-				return "", fmt.Errorf("QLab authentication failed - check passcode and ensure workspace is connected")
-			}
-		}
-		return "", formatErrorWithJSON("no uniqueID in new cue reply", replyStr)
+		return "", newQLabError("no uniqueID in new cue reply", replyStr)
 	}
 
-	log.Infof("Created cue with ID: %s", uniqueID)
+	q.log().Infof("Created cue with ID: %s", uniqueID)
 
 	// Track this cue for potential rollback
 	q.trackCreatedCue(uniqueID)
@@ -561,7 +561,7 @@ func (q *Workspace) createCueWithoutTarget(cueData map[string]any, cueNumber str
 		if err := q.setCueProperty(uniqueID, "number", cueNumber); err != nil {
 			// Check if this is a cue number conflict error
 			if _, isConflict := err.(*CueNumberConflictError); isConflict {
-				log.Warnf("Skipping cue number assignment due to conflict: %v", err)
+				q.log().Warnf("Skipping cue number assignment due to conflict: %v", err)
 			} else {
 				return "", fmt.Errorf("failed to set cue number: %v", err)
 			}
@@ -584,32 +584,28 @@ func (q *Workspace) createCueWithoutTarget(cueData map[string]any, cueNumber str
 	// Handle common cue properties
 	if notes, ok := cueData["notes"].(string); ok && notes != "" {
 		if err := q.setCueProperty(uniqueID, "notes", notes); err != nil {
-			return "", fmt.Errorf("failed to set notes: %v", err)
+			return "", fmt.Errorf("failed to set notes: %w", err)
 		}
 	}
 
-	if duration, ok := cueData["duration"].(string); ok && duration != "" && duration != "0" {
+	// Fade and wait durations are set along with their other properties
+	if duration, ok := cueData["duration"].(string); ok && duration != "" && duration != "0" && cueType != CueTypeFade && cueType != CueTypeWait {
 		if err := q.setCueProperty(uniqueID, "duration", duration); err != nil {
 			return "", fmt.Errorf("failed to set duration: %v", err)
 		}
 	}
 
-	if preWait, ok := cueData["preWait"].(string); ok && preWait != "" && preWait != "0" {
-		if err := q.setCueProperty(uniqueID, "preWait", preWait); err != nil {
-			return "", fmt.Errorf("failed to set preWait: %v", err)
-		}
-	}
-
 	if armed, ok := cueData["armed"].(string); ok && armed == "true" {
 		if err := q.setCueProperty(uniqueID, "armed", "1"); err != nil {
 			return "", fmt.Errorf("failed to set armed: %v", err)
 		}
 	}
+	if err := q.setOperationalState(uniqueID, cueData); err != nil {
+		return "", err
+	}
 
-	if colorName, ok := cueData["colorName"].(string); ok && colorName != "" && colorName != "none" {
-		if err := q.setCueProperty(uniqueID, "colorName", colorName); err != nil {
-			return "", fmt.Errorf("failed to set colorName: %v", err)
-		}
+	if err := q.setCueColor(uniqueID, cueData, true, true); err != nil {
+		return "", err
 	}
 
 	// Set type-specific properties (excluding cue targets)
@@ -618,29 +614,29 @@ func (q *Workspace) createCueWithoutTarget(cueData map[string]any, cueNumber str
 		// Set basic text property first
 		if text, ok := cueData["text"].(string); ok && text != "" {
 			if err := q.setCueProperty(uniqueID, "text", text); err != nil {
-				return "", fmt.Errorf("failed to set text: %v", err)
+				return "", fmt.Errorf("failed to set text: %w", err)
 			}
 		}
 		// Set stage assignment BEFORE format properties (required for format props to work)
 		if stageName, ok := cueData["stageName"].(string); ok && stageName != "" {
 			if err := q.setCueProperty(uniqueID, "stageName", stageName); err != nil {
-				log.Warnf("Failed to set stage name (may not exist): %v", err)
+				q.log().Warnf("Failed to set stage name (may not exist): %v", err)
 			}
 		} else if stageID, ok := cueData["stageID"].(string); ok && stageID != "" {
 			if err := q.setCueProperty(uniqueID, "stageID", stageID); err != nil {
-				log.Warnf("Failed to set stage ID (may not exist): %v", err)
+				q.log().Warnf("Failed to set stage ID (may not exist): %v", err)
 			}
 		} else {
 			// No stage specified - try to get first available stage
-			stages, err := q.getVideoStages()
+			stages, err := q.Settings().VideoStages()
 			if err == nil && len(stages) > 0 {
-				firstStageID := stages[0]["uniqueID"].(string)
-				log.Debugf("Auto-assigning text cue to first video stage: %s", firstStageID)
+				firstStageID := stages[0].UniqueID
+				q.log().Debugf("Auto-assigning text cue to first video stage: %s", firstStageID)
 				if err := q.setCueProperty(uniqueID, "stageID", firstStageID); err != nil {
-					log.Warnf("Failed to auto-assign to video stage: %v", err)
+					q.log().Warnf("Failed to auto-assign to video stage: %v", err)
 				}
 			} else {
-				log.Warnf("No video stage available for text cue - format properties may not work")
+				q.log().Warnf("No video stage available for text cue - format properties may not work")
 			}
 		}
 		// Set text format color (text/format/color) - requires 4 separate numeric arguments as float32
@@ -652,7 +648,7 @@ func (q *Workspace) createCueWithoutTarget(cueData map[string]any, cueNumber str
 			a, _ := textColor[3].(float64)
 			if err := q.setCuePropertyWithArgs(uniqueID, "text/format/color", float32(r), float32(g), float32(b), float32(a)); err != nil {
 				// Log warning but don't fail - text cue may not be patched to stage yet
-				log.Warnf("Failed to set text color for cue %s (may need stage assignment): %v", uniqueID, err)
+				q.log().Warnf("Failed to set text color for cue %s (may need stage assignment): %v", uniqueID, err)
 			}
 		}
 		// Set text background color (text/format/backgroundColor) - requires 4 separate numeric arguments as float32
@@ -664,18 +660,18 @@ func (q *Workspace) createCueWithoutTarget(cueData map[string]any, cueNumber str
 			a, _ := textBgColor[3].(float64)
 			if err := q.setCuePropertyWithArgs(uniqueID, "text/format/backgroundColor", float32(r), float32(g), float32(b), float32(a)); err != nil {
 				// Log warning but don't fail - text cue may not be patched to stage yet
-				log.Warnf("Failed to set text background color for cue %s (may need stage assignment): %v", uniqueID, err)
+				q.log().Warnf("Failed to set text background color for cue %s (may need stage assignment): %v", uniqueID, err)
 			}
 		}
 		// Set text format properties
 		if fontSize, ok := cueData["text/format/fontSize"].(float64); ok && fontSize > 0 {
 			if err := q.setCueProperty(uniqueID, "text/format/fontSize", fmt.Sprintf("%g", fontSize)); err != nil {
-				log.Warnf("Failed to set font size for cue %s: %v", uniqueID, err)
+				q.log().Warnf("Failed to set font size for cue %s: %v", uniqueID, err)
 			}
 		}
 		if alignment, ok := cueData["text/format/alignment"].(string); ok && alignment != "" {
 			if err := q.setCueProperty(uniqueID, "text/format/alignment", alignment); err != nil {
-				log.Warnf("Failed to set text alignment for cue %s: %v", uniqueID, err)
+				q.log().Warnf("Failed to set text alignment for cue %s: %v", uniqueID, err)
 			}
 		}
 		// Set geometry properties
@@ -693,77 +689,77 @@ func (q *Workspace) createCueWithoutTarget(cueData map[string]any, cueNumber str
 			x, _ := translation[0].(float64)
 			y, _ := translation[1].(float64)
 			if err := q.setCuePropertyWithArgs(uniqueID, "translation", float32(x), float32(y)); err != nil {
-				log.Warnf("Failed to set translation for cue %s: %v", uniqueID, err)
+				q.log().Warnf("Failed to set translation for cue %s: %v", uniqueID, err)
 			}
 		}
 		if opacity, ok := cueData["opacity"].(float64); ok && opacity > 0 {
 			if err := q.setCueProperty(uniqueID, "opacity", fmt.Sprintf("%g", opacity)); err != nil {
-				log.Warnf("Failed to set opacity for cue %s: %v", uniqueID, err)
+				q.log().Warnf("Failed to set opacity for cue %s: %v", uniqueID, err)
 			}
 		}
 	case "audio":
+		if err := q.setAudioOutputPatch(uniqueID, cueData); err != nil {
+			return "", err
+		}
+		if err := q.setAudioLevels(uniqueID, cueData); err != nil {
+			return "", err
+		}
 		if infiniteLoop, ok := cueData["infiniteLoop"].(bool); ok && infiniteLoop {
 			if err := q.setCueProperty(uniqueID, "infiniteLoop", "1"); err != nil {
 				return "", fmt.Errorf("failed to set infinite loop: %v", err)
 			}
 		}
 	case "group":
-		if mode, ok := cueData["mode"].(float64); ok {
-			if err := q.setCueProperty(uniqueID, "mode", fmt.Sprintf("%.0f", mode)); err != nil {
-				return "", fmt.Errorf("failed to set group mode: %v", err)
-			}
+		if err := q.setGroupMode(uniqueID, cueData); err != nil {
+			return "", err
 		}
 	case "fade":
-		// Set fade geometry parameter enables
-		if doOpacity, ok := cueData["doOpacity"].(bool); ok && doOpacity {
-			if err := q.setCueProperty(uniqueID, "doOpacity", "1"); err != nil {
-				log.Warnf("Failed to set doOpacity for fade cue %s: %v", uniqueID, err)
-			}
+		if err := q.setFadeCueProperties(uniqueID, cueData, false); err != nil {
+			return "", err
 		}
-		if doTranslation, ok := cueData["doTranslation"].(bool); ok && doTranslation {
-			if err := q.setCueProperty(uniqueID, "doTranslation", "1"); err != nil {
-				log.Warnf("Failed to set doTranslation for fade cue %s: %v", uniqueID, err)
-			}
-		}
-		if doScale, ok := cueData["doScale"].(bool); ok && doScale {
-			if err := q.setCueProperty(uniqueID, "doScale", "1"); err != nil {
-				log.Warnf("Failed to set doScale for fade cue %s: %v", uniqueID, err)
-			}
+	case CueTypeVideo:
+		if err := q.setVideoCueProperties(uniqueID, cueData, false); err != nil {
+			return "", err
 		}
-		if doRotation, ok := cueData["doRotation"].(bool); ok && doRotation {
-			if err := q.setCueProperty(uniqueID, "doRotation", "1"); err != nil {
-				log.Warnf("Failed to set doRotation for fade cue %s: %v", uniqueID, err)
-			}
+	case CueTypeMIDI, CueTypeNetwork, CueTypeOSC, CueTypeMIDIFile, CueTypeScript:
+		if err := q.setMappedCueProperties(uniqueID, cueData, mappedCueProperties(cueType), false); err != nil {
+			return "", err
 		}
-		// Set geometry properties for fade cues
-		if opacity, ok := cueData["opacity"].(float64); ok && opacity > 0 {
-			if err := q.setCueProperty(uniqueID, "opacity", fmt.Sprintf("%g", opacity)); err != nil {
-				log.Warnf("Failed to set opacity for fade cue %s: %v", uniqueID, err)
-			}
+	case CueTypeLight:
+		if err := q.setLightCueProperties(uniqueID, cueData, false); err != nil {
+			return "", err
 		}
-		if translation, ok := cueData["translation"].([]any); ok && len(translation) == 2 {
-			x, _ := translation[0].(float64)
-			y, _ := translation[1].(float64)
-			if err := q.setCuePropertyWithArgs(uniqueID, "translation", float32(x), float32(y)); err != nil {
-				log.Warnf("Failed to set translation for fade cue %s: %v", uniqueID, err)
-			}
+	case "list":
+		// List cues have read-only mode properties, skip mode setting
+	case "cart":
+		// Cart mode is read-only, but the grid must be sized before children are moved in
+		if err := q.setMappedCueProperties(uniqueID, cueData, cartCueProperties, false); err != nil {
+			return "", err
 		}
-		if scale, ok := cueData["scale"].([]any); ok && len(scale) == 2 {
-			x, _ := scale[0].(float64)
-			y, _ := scale[1].(float64)
-			if err := q.setCuePropertyWithArgs(uniqueID, "scale", float32(x), float32(y)); err != nil {
-				log.Warnf("Failed to set scale for fade cue %s: %v", uniqueID, err)
-			}
+	case CueTypeStart, CueTypeStop, CueTypePause, CueTypeReset, CueTypeGoto, CueTypeTarget, CueTypeLoad, CueTypeArm, CueTypeDisarm:
+		// Skip cue target setting - this will be handled in the second pass
+	case CueTypeDevamp:
+		// The target is set in the second pass
+		if err := q.setMappedCueProperties(uniqueID, cueData, devampCueProperties, false); err != nil {
+			return "", err
 		}
-		if rotation, ok := cueData["rotation"].(float64); ok && rotation != 0 {
-			if err := q.setCueProperty(uniqueID, "rotation", fmt.Sprintf("%g", rotation)); err != nil {
-				log.Warnf("Failed to set rotation for fade cue %s: %v", uniqueID, err)
-			}
+	case CueTypeWait:
+		if err := q.setWaitCueProperties(uniqueID, cueData, false); err != nil {
+			return "", err
 		}
-	case "list", "cart":
-		// List and Cart cues have read-only mode properties, skip mode setting
-	case "start", "stop":
-		// Skip cue target setting - this will be handled in the second pass
+	case CueTypeMemo:
+		// Notes and color are set above, as for every cue
+	}
+
+	// Follows and triggers apply to every cue type
+	if err := q.setFollowProperties(uniqueID, cueData, false); err != nil {
+		return "", err
+	}
+	if err := q.setTriggerProperties(uniqueID, cueData, false); err != nil {
+		return "", err
+	}
+	if err := q.stampExternalID(uniqueID, cueData, true); err != nil {
+		return "", fmt.Errorf("failed to stamp external ID: %w", err)
 	}
 
 	return uniqueID, nil
@@ -774,7 +770,12 @@ func (q *Workspace) updateCueProperties(uniqueID string, cueData map[string]any)
 	cueType, _ := cueData["type"].(string)
 	cueName, _ := cueData["name"].(string)
 
-	log.Debug("Updating cue properties", "uniqueID", uniqueID, "type", cueType, "name", cueName)
+	q.log().Debug("Updating cue properties", "uniqueID", uniqueID, "type", cueType, "name", cueName)
+
+	cueData, err := q.resolvePatchNames(cueData)
+	if err != nil {
+		return err
+	}
 
 	// Set cue properties that may have changed
 	if cueName != "" {
@@ -801,7 +802,7 @@ func (q *Workspace) updateCueProperties(uniqueID string, cueData map[string]any)
 	case "text":
 		if text, ok := cueData["text"].(string); ok && text != "" {
 			if err := q.setCueProperty(uniqueID, "text", text); err != nil {
-				return fmt.Errorf("failed to update text: %v", err)
+				return fmt.Errorf("failed to update text: %w", err)
 			}
 		}
 		// Set text format color (text/format/color) - requires 4 separate numeric arguments as float32
@@ -861,74 +862,81 @@ func (q *Workspace) updateCueProperties(uniqueID string, cueData map[string]any)
 			}
 		}
 	case "audio":
+		if err := q.setAudioOutputPatch(uniqueID, cueData); err != nil {
+			return err
+		}
+		if err := q.setAudioLevels(uniqueID, cueData); err != nil {
+			return fmt.Errorf("failed to update audio levels: %w", err)
+		}
 		if infiniteLoop, ok := cueData["infiniteLoop"].(bool); ok && infiniteLoop {
 			if err := q.setCueProperty(uniqueID, "infiniteLoop", "1"); err != nil {
 				return fmt.Errorf("failed to update infinite loop: %v", err)
 			}
 		}
 	case "group":
-		if mode, ok := cueData["mode"].(float64); ok {
-			if err := q.setCueProperty(uniqueID, "mode", fmt.Sprintf("%.0f", mode)); err != nil {
-				return fmt.Errorf("failed to update group mode: %v", err)
-			}
+		if err := q.setGroupMode(uniqueID, cueData); err != nil {
+			return fmt.Errorf("failed to update group mode: %w", err)
 		}
 	case "fade":
-		// Set fade geometry parameter enables
-		if doOpacity, ok := cueData["doOpacity"].(bool); ok && doOpacity {
-			if err := q.setCueProperty(uniqueID, "doOpacity", "1"); err != nil {
-				log.Warnf("Failed to set doOpacity for fade cue %s: %v", uniqueID, err)
-			}
+		if err := q.setFadeCueProperties(uniqueID, cueData, true); err != nil {
+			return fmt.Errorf("failed to update fade cue: %w", err)
 		}
-		if doTranslation, ok := cueData["doTranslation"].(bool); ok && doTranslation {
-			if err := q.setCueProperty(uniqueID, "doTranslation", "1"); err != nil {
-				log.Warnf("Failed to set doTranslation for fade cue %s: %v", uniqueID, err)
-			}
+	case CueTypeVideo:
+		if err := q.setVideoCueProperties(uniqueID, cueData, true); err != nil {
+			return fmt.Errorf("failed to update video cue: %w", err)
 		}
-		if doScale, ok := cueData["doScale"].(bool); ok && doScale {
-			if err := q.setCueProperty(uniqueID, "doScale", "1"); err != nil {
-				log.Warnf("Failed to set doScale for fade cue %s: %v", uniqueID, err)
-			}
+	case CueTypeMIDI, CueTypeNetwork, CueTypeOSC, CueTypeMIDIFile, CueTypeScript:
+		if err := q.setMappedCueProperties(uniqueID, cueData, mappedCueProperties(cueType), true); err != nil {
+			return fmt.Errorf("failed to update %s cue: %w", cueType, err)
 		}
-		if doRotation, ok := cueData["doRotation"].(bool); ok && doRotation {
-			if err := q.setCueProperty(uniqueID, "doRotation", "1"); err != nil {
-				log.Warnf("Failed to set doRotation for fade cue %s: %v", uniqueID, err)
-			}
+	case CueTypeLight:
+		if err := q.setLightCueProperties(uniqueID, cueData, true); err != nil {
+			return fmt.Errorf("failed to update light cue: %w", err)
 		}
-		// Set geometry properties for fade cues
-		if opacity, ok := cueData["opacity"].(float64); ok && opacity > 0 {
-			if err := q.setCueProperty(uniqueID, "opacity", fmt.Sprintf("%g", opacity)); err != nil {
-				return fmt.Errorf("failed to update opacity: %v", err)
-			}
+	case "list":
+		// List cues have read-only mode properties, skip mode setting
+	case "cart":
+		// Cart mode is read-only, but the grid must be resized before children are moved in
+		if err := q.setMappedCueProperties(uniqueID, cueData, cartCueProperties, true); err != nil {
+			return fmt.Errorf("failed to update cart grid: %w", err)
 		}
-		if translation, ok := cueData["translation"].([]any); ok && len(translation) == 2 {
-			x, _ := translation[0].(float64)
-			y, _ := translation[1].(float64)
-			if err := q.setCuePropertyWithArgs(uniqueID, "translation", float32(x), float32(y)); err != nil {
-				return fmt.Errorf("failed to update translation: %v", err)
-			}
+	case CueTypeStart, CueTypeStop, CueTypePause, CueTypeReset, CueTypeGoto, CueTypeTarget, CueTypeLoad, CueTypeArm, CueTypeDisarm:
+		// Targets are set below, for every cue type
+	case CueTypeDevamp:
+		if err := q.setMappedCueProperties(uniqueID, cueData, devampCueProperties, true); err != nil {
+			return fmt.Errorf("failed to update devamp cue: %w", err)
 		}
-		if scale, ok := cueData["scale"].([]any); ok && len(scale) == 2 {
-			x, _ := scale[0].(float64)
-			y, _ := scale[1].(float64)
-			if err := q.setCuePropertyWithArgs(uniqueID, "scale", float32(x), float32(y)); err != nil {
-				return fmt.Errorf("failed to update scale: %v", err)
-			}
+	case CueTypeWait:
+		if err := q.setWaitCueProperties(uniqueID, cueData, true); err != nil {
+			return fmt.Errorf("failed to update wait cue: %w", err)
 		}
-		if rotation, ok := cueData["rotation"].(float64); ok && rotation != 0 {
-			if err := q.setCueProperty(uniqueID, "rotation", fmt.Sprintf("%g", rotation)); err != nil {
-				return fmt.Errorf("failed to update rotation: %v", err)
-			}
+	case CueTypeMemo:
+		if err := q.setMemoCueProperties(uniqueID, cueData, true); err != nil {
+			return fmt.Errorf("failed to update memo cue: %w", err)
 		}
-	case "list", "cart":
-		// List and Cart cues have read-only mode properties, skip mode setting
-	case "start", "stop":
-		// Skip cue target setting - this will be handled elsewhere if needed
 	}
 
-	// Handle cueTargetNumber if present
+	// Follows and triggers apply to every cue type
+	if err := q.setFollowProperties(uniqueID, cueData, true); err != nil {
+		return fmt.Errorf("failed to update follow: %w", err)
+	}
+	if err := q.setTriggerProperties(uniqueID, cueData, true); err != nil {
+		return fmt.Errorf("failed to update triggers: %w", err)
+	}
+	if err := q.setCueColor(uniqueID, cueData, false, true); err != nil {
+		return err
+	}
+	if err := q.setOperationalState(uniqueID, cueData); err != nil {
+		return fmt.Errorf("failed to update operational state: %w", err)
+	}
+	if err := q.stampExternalID(uniqueID, cueData, false); err != nil {
+		return fmt.Errorf("failed to update external ID: %w", err)
+	}
+
+	// Handle cueTargetNumber if present, falling back to cueTargetID
 	if cueTargetNumber, ok := cueData["cueTargetNumber"].(string); ok && cueTargetNumber != "" {
-		if err := q.setCueProperty(uniqueID, "cueTargetNumber", cueTargetNumber); err != nil {
-			return fmt.Errorf("failed to update cue target number: %v", err)
+		if err := q.setCueTarget(uniqueID, cueData, true); err != nil {
+			return fmt.Errorf("failed to update cue target number: %w", err)
 		}
 	}
 
@@ -946,7 +954,7 @@ func (q *Workspace) setCueProperty(uniqueID, property, value string) error {
 		if err := q.handleCueNumberConflict(uniqueID, value); err != nil {
 			// If it's a conflict error and we're not forcing, skip setting the property
 			if _, isConflict := err.(*CueNumberConflictError); isConflict {
-				log.Infof("Skipping cue number assignment due to conflict")
+				q.log().Infof("Skipping cue number assignment due to conflict")
 				return err
 			}
 			return err
@@ -954,34 +962,47 @@ func (q *Workspace) setCueProperty(uniqueID, property, value string) error {
 	}
 
 	address := q.addressBuilder.BuildCuePropertyAddress(uniqueID, property)
-	log.Debug("Setting cue property - sending OSC", "address", address, "value", value)
+	if err := q.checkPayloadSize(address, osc.NewMessage(address, value)); err != nil {
+		return err
+	}
+
+	// Queue the property set when batching; failures surface when the batch is flushed
+	if q.batch != nil {
+		q.queuePropertySet(uniqueID, property, value)
+		if property == "number" && value != "" {
+			q.recordCueNumber(value, uniqueID)
+		}
+		return nil
+	}
+
+	q.log().Debug("Setting cue property - sending OSC", "address", address, "value", value)
 	reply := q.Send(address, value)
 
 	// Check for error in reply
 	if len(reply) > 0 {
 		if replyStr, ok := reply[0].(string); ok {
-			log.Debug("Received OSC reply for property setting", "reply", replyStr)
+			q.log().Debug("Received OSC reply for property setting", "reply", replyStr)
 			var replyData map[string]any
 			if err := json.Unmarshal([]byte(replyStr), &replyData); err == nil {
 				if status, ok := replyData["status"].(string); ok && status == "error" {
-					log.Debug("ERROR - QLab returned error status for property setting")
-					return formatErrorWithJSON(fmt.Sprintf("failed to set %s=%s for cue %s", property, value, uniqueID), replyStr)
+					q.log().Debug("ERROR - QLab returned error status for property setting")
+					return newQLabError(fmt.Sprintf("failed to set %s=%s for cue %s", property, value, uniqueID), replyStr)
 				}
 			}
 		}
 	} else {
-		log.Debug("WARNING - No reply received for property setting", "property", property, "value", value)
+		q.log().Debug("WARNING - No reply received for property setting", "property", property, "value", value)
 	}
 
 	// Update tracking for cue numbers
 	if property == "number" {
 		if value != "" {
-			q.cueNumbers[value] = uniqueID
-			log.Debug("Tracked new cue number", "cue_number", value, "id", uniqueID)
+			q.recordCueNumber(value, uniqueID)
+			q.log().Debug("Tracked new cue number", "cue_number", value, "id", uniqueID)
 		}
 	}
 
-	log.Debug("Set cue property", "property", property, "value", value, "cue_id", uniqueID)
+	q.log().Debug("Set cue property", "property", property, "value", value, "cue_id", uniqueID)
 	return nil
 }
 
@@ -992,26 +1013,35 @@ func (q *Workspace) setCuePropertyWithArgs(uniqueID, property string, args ...an
 	}
 
 	address := q.addressBuilder.BuildCuePropertyAddress(uniqueID, property)
-	log.Debug("Setting cue property with args - sending OSC", "address", address, "args", args)
+	if err := q.checkPayloadSize(address, osc.NewMessage(address, args...)); err != nil {
+		return err
+	}
+
+	if q.batch != nil {
+		q.queuePropertySet(uniqueID, property, args...)
+		return nil
+	}
+
+	q.log().Debug("Setting cue property with args - sending OSC", "address", address, "args", args)
 	reply := q.SendWithArgs(address, args...)
 
 	// Check for error in reply
 	if len(reply) > 0 {
 		if replyStr, ok := reply[0].(string); ok {
-			log.Debug("Received OSC reply for property setting", "reply", replyStr)
+			q.log().Debug("Received OSC reply for property setting", "reply", replyStr)
 			var replyData map[string]any
 			if err := json.Unmarshal([]byte(replyStr), &replyData); err == nil {
 				if status, ok := replyData["status"].(string); ok && status == "error" {
-					log.Debug("ERROR - QLab returned error status for property setting")
-					return formatErrorWithJSON(fmt.Sprintf("failed to set %s for cue %s", property, uniqueID), replyStr)
+					q.log().Debug("ERROR - QLab returned error status for property setting")
+					return newQLabError(fmt.Sprintf("failed to set %s for cue %s", property, uniqueID), replyStr)
 				}
 			}
 		}
 	} else {
-		log.Debug("WARNING - No reply received for property setting", "property", property, "args", args)
+		q.log().Debug("WARNING - No reply received for property setting", "property", property, "args", args)
 	}
 
-	log.Debug("Set cue property with args", "property", property, "args", args, "cue_id", uniqueID)
+	q.log().Debug("Set cue property with args", "property", property, "args", args, "cue_id", uniqueID)
 	return nil
 }
 
@@ -1025,7 +1055,7 @@ func (q *Workspace) moveCueToParent(cueID, parentCueID string) error {
 	address := fmt.Sprintf("/workspace/%s/move/%s", q.workspace_id, cueID)
 
 	// Use index 0 to place the cue at the beginning of the parent group
-	log.Debug("Moving cue into parent at index 0", "cue_id", cueID, "parent_id", parentCueID)
+	q.log().Debug("Moving cue into parent at index 0", "cue_id", cueID, "parent_id", parentCueID)
 	reply := q.SendWithArgs(address, int32(0), parentCueID)
 
 	// Check for error in reply
@@ -1034,13 +1064,13 @@ func (q *Workspace) moveCueToParent(cueID, parentCueID string) error {
 			var replyData map[string]any
 			if err := json.Unmarshal([]byte(replyStr), &replyData); err == nil {
 				if status, ok := replyData["status"].(string); ok && status == "error" {
-					return formatErrorWithJSON(fmt.Sprintf("failed to move cue %s into parent %s", cueID, parentCueID), replyStr)
+					return newQLabError(fmt.Sprintf("failed to move cue %s into parent %s", cueID, parentCueID), replyStr)
 				}
 			}
 		}
 	}
 
-	log.Infof("Successfully moved cue %s into parent %s", cueID, parentCueID)
+	q.log().Infof("Successfully moved cue %s into parent %s", cueID, parentCueID)
 	return nil
 }
 
@@ -1053,7 +1083,7 @@ func (q *Workspace) moveCueToParentWithIndex(cueID, parentCueID string, index in
 	// Build the move address: /workspace/{id}/move/{cue_id} {new_index} {new_parent_cue_id}
 	address := fmt.Sprintf("/workspace/%s/move/%s", q.workspace_id, cueID)
 
-	log.Debug("Moving cue into parent at index", "cue_id", cueID, "parent_id", parentCueID, "index", index)
+	q.log().Debug("Moving cue into parent at index", "cue_id", cueID, "parent_id", parentCueID, "index", index)
 	reply := q.SendWithArgs(address, int32(index), parentCueID)
 
 	// Check for error in reply
@@ -1062,13 +1092,13 @@ func (q *Workspace) moveCueToParentWithIndex(cueID, parentCueID string, index in
 			var replyData map[string]any
 			if err := json.Unmarshal([]byte(replyStr), &replyData); err == nil {
 				if status, ok := replyData["status"].(string); ok && status == "error" {
-					return formatErrorWithJSON(fmt.Sprintf("failed to move cue %s into parent %s at index %d", cueID, parentCueID, index), replyStr)
+					return newQLabError(fmt.Sprintf("failed to move cue %s into parent %s at index %d", cueID, parentCueID, index), replyStr)
 				}
 			}
 		}
 	}
 
-	log.Infof("Successfully moved cue %s into parent %s at index %d", cueID, parentCueID, index)
+	q.log().Infof("Successfully moved cue %s into parent %s at index %d", cueID, parentCueID, index)
 	return nil
 }
 
@@ -1081,7 +1111,7 @@ func (q *Workspace) getCueChildren(cueID string) ([]map[string]any, error) {
 	// Build the children query address: /workspace/{id}/cue_id/{cue_id}/children
 	address := fmt.Sprintf("/workspace/%s/cue_id/%s/children", q.workspace_id, cueID)
 
-	log.Debug("Querying children for cue", "cue_id", cueID)
+	q.log().Debug("Querying children for cue", "cue_id", cueID)
 	reply := q.Send(address, "")
 
 	if len(reply) == 0 {
@@ -1102,7 +1132,7 @@ func (q *Workspace) getCueChildren(cueID string) ([]map[string]any, error) {
 
 	// Check for error status
 	if status, ok := replyData["status"].(string); ok && status == "error" {
-		return nil, formatErrorWithJSON("QLab error querying children", replyStr)
+		return nil, newQLabError("QLab error querying children", replyStr)
 	}
 
 	// Extract the children data
@@ -1119,7 +1149,7 @@ func (q *Workspace) getCueChildren(cueID string) ([]map[string]any, error) {
 		}
 	}
 
-	log.Debug("Found children for cue", "child_count", len(children), "cue_id", cueID)
+	q.log().Debug("Found children for cue", "child_count", len(children), "cue_id", cueID)
 	return children, nil
 }
 
@@ -1128,11 +1158,14 @@ func (q *Workspace) getAllCueIDs() ([]string, error) {
 	if q.workspace_id == "" {
 		return nil, fmt.Errorf("workspace ID is required for cue queries but not available")
 	}
+	if !q.Supports(FeatureCueListUniqueIDs) {
+		return q.cueIDsFromCueLists()
+	}
 
 	// Build the cueLists query address: /workspace/{id}/cueLists/uniqueIDs
 	address := fmt.Sprintf("/workspace/%s/cueLists/uniqueIDs", q.workspace_id)
 
-	log.Debug("Querying all cue IDs in workspace", "workspace_id", q.workspace_id)
+	q.log().Debug("Querying all cue IDs in workspace", "workspace_id", q.workspace_id)
 	reply := q.Send(address, "")
 
 	if len(reply) == 0 {
@@ -1153,7 +1186,7 @@ func (q *Workspace) getAllCueIDs() ([]string, error) {
 
 	// Check for error status
 	if status, ok := replyData["status"].(string); ok && status == "error" {
-		return nil, formatErrorWithJSON("QLab error querying all cue IDs", replyStr)
+		return nil, newQLabError("QLab error querying all cue IDs", replyStr)
 	}
 
 	// Extract the data
@@ -1177,7 +1210,7 @@ func (q *Workspace) getAllCueIDs() ([]string, error) {
 		}
 	}
 
-	log.Infof("Found %d total cues in workspace", len(allIDs))
+	q.log().Infof("Found %d total cues in workspace", len(allIDs))
 	return allIDs, nil
 }
 
@@ -1213,13 +1246,13 @@ func (q *Workspace) getWorkspaceBasePath() (string, error) {
 	// Try workspace-specific basePath first
 	basePath, err := q.queryWorkspaceBasePath()
 	if err != nil {
-		log.Debug("Failed to get workspace basePath, trying workingDirectory fallback", "error", err)
+		q.log().Debug("Failed to get workspace basePath, trying workingDirectory fallback", "error", err)
 	} else if basePath != "" {
 		return basePath, nil
 	}
 
 	// Fallback to /workingDirectory if basePath is empty or failed
-	log.Debugf("BasePath empty or unavailable, falling back to /workingDirectory")
+	q.log().Debugf("BasePath empty or unavailable, falling back to /workingDirectory")
 	workingDir, err := q.queryWorkingDirectory()
 	if err != nil {
 		return "", fmt.Errorf("failed to get workingDirectory fallback: %v", err)
@@ -1233,7 +1266,7 @@ func (q *Workspace) queryWorkspaceBasePath() (string, error) {
 	// Build the basePath query address: /workspace/{id}/basePath
 	address := fmt.Sprintf("/workspace/%s/basePath", q.workspace_id)
 
-	log.Debug("Querying basePath for workspace", "workspace_id", q.workspace_id)
+	q.log().Debug("Querying basePath for workspace", "workspace_id", q.workspace_id)
 	reply := q.Send(address, "")
 
 	if len(reply) == 0 {
@@ -1258,7 +1291,7 @@ func (q *Workspace) queryWorkspaceBasePath() (string, error) {
 
 	// Extract the basePath from the data field
 	if data, ok := replyData["data"].(string); ok {
-		log.Debug("Workspace basePath retrieved", "base_path", data)
+		q.log().Debug("Workspace basePath retrieved", "base_path", data)
 		return data, nil
 	}
 
@@ -1269,7 +1302,7 @@ func (q *Workspace) queryWorkspaceBasePath() (string, error) {
 func (q *Workspace) queryWorkingDirectory() (string, error) {
 	address := "/workingDirectory"
 
-	log.Debug("Querying /workingDirectory as fallback")
+	q.log().Debug("Querying /workingDirectory as fallback")
 	reply := q.Send(address, "")
 
 	if len(reply) == 0 {
@@ -1294,7 +1327,7 @@ func (q *Workspace) queryWorkingDirectory() (string, error) {
 
 	// Extract the working directory from the data field
 	if data, ok := replyData["data"].(string); ok {
-		log.Debug("Working directory retrieved", "working_directory", data)
+		q.log().Debug("Working directory retrieved", "working_directory", data)
 		return data, nil
 	}
 
@@ -1311,7 +1344,7 @@ func (q *Workspace) resolveFilePath(filePath string) (string, error) {
 	// First try to resolve relative to CUE file directory (if available)
 	if q.cueFileDirectory != "" {
 		absolutePath := filepath.Join(q.cueFileDirectory, filePath)
-		log.Debug("Resolved relative path to absolute path (via CUE file directory)", "relative_path", filePath, "absolute_path", absolutePath)
+		q.log().Debug("Resolved relative path to absolute path (via CUE file directory)", "relative_path", filePath, "absolute_path", absolutePath)
 		return absolutePath, nil
 	}
 
@@ -1323,7 +1356,7 @@ func (q *Workspace) resolveFilePath(filePath string) (string, error) {
 
 	// Join the base path with the relative file path
 	absolutePath := filepath.Join(basePath, filePath)
-	log.Debug("Resolved relative path to absolute path (via workspace basePath)", "relative_path", filePath, "absolute_path", absolutePath)
+	q.log().Debug("Resolved relative path to absolute path (via workspace basePath)", "relative_path", filePath, "absolute_path", absolutePath)
 
 	return absolutePath, nil
 }
@@ -1337,7 +1370,7 @@ func (q *Workspace) deleteCue(cueID string) error {
 	// Build the delete address: /workspace/{id}/delete_id/{cue_id}
 	address := fmt.Sprintf("/workspace/%s/delete_id/%s", q.workspace_id, cueID)
 
-	log.Debug("Deleting cue", "cue_id", cueID)
+	q.log().Debug("Deleting cue", "cue_id", cueID)
 	reply := q.Send(address, "")
 
 	if len(reply) == 0 {
@@ -1358,31 +1391,34 @@ func (q *Workspace) deleteCue(cueID string) error {
 
 	// Check for error status
 	if status, ok := replyData["status"].(string); ok && status == "error" {
-		return formatErrorWithJSON("QLab error deleting cue", replyStr)
+		return newQLabError("QLab error deleting cue", replyStr)
 	}
 
-	log.Debug("Successfully deleted cue", "cue_id", cueID)
+	q.log().Debug("Successfully deleted cue", "cue_id", cueID)
 	return nil
 }
 
 // getCueLists queries QLab for all cue lists, using cached data if available
 func (q *Workspace) getCueLists() ([]any, error) {
 	// Return cached data if available
-	if q.cueListsCache != nil {
-		log.Debug("Using cached cue lists data")
-		return q.cueListsCache, nil
+	q.stateMu.Lock()
+	cached := q.cueListsCache
+	q.stateMu.Unlock()
+	if cached != nil {
+		q.log().Debug("Using cached cue lists data")
+		return cached, nil
 	}
 
 	if q.workspace_id == "" {
 		return nil, fmt.Errorf("workspace ID is required but not available")
 	}
 
-	log.Debug("Querying cue lists from QLab")
+	q.log().Debug("Querying cue lists from QLab")
 	address := fmt.Sprintf("/workspace/%s/cueLists", q.workspace_id)
 	reply := q.Send(address, "")
 
 	if len(reply) == 0 {
-		log.Debug("No reply received when querying cue lists")
+		q.log().Debug("No reply received when querying cue lists")
 		return nil, nil
 	}
 
@@ -1405,12 +1441,14 @@ func (q *Workspace) getCueLists() ([]any, error) {
 	// Extract the cue lists data
 	data, ok := replyData["data"].([]any)
 	if !ok {
-		log.Debug("No cue lists found in response")
+		q.log().Debug("No cue lists found in response")
 		return nil, nil
 	}
 
 	// Cache the result for subsequent calls
+	q.stateMu.Lock()
 	q.cueListsCache = data
+	q.stateMu.Unlock()
 	return data, nil
 }
 
@@ -1420,7 +1458,7 @@ func (q *Workspace) indexExistingCues() error {
 		return fmt.Errorf("workspace ID is required for cue indexing but not available")
 	}
 
-	log.Debug("Indexing existing cues for conflict detection")
+	q.log().Debug("Indexing existing cues for conflict detection")
 
 	// Use cached cue lists data
 	data, err := q.getCueLists()
@@ -1429,7 +1467,7 @@ func (q *Workspace) indexExistingCues() error {
 	}
 
 	if data == nil {
-		log.Debug("No cue lists found, workspace is empty")
+		q.log().Debug("No cue lists found, workspace is empty")
 		return nil
 	}
 
@@ -1445,7 +1483,7 @@ func (q *Workspace) indexExistingCues() error {
 		// Index this cue list by name
 		if name, hasName := cueList["name"].(string); hasName && name != "" {
 			if uniqueID, hasID := cueList["uniqueID"].(string); hasID {
-				q.cueListNames[name] = uniqueID
+				q.recordCueList(name, uniqueID)
 				totalCueLists++
 			}
 		}
@@ -1457,7 +1495,7 @@ func (q *Workspace) indexExistingCues() error {
 		}
 	}
 
-	log.Infof("Indexed %d existing cues with numbers and %d cue lists", totalCues, totalCueLists)
+	q.log().Infof("Indexed %d existing cues with numbers and %d cue lists", totalCues, totalCueLists)
 	return nil
 }
 
@@ -1475,7 +1513,7 @@ func (e *CueNumberConflictError) Error() string {
 // handleCueNumberConflict checks for conflicts and handles resolution based on force flag
 func (q *Workspace) handleCueNumberConflict(newCueID, cueNumber string) error {
 	// Check if this number is already in use
-	existingID, exists := q.cueNumbers[cueNumber]
+	existingID, exists := q.lookupCueNumber(cueNumber)
 	if !exists {
 		return nil // No conflict
 	}
@@ -1485,11 +1523,11 @@ func (q *Workspace) handleCueNumberConflict(newCueID, cueNumber string) error {
 		return nil
 	}
 
-	log.Warnf("Cue number conflict detected: '%s' is already assigned to cue %s", cueNumber, existingID)
+	q.log().Warnf("Cue number conflict detected: '%s' is already assigned to cue %s", cueNumber, existingID)
 
 	if q.forceCueNumbers {
 		// Force cue number by clearing the existing cue's number
-		log.Infof("Force mode enabled: clearing number from existing cue %s", existingID)
+		q.log().Infof("Force mode enabled: clearing number from existing cue %s", existingID)
 
 		err := q.clearCueNumber(existingID)
 		if err != nil {
@@ -1497,8 +1535,8 @@ func (q *Workspace) handleCueNumberConflict(newCueID, cueNumber string) error {
 		}
 
 		// Remove from tracking
-		delete(q.cueNumbers, cueNumber)
-		log.Infof("Cleared cue number '%s' from existing cue %s", cueNumber, existingID)
+		q.forgetCueNumber(cueNumber)
+		q.log().Infof("Cleared cue number '%s' from existing cue %s", cueNumber, existingID)
 		return nil
 	} else {
 		// Return special error type for conflicts when not forcing
@@ -1517,7 +1555,7 @@ func (q *Workspace) clearCueNumber(cueID string) error {
 	}
 
 	address := q.addressBuilder.BuildCuePropertyAddress(cueID, "number")
-	reply := q.Send(address, "") // Empty string clears the number
+	reply := q.SendWithArgs(address, "") // An empty string clears the number; Send would query it
 
 	// Check for error in reply
 	if len(reply) > 0 {
@@ -1525,18 +1563,28 @@ func (q *Workspace) clearCueNumber(cueID string) error {
 			var replyData map[string]any
 			if err := json.Unmarshal([]byte(replyStr), &replyData); err == nil {
 				if status, ok := replyData["status"].(string); ok && status == "error" {
-					return formatErrorWithJSON(fmt.Sprintf("failed to clear number for cue %s", cueID), replyStr)
+					return newQLabError(fmt.Sprintf("failed to clear number for cue %s", cueID), replyStr)
 				}
 			}
 		}
 	}
 
-	log.Debug("Cleared number for cue", "cue_id", cueID)
+	q.log().Debug("Cleared number for cue", "cue_id", cueID)
 	return nil
 }
 
 // indexCueNumbers recursively processes cues and indexes their numbers
 func (q *Workspace) indexCueNumbers(cues []any) int {
+	q.stateMu.Lock()
+	defer q.stateMu.Unlock()
+	if q.cueNumbers == nil {
+		q.cueNumbers = make(map[string]string)
+	}
+	return indexCueNumbersInto(cues, q.cueNumbers, q.log())
+}
+
+// indexCueNumbersInto recursively indexes cue numbers into the given map
+func indexCueNumbersInto(cues []any, index map[string]string, logger Logger) int {
 	count := 0
 	for _, cueData := range cues {
 		cue, ok := cueData.(map[string]any)
@@ -1573,15 +1621,15 @@ func (q *Workspace) indexCueNumbers(cues []any) int {
 				}
 			}
 			if cueNumber != "" {
-				q.cueNumbers[cueNumber] = uniqueID
+				index[cueNumber] = uniqueID
 				count++
-				log.Debug("Indexed cue number", "cue_number", cueNumber, "id", uniqueID)
+				logger.Debug("Indexed cue number", "cue_number", cueNumber, "id", uniqueID)
 			}
 		}
 
 		// Recursively process children if this is a group cue
 		if children, ok := cue["cues"].([]any); ok {
-			childCount := q.indexCueNumbers(children)
+			childCount := indexCueNumbersInto(children, index, logger)
 			count += childCount
 		}
 	}
@@ -1594,26 +1642,27 @@ func (q *Workspace) clearAllCues() error {
 	if err != nil {
 		// Check if this is the specific API error we expect to handle gracefully
 		if strings.Contains(err.Error(), "QLab error querying all cue IDs") {
-			log.Warnf("cueLists/uniqueIDs endpoint not available, cleanup will be limited: %v", err)
+			q.log().Warnf("cueLists/uniqueIDs endpoint not available, cleanup will be limited: %v", err)
 			return nil // Don't fail the test for this known API limitation
 		}
 		return fmt.Errorf("failed to get cue IDs for cleanup: %v", err)
 	}
 
 	if len(cueIDs) == 0 {
-		log.Info("No cues to clean up")
+		q.log().Info("No cues to clean up")
 		return nil
 	}
 
-	log.Infof("Cleaning up %d cues from workspace", len(cueIDs))
+	q.log().Infof("Cleaning up %d cues from workspace", len(cueIDs))
 
-	// Delete each cue - track if any deletions failed
+	// Delete each cue - track if any deletions failed. Children come after their group, so
+	// deleting in reverse removes them before the group takes them with it.
 	var deletionErrors []string
-	for _, cueID := range cueIDs {
+	for _, cueID := range slices.Backward(cueIDs) {
 		err := q.deleteCue(cueID)
 		if err != nil {
 			deletionErrors = append(deletionErrors, fmt.Sprintf("cue %s: %v", cueID, err))
-			log.Warnf("Failed to delete cue %s: %v", cueID, err)
+			q.log().Warnf("Failed to delete cue %s: %v", cueID, err)
 		}
 	}
 
@@ -1622,17 +1671,18 @@ func (q *Workspace) clearAllCues() error {
 		return fmt.Errorf("failed to delete %d cues: %s", len(deletionErrors), strings.Join(deletionErrors, "; "))
 	}
 
-	log.Info("Workspace cleanup completed")
+	q.log().Info("Workspace cleanup completed")
 	return nil
 }
 
-// ensureCuejitsuInbox detects or creates a "Cuejitsu Inbox" cue list for staging imported cues
+// ensureCuejitsuInbox detects or creates the staging cue list for imported cues, named
+// "Cuejitsu Inbox" unless SetInboxName gives another name
 func (q *Workspace) ensureCuejitsuInbox() (string, error) {
 	if q.workspace_id == "" {
 		return "", fmt.Errorf("workspace ID is required for inbox management but not available")
 	}
 
-	log.Debug("Ensuring Cuejitsu Inbox cue list exists")
+	q.log().Debug("Ensuring Cuejitsu Inbox cue list exists")
 
 	// First, try to find existing "Cuejitsu Inbox" cue list
 	inboxID, err := q.findCuejitsuInbox()
@@ -1642,24 +1692,25 @@ func (q *Workspace) ensureCuejitsuInbox() (string, error) {
 
 	// If found, store and return its ID
 	if inboxID != "" {
-		log.Infof("Found existing Cuejitsu Inbox cue list: %s", inboxID)
-		q.inboxID = inboxID
+		q.log().Infof("Found existing Cuejitsu Inbox cue list: %s", inboxID)
+		q.setInboxID(inboxID)
 		return inboxID, nil
 	}
 
 	// If not found, create it
-	log.Info("Cuejitsu Inbox not found, creating new cue list")
+	q.log().Info("Cuejitsu Inbox not found, creating new cue list")
 	inboxID, err = q.createCuejitsuInbox()
 	if err != nil {
 		return "", fmt.Errorf("error creating Cuejitsu Inbox: %v", err)
 	}
 
-	log.Infof("Created Cuejitsu Inbox cue list: %s", inboxID)
-	q.inboxID = inboxID
+	q.log().Infof("Created Cuejitsu Inbox cue list: %s", inboxID)
+	q.recordCueList(q.inboxListName(), inboxID)
+	q.setInboxID(inboxID)
 	return inboxID, nil
 }
 
-// findCuejitsuInbox searches for an existing "Cuejitsu Inbox" cue list
+// findCuejitsuInbox searches for an existing staging cue list
 func (q *Workspace) findCuejitsuInbox() (string, error) {
 	// Use cached cue lists data
 	data, err := q.getCueLists()
@@ -1678,8 +1729,8 @@ func (q *Workspace) findCuejitsuInbox() (string, error) {
 			continue
 		}
 
-		// Check if this cue list is named "Cuejitsu Inbox"
-		if name, ok := cueList["name"].(string); ok && name == "Cuejitsu Inbox" {
+		// Check if this cue list has the staging list's name
+		if name, ok := cueList["name"].(string); ok && name == q.inboxListName() {
 			if uniqueID, ok := cueList["uniqueID"].(string); ok {
 				return uniqueID, nil
 			}
@@ -1698,29 +1749,30 @@ func (q *Workspace) IdentifyConflicts(comparison *ThreeWayComparison) ([]CueConf
 	// Handle case where QLab query failed
 	if !comparison.HasQLabData {
 		if comparison.HasCache {
-			log.Warn("QLab data unavailable - using cache-only comparison")
-			log.Info("Conflicts cannot be detected without current QLab state")
-			log.Info("Recommendation: Increase timeout or check QLab connection")
+			q.log().Warn("QLab data unavailable - using cache-only comparison")
+			q.log().Info("Conflicts cannot be detected without current QLab state")
+			q.log().Info("Recommendation: Increase timeout or check QLab connection")
 		}
 		return conflicts, nil
 	}
 
 	// Only identify conflicts if we have cache (need common ancestor)
 	if !comparison.HasCache {
-		log.Debug("No cache available - three-way conflict detection unavailable")
+		q.log().Debug("No cache available - three-way conflict detection unavailable")
 		return conflicts, nil
 	}
 
 	// If cache matches QLab, then only simple source vs cache conflicts are possible
 	// These are typically handled automatically, so we don't need user input
 	if comparison.CacheMatchesQLab {
-		log.Debug("Cache matches QLab state, no complex conflicts detected")
+		q.log().Debug("Cache matches QLab state, no complex conflicts detected")
 		return conflicts, nil
 	}
 
 	// Use scope-based conflict identification if available
 	if comparison.WorkspaceScope != nil {
-		return q.identifyConflictsFromScope(comparison.WorkspaceScope), nil
+		conflicts = q.identifyConflictsFromScope(comparison.WorkspaceScope)
+		return append(conflicts, deletionConflicts(comparison)...), nil
 	}
 
 	// Fallback to legacy cue-level conflict detection
@@ -1743,21 +1795,27 @@ func (q *Workspace) IdentifyConflicts(comparison *ThreeWayComparison) ([]CueConf
 				description = fmt.Sprintf("Cue %s has been modified in both the source file and QLab since last sync", cueNumber)
 			}
 
+			// Without a scope comparison the field values come from the modified fields
+			fieldConflicts := result.FieldConflicts
+			if len(fieldConflicts) == 0 {
+				fieldConflicts = fieldConflictsFromModified(result, conflictType == ConflictCacheStale)
+			}
+
 			conflict := CueConflict{
 				CueNumber:      cueNumber,
 				CueIdentifier:  cueNumber,
 				ConflictType:   conflictType,
 				Scope:          ScopeCue,
 				Description:    description,
-				FieldConflicts: result.FieldConflicts,
+				FieldConflicts: fieldConflicts,
 				Resolved:       false,
 			}
 			conflicts = append(conflicts, conflict)
-			log.Debug("Identified conflict for cue", "cue_number", cueNumber, "type", conflictType)
+			q.log().Debug("Identified conflict for cue", "cue_number", cueNumber, "type", conflictType)
 		}
 	}
 
-	return conflicts, nil
+	return append(conflicts, deletionConflicts(comparison)...), nil
 }
 
 // identifyConflictsFromScope recursively identifies conflicts from scope comparison
@@ -1825,10 +1883,12 @@ func (q *Workspace) identifyConflictsFromScope(scope *ScopeComparison) []CueConf
 				FieldConflicts: fieldConflicts,
 				Description:    description,
 				Resolved:       false,
+				Ancestors:      scope.Ancestors,
+				CueList:        scope.CueList,
 			}
 
 			conflicts = append(conflicts, conflict)
-			log.Debugf("Identified %s-level conflict: %s (%d fields)", scope.Scope, scope.Identifier, len(properties))
+			q.log().Debugf("Identified %s-level conflict: %s (%d fields)", scope.Scope, scope.Identifier, len(properties))
 		}
 	}
 
@@ -1841,61 +1901,130 @@ func (q *Workspace) identifyConflictsFromScope(scope *ScopeComparison) []CueConf
 	return conflicts
 }
 
-// PromptUserForConflictResolution uses huh to prompt the user for conflict resolution choices
+// PromptUserForConflictResolution uses huh to prompt the user for conflict resolution choices.
+// After each choice the user can apply it to the rest of the cue's group, its cue list, or every
+// remaining conflict; conflicts covered that way are not prompted for again. Conflicts the user
+// chooses to resolve per field are then prompted for field by field.
 func (q *Workspace) PromptUserForConflictResolution(conflicts []CueConflict, comparison *ThreeWayComparison) error {
 	if len(conflicts) == 0 {
 		return nil
 	}
+	return applyResolver(PromptResolver{Logger: q.log(), Presenter: q.conflictPresenter}, conflicts, comparison)
+}
 
-	log.Infof("Found %d conflicts that require your attention", len(conflicts))
+// promptForResolutions prompts for a choice for each conflict not already covered by an
+// earlier answer's scope, showing the conflict as the presenter renders it
+func promptForResolutions(conflicts []CueConflict, logger Logger, presenter ConflictPresenter) (map[string]ConflictResolutionChoice, error) {
+	logger.Infof("Found %d conflicts that require your attention", len(conflicts))
 
+	resolutions := make(map[string]ConflictResolutionChoice, len(conflicts))
 	for i, conflict := range conflicts {
-		log.Infof("Conflict %d/%d: %s", i+1, len(conflicts), conflict.Description)
-
-		var choice string
-		form := huh.NewForm(
-			huh.NewGroup(
-				huh.NewSelect[string]().
-					Title(fmt.Sprintf("How would you like to resolve the conflict for cue %s?", conflict.CueNumber)).
-					Description(conflict.Description).
-					Options(
-						huh.NewOption("Use source file version (overwrite QLab)", "source"),
-						huh.NewOption("Keep QLab version (overwrite source)", "qlab"),
-						huh.NewOption("Skip this cue (no changes)", "skip"),
-					).
-					Value(&choice),
-			),
-		)
-
-		err := form.Run()
-		if err != nil {
-			return fmt.Errorf("failed to get user input for conflict resolution: %v", err)
+		if _, resolved := resolutions[conflict.CueNumber]; resolved {
+			continue
+		}
+		logger.Infof("Conflict %d/%d: %s", i+1, len(conflicts), conflict.Description)
+
+		var choice ConflictResolutionChoice
+		scope := ResolveThisCue
+		options := []huh.Option[ConflictResolutionChoice]{
+			huh.NewOption("Use source file version (overwrite QLab)", ChoiceUseSource),
+			huh.NewOption("Keep QLab version (overwrite source)", ChoiceKeepQLab),
+			huh.NewOption("Skip this cue (no changes)", ChoiceSkip),
+		}
+		if len(conflict.Fields()) > 1 {
+			options = append(options, huh.NewOption("Choose for each field", ChoicePerField))
+		}
+		fields := []huh.Field{
+			huh.NewSelect[ConflictResolutionChoice]().
+				Title(fmt.Sprintf("How would you like to resolve the conflict for cue %s?", conflict.CueNumber)).
+				Description(presenter.RenderConflict(NewConflictView(conflict))).
+				Options(options...).
+				Value(&choice),
+		}
+		if scopeOptions := resolutionScopeOptions(conflicts[i:], conflict); len(scopeOptions) > 1 {
+			fields = append(fields, huh.NewSelect[ResolutionScope]().
+				Title("Apply this choice to").
+				Options(scopeOptions...).
+				Value(&scope))
+		}
+
+		if err := huh.NewForm(huh.NewGroup(fields...)).Run(); err != nil {
+			return nil, fmt.Errorf("failed to get user input for conflict resolution: %v", err)
 		}
 
-		// Apply the user's choice by modifying the comparison results
-		if result, exists := comparison.CueResults[conflict.CueNumber]; exists {
-			switch choice {
-			case "source":
-				result.Action = "update"
-				result.Reason = "User chose to use source file version"
-				log.Infof("User chose to use source version for cue %s", conflict.CueNumber)
-			case "qlab":
-				result.Action = "skip"
-				result.Reason = "User chose to keep QLab version"
-				comparison.QLabChosenCues[conflict.CueNumber] = true
-				log.Infof("User chose to keep QLab version for cue %s", conflict.CueNumber)
-			case "skip":
-				result.Action = "skip"
-				result.Reason = "User chose to skip this cue"
-				log.Infof("User chose to skip cue %s", conflict.CueNumber)
-			default:
-				return fmt.Errorf("unexpected choice: %s", choice)
+		// Fields differ from cue to cue, so they're chosen one cue at a time
+		if choice == ChoicePerField {
+			scope = ResolveThisCue
+		}
+
+		// Only conflicts not yet answered are covered, so earlier answers stand
+		covered := 0
+		for _, cueNumber := range ConflictsInScope(conflicts[i:], conflict.CueNumber, scope) {
+			if _, resolved := resolutions[cueNumber]; !resolved {
+				resolutions[cueNumber] = choice
+				covered++
 			}
 		}
+		logger.Infof("User chose %s for %d conflict(s) starting at cue %s", choice, covered, conflict.CueNumber)
 	}
 
-	log.Info("All conflicts resolved by user")
-	return nil
+	logger.Info("All conflicts resolved by user")
+	return resolutions, nil
+}
+
+// promptForFieldResolutions prompts for source or QLab for each field of each conflict
+func promptForFieldResolutions(conflicts []CueConflict, logger Logger) (FieldResolutions, error) {
+	resolutions := make(FieldResolutions, len(conflicts))
+	for _, conflict := range conflicts {
+		names := conflict.Fields()
+		choices := make([]ConflictResolutionChoice, len(names))
+		fields := make([]huh.Field, len(names))
+		for i, name := range names {
+			choices[i] = ChoiceUseSource
+			source, qlab := "", ""
+			if change := conflict.FieldConflicts[name]; change != nil {
+				source, qlab = fmt.Sprintf(" (%v)", change.SourceValue), fmt.Sprintf(" (%v)", change.QLabValue)
+			}
+			fields[i] = huh.NewSelect[ConflictResolutionChoice]().
+				Title(fmt.Sprintf("Cue %s: %s", conflict.CueNumber, name)).
+				Options(
+					huh.NewOption("Take from source file"+source, ChoiceUseSource),
+					huh.NewOption("Keep QLab's"+qlab, ChoiceKeepQLab),
+				).
+				Value(&choices[i])
+		}
+
+		if err := huh.NewForm(huh.NewGroup(fields...)).Run(); err != nil {
+			return nil, fmt.Errorf("failed to get user input for conflict resolution: %v", err)
+		}
+		resolutions[conflict.CueNumber] = make(map[string]ConflictResolutionChoice, len(names))
+		for i, name := range names {
+			resolutions[conflict.CueNumber][name] = choices[i]
+		}
+		logger.Infof("User chose per field for cue %s: %v", conflict.CueNumber, resolutions[conflict.CueNumber])
+	}
+	return resolutions, nil
+}
+
+// resolutionScopeOptions returns the scopes worth offering for a conflict: a scope is only
+// listed when it covers more conflicts than the narrower ones before it
+func resolutionScopeOptions(remaining []CueConflict, conflict CueConflict) []huh.Option[ResolutionScope] {
+	options := []huh.Option[ResolutionScope]{huh.NewOption("Just this cue", ResolveThisCue)}
+	covered := 1
+
+	if n := len(ConflictsInScope(remaining, conflict.CueNumber, ResolveThisGroup)); n > covered {
+		group := conflictGroup(remaining, conflict)
+		options = append(options, huh.NewOption(fmt.Sprintf("Every conflict in group %s (%d)", group, n), ResolveThisGroup))
+		covered = n
+	}
+	if n := len(ConflictsInScope(remaining, conflict.CueNumber, ResolveThisCueList)); n > covered {
+		options = append(options, huh.NewOption(fmt.Sprintf("Every conflict in cue list %q (%d)", conflict.CueList, n), ResolveThisCueList))
+		covered = n
+	}
+	if n := len(remaining); n > covered {
+		options = append(options, huh.NewOption(fmt.Sprintf("All remaining conflicts (%d)", n), ResolveRemaining))
+	}
+	return options
 }
 
 // getMapKeys helper function to get keys from a map for logging
@@ -1909,9 +2038,9 @@ func getMapKeys(m map[string]map[string]any) []string {
 
 // processCueListWithMappingAndChangeDetection processes cues with change detection support
 func (q *Workspace) processCueListWithMappingAndChangeDetection(cueData map[string]any, parentNumber string, mapping *CueMapping, changeResults map[string]*CueChangeResult) error {
-	log.Debug("Wrapper function calling processCueListWithParentMappingAndChangeDetection")
+	q.log().Debug("Wrapper function calling processCueListWithParentMappingAndChangeDetection")
 	uniqueID, err := q.processCueListWithParentMappingAndChangeDetection(cueData, parentNumber, "", mapping, changeResults)
-	log.Debug("Wrapper function returned", "unique_id", uniqueID, "error", err)
+	q.log().Debug("Wrapper function returned", "unique_id", uniqueID, "error", err)
 	return err
 }
 
@@ -1930,70 +2059,40 @@ func (q *Workspace) processCueListWithParentMappingAndChangeDetectionWithIndex(c
 	for k := range cueData {
 		keys = append(keys, k)
 	}
-	log.Debug("Processing cue", "type", cueType, "name", cueName, "parent", parentNumber, "keys", keys)
+	q.log().Debug("Processing cue", "type", cueType, "name", cueName, "parent", parentNumber, "keys", keys)
 
 	// Check if this cue list already exists (for duplicate prevention)
 	var existingCueListID string
 	if cueType == "list" && cueName != "" {
-		log.Debug("Checking for existing cue list", "name", cueName)
-		if existingID, exists := q.cueListNames[cueName]; exists {
-			log.Debug("Found existing cue list, will use existing and process sub-cues", "name", cueName, "type", cueType, "id", existingID)
+		q.log().Debug("Checking for existing cue list", "name", cueName)
+		if existingID := q.lookupCueList(cueName); existingID != "" {
+			q.log().Debug("Found existing cue list, will use existing and process sub-cues", "name", cueName, "type", cueType, "id", existingID)
 			existingCueListID = existingID
 		} else {
-			log.Debug("Cue list does not exist yet, will create new one", "name", cueName)
+			q.log().Debug("Cue list does not exist yet, will create new one", "name", cueName)
 		}
 	}
 
-	log.Debug("Past duplicate check, extracting cue number")
+	q.log().Debug("Past duplicate check, extracting cue number")
 
-	var cueNumber string
-	if num, ok := cueData["number"]; ok && num != nil {
-		// Handle different number types while preserving decimal format
-		switch v := num.(type) {
-		case string:
-			cueNumber = v
-		case float64:
-			if v == float64(int64(v)) && v >= 0 && v <= 999 {
-				cueNumber = fmt.Sprintf("%.1f", v)
-			} else {
-				cueNumber = fmt.Sprintf("%g", v)
-			}
-		case int64:
-			cueNumber = fmt.Sprintf("%d", v)
-		case int:
-			cueNumber = fmt.Sprintf("%d", v)
-		default:
-			cueNumber = fmt.Sprintf("%v", v)
-		}
-	}
+	cueNumber := formatCueNumber(cueData["number"])
 
-	log.Debug("Extracted cue number from cue data", "cue_number", cueNumber)
+	q.log().Debug("Extracted cue number from cue data", "cue_number", cueNumber)
 
 	// Build full cue number with parent prefix
-	fullNumber := cueNumber
-	if parentNumber != "" && cueNumber != "" {
-		if strings.Contains(cueNumber, ".") {
-			fullNumber = cueNumber
-		} else {
-			fullNumber = parentNumber + "." + cueNumber
-		}
-	}
+	fullNumber := q.fullCueNumber(parentNumber, cueNumber)
 
 	// Check change detection results for this cue
 	var uniqueID string
 	var err error
 
-	log.Debug("About to check change detection for cue", "full_number", fullNumber, "cue_name", cueName)
+	q.log().Debug("About to check change detection for cue", "full_number", fullNumber, "cue_name", cueName)
 
 	// Generate position-based key for cues without numbers (same logic as indexing)
 	var positionKey string
 	if fullNumber == "" && cueIndex >= 0 {
-		if parentNumber != "" {
-			positionKey = fmt.Sprintf("%s@%d[%s:%s]", parentNumber, cueIndex, strings.ToLower(cueType), cueName)
-		} else {
-			positionKey = fmt.Sprintf("@%d[%s:%s]", cueIndex, strings.ToLower(cueType), cueName)
-		}
-		log.Debug("Generated position key for numberless cue", "position_key", positionKey, "parent", parentNumber, "index", cueIndex, "type", cueType, "name", cueName)
+		positionKey = positionCueKey(parentNumber, cueIndex, cueType, cueName)
+		q.log().Debug("Generated position key for numberless cue", "position_key", positionKey, "parent", parentNumber, "index", cueIndex, "type", cueType, "name", cueName)
 	}
 
 	// Check change detection results using number first, then position key as fallback
@@ -2012,119 +2111,132 @@ func (q *Workspace) processCueListWithParentMappingAndChangeDetectionWithIndex(c
 	}
 
 	if changeResult != nil {
-		log.Debug("Found change result for cue", "lookup_key", lookupKey, "action", changeResult.Action)
+		q.log().Debug("Found change result for cue", "lookup_key", lookupKey, "action", changeResult.Action)
 
 		switch changeResult.Action {
 		case "skip":
 			// Cue hasn't changed, skip creation and hierarchy processing
-			log.Infof("Skipping unchanged cue: [%s] %s (%s) - %s", lookupKey, cueName, cueType, changeResult.Reason)
+			q.log().Infof("Skipping unchanged cue: [%s] %s (%s) - %s", lookupKey, cueName, cueType, changeResult.Reason)
 			uniqueID = changeResult.ExistingID
-			if fullNumber != "" && uniqueID != "" {
-				mapping.NumberToID[fullNumber] = uniqueID
-			}
+			mapping.recordCue(fullNumber, cueName, uniqueID)
+			q.cueSkipped(lookupKey, cueData, changeResult)
 			// Early return to avoid move operations and sub-cue processing
 			return uniqueID, nil
 
+		case "move", "keep":
+			// Cue only changed position, which applyMoves has already taken care of, or is
+			// only kept to reach selected cues, but its sub-cues may still have changes
+			q.log().Infof("Keeping cue: [%s] %s (%s) - %s", lookupKey, cueName, cueType, changeResult.Reason)
+			uniqueID = changeResult.ExistingID
+
 		case "update":
 			// Update existing cue with changed properties
-			log.Infof("Updating changed cue: [%s] %s (%s) - %s", lookupKey, cueName, cueType, changeResult.Reason)
+			q.log().Infof("Updating changed cue: [%s] %s (%s) - %s", lookupKey, cueName, cueType, changeResult.Reason)
 			uniqueID = changeResult.ExistingID
 			if uniqueID == "" {
 				return "", fmt.Errorf("cannot update cue %s: no existing ID provided", lookupKey)
 			}
 
-			// Update the cue properties
-			err = q.updateCueProperties(uniqueID, cueData)
+			// Update the cue properties, leaving the fields kept from QLab as they are
+			updateData := cueData
+			if len(changeResult.KeptFields) > 0 {
+				updateData = maps.Clone(cueData)
+				for _, field := range changeResult.KeptFields {
+					delete(updateData, field)
+				}
+			}
+			err = q.updateCueProperties(uniqueID, updateData)
 			if err != nil {
-				log.Debug("ERROR - Failed to update cue", "lookup_key", lookupKey, "uniqueID", uniqueID, "error", err)
-				return "", fmt.Errorf("failed to update cue %s: %v", lookupKey, err)
+				q.log().Debug("ERROR - Failed to update cue", "lookup_key", lookupKey, "uniqueID", uniqueID, "error", err)
+				return "", q.transmitFailed(lookupKey, PhaseProperties, fmt.Errorf("failed to update cue %s: %v", lookupKey, err))
 			}
-			log.Debug("Successfully updated cue", "lookup_key", lookupKey, "uniqueID", uniqueID)
 
-			if fullNumber != "" && uniqueID != "" {
-				mapping.NumberToID[fullNumber] = uniqueID
+			// A cue matched by uniqueID under another number takes the source's number
+			if _, renumbered := changeResult.ModifiedFields["number"]; renumbered && !slices.Contains(changeResult.KeptFields, "number") {
+				if err := q.setCueProperty(uniqueID, "number", fullNumber); err != nil {
+					if _, isConflict := err.(*CueNumberConflictError); !isConflict {
+						return "", q.transmitFailed(lookupKey, PhaseProperties, fmt.Errorf("failed to renumber cue %s: %w", lookupKey, err))
+					}
+					q.log().Warnf("Skipping renumbering due to conflict: %v", err)
+				}
 			}
+			q.log().Debug("Successfully updated cue", "lookup_key", lookupKey, "uniqueID", uniqueID)
+			q.progress.step(PhaseProperties, fullNumber)
+			q.cueUpdated(lookupKey, uniqueID, cueData, changeResult)
+
+			mapping.recordCue(fullNumber, cueName, uniqueID)
 
 		case "create":
 			// Create new cue
-			log.Debug("PROCESSING CREATE ACTION for cue", "lookup_key", lookupKey, "name", cueName, "type", cueType, "reason", changeResult.Reason)
+			q.log().Debug("PROCESSING CREATE ACTION for cue", "lookup_key", lookupKey, "name", cueName, "type", cueType, "reason", changeResult.Reason)
 			uniqueID, err = q.createCueWithoutTarget(cueData, fullNumber)
 			if err != nil {
-				log.Debug("ERROR - Failed to create cue", "lookup_key", lookupKey, "error", err)
-				return "", fmt.Errorf("failed to create cue %s: %v", lookupKey, err)
+				q.log().Debug("ERROR - Failed to create cue", "lookup_key", lookupKey, "error", err)
+				return "", q.transmitFailed(lookupKey, PhaseCreate, fmt.Errorf("failed to create cue %s: %w", lookupKey, err))
 			}
-			log.Debug("Successfully created cue", "lookup_key", lookupKey, "uniqueID", uniqueID)
+			q.log().Debug("Successfully created cue", "lookup_key", lookupKey, "uniqueID", uniqueID)
+			q.progress.step(PhaseCreate, fullNumber)
+			q.cueCreated(lookupKey, uniqueID, cueData)
 		default:
 			// Create new cue
-			log.Infof("Creating new cue: [%s] %s (%s) - %s", lookupKey, cueName, cueType, changeResult.Reason)
+			q.log().Infof("Creating new cue: [%s] %s (%s) - %s", lookupKey, cueName, cueType, changeResult.Reason)
 			uniqueID, err = q.createCueWithoutTarget(cueData, fullNumber)
 			if err != nil {
-				return "", fmt.Errorf("failed to create cue %s: %v", lookupKey, err)
+				return "", q.transmitFailed(lookupKey, PhaseCreate, fmt.Errorf("failed to create cue %s: %w", lookupKey, err))
 			}
+			q.progress.step(PhaseCreate, fullNumber)
+			q.cueCreated(lookupKey, uniqueID, cueData)
 		}
 	} else {
 		// No change detection data available
-		log.Debug("No change detection data found for cue, checking if cue already exists", "number", fullNumber)
+		q.log().Debug("No change detection data found for cue, checking if cue already exists", "number", fullNumber)
 
 		// Check if we already found this cue list exists
 		if existingCueListID != "" {
-			log.Infof("Using existing cue list: %s (%s) - ID %s", cueName, cueType, existingCueListID)
+			q.log().Infof("Using existing cue list: %s (%s) - ID %s", cueName, cueType, existingCueListID)
 			uniqueID = existingCueListID
 
 			// Return early - don't process sub-cues or move operations for existing cue lists
-			if fullNumber != "" && uniqueID != "" {
-				mapping.NumberToID[fullNumber] = uniqueID
-			}
+			mapping.recordCue(fullNumber, cueName, uniqueID)
 			return uniqueID, nil
 		} else {
 			// Create new cue
 			if cueName != "" {
 				if fullNumber != "" {
-					log.Infof("Creating new cue (no change data): [%s] %s (%s)", fullNumber, cueName, cueType)
+					q.log().Infof("Creating new cue (no change data): [%s] %s (%s)", fullNumber, cueName, cueType)
 				} else {
-					log.Infof("Creating new cue (no change data): %s (%s)", cueName, cueType)
+					q.log().Infof("Creating new cue (no change data): %s (%s)", cueName, cueType)
 				}
 			}
 			uniqueID, err = q.createCueWithoutTarget(cueData, fullNumber)
 			if err != nil {
-				log.Debug("ERROR - Failed to create cue in no-change-data path", "error", err)
-				return "", fmt.Errorf("failed to create cue %s: %v", fullNumber, err)
+				q.log().Debug("ERROR - Failed to create cue in no-change-data path", "error", err)
+				return "", q.transmitFailed(fullNumber, PhaseCreate, fmt.Errorf("failed to create cue %s: %w", fullNumber, err))
 			}
-			log.Debug("Successfully created cue (no change data)", "number", fullNumber, "uniqueID", uniqueID)
+			q.log().Debug("Successfully created cue (no change data)", "number", fullNumber, "uniqueID", uniqueID)
+			q.progress.step(PhaseCreate, fullNumber)
+			q.cueCreated(fullNumber, uniqueID, cueData)
 		}
 	}
 
 	// Index newly created cue lists by name for duplicate prevention
 	if cueType == "list" && cueName != "" && uniqueID != "" {
-		q.cueListNames[cueName] = uniqueID
+		q.recordCueList(cueName, uniqueID)
 	}
 
-	// Add to mapping if we have a cue number and ID
-	if fullNumber != "" && uniqueID != "" {
-		mapping.NumberToID[fullNumber] = uniqueID
-	}
+	// Add to the number and name indexes
+	mapping.recordCue(fullNumber, cueName, uniqueID)
 
 	// Check if this cue has a target that needs to be set later
-	if targetNumber, ok := cueData["cueTargetNumber"].(string); ok && targetNumber != "" && uniqueID != "" {
-		mapping.CuesWithTargets = append(mapping.CuesWithTargets, CueTarget{
-			UniqueID:     uniqueID,
-			TargetNumber: targetNumber,
-		})
-	}
+	mapping.recordTarget(uniqueID, cueData)
 
 	// Move cue into parent group if we have a parent
 	if parentUniqueID != "" && uniqueID != "" {
 		// Check if parent is an existing cue list - if so, skip move operation
-		isExistingCueList := false
-		for _, existingID := range q.cueListNames {
-			if existingID == parentUniqueID {
-				isExistingCueList = true
-				break
-			}
-		}
+		isExistingCueList := q.isCueListID(parentUniqueID)
 
 		if isExistingCueList {
-			log.Debug("Skipping move operation - parent is an existing cue list that cannot accept new cues", "parentUniqueID", parentUniqueID)
+			q.log().Debug("Skipping move operation - parent is an existing cue list that cannot accept new cues", "parentUniqueID", parentUniqueID)
 		} else {
 			err = q.moveCueToParent(uniqueID, parentUniqueID)
 			if err != nil {
@@ -2135,18 +2247,20 @@ func (q *Workspace) processCueListWithParentMappingAndChangeDetectionWithIndex(c
 
 	// Process sub-cues if they exist
 	if cuesValue, exists := cueData["cues"]; exists {
-		log.Debug("Found 'cues' field in cue data", "number", fullNumber)
+		q.log().Debug("Found 'cues' field in cue data", "number", fullNumber)
 		if subCues, ok := cuesValue.([]any); ok {
-			log.Debug("Processing sub-cues for parent cue", "count", len(subCues), "parentNumber", fullNumber)
+			q.log().Debug("Processing sub-cues for parent cue", "count", len(subCues), "parentNumber", fullNumber)
 			if uniqueID != "" {
+				childIDs := make(map[int]string, len(subCues))
 				for childIndex, subCueData := range subCues {
 					if subCue, ok := subCueData.(map[string]any); ok {
-						log.Debug("Processing sub-cue for parent", "childIndex", childIndex+1, "totalSubCues", len(subCues), "parentNumber", fullNumber)
+						q.log().Debug("Processing sub-cue for parent", "childIndex", childIndex+1, "totalSubCues", len(subCues), "parentNumber", fullNumber)
 						childUniqueID, err := q.processCueListWithParentMappingAndChangeDetectionWithIndex(subCue, fullNumber, "", mapping, changeResults, childIndex)
 						if err != nil {
-							log.Debug("ERROR - Failed to process sub-cue", "childIndex", childIndex, "error", err)
+							q.log().Debug("ERROR - Failed to process sub-cue", "childIndex", childIndex, "error", err)
 							return "", fmt.Errorf("error processing sub-cue %d: %v", childIndex, err)
 						}
+						childIDs[childIndex] = childUniqueID
 
 						// Move the child cue into this parent group at the correct index
 						if childUniqueID != "" {
@@ -2170,48 +2284,50 @@ func (q *Workspace) processCueListWithParentMappingAndChangeDetectionWithIndex(c
 								}
 							}
 
-							// Check if this child was skipped
-							if childChangeResult, exists := changeResults[childLookupKey]; exists && childChangeResult.Action == "skip" {
+							// Check if this child was skipped or already moved into place
+							if childChangeResult, exists := changeResults[childLookupKey]; exists && (childChangeResult.Action == "skip" || childChangeResult.Action == "move" || childChangeResult.Action == "keep") {
 								shouldSkipMove = true
-								log.Debug("Skipping move for unchanged child cue", "childLookupKey", childLookupKey, "childUniqueID", childUniqueID)
+								q.log().Debug("Skipping move for unchanged child cue", "childLookupKey", childLookupKey, "childUniqueID", childUniqueID)
 							}
 
 							if shouldSkipMove {
 								// Child cue is already in correct position, don't move it
 							} else {
 								// Check if parent is an existing cue list - if so, skip move operation
-								isExistingCueList := false
-								for _, existingID := range q.cueListNames {
-									if existingID == uniqueID {
-										isExistingCueList = true
-										break
-									}
-								}
+								isExistingCueList := q.isCueListID(uniqueID)
 
 								if isExistingCueList {
-									log.Debug("Skipping child move operation - parent is an existing cue list that cannot accept moved cues", "parentUniqueID", uniqueID)
+									q.log().Debug("Skipping child move operation - parent is an existing cue list that cannot accept moved cues", "parentUniqueID", uniqueID)
 								} else {
-									log.Debug("Moving child cue into parent", "childUniqueID", childUniqueID, "parentUniqueID", uniqueID, "index", childIndex)
+									q.log().Debug("Moving child cue into parent", "childUniqueID", childUniqueID, "parentUniqueID", uniqueID, "index", childIndex)
 									err = q.moveCueToParentWithIndex(childUniqueID, uniqueID, childIndex)
 									if err != nil {
-										log.Debug("ERROR - Failed to move child cue", "error", err)
+										q.log().Debug("ERROR - Failed to move child cue", "error", err)
 										return "", fmt.Errorf("failed to move child cue %s into parent %s at index %d: %v", childUniqueID, uniqueID, childIndex, err)
 									}
+									q.progress.step(PhaseMove, childFullNumber)
 								}
 							}
 						}
 					} else {
-						log.Debug("WARNING - Sub-cue is not a valid map", "childIndex", childIndex)
+						q.log().Debug("WARNING - Sub-cue is not a valid map", "childIndex", childIndex)
+					}
+				}
+
+				// Children of a cart land in its first empty cells; put them in their own
+				if strings.EqualFold(cueType, CueTypeCart) {
+					if err := q.placeCartChildren(uniqueID, subCues, childIDs); err != nil {
+						return "", err
 					}
 				}
 			} else {
-				log.Debug("WARNING - Parent cue has no uniqueID, cannot process sub-cues")
+				q.log().Debug("WARNING - Parent cue has no uniqueID, cannot process sub-cues")
 			}
 		} else {
-			log.Debug("WARNING - 'cues' field exists but is not an array", "number", fullNumber)
+			q.log().Debug("WARNING - 'cues' field exists but is not an array", "number", fullNumber)
 		}
 	} else {
-		log.Debug("No 'cues' field found in cue data", "number", fullNumber)
+		q.log().Debug("No 'cues' field found in cue data", "number", fullNumber)
 	}
 
 	return uniqueID, nil
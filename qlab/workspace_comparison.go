@@ -5,22 +5,33 @@ type CueChangeResult struct {
 	HasChanged     bool                      // Whether the cue needs to be updated
 	Reason         string                    // Explanation of why it changed or didn't change
 	ExistingID     string                    // ID of existing cue in QLab (if unchanged)
-	Action         string                    // What action to take: "create", "update", "skip"
+	Action         string                    // What action to take: "create", "update", "skip", "move", "keep", "delete"
 	ModifiedFields map[string]string         // Fields that differ: field_name -> "old_value -> new_value"
 	CueID          string                    // QLab cue ID for traceability
 	FieldConflicts map[string]*FieldConflict // Detailed field-level conflict information
 	ScopeData      *ScopeComparison          // Scope-based comparison data
+	Move           *CueMove                  // Where to move the cue, nil when its position is unchanged
+	PreviousKey    string                    // Key the cue has in QLab when it was matched by uniqueID under another number or name
+	ColorMapping   string                    // How a hex, RGB or unavailable source color maps to a QLab color, e.g. "#3070e0 -> blue"
+	KeptFields     []string                  // Fields a per-field resolution kept from QLab, left out of the update
 }
 
 // ThreeWayComparison contains the results of comparing QLab workspace, cache, and source
 type ThreeWayComparison struct {
-	CueResults       map[string]*CueChangeResult // Map of cue number -> comparison result
-	HasCache         bool                        // Whether cache was available
-	HasQLabData      bool                        // Whether QLab data was available
-	CacheMatchesQLab bool                        // Whether cache matches current QLab state
-	QLabChosenCues   map[string]bool             // Cues where user chose "Keep QLab version"
-	QLabChosenFields map[string]map[string]bool  // Fields where user chose "Keep QLab version": cue -> field -> bool
-	CurrentQLabData  map[string]any              // Current QLab workspace data for source file updates
-	WorkspaceScope   *ScopeComparison            // Workspace-level scope comparison
-	MergedResult     *MergedScope                // Final merged result after conflict resolution
+	CueResults       map[string]*CueChangeResult         // Map of cue number -> comparison result
+	HasCache         bool                                // Whether cache was available
+	HasQLabData      bool                                // Whether QLab data was available
+	CacheMatchesQLab bool                                // Whether cache matches current QLab state
+	QLabChosenCues   map[string]bool                     // Cues where user chose "Keep QLab version"
+	QLabChosenFields map[string]map[string]bool          // Fields where user chose "Keep QLab version": cue -> field -> bool
+	CurrentQLabData  map[string]any                      // Current QLab workspace data for source file updates
+	SourceData       map[string]any                      // Source workspace data the comparison was made from
+	WorkspaceScope   *ScopeComparison                    // Workspace-level scope comparison
+	MergedResult     *MergedScope                        // Final merged result after conflict resolution
+	ClockSkew        *ClockSkewReport                    // Cache age and clock skew, nil without a cache
+	Duplicates       []DuplicateCue                      // Cue identifiers the source uses more than once, with Suffixed set when they were renumbered
+	Resolutions      map[string]ConflictResolutionChoice // Choice applied to each conflict, by cue number
+	FieldResolutions FieldResolutions                    // Per-field choices applied to the conflicts resolved with ChoicePerField
+	Remembered       []RememberedResolution              // Conflict choices remembered by the cache, see SetRememberResolutions
+	Media            *MediaCollectReport                 // Media collected by the media manager, nil without one
 }
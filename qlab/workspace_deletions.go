@@ -0,0 +1,204 @@
+package qlab
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// cuejitsuInboxName is the default name of the cue list imported content is staged in
+const cuejitsuInboxName = "Cuejitsu Inbox"
+
+// SetSyncDeletions sets whether TransmitWorkspaceData deletes cues that were removed from the
+// source since the last transmit. A cue is only deleted when the cache shows it was
+// transmitted before and it is in the Cuejitsu Inbox or a cue list the source defines, so
+// cues added in QLab and cue lists the source doesn't manage are never touched. Cues that
+// were modified in QLab since the last transmit are offered as conflicts first.
+func (q *Workspace) SetSyncDeletions(enabled bool) {
+	q.syncDeletions = enabled
+}
+
+// detectDeletions adds a "delete" result for each managed cue that is in the cache and in
+// QLab, as the same cue by uniqueID, but no longer in the source. Cues inside a group that
+// is itself deleted are left to the group's deletion. A group holding cues added in QLab is
+// kept, so they aren't deleted with it; the removed cues inside it are deleted on their own.
+func (q *Workspace) detectDeletions(comparison *ThreeWayComparison, sourceCueData map[string]any, sourceCues, cachedCues, currentCues map[string]map[string]any) {
+	if !comparison.HasCache || !comparison.HasQLabData {
+		q.log().Debug("Deletion sync needs both the cache and QLab's state, skipping")
+		return
+	}
+
+	hierarchy := make(map[string]scopeHierarchy)
+	q.indexScopeHierarchy(comparison.CurrentQLabData, hierarchy)
+	managed := managedCueLists(sourceCueData, q.managedInboxName())
+
+	// Cues a source cue was matched to by uniqueID were renumbered or renamed, not removed
+	claimed := make(map[string]bool)
+	for _, result := range comparison.CueResults {
+		if result.ExistingID != "" {
+			claimed[result.ExistingID] = true
+		}
+	}
+
+	// Groups holding cues the cache doesn't know, which were added in QLab, aren't deleted
+	holdsUntracked := make(map[string]bool)
+	for key, currentCue := range currentCues {
+		cachedCue, tracked := cachedCues[key]
+		if tracked && cachedCue["uniqueID"] == currentCue["uniqueID"] {
+			continue
+		}
+		for _, ancestor := range hierarchy[key].ancestors {
+			holdsUntracked[ancestor] = true
+		}
+	}
+
+	deleted := make(map[string]*CueChangeResult)
+	for key, cachedCue := range cachedCues {
+		if _, inSource := sourceCues[key]; inSource {
+			continue
+		}
+		currentCue, inQLab := currentCues[key]
+		if !inQLab {
+			continue // Already gone from QLab
+		}
+		if !managed[hierarchy[key].cueList] {
+			q.log().Debugf("Not deleting cue %s outside the managed cue lists", key)
+			continue
+		}
+		uniqueID, _ := currentCue["uniqueID"].(string)
+		if uniqueID == "" || claimed[uniqueID] {
+			continue
+		}
+		// The number may have passed to another cue in QLab since the last transmit
+		if cachedID, _ := cachedCue["uniqueID"].(string); cachedID != uniqueID {
+			q.log().Debugf("Not deleting cue %s, which is no longer the cue transmitted under that number", key)
+			continue
+		}
+		if holdsUntracked[key] {
+			q.log().Infof("Not deleting group %s, which holds cues added in QLab", key)
+			continue
+		}
+
+		result := &CueChangeResult{
+			HasChanged:     true,
+			Action:         "delete",
+			Reason:         "removed from source",
+			ExistingID:     uniqueID,
+			CueID:          uniqueID,
+			ModifiedFields: make(map[string]string),
+			FieldConflicts: make(map[string]*FieldConflict),
+		}
+		if diffs := q.compareCuePropertiesDetailed(cachedCue, currentCue); len(diffs) > 0 {
+			result.Reason = "removed from source but modified in QLab"
+			result.ModifiedFields = diffs
+		}
+		deleted[key] = result
+	}
+
+	for key, result := range deleted {
+		if anyDeleted(hierarchy[key].ancestors, deleted) {
+			continue
+		}
+		comparison.CueResults[key] = result
+	}
+}
+
+// anyDeleted reports whether any of the cues is being deleted
+func anyDeleted(keys []string, deleted map[string]*CueChangeResult) bool {
+	for _, key := range keys {
+		if _, ok := deleted[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// managedCueLists returns the names of the cue lists deletion sync may delete from: the
+// staging cue list named inboxName, if any, every cue list the source defines and every
+// cue list its cues are routed to by listName
+func managedCueLists(sourceCueData map[string]any, inboxName string) map[string]bool {
+	managed := make(map[string]bool)
+	if inboxName != "" {
+		managed[inboxName] = true
+	}
+	cues, ok := sourceCueData["cues"].([]any)
+	if !ok {
+		if nested, ok := sourceCueData["workspace"].(map[string]any); ok {
+			cues, _ = nested["cues"].([]any)
+		}
+	}
+	for _, cueData := range cues {
+		cue, ok := cueData.(map[string]any)
+		if !ok {
+			continue
+		}
+		if listName, _ := cue["listName"].(string); listName != "" {
+			managed[listName] = true
+		}
+		switch cueType, _ := cue["type"].(string); strings.ToLower(cueType) {
+		case "list", "cart", CueTypeList:
+			if name, _ := cue["name"].(string); name != "" {
+				managed[name] = true
+			}
+		}
+	}
+	return managed
+}
+
+// deletionConflicts returns a conflict for each pending deletion of a cue modified in QLab
+func deletionConflicts(comparison *ThreeWayComparison) []CueConflict {
+	var conflicts []CueConflict
+	for _, key := range sortedResultKeys(comparison.CueResults, "delete") {
+		result := comparison.CueResults[key]
+		if len(result.ModifiedFields) == 0 {
+			continue
+		}
+		properties := make([]string, 0, len(result.ModifiedFields))
+		for field := range result.ModifiedFields {
+			properties = append(properties, field)
+		}
+		sort.Strings(properties)
+		fieldConflicts := fieldConflictsFromModified(result, true)
+		for _, change := range fieldConflicts {
+			change.SourceValue = nil
+		}
+		conflicts = append(conflicts, CueConflict{
+			CueNumber:      key,
+			CueIdentifier:  key,
+			ConflictType:   ConflictDeletedInSource,
+			Scope:          ScopeCue,
+			Properties:     properties,
+			FieldConflicts: fieldConflicts,
+			Description:    fmt.Sprintf("Cue %s was removed from the source file but has been modified in QLab since last sync (fields: %v)", key, properties),
+		})
+	}
+	return conflicts
+}
+
+// applyDeletions deletes the cues with a "delete" result
+func (q *Workspace) applyDeletions(results map[string]*CueChangeResult) error {
+	keys := sortedResultKeys(results, "delete")
+	q.progress.setTotal(PhaseDelete, len(keys))
+	for _, key := range keys {
+		result := results[key]
+		q.log().Infof("Deleting cue removed from source: [%s] (ID: %s)", key, result.ExistingID)
+		if err := q.deleteCue(result.ExistingID); err != nil {
+			return q.transmitFailed(key, PhaseDelete, fmt.Errorf("failed to delete cue %s: %w", key, err))
+		}
+		q.progress.step(PhaseDelete, key)
+		q.journal.record(JournalEntry{Phase: PhaseDelete, Cue: key, UniqueID: result.ExistingID})
+	}
+	return nil
+}
+
+// sortedResultKeys returns the keys of the results with the given action, sorted
+func sortedResultKeys(results map[string]*CueChangeResult, action string) []string {
+	var keys []string
+	for key, result := range results {
+		if result != nil && result.Action == action {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,216 @@
+package qlab
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ErrNoCueData is returned by DiffWorkspaces when a side has no cues in a known format
+var ErrNoCueData = errors.New("no cue data found")
+
+// diffIgnored are cue data keys that differ between machines or are compared separately
+var diffIgnored = []string{"cues", "uniqueID", "listName"}
+
+// WorkspaceDiff is the result of DiffWorkspaces. Cues are listed in workspace order.
+type WorkspaceDiff struct {
+	Added   []CueDiff // Cues only in b
+	Removed []CueDiff // Cues only in a
+	Changed []CueDiff // Cues in both whose properties or place differ
+}
+
+// CueDiff is a cue that differs between two workspaces
+type CueDiff struct {
+	Key     string      // Full cue number, or parent@position[type:name] key for unnumbered cues
+	Name    string      // Name in b, or in a when the cue was removed
+	Type    string      // Type in b, or in a when the cue was removed
+	CueList string      // Name of the enclosing cue list, empty for sources without cue lists
+	Path    []string    // Keys of the enclosing groups, outermost first
+	Fields  []FieldDiff // Changed properties, sorted by name; empty for added and removed cues
+}
+
+// FieldDiff is a property whose value differs between two workspaces. A cue that moved
+// to another group or cue list has a "parent" field with both locations.
+type FieldDiff struct {
+	Field string
+	A     any // Value in a, nil when unset
+	B     any // Value in b, nil when unset
+}
+
+// Empty reports whether the workspaces had the same cues
+func (d *WorkspaceDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// String renders the diff as a report with one line per cue and per changed property
+func (d *WorkspaceDiff) String() string {
+	if d.Empty() {
+		return "No differences\n"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d added, %d removed, %d changed\n", len(d.Added), len(d.Removed), len(d.Changed))
+	for _, cue := range d.Added {
+		fmt.Fprintf(&b, "+ %s\n", cue.label())
+	}
+	for _, cue := range d.Removed {
+		fmt.Fprintf(&b, "- %s\n", cue.label())
+	}
+	for _, cue := range d.Changed {
+		fmt.Fprintf(&b, "~ %s\n", cue.label())
+		for _, field := range cue.Fields {
+			fmt.Fprintf(&b, "    %s: %q -> %q\n", field.Field, diffValue(field.A), diffValue(field.B))
+		}
+	}
+	return b.String()
+}
+
+// location renders the cue list and groups the cue is in
+func (c CueDiff) location() string {
+	return strings.Join(append([]string{c.CueList}, c.Path...), " > ")
+}
+
+// label identifies the cue and where it is for the report
+func (c CueDiff) label() string {
+	label := fmt.Sprintf("[%s] %s %q", c.Key, c.Type, c.Name)
+	if location := strings.TrimPrefix(c.location(), " > "); location != "" {
+		label += " in " + location
+	}
+	return label
+}
+
+// diffValue renders a FieldDiff value for the report
+func diffValue(value any) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// DiffWorkspaces compares the cues of two workspaces, for example the show machine and its
+// backup. Each side can be QLab workspace data, CUE source data, or a snapshot written by
+// ExportSnapshot and decoded into a map. Cues are matched the same way as change detection,
+// by full cue number or by position for unnumbered cues, and compared with the same rules,
+// so unique IDs and operational state such as armed are not reported.
+func DiffWorkspaces(a, b map[string]any) (*WorkspaceDiff, error) {
+	a, b = diffableWorkspace(a), diffableWorkspace(b)
+	if !hasCueData(a) {
+		return nil, fmt.Errorf("workspace a: %w", ErrNoCueData)
+	}
+	if !hasCueData(b) {
+		return nil, fmt.Errorf("workspace b: %w", ErrNoCueData)
+	}
+	q := &Workspace{}
+	cuesA, cuesB := q.diffIndex(a), q.diffIndex(b)
+
+	hierarchyA := make(map[string]scopeHierarchy)
+	q.indexScopeHierarchy(a, hierarchyA)
+	hierarchyB := make(map[string]scopeHierarchy)
+	q.indexScopeHierarchy(b, hierarchyB)
+
+	diff := &WorkspaceDiff{}
+	for key, cueB := range cuesB {
+		cueA, inA := cuesA[key]
+		if !inA {
+			diff.Added = append(diff.Added, newCueDiff(key, cueB, hierarchyB[key], cuesB))
+			continue
+		}
+		before := newCueDiff(key, cueA, hierarchyA[key], cuesA)
+		changed := newCueDiff(key, cueB, hierarchyB[key], cuesB)
+		changed.Fields = q.diffFields(cueA, cueB)
+		if parentA, parentB := before.location(), changed.location(); parentA != parentB {
+			changed.Fields = append(changed.Fields, FieldDiff{Field: "parent", A: parentA, B: parentB})
+		}
+		if len(changed.Fields) > 0 {
+			diff.Changed = append(diff.Changed, changed)
+		}
+	}
+	for key, cueA := range cuesA {
+		if _, inB := cuesB[key]; !inB {
+			diff.Removed = append(diff.Removed, newCueDiff(key, cueA, hierarchyA[key], cuesA))
+		}
+	}
+
+	sortCueDiffs(diff.Added, hierarchyB)
+	sortCueDiffs(diff.Removed, hierarchyA)
+	sortCueDiffs(diff.Changed, hierarchyB)
+	return diff, nil
+}
+
+// diffableWorkspace reads snapshots as QLab data, whose cue lists are under "data"
+func diffableWorkspace(workspace map[string]any) map[string]any {
+	if cueLists, ok := workspace["cueLists"].([]any); ok {
+		if _, hasData := workspace["data"]; !hasData {
+			return map[string]any{"data": cueLists}
+		}
+	}
+	return workspace
+}
+
+// hasCueData reports whether workspace data is in one of the formats DiffWorkspaces reads
+func hasCueData(workspace map[string]any) bool {
+	if _, ok := workspace["cues"].([]any); ok {
+		return true
+	}
+	if _, ok := workspace["workspace"].(map[string]any); ok {
+		return true
+	}
+	_, ok := workspace["data"]
+	return ok
+}
+
+// diffIndex indexes the cues of workspace data, leaving out the list and cart cues that
+// name cue lists in source data since QLab data has no matching cues
+func (q *Workspace) diffIndex(workspace map[string]any) map[string]map[string]any {
+	cues := q.indexCuesFromWorkspace(workspace)
+	for key, cue := range cues {
+		switch cueType, _ := cue["type"].(string); strings.ToLower(cueType) {
+		case "list", "cart", CueTypeList:
+			delete(cues, key)
+		}
+	}
+	return cues
+}
+
+// diffFields returns the known properties that differ between two versions of a cue
+func (q *Workspace) diffFields(cueA, cueB map[string]any) []FieldDiff {
+	var fields []FieldDiff
+	for _, field := range q.getAllFieldNames(cueA, cueB, nil) {
+		if slices.Contains(diffIgnored, field) || !isKnownCueProperty(field) {
+			continue
+		}
+		valueA, valueB := q.normalizeProperty(cueA[field]), q.normalizeProperty(cueB[field])
+		// Snapshots and source data leave out timings that are zero
+		if (valueA == "0" && valueB == "") || (valueA == "" && valueB == "0") {
+			continue
+		}
+		if q.comparePropertyValues(field, valueA, valueB) {
+			continue
+		}
+		fields = append(fields, FieldDiff{Field: field, A: cueA[field], B: cueB[field]})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+	return fields
+}
+
+// newCueDiff describes a cue and where it sits. Only ancestors in cues are groups; the
+// others are the cue list cues of source data.
+func newCueDiff(key string, cue map[string]any, position scopeHierarchy, cues map[string]map[string]any) CueDiff {
+	name, _ := cue["name"].(string)
+	cueType, _ := cue["type"].(string)
+	path := []string{}
+	for i := len(position.ancestors) - 1; i >= 0; i-- {
+		if _, isGroup := cues[position.ancestors[i]]; isGroup {
+			path = append(path, position.ancestors[i])
+		}
+	}
+	return CueDiff{Key: key, Name: name, Type: strings.ToLower(cueType), CueList: position.cueList, Path: path}
+}
+
+// sortCueDiffs orders cues as they appear in the workspace they were described from
+func sortCueDiffs(cues []CueDiff, hierarchy map[string]scopeHierarchy) {
+	sort.Slice(cues, func(i, j int) bool {
+		return hierarchy[cues[i].Key].order < hierarchy[cues[j].Key].order
+	})
+}
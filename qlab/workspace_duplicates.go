@@ -0,0 +1,187 @@
+package qlab
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DuplicatePolicy controls how TransmitWorkspaceData handles duplicate cue identifiers in source data
+type DuplicatePolicy int
+
+const (
+	// DuplicatePolicyRefuse aborts the transmit before any OSC is sent (default)
+	DuplicatePolicyRefuse DuplicatePolicy = iota
+	// DuplicatePolicyWarn logs every duplicate and transmits anyway; later cues overwrite earlier ones in the index
+	DuplicatePolicyWarn
+	// DuplicatePolicySuffix renumbers every duplicate after the first with a letter suffix, e.g. 5a, and transmits
+	DuplicatePolicySuffix
+)
+
+// DuplicateCue is a cue identifier (full cue number or position key) used by more than one source cue
+type DuplicateCue struct {
+	Identifier string   // Full cue number, or parent@position[type:name] key for unnumbered cues
+	Paths      []string // Locations in the source data, e.g. "cues[2].cues[0]"
+	Suffixed   []string // Numbers DuplicatePolicySuffix gave the cues at Paths[1:], in order
+}
+
+// DuplicateCueError is returned when source data contains duplicate cue identifiers
+type DuplicateCueError struct {
+	Duplicates []DuplicateCue
+}
+
+func (e *DuplicateCueError) Error() string {
+	var details []string
+	for _, dup := range e.Duplicates {
+		details = append(details, fmt.Sprintf("%s at %s", dup.Identifier, strings.Join(dup.Paths, ", ")))
+	}
+	return fmt.Sprintf("source data contains %d duplicate cue identifiers: %s", len(e.Duplicates), strings.Join(details, "; "))
+}
+
+// SetDuplicatePolicy sets whether duplicate cue identifiers in source data refuse, only warn, or are suffixed before transmitting
+func (q *Workspace) SetDuplicatePolicy(policy DuplicatePolicy) {
+	q.duplicatePolicy = policy
+}
+
+// FindDuplicateCues scans source workspace data for cues that share a cue number or position key.
+// Identifiers are computed the same way as the change-detection index, so every duplicate reported
+// here is a cue that would otherwise silently overwrite another one.
+func FindDuplicateCues(workspaceData map[string]any) []DuplicateCue {
+	return findDuplicateCues(workspaceData, sourceCueKey)
+}
+
+// findDuplicateCues is FindDuplicateCues with cues identified by keyOf
+func findDuplicateCues(workspaceData map[string]any, keyOf cueKeyFunc) []DuplicateCue {
+	var cuesData []any
+	if cues, ok := workspaceData["cues"].([]any); ok {
+		cuesData = cues
+	} else if workspace, ok := workspaceData["workspace"].(map[string]any); ok {
+		if cues, ok := workspace["cues"].([]any); ok {
+			cuesData = cues
+		}
+	}
+
+	paths := make(map[string][]string)
+	collectCuePaths(cuesData, "", "cues", paths, keyOf)
+
+	var duplicates []DuplicateCue
+	for identifier, locations := range paths {
+		if len(locations) > 1 {
+			duplicates = append(duplicates, DuplicateCue{Identifier: identifier, Paths: locations})
+		}
+	}
+	sort.Slice(duplicates, func(i, j int) bool {
+		return duplicates[i].Paths[0] < duplicates[j].Paths[0]
+	})
+	return duplicates
+}
+
+// collectCuePaths records the source path of every identifiable cue, keyed by identifier
+func collectCuePaths(cuesData []any, parentNumber, path string, paths map[string][]string, keyOf cueKeyFunc) {
+	for i, cueData := range cuesData {
+		cue, ok := cueData.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		cuePath := fmt.Sprintf("%s[%d]", path, i)
+		key, fullNumber := keyOf(cue, parentNumber, i)
+		if key != "" {
+			paths[key] = append(paths[key], cuePath)
+		}
+
+		if subCues, ok := cue["cues"].([]any); ok {
+			collectCuePaths(subCues, fullNumber, cuePath+".cues", paths, keyOf)
+		}
+	}
+}
+
+// checkDuplicateCues applies the duplicate policy to source data before anything is transmitted
+func (q *Workspace) checkDuplicateCues(workspaceData map[string]any) error {
+	duplicates := findDuplicateCues(workspaceData, q.sourceCueKey)
+	if len(duplicates) == 0 {
+		return nil
+	}
+
+	if q.duplicatePolicy == DuplicatePolicyWarn {
+		for _, dup := range duplicates {
+			q.log().Warnf("Duplicate cue identifier %s at %s; only the last occurrence will be tracked", dup.Identifier, strings.Join(dup.Paths, ", "))
+		}
+		return nil
+	}
+	return &DuplicateCueError{Duplicates: duplicates}
+}
+
+// SuffixDuplicateCues returns a copy of source data in which every cue reusing an earlier
+// cue's number is renumbered with the first letter suffix that's free, e.g. a second cue 5
+// becomes 5a, and the duplicates it resolved with their new numbers. A cue number written
+// relative to its group keeps that form. The source data itself is left alone.
+func SuffixDuplicateCues(workspaceData map[string]any) (map[string]any, []DuplicateCue) {
+	return suffixDuplicateCues(workspaceData, sourceCueKey)
+}
+
+// suffixDuplicateCues is SuffixDuplicateCues with cues identified by keyOf
+func suffixDuplicateCues(workspaceData map[string]any, keyOf cueKeyFunc) (map[string]any, []DuplicateCue) {
+	duplicates := findDuplicateCues(workspaceData, keyOf)
+	if len(duplicates) == 0 {
+		return workspaceData, nil
+	}
+	suffixed, _ := cloneMergeValue(workspaceData).(map[string]any)
+	cues, ok := suffixed["cues"].([]any)
+	if !ok {
+		if workspace, ok := suffixed["workspace"].(map[string]any); ok {
+			cues, _ = workspace["cues"].([]any)
+		}
+	}
+
+	paths := make(map[string][]string)
+	collectCuePaths(cues, "", "cues", paths, keyOf)
+	used := make(map[string]bool, len(paths))
+	for key := range paths {
+		used[key] = true
+	}
+
+	seen := make(map[string]bool)
+	var walk func(cues []any, parentNumber string)
+	walk = func(cues []any, parentNumber string) {
+		for i, cueData := range cues {
+			cue, ok := cueData.(map[string]any)
+			if !ok {
+				continue
+			}
+			key, fullNumber := keyOf(cue, parentNumber, i)
+			if key != "" && key == fullNumber && seen[key] {
+				_, written := sourceCueKey(cue, "", i)
+				for suffix := 'a'; suffix <= 'z'; suffix++ {
+					if !used[fullNumber+string(suffix)] {
+						fullNumber += string(suffix)
+						cue["number"] = written + string(suffix)
+						break
+					}
+				}
+			}
+			seen[fullNumber] = true
+			used[fullNumber] = true
+			if children, ok := cue["cues"].([]any); ok {
+				walk(children, fullNumber)
+			}
+		}
+	}
+	walk(cues, "")
+
+	// Report the numbers the cues ended up with, which includes the cues whose group was suffixed
+	numbers := make(map[string]string)
+	paths = make(map[string][]string)
+	collectCuePaths(cues, "", "cues", paths, keyOf)
+	for key, locations := range paths {
+		for _, location := range locations {
+			numbers[location] = key
+		}
+	}
+	for i, dup := range duplicates {
+		for _, location := range dup.Paths[1:] {
+			duplicates[i].Suffixed = append(duplicates[i].Suffixed, numbers[location])
+		}
+	}
+	return suffixed, duplicates
+}
@@ -0,0 +1,93 @@
+package qlab
+
+import (
+	"sync"
+)
+
+// DefaultEnrichmentConcurrency is how many cues are enriched at once by default
+const DefaultEnrichmentConcurrency = 8
+
+// defaultEnrichedProperties are queried for every cue when SetEnrichedProperties isn't used
+var defaultEnrichedProperties = []string{"fileTarget", "cueTargetNumber"}
+
+// SetEnrichmentConcurrency sets how many cues queryCurrentWorkspaceState enriches in
+// parallel with the properties /cueLists leaves out (default DefaultEnrichmentConcurrency).
+// Queries are only sent in parallel once replies arrive on a shared listener, that is
+// after the first message, over TCP, or from a Client; otherwise they go one at a time.
+func (q *Workspace) SetEnrichmentConcurrency(workers int) {
+	q.enrichWorkers = workers
+}
+
+// SetEnrichedProperties sets the properties queried for every cue when snapshotting the
+// workspace (default fileTarget and cueTargetNumber). The type-specific properties of fade,
+// video, MIDI and network cues are still queried. With no properties, only those are.
+func (q *Workspace) SetEnrichedProperties(properties ...string) {
+	q.enrichProperties = append([]string{}, properties...)
+}
+
+// enrichedProperties returns the properties queried for every cue
+func (q *Workspace) enrichedProperties() []string {
+	if q.enrichProperties == nil {
+		return defaultEnrichedProperties
+	}
+	return q.enrichProperties
+}
+
+// enrichmentConcurrency returns how many cues can be enriched at once
+func (q *Workspace) enrichmentConcurrency() int {
+	// Before the listener starts there's nothing to route overlapping replies
+	if q.updateServer == nil && !q.useTCP {
+		return 1
+	}
+	if q.enrichWorkers <= 0 {
+		return DefaultEnrichmentConcurrency
+	}
+	return q.enrichWorkers
+}
+
+// collectEnrichableCues appends every cue with a unique ID in cues and their children
+func collectEnrichableCues(cues []any, found []map[string]any) []map[string]any {
+	for _, cueData := range cues {
+		cue, ok := cueData.(map[string]any)
+		if !ok {
+			continue
+		}
+		if uniqueID, ok := cue["uniqueID"].(string); ok && uniqueID != "" {
+			found = append(found, cue)
+		}
+		if children, ok := cue["cues"].([]any); ok {
+			found = collectEnrichableCues(children, found)
+		}
+	}
+	return found
+}
+
+// enrichCuesConcurrently enriches the cues with a bounded pool of workers. Each worker
+// writes only to the cue it is enriching, so the cue maps need no locking.
+func (q *Workspace) enrichCuesConcurrently(cues []map[string]any) {
+	workers := min(q.enrichmentConcurrency(), len(cues))
+	if workers <= 1 {
+		for _, cue := range cues {
+			q.enrichCueProperties(cue, cue["uniqueID"].(string))
+		}
+		return
+	}
+
+	q.log().Debug("Enriching cues in parallel", "cues", len(cues), "workers", workers)
+	queue := make(chan map[string]any)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for cue := range queue {
+				q.enrichCueProperties(cue, cue["uniqueID"].(string))
+			}
+		}()
+	}
+	for _, cue := range cues {
+		queue <- cue
+	}
+	close(queue)
+	wg.Wait()
+}
@@ -0,0 +1,152 @@
+package qlab
+
+import (
+	"fmt"
+	"maps"
+)
+
+// EnvelopeVersion is the envelope format ReceiveWorkspace writes
+const EnvelopeVersion = 1
+
+// WorkspaceEnvelope is a workspace's cues arranged by cue list, with the workspace they came
+// from. It marshals to the JSON TransmitWorkspaceData takes back.
+type WorkspaceEnvelope struct {
+	Version     int               `json:"envelope"`
+	WorkspaceID string            `json:"workspaceID,omitempty"`
+	Name        string            `json:"name,omitempty"` // Workspace name as QLab shows it
+	BasePath    string            `json:"basePath,omitempty"`
+	CueLists    []EnvelopeCueList `json:"cueLists"` // In QLab's order
+}
+
+// EnvelopeCueList is a cue list of a WorkspaceEnvelope
+type EnvelopeCueList struct {
+	Name     string `json:"name"`
+	UniqueID string `json:"uniqueID,omitempty"`
+	Cues     []any  `json:"cues"`
+}
+
+// WorkspaceData returns the envelope as source data for TransmitWorkspaceData
+func (e *WorkspaceEnvelope) WorkspaceData() map[string]any {
+	cueLists := make([]any, len(e.CueLists))
+	for i, list := range e.CueLists {
+		cueLists[i] = map[string]any{"name": list.Name, "uniqueID": list.UniqueID, "cues": list.Cues}
+	}
+	return map[string]any{
+		"envelope": e.Version, "workspaceID": e.WorkspaceID, "name": e.Name, "basePath": e.BasePath, "cueLists": cueLists,
+	}
+}
+
+// isEnvelope reports whether source data is a WorkspaceEnvelope
+func isEnvelope(workspaceData map[string]any) bool {
+	_, hasLists := workspaceData["cueLists"].([]any)
+	_, hasCues := workspaceData["cues"]
+	return hasLists && !hasCues
+}
+
+// UnwrapEnvelope returns source data given as a WorkspaceEnvelope in the form change
+// detection compares: the cues of every cue list at the top level, in order, each with the
+// listName that routes it to its list, so lists QLab doesn't have are created in envelope
+// order. Empty cue lists have nothing to route and aren't created. The workspace name, ID
+// and basePath stay alongside as metadata. Other data is returned as it is.
+func UnwrapEnvelope(workspaceData map[string]any) map[string]any {
+	if !isEnvelope(workspaceData) {
+		return workspaceData
+	}
+	unwrapped := make(map[string]any, len(workspaceData))
+	for key, value := range workspaceData {
+		if key != "cueLists" {
+			unwrapped[key] = value
+		}
+	}
+
+	// Lists are found by name; QLab's uniqueIDs for them belong to the exported workspace
+	cues := []any{}
+	for _, listData := range workspaceData["cueLists"].([]any) {
+		list, ok := listData.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := list["name"].(string)
+		children, _ := list["cues"].([]any)
+		for _, cueData := range children {
+			if cue, ok := cueData.(map[string]any); ok {
+				cue = maps.Clone(cue)
+				cue["listName"] = name
+				cueData = cue
+			}
+			cues = append(cues, cueData)
+		}
+	}
+	unwrapped["cues"] = cues
+	return unwrapped
+}
+
+// ReceiveWorkspace queries the current QLab workspace like ReceiveWorkspaceData, returning
+// its cues by cue list with the workspace's name, ID and basePath. Marshaled to JSON, the
+// envelope can be transmitted back with TransmitWorkspaceData.
+func (q *Workspace) ReceiveWorkspace(opts ...ReceiveOption) (*WorkspaceEnvelope, error) {
+	var options receiveOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	currentWorkspace, err := q.queryCurrentWorkspaceState()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query current workspace state: %v", err)
+	}
+
+	envelope := &WorkspaceEnvelope{Version: EnvelopeVersion, WorkspaceID: q.workspace_id, CueLists: []EnvelopeCueList{}}
+	if envelope.BasePath, err = q.getWorkspaceBasePath(); err != nil {
+		q.log().Debug("Exporting without the workspace basePath", "error", err)
+	}
+	if envelope.Name, err = q.queryWorkspaceName(); err != nil {
+		q.log().Debug("Exporting without the workspace name", "error", err)
+	}
+	root := options.pathRoot
+	if options.relativePaths && root == "" {
+		if envelope.BasePath == "" {
+			return nil, fmt.Errorf("failed to get workspace basePath for relative paths")
+		}
+		root = envelope.BasePath
+	}
+
+	lists, _ := currentWorkspace["data"].([]any)
+	for _, listData := range lists {
+		list, ok := listData.(map[string]any)
+		if !ok {
+			continue
+		}
+		cueList := EnvelopeCueList{Cues: []any{}}
+		cueList.Name, _ = list["name"].(string)
+		cueList.UniqueID, _ = list["uniqueID"].(string)
+		if cues, ok := list["cues"].([]any); ok {
+			cueList.Cues = cues
+		}
+		if options.relativePaths {
+			cueList.Cues = q.relativeFileTargets(cueList.Cues, root)
+		}
+		envelope.CueLists = append(envelope.CueLists, cueList)
+	}
+	return envelope, nil
+}
+
+// queryWorkspaceName returns the name QLab shows for the connected workspace, from /workspaces
+func (q *Workspace) queryWorkspaceName() (string, error) {
+	reply, err := q.Query("/workspaces")
+	if err != nil {
+		return "", err
+	}
+	var workspaces []struct {
+		DisplayName string `json:"displayName"`
+		UniqueID    string `json:"uniqueID"`
+	}
+	if err := reply.Decode(&workspaces); err != nil {
+		return "", err
+	}
+	for _, workspace := range workspaces {
+		if workspace.UniqueID == q.workspace_id {
+			return workspace.DisplayName, nil
+		}
+	}
+	return "", fmt.Errorf("workspace %s not in /workspaces", q.workspace_id)
+}
@@ -0,0 +1,98 @@
+package qlab
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zenibako/qlab-golang/messages"
+)
+
+// OpenWorkspaceFile has QLab open the workspace file at path, a path on the QLab machine,
+// and returns the ID of the opened workspace. Before Init, the workspace is then set to
+// connect to the opened workspace rather than whichever is frontmost. A file QLab can't open
+// returns the *QLabError of its reply; one that doesn't show up in /workspaces within the
+// timeout returns an error matching ErrWorkspaceNotFound.
+func (q *Workspace) OpenWorkspaceFile(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("no workspace file to open")
+	}
+	if !q.initialized {
+		// Without a connection QLab only replies to messages that return data
+		q.Send("/alwaysReply", "1")
+	}
+	if _, err := q.Query("/open", path); err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	// QLab opens the file asynchronously; it's listed once it has
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	deadline := time.Now().Add(time.Duration(q.timeout) * time.Second)
+	for {
+		workspaceID, err := q.findWorkspaceByName(name)
+		if err != nil {
+			return "", err
+		}
+		if workspaceID != "" {
+			q.log().Infof("Opened workspace %s (%s)", path, workspaceID)
+			if !q.initialized {
+				q.workspace_id = workspaceID
+				q.addressBuilder = messages.NewOSCAddressBuilder(workspaceID)
+			}
+			return workspaceID, nil
+		}
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("%w: %s didn't open", ErrWorkspaceNotFound, path)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// findWorkspaceByName returns the ID of the open workspace QLab shows under name, "" if
+// there's none
+func (q *Workspace) findWorkspaceByName(name string) (string, error) {
+	reply, err := q.Query("/workspaces")
+	if err != nil {
+		return "", fmt.Errorf("failed to list workspaces: %w", err)
+	}
+	var workspaces []struct {
+		DisplayName string `json:"displayName"`
+		UniqueID    string `json:"uniqueID"`
+	}
+	if err := reply.Decode(&workspaces); err != nil {
+		return "", err
+	}
+	for _, workspace := range workspaces {
+		if workspace.DisplayName == name {
+			return workspace.UniqueID, nil
+		}
+	}
+	return "", nil
+}
+
+// SaveWorkspace has QLab save the connected workspace to its file, or the frontmost
+// workspace before Init. A save QLab refuses returns the *QLabError of its reply.
+func (q *Workspace) SaveWorkspace() error {
+	if err := q.checkWritable("save workspace"); err != nil {
+		return err
+	}
+	if q.dryRun {
+		q.log().Infof("[DRY RUN] Would save the workspace")
+		return nil
+	}
+	if _, err := q.Query("/save"); err != nil {
+		return fmt.Errorf("failed to save workspace: %w", err)
+	}
+	q.log().Info("Saved workspace", "workspace_id", q.workspace_id)
+	return nil
+}
+
+// disconnect tells QLab the workspace's connection is closing, without waiting for a reply
+func (q *Workspace) disconnect() error {
+	if !q.initialized || q.workspace_id == "" {
+		return nil
+	}
+	q.initialized = false
+	return q.sendNoReply(fmt.Sprintf("/workspace/%s/disconnect", q.workspace_id))
+}
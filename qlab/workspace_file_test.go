@@ -0,0 +1,71 @@
+package qlab
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestWorkspaceFile tests opening a workspace file before connecting, saving it, and
+// disconnecting on Close
+func TestWorkspaceFile(t *testing.T) {
+	port, err := getFreePort()
+	if err != nil {
+		t.Fatalf("Failed to get free port: %v", err)
+	}
+	mockServer := NewMockOSCServer("localhost", port)
+	if err := mockServer.Start(); err != nil {
+		t.Fatalf("Failed to start mock server: %v", err)
+	}
+	workspace := NewWorkspace("localhost", port)
+	workspace.SetTimeout(1)
+	workspace.SetSkipInbox(true)
+	t.Cleanup(func() {
+		workspace.Close()
+		if err := mockServer.Stop(); err != nil {
+			t.Logf("Failed to stop mock server: %v", err)
+		}
+	})
+
+	var qlabErr *QLabError
+	if _, err := workspace.OpenWorkspaceFile("/Shows/notes.txt"); !errors.As(err, &qlabErr) {
+		t.Errorf("Expected QLab to refuse a file that isn't a workspace, got %v", err)
+	}
+	workspaceID, err := workspace.OpenWorkspaceFile("/Shows/Tour.qlab5")
+	if err != nil {
+		t.Fatalf("OpenWorkspaceFile failed: %v", err)
+	}
+	if workspaceID != mockServer.GetWorkspaceID() {
+		t.Errorf("Expected the opened workspace's ID, got %q", workspaceID)
+	}
+	if _, err := workspace.Init(""); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	if workspace.WorkspaceID() != workspaceID {
+		t.Errorf("Expected to connect to the opened workspace, got %s", workspace.WorkspaceID())
+	}
+
+	prefix := "/workspace/" + workspaceID
+	if err := workspace.SaveWorkspace(); err != nil {
+		t.Fatalf("SaveWorkspace failed: %v", err)
+	}
+	if saves := mockServer.GetMessagesForAddress(prefix + "/save"); len(saves) != 1 {
+		t.Errorf("Expected one save, got %d", len(saves))
+	}
+	workspace.SetReadOnly(true)
+	if err := workspace.SaveWorkspace(); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected a read-only workspace to refuse saving, got %v", err)
+	}
+
+	workspace.Close()
+	deadline := time.Now().Add(time.Second)
+	for len(mockServer.GetMessagesForAddress(prefix+"/disconnect")) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected Close to disconnect")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if workspace.IsConnected() {
+		t.Error("Expected the workspace disconnected after Close")
+	}
+}
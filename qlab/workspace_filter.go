@@ -0,0 +1,228 @@
+package qlab
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	// reasonNotSelected is the Reason of cues a selective transmit leaves alone
+	reasonNotSelected = "not selected for this transmit"
+	// reasonContainsSelected is the Reason of groups kept only to reach selected cues
+	reasonContainsSelected = "contains selected cues"
+)
+
+// CueSelector decides whether a source cue is transmitted. key is the cue's full number,
+// or its position key when it has none, and cueList is the name of its cue list.
+type CueSelector func(key, cueList string, cue map[string]any) bool
+
+// transmitFilter holds the criteria of a selective transmit; a cue must meet all of them
+type transmitFilter struct {
+	cueLists  map[string]bool
+	first     string
+	last      string
+	ranged    bool
+	selectors []CueSelector
+}
+
+// filtering returns the filter of a transmit, starting one when there isn't one yet
+func (o *transmitOptions) filtering() *transmitFilter {
+	if o.filter == nil {
+		o.filter = &transmitFilter{}
+	}
+	return o.filter
+}
+
+// WithCueLists transmits only the cues in the named cue lists. Only sources that define
+// their cue lists with list or cart cues can be selected this way.
+func WithCueLists(names ...string) TransmitOption {
+	return func(o *transmitOptions) {
+		filter := o.filtering()
+		if filter.cueLists == nil {
+			filter.cueLists = make(map[string]bool)
+		}
+		for _, name := range names {
+			filter.cueLists[name] = true
+		}
+	}
+}
+
+// WithCueRange transmits only the cues numbered from first to last, inclusive, such as
+// "10.0" to "20.0". An empty end leaves the range open on that side. Numbers compare part
+// by part, numerically where both parts are numbers, so 10.2 comes before 10.10.
+func WithCueRange(first, last string) TransmitOption {
+	return func(o *transmitOptions) {
+		filter := o.filtering()
+		filter.first, filter.last, filter.ranged = first, last, true
+	}
+}
+
+// WithCueFilter transmits only the cues selector accepts
+func WithCueFilter(selector CueSelector) TransmitOption {
+	return func(o *transmitOptions) {
+		filter := o.filtering()
+		filter.selectors = append(filter.selectors, selector)
+	}
+}
+
+// matches reports whether a cue meets every criterion of the selection
+func (f *transmitFilter) matches(key, fullNumber, cueList string, cue map[string]any) bool {
+	if len(f.cueLists) > 0 && !f.cueLists[cueList] {
+		return false
+	}
+	if f.ranged {
+		if fullNumber == "" {
+			return false
+		}
+		if f.first != "" && compareCueNumbers(fullNumber, f.first) < 0 {
+			return false
+		}
+		if f.last != "" && compareCueNumbers(fullNumber, f.last) > 0 {
+			return false
+		}
+	}
+	for _, selector := range f.selectors {
+		if !selector(key, cueList, cue) {
+			return false
+		}
+	}
+	return true
+}
+
+// selectCues returns the keys of the selected source cues, along with the groups that
+// aren't selected themselves but contain selected cues. The cues in a selected group are
+// selected along with it.
+func (f *transmitFilter) selectCues(sourceCueData map[string]any, keyOf cueKeyFunc) (selected, containers map[string]bool) {
+	selected = make(map[string]bool)
+	containers = make(map[string]bool)
+
+	var walk func(cues []any, parentNumber, cueList string, inherited bool) bool
+	walk = func(cues []any, parentNumber, cueList string, inherited bool) bool {
+		found := false
+		for i, cueData := range cues {
+			cue, ok := cueData.(map[string]any)
+			if !ok {
+				continue
+			}
+			key, fullNumber := keyOf(cue, parentNumber, i)
+			children, _ := cue["cues"].([]any)
+
+			switch cueType, _ := cue["type"].(string); strings.ToLower(cueType) {
+			case "list", "cart", CueTypeList:
+				name, _ := cue["name"].(string)
+				if walk(children, fullNumber, name, inherited) {
+					found = true
+				}
+				continue
+			}
+
+			isSelected := inherited || f.matches(key, fullNumber, cueList, cue)
+			if isSelected && key != "" {
+				selected[key] = true
+			}
+			if walk(children, fullNumber, cueList, isSelected) && !isSelected && key != "" {
+				containers[key] = true
+			}
+			if isSelected || containers[key] {
+				found = true
+			}
+		}
+		return found
+	}
+
+	if cues, ok := sourceCueData["cues"].([]any); ok {
+		walk(cues, "", "", false)
+	} else if nested, ok := sourceCueData["workspace"].(map[string]any); ok {
+		if cues, ok := nested["cues"].([]any); ok {
+			walk(cues, "", "", false)
+		}
+	}
+	return selected, containers
+}
+
+// applyTransmitFilter leaves the cues outside the selection as they are in QLab: they're
+// skipped, their conflicts dropped, and deletion sync doesn't apply. Existing groups that
+// contain selected cues are kept without being updated; new ones are created around them.
+func (q *Workspace) applyTransmitFilter(comparison *ThreeWayComparison, sourceCueData map[string]any, filter *transmitFilter) {
+	selected, containers := filter.selectCues(sourceCueData, q.sourceCueKey)
+	restrictComparison(comparison, selected, containers)
+	q.log().Infof("Selective transmit: %d of %d cues selected", len(selected), len(comparison.CueResults))
+}
+
+// restrictComparison leaves the cues of a comparison outside selected as they are, keeping
+// the containers of selected cues like applyTransmitFilter
+func restrictComparison(comparison *ThreeWayComparison, selected, containers map[string]bool) {
+	for key, result := range comparison.CueResults {
+		switch {
+		case result == nil || selected[key]:
+			continue
+		case result.Action == "delete":
+			delete(comparison.CueResults, key)
+		case containers[key] && result.ExistingID == "":
+			continue
+		default:
+			reason, action := reasonNotSelected, "skip"
+			if containers[key] {
+				reason, action = reasonContainsSelected, "keep"
+			}
+			comparison.CueResults[key] = &CueChangeResult{
+				Action:         action,
+				Reason:         reason,
+				ExistingID:     result.ExistingID,
+				CueID:          result.CueID,
+				ModifiedFields: make(map[string]string),
+				FieldConflicts: make(map[string]*FieldConflict),
+			}
+		}
+	}
+	clearUnselectedConflicts(comparison.WorkspaceScope, selected)
+}
+
+// clearUnselectedConflicts drops the conflicts of cue scopes outside the selection
+func clearUnselectedConflicts(scope *ScopeComparison, selected map[string]bool) {
+	if scope == nil {
+		return
+	}
+	if scope.Scope == ScopeCue && !selected[scope.Identifier] {
+		scope.HasChanges = false
+		scope.ConflictExists = false
+	}
+	for _, child := range scope.ChildScopes {
+		clearUnselectedConflicts(child, selected)
+	}
+}
+
+// compareCueNumbers orders cue numbers part by part, numerically where both parts are
+// numbers, returning -1, 0 or 1
+func compareCueNumbers(a, b string) int {
+	partsA, partsB := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(partsA) && i < len(partsB); i++ {
+		numberA, errA := strconv.ParseFloat(partsA[i], 64)
+		numberB, errB := strconv.ParseFloat(partsB[i], 64)
+		switch {
+		case errA == nil && errB == nil && numberA != numberB:
+			if numberA < numberB {
+				return -1
+			}
+			return 1
+		case (errA != nil || errB != nil) && partsA[i] != partsB[i]:
+			return strings.Compare(partsA[i], partsB[i])
+		}
+	}
+	// Trailing zero parts don't count, so 10 and 10.0 are the same cue
+	trailing := func(parts []string) bool {
+		for _, part := range parts {
+			if n, err := strconv.ParseFloat(part, 64); err != nil || n != 0 {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case len(partsA) > len(partsB) && trailing(partsA[len(partsB):]):
+		return 1
+	case len(partsB) > len(partsA) && trailing(partsB[len(partsA):]):
+		return -1
+	}
+	return 0
+}
@@ -0,0 +1,153 @@
+package qlab
+
+import (
+	"fmt"
+	"strings"
+)
+
+// cueIDIndex maps each uniqueID among indexed cues to the cue's change detection key
+func cueIDIndex(cues map[string]map[string]any) map[string]string {
+	index := make(map[string]string, len(cues))
+	for key, cue := range cues {
+		if id, _ := cue["uniqueID"].(string); id != "" {
+			index[id] = key
+		}
+	}
+	return index
+}
+
+// keyNumber returns the cue number a change detection key stands for, or "" for the
+// position key of a cue without a number
+func keyNumber(key string) string {
+	if strings.Contains(key, "[") && strings.HasSuffix(key, "]") {
+		return ""
+	}
+	return key
+}
+
+// matchCueIdentities returns the uniqueID each source cue is known by, so that a cue keeps
+// its identity when its number or name changes. The QLab cue stamped with the source cue's
+// external ID comes first, then the source cue's own "uniqueID", then the identity the cache
+// recorded for its key. A source cue whose key matches nothing is paired with the one cached
+// cue of the same type that left the source and shares its name or file target. Source cues
+// not in the result are matched by key.
+func (q *Workspace) matchCueIdentities(sourceCues, cachedCues, currentCues map[string]map[string]any, meta *CacheMetadata) map[string]string {
+	identities := make(map[string]string)
+	claimed := make(map[string]bool)
+	stamped := q.externalIDIndex(currentCues)
+	for key, sourceCue := range sourceCues {
+		id := stamped[q.sourceExternalID(sourceCue)]
+		if id == "" {
+			id, _ = sourceCue["uniqueID"].(string)
+		}
+		if id == "" && meta != nil {
+			id = meta.Identities[key]
+		}
+		if id != "" {
+			identities[key] = id
+			claimed[id] = true
+		}
+	}
+	for key, cachedCue := range cachedCues {
+		if _, inSource := sourceCues[key]; inSource {
+			if id, _ := cachedCue["uniqueID"].(string); id != "" && identities[key] == "" {
+				claimed[id] = true
+			}
+		}
+	}
+
+	for key, sourceCue := range sourceCues {
+		if identities[key] != "" {
+			continue
+		}
+		if _, cached := cachedCues[key]; cached {
+			continue
+		}
+		var candidates []string
+		for cachedKey, cachedCue := range cachedCues {
+			id, _ := cachedCue["uniqueID"].(string)
+			if _, inSource := sourceCues[cachedKey]; inSource || id == "" || claimed[id] {
+				continue
+			}
+			if sameCueIdentity(sourceCue, cachedCue) {
+				candidates = append(candidates, id)
+			}
+		}
+		if len(candidates) == 1 {
+			q.log().Debugf("Cue %s matched cached cue %s by name or file target", key, candidates[0])
+			identities[key] = candidates[0]
+			claimed[candidates[0]] = true
+		}
+	}
+	return identities
+}
+
+// sameCueIdentity reports whether a cached cue that left the source looks like the same cue
+// as a source cue: the same type, and the same name or file target
+func sameCueIdentity(sourceCue, cachedCue map[string]any) bool {
+	sourceType, _ := sourceCue["type"].(string)
+	cachedType, _ := cachedCue["type"].(string)
+	if !strings.EqualFold(sourceType, cachedType) {
+		return false
+	}
+	for _, property := range []string{"name", "fileTarget"} {
+		value, _ := sourceCue[property].(string)
+		if cached, _ := cachedCue[property].(string); value != "" && value == cached {
+			return true
+		}
+	}
+	return false
+}
+
+// identifiedCue finds a cue by uniqueID when the source cue has a known identity, and by
+// key otherwise. It returns the key the cue has there.
+func identifiedCue(cues map[string]map[string]any, byID map[string]string, id, key string) (map[string]any, string, bool) {
+	if id != "" {
+		if found, ok := byID[id]; ok {
+			return cues[found], found, true
+		}
+	}
+	cue, ok := cues[key]
+	return cue, key, ok
+}
+
+// markRenumbered records that a cue matched by uniqueID has another key in QLab. A cue whose
+// number changed gets a "number" modification and is updated even if nothing else changed.
+func markRenumbered(result *CueChangeResult, key, currentKey string, currentCue map[string]any) {
+	if currentKey == key {
+		return
+	}
+	result.PreviousKey = currentKey
+	number, current := keyNumber(key), formatCueNumber(currentCue["number"])
+	if number == current {
+		return
+	}
+	if result.ModifiedFields == nil {
+		result.ModifiedFields = make(map[string]string)
+	}
+	result.ModifiedFields["number"] = fmt.Sprintf("'%s' -> '%s'", current, number)
+	if result.Action == "skip" {
+		result.HasChanged = true
+		result.Action = "update"
+		result.Reason = "renumbered in source"
+	}
+}
+
+// cueIdentities returns the uniqueID of each source cue after a transmit, for the cache to
+// remember which QLab cue each source cue became
+func (q *Workspace) cueIdentities(source, snapshot map[string]any, comparison *ThreeWayComparison) map[string]string {
+	snapshotCues := q.indexCuesFromWorkspace(snapshot)
+	identities := make(map[string]string)
+	for key := range q.indexCuesFromWorkspace(source) {
+		if comparison != nil {
+			if result := comparison.CueResults[key]; result != nil && result.Action != "delete" && result.ExistingID != "" {
+				identities[key] = result.ExistingID
+				continue
+			}
+		}
+		if id, _ := snapshotCues[key]["uniqueID"].(string); id != "" {
+			identities[key] = id
+		}
+	}
+	return identities
+}
@@ -0,0 +1,195 @@
+package qlab
+
+import (
+	"fmt"
+	"sort"
+)
+
+// IndexDivergence is an entry in the in-memory cue indexes that disagrees with QLab
+type IndexDivergence struct {
+	Index     string // "cueNumbers" or "cueListNames"
+	Key       string // Cue number or cue list name
+	IndexedID string // uniqueID in the in-memory index, "" if missing
+	QLabID    string // uniqueID in QLab, "" if the key no longer exists
+}
+
+func (d IndexDivergence) String() string {
+	switch {
+	case d.IndexedID == "":
+		return fmt.Sprintf("%s %q missing from index (QLab has %s)", d.Index, d.Key, d.QLabID)
+	case d.QLabID == "":
+		return fmt.Sprintf("%s %q indexed as %s but not in QLab", d.Index, d.Key, d.IndexedID)
+	default:
+		return fmt.Sprintf("%s %q indexed as %s but QLab has %s", d.Index, d.Key, d.IndexedID, d.QLabID)
+	}
+}
+
+// IndexReport is the result of ValidateIndexes
+type IndexReport struct {
+	Divergences []IndexDivergence
+	CueNumbers  int // Cue numbers in QLab
+	CueLists    int // Named cue lists in QLab
+}
+
+// OK reports whether the indexes matched QLab
+func (r *IndexReport) OK() bool {
+	return len(r.Divergences) == 0
+}
+
+// ValidateIndexes re-queries QLab and compares it with the in-memory cue number and
+// cue list name indexes used for conflict detection, which can drift from QLab after
+// a failed or aborted sync. Divergences are reported and the indexes are replaced with
+// QLab's current state. TransmitWorkspaceData calls this automatically when it fails.
+func (q *Workspace) ValidateIndexes() (*IndexReport, error) {
+	if q.workspace_id == "" {
+		return nil, fmt.Errorf("workspace ID is required for index validation but not available")
+	}
+
+	// Bypass the cached cue lists; the cache may predate the failure
+	q.InvalidateCueCache()
+	data, err := q.getCueLists()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cue lists: %v", err)
+	}
+
+	cueNumbers := make(map[string]string)
+	cueListNames := make(map[string]string)
+	for _, cueListData := range data {
+		cueList, ok := cueListData.(map[string]any)
+		if !ok {
+			continue
+		}
+		if name, ok := cueList["name"].(string); ok && name != "" {
+			if uniqueID, ok := cueList["uniqueID"].(string); ok {
+				cueListNames[name] = uniqueID
+			}
+		}
+		if cues, ok := cueList["cues"].([]any); ok {
+			indexCueNumbersInto(cues, cueNumbers, q.log())
+		}
+	}
+
+	report := &IndexReport{
+		CueNumbers: len(cueNumbers),
+		CueLists:   len(cueListNames),
+	}
+	q.stateMu.Lock()
+	report.Divergences = append(report.Divergences, compareIndex("cueNumbers", q.cueNumbers, cueNumbers)...)
+	report.Divergences = append(report.Divergences, compareIndex("cueListNames", q.cueListNames, cueListNames)...)
+	q.cueNumbers = cueNumbers
+	q.cueListNames = cueListNames
+	q.stateMu.Unlock()
+
+	if report.OK() {
+		q.log().Debug("Cue indexes match QLab", "cue_numbers", report.CueNumbers, "cue_lists", report.CueLists)
+	} else {
+		q.log().Warnf("Repaired %d cue index divergences from QLab", len(report.Divergences))
+	}
+	return report, nil
+}
+
+// lookupCueNumber returns the cue indexed under a cue number
+func (q *Workspace) lookupCueNumber(number string) (string, bool) {
+	q.stateMu.Lock()
+	defer q.stateMu.Unlock()
+	uniqueID, ok := q.cueNumbers[number]
+	return uniqueID, ok
+}
+
+// recordCueNumber indexes a cue under its number
+func (q *Workspace) recordCueNumber(number, uniqueID string) {
+	q.stateMu.Lock()
+	defer q.stateMu.Unlock()
+	if q.cueNumbers == nil {
+		q.cueNumbers = make(map[string]string)
+	}
+	q.cueNumbers[number] = uniqueID
+}
+
+// forgetCueNumber removes a cue number from the index
+func (q *Workspace) forgetCueNumber(number string) {
+	q.stateMu.Lock()
+	defer q.stateMu.Unlock()
+	delete(q.cueNumbers, number)
+}
+
+// lookupCueList returns the cue list indexed under a name, or "" if there is none
+func (q *Workspace) lookupCueList(name string) string {
+	q.stateMu.Lock()
+	defer q.stateMu.Unlock()
+	return q.cueListNames[name]
+}
+
+// recordCueList indexes a cue list under its name
+func (q *Workspace) recordCueList(name, uniqueID string) {
+	q.stateMu.Lock()
+	defer q.stateMu.Unlock()
+	if q.cueListNames == nil {
+		q.cueListNames = make(map[string]string)
+	}
+	q.cueListNames[name] = uniqueID
+}
+
+// forgetCueList removes a cue list from the name index
+func (q *Workspace) forgetCueList(name string) {
+	q.stateMu.Lock()
+	defer q.stateMu.Unlock()
+	delete(q.cueListNames, name)
+}
+
+// isCueListID reports whether a uniqueID is an indexed cue list
+func (q *Workspace) isCueListID(uniqueID string) bool {
+	q.stateMu.Lock()
+	defer q.stateMu.Unlock()
+	for _, listID := range q.cueListNames {
+		if listID == uniqueID {
+			return true
+		}
+	}
+	return false
+}
+
+// setInboxID records the ID of the Cuejitsu Inbox cue list
+func (q *Workspace) setInboxID(inboxID string) {
+	q.stateMu.Lock()
+	defer q.stateMu.Unlock()
+	q.inboxID = inboxID
+}
+
+// getInboxID returns the ID of the Cuejitsu Inbox cue list, or "" if it isn't known yet
+func (q *Workspace) getInboxID() string {
+	q.stateMu.Lock()
+	defer q.stateMu.Unlock()
+	return q.inboxID
+}
+
+// compareIndex returns the keys whose uniqueIDs differ between an index and QLab, sorted by key
+func compareIndex(name string, indexed, actual map[string]string) []IndexDivergence {
+	var divergences []IndexDivergence
+	for key, indexedID := range indexed {
+		if qlabID := actual[key]; qlabID != indexedID {
+			divergences = append(divergences, IndexDivergence{Index: name, Key: key, IndexedID: indexedID, QLabID: qlabID})
+		}
+	}
+	for key, qlabID := range actual {
+		if _, ok := indexed[key]; !ok {
+			divergences = append(divergences, IndexDivergence{Index: name, Key: key, QLabID: qlabID})
+		}
+	}
+	sort.Slice(divergences, func(i, j int) bool {
+		return divergences[i].Key < divergences[j].Key
+	})
+	return divergences
+}
+
+// repairIndexesAfterFailure resynchronizes the cue indexes after an error-terminated transmit
+func (q *Workspace) repairIndexesAfterFailure() {
+	report, err := q.ValidateIndexes()
+	if err != nil {
+		q.log().Warnf("Failed to validate cue indexes after failed transmit: %v", err)
+		return
+	}
+	for _, divergence := range report.Divergences {
+		q.log().Debug("Repaired cue index divergence", "divergence", divergence.String())
+	}
+}
@@ -0,0 +1,132 @@
+package qlab
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// SetAlwaysAudition turns the workspace's always-audition mode on or off. While it is on,
+// every GO auditions cues through the audition patch instead of the main outputs.
+func (q *Workspace) SetAlwaysAudition(enabled bool) error {
+	value := "0"
+	if enabled {
+		value = "1"
+	}
+	reply := q.Send(q.GetAddress("/alwaysAudition"), value)
+	if err := checkReplyStatus(reply); err != nil {
+		return fmt.Errorf("failed to set alwaysAudition: %w", err)
+	}
+	return nil
+}
+
+// AlwaysAudition reports whether the workspace's always-audition mode is on
+func (q *Workspace) AlwaysAudition() (bool, error) {
+	reply := q.Send(q.GetAddress("/alwaysAudition"), "")
+	if err := checkReplyStatus(reply); err != nil {
+		return false, fmt.Errorf("failed to query alwaysAudition: %w", err)
+	}
+	value, ok := replyDataValue(reply)
+	if !ok {
+		return false, fmt.Errorf("no data in alwaysAudition reply")
+	}
+	return toBool(value), nil
+}
+
+// SetCueMasterLevel sets a cue's master audio level in decibels. It waits for a running
+// transmit or batch to finish, so the change is never queued into another caller's batch.
+func (q *Workspace) SetCueMasterLevel(uniqueID string, db float64) error {
+	q.opMu.Lock()
+	defer q.opMu.Unlock()
+	return q.setCueMasterLevel(uniqueID, db)
+}
+
+// setCueMasterLevel sets a cue's master audio level; q.opMu must be held
+func (q *Workspace) setCueMasterLevel(uniqueID string, db float64) error {
+	if err := q.setCueProperty(uniqueID, "masterLevel", strconv.FormatFloat(db, 'g', -1, 64)); err != nil {
+		return fmt.Errorf("failed to set master level of cue %s: %w", uniqueID, err)
+	}
+	return nil
+}
+
+// CueMasterLevel returns a cue's master audio level in decibels
+func (q *Workspace) CueMasterLevel(uniqueID string) (float64, error) {
+	value, ok := q.queryCueValue(uniqueID, "masterLevel")
+	if !ok {
+		return 0, fmt.Errorf("failed to query master level of cue %s", uniqueID)
+	}
+	db, ok := toFloat(value)
+	if !ok {
+		return 0, fmt.Errorf("master level of cue %s is not a number: %v", uniqueID, value)
+	}
+	return db, nil
+}
+
+// Dim lowers the master level of every running cue by db decibels, e.g. to talk over
+// playback during a notes session. QLab has no workspace master fader over OSC, so the
+// running cues stand in for it; cues without audio levels are skipped. Original levels
+// are remembered until Undim, and dimming again while dimmed only affects newly running cues.
+// It returns the number of cues that were dimmed, and waits for a running transmit or batch.
+func (q *Workspace) Dim(db float64) (int, error) {
+	if db < 0 {
+		db = -db
+	}
+
+	q.opMu.Lock()
+	defer q.opMu.Unlock()
+	q.dimMu.Lock()
+	defer q.dimMu.Unlock()
+
+	if q.dimmedLevels == nil {
+		q.dimmedLevels = make(map[string]float64)
+	}
+
+	dimmed := 0
+	for _, cue := range q.GetRunningCues() {
+		uniqueID, _ := cue["uniqueID"].(string)
+		if uniqueID == "" {
+			continue
+		}
+		if _, already := q.dimmedLevels[uniqueID]; already {
+			continue
+		}
+
+		level, err := q.CueMasterLevel(uniqueID)
+		if err != nil {
+			q.log().Debugf("Not dimming cue %s: %v", uniqueID, err)
+			continue
+		}
+		if err := q.setCueMasterLevel(uniqueID, level-db); err != nil {
+			return dimmed, err
+		}
+		q.dimmedLevels[uniqueID] = level
+		dimmed++
+	}
+
+	q.log().Infof("Dimmed %d running cues by %g dB", dimmed, db)
+	return dimmed, nil
+}
+
+// Undim restores the master levels changed by Dim. Cues that can no longer be reached are
+// forgotten; the returned error reports the first failure.
+func (q *Workspace) Undim() error {
+	q.opMu.Lock()
+	defer q.opMu.Unlock()
+	q.dimMu.Lock()
+	defer q.dimMu.Unlock()
+
+	var firstErr error
+	for uniqueID, level := range q.dimmedLevels {
+		if err := q.setCueMasterLevel(uniqueID, level); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	q.dimmedLevels = nil
+	return firstErr
+}
+
+// IsDimmed reports whether Dim has lowered any cue levels that have not been restored
+func (q *Workspace) IsDimmed() bool {
+	q.dimMu.Lock()
+	defer q.dimMu.Unlock()
+	return len(q.dimmedLevels) > 0
+}
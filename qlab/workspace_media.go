@@ -0,0 +1,182 @@
+package qlab
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// MediaProblemKind classifies a problem found by CheckMedia
+type MediaProblemKind string
+
+const (
+	MediaMissing     MediaProblemKind = "missing"     // No file at the path
+	MediaUnreadable  MediaProblemKind = "unreadable"  // The file exists but can't be opened
+	MediaNotFile     MediaProblemKind = "not_file"    // The path is a directory or other non-regular file
+	MediaEmpty       MediaProblemKind = "empty"       // The file is zero bytes
+	MediaUnsupported MediaProblemKind = "unsupported" // The extension isn't a format the cue type plays
+)
+
+// mediaExtensions are the file extensions QLab plays, by cue type
+var mediaExtensions = map[string][]string{
+	CueTypeAudio:    {".aif", ".aifc", ".aiff", ".caf", ".flac", ".m4a", ".mp3", ".aac", ".mp4", ".ogg", ".wav", ".wave"},
+	CueTypeVideo:    {".mov", ".mp4", ".m4v", ".avi", ".mkv", ".png", ".jpg", ".jpeg", ".gif", ".tif", ".tiff", ".psd", ".pdf", ".heic", ".bmp", ".webp"},
+	CueTypeMIDIFile: {".mid", ".midi", ".smf"},
+}
+
+// MediaFile is a file target that checked out
+type MediaFile struct {
+	Cue        string // Full cue number, or position key for unnumbered cues
+	FileTarget string // As written in the source
+	Path       string // Absolute path that was checked
+	Extension  string // Lowercase, with the leading dot
+	Size       int64  // In bytes
+}
+
+// MediaProblem is a file target that would leave its cue without media
+type MediaProblem struct {
+	Kind       MediaProblemKind
+	Cue        string
+	Name       string
+	FileTarget string
+	Path       string
+	Err        error // Underlying error for missing and unreadable files
+}
+
+func (p MediaProblem) String() string {
+	label := "cue " + p.Cue
+	if p.Name != "" {
+		label += fmt.Sprintf(" %q", p.Name)
+	}
+	switch p.Kind {
+	case MediaMissing:
+		return fmt.Sprintf("%s: file not found: %s", label, p.Path)
+	case MediaUnreadable:
+		return fmt.Sprintf("%s: can't read %s: %v", label, p.Path, p.Err)
+	case MediaNotFile:
+		return fmt.Sprintf("%s: not a file: %s", label, p.Path)
+	case MediaEmpty:
+		return fmt.Sprintf("%s: empty file: %s", label, p.Path)
+	default:
+		return fmt.Sprintf("%s: unsupported file type %q: %s", label, filepath.Ext(p.Path), p.Path)
+	}
+}
+
+// MediaReport is the result of CheckMedia
+type MediaReport struct {
+	Files    []MediaFile    // File targets that checked out, in workspace order
+	Problems []MediaProblem // File targets that didn't, in workspace order
+}
+
+// OK reports whether every file target checked out
+func (r *MediaReport) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// MediaError is returned by TransmitWorkspaceData when media preflight finds problems
+type MediaError struct {
+	Problems []MediaProblem
+}
+
+func (e *MediaError) Error() string {
+	details := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		details[i] = problem.String()
+	}
+	return fmt.Sprintf("%d media problems: %s", len(e.Problems), strings.Join(details, "; "))
+}
+
+// SetMediaPreflight sets whether TransmitWorkspaceData checks every file target with
+// CheckMedia and refuses to transmit, before any cue is created, when one is bad
+func (q *Workspace) SetMediaPreflight(enabled bool) {
+	q.mediaPreflight = enabled
+}
+
+// CheckMedia checks that the file target of every cue in source data is a readable,
+// non-empty file with an extension its cue type plays. Relative paths resolve against the
+// directory of filePath, as when transmitting, or against the workspace's folder when
+// filePath is empty. Files are checked on this machine, so it should be the one running QLab
+// or share its media paths.
+func (q *Workspace) CheckMedia(filePath string, workspaceData map[string]any) (*MediaReport, error) {
+	dir := ""
+	if filePath != "" {
+		absFilePath, err := filepath.Abs(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get absolute path: %v", err)
+		}
+		dir = filepath.Dir(absFilePath)
+	}
+
+	report := &MediaReport{}
+	for _, cue := range q.collectTargetChecks(workspaceData) {
+		if cue.fileTarget == "" {
+			continue
+		}
+		path := cue.fileTarget
+		if !filepath.IsAbs(path) {
+			if dir != "" {
+				path = filepath.Join(dir, path)
+			} else {
+				resolved, err := q.resolveFilePath(path)
+				if err != nil {
+					return nil, err
+				}
+				path = resolved
+			}
+		}
+
+		file, problem := checkMediaFile(path, cue.cueType)
+		if problem != "" {
+			report.Problems = append(report.Problems, MediaProblem{
+				Kind: problem, Cue: cue.key, Name: cue.name, FileTarget: cue.fileTarget, Path: path, Err: file.err,
+			})
+			continue
+		}
+		report.Files = append(report.Files, MediaFile{
+			Cue: cue.key, FileTarget: cue.fileTarget, Path: path, Extension: file.extension, Size: file.size,
+		})
+	}
+	return report, nil
+}
+
+// mediaStat is what checkMediaFile learned about a file
+type mediaStat struct {
+	extension string
+	size      int64
+	err       error
+}
+
+// checkMediaFile stats and opens a file, returning the problem with it if there is one
+func checkMediaFile(path, cueType string) (mediaStat, MediaProblemKind) {
+	stat := mediaStat{extension: strings.ToLower(filepath.Ext(path))}
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		stat.err = err
+		return stat, MediaMissing
+	}
+	if err != nil {
+		stat.err = err
+		return stat, MediaUnreadable
+	}
+	if !info.Mode().IsRegular() {
+		return stat, MediaNotFile
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		stat.err = err
+		return stat, MediaUnreadable
+	}
+	file.Close()
+
+	stat.size = info.Size()
+	if stat.size == 0 {
+		return stat, MediaEmpty
+	}
+	if extensions, ok := mediaExtensions[cueType]; ok && !slices.Contains(extensions, stat.extension) {
+		return stat, MediaUnsupported
+	}
+	return stat, ""
+}
@@ -0,0 +1,133 @@
+package qlab
+
+import (
+	"fmt"
+	"slices"
+)
+
+// MergeProvenance records where each field of merged workspace data came from: cue
+// identifier -> field -> "source" or "qlab", like MergedScope.SourceFields
+type MergeProvenance map[string]map[string]string
+
+// MergeToWorkspaceData returns the source workspace data of a comparison with the conflict
+// resolutions applied, ready to be written back to a source file with WriteCueFile or
+// ToJSON. See MergeToWorkspaceDataWithProvenance.
+func (q *Workspace) MergeToWorkspaceData(comparison *ThreeWayComparison) (map[string]any, error) {
+	merged, _, err := q.MergeToWorkspaceDataWithProvenance(comparison)
+	return merged, err
+}
+
+// MergeToWorkspaceDataWithProvenance merges like MergeToWorkspaceData and also returns
+// where every field came from. The source data keeps its structure and order. A cue kept
+// from QLab (QLabChosenCues) takes QLab's values for the fields the source sets and the
+// fields the scope comparison found changed; a field kept from QLab (QLabChosenFields)
+// takes QLab's value alone. Everything else comes from the source. Cues only in QLab are
+// left out, and the source data is not modified.
+func (q *Workspace) MergeToWorkspaceDataWithProvenance(comparison *ThreeWayComparison) (map[string]any, MergeProvenance, error) {
+	if comparison == nil {
+		return nil, nil, fmt.Errorf("comparison is nil")
+	}
+	if comparison.SourceData == nil {
+		return nil, nil, fmt.Errorf("comparison has no source data to merge into")
+	}
+
+	merged, _ := cloneMergeValue(comparison.SourceData).(map[string]any)
+	qlabCues := q.indexCuesFromWorkspace(comparison.CurrentQLabData)
+	changedFields := make(map[string]map[string]*FieldConflict)
+	if comparison.WorkspaceScope != nil {
+		for _, scope := range comparison.WorkspaceScope.ChildScopes {
+			changedFields[scope.Identifier] = scope.FieldChanges
+		}
+	}
+	provenance := make(MergeProvenance)
+
+	var walk func(cues []any, parentNumber string)
+	walk = func(cues []any, parentNumber string) {
+		for i, cueData := range cues {
+			cue, ok := cueData.(map[string]any)
+			if !ok {
+				continue
+			}
+			key, fullNumber := q.sourceCueKey(cue, parentNumber, i)
+			if key != "" {
+				provenance[key] = q.mergeCue(cue, qlabCues[key], comparison.QLabChosenCues[key], comparison.QLabChosenFields[key], changedFields[key])
+			}
+			if children, ok := cue["cues"].([]any); ok {
+				walk(children, fullNumber)
+			}
+		}
+	}
+	if cues, ok := merged["cues"].([]any); ok {
+		walk(cues, "")
+	} else if nested, ok := merged["workspace"].(map[string]any); ok {
+		if cues, ok := nested["cues"].([]any); ok {
+			walk(cues, "")
+		}
+	}
+
+	q.log().Debugf("Merged workspace data for %d cues", len(provenance))
+	return merged, provenance, nil
+}
+
+// mergeCue applies the QLab choices for one cue to its source data in place and returns the
+// provenance of its fields
+func (q *Workspace) mergeCue(cue, qlabCue map[string]any, keepCue bool, keepFields map[string]bool, changed map[string]*FieldConflict) map[string]string {
+	fields := make(map[string]string, len(cue))
+	for field := range cue {
+		if field != "cues" {
+			fields[field] = "source"
+		}
+	}
+	if qlabCue == nil {
+		return fields
+	}
+
+	candidates := make([]string, 0, len(keepFields))
+	for field, keep := range keepFields {
+		if keep {
+			candidates = append(candidates, field)
+		}
+	}
+	if keepCue {
+		for field := range cue {
+			candidates = append(candidates, field)
+		}
+		for field := range changed {
+			candidates = append(candidates, field)
+		}
+	}
+	slices.Sort(candidates)
+
+	for _, field := range slices.Compact(candidates) {
+		// Identity and structure stay as the source has them
+		if field == "cues" || field == "uniqueID" {
+			continue
+		}
+		value, ok := qlabCue[field]
+		if !ok || q.compareField(field, cue, nil, qlabCue, false) == nil {
+			continue
+		}
+		cue[field] = cloneMergeValue(value)
+		fields[field] = "qlab"
+	}
+	return fields
+}
+
+// cloneMergeValue deep-copies the maps and slices of workspace data
+func cloneMergeValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		clone := make(map[string]any, len(v))
+		for key, item := range v {
+			clone[key] = cloneMergeValue(item)
+		}
+		return clone
+	case []any:
+		clone := make([]any, len(v))
+		for i, item := range v {
+			clone[i] = cloneMergeValue(item)
+		}
+		return clone
+	}
+	return value
+}
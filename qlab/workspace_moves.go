@@ -0,0 +1,258 @@
+package qlab
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CueMove says where a cue that changed position in the source goes in QLab
+type CueMove struct {
+	ParentID string // Unique ID of the group or cue list the cue belongs in
+	Parent   string // Key of the group, or "list:" and the name of the cue list
+	AfterID  string // Unique ID of the cue it follows, empty to place it first
+	sequence int    // Depth-first position in the source, moves are applied in this order
+}
+
+// cueOrder records where each cue sits in workspace data. Cue list parents are named
+// "list:" and the list name, group parents by the group's change detection key.
+type cueOrder struct {
+	parents  map[string]string   // Parent of each cue
+	children map[string][]string // Cues of each parent, in order
+	ids      map[string]string   // Unique ID of each parent, when the data has one
+	sequence map[string]int      // Depth-first position of each cue
+}
+
+// indexCueOrder walks source or QLab data the same way as indexScopeHierarchy. Cues at
+// the top of a source without cue lists have no parent and are left out.
+func (q *Workspace) indexCueOrder(workspace map[string]any) *cueOrder {
+	order := &cueOrder{
+		parents:  make(map[string]string),
+		children: make(map[string][]string),
+		ids:      make(map[string]string),
+		sequence: make(map[string]int),
+	}
+
+	var walk func(cues []any, parentNumber, parent string)
+	walk = func(cues []any, parentNumber, parent string) {
+		for i, cueData := range cues {
+			cue, ok := cueData.(map[string]any)
+			if !ok {
+				continue
+			}
+			key, fullNumber := q.sourceCueKey(cue, parentNumber, i)
+			childParent := key
+			switch cueType, _ := cue["type"].(string); strings.ToLower(cueType) {
+			case "list", "cart", CueTypeList:
+				name, _ := cue["name"].(string)
+				childParent = "list:" + name
+			default:
+				if key != "" && parent != "" {
+					if _, seen := order.parents[key]; !seen {
+						order.parents[key] = parent
+						order.children[parent] = append(order.children[parent], key)
+						order.sequence[key] = len(order.sequence)
+					}
+				}
+			}
+			if id, _ := cue["uniqueID"].(string); id != "" && childParent != "" {
+				order.ids[childParent] = id
+			}
+			if children, ok := cue["cues"].([]any); ok && childParent != "" {
+				walk(children, fullNumber, childParent)
+			}
+		}
+	}
+
+	if cues, ok := workspace["cues"].([]any); ok {
+		walk(cues, "", "")
+	} else if nested, ok := workspace["workspace"].(map[string]any); ok {
+		if cues, ok := nested["cues"].([]any); ok {
+			walk(cues, "", "")
+		}
+	} else if cueLists, ok := workspace["data"].([]any); ok {
+		for _, cueListData := range cueLists {
+			cueList, ok := cueListData.(map[string]any)
+			if !ok {
+				continue
+			}
+			name, _ := cueList["name"].(string)
+			if id, _ := cueList["uniqueID"].(string); id != "" {
+				order.ids["list:"+name] = id
+			}
+			if cues, ok := cueList["cues"].([]any); ok {
+				walk(cues, "", "list:"+name)
+			}
+		}
+	}
+	return order
+}
+
+// detectMoves gives each cue already in QLab whose place differs from the source a Move.
+// Within each parent, the longest run of cues that QLab already has in source order stays
+// put and the rest are moved around it. Unchanged cues that move get the "move" action so
+// nothing else about them is touched; updated cues keep "update" and are moved as well.
+func (q *Workspace) detectMoves(comparison *ThreeWayComparison, sourceCueData map[string]any) {
+	if !comparison.HasQLabData {
+		return
+	}
+	source := q.indexCueOrder(sourceCueData)
+	current := q.indexCueOrder(comparison.CurrentQLabData)
+
+	// Cues matched by uniqueID under another key sit in QLab under their old key
+	sourceKeys := make(map[string]string)
+	for key, result := range comparison.CueResults {
+		if result.PreviousKey != "" {
+			sourceKeys[result.PreviousKey] = key
+		}
+	}
+
+	for parent, keys := range source.children {
+		parentID := current.ids[parent]
+		if parentID == "" {
+			continue // The parent is new, and its cues are placed as it's created
+		}
+
+		// Cues of this parent that are already in QLab, in source order
+		var placed []string
+		positions := make(map[string]int)
+		for _, key := range keys {
+			result := comparison.CueResults[key]
+			if result == nil || result.ExistingID == "" || (result.Action != "skip" && result.Action != "update") {
+				continue
+			}
+			positions[key] = len(placed)
+			placed = append(placed, key)
+		}
+
+		// Source positions of the ones QLab already has in this parent, in QLab's order
+		var inParent []string
+		var sequence []int
+		for _, key := range current.children[parent] {
+			if sourceKey, ok := sourceKeys[key]; ok {
+				key = sourceKey
+			}
+			if position, ok := positions[key]; ok {
+				inParent = append(inParent, key)
+				sequence = append(sequence, position)
+			}
+		}
+		stable := make(map[string]bool)
+		for _, i := range longestIncreasingSubsequence(sequence) {
+			stable[inParent[i]] = true
+		}
+
+		for i, key := range placed {
+			if stable[key] {
+				continue
+			}
+			result := comparison.CueResults[key]
+			result.Move = &CueMove{ParentID: parentID, Parent: parent, sequence: source.sequence[key]}
+			if i > 0 {
+				result.Move.AfterID = comparison.CueResults[placed[i-1]].ExistingID
+			}
+			if result.Action == "skip" {
+				result.HasChanged = true
+				result.Action = "move"
+				result.Reason = "reordered in source"
+				if current.parents[key] != parent {
+					result.Reason = fmt.Sprintf("moved to %s in source", parent)
+				}
+			}
+			q.log().Debugf("Cue %s needs moving within %s", key, parent)
+		}
+	}
+}
+
+// longestIncreasingSubsequence returns the indexes of a longest strictly increasing
+// subsequence of values
+func longestIncreasingSubsequence(values []int) []int {
+	// tails[k] is the index of the smallest value ending an increasing run of length k+1
+	var tails []int
+	previous := make([]int, len(values))
+	for i, value := range values {
+		k := sort.Search(len(tails), func(k int) bool { return values[tails[k]] >= value })
+		previous[i] = -1
+		if k > 0 {
+			previous[i] = tails[k-1]
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+
+	indexes := make([]int, len(tails))
+	if len(tails) == 0 {
+		return indexes
+	}
+	for k, i := len(tails)-1, tails[len(tails)-1]; k >= 0; k-- {
+		indexes[k] = i
+		i = previous[i]
+	}
+	return indexes
+}
+
+// applyMoves moves the cues with a Move into place, in source order so that each cue's
+// predecessor is settled before it. Cues the user chose to keep as they are in QLab stay.
+func (q *Workspace) applyMoves(results map[string]*CueChangeResult) error {
+	keys := pendingMoves(results)
+	sort.Slice(keys, func(i, j int) bool {
+		return results[keys[i]].Move.sequence < results[keys[j]].Move.sequence
+	})
+
+	for _, key := range keys {
+		result := results[key]
+		index, err := q.moveIndex(result.ExistingID, result.Move)
+		if err != nil {
+			return fmt.Errorf("failed to place cue %s: %w", key, err)
+		}
+		q.log().Infof("Moving cue [%s] to position %d in %s", key, index, result.Move.Parent)
+		if err := q.moveCueToParentWithIndex(result.ExistingID, result.Move.ParentID, index); err != nil {
+			return q.transmitFailed(key, PhaseMove, fmt.Errorf("failed to move cue %s: %w", key, err))
+		}
+		q.progress.step(PhaseMove, key)
+		q.journal.record(JournalEntry{Phase: PhaseMove, Cue: key, UniqueID: result.ExistingID})
+		fireHooks(q, &q.hooks.moved, CueMovedEvent{
+			CueNumber: key, UniqueID: result.ExistingID, Parent: result.Move.Parent, ParentID: result.Move.ParentID, Index: index,
+		})
+	}
+	return nil
+}
+
+// pendingMoves returns the keys of the results whose cue is to be moved
+func pendingMoves(results map[string]*CueChangeResult) []string {
+	var keys []string
+	for key, result := range results {
+		if result != nil && result.Move != nil && (result.Action == "move" || result.Action == "update") {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// moveIndex returns the index in the move's parent that puts the cue right after the one
+// it follows, based on the parent's children in QLab now
+func (q *Workspace) moveIndex(cueID string, move *CueMove) (int, error) {
+	if move.AfterID == "" {
+		return 0, nil
+	}
+	children, err := q.getCueChildren(move.ParentID)
+	if err != nil {
+		return 0, err
+	}
+	index := 0
+	for _, child := range children {
+		id, _ := child["uniqueID"].(string)
+		if id == cueID {
+			continue
+		}
+		index++
+		if id == move.AfterID {
+			return index, nil
+		}
+	}
+	// The cue it follows isn't there yet, so the cue goes last
+	return index, nil
+}
@@ -0,0 +1,144 @@
+package qlab
+
+import (
+	"fmt"
+	"time"
+)
+
+// Playback control. Workspace-level commands act on the current cue list the way the
+// buttons in QLab's workspace window do; cue-level commands act on a single cue by unique ID.
+
+// Go triggers the cue at the playhead and advances the playhead
+func (q *Workspace) Go() error {
+	return q.playbackCommand("go", q.GetAddress("/go"), "")
+}
+
+// GoCue moves the playhead to the cue with the given number and triggers it
+func (q *Workspace) GoCue(number string) error {
+	if number == "" {
+		return fmt.Errorf("cue number is required")
+	}
+	return q.playbackCommand("go cue "+number, q.GetAddress("/go"), number)
+}
+
+// GoFrom stops everything immediately, then moves the playhead to the cue with the given
+// number and triggers it, for restarting a rehearsal from a cue
+func (q *Workspace) GoFrom(number string) error {
+	if number == "" {
+		return fmt.Errorf("cue number is required")
+	}
+	if err := q.HardStop(); err != nil {
+		return err
+	}
+	return q.GoCue(number)
+}
+
+// LoadCue loads the cue with the given number so that it starts without delay when triggered
+func (q *Workspace) LoadCue(number string) error {
+	if number == "" {
+		return fmt.Errorf("cue number is required")
+	}
+	return q.playbackCommand("load cue "+number, q.cueNumberAddress(number, "load"), "")
+}
+
+// LoadAt loads the cue with the given number to a time into its action, so that triggering
+// it starts from there
+func (q *Workspace) LoadAt(number string, offset time.Duration) error {
+	if number == "" {
+		return fmt.Errorf("cue number is required")
+	}
+	if offset < 0 {
+		return fmt.Errorf("load time must not be negative, got %v", offset)
+	}
+	address := q.cueNumberAddress(number, "loadAt")
+	what := fmt.Sprintf("load cue %s at %v", number, offset)
+	if err := checkReplyStatus(q.SendWithArgs(address, float32(offset.Seconds()))); err != nil {
+		return fmt.Errorf("failed to %s: %w", what, err)
+	}
+	return nil
+}
+
+// StopAll stops every running cue, honoring each cue's fade-out time
+func (q *Workspace) StopAll() error {
+	return q.playbackCommand("stop", q.GetAddress("/stop"), "")
+}
+
+// PauseAll pauses every running cue
+func (q *Workspace) PauseAll() error {
+	return q.playbackCommand("pause", q.GetAddress("/pause"), "")
+}
+
+// ResumeAll resumes every paused cue
+func (q *Workspace) ResumeAll() error {
+	return q.playbackCommand("resume", q.GetAddress("/resume"), "")
+}
+
+// Panic fades out and stops every running cue using the workspace's panic duration.
+// Calling Panic again while a panic is in progress stops everything immediately.
+func (q *Workspace) Panic() error {
+	return q.playbackCommand("panic", q.GetAddress("/panic"), "")
+}
+
+// HardStop stops every running cue immediately, without fades
+func (q *Workspace) HardStop() error {
+	return q.playbackCommand("hard stop", q.GetAddress("/hardStop"), "")
+}
+
+// Reset stops every cue and returns the workspace to its loaded state
+func (q *Workspace) Reset() error {
+	return q.playbackCommand("reset", q.GetAddress("/reset"), "")
+}
+
+// Start starts a cue without moving the playhead
+func (q *Workspace) Start(uniqueID string) error {
+	return q.cuePlaybackCommand(uniqueID, "start")
+}
+
+// Stop stops a running cue
+func (q *Workspace) Stop(uniqueID string) error {
+	return q.cuePlaybackCommand(uniqueID, "stop")
+}
+
+// Pause pauses a running cue
+func (q *Workspace) Pause(uniqueID string) error {
+	return q.cuePlaybackCommand(uniqueID, "pause")
+}
+
+// Resume resumes a paused cue
+func (q *Workspace) Resume(uniqueID string) error {
+	return q.cuePlaybackCommand(uniqueID, "resume")
+}
+
+// Load loads a cue so that it starts without delay when triggered
+func (q *Workspace) Load(uniqueID string) error {
+	return q.cuePlaybackCommand(uniqueID, "load")
+}
+
+// PreviewCue plays a cue on its own, without triggering its actions on other cues
+// (e.g. a fade cue previews its fade without the target cue having to be running)
+func (q *Workspace) PreviewCue(uniqueID string) error {
+	return q.cuePlaybackCommand(uniqueID, "preview")
+}
+
+// cueNumberAddress returns the address of a method of the cue with a number
+func (q *Workspace) cueNumberAddress(number, method string) string {
+	return q.GetAddress(fmt.Sprintf("/cue/%s/%s", number, method))
+}
+
+// cuePlaybackCommand sends a playback command to a single cue
+func (q *Workspace) cuePlaybackCommand(uniqueID, command string) error {
+	if uniqueID == "" {
+		return fmt.Errorf("cue ID is required to %s a cue", command)
+	}
+	address := q.addressBuilder.BuildCuePropertyAddress(uniqueID, command)
+	return q.playbackCommand(command+" cue "+uniqueID, address, "")
+}
+
+// playbackCommand sends a playback command and checks QLab's reply
+func (q *Workspace) playbackCommand(what, address, input string) error {
+	reply := q.Send(address, input)
+	if err := checkReplyStatus(reply); err != nil {
+		return fmt.Errorf("failed to %s: %w", what, err)
+	}
+	return nil
+}
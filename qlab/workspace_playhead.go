@@ -0,0 +1,92 @@
+package qlab
+
+import (
+	"fmt"
+	"time"
+)
+
+// CueTimes reports how far a cue's action has run, e.g. for a stage display showing the
+// time left in the current cue
+type CueTimes struct {
+	UniqueID  string
+	Number    string
+	Name      string
+	Duration  time.Duration // Length of the cue's action, 0 for cues without one
+	Elapsed   time.Duration // How much of the action has run, 0 unless the cue is running
+	Remaining time.Duration // Duration less Elapsed, never negative
+}
+
+// Playhead returns the uniqueID of the cue at the playhead of a cue list, given by uniqueID
+// or name; "" when the playhead is past the last cue
+func (q *Workspace) Playhead(cueList string) (string, error) {
+	playheadID, err := q.queryPlayheadID(q.cueListID(cueList))
+	if err != nil {
+		return "", fmt.Errorf("failed to query playhead of %s: %w", cueList, err)
+	}
+	return playheadID, nil
+}
+
+// SetPlayhead moves the playhead of a cue list, given by uniqueID or name, to a cue in it
+func (q *Workspace) SetPlayhead(cueList, cueID string) error {
+	address := q.addressBuilder.BuildCuePropertyAddress(q.cueListID(cueList), "playheadId")
+	return q.playbackCommand("move playhead of "+cueList, address, cueID)
+}
+
+// SetPlayheadNumber moves the playhead of a cue list, given by uniqueID or name, to the cue
+// with a number
+func (q *Workspace) SetPlayheadNumber(cueList, number string) error {
+	address := q.addressBuilder.BuildCuePropertyAddress(q.cueListID(cueList), "playhead")
+	return q.playbackCommand("move playhead of "+cueList+" to cue "+number, address, number)
+}
+
+// GetCueTimes returns a cue's duration and how much of its action has run
+func (q *Workspace) GetCueTimes(uniqueID string) (*CueTimes, error) {
+	values, err := q.GetCueValues(uniqueID, []string{"number", "name", "duration", "actionElapsed"})
+	if err != nil {
+		return nil, err
+	}
+	times := &CueTimes{
+		UniqueID: uniqueID,
+		Number:   q.normalizeProperty(values["number"]),
+		Name:     q.normalizeProperty(values["name"]),
+		Duration: secondsDuration(values["duration"]),
+		Elapsed:  secondsDuration(values["actionElapsed"]),
+	}
+	times.Remaining = max(times.Duration-times.Elapsed, 0)
+	return times, nil
+}
+
+// RunningCueTimes returns the times of every running cue, in the order QLab lists them
+func (q *Workspace) RunningCueTimes() ([]CueTimes, error) {
+	var running []CueTimes
+	for _, cue := range q.GetRunningCues() {
+		uniqueID, _ := cue["uniqueID"].(string)
+		if uniqueID == "" {
+			continue
+		}
+		times, err := q.GetCueTimes(uniqueID)
+		if err != nil {
+			return running, err
+		}
+		running = append(running, *times)
+	}
+	return running, nil
+}
+
+// cueListID returns the uniqueID of a cue list given by name, or the argument unchanged if
+// no cue list has that name
+func (q *Workspace) cueListID(cueList string) string {
+	if listID := q.lookupCueList(cueList); listID != "" {
+		return listID
+	}
+	return cueList
+}
+
+// secondsDuration converts a time in seconds as QLab reports it to a duration
+func secondsDuration(value any) time.Duration {
+	seconds, ok := toFloat(value)
+	if !ok {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,86 @@
+package qlab
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestPlayhead tests reading and moving a cue list's playhead, and that GO advances it
+func TestPlayhead(t *testing.T) {
+	workspace, mockServer := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+
+	var ids []string
+	for _, number := range []string{"1", "2", "3"} {
+		id, err := workspace.createCueWithoutTarget(map[string]any{"type": "memo", "number": number}, number)
+		if err != nil {
+			t.Fatalf("Failed to create cue %s: %v", number, err)
+		}
+		ids = append(ids, id)
+	}
+
+	if playhead, err := workspace.Playhead("main-cue-list"); err != nil || playhead != ids[0] {
+		t.Errorf("Expected the playhead on the first cue, got %q (%v)", playhead, err)
+	}
+	if err := workspace.SetPlayheadNumber("main-cue-list", "2"); err != nil {
+		t.Fatalf("SetPlayheadNumber failed: %v", err)
+	}
+	if err := workspace.Go(); err != nil {
+		t.Fatalf("Go failed: %v", err)
+	}
+	if !mockServer.IsCueRunning(ids[1]) {
+		t.Error("Expected GO to start the cue at the playhead")
+	}
+	if playhead, err := workspace.Playhead("main-cue-list"); err != nil || playhead != ids[2] {
+		t.Errorf("Expected GO to advance the playhead to the third cue, got %q (%v)", playhead, err)
+	}
+
+	if err := workspace.SetPlayhead("main-cue-list", ids[0]); err != nil {
+		t.Fatalf("SetPlayhead failed: %v", err)
+	}
+	if playhead, _ := workspace.Playhead("main-cue-list"); playhead != ids[0] {
+		t.Errorf("Expected the playhead moved back to the first cue, got %q", playhead)
+	}
+	var qlabErr *QLabError
+	if err := workspace.SetPlayhead("main-cue-list", "MISSING"); !errors.As(err, &qlabErr) {
+		t.Errorf("Expected moving the playhead to a missing cue to fail, got %v", err)
+	}
+}
+
+// TestCueTimes tests reading the elapsed and remaining time of running cues
+func TestCueTimes(t *testing.T) {
+	workspace, _ := setupWorkspaceWithCleanup(t)
+	workspace.SetTimeout(1)
+
+	cueID, err := workspace.createCueWithoutTarget(map[string]any{"type": "audio", "number": "1", "name": "Overture"}, "1")
+	if err != nil {
+		t.Fatalf("Failed to create cue: %v", err)
+	}
+	if err := workspace.setCueProperty(cueID, "duration", "30"); err != nil {
+		t.Fatalf("Failed to set duration: %v", err)
+	}
+
+	times, err := workspace.GetCueTimes(cueID)
+	if err != nil {
+		t.Fatalf("GetCueTimes failed: %v", err)
+	}
+	if times.Duration != 30*time.Second || times.Elapsed != 0 || times.Remaining != 30*time.Second {
+		t.Errorf("Expected 30s remaining of a stopped cue, got %+v", times)
+	}
+
+	if err := workspace.Start(cueID); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	running, err := workspace.RunningCueTimes()
+	if err != nil {
+		t.Fatalf("RunningCueTimes failed: %v", err)
+	}
+	if len(running) != 1 || running[0].Name != "Overture" || running[0].Number != "1" {
+		t.Fatalf("Expected the running cue, got %+v", running)
+	}
+	if running[0].Elapsed <= 0 || running[0].Remaining >= 30*time.Second || running[0].Remaining+running[0].Elapsed != 30*time.Second {
+		t.Errorf("Expected elapsed and remaining time to add up to the duration, got %+v", running[0])
+	}
+}
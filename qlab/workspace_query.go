@@ -0,0 +1,205 @@
+package qlab
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// ErrCueNotFound is returned by GetCueByNumber and GetCueByID when QLab has no matching cue
+var ErrCueNotFound = errors.New("cue not found")
+
+// GetCueByNumber returns the cue with the given number, including the type-specific
+// properties that /cueLists does not report. Results are served from the workspace's
+// cue cache; call InvalidateCueCache after changing QLab outside this workspace.
+func (q *Workspace) GetCueByNumber(number string) (*Cue, error) {
+	if number == "" {
+		return nil, fmt.Errorf("cue number is required")
+	}
+	cue, err := q.findCueData(func(cue map[string]any) bool {
+		cueNumber, _ := cue["number"].(string)
+		return cueNumber == number
+	})
+	if err != nil {
+		return nil, err
+	}
+	if cue == nil {
+		return nil, fmt.Errorf("cue %s: %w", number, ErrCueNotFound)
+	}
+	return q.cueDetails(cue)
+}
+
+// GetCueByID returns the cue with the given unique ID, including the type-specific
+// properties that /cueLists does not report. Results are cached like GetCueByNumber.
+func (q *Workspace) GetCueByID(uniqueID string) (*Cue, error) {
+	if uniqueID == "" {
+		return nil, fmt.Errorf("cue ID is required")
+	}
+	cue, err := q.findCueData(func(cue map[string]any) bool {
+		cueID, _ := cue["uniqueID"].(string)
+		return cueID == uniqueID
+	})
+	if err != nil {
+		return nil, err
+	}
+	if cue == nil {
+		return nil, fmt.Errorf("cue %s: %w", uniqueID, ErrCueNotFound)
+	}
+	return q.cueDetails(cue)
+}
+
+// FindCues returns every cue, in every cue list, for which match returns true, in
+// cue list order with groups before their children. Only the properties reported by
+// /cueLists are set (uniqueID, number, name, listName, type, colorName, flagged, armed,
+// and child cues); use GetCueByID for the full set.
+func (q *Workspace) FindCues(match func(*Cue) bool) ([]*Cue, error) {
+	var found []*Cue
+	var walkErr error
+	_, err := q.findCueData(func(cueData map[string]any) bool {
+		cue, err := cueFromMap(cueData, q.log())
+		if err != nil {
+			walkErr = err
+			return true
+		}
+		if match == nil || match(cue) {
+			found = append(found, cue)
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	if walkErr != nil {
+		return nil, walkErr
+	}
+	return found, nil
+}
+
+// GetCueValues reads several properties of the cue with the given unique ID in one round
+// trip with valuesForKeys, returning them by key. Properties QLab doesn't report for the
+// cue are left out.
+func (q *Workspace) GetCueValues(uniqueID string, keys []string) (map[string]any, error) {
+	if uniqueID == "" {
+		return nil, fmt.Errorf("cue ID is required")
+	}
+	if len(keys) == 0 {
+		return make(map[string]any), nil
+	}
+	encodedKeys, err := json.Marshal(keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode keys: %w", err)
+	}
+
+	reply := q.Send(q.addressBuilder.BuildCuePropertyAddress(uniqueID, "valuesForKeys"), string(encodedKeys))
+	if err := checkReplyStatus(reply); err != nil {
+		return nil, fmt.Errorf("failed to read values of cue %s: %w", uniqueID, err)
+	}
+	data, _ := replyDataValue(reply)
+	values, ok := data.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected valuesForKeys reply for cue %s: %v", uniqueID, data)
+	}
+	return values, nil
+}
+
+// InvalidateCueCache discards the cached cue lists and cue details so the next query
+// reads QLab again
+func (q *Workspace) InvalidateCueCache() {
+	q.stateMu.Lock()
+	defer q.stateMu.Unlock()
+	q.cueListsCache = nil
+	q.cueDetailsCache = nil
+}
+
+// findCueData walks the cached cue lists depth-first and returns the first cue for
+// which match returns true, or nil if none does
+func (q *Workspace) findCueData(match func(map[string]any) bool) (map[string]any, error) {
+	data, err := q.getCueLists()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cue lists: %w", err)
+	}
+
+	var walk func(cues []any) map[string]any
+	walk = func(cues []any) map[string]any {
+		for _, cueData := range cues {
+			cue, ok := cueData.(map[string]any)
+			if !ok {
+				continue
+			}
+			if match(cue) {
+				return cue
+			}
+			if children, ok := cue["cues"].([]any); ok {
+				if found := walk(children); found != nil {
+					return found
+				}
+			}
+		}
+		return nil
+	}
+	for _, cueListData := range data {
+		if cueList, ok := cueListData.(map[string]any); ok {
+			if cues, ok := cueList["cues"].([]any); ok {
+				if found := walk(cues); found != nil {
+					return found, nil
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// cueDetails enriches a copy of a cue from /cueLists with its remaining properties,
+// caching the result by unique ID
+func (q *Workspace) cueDetails(cueData map[string]any) (*Cue, error) {
+	uniqueID, _ := cueData["uniqueID"].(string)
+	q.stateMu.Lock()
+	cached, ok := q.cueDetailsCache[uniqueID]
+	q.stateMu.Unlock()
+	if ok {
+		return cueFromMap(cached, q.log())
+	}
+
+	enriched := maps.Clone(cueData)
+	if uniqueID != "" {
+		q.enrichCueProperties(enriched, uniqueID)
+		q.stateMu.Lock()
+		if q.cueDetailsCache == nil {
+			q.cueDetailsCache = make(map[string]map[string]any)
+		}
+		q.cueDetailsCache[uniqueID] = enriched
+		q.stateMu.Unlock()
+	}
+	return cueFromMap(enriched, q.log())
+}
+
+// cueFromMap converts cue data as returned by QLab into a Cue. Properties whose values
+// don't match the Cue field types are skipped rather than failing the conversion.
+func cueFromMap(cueData map[string]any, logger Logger) (*Cue, error) {
+	data, err := json.Marshal(cueData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode cue data: %w", err)
+	}
+	var cue Cue
+	if err := json.Unmarshal(data, &cue); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) {
+			return nil, fmt.Errorf("failed to decode cue data: %w", err)
+		}
+		logger.Debug("Skipping cue property with unexpected type", "field", typeErr.Field, "value", typeErr.Value)
+	}
+	// QLab reports types capitalized ("Audio"); the CueType constants are lowercase
+	cue.Type = strings.ToLower(cue.Type)
+	lowerChildTypes(cue.Cues)
+	return &cue, nil
+}
+
+// lowerChildTypes lowercases the types of nested cues
+func lowerChildTypes(cues []Cue) {
+	for i := range cues {
+		cues[i].Type = strings.ToLower(cues[i].Type)
+		lowerChildTypes(cues[i].Cues)
+	}
+}
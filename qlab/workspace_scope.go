@@ -3,9 +3,9 @@ package qlab
 import (
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 	"time"
-
-	"github.com/charmbracelet/log"
 )
 
 // PerformScopeBasedComparison performs a hierarchical scope-based comparison
@@ -23,11 +23,29 @@ func (q *Workspace) PerformScopeBasedComparison(sourceCueData, cachedCueData, cu
 	cachedCues := q.indexCuesFromWorkspace(cachedCueData)
 	currentCues := q.indexCuesFromWorkspace(currentQLabData)
 
-	log.Debugf("Scope comparison: source=%d cues, cache=%d cues, qlab=%d cues",
+	q.log().Debugf("Scope comparison: source=%d cues, cache=%d cues, qlab=%d cues",
 		len(sourceCues), len(cachedCues), len(currentCues))
 
-	// Compare each cue at the cue scope level
+	// Record where each cue sits so conflicts can be resolved a group or cue list at a time.
+	// Source structure takes precedence over QLab's.
+	hierarchy := make(map[string]scopeHierarchy)
+	q.indexScopeHierarchy(sourceCueData, hierarchy)
+	q.indexScopeHierarchy(currentQLabData, hierarchy)
+	q.indexScopeHierarchy(cachedCueData, hierarchy)
+
+	// Compare each cue at the cue scope level, in cue list order
 	allCueNumbers := q.getAllCueIdentifiers(sourceCues, cachedCues, currentCues)
+	sort.SliceStable(allCueNumbers, func(i, j int) bool {
+		a, aOK := hierarchy[allCueNumbers[i]]
+		b, bOK := hierarchy[allCueNumbers[j]]
+		if aOK != bOK {
+			return aOK
+		}
+		if !aOK || a.order == b.order {
+			return allCueNumbers[i] < allCueNumbers[j]
+		}
+		return a.order < b.order
+	})
 
 	hasChanges := false
 	hasConflicts := false
@@ -38,6 +56,10 @@ func (q *Workspace) PerformScopeBasedComparison(sourceCueData, cachedCueData, cu
 		currentCue, hasCurrent := currentCues[cueNumber]
 
 		cueScope := q.compareCueScope(cueNumber, sourceCue, cachedCue, currentCue, hasSource, hasCache, hasCurrent)
+		if position, ok := hierarchy[cueNumber]; ok {
+			cueScope.Ancestors = position.ancestors
+			cueScope.CueList = position.cueList
+		}
 
 		if cueScope.HasChanges {
 			hasChanges = true
@@ -58,12 +80,70 @@ func (q *Workspace) PerformScopeBasedComparison(sourceCueData, cachedCueData, cu
 		workspaceScope.ChangeType = "none"
 	}
 
-	log.Infof("Workspace scope: hasChanges=%t, hasConflicts=%t, cues=%d",
+	q.log().Infof("Workspace scope: hasChanges=%t, hasConflicts=%t, cues=%d",
 		hasChanges, hasConflicts, len(workspaceScope.ChildScopes))
 
 	return workspaceScope, nil
 }
 
+// scopeHierarchy records where a cue sits in the tree of cue lists and groups
+type scopeHierarchy struct {
+	ancestors []string // Identifiers of the enclosing groups and cue lists, innermost first
+	cueList   string   // Name of the enclosing cue list
+	order     int      // Depth-first position, for ordering scopes
+}
+
+// indexScopeHierarchy adds the position of every cue in workspace data to hierarchy, keyed
+// the same way as indexCuesFromWorkspace. Cues already in hierarchy are left unchanged.
+// Source data names cue lists with list or cart cues; QLab data wraps cues in its cue lists.
+func (q *Workspace) indexScopeHierarchy(workspace map[string]any, hierarchy map[string]scopeHierarchy) {
+	var walk func(cues []any, parentNumber string, ancestors []string, cueList string)
+	walk = func(cues []any, parentNumber string, ancestors []string, cueList string) {
+		for i, cueData := range cues {
+			cue, ok := cueData.(map[string]any)
+			if !ok {
+				continue
+			}
+			key, fullNumber := q.sourceCueKey(cue, parentNumber, i)
+			if _, seen := hierarchy[key]; key != "" && !seen {
+				hierarchy[key] = scopeHierarchy{ancestors: ancestors, cueList: cueList, order: len(hierarchy)}
+			}
+
+			children, ok := cue["cues"].([]any)
+			if !ok {
+				continue
+			}
+			childList := cueList
+			switch cueType, _ := cue["type"].(string); strings.ToLower(cueType) {
+			case "list", "cart", CueTypeList:
+				childList, _ = cue["name"].(string)
+			}
+			childAncestors := ancestors
+			if key != "" {
+				childAncestors = append([]string{key}, ancestors...)
+			}
+			walk(children, fullNumber, childAncestors, childList)
+		}
+	}
+
+	if cues, ok := workspace["cues"].([]any); ok {
+		walk(cues, "", nil, "")
+	} else if nested, ok := workspace["workspace"].(map[string]any); ok {
+		if cues, ok := nested["cues"].([]any); ok {
+			walk(cues, "", nil, "")
+		}
+	} else if cueLists, ok := workspace["data"].([]any); ok {
+		for _, cueListData := range cueLists {
+			if cueList, ok := cueListData.(map[string]any); ok {
+				if cues, ok := cueList["cues"].([]any); ok {
+					name, _ := cueList["name"].(string)
+					walk(cues, "", nil, name)
+				}
+			}
+		}
+	}
+}
+
 // compareCueScope compares a single cue across three sources at field level
 func (q *Workspace) compareCueScope(cueNumber string, sourceCue, cachedCue, currentCue map[string]any, hasSource, hasCache, hasCurrent bool) *ScopeComparison {
 	cueScope := &ScopeComparison{
@@ -102,6 +182,11 @@ func (q *Workspace) compareCueScope(cueNumber string, sourceCue, cachedCue, curr
 	hasConflicts := false
 
 	for _, fieldName := range allFields {
+		// Unknown properties only exist in source data and snapshots; QLab never has them
+		if !q.compareUnknown && fieldName != "cues" && !isKnownCueProperty(fieldName) {
+			continue
+		}
+
 		fieldConflict := q.compareField(fieldName, sourceCue, cachedCue, currentCue, hasCache)
 
 		if fieldConflict != nil {
@@ -261,7 +346,7 @@ func (q *Workspace) GenerateMergedScope(scopeComparison *ScopeComparison, compar
 		AppliedAt:    time.Now().Format(time.RFC3339),
 	}
 
-	log.Debugf("Generating merged scope for %s: %s", scopeComparison.Scope, scopeComparison.Identifier)
+	q.log().Debugf("Generating merged scope for %s: %s", scopeComparison.Scope, scopeComparison.Identifier)
 
 	// Process field-level merges
 	for fieldName, fieldConflict := range scopeComparison.FieldChanges {
@@ -318,13 +403,13 @@ func (q *Workspace) GenerateMergedScope(scopeComparison *ScopeComparison, compar
 	for _, childScope := range scopeComparison.ChildScopes {
 		childMerged, err := q.GenerateMergedScope(childScope, comparison)
 		if err != nil {
-			log.Warnf("Failed to merge child scope %s: %v", childScope.Identifier, err)
+			q.log().Warnf("Failed to merge child scope %s: %v", childScope.Identifier, err)
 			continue
 		}
 		merged.ChildScopes = append(merged.ChildScopes, childMerged)
 	}
 
-	log.Debugf("Merged scope %s: %d fields, %d children",
+	q.log().Debugf("Merged scope %s: %d fields, %d children",
 		merged.Identifier, len(merged.MergedData), len(merged.ChildScopes))
 
 	return merged, nil
@@ -377,7 +462,7 @@ func (q *Workspace) ExtractMergedWorkspaceData(mergedScope *MergedScope) (map[st
 
 	workspaceData["cues"] = cues
 
-	log.Infof("Extracted merged workspace with %d top-level cues", len(cues))
+	q.log().Infof("Extracted merged workspace with %d top-level cues", len(cues))
 
 	return workspaceData, nil
 }
@@ -0,0 +1,149 @@
+package qlab
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SelectionSnapshot captures the operator's cue selection and cue list playheads
+// so they can be put back after an automated sync has moved them. QLab's OSC API can
+// only select one cue at a time, so RestoreSelection selects the first of several
+// selected cues; the rest stay recorded in SelectedCueIDs.
+type SelectionSnapshot struct {
+	SelectedCueIDs []string          // Unique IDs of selected cues, in QLab's selection order
+	Playheads      map[string]string // Cue list ID -> unique ID of the cue at the playhead
+}
+
+// SetPreserveSelection sets whether TransmitWorkspaceData records the selection and
+// playheads before transmitting and restores them afterwards
+func (q *Workspace) SetPreserveSelection(preserve bool) {
+	q.preserveSelection = preserve
+}
+
+// RecordSelection queries QLab for the current cue selection and the playhead of every cue list
+func (q *Workspace) RecordSelection() (*SelectionSnapshot, error) {
+	if q.workspace_id == "" {
+		return nil, fmt.Errorf("workspace ID is required for recording selection but not available")
+	}
+
+	snapshot := &SelectionSnapshot{
+		Playheads: make(map[string]string),
+	}
+
+	for _, cue := range q.GetSelectedCues() {
+		if uniqueID, ok := cue["uniqueID"].(string); ok && uniqueID != "" {
+			snapshot.SelectedCueIDs = append(snapshot.SelectedCueIDs, uniqueID)
+		}
+	}
+
+	cueLists, err := q.getCueLists()
+	if err != nil {
+		return snapshot, fmt.Errorf("failed to query cue lists for playheads: %v", err)
+	}
+
+	for _, cueListData := range cueLists {
+		cueList, ok := cueListData.(map[string]any)
+		if !ok {
+			continue
+		}
+		listID, ok := cueList["uniqueID"].(string)
+		if !ok || listID == "" {
+			continue
+		}
+
+		playheadID, err := q.queryPlayheadID(listID)
+		if err != nil {
+			q.log().Debug("Failed to query playhead for cue list", "cue_list_id", listID, "error", err)
+			continue
+		}
+		if playheadID != "" {
+			snapshot.Playheads[listID] = playheadID
+		}
+	}
+
+	q.log().Debug("Recorded selection", "selected", len(snapshot.SelectedCueIDs), "playheads", len(snapshot.Playheads))
+	return snapshot, nil
+}
+
+// RestoreSelection puts back the playheads and selection captured by RecordSelection.
+// Of several selected cues, only the first is selected again.
+func (q *Workspace) RestoreSelection(snapshot *SelectionSnapshot) error {
+	if snapshot == nil {
+		return nil
+	}
+
+	var restoreErrors []string
+
+	// Restore playheads first, since moving a playhead also moves the selection in QLab
+	for listID, playheadID := range snapshot.Playheads {
+		address := fmt.Sprintf("/workspace/%s/cue_id/%s/playheadId", q.workspace_id, listID)
+		if err := checkReplyStatus(q.Send(address, playheadID)); err != nil {
+			restoreErrors = append(restoreErrors, fmt.Sprintf("playhead of %s: %v", listID, err))
+		}
+	}
+
+	if len(snapshot.SelectedCueIDs) > 0 {
+		// QLab's select_id replaces the selection, so the first selected cue becomes the selection
+		cueID := snapshot.SelectedCueIDs[0]
+		if len(snapshot.SelectedCueIDs) > 1 {
+			q.log().Debug("Restoring primary selection only", "selected", len(snapshot.SelectedCueIDs))
+		}
+		address := fmt.Sprintf("/workspace/%s/select_id/%s", q.workspace_id, cueID)
+		if err := checkReplyStatus(q.Send(address, "")); err != nil {
+			restoreErrors = append(restoreErrors, fmt.Sprintf("selection of %s: %v", cueID, err))
+		}
+	}
+
+	if len(restoreErrors) > 0 {
+		return fmt.Errorf("failed to restore selection: %v", restoreErrors)
+	}
+
+	q.log().Debug("Restored selection", "selected", len(snapshot.SelectedCueIDs), "playheads", len(snapshot.Playheads))
+	return nil
+}
+
+// queryPlayheadID queries the unique ID of the cue at the playhead of a cue list
+func (q *Workspace) queryPlayheadID(cueListID string) (string, error) {
+	address := fmt.Sprintf("/workspace/%s/cue_id/%s/playheadId", q.workspace_id, cueListID)
+	reply := q.Send(address, "")
+
+	if len(reply) == 0 {
+		return "", fmt.Errorf("no reply received when querying playhead")
+	}
+
+	replyStr, ok := reply[0].(string)
+	if !ok {
+		return "", fmt.Errorf("invalid reply format from playhead query")
+	}
+
+	var replyData map[string]any
+	if err := json.Unmarshal([]byte(replyStr), &replyData); err != nil {
+		return "", fmt.Errorf("failed to parse playhead reply: %v", err)
+	}
+
+	if status, ok := replyData["status"].(string); ok && status == "error" {
+		return "", newQLabError("QLab error querying playhead", replyStr)
+	}
+
+	playheadID, _ := replyData["data"].(string)
+	return playheadID, nil
+}
+
+// checkReplyStatus returns an error if a reply carries an error status
+func checkReplyStatus(reply []any) error {
+	if len(reply) == 0 {
+		return fmt.Errorf("no reply received")
+	}
+	replyStr, ok := reply[0].(string)
+	if !ok {
+		return nil
+	}
+	var replyData map[string]any
+	if err := json.Unmarshal([]byte(replyStr), &replyData); err != nil {
+		return nil
+	}
+	if status, ok := replyData["status"].(string); ok && status == "error" {
+		return newQLabError("QLab returned error", replyStr)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package qlab
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// PatchKind is a kind of workspace output: an audio or network patch, or a video stage
+type PatchKind string
+
+const (
+	PatchAudio   PatchKind = "audio"
+	PatchNetwork PatchKind = "network"
+	PatchStage   PatchKind = "stage"
+)
+
+// patchSettings are where QLab lists each kind of patch, and where one is renamed
+var patchSettings = map[PatchKind]struct {
+	list   string // Lists the patches
+	single string // Addresses one patch by ID
+}{
+	PatchAudio:   {list: "/settings/audio/patchList", single: "/settings/audio/patch/%s"},
+	PatchNetwork: {list: "/settings/network/patchList", single: "/settings/network/patch/%s"},
+	PatchStage:   {list: "/settings/video/stages", single: "/settings/video/stage/%s"},
+}
+
+// patchCueProperties are the cue data keys that name a patch, and the ID property each
+// name resolves to
+var patchCueProperties = []struct {
+	kind    PatchKind
+	nameKey string
+	idKey   string
+}{
+	{PatchAudio, "audioOutputPatchName", "audioOutputPatchID"},
+	{PatchNetwork, "networkPatchName", "networkPatchID"},
+	{PatchStage, "stageName", "stageID"},
+}
+
+// Patch is an audio patch, network patch, or video stage of the workspace
+type Patch struct {
+	Kind     PatchKind      `json:"kind"`
+	UniqueID string         `json:"uniqueID"`
+	Name     string         `json:"name"`
+	Data     map[string]any `json:"-"` // Everything QLab reported for the patch
+}
+
+// Settings reads and changes the workspace's audio patches, network patches, and video
+// stages. Patches are cached once read; Refresh drops the cache.
+type Settings struct {
+	q *Workspace
+}
+
+// Settings returns the workspace settings API
+func (q *Workspace) Settings() *Settings {
+	return &Settings{q: q}
+}
+
+// AudioPatches returns the workspace's audio output patches
+func (s *Settings) AudioPatches() ([]Patch, error) {
+	return s.Patches(PatchAudio)
+}
+
+// NetworkPatches returns the workspace's network patches
+func (s *Settings) NetworkPatches() ([]Patch, error) {
+	return s.Patches(PatchNetwork)
+}
+
+// VideoStages returns the workspace's video stages
+func (s *Settings) VideoStages() ([]Patch, error) {
+	return s.Patches(PatchStage)
+}
+
+// Patches returns the patches of a kind, in QLab's order
+func (s *Settings) Patches(kind PatchKind) ([]Patch, error) {
+	q := s.q
+	settings, ok := patchSettings[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown patch kind %q", kind)
+	}
+
+	q.patchMu.Lock()
+	cached, ok := q.patchCache[kind]
+	q.patchMu.Unlock()
+	if ok {
+		s.q.log().Debugf("Returning cached %s patches (%d)", kind, len(cached))
+		return cached, nil
+	}
+
+	if q.workspace_id == "" {
+		return nil, fmt.Errorf("workspace ID is required")
+	}
+	s.q.log().Debugf("Querying QLab for %s patches", kind)
+	reply, err := q.Query(settings.list)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s patches: %w", kind, err)
+	}
+	var data []map[string]any
+	if err := reply.Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to list %s patches: %w", kind, err)
+	}
+
+	patches := make([]Patch, 0, len(data))
+	for _, patchData := range data {
+		patch := Patch{Kind: kind, Data: patchData}
+		patch.UniqueID, _ = patchData["uniqueID"].(string)
+		patch.Name, _ = patchData["name"].(string)
+		patches = append(patches, patch)
+	}
+
+	q.patchMu.Lock()
+	if q.patchCache == nil {
+		q.patchCache = make(map[PatchKind][]Patch)
+	}
+	q.patchCache[kind] = patches
+	q.patchMu.Unlock()
+	s.q.log().Debugf("Cached %d %s patches", len(patches), kind)
+	return patches, nil
+}
+
+// Patch finds a patch by name, matched case-insensitively, or by unique ID
+func (s *Settings) Patch(kind PatchKind, nameOrID string) (Patch, error) {
+	patches, err := s.Patches(kind)
+	if err != nil {
+		return Patch{}, err
+	}
+	for _, patch := range patches {
+		if patch.UniqueID == nameOrID {
+			return patch, nil
+		}
+	}
+	for _, patch := range patches {
+		if strings.EqualFold(patch.Name, nameOrID) {
+			return patch, nil
+		}
+	}
+	names := make([]string, len(patches))
+	for i, patch := range patches {
+		names[i] = patch.Name
+	}
+	return Patch{}, fmt.Errorf("no %s patch named %q (have %s)", kind, nameOrID, strings.Join(names, ", "))
+}
+
+// RenamePatch renames a patch, found by name or unique ID. Renaming is the only change
+// QLab allows to patches over OSC.
+func (s *Settings) RenamePatch(kind PatchKind, nameOrID, newName string) error {
+	if newName == "" {
+		return fmt.Errorf("new %s patch name is empty", kind)
+	}
+	patch, err := s.Patch(kind, nameOrID)
+	if err != nil {
+		return err
+	}
+	address := fmt.Sprintf(patchSettings[kind].single, patch.UniqueID) + "/name"
+	if _, err := s.q.Query(address, newName); err != nil {
+		return fmt.Errorf("failed to rename %s patch %q: %w", kind, patch.Name, err)
+	}
+	s.Refresh()
+	return nil
+}
+
+// Refresh drops the cached patches, so the next read asks QLab again
+func (s *Settings) Refresh() {
+	s.q.patchMu.Lock()
+	s.q.patchCache = nil
+	s.q.patchMu.Unlock()
+}
+
+// resolvePatchNames returns cue data with the patches it names replaced by their IDs, so
+// a misspelled patch fails before the cue is changed. Names are left alone when the cue
+// also gives the ID, or when QLab lists no patches of that kind.
+func (q *Workspace) resolvePatchNames(cueData map[string]any) (map[string]any, error) {
+	resolved, cloned := cueData, false
+	for _, property := range patchCueProperties {
+		name, ok := cueData[property.nameKey].(string)
+		if !ok || name == "" {
+			continue
+		}
+		if id, ok := cueData[property.idKey].(string); ok && id != "" {
+			continue
+		}
+		patches, err := q.Settings().Patches(property.kind)
+		if err != nil || len(patches) == 0 {
+			q.log().Debug("Cannot list patches, sending the name as is", "kind", property.kind, "error", err)
+			continue
+		}
+		index := slices.IndexFunc(patches, func(p Patch) bool { return strings.EqualFold(p.Name, name) })
+		if index < 0 {
+			_, err := q.Settings().Patch(property.kind, name)
+			return nil, err
+		}
+		if !cloned {
+			resolved, cloned = maps.Clone(cueData), true
+		}
+		delete(resolved, property.nameKey)
+		resolved[property.idKey] = patches[index].UniqueID
+		q.log().Debug("Resolved patch name", "kind", property.kind, "name", name, "id", patches[index].UniqueID)
+	}
+	return resolved, nil
+}
+
+// setAudioOutputPatch sets the audio output patch of an audio, video, or microphone cue
+func (q *Workspace) setAudioOutputPatch(uniqueID string, cueData map[string]any) error {
+	for _, key := range []string{"audioOutputPatchID", "audioOutputPatchName"} {
+		if value, ok := cueData[key].(string); ok && value != "" {
+			if err := q.setCueProperty(uniqueID, key, value); err != nil {
+				return fmt.Errorf("failed to set audio output patch: %w", err)
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// String returns the patch's kind and name
+func (p Patch) String() string {
+	return fmt.Sprintf("%s patch %q", p.Kind, p.Name)
+}
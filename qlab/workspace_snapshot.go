@@ -0,0 +1,240 @@
+package qlab
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SnapshotVersion is the version of the snapshot schema written by ExportSnapshot
+const SnapshotVersion = 1
+
+// ErrWorkspaceNotEmpty is returned by ImportSnapshot when the workspace already has cues
+var ErrWorkspaceNotEmpty = errors.New("workspace is not empty")
+
+// exportedProperties are read for every cue when exporting, on top of the type-specific ones
+var exportedProperties = []string{"fileTarget", "cueTargetNumber", "cueTargetID", "notes", "preWait", "duration"}
+
+// snapshotDropped are QLab bookkeeping properties that don't belong in a snapshot
+var snapshotDropped = []string{"listName", "flagged"}
+
+// WorkspaceSnapshot is a portable copy of the cues in a workspace. Cues use the same
+// properties as CUE source data, with numbers as QLab reports them (absolute, not
+// relative to their group) and child cues under "cues".
+type WorkspaceSnapshot struct {
+	Version     int               `json:"version"`
+	CreatedAt   time.Time         `json:"createdAt"`
+	WorkspaceID string            `json:"workspaceID,omitempty"`
+	CueLists    []SnapshotCueList `json:"cueLists"`
+}
+
+// SnapshotCueList is a cue list or cart in a snapshot
+type SnapshotCueList struct {
+	Name string `json:"name"`
+	Type string `json:"type"` // "list" or "cart"
+	Cues []any  `json:"cues"` // Cue data maps in cue list order
+}
+
+// ExportSnapshot writes every cue list and cue in the workspace, including type-specific
+// properties, targets, and group structure, to w as a versioned JSON WorkspaceSnapshot
+func (q *Workspace) ExportSnapshot(w io.Writer) error {
+	saved := q.enrichProperties
+	q.enrichProperties = exportedProperties
+	data, err := q.queryCurrentWorkspaceState()
+	q.enrichProperties = saved
+	if err != nil {
+		return fmt.Errorf("failed to read workspace: %w", err)
+	}
+
+	snapshot := WorkspaceSnapshot{
+		Version:     SnapshotVersion,
+		CreatedAt:   time.Now().UTC(),
+		WorkspaceID: q.workspace_id,
+		CueLists:    []SnapshotCueList{},
+	}
+	cueLists, _ := data["data"].([]any)
+	for _, cueListData := range cueLists {
+		cueList, ok := cueListData.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := cueList["name"].(string)
+		listType := "list"
+		if cueType, _ := cueList["type"].(string); strings.EqualFold(cueType, "cart") {
+			listType = "cart"
+		}
+		cues, _ := cueList["cues"].([]any)
+		snapshot.CueLists = append(snapshot.CueLists, SnapshotCueList{
+			Name: name,
+			Type: listType,
+			Cues: snapshotCues(cues),
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(snapshot); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	q.log().Infof("Exported snapshot of %d cue lists", len(snapshot.CueLists))
+	return nil
+}
+
+// snapshotCues converts QLab cue data to snapshot cues
+func snapshotCues(cues []any) []any {
+	converted := make([]any, 0, len(cues))
+	for _, cueData := range cues {
+		cue, ok := cueData.(map[string]any)
+		if !ok {
+			continue
+		}
+		snapshot := maps.Clone(cue)
+		for _, key := range snapshotDropped {
+			delete(snapshot, key)
+		}
+		if cueType, ok := snapshot["type"].(string); ok {
+			snapshot["type"] = strings.ToLower(cueType)
+		}
+		// Source data carries timings as strings and armed only when set
+		for _, key := range []string{"preWait", "duration"} {
+			if f, ok := snapshot[key].(float64); ok {
+				if f == 0 {
+					delete(snapshot, key)
+				} else {
+					snapshot[key] = strconv.FormatFloat(f, 'g', -1, 64)
+				}
+			}
+		}
+		if armed, ok := snapshot["armed"].(bool); ok {
+			if armed {
+				snapshot["armed"] = "true"
+			} else {
+				delete(snapshot, "armed")
+			}
+		}
+		if children, ok := cue["cues"].([]any); ok {
+			snapshot["cues"] = snapshotCues(children)
+		}
+		converted = append(converted, snapshot)
+	}
+	return converted
+}
+
+// ImportSnapshot rebuilds the cues of a snapshot written by ExportSnapshot. The workspace
+// must not have any cues yet; cue lists with the same name as an existing one are reused.
+// Targets are restored to the rebuilt cues they referred to in the snapshot.
+func (q *Workspace) ImportSnapshot(r io.Reader) error {
+	if err := q.checkWritable("snapshot import"); err != nil {
+		return err
+	}
+	q.opMu.Lock()
+	defer q.opMu.Unlock()
+
+	var snapshot WorkspaceSnapshot
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	if snapshot.Version < 1 || snapshot.Version > SnapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d (supported up to %d)", snapshot.Version, SnapshotVersion)
+	}
+
+	if _, err := q.ValidateIndexes(); err != nil {
+		return err
+	}
+	cueLists, err := q.getCueLists()
+	if err != nil {
+		return fmt.Errorf("failed to query cue lists: %w", err)
+	}
+	for _, cueListData := range cueLists {
+		if cueList, ok := cueListData.(map[string]any); ok {
+			if cues, _ := cueList["cues"].([]any); len(cues) > 0 {
+				return fmt.Errorf("%w - cue list %v has %d cues", ErrWorkspaceNotEmpty, cueList["name"], len(cues))
+			}
+		}
+	}
+
+	restore := &snapshotRestore{
+		mapping:   &CueMapping{NumberToID: make(map[string]string)},
+		ids:       make(map[string]string),
+		targetIDs: make(map[string]string),
+	}
+	for _, cueList := range snapshot.CueLists {
+		listID := q.lookupCueList(cueList.Name)
+		if listID == "" {
+			listID, err = q.createCueWithoutTarget(map[string]any{"type": cueList.Type, "name": cueList.Name}, "")
+			if err != nil {
+				return fmt.Errorf("failed to create cue list %q: %w", cueList.Name, err)
+			}
+			q.recordCueList(cueList.Name, listID)
+		}
+		if err := q.importSnapshotCues(cueList.Cues, listID, restore); err != nil {
+			return err
+		}
+	}
+
+	// Targets are set once every cue exists, by ID when the snapshot has one
+	for uniqueID, snapshotTargetID := range restore.targetIDs {
+		targetID, ok := restore.ids[snapshotTargetID]
+		if !ok {
+			q.log().Warnf("Cannot restore target of cue %s: %s is not in the snapshot", uniqueID, snapshotTargetID)
+			continue
+		}
+		if err := q.setCueProperty(uniqueID, "cueTargetID", targetID); err != nil {
+			return fmt.Errorf("failed to restore target of cue %s: %w", uniqueID, err)
+		}
+	}
+	if err := q.setCueTargets(restore.mapping); err != nil {
+		return err
+	}
+	q.InvalidateCueCache()
+	q.log().Infof("Imported snapshot of %d cue lists (%d cues)", len(snapshot.CueLists), len(restore.ids))
+	return nil
+}
+
+// snapshotRestore tracks the cues rebuilt by ImportSnapshot
+type snapshotRestore struct {
+	mapping   *CueMapping       // Targets by number, resolved with setCueTargets
+	ids       map[string]string // Snapshot unique ID -> rebuilt unique ID
+	targetIDs map[string]string // Rebuilt unique ID -> snapshot unique ID of its target
+}
+
+// importSnapshotCues creates the cues in order inside the parent, then their children
+func (q *Workspace) importSnapshotCues(cues []any, parentID string, restore *snapshotRestore) error {
+	for i, cueData := range cues {
+		cue, ok := cueData.(map[string]any)
+		if !ok {
+			continue
+		}
+		number := formatCueNumber(cue["number"])
+		name, _ := cue["name"].(string)
+		uniqueID, err := q.createCueWithoutTarget(cue, number)
+		if err != nil {
+			return fmt.Errorf("failed to create cue %s %q: %w", number, name, err)
+		}
+		if err := q.moveCueToParentWithIndex(uniqueID, parentID, i); err != nil {
+			return fmt.Errorf("failed to place cue %s %q: %w", number, name, err)
+		}
+		restore.mapping.recordCue(number, name, uniqueID)
+		if oldID, _ := cue["uniqueID"].(string); oldID != "" {
+			restore.ids[oldID] = uniqueID
+		}
+
+		if targetID, _ := cue["cueTargetID"].(string); targetID != "" {
+			restore.targetIDs[uniqueID] = targetID
+		} else if targetNumber, _ := cue["cueTargetNumber"].(string); targetNumber != "" {
+			restore.mapping.CuesWithTargets = append(restore.mapping.CuesWithTargets, CueTarget{UniqueID: uniqueID, TargetNumber: targetNumber})
+		}
+
+		if children, ok := cue["cues"].([]any); ok {
+			if err := q.importSnapshotCues(children, uniqueID, restore); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
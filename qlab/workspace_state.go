@@ -1,8 +1,6 @@
 package qlab
 
-import (
-	"github.com/charmbracelet/log"
-)
+import ()
 
 // GetRunningCueNumbers extracts cue numbers from running cues
 func GetRunningCueNumbers(runningCues []map[string]any) []string {
@@ -29,7 +27,7 @@ func GetSelectedCueNumbers(selectedCues []map[string]any) []string {
 // SetupUpdateListener sets up a listener that calls the handler when QLab sends updates
 func SetupUpdateListener(workspace *Workspace, handler func()) error {
 	return workspace.StartUpdateListener(func(address string, args []any) {
-		log.Debug("QLab update received", "address", address)
+		workspace.log().Debug("QLab update received", "address", address)
 		handler()
 	})
 }
@@ -0,0 +1,289 @@
+package qlab
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// TargetProblemKind classifies a problem found by ValidateTargets
+type TargetProblemKind string
+
+const (
+	TargetBroken      TargetProblemKind = "broken"       // The cue's target doesn't resolve to exactly one cue
+	TargetMissing     TargetProblemKind = "missing"      // The cue needs a cue target and has none
+	TargetFileMissing TargetProblemKind = "missing_file" // The cue needs a file target and has none
+	TargetCircular    TargetProblemKind = "circular"     // Start or stop cues that end up targeting themselves
+)
+
+// targetingCueTypes do nothing without a cue target
+var targetingCueTypes = []string{CueTypeFade, CueTypeStart, CueTypeStop, CueTypePause, CueTypeReset, CueTypeDevamp, CueTypeGoto, CueTypeTarget, CueTypeLoad, CueTypeArm, CueTypeDisarm}
+
+// isTargetingCueType reports whether cues of a type act on a target cue
+func isTargetingCueType(cueType string) bool {
+	return slices.Contains(targetingCueTypes, strings.ToLower(cueType))
+}
+
+// fileCueTypes play a file and do nothing without a file target
+var fileCueTypes = []string{CueTypeAudio, CueTypeVideo, CueTypeMIDIFile}
+
+// triggeringCueTypes start or stop their target, so chains of them can loop
+var triggeringCueTypes = []string{CueTypeStart, CueTypeStop}
+
+// TargetProblem is a cue whose target is broken, missing, or part of a loop
+type TargetProblem struct {
+	Kind   TargetProblemKind
+	Cue    string // Full cue number, or position key for unnumbered cues
+	Name   string
+	Target string // The reference that failed, or the cues of a loop joined by " -> "
+	Detail string
+}
+
+func (p TargetProblem) String() string {
+	label := "cue " + p.Cue
+	if p.Name != "" {
+		label += fmt.Sprintf(" %q", p.Name)
+	}
+	switch p.Kind {
+	case TargetMissing:
+		return label + ": no cue target"
+	case TargetFileMissing:
+		return label + ": no file target"
+	case TargetCircular:
+		return label + ": circular targets " + p.Target
+	default:
+		return fmt.Sprintf("%s: target %s: %s", label, p.Target, p.Detail)
+	}
+}
+
+// TargetReport is the result of ValidateTargets
+type TargetReport struct {
+	Problems []TargetProblem // In workspace order
+	Cues     int             // Cues checked
+}
+
+// OK reports whether every target checked out
+func (r *TargetReport) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// targetCheck is a cue as ValidateTargets sees it
+type targetCheck struct {
+	key        string
+	number     string
+	name       string
+	cueType    string
+	uniqueID   string
+	fileTarget string
+	byNumber   string
+	byName     string
+	byID       string
+}
+
+// ValidateTargets checks the cue targets and file targets of source data, or of QLab's
+// current state when workspaceData is nil. It reports cues whose target number, name or ID
+// doesn't resolve, targeting cues without a target, audio, video and MIDI file cues without
+// a file, and start and stop cues that target each other in a loop. Targets in source data
+// may also resolve to cues already in QLab.
+func (q *Workspace) ValidateTargets(workspaceData map[string]any) (*TargetReport, error) {
+	fromQLab := workspaceData == nil
+	if fromQLab {
+		current, err := q.queryCurrentWorkspaceState()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read workspace: %w", err)
+		}
+		workspaceData = current
+	}
+	cues := q.collectTargetChecks(workspaceData)
+
+	byNumber := make(map[string]string)
+	byName := make(map[string][]string)
+	byID := make(map[string]string)
+	for _, cue := range cues {
+		if cue.number != "" {
+			byNumber[cue.number] = cue.key
+		}
+		if cue.name != "" {
+			byName[cue.name] = append(byName[cue.name], cue.key)
+		}
+		if cue.uniqueID != "" {
+			byID[cue.uniqueID] = cue.key
+		}
+	}
+
+	// Source targets may point at cues that are only in QLab
+	var inQLab func(number, name string) (int, bool)
+	if !fromQLab {
+		inQLab = q.qlabTargetLookup()
+	}
+
+	report := &TargetReport{Cues: len(cues)}
+	edges := make(map[string]string)
+	for _, cue := range cues {
+		problem := TargetProblem{Cue: cue.key, Name: cue.name}
+		switch {
+		case cue.byNumber != "":
+			problem.Target = "number " + cue.byNumber
+			if key, ok := byNumber[cue.byNumber]; ok {
+				edges[cue.key] = key
+			} else if inQLab == nil {
+				problem.Kind, problem.Detail = TargetBroken, "no cue with that number"
+			} else if _, ok := inQLab(cue.byNumber, ""); !ok {
+				problem.Kind, problem.Detail = TargetBroken, "no cue with that number in the source or QLab"
+			}
+		case cue.byName != "":
+			problem.Target = fmt.Sprintf("name %q", cue.byName)
+			matches := len(byName[cue.byName])
+			if matches == 0 && inQLab != nil {
+				matches, _ = inQLab("", cue.byName)
+			}
+			switch {
+			case matches == 1 && len(byName[cue.byName]) == 1:
+				edges[cue.key] = byName[cue.byName][0]
+			case matches == 0:
+				problem.Kind, problem.Detail = TargetBroken, "no cue with that name"
+			case matches > 1:
+				problem.Kind, problem.Detail = TargetBroken, fmt.Sprintf("%d cues have that name", matches)
+			}
+		case cue.byID != "":
+			problem.Target = "ID " + cue.byID
+			if key, ok := byID[cue.byID]; ok {
+				edges[cue.key] = key
+			} else if fromQLab {
+				problem.Kind, problem.Detail = TargetBroken, "no cue with that ID"
+			}
+		case slices.Contains(targetingCueTypes, cue.cueType):
+			problem.Kind = TargetMissing
+		}
+		if problem.Kind == "" && slices.Contains(fileCueTypes, cue.cueType) && cue.fileTarget == "" {
+			problem.Kind = TargetFileMissing
+		}
+		if problem.Kind != "" {
+			report.Problems = append(report.Problems, problem)
+		}
+	}
+
+	report.Problems = append(report.Problems, targetLoops(cues, edges)...)
+	return report, nil
+}
+
+// collectTargetChecks returns the cues of source or QLab data in workspace order,
+// leaving out the list and cart cues that name cue lists in source data
+func (q *Workspace) collectTargetChecks(workspaceData map[string]any) []targetCheck {
+	var checks []targetCheck
+	var walk func(cues []any, parentNumber string)
+	walk = func(cues []any, parentNumber string) {
+		for i, cueData := range cues {
+			cue, ok := cueData.(map[string]any)
+			if !ok {
+				continue
+			}
+			key, fullNumber := q.sourceCueKey(cue, parentNumber, i)
+			cueType, _ := cue["type"].(string)
+			cueType = strings.ToLower(cueType)
+			switch cueType {
+			case "list", CueTypeCart, CueTypeList:
+			default:
+				check := targetCheck{key: key, number: fullNumber, cueType: cueType}
+				check.name, _ = cue["name"].(string)
+				check.uniqueID, _ = cue["uniqueID"].(string)
+				check.fileTarget, _ = cue["fileTarget"].(string)
+				check.byNumber = formatCueNumber(cue["cueTargetNumber"])
+				check.byName, _ = cue["cueTargetName"].(string)
+				check.byID, _ = cue["cueTargetID"].(string)
+				if key != "" {
+					checks = append(checks, check)
+				}
+			}
+			if children, ok := cue["cues"].([]any); ok {
+				walk(children, fullNumber)
+			}
+		}
+	}
+
+	if cues, ok := workspaceData["cues"].([]any); ok {
+		walk(cues, "")
+	} else if nested, ok := workspaceData["workspace"].(map[string]any); ok {
+		if cues, ok := nested["cues"].([]any); ok {
+			walk(cues, "")
+		}
+	} else if cueLists, ok := workspaceData["data"].([]any); ok {
+		for _, cueListData := range cueLists {
+			if cueList, ok := cueListData.(map[string]any); ok {
+				if cues, ok := cueList["cues"].([]any); ok {
+					walk(cues, "")
+				}
+			}
+		}
+	}
+	return checks
+}
+
+// qlabTargetLookup returns a function that counts the cues in QLab with a number or name.
+// QLab is only queried the first time, and counts nothing when it can't be reached.
+func (q *Workspace) qlabTargetLookup() func(number, name string) (int, bool) {
+	var numbers map[string]int
+	var names map[string]int
+	return func(number, name string) (int, bool) {
+		if numbers == nil {
+			numbers, names = make(map[string]int), make(map[string]int)
+			if cueLists, err := q.getCueLists(); err == nil {
+				for _, cue := range q.collectTargetChecks(map[string]any{"data": cueLists}) {
+					if cue.number != "" {
+						numbers[cue.number]++
+					}
+					if cue.name != "" {
+						names[cue.name]++
+					}
+				}
+			}
+		}
+		if number != "" {
+			return numbers[number], numbers[number] > 0
+		}
+		return names[name], names[name] > 0
+	}
+}
+
+// targetLoops reports each loop of start and stop cues once, from its first cue in
+// workspace order
+func targetLoops(cues []targetCheck, edges map[string]string) []TargetProblem {
+	triggers := make(map[string]targetCheck)
+	for _, cue := range cues {
+		if slices.Contains(triggeringCueTypes, cue.cueType) {
+			triggers[cue.key] = cue
+		}
+	}
+
+	var problems []TargetProblem
+	reported := make(map[string]bool)
+	for _, cue := range cues {
+		if _, ok := triggers[cue.key]; !ok || reported[cue.key] {
+			continue
+		}
+		// Follow the chain of start and stop cues until it ends or comes back around
+		chain := []string{cue.key}
+		for next, ok := edges[cue.key]; ok; next, ok = edges[next] {
+			if _, isTrigger := triggers[next]; !isTrigger || reported[next] {
+				break
+			}
+			if next == cue.key {
+				for _, key := range chain {
+					reported[key] = true
+				}
+				problems = append(problems, TargetProblem{
+					Kind:   TargetCircular,
+					Cue:    cue.key,
+					Name:   cue.name,
+					Target: strings.Join(append(chain, cue.key), " -> "),
+				})
+				break
+			}
+			if slices.Contains(chain, next) {
+				break // A loop that this cue only leads into, reported from its own cues
+			}
+			chain = append(chain, next)
+		}
+	}
+	return problems
+}
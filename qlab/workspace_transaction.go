@@ -0,0 +1,391 @@
+package qlab
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// TransactionOpKind identifies the kind of mutating OSC call recorded by a transaction
+type TransactionOpKind string
+
+const (
+	TransactionCreate      TransactionOpKind = "create"       // /workspace/{id}/new
+	TransactionMove        TransactionOpKind = "move"         // /workspace/{id}/move/{cue_id}
+	TransactionDelete      TransactionOpKind = "delete"       // /workspace/{id}/delete_id/{cue_id}
+	TransactionSetProperty TransactionOpKind = "set_property" // /workspace/{id}/cue_id/{cue_id}/{property}
+	TransactionOther       TransactionOpKind = "other"        // Any other write; recorded but cannot be undone
+)
+
+// TransactionOp is a single mutating OSC call recorded by a transaction, with
+// enough prior state to undo it
+type TransactionOp struct {
+	Kind     TransactionOpKind
+	Address  string
+	Args     []any
+	CueID    string // Cue created, moved, deleted, or modified
+	Property string // Property name for TransactionSetProperty
+
+	PreviousValue    any               // Value before a property set, as reported by QLab
+	HasPreviousValue bool              // Whether PreviousValue was captured
+	PreviousParentID string            // Parent before a move or delete
+	PreviousIndex    int               // Index within the previous parent, -1 if unknown
+	Snapshot         map[string]string // Basic properties of a deleted cue, used to recreate it
+}
+
+// Transaction records every mutating OSC call made through a Workspace so they
+// can be undone if an operation fails part-way through
+type Transaction struct {
+	workspace *Workspace
+	ops       []TransactionOp
+	created   map[string]bool // Cues created within the transaction
+	done      bool
+	committed bool
+	mu        sync.Mutex
+}
+
+// snapshotProperties are queried before a cue is deleted so rollback can recreate it
+var snapshotProperties = []string{"type", "name", "number", "notes", "colorName"}
+
+// SetTransactional sets whether TransmitWorkspaceData runs inside a transaction
+// and rolls back every change if it fails part-way through
+func (q *Workspace) SetTransactional(transactional bool) {
+	q.transactional = transactional
+}
+
+// BeginTransaction starts recording mutating OSC calls. Only one transaction may be
+// active on a workspace at a time; finish it with Commit or Rollback.
+func (q *Workspace) BeginTransaction() (*Transaction, error) {
+	tx := &Transaction{
+		workspace: q,
+		created:   make(map[string]bool),
+	}
+	q.stateMu.Lock()
+	defer q.stateMu.Unlock()
+	if q.transaction != nil {
+		return nil, fmt.Errorf("a transaction is already active on this workspace")
+	}
+	q.transaction = tx
+	q.log().Debug("Transaction started")
+	return tx, nil
+}
+
+// activeTransaction returns the transaction recording mutating calls, or nil if none is active
+func (q *Workspace) activeTransaction() *Transaction {
+	q.stateMu.Lock()
+	defer q.stateMu.Unlock()
+	return q.transaction
+}
+
+// Operations returns a copy of the operations recorded so far
+func (t *Transaction) Operations() []TransactionOp {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ops := make([]TransactionOp, len(t.ops))
+	copy(ops, t.ops)
+	return ops
+}
+
+// Commit stops recording and keeps every change
+func (t *Transaction) Commit() {
+	if t.finish() {
+		t.mu.Lock()
+		t.committed = true
+		t.mu.Unlock()
+	}
+	t.workspace.log().Debug("Transaction committed", "operations", len(t.ops))
+}
+
+// Rollback undoes the recorded operations in reverse order: property sets are restored,
+// moves are reversed, created cues are deleted, and deleted cues are recreated from their
+// snapshot (media and other properties are not restored). Every operation is attempted;
+// the returned error summarizes the ones that could not be undone.
+func (t *Transaction) Rollback() error {
+	t.workspace.opMu.Lock()
+	defer t.workspace.opMu.Unlock()
+	return t.rollback()
+}
+
+// rollback undoes the recorded operations; the workspace's opMu must be held
+func (t *Transaction) rollback() error {
+	if !t.finish() {
+		return fmt.Errorf("transaction already finished")
+	}
+
+	t.mu.Lock()
+	ops := make([]TransactionOp, len(t.ops))
+	copy(ops, t.ops)
+	t.mu.Unlock()
+
+	t.workspace.log().Infof("Rolling back transaction with %d operations", len(ops))
+
+	var failures []string
+	for i := len(ops) - 1; i >= 0; i-- {
+		if err := t.undo(ops[i]); err != nil {
+			t.workspace.log().Warnf("Failed to undo %s on %s: %v", ops[i].Kind, ops[i].Address, err)
+			failures = append(failures, fmt.Sprintf("%s %s: %v", ops[i].Kind, ops[i].Address, err))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("rollback incomplete, %d of %d operations could not be undone: %s",
+			len(failures), len(ops), strings.Join(failures, "; "))
+	}
+	t.workspace.log().Info("Transaction rolled back")
+	return nil
+}
+
+// finish detaches the transaction from its workspace; it returns false if already finished
+func (t *Transaction) finish() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.done {
+		return false
+	}
+	t.done = true
+	t.workspace.stateMu.Lock()
+	if t.workspace.transaction == t {
+		t.workspace.transaction = nil
+	}
+	t.workspace.stateMu.Unlock()
+	return true
+}
+
+// isCommitted reports whether the transaction finished with Commit
+func (t *Transaction) isCommitted() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.committed
+}
+
+// undo reverses a single recorded operation
+func (t *Transaction) undo(op TransactionOp) error {
+	q := t.workspace
+
+	switch op.Kind {
+	case TransactionCreate:
+		return q.deleteCue(op.CueID)
+
+	case TransactionSetProperty:
+		if t.created[op.CueID] {
+			return nil // The cue itself is deleted when its create is undone
+		}
+		if !op.HasPreviousValue {
+			return fmt.Errorf("previous value of %s was not captured", op.Property)
+		}
+		return checkReplyStatus(q.SendWithArgs(op.Address, previousValueArgs(op.PreviousValue)...))
+
+	case TransactionMove:
+		if t.created[op.CueID] {
+			return nil
+		}
+		if op.PreviousParentID == "" {
+			return fmt.Errorf("previous parent of cue %s was not captured", op.CueID)
+		}
+		index := op.PreviousIndex
+		if index < 0 {
+			index = 0
+		}
+		return q.moveCueToParentWithIndex(op.CueID, op.PreviousParentID, index)
+
+	case TransactionDelete:
+		if t.created[op.CueID] {
+			return nil
+		}
+		return t.recreate(op)
+
+	default:
+		return fmt.Errorf("operation cannot be undone")
+	}
+}
+
+// recreate creates a new cue from a deleted cue's snapshot
+func (t *Transaction) recreate(op TransactionOp) error {
+	q := t.workspace
+
+	cueType := op.Snapshot["type"]
+	if cueType == "" {
+		return fmt.Errorf("type of deleted cue %s was not captured", op.CueID)
+	}
+
+	address := fmt.Sprintf("/workspace/%s/new", q.workspace_id)
+	reply := q.Send(address, strings.ToLower(cueType))
+	if err := checkReplyStatus(reply); err != nil {
+		return err
+	}
+	newID := replyDataString(reply)
+	if newID == "" {
+		return fmt.Errorf("no uniqueID in new cue reply")
+	}
+
+	for _, property := range snapshotProperties[1:] {
+		if value := op.Snapshot[property]; value != "" {
+			if err := checkReplyStatus(q.Send(q.addressBuilder.BuildCuePropertyAddress(newID, property), value)); err != nil {
+				t.workspace.log().Warnf("Failed to restore %s on recreated cue %s: %v", property, newID, err)
+			}
+		}
+	}
+
+	if op.PreviousParentID != "" {
+		index := op.PreviousIndex
+		if index < 0 {
+			index = 0
+		}
+		if err := q.moveCueToParentWithIndex(newID, op.PreviousParentID, index); err != nil {
+			return err
+		}
+	}
+
+	t.workspace.log().Infof("Recreated deleted cue %s as %s", op.CueID, newID)
+	return nil
+}
+
+// beginOp inspects an outgoing message and, if it mutates the workspace, captures the
+// state needed to undo it. It returns nil for reads.
+func (t *Transaction) beginOp(address string, args []any) *TransactionOp {
+	parts := strings.Split(strings.TrimPrefix(address, "/"), "/")
+	if len(parts) >= 2 && parts[0] == "workspace" {
+		parts = parts[2:]
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+
+	q := t.workspace
+	op := &TransactionOp{Address: address, Args: args, PreviousIndex: -1}
+
+	switch {
+	case parts[0] == "new":
+		op.Kind = TransactionCreate
+
+	case parts[0] == "move" && len(parts) >= 2:
+		op.Kind = TransactionMove
+		op.CueID = parts[1]
+		if !t.isCreated(op.CueID) {
+			op.PreviousParentID, op.PreviousIndex = q.queryCueLocation(op.CueID)
+		}
+
+	case parts[0] == "delete_id" && len(parts) >= 2:
+		op.Kind = TransactionDelete
+		op.CueID = parts[1]
+		if !t.isCreated(op.CueID) {
+			op.Snapshot = make(map[string]string)
+			for _, property := range snapshotProperties {
+				reply := q.Send(q.addressBuilder.BuildCuePropertyAddress(op.CueID, property), "")
+				if checkReplyStatus(reply) == nil {
+					op.Snapshot[property] = replyDataString(reply)
+				}
+			}
+			op.PreviousParentID, op.PreviousIndex = q.queryCueLocation(op.CueID)
+		}
+
+	case parts[0] == "cue_id" && len(parts) >= 3:
+		if len(args) == 0 {
+			return nil // Property query
+		}
+		op.Kind = TransactionSetProperty
+		op.CueID = parts[1]
+		op.Property = strings.Join(parts[2:], "/")
+		if !t.isCreated(op.CueID) {
+			op.PreviousValue, op.HasPreviousValue = q.queryPreviousValue(address)
+		}
+
+	default:
+		if !q.isWriteOperation(address) || len(args) == 0 {
+			return nil
+		}
+		op.Kind = TransactionOther
+	}
+
+	return op
+}
+
+// endOp records an operation once QLab has accepted it
+func (t *Transaction) endOp(op *TransactionOp, reply []any) {
+	if op == nil || checkReplyStatus(reply) != nil {
+		return
+	}
+	if op.Kind == TransactionCreate {
+		op.CueID = replyDataString(reply)
+	}
+	t.record(*op)
+}
+
+// record appends an operation to the transaction log
+func (t *Transaction) record(op TransactionOp) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if op.Kind == TransactionCreate && op.CueID != "" {
+		t.created[op.CueID] = true
+	}
+	t.ops = append(t.ops, op)
+	t.workspace.log().Debug("Transaction recorded operation", "kind", op.Kind, "address", op.Address)
+}
+
+// isCreated reports whether a cue was created within this transaction
+func (t *Transaction) isCreated(cueID string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.created[cueID]
+}
+
+// queryPreviousValue reads the current value at a property address before it is overwritten
+func (q *Workspace) queryPreviousValue(address string) (any, bool) {
+	reply := q.Send(address, "")
+	if checkReplyStatus(reply) != nil {
+		return nil, false
+	}
+	return replyDataValue(reply)
+}
+
+// queryCueLocation returns a cue's parent ID and its index within the parent, or "", -1 if unknown
+func (q *Workspace) queryCueLocation(cueID string) (string, int) {
+	if !q.Supports(FeatureParentProperty) {
+		return q.locateCue(cueID)
+	}
+	reply := q.Send(q.addressBuilder.BuildCuePropertyAddress(cueID, "parent"), "")
+	if checkReplyStatus(reply) != nil {
+		return "", -1
+	}
+	parentID := replyDataString(reply)
+	if parentID == "" {
+		return "", -1
+	}
+
+	children, err := q.getCueChildren(parentID)
+	if err != nil {
+		return parentID, -1
+	}
+	for i, child := range children {
+		if id, _ := child["uniqueID"].(string); id == cueID {
+			return parentID, i
+		}
+	}
+	return parentID, -1
+}
+
+// previousValueArgs converts a queried property value back into OSC arguments
+func previousValueArgs(value any) []any {
+	switch v := value.(type) {
+	case []any:
+		// Multi-component values such as translation or colors are sent as float32 arguments
+		args := make([]any, 0, len(v))
+		for _, component := range v {
+			if f, ok := component.(float64); ok {
+				args = append(args, float32(f))
+			} else {
+				args = append(args, component)
+			}
+		}
+		return args
+	case nil:
+		return []any{""}
+	case string:
+		return []any{v}
+	default:
+		return []any{fmt.Sprintf("%v", v)}
+	}
+}
@@ -8,6 +8,8 @@ import (
 // ToWorkspaceData converts workspace name and cues to structured data.
 // The caller can serialize this to JSON, YAML, TOML, or any other format.
 // This returns a WorkspaceData struct with Name and Cues fields.
+//
+// This API is stable.
 func ToWorkspaceData(workspaceName string, cues []Cue) WorkspaceData {
 	// Normalize all cues to ensure proper defaults
 	normalizedCues := make([]Cue, len(cues))
@@ -24,6 +26,8 @@ func ToWorkspaceData(workspaceName string, cues []Cue) WorkspaceData {
 
 // ToJSON converts workspace name and cues to JSON format.
 // The caller can write this to a .json file if needed.
+//
+// This API is stable.
 func ToJSON(workspaceName string, cues []Cue, indent bool) (string, error) {
 	data := ToWorkspaceData(workspaceName, cues)
 	var result []byte
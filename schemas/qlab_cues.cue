@@ -37,10 +37,27 @@ package schemas
 	// === TARGETING ===
 	cueTargetID:     string | *"" // Target cue unique ID
 	cueTargetNumber: string | *"" // Target cue number
+	cueTargetName?:  string       // Target cue name, used when cueTargetNumber is empty
 	fileTarget:      string | *"" // File path for audio/video/image cues
 	
+	// === TRIGGERS ===
+	midiTrigger?:         bool
+	midiTriggerStatus?:   int | string // Status byte or name, e.g. "noteOn"
+	midiTriggerChannel?:  int
+	midiTriggerByteOne?:  int
+	midiTriggerByteTwo?:  int
+	hotkeyTrigger?:       bool
+	hotkeyTriggerString?: string
+	wallClockTrigger?:    bool
+	wallClockHours?:      int & >=0 & <=23
+	wallClockMinutes?:    int & >=0 & <=59
+	wallClockSeconds?:    int & >=0 & <=59
+	timecodeTrigger?:     bool
+	timecodeTriggerText?: string // "HH:MM:SS:FF"
+
 	// === NESTED CUES ===
 	cues?: [...#Cue] // Child cues for groups
+	cartPosition?: {row: int & >=1, column: int & >=1} | [int & >=1, int & >=1] // Cell of a cart child
 	
 	// Allow additional fields for specific cue types
 	...
@@ -75,10 +92,19 @@ package schemas
 	"endTime"?:         string
 	layer?:             int
 	fullScreen?:        bool
+	fillStage?:         bool
+	preserveAspectRatio?: bool
+	fillMode?:          "none" | "stretch" | "fit" // Shorthand for fillStage/preserveAspectRatio
+	holdAtEnd?:         bool
 	translation?:       [number, number] // [x, y]
 	scale?:             [number, number] // [x, y]
 	rotation?:          number
+	rotationType?:      int
 	opacity?:           number | *1.0
+
+	// Stage assignment
+	stageName?: string
+	stageID?:   string
 	...
 }
 
@@ -118,6 +144,10 @@ package schemas
 	// Light-specific properties
 	patchNumber?:      string
 	patchTargetID?:    string
+	lightCommandText?: string               // One "instrument = level" command per line
+	lightCommands?:    [string]: number     // Instrument or group levels, built into lightCommandText
+	alwaysCollate?:    bool
+	prune?:            bool                 // Prune the commands after setting them
 	...
 }
 
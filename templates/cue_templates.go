@@ -10,10 +10,13 @@ type CueTemplate struct {
 
 // CueGenerationRequest represents a request to generate cues
 type CueGenerationRequest struct {
-	AnnotationID  string      `json:"annotation_id"`
-	CueNumber string      `json:"cue_number"`
-	Template  CueTemplate `json:"template"`
-	ParentID  string      `json:"parent_id,omitempty"` // Optional: where to insert in hierarchy
+	AnnotationID string      `json:"annotation_id"`
+	CueNumber    string      `json:"cue_number"`
+	Template     CueTemplate `json:"template"`
+	ParentID     string      `json:"parent_id,omitempty"` // Optional: where to insert in hierarchy
+	// IdempotencyKey, when set, is stamped on the generated cue so generating the same
+	// request again updates that cue instead of creating another
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
 }
 
 // CueGenerationResult represents the result of cue generation
@@ -30,4 +33,12 @@ type CreatedCue struct {
 	Name      string `json:"name"`
 	Type      string `json:"type"`
 	ParentID  string `json:"parent_id,omitempty"`
+	Updated   bool   `json:"updated,omitempty"` // Whether an existing cue was updated rather than created
+}
+
+// BatchGenerationResult represents the result of generating several requests together
+type BatchGenerationResult struct {
+	Success     bool                  `json:"success"`
+	CuesCreated []CreatedCue          `json:"cues_created,omitempty"` // Every request's cues, in the order they were created
+	Results     []CueGenerationResult `json:"results"`                // Each request's result, in the order the requests were given
 }
@@ -0,0 +1,270 @@
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Parameters that name where generated cues go rather than what they contain. They are
+// never required, and are available to template text like any other parameter.
+const (
+	ParamCueNumber = "CueNumber" // Number of the top cue; children are numbered under it
+	ParamParentID  = "ParentID"  // Unique ID of the group to create the cues in
+)
+
+// TemplateLibrary is a named collection of reusable cue templates
+type TemplateLibrary struct {
+	Name      string                     `json:"name,omitempty" yaml:"name,omitempty"`
+	Templates map[string]LibraryTemplate `json:"templates" yaml:"templates"`
+}
+
+// LibraryTemplate is a template in a library. Its names and string properties can refer
+// to parameters with Go template syntax, e.g. "{{.SceneName}} lights".
+type LibraryTemplate struct {
+	Description string              `json:"description,omitempty" yaml:"description,omitempty"`
+	Parameters  []TemplateParameter `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	Template    CueTemplate         `json:"template" yaml:"template"`
+}
+
+// TemplateParameter is a value supplied when a library template is used
+type TemplateParameter struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Default     string `json:"default,omitempty" yaml:"default,omitempty"`
+	Required    bool   `json:"required,omitempty" yaml:"required,omitempty"`
+}
+
+// NewTemplateLibrary creates an empty library
+func NewTemplateLibrary(name string) *TemplateLibrary {
+	return &TemplateLibrary{Name: name, Templates: make(map[string]LibraryTemplate)}
+}
+
+// LoadTemplateLibrary reads a library saved as JSON by Save
+func LoadTemplateLibrary(r io.Reader) (*TemplateLibrary, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template library: %w", err)
+	}
+	return DecodeTemplateLibrary(data, json.Unmarshal)
+}
+
+// DecodeTemplateLibrary reads a library with the given unmarshal function, such as
+// json.Unmarshal or yaml.Unmarshal from gopkg.in/yaml.v3, and validates it
+func DecodeTemplateLibrary(data []byte, unmarshal func([]byte, any) error) (*TemplateLibrary, error) {
+	library := NewTemplateLibrary("")
+	if err := unmarshal(data, library); err != nil {
+		return nil, fmt.Errorf("failed to decode template library: %w", err)
+	}
+	if library.Templates == nil {
+		library.Templates = make(map[string]LibraryTemplate)
+	}
+	if err := library.Validate(); err != nil {
+		return nil, err
+	}
+	return library, nil
+}
+
+// Save writes the library as indented JSON
+func (l *TemplateLibrary) Save(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(l); err != nil {
+		return fmt.Errorf("failed to write template library: %w", err)
+	}
+	return nil
+}
+
+// Encode writes the library with the given marshal function, such as yaml.Marshal
+func (l *TemplateLibrary) Encode(marshal func(any) ([]byte, error)) ([]byte, error) {
+	data, err := marshal(l)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode template library: %w", err)
+	}
+	return data, nil
+}
+
+// Add validates a template and adds it to the library, replacing any with the same name
+func (l *TemplateLibrary) Add(name string, t LibraryTemplate) error {
+	if err := validateLibraryTemplate(name, t); err != nil {
+		return err
+	}
+	if l.Templates == nil {
+		l.Templates = make(map[string]LibraryTemplate)
+	}
+	l.Templates[name] = t
+	return nil
+}
+
+// Get returns the named template
+func (l *TemplateLibrary) Get(name string) (LibraryTemplate, bool) {
+	t, ok := l.Templates[name]
+	return t, ok
+}
+
+// Remove deletes the named template
+func (l *TemplateLibrary) Remove(name string) {
+	delete(l.Templates, name)
+}
+
+// Names returns the names of the templates, sorted
+func (l *TemplateLibrary) Names() []string {
+	names := make([]string, 0, len(l.Templates))
+	for name := range l.Templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate checks every template in the library, returning all the problems found
+func (l *TemplateLibrary) Validate() error {
+	var errs []error
+	for _, name := range l.Names() {
+		if err := validateLibraryTemplate(name, l.Templates[name]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateLibraryTemplate checks that a template has a name, that every cue has a type,
+// and that its text only refers to declared parameters
+func validateLibraryTemplate(name string, t LibraryTemplate) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("template name is empty")
+	}
+	declared := map[string]string{ParamCueNumber: "", ParamParentID: ""}
+	for _, param := range t.Parameters {
+		if param.Name == "" {
+			return fmt.Errorf("template %q: parameter with no name", name)
+		}
+		if _, dup := declared[param.Name]; dup {
+			return fmt.Errorf("template %q: parameter %q declared twice", name, param.Name)
+		}
+		declared[param.Name] = ""
+	}
+	if _, err := expandCueTemplate(t.Template, declared, "template"); err != nil {
+		return fmt.Errorf("template %q: %w", name, err)
+	}
+	return nil
+}
+
+// Request builds a generation request from the named template. Parameters missing from
+// params take their defaults; a missing required parameter is an error. The cue number
+// and parent come from the ParamCueNumber and ParamParentID parameters.
+func (l *TemplateLibrary) Request(name string, params map[string]string) (CueGenerationRequest, error) {
+	t, ok := l.Templates[name]
+	if !ok {
+		return CueGenerationRequest{}, fmt.Errorf("no template named %q", name)
+	}
+
+	values := map[string]string{ParamCueNumber: params[ParamCueNumber], ParamParentID: params[ParamParentID]}
+	for _, param := range t.Parameters {
+		value, ok := params[param.Name]
+		if !ok {
+			if param.Required {
+				return CueGenerationRequest{}, fmt.Errorf("template %q: missing required parameter %q", name, param.Name)
+			}
+			value = param.Default
+		}
+		values[param.Name] = value
+	}
+
+	expanded, err := expandCueTemplate(t.Template, values, "template")
+	if err != nil {
+		return CueGenerationRequest{}, fmt.Errorf("template %q: %w", name, err)
+	}
+	return CueGenerationRequest{
+		CueNumber: values[ParamCueNumber],
+		ParentID:  values[ParamParentID],
+		Template:  expanded,
+	}, nil
+}
+
+// expandCueTemplate substitutes the parameters into the cue's name and string properties,
+// and into its children. path locates the cue in errors.
+func expandCueTemplate(t CueTemplate, values map[string]string, path string) (CueTemplate, error) {
+	if strings.TrimSpace(t.Type) == "" {
+		return CueTemplate{}, fmt.Errorf("%s has no type", path)
+	}
+	expanded := CueTemplate{Type: t.Type}
+
+	name, err := expandText(t.Name, values)
+	if err != nil {
+		return CueTemplate{}, fmt.Errorf("%s name: %w", path, err)
+	}
+	expanded.Name = name
+
+	if t.Properties != nil {
+		expanded.Properties = make(map[string]any, len(t.Properties))
+		for key, value := range t.Properties {
+			v, err := expandValue(value, values)
+			if err != nil {
+				return CueTemplate{}, fmt.Errorf("%s property %s: %w", path, key, err)
+			}
+			expanded.Properties[key] = v
+		}
+	}
+
+	for i, child := range t.Children {
+		c, err := expandCueTemplate(child, values, fmt.Sprintf("%s.children[%d]", path, i))
+		if err != nil {
+			return CueTemplate{}, err
+		}
+		expanded.Children = append(expanded.Children, c)
+	}
+	return expanded, nil
+}
+
+// expandValue substitutes the parameters into the strings of a property value
+func expandValue(value any, values map[string]string) (any, error) {
+	switch v := value.(type) {
+	case string:
+		return expandText(v, values)
+	case []any:
+		expanded := make([]any, len(v))
+		for i, item := range v {
+			e, err := expandValue(item, values)
+			if err != nil {
+				return nil, err
+			}
+			expanded[i] = e
+		}
+		return expanded, nil
+	case map[string]any:
+		expanded := make(map[string]any, len(v))
+		for key, item := range v {
+			e, err := expandValue(item, values)
+			if err != nil {
+				return nil, err
+			}
+			expanded[key] = e
+		}
+		return expanded, nil
+	default:
+		return value, nil
+	}
+}
+
+// expandText executes text as a Go template over the parameters. Referring to a parameter
+// that isn't there is an error.
+func expandText(text string, values map[string]string) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	tmpl, err := template.New("").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, values); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,104 @@
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// sceneTemplate is a group with a parameterized name and a child with a default parameter
+func sceneTemplate() LibraryTemplate {
+	return LibraryTemplate{
+		Description: "A scene with a standby memo",
+		Parameters: []TemplateParameter{
+			{Name: "SceneName", Required: true},
+			{Name: "Fade", Default: "3"},
+		},
+		Template: CueTemplate{
+			Type: "group",
+			Name: "{{.SceneName}}",
+			Children: []CueTemplate{
+				{Type: "fade", Name: "{{.SceneName}} fade", Properties: map[string]any{"duration": "{{.Fade}}", "mode": 1.0}},
+			},
+		},
+	}
+}
+
+// TestTemplateLibraryRoundTrip tests saving and loading a library as JSON
+func TestTemplateLibraryRoundTrip(t *testing.T) {
+	library := NewTemplateLibrary("Show")
+	if err := library.Add("scene", sceneTemplate()); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := library.Save(&buf); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	loaded, err := LoadTemplateLibrary(&buf)
+	if err != nil {
+		t.Fatalf("LoadTemplateLibrary failed: %v", err)
+	}
+	if loaded.Name != "Show" || len(loaded.Names()) != 1 || loaded.Names()[0] != "scene" {
+		t.Fatalf("Unexpected library: %+v", loaded)
+	}
+
+	// Any marshal/unmarshal pair works, which is how YAML is supported
+	data, err := loaded.Encode(json.Marshal)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if _, err := DecodeTemplateLibrary(data, json.Unmarshal); err != nil {
+		t.Fatalf("DecodeTemplateLibrary failed: %v", err)
+	}
+}
+
+// TestTemplateLibraryRequest tests parameter substitution and defaults
+func TestTemplateLibraryRequest(t *testing.T) {
+	library := NewTemplateLibrary("")
+	if err := library.Add("scene", sceneTemplate()); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	request, err := library.Request("scene", map[string]string{"SceneName": "Storm", ParamCueNumber: "20", ParamParentID: "GROUP-1"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if request.CueNumber != "20" || request.ParentID != "GROUP-1" || request.Template.Name != "Storm" {
+		t.Errorf("Unexpected request: %+v", request)
+	}
+	fade := request.Template.Children[0]
+	if fade.Name != "Storm fade" || fade.Properties["duration"] != "3" || fade.Properties["mode"] != 1.0 {
+		t.Errorf("Expected the default fade time substituted, got %+v", fade)
+	}
+	if library.Templates["scene"].Template.Name != "{{.SceneName}}" {
+		t.Error("Request should not change the library's template")
+	}
+
+	if _, err := library.Request("scene", nil); err == nil || !strings.Contains(err.Error(), `"SceneName"`) {
+		t.Errorf("Expected a missing required parameter error, got %v", err)
+	}
+	if _, err := library.Request("unknown", nil); err == nil {
+		t.Error("Expected an error for an unknown template")
+	}
+}
+
+// TestTemplateLibraryValidate tests the problems validation reports
+func TestTemplateLibraryValidate(t *testing.T) {
+	undeclared := sceneTemplate()
+	undeclared.Template.Children[0].Name = "{{.Missing}}"
+	noType := sceneTemplate()
+	noType.Template.Children[0].Type = ""
+
+	for name, tmpl := range map[string]LibraryTemplate{"undeclared": undeclared, "no type": noType} {
+		if err := NewTemplateLibrary("").Add(name, tmpl); err == nil {
+			t.Errorf("%s: expected Add to fail", name)
+		}
+	}
+
+	_, err := DecodeTemplateLibrary([]byte(`{"templates": {"bad": {"template": {"name": "{{.X"}}}}`), json.Unmarshal)
+	if err == nil || !strings.Contains(err.Error(), `template "bad"`) {
+		t.Errorf("Expected loading an invalid library to fail, got %v", err)
+	}
+}